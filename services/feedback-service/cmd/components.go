@@ -0,0 +1,521 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/envelope"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/leader"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/notification"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/render"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/upstream"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/webhook"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// postgresComponent connects to PostgreSQL and applies migrations
+type postgresComponent struct {
+	cfg config.DatabaseConfig
+	db  *sql.DB
+}
+
+func (c *postgresComponent) Name() string { return "postgres" }
+
+func (c *postgresComponent) Start(ctx context.Context) error {
+	db, err := database.NewConnection(ctx, c.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	if err := database.Migrate(ctx, db, "migrations"); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to run database migrations: %w", err)
+	}
+	c.db = db
+	return nil
+}
+
+func (c *postgresComponent) Stop(ctx context.Context) error {
+	return c.db.Close()
+}
+
+// mongoComponent connects to MongoDB and ensures its indexes
+type mongoComponent struct {
+	cfg    config.MongoDBConfig
+	client *database.MongoDBClient
+}
+
+func (c *mongoComponent) Name() string { return "mongodb" }
+
+func (c *mongoComponent) Start(ctx context.Context) error {
+	client, err := database.ConnectMongoDB(ctx, c.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.CreateIndexes(ctx, c.cfg.Collection); err != nil {
+		client.Close(context.Background())
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+	if err := client.CreateCommentReactionIndexes(ctx); err != nil {
+		client.Close(context.Background())
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+	if err := client.CreateCommentReportIndexes(ctx); err != nil {
+		client.Close(context.Background())
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+	if err := client.CreateCommentDeletionIndexes(ctx); err != nil {
+		client.Close(context.Background())
+		return fmt.Errorf("failed to create MongoDB indexes: %w", err)
+	}
+	c.client = client
+	return nil
+}
+
+func (c *mongoComponent) Stop(ctx context.Context) error {
+	return c.client.Close(ctx)
+}
+
+// minioComponent creates the MinIO client and prepares the attachment bucket
+type minioComponent struct {
+	cfg    config.MinIOConfig
+	logger *slog.Logger
+	client *minio.Client
+}
+
+func (c *minioComponent) Name() string { return "minio" }
+
+func (c *minioComponent) Start(ctx context.Context) error {
+	client, err := minio.New(c.cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(c.cfg.AccessKey, c.cfg.SecretKey, ""),
+		Secure: c.cfg.UseSSL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize MinIO client: %w", err)
+	}
+
+	// Create bucket if it doesn't exist
+	if c.cfg.CreateBucket {
+		exists, err := client.BucketExists(ctx, c.cfg.BucketName)
+		if err != nil {
+			return fmt.Errorf("failed to check if bucket exists: %w", err)
+		}
+		if !exists {
+			if err := client.MakeBucket(ctx, c.cfg.BucketName, minio.MakeBucketOptions{}); err != nil {
+				return fmt.Errorf("failed to create bucket: %w", err)
+			}
+			c.logger.Info("Created bucket", "bucket", c.cfg.BucketName)
+		}
+	}
+
+	// Set bucket policy for public read access
+	policy := fmt.Sprintf(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Effect": "Allow",
+				"Principal": "*",
+				"Action": ["s3:GetObject"],
+				"Resource": ["arn:aws:s3:::%s/*"]
+			}
+		]
+	}`, c.cfg.BucketName)
+	if err := client.SetBucketPolicy(ctx, c.cfg.BucketName, policy); err != nil {
+		return fmt.Errorf("failed to set bucket policy: %w", err)
+	}
+	c.logger.Info("Set read-only policy for bucket", "bucket", c.cfg.BucketName)
+
+	c.client = client
+	return nil
+}
+
+func (c *minioComponent) Stop(ctx context.Context) error {
+	return nil
+}
+
+// upstreamComponent creates the clients of users-service and labs-service that new feedback
+// is checked with; an unset address leaves its client nil
+type upstreamComponent struct {
+	cfg    config.UpstreamConfig
+	logger *slog.Logger
+	conns  []*grpc.ClientConn
+
+	submissions upstream.SubmissionClient
+	users       upstream.UserClient
+}
+
+func (c *upstreamComponent) Name() string { return "upstream" }
+
+func (c *upstreamComponent) Start(ctx context.Context) error {
+	if !c.cfg.Validate {
+		c.logger.Warn("Upstream validation disabled; feedback IDs are not checked")
+		return nil
+	}
+	if c.cfg.LabsAddr != "" {
+		conn, err := upstream.Dial(c.cfg.LabsAddr)
+		if err != nil {
+			return err
+		}
+		c.conns = append(c.conns, conn)
+		c.submissions = upstream.NewSubmissionClient(conn)
+	}
+	if c.cfg.UsersAddr != "" {
+		conn, err := upstream.Dial(c.cfg.UsersAddr)
+		if err != nil {
+			return err
+		}
+		c.conns = append(c.conns, conn)
+		c.users = upstream.NewUserClient(conn)
+	}
+	c.logger.Info("Upstream validation enabled",
+		"labs_service", c.cfg.LabsAddr,
+		"users_service", c.cfg.UsersAddr,
+		"fail_open", c.cfg.FailOpen,
+	)
+	return nil
+}
+
+func (c *upstreamComponent) Stop(ctx context.Context) error {
+	var errs []error
+	for _, conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// servicesComponent builds the repositories and services and runs their background workers
+type servicesComponent struct {
+	cfg      *config.Config
+	logger   *slog.Logger
+	postgres *postgresComponent
+	mongo    *mongoComponent
+	minio    *minioComponent
+	upstream *upstreamComponent
+
+	policyService      *service.CoursePolicyService
+	templateService    *service.TemplateService
+	feedbackService    *service.FeedbackService
+	commentService     *service.CommentService
+	permissionService  *service.PermissionService
+	commentRenderer    *render.Renderer
+	notifications      *notification.Renderer
+	pageTokens         *pagetoken.Codec
+	events             *bus.Bus
+	transferAccountant *service.TransferAccountant
+	retentionService   *service.RetentionService
+	attachmentRepo     repository.AttachmentRepository
+	elector            *leader.Elector
+	flags              *flags.Registry
+	webhooks           *webhook.Dispatcher // Nil unless WEBHOOK_ENDPOINTS_FILE is set
+	hup                chan os.Signal
+
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+func (c *servicesComponent) Name() string { return "services" }
+
+func (c *servicesComponent) Start(ctx context.Context) error {
+	db, mongodb, cfg := c.postgres.db, c.mongo.client, c.cfg
+
+	// Parse notification templates first so a broken override fails startup
+	notifications, err := notification.NewRenderer(cfg.Notification.TemplateDir, cfg.Notification.MaxContentChars)
+	if err != nil {
+		return fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	c.notifications = notifications
+
+	if c.flags, err = flags.New(cfg.Flags, c.logger); err != nil {
+		return err
+	}
+
+	var keyring *envelope.Keyring
+	if cfg.Encryption.Enabled {
+		if keyring, err = envelope.ParseKeyring(cfg.Encryption.MasterKeys, cfg.Encryption.ActiveKeyID); err != nil {
+			return fmt.Errorf("failed to load attachment encryption keys: %w", err)
+		}
+		c.logger.Info("Attachment encryption enabled", "active_key_id", keyring.ActiveKeyID())
+	}
+
+	// Columns still rolling out are read as their defaults until a migration adds them
+	schema, err := repository.LoadSchema(ctx, db, cfg.Schema.DeferredWrites)
+	if err != nil {
+		return fmt.Errorf("failed to load the feedbacks schema: %w", err)
+	}
+	if missing := schema.Missing(); len(missing) > 0 {
+		c.logger.Warn("Feedbacks table lacks rolling columns; reading their defaults", "columns", missing)
+	}
+
+	// Initialize repositories
+	feedbackRepo := repository.NewFeedbackRepository(db, mongodb, schema)
+	location, legacy := repository.AttachmentLocations(cfg.MinIO, cfg.Migration)
+	attachmentRepo := repository.NewAttachmentRepository(c.minio.client, location, legacy, cfg.MinIO.Endpoint, cfg.MinIO.UseSSL, keyring)
+	c.attachmentRepo = attachmentRepo
+	assetRepo := repository.NewAssetRepository(db)
+	transferRepo := repository.NewTransferUsageRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	sessionRepo := repository.NewUploadSessionRepository(db)
+	intentRepo := repository.NewDeletionIntentRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	commentRepo := repository.NewCommentRepository(mongodb, cfg.MongoDB.Collection)
+	policyRepo := repository.NewCoursePolicyRepository(db)
+
+	// Refuse to stop reading the legacy location while objects may still only exist there
+	if cfg.Migration.Cutover {
+		migrationService := service.NewAttachmentMigrationService(attachmentRepo, repository.NewMigrationJournalRepository(db), cfg.Migration, c.logger)
+		if err := migrationService.CheckCutover(ctx); err != nil {
+			return fmt.Errorf("ATTACHMENT_MIGRATION_CUTOVER is set: %w", err)
+		}
+	} else if legacy != nil {
+		c.logger.Info("Attachment migration enabled; reads fall back to the legacy location",
+			"legacy_bucket", legacy.Bucket,
+			"legacy_prefix", legacy.Prefix,
+		)
+	}
+
+	if cfg.Metadata.ListSource != config.AttachmentListStorage {
+		c.logger.Info("Attachment lists are read from PostgreSQL", "source", cfg.Metadata.ListSource)
+	}
+
+	// Initialize services
+	c.events = bus.New(c.logger)
+	c.policyService = service.NewCoursePolicyService(policyRepo, cfg.Comments, c.logger)
+	c.feedbackService = service.NewFeedbackService(service.FeedbackServiceDeps{
+		FeedbackRepo:    feedbackRepo,
+		AttachmentRepo:  attachmentRepo,
+		AssetRepo:       assetRepo,
+		AuditRepo:       auditRepo,
+		SessionRepo:     sessionRepo,
+		IntentRepo:      intentRepo,
+		BackfillJournal: repository.NewBackfillJournalRepository(db),
+		SealRepo:        repository.NewSealRepository(db),
+		OutboxRepo:      repository.NewContentOutboxRepository(db),
+		CommentRepo:     commentRepo,
+		Policies:        c.policyService,
+		Upstream:        service.NewUpstreamValidator(cfg.Upstream, c.upstream.submissions, c.upstream.users, c.logger),
+		Events:          c.events,
+		DeletionCfg:     cfg.Deletion,
+		PrefetchCfg:     cfg.Prefetch,
+		DashboardCfg:    cfg.Dashboard,
+		MetadataCfg:     cfg.Metadata,
+		OutboxCfg:       cfg.Outbox,
+		ArchiveCfg:      cfg.Archive,
+		EventsCfg:       cfg.Events,
+		Limits:          cfg.Feedback,
+		IdempotencyTTL:  cfg.Retention.IdempotencyKeys,
+		Logger:          c.logger,
+	})
+	c.commentService = service.NewCommentService(commentRepo, cfg.Comments, c.policyService, c.feedbackService, c.events, c.logger)
+	c.templateService = service.NewTemplateService(repository.NewTemplateRepository(db), cfg.Feedback, c.logger)
+	c.permissionService = service.NewPermissionService(feedbackRepo, commentRepo, c.policyService, cfg.Comments, c.logger)
+	c.commentRenderer = render.NewRenderer(cfg.Render.MaxOutputBytes, cfg.Render.CacheEntries)
+	c.pageTokens = pagetoken.NewCodec([]byte(cfg.PageToken.Secret), cfg.PageToken.TTL)
+	if err := service.SubscribeSideEffects(c.events, c.feedbackService, c.commentRenderer); err != nil {
+		return err
+	}
+	if cfg.Webhook.EndpointsFile != "" {
+		c.webhooks = webhook.New(cfg.Webhook, c.logger)
+		if err := c.webhooks.Load(cfg.Webhook.EndpointsFile); err != nil {
+			return fmt.Errorf("failed to load webhook endpoints: %w", err)
+		}
+		if err := c.webhooks.Subscribe(c.events); err != nil {
+			return err
+		}
+	}
+	c.transferAccountant = service.NewTransferAccountant(transferRepo, cfg.Transfer, c.logger)
+	c.retentionService = service.NewRetentionService(retentionRepo, attachmentRepo, cfg.Retention, c.logger)
+	c.elector = leader.New(db, cfg.Leader, c.logger)
+
+	// Background workers outlive the startup context and stop with the component. Transfer
+	// counters and comment streams are per replica; the other workers are singletons run by the
+	// elected leader.
+	workerCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.workers.Add(6)
+	go func() {
+		defer c.workers.Done()
+		c.transferAccountant.Run(workerCtx) // flush transfer counters periodically
+	}()
+	go func() {
+		defer c.workers.Done()
+		c.commentService.RunCommentWatch(workerCtx) // feed this replica's WatchComments streams
+	}()
+	go func() {
+		defer c.workers.Done()
+		c.elector.RunWhileLeader(workerCtx, "retention", c.retentionService.Run) // prune expired maintenance data periodically
+	}()
+	go func() {
+		defer c.workers.Done()
+		c.elector.RunWhileLeader(workerCtx, "deletion-recovery", c.feedbackService.RunDeletionRecovery) // finish interrupted feedback deletions
+	}()
+	go func() {
+		defer c.workers.Done()
+		c.elector.RunWhileLeader(workerCtx, "content-outbox", c.feedbackService.RunContentOutbox) // write feedback content MongoDB missed
+	}()
+	go func() {
+		defer c.workers.Done()
+		c.elector.RunWhileLeader(workerCtx, "archiver", c.feedbackService.RunArchiver) // archive feedbacks past FEEDBACK_ARCHIVE_AFTER
+	}()
+
+	// Every replica delivers the webhooks of its own events. SIGHUP reloads the endpoints, so
+	// signing keys rotate without a restart.
+	if c.webhooks != nil {
+		c.hup = make(chan os.Signal, 1)
+		signal.Notify(c.hup, syscall.SIGHUP)
+		c.workers.Add(2)
+		go func() {
+			defer c.workers.Done()
+			c.webhooks.Run(workerCtx)
+		}()
+		go func() {
+			defer c.workers.Done()
+			c.webhooks.Watch(workerCtx, cfg.Webhook.EndpointsFile, c.hup)
+		}()
+	}
+
+	return nil
+}
+
+// Stop waits for the workers to finish their current batch, delivers queued events, then
+// persists transfer counters accumulated since the last flush
+func (c *servicesComponent) Stop(ctx context.Context) error {
+	c.cancel()
+	c.workers.Wait()
+
+	if err := c.events.Close(ctx); err != nil {
+		c.logger.Warn("Failed to drain event subscribers", "error", err)
+	}
+	if c.cfg.Migration.ReadsLegacy() {
+		c.logger.Info("Attachment reads served from the legacy location", "fallback_reads", c.attachmentRepo.FallbackReads())
+	}
+	if corrupt := c.feedbackService.CorruptAttachments(); corrupt > 0 {
+		c.logger.Warn("Attachment checksum mismatches detected", "corrupt_attachments", corrupt)
+	}
+	if c.webhooks != nil {
+		signal.Stop(c.hup)
+		for _, status := range c.webhooks.Statuses() {
+			c.logger.Info("Webhook deliveries",
+				"endpoint", status.Name,
+				"primary_key_id", status.PrimaryKeyID,
+				"delivered", status.Delivered,
+				"failed", status.Failed,
+				"dropped", status.Dropped,
+				"signed_by_key_id", status.Signed,
+			)
+		}
+	}
+
+	if err := c.transferAccountant.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush transfer usage: %w", err)
+	}
+	return nil
+}
+
+// grpcGracePeriod is how long in-flight RPCs may run after shutdown begins
+const grpcGracePeriod = 10 * time.Second
+
+// grpcComponent serves the gRPC API
+type grpcComponent struct {
+	port     string
+	logger   *slog.Logger
+	services *servicesComponent
+	fail     func(error)
+
+	server *grpc.Server
+}
+
+func (c *grpcComponent) Name() string { return "grpc" }
+
+func (c *grpcComponent) StopTimeout() time.Duration { return grpcGracePeriod + 5*time.Second }
+
+func (c *grpcComponent) Start(ctx context.Context) error {
+	// Create gRPC server with improved streaming error handling
+	c.server = grpc.NewServer(server.ServerOptions(c.services.flags, c.services.cfg.Keepalive)...)
+
+	// Register services
+	svc := c.services
+	server.RegisterFeedbackServer(c.server, server.FeedbackServerDeps{
+		FeedbackService: svc.feedbackService,
+		Transfers:       svc.transferAccountant,
+		Retention:       svc.retentionService,
+		Policies:        svc.policyService,
+		Permissions:     svc.permissionService,
+		Templates:       svc.templateService,
+		Notifications:   svc.notifications,
+		PageTokens:      svc.pageTokens,
+		Jobs:            svc.elector,
+		Flags:           svc.flags,
+		Downloads:       svc.cfg.Download,
+		Limits:          svc.cfg.Feedback,
+		Logger:          c.logger,
+	})
+	server.RegisterCommentServer(c.server, svc.commentService, svc.commentRenderer, svc.cfg.Comments.AcceptHexIDs, svc.cfg.Comments.ListContentMaxBytes, svc.pageTokens, c.logger)
+
+	// Create a new health server and register it
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(c.server, healthServer)
+	healthServer.SetServingStatus("feedback.FeedbackService", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("comment.CommentService", healthpb.HealthCheckResponse_SERVING)
+
+	// Enable reflection for easier debugging
+	reflection.Register(c.server)
+
+	listener, err := net.Listen("tcp", ":"+c.port)
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %s: %w", c.port, err)
+	}
+
+	c.logger.Info("Starting gRPC server", "port", c.port)
+	go func() {
+		if err := c.server.Serve(listener); err != nil {
+			c.fail(fmt.Errorf("failed to serve gRPC server: %w", err))
+		}
+	}()
+	return nil
+}
+
+// Stop drains in-flight RPCs, forcing the server down after the grace period
+func (c *grpcComponent) Stop(ctx context.Context) error {
+	// Event streams only end when their clients cancel them; end them so draining can finish
+	c.services.feedbackService.CloseEventStreams()
+	c.services.commentService.CloseCommentStreams()
+
+	done := make(chan struct{})
+	go func() {
+		c.server.GracefulStop()
+		close(done)
+	}()
+
+	grace := time.NewTimer(grpcGracePeriod)
+	defer grace.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-grace.C:
+		c.logger.Warn("Server shutdown timeout, forcing stop")
+		c.server.Stop()
+		return nil
+	}
+}
@@ -0,0 +1,551 @@
+// Command maintenance runs one-off maintenance tasks against the feedback service storage.
+//
+// Usage:
+//
+//	maintenance <task> [flags]
+//
+// Tasks:
+//
+//	backfill-comment-depth   compute the depth field of comments created before it was stored
+//	consistency-report       compare feedback rows with attachment prefixes in MinIO and print
+//	                         findings as JSON lines; flags: -full, -sample, -delete-orphans,
+//	                         -mark-reupload
+//	rewrap-attachment-keys   re-wrap the data keys of encrypted attachments with the active
+//	                         master key after a key rotation
+//	migrate-attachments      copy attachments from the legacy location to the current one,
+//	                         resuming from the journal; flags: -restart
+//	attachment-migration-status
+//	                         print the attachment migration progress as JSON
+//	verify-attachments       re-hash stored attachments and print checksum mismatches as JSON
+//	                         lines; flags: -feedback or -all, -sample-rate
+//	backfill-search-index    index the content of existing feedbacks for SearchFeedbacks and statistics
+//	backfill-attachment-metadata
+//	                         record feedback_assets rows for attachments stored before their
+//	                         metadata was, resuming from the journal; flags: -concurrency, -rate
+//	attachment-backfill-status
+//	                         print the attachment metadata backfill progress as JSON
+//	backfill-column          fill a feedbacks column that is being rolled out for the rows written
+//	                         before it; flags: -column, -batch-size
+//	seed                     generate synthetic feedbacks, attachments and comment threads and
+//	                         load them through the repositories; flags: -seed, -feedbacks,
+//	                         -reviewers, -students, -courses, -attachments, -attachment-bytes,
+//	                         -contents, -threads, -branching, -max-depth, -out, -no-load
+//	snapshot-scrub           anonymize an exported dataset, remapping IDs consistently, and
+//	                         optionally load it; flags: -in, -out, -seed, -load,
+//	                         -attachment-bytes
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/envelope"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/seed"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// task is a maintenance task run with the loaded configuration and connections
+type task func(ctx context.Context, env *environment) error
+
+// environment holds the connections shared by maintenance tasks
+type environment struct {
+	cfg     *config.Config
+	db      *sql.DB
+	mongodb *database.MongoDBClient
+	minio   *minio.Client
+	keyring *envelope.Keyring  // Attachment encryption keys; nil when encryption is disabled
+	schema  *repository.Schema // Shape of the feedbacks table
+	args    []string           // Arguments following the task name
+	logger  *slog.Logger
+}
+
+var tasks = map[string]task{
+	"backfill-comment-depth": backfillCommentDepth,
+	"consistency-report":     consistencyReport,
+	"rewrap-attachment-keys": rewrapAttachmentKeys,
+	"migrate-attachments":    migrateAttachments,
+	"verify-attachments":     verifyAttachments,
+	"backfill-search-index":  backfillSearchIndex,
+
+	"attachment-migration-status":  attachmentMigrationStatus,
+	"backfill-attachment-metadata": backfillAttachmentMetadata,
+	"attachment-backfill-status":   attachmentBackfillStatus,
+	"backfill-column":              backfillColumn,
+
+	"seed":           seedData,
+	"snapshot-scrub": snapshotScrub,
+}
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	run, ok := tasks[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown task %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Stop gracefully on interrupt; tasks check the context between batches
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.NewConnection(ctx, cfg.Database)
+	if err != nil {
+		logger.Error("Failed to connect to PostgreSQL", "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	mongodb, err := database.ConnectMongoDB(ctx, cfg.MongoDB)
+	if err != nil {
+		logger.Error("Failed to connect to MongoDB", "error", err)
+		os.Exit(1)
+	}
+	defer mongodb.Close(context.Background())
+
+	minioClient, err := minio.New(cfg.MinIO.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.MinIO.AccessKey, cfg.MinIO.SecretKey, ""),
+		Secure: cfg.MinIO.UseSSL,
+	})
+	if err != nil {
+		logger.Error("Failed to initialize MinIO client", "error", err)
+		os.Exit(1)
+	}
+
+	var keyring *envelope.Keyring
+	if cfg.Encryption.Enabled {
+		keyring, err = envelope.ParseKeyring(cfg.Encryption.MasterKeys, cfg.Encryption.ActiveKeyID)
+		if err != nil {
+			logger.Error("Failed to load attachment encryption keys", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	schema, err := repository.LoadSchema(ctx, db, cfg.Schema.DeferredWrites)
+	if err != nil {
+		logger.Error("Failed to load the feedbacks schema", "error", err)
+		os.Exit(1)
+	}
+
+	env := &environment{
+		cfg:     cfg,
+		db:      db,
+		mongodb: mongodb,
+		minio:   minioClient,
+		keyring: keyring,
+		schema:  schema,
+		args:    os.Args[2:],
+		logger:  logger,
+	}
+
+	logger.Info("Running maintenance task", "task", name)
+	if err := run(ctx, env); err != nil {
+		logger.Error("Maintenance task failed", "task", name, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("Maintenance task completed", "task", name)
+}
+
+// attachmentRepository creates the attachment repository for the configured locations
+func (env *environment) attachmentRepository() repository.AttachmentRepository {
+	location, legacy := repository.AttachmentLocations(env.cfg.MinIO, env.cfg.Migration)
+	return repository.NewAttachmentRepository(env.minio, location, legacy, env.cfg.MinIO.Endpoint, env.cfg.MinIO.UseSSL, env.keyring)
+}
+
+// usage prints the available tasks
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: maintenance <task> [flags]")
+	fmt.Fprintln(os.Stderr, "tasks:")
+	for name := range tasks {
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+	}
+}
+
+// backfillCommentDepth computes the depth of comments created before depth was stored
+func backfillCommentDepth(ctx context.Context, env *environment) error {
+	commentRepo := repository.NewCommentRepository(env.mongodb, env.cfg.MongoDB.Collection)
+	policyService := service.NewCoursePolicyService(repository.NewCoursePolicyRepository(env.db), env.cfg.Comments, env.logger)
+	commentService := service.NewCommentService(commentRepo, env.cfg.Comments, policyService, nil, nil, env.logger)
+
+	modified, err := commentService.BackfillCommentDepth(ctx)
+	if err != nil {
+		return err
+	}
+
+	env.logger.Info("Comment depth backfilled", "modified", modified)
+	return nil
+}
+
+// feedbackService creates the feedback service for tasks that run without an event bus or
+// upstream checks, since they create no feedback
+func (env *environment) feedbackService() *service.FeedbackService {
+	feedbackRepo := repository.NewFeedbackRepository(env.db, env.mongodb, env.schema)
+	assetRepo := repository.NewAssetRepository(env.db)
+	auditRepo := repository.NewAuditRepository(env.db)
+	sessionRepo := repository.NewUploadSessionRepository(env.db)
+	intentRepo := repository.NewDeletionIntentRepository(env.db)
+	policyService := service.NewCoursePolicyService(repository.NewCoursePolicyRepository(env.db), env.cfg.Comments, env.logger)
+	return service.NewFeedbackService(service.FeedbackServiceDeps{
+		FeedbackRepo:    feedbackRepo,
+		AttachmentRepo:  env.attachmentRepository(),
+		AssetRepo:       assetRepo,
+		AuditRepo:       auditRepo,
+		SessionRepo:     sessionRepo,
+		IntentRepo:      intentRepo,
+		BackfillJournal: repository.NewBackfillJournalRepository(env.db),
+		SealRepo:        repository.NewSealRepository(env.db),
+		OutboxRepo:      repository.NewContentOutboxRepository(env.db),
+		Policies:        policyService,
+		DeletionCfg:     env.cfg.Deletion,
+		PrefetchCfg:     env.cfg.Prefetch,
+		DashboardCfg:    env.cfg.Dashboard,
+		MetadataCfg:     env.cfg.Metadata,
+		OutboxCfg:       env.cfg.Outbox,
+		ArchiveCfg:      env.cfg.Archive,
+		EventsCfg:       env.cfg.Events,
+		Limits:          env.cfg.Feedback,
+		IdempotencyTTL:  env.cfg.Retention.IdempotencyKeys,
+		Logger:          env.logger,
+	})
+}
+
+// backfillSearchIndex indexes the content of feedbacks written before search was introduced
+func backfillSearchIndex(ctx context.Context, env *environment) error {
+	indexed, err := env.feedbackService().BackfillSearchIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	env.logger.Info("Feedback search index backfilled", "indexed", indexed)
+	return nil
+}
+
+// backfillColumn fills a rolling feedbacks column in batches, for the second phase of a column
+// rollout
+func backfillColumn(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("backfill-column", flag.ContinueOnError)
+	column := flags.String("column", "", "rolling column to fill")
+	batchSize := flags.Int("batch-size", 1000, "rows updated per statement")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+	if *column == "" {
+		return fmt.Errorf("-column is required")
+	}
+
+	updated, err := repository.BackfillColumn(ctx, env.db, *column, *batchSize, func(updated int64) {
+		env.logger.Info("Column backfill progress", "column", *column, "updated", updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	env.logger.Info("Column backfilled", "column", *column, "updated", updated)
+	return nil
+}
+
+// seedLoader creates the loader writing datasets through the service repositories
+func (env *environment) seedLoader(seedValue uint64, maxAttachmentBytes int64) *seed.Loader {
+	return seed.NewLoader(
+		repository.NewFeedbackRepository(env.db, env.mongodb, env.schema),
+		env.attachmentRepository(),
+		repository.NewAssetRepository(env.db),
+		repository.NewCommentRepository(env.mongodb, env.cfg.MongoDB.Collection),
+		seedValue, maxAttachmentBytes, env.logger,
+	)
+}
+
+// seedData generates a synthetic dataset for local and QA environments and loads it. The same
+// flags always generate the same dataset.
+func seedData(ctx context.Context, env *environment) error {
+	opts := seed.DefaultOptions()
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Uint64Var(&opts.Seed, "seed", opts.Seed, "random seed; the same seed generates the same data")
+	flags.IntVar(&opts.Feedbacks, "feedbacks", opts.Feedbacks, "number of feedbacks")
+	flags.IntVar(&opts.Reviewers, "reviewers", opts.Reviewers, "number of reviewers")
+	flags.IntVar(&opts.Students, "students", opts.Students, "number of students")
+	flags.IntVar(&opts.Courses, "courses", opts.Courses, "number of courses")
+	flags.Float64Var(&opts.Attachments, "attachments", opts.Attachments, "mean attachments per feedback")
+	flags.Int64Var(&opts.MaxAttachmentBytes, "attachment-bytes", opts.MaxAttachmentBytes, "largest attachment")
+	flags.IntVar(&opts.Contents, "contents", opts.Contents, "labs and articles commented on")
+	flags.IntVar(&opts.Threads, "threads", opts.Threads, "top-level comments")
+	flags.Float64Var(&opts.Branching, "branching", opts.Branching, "mean replies per comment")
+	flags.IntVar(&opts.MaxDepth, "max-depth", opts.MaxDepth, "deepest reply level")
+	out := flags.String("out", "", "also write the dataset to this file as JSON lines")
+	noLoad := flags.Bool("no-load", false, "only generate the dataset, without writing to storage")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+
+	dataset, err := seed.Generate(opts)
+	if err != nil {
+		return err
+	}
+	env.logger.Info("Dataset generated", "feedbacks", len(dataset.Feedbacks), "attachments", len(dataset.Attachments), "comments", len(dataset.Comments))
+	if *out != "" {
+		if err := writeDataset(*out, dataset); err != nil {
+			return err
+		}
+	}
+	if *noLoad {
+		return nil
+	}
+
+	summary, err := env.seedLoader(opts.Seed, opts.MaxAttachmentBytes).Load(ctx, dataset)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(summary)
+}
+
+// snapshotScrub anonymizes an exported dataset so it can leave production: text is replaced
+// with placeholder text of the same length and IDs are remapped consistently
+func snapshotScrub(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("snapshot-scrub", flag.ContinueOnError)
+	in := flags.String("in", "", "dataset to scrub, as JSON lines")
+	out := flags.String("out", "", "file the scrubbed dataset is written to")
+	seedValue := flags.Uint64("seed", 1, "random seed of the placeholder text and attachment content")
+	load := flags.Bool("load", false, "load the scrubbed dataset through the repositories")
+	maxAttachmentBytes := flags.Int64("attachment-bytes", 1024*1024, "largest attachment content written when loading")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+	if *in == "" || (*out == "" && !*load) {
+		return fmt.Errorf("-in and -out or -load are required")
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	dataset, err := seed.Read(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", *in, err)
+	}
+	scrubbed, err := seed.Scrub(dataset, *seedValue)
+	if err != nil {
+		return err
+	}
+	env.logger.Info("Dataset scrubbed", "feedbacks", len(scrubbed.Feedbacks), "attachments", len(scrubbed.Attachments), "comments", len(scrubbed.Comments))
+	if *out != "" {
+		if err := writeDataset(*out, scrubbed); err != nil {
+			return err
+		}
+	}
+	if !*load {
+		return nil
+	}
+
+	summary, err := env.seedLoader(*seedValue, *maxAttachmentBytes).Load(ctx, scrubbed)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(summary)
+}
+
+// writeDataset writes a dataset to a file as JSON lines
+func writeDataset(name string, dataset *seed.Dataset) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := dataset.Write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return file.Close()
+}
+
+// consistencyReport compares feedback rows in PostgreSQL with attachment prefixes in MinIO.
+// Findings and the final summary are written to stdout as JSON lines.
+func consistencyReport(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("consistency-report", flag.ContinueOnError)
+	var opts models.ConsistencyOptions
+	flags.BoolVar(&opts.FullScan, "full", false, "check every feedback instead of a sample")
+	flags.IntVar(&opts.SamplePercent, "sample", 0, "percentage of feedbacks to check when -full is not set (default 10)")
+	flags.BoolVar(&opts.DeleteOrphans, "delete-orphans", false, "remove objects under prefixes with no feedback row")
+	flags.BoolVar(&opts.MarkForReupload, "mark-reupload", false, "flag feedbacks with missing objects as needing re-upload")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	summary, err := env.feedbackService().CheckConsistency(ctx, opts, func(finding *models.ConsistencyFinding) error {
+		return out.Encode(map[string]any{"finding": finding})
+	})
+	if err != nil {
+		return err
+	}
+
+	return out.Encode(map[string]any{"summary": summary})
+}
+
+// rewrapAttachmentKeys re-wraps the data keys of encrypted attachments with the active master
+// key, so retired master keys can be removed from ATTACHMENT_ENCRYPTION_KEYS afterwards. The
+// summary is written to stdout as a JSON line.
+func rewrapAttachmentKeys(ctx context.Context, env *environment) error {
+	if env.keyring == nil {
+		return fmt.Errorf("ATTACHMENT_ENCRYPTION_ENABLED is not set")
+	}
+
+	attachmentRepo := env.attachmentRepository()
+	summary, err := attachmentRepo.RewrapKeys(ctx)
+	if summary != nil {
+		if encErr := json.NewEncoder(os.Stdout).Encode(map[string]any{"summary": summary}); encErr != nil && err == nil {
+			err = encErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d data keys could not be re-wrapped", summary.Failed)
+	}
+	return nil
+}
+
+// migrateAttachments copies attachments from the legacy location to the current one. Progress
+// is journaled in PostgreSQL, so an interrupted run resumes where it stopped; the final progress
+// is written to stdout as a JSON line.
+func migrateAttachments(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("migrate-attachments", flag.ContinueOnError)
+	restart := flags.Bool("restart", false, "list the legacy location again from the start")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+
+	migrationService := service.NewAttachmentMigrationService(env.attachmentRepository(), repository.NewMigrationJournalRepository(env.db), env.cfg.Migration, env.logger)
+	progress, err := migrationService.Run(ctx, *restart)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(map[string]any{"progress": progress}); err != nil {
+		return err
+	}
+	if progress.Failed > 0 {
+		return fmt.Errorf("%d attachments could not be migrated; run the task again to retry them", progress.Failed)
+	}
+	return nil
+}
+
+// attachmentMigrationStatus writes the journaled attachment migration progress to stdout as a
+// JSON line
+func attachmentMigrationStatus(ctx context.Context, env *environment) error {
+	migrationService := service.NewAttachmentMigrationService(env.attachmentRepository(), repository.NewMigrationJournalRepository(env.db), env.cfg.Migration, env.logger)
+	progress, err := migrationService.Progress(ctx)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(map[string]any{"progress": progress})
+}
+
+// attachmentBackfillService creates the attachment metadata backfill service
+func (env *environment) attachmentBackfillService() *service.AttachmentBackfillService {
+	return service.NewAttachmentBackfillService(env.attachmentRepository(), repository.NewAssetRepository(env.db), repository.NewBackfillJournalRepository(env.db), env.cfg.Metadata, env.logger)
+}
+
+// backfillAttachmentMetadata records feedback_assets rows for attachments stored in MinIO before
+// their metadata was recorded. Finished prefixes are journaled, so an interrupted run resumes
+// where it stopped; the final progress is written to stdout as a JSON line.
+func backfillAttachmentMetadata(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("backfill-attachment-metadata", flag.ContinueOnError)
+	flags.IntVar(&env.cfg.Metadata.BackfillConcurrency, "concurrency", env.cfg.Metadata.BackfillConcurrency, "prefixes backfilled in parallel")
+	flags.Int64Var(&env.cfg.Metadata.BackfillRequestsPerSecond, "rate", env.cfg.Metadata.BackfillRequestsPerSecond, "MinIO requests per second (0 means unlimited)")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+	if env.cfg.Metadata.BackfillConcurrency <= 0 || env.cfg.Metadata.BackfillRequestsPerSecond < 0 {
+		return fmt.Errorf("-concurrency must be positive and -rate must not be negative")
+	}
+
+	progress, err := env.attachmentBackfillService().Run(ctx)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(map[string]any{"progress": progress}); err != nil {
+		return err
+	}
+	if !progress.Complete {
+		return fmt.Errorf("%d prefixes could not be backfilled; run the task again to retry them", progress.PrefixesFailed)
+	}
+	return nil
+}
+
+// attachmentBackfillStatus writes the journaled attachment metadata backfill progress to stdout
+// as a JSON line
+func attachmentBackfillStatus(ctx context.Context, env *environment) error {
+	progress, err := env.attachmentBackfillService().Progress(ctx)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(os.Stdout).Encode(map[string]any{"progress": progress})
+}
+
+// verifyAttachments re-hashes stored attachments and compares them with the checksums recorded
+// at upload. Mismatches, attachments that cannot be read and the final summary are written to
+// stdout as JSON lines; mismatches are also written to the audit log.
+func verifyAttachments(ctx context.Context, env *environment) error {
+	flags := flag.NewFlagSet("verify-attachments", flag.ContinueOnError)
+	feedbackID := flags.String("feedback", "", "verify the attachments of this feedback")
+	all := flags.Bool("all", false, "verify the attachments of every feedback")
+	var opts models.AttachmentVerifyOptions
+	flags.Float64Var(&opts.SampleRate, "sample-rate", 1, "share of attachments to verify, between 0 and 1")
+	if err := flags.Parse(env.args); err != nil {
+		return err
+	}
+	if (*feedbackID == "") == !*all {
+		return fmt.Errorf("exactly one of -feedback and -all is required")
+	}
+	if *feedbackID != "" {
+		id, err := uuid.Parse(*feedbackID)
+		if err != nil {
+			return fmt.Errorf("invalid feedback ID: %w", err)
+		}
+		opts.FeedbackID = &id
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	summary, err := env.feedbackService().VerifyAttachments(ctx, opts, func(mismatch *models.AttachmentMismatch) error {
+		return out.Encode(map[string]any{"mismatch": mismatch})
+	})
+	if summary != nil {
+		if encErr := out.Encode(map[string]any{"summary": summary}); encErr != nil && err == nil {
+			err = encErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if summary.Mismatches > 0 {
+		return fmt.Errorf("%d attachments do not match their recorded checksum", summary.Mismatches)
+	}
+	return nil
+}
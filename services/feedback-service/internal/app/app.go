@@ -0,0 +1,240 @@
+// Package app runs the service as a set of components with an explicit lifecycle.
+//
+// Components are started in dependency order (registration order among independent
+// components) and stopped in reverse order, each with its own stop timeout. If a component
+// fails to start, the components already started are stopped again. Errors from every
+// stage are collected and returned together.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultStopTimeout bounds Stop for components that do not set their own timeout
+const DefaultStopTimeout = 10 * time.Second
+
+// Component is a part of the service with a lifecycle. Start must return once the component
+// is ready; long-running work continues in goroutines owned by the component until Stop.
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// StopTimeouter is implemented by components that need a stop timeout other than
+// DefaultStopTimeout
+type StopTimeouter interface {
+	StopTimeout() time.Duration
+}
+
+// Hook adapts a pair of functions to Component; either function may be nil
+type Hook struct {
+	HookName string
+	OnStart  func(ctx context.Context) error
+	OnStop   func(ctx context.Context) error
+}
+
+// Name returns the hook name
+func (h *Hook) Name() string { return h.HookName }
+
+// Start runs OnStart
+func (h *Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+// Stop runs OnStop
+func (h *Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+// registration is a component with the names of the components it depends on
+type registration struct {
+	component Component
+	dependsOn []string
+}
+
+// App starts, runs and stops registered components
+type App struct {
+	logger     *slog.Logger
+	components []registration
+
+	failOnce sync.Once
+	failed   chan struct{}
+	failErr  error
+}
+
+// New creates an empty application
+func New(logger *slog.Logger) *App {
+	return &App{
+		logger: logger,
+		failed: make(chan struct{}),
+	}
+}
+
+// Register adds a component that starts after the named components
+func (a *App) Register(component Component, dependsOn ...string) {
+	a.components = append(a.components, registration{component: component, dependsOn: dependsOn})
+}
+
+// Fail reports a fatal error from a running component, e.g. a server that stopped serving.
+// The first reported error shuts the application down and is returned by Run.
+func (a *App) Fail(err error) {
+	a.failOnce.Do(func() {
+		a.failErr = err
+		close(a.failed)
+	})
+}
+
+// order returns the components sorted so that every component follows its dependencies,
+// keeping registration order where there is no constraint
+func (a *App) order() ([]Component, error) {
+	byName := make(map[string]registration, len(a.components))
+	for _, reg := range a.components {
+		name := reg.component.Name()
+		if _, ok := byName[name]; ok {
+			return nil, fmt.Errorf("component %q registered twice", name)
+		}
+		byName[name] = reg
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(a.components))
+	ordered := make([]Component, 0, len(a.components))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		reg, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("component %q depends on unknown component %q", path[len(path)-1], name)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, name))
+		}
+
+		state[name] = visiting
+		for _, dep := range reg.dependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, reg.component)
+		return nil
+	}
+
+	for _, reg := range a.components {
+		if err := visit(reg.component.Name(), nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Start starts all components in dependency order. If a component fails, the components
+// started before it are stopped in reverse order and all errors are returned. On success
+// the returned stop function stops every component in reverse order.
+func (a *App) Start(ctx context.Context) (stop func() error, err error) {
+	ordered, err := a.order()
+	if err != nil {
+		return nil, err
+	}
+
+	var started []Component
+	for _, component := range ordered {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Join(fmt.Errorf("startup interrupted: %w", err), a.stopAll(started))
+		}
+
+		begin := time.Now()
+		a.logger.Info("Starting component", "component", component.Name())
+		if err := component.Start(ctx); err != nil {
+			a.logger.Error("Component failed to start", "component", component.Name(), "error", err)
+			startErr := fmt.Errorf("start %s: %w", component.Name(), err)
+			return nil, errors.Join(startErr, a.stopAll(started))
+		}
+		a.logger.Info("Component started", "component", component.Name(), "duration", time.Since(begin))
+		started = append(started, component)
+	}
+
+	return func() error { return a.stopAll(started) }, nil
+}
+
+// stopAll stops components in reverse order, giving each its own timeout, and joins the errors
+func (a *App) stopAll(started []Component) error {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := a.stopComponent(started[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopComponent stops one component within its timeout. A component that does not return in
+// time is reported and left behind so the remaining components can still stop.
+func (a *App) stopComponent(component Component) error {
+	timeout := DefaultStopTimeout
+	if t, ok := component.(StopTimeouter); ok && t.StopTimeout() > 0 {
+		timeout = t.StopTimeout()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	a.logger.Info("Stopping component", "component", component.Name())
+	done := make(chan error, 1)
+	go func() {
+		done <- component.Stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			a.logger.Error("Component failed to stop", "component", component.Name(), "error", err)
+			return fmt.Errorf("stop %s: %w", component.Name(), err)
+		}
+		a.logger.Info("Component stopped", "component", component.Name())
+		return nil
+	case <-ctx.Done():
+		a.logger.Error("Component stop timed out", "component", component.Name(), "timeout", timeout)
+		return fmt.Errorf("stop %s: timed out after %s", component.Name(), timeout)
+	}
+}
+
+// Run starts all components, waits until ctx is cancelled or a component calls Fail, then
+// stops everything. It returns the start, failure and stop errors joined together.
+func (a *App) Run(ctx context.Context) error {
+	stop, err := a.Start(ctx)
+	if err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("Shutting down")
+	case <-a.failed:
+		a.logger.Error("Component failed, shutting down", "error", a.failErr)
+		runErr = a.failErr
+	}
+
+	return errors.Join(runErr, stop())
+}
@@ -0,0 +1,268 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the lifecycle calls of fake components in the order they happen
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// fakeComponent records its calls and fails or blocks as configured
+type fakeComponent struct {
+	name        string
+	rec         *recorder
+	startErr    error
+	stopErr     error
+	stopTimeout time.Duration
+	block       chan struct{} // Stop waits for it, ignoring its context, when set
+}
+
+func (c *fakeComponent) Name() string { return c.name }
+
+func (c *fakeComponent) Start(ctx context.Context) error {
+	c.rec.add("start " + c.name)
+	return c.startErr
+}
+
+func (c *fakeComponent) Stop(ctx context.Context) error {
+	c.rec.add("stop " + c.name)
+	if c.block != nil {
+		<-c.block
+	}
+	return c.stopErr
+}
+
+func (c *fakeComponent) StopTimeout() time.Duration { return c.stopTimeout }
+
+func newTestApp() *App {
+	return New(slog.New(slog.DiscardHandler))
+}
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		name       string
+		components func(rec *recorder) []*fakeComponent
+		dependsOn  map[string][]string
+		wantErr    string
+		wantEvents []string
+		wantStop   []string // Events of the returned stop function
+	}{
+		{
+			name: "registration order",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{{name: "a", rec: rec}, {name: "b", rec: rec}, {name: "c", rec: rec}}
+			},
+			wantEvents: []string{"start a", "start b", "start c"},
+			wantStop:   []string{"stop c", "stop b", "stop a"},
+		},
+		{
+			name: "dependencies first",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{{name: "server", rec: rec}, {name: "database", rec: rec}, {name: "cache", rec: rec}}
+			},
+			dependsOn:  map[string][]string{"server": {"cache"}, "cache": {"database"}},
+			wantEvents: []string{"start database", "start cache", "start server"},
+			wantStop:   []string{"stop server", "stop cache", "stop database"},
+		},
+		{
+			name: "failure mid-sequence stops the started components in reverse",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{
+					{name: "a", rec: rec},
+					{name: "b", rec: rec},
+					{name: "c", rec: rec, startErr: errors.New("port in use")},
+					{name: "d", rec: rec},
+				}
+			},
+			wantErr:    "start c: port in use",
+			wantEvents: []string{"start a", "start b", "start c", "stop b", "stop a"},
+		},
+		{
+			name: "stop errors are joined to the start error",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{
+					{name: "a", rec: rec, stopErr: errors.New("flush failed")},
+					{name: "b", rec: rec},
+					{name: "c", rec: rec, startErr: errors.New("port in use")},
+				}
+			},
+			wantErr:    "start c: port in use\nstop a: flush failed",
+			wantEvents: []string{"start a", "start b", "start c", "stop b", "stop a"},
+		},
+		{
+			name: "unknown dependency",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{{name: "a", rec: rec}}
+			},
+			dependsOn: map[string][]string{"a": {"missing"}},
+			wantErr:   `component "a" depends on unknown component "missing"`,
+		},
+		{
+			name: "dependency cycle",
+			components: func(rec *recorder) []*fakeComponent {
+				return []*fakeComponent{{name: "a", rec: rec}, {name: "b", rec: rec}}
+			},
+			dependsOn: map[string][]string{"a": {"b"}, "b": {"a"}},
+			wantErr:   "dependency cycle: [a b a]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			a := newTestApp()
+			for _, component := range tt.components(rec) {
+				a.Register(component, tt.dependsOn[component.name]...)
+			}
+
+			stop, err := a.Start(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Start() error = %v, want %q", err, tt.wantErr)
+				}
+				if stop != nil {
+					t.Error("Start() returned a stop function with an error")
+				}
+			} else if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if got := rec.list(); !slices.Equal(got, tt.wantEvents) {
+				t.Errorf("Start() events = %v, want %v", got, tt.wantEvents)
+			}
+			if stop == nil {
+				return
+			}
+
+			if err := stop(); err != nil {
+				t.Fatalf("stop() error = %v", err)
+			}
+			if got := rec.list()[len(tt.wantEvents):]; !slices.Equal(got, tt.wantStop) {
+				t.Errorf("stop() events = %v, want %v", got, tt.wantStop)
+			}
+		})
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	rec := &recorder{}
+	block := make(chan struct{})
+	defer close(block)
+
+	a := newTestApp()
+	a.Register(&fakeComponent{name: "a", rec: rec})
+	a.Register(&fakeComponent{name: "stuck", rec: rec, block: block, stopTimeout: 20 * time.Millisecond})
+	a.Register(&fakeComponent{name: "c", rec: rec})
+
+	stop, err := a.Start(context.Background())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	begin := time.Now()
+	err = stop()
+	if err == nil || !strings.Contains(err.Error(), "stop stuck: timed out after 20ms") {
+		t.Fatalf("stop() error = %v, want the stuck component to time out", err)
+	}
+	if elapsed := time.Since(begin); elapsed > DefaultStopTimeout/2 {
+		t.Errorf("stop() took %s, want the component's own timeout", elapsed)
+	}
+	// The stuck component is left behind; the ones after it in stop order still stop
+	want := []string{"start a", "start stuck", "start c", "stop c", "stop stuck", "stop a"}
+	if got := rec.list(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("context cancelled", func(t *testing.T) {
+		rec := &recorder{}
+		started := make(chan struct{})
+		a := newTestApp()
+		a.Register(&fakeComponent{name: "a", rec: rec})
+		a.Register(&Hook{HookName: "server", OnStart: func(context.Context) error {
+			rec.add("start server")
+			close(started)
+			return nil
+		}, OnStop: func(context.Context) error {
+			rec.add("stop server")
+			return nil
+		}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- a.Run(ctx) }()
+		<-started
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		want := []string{"start a", "start server", "stop server", "stop a"}
+		if got := rec.list(); !slices.Equal(got, want) {
+			t.Errorf("Run() events = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("cancelled during startup", func(t *testing.T) {
+		rec := &recorder{}
+		ctx, cancel := context.WithCancel(context.Background())
+		a := newTestApp()
+		a.Register(&Hook{HookName: "a", OnStart: func(context.Context) error {
+			rec.add("start a")
+			cancel()
+			return nil
+		}, OnStop: func(context.Context) error {
+			rec.add("stop a")
+			return nil
+		}})
+		a.Register(&fakeComponent{name: "b", rec: rec})
+
+		err := a.Run(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Run() error = %v, want %v", err, context.Canceled)
+		}
+		want := []string{"start a", "stop a"}
+		if got := rec.list(); !slices.Equal(got, want) {
+			t.Errorf("Run() events = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("component failure", func(t *testing.T) {
+		rec := &recorder{}
+		a := newTestApp()
+		a.Register(&fakeComponent{name: "a", rec: rec})
+		a.Register(&Hook{HookName: "server", OnStart: func(context.Context) error {
+			rec.add("start server")
+			go a.Fail(errors.New("listener closed"))
+			return nil
+		}})
+
+		err := a.Run(context.Background())
+		if err == nil || err.Error() != "listener closed" {
+			t.Fatalf("Run() error = %v, want the reported failure", err)
+		}
+		want := []string{"start a", "start server", "stop a"}
+		if got := rec.list(); !slices.Equal(got, want) {
+			t.Errorf("Run() events = %v, want %v", got, want)
+		}
+	})
+}
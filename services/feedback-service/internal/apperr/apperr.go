@@ -0,0 +1,209 @@
+// Package apperr defines the domain errors shared by the repository, service and transport
+// layers.
+//
+// An Error has a Kind, which decides the gRPC status code, a machine-readable Reason reported
+// in the status's ErrorInfo, and a Message that is safe to return to clients. The underlying
+// cause is kept for logging only. Repositories and services return these errors, possibly
+// wrapped with fmt.Errorf("...: %w"), and handlers convert them with ToStatus.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the ErrorInfo domain reported by this service
+const Domain = "feedback-service"
+
+// Kind classifies an error and decides its gRPC status code
+type Kind int
+
+// Error kinds
+const (
+	KindInternal           Kind = iota // A bug or an unexpected failure; details are not exposed
+	KindNotFound                       // The entity does not exist
+	KindAlreadyExists                  // The entity to create exists already
+	KindPermissionDenied               // The caller may not perform the operation
+	KindFailedPrecondition             // The entity is not in a state that allows the operation
+	KindInvalidInput                   // The request is malformed, regardless of state
+	KindUnavailable                    // A dependency is temporarily unreachable; retrying may help
+	KindDataLoss                       // Stored data is corrupted and cannot be served intact
+)
+
+// kindCodes maps error kinds to gRPC status codes
+var kindCodes = map[Kind]codes.Code{
+	KindInternal:           codes.Internal,
+	KindNotFound:           codes.NotFound,
+	KindAlreadyExists:      codes.AlreadyExists,
+	KindPermissionDenied:   codes.PermissionDenied,
+	KindFailedPrecondition: codes.FailedPrecondition,
+	KindInvalidInput:       codes.InvalidArgument,
+	KindUnavailable:        codes.Unavailable,
+	KindDataLoss:           codes.DataLoss,
+}
+
+// internalMessage is returned to clients for errors that are not domain errors
+const internalMessage = "internal error"
+
+// Error is a domain error
+type Error struct {
+	Kind     Kind
+	Reason   string            // Machine-readable reason, e.g. FEEDBACK_NOT_FOUND
+	Message  string            // Safe to return to clients
+	Metadata map[string]string // Reported in the ErrorInfo metadata
+	Err      error             // Underlying cause; logged, never returned to clients
+}
+
+// New creates a domain error
+func New(kind Kind, reason, message string) *Error {
+	return &Error{Kind: kind, Reason: reason, Message: message}
+}
+
+// NotFound creates an error for an entity that does not exist
+func NotFound(reason, message string) *Error {
+	return New(KindNotFound, reason, message)
+}
+
+// AlreadyExists creates an error for an entity that exists already
+func AlreadyExists(reason, message string) *Error {
+	return New(KindAlreadyExists, reason, message)
+}
+
+// PermissionDenied creates an error for an operation the caller may not perform
+func PermissionDenied(reason, message string) *Error {
+	return New(KindPermissionDenied, reason, message)
+}
+
+// FailedPrecondition creates an error for an entity whose state does not allow the operation
+func FailedPrecondition(reason, message string) *Error {
+	return New(KindFailedPrecondition, reason, message)
+}
+
+// InvalidInput creates an error for a malformed request
+func InvalidInput(reason, message string) *Error {
+	return New(KindInvalidInput, reason, message)
+}
+
+// InvalidField creates an InvalidInput error for one request field, with reason FIELD_INVALID
+// and the field name in the metadata
+func InvalidField(field, message string) *Error {
+	return InvalidInput("FIELD_INVALID", message).WithMetadata("field", field)
+}
+
+// Unavailable creates an error for a dependency that is temporarily unreachable
+func Unavailable(reason, message string) *Error {
+	return New(KindUnavailable, reason, message)
+}
+
+// DataLoss creates an error for stored data that is corrupted
+func DataLoss(reason, message string) *Error {
+	return New(KindDataLoss, reason, message)
+}
+
+// Internal creates an error for an unexpected failure
+func Internal(reason, message string) *Error {
+	return New(KindInternal, reason, message)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a domain error of the same kind and reason, so sentinel errors
+// match copies made with Wrap, WithMessage and WithMetadata
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Reason != "" && t.Kind == e.Kind && t.Reason == e.Reason
+}
+
+// clone returns a copy that can be changed without affecting e
+func (e *Error) clone() *Error {
+	c := *e
+	c.Metadata = maps.Clone(e.Metadata)
+	return &c
+}
+
+// Wrap returns a copy of e with cause as the underlying error
+func (e *Error) Wrap(cause error) *Error {
+	c := e.clone()
+	c.Err = cause
+	return c
+}
+
+// WithMessage returns a copy of e with a different client-safe message
+func (e *Error) WithMessage(message string) *Error {
+	c := e.clone()
+	c.Message = message
+	return c
+}
+
+// WithMetadata returns a copy of e with key set in the metadata
+func (e *Error) WithMetadata(key, value string) *Error {
+	c := e.clone()
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]string)
+	}
+	c.Metadata[key] = value
+	return c
+}
+
+// ToStatus converts an error into a gRPC status error:
+//   - errors that already carry a status are returned unchanged;
+//   - context cancellation and deadline errors become Canceled and DeadlineExceeded;
+//   - domain errors become their kind's code, with the safe message and an ErrorInfo detail;
+//   - anything else becomes Internal with a generic message.
+//
+// A nil error returns nil.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		code, ok := kindCodes[appErr.Kind]
+		if !ok {
+			code = codes.Internal
+		}
+		message := appErr.Message
+		if code == codes.Internal && message == "" {
+			message = internalMessage
+		}
+		st := status.New(code, message)
+		if appErr.Reason == "" {
+			return st.Err()
+		}
+		detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   appErr.Reason,
+			Domain:   Domain,
+			Metadata: appErr.Metadata,
+		})
+		if detailErr != nil {
+			return st.Err()
+		}
+		return detailed.Err()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request was cancelled by the client")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "request deadline exceeded")
+	}
+	return status.Error(codes.Internal, internalMessage)
+}
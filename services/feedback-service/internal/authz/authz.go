@@ -0,0 +1,286 @@
+// Package authz holds the authorization rules for feedbacks, their attachments and comments.
+//
+// Each rule is a predicate that returns nil when the principal may perform the action and a
+// domain error with the reason otherwise. The services call these predicates before mutating
+// anything, and GetPermissions evaluates the same ones to tell clients which actions are
+// available, so the two cannot disagree. A new rule belongs here, not in a service or handler.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+var (
+	// ErrNotFeedbackAuthor is returned when someone other than the author changes a feedback or its attachments
+	ErrNotFeedbackAuthor = apperr.PermissionDenied("NOT_FEEDBACK_AUTHOR", "access denied: only the feedback author can change its attachments")
+
+	// ErrFeedbackLocked is returned when a mutation targets a locked feedback
+	ErrFeedbackLocked = apperr.FailedPrecondition("FEEDBACK_LOCKED", "feedback is locked")
+
+	// ErrFeedbackLockDisabled is returned when a feedback's course policy does not allow locking
+	ErrFeedbackLockDisabled = apperr.FailedPrecondition("FEEDBACK_LOCK_DISABLED", "locking is disabled for this course")
+
+	// ErrNotFeedbackApprover is returned when someone other than the requested approver decides on a feedback
+	ErrNotFeedbackApprover = apperr.PermissionDenied("NOT_FEEDBACK_APPROVER", "access denied: only the requested approver can decide on this feedback")
+
+	// ErrSelfApproval is returned when a reviewer would approve their own feedback
+	ErrSelfApproval = apperr.PermissionDenied("SELF_APPROVAL", "reviewers cannot approve their own feedback")
+
+	// ErrInvalidApprovalTransition is returned when a feedback's approval status does not allow an approval action
+	ErrInvalidApprovalTransition = apperr.FailedPrecondition("INVALID_APPROVAL_TRANSITION", "approval status does not allow this action")
+
+	// ErrAdminRequired is returned when an action is reserved for administrators
+	ErrAdminRequired = apperr.PermissionDenied("ADMIN_REQUIRED", "administrator role required")
+
+	// ErrNotFeedbackStudent is returned when someone other than the feedback's student acknowledges it
+	ErrNotFeedbackStudent = apperr.PermissionDenied("NOT_FEEDBACK_STUDENT", "access denied: only the student the feedback is for can acknowledge it")
+
+	// ErrNotFeedbackParticipant is returned when someone other than a feedback's reviewer or student reads its history
+	ErrNotFeedbackParticipant = apperr.PermissionDenied("NOT_FEEDBACK_PARTICIPANT", "access denied: only the feedback's reviewer and student can see its history")
+
+	// ErrNotTemplateOwner is returned when a reviewer uses or changes another reviewer's feedback template
+	ErrNotTemplateOwner = apperr.PermissionDenied("NOT_TEMPLATE_OWNER", "access denied: templates can only be used by the reviewer who created them")
+
+	// ErrNotCommentAuthor is returned when someone other than the author changes a comment
+	ErrNotCommentAuthor = apperr.PermissionDenied("NOT_COMMENT_AUTHOR", "you can only change your own comments")
+
+	// ErrCommentHidden is returned when the author edits a comment a moderator hid
+	ErrCommentHidden = apperr.FailedPrecondition("COMMENT_HIDDEN", "comment was removed by a moderator")
+
+	// ErrCommentEditWindowClosed is returned when a comment is edited after its course's edit window
+	ErrCommentEditWindowClosed = apperr.FailedPrecondition("COMMENT_EDIT_WINDOW_CLOSED", "comment edit window has closed")
+)
+
+// Principal is the user an action is authorized for
+type Principal struct {
+	UserID int64
+	Admin  bool
+}
+
+// FromContext returns the principal of a request made on behalf of userID, with the role
+// taken from the request metadata
+func FromContext(ctx context.Context, userID int64) Principal {
+	return Principal{UserID: userID, Admin: middleware.IsAdmin(ctx)}
+}
+
+// Actions evaluated by GetPermissions
+const (
+	ActionUpdate             = "update"
+	ActionDelete             = "delete"
+	ActionPublish            = "publish"
+	ActionRequestApproval    = "request_approval"
+	ActionApprove            = "approve"
+	ActionLock               = "lock"
+	ActionUnlock             = "unlock"
+	ActionTransfer           = "transfer"
+	ActionUploadAttachment   = "upload_attachment"
+	ActionReorderAttachments = "reorder_attachments"
+	ActionAcknowledge        = "acknowledge"
+	ActionViewRevisions      = "view_revisions"
+	ActionReact              = "react"
+	ActionArchive            = "archive"
+	ActionViewEditHistory    = "view_edit_history"
+)
+
+// locked returns ErrFeedbackLocked with the lock reason of a feedback
+func locked(feedback *models.Feedback) error {
+	return ErrFeedbackLocked.WithMessage("feedback is locked: " + feedback.LockReason)
+}
+
+// modifyFeedback allows the author to change an unlocked feedback; denied explains the action
+func modifyFeedback(p Principal, feedback *models.Feedback, denied string) error {
+	if !feedback.CanModify(p.UserID) {
+		return ErrNotFeedbackAuthor.WithMessage(denied)
+	}
+	return Unlocked(feedback)
+}
+
+// Unlocked allows any change to a feedback that is not locked
+func Unlocked(feedback *models.Feedback) error {
+	if feedback.Locked {
+		return locked(feedback)
+	}
+	return nil
+}
+
+// UpdateFeedback allows the author to edit an unlocked feedback
+func UpdateFeedback(p Principal, feedback *models.Feedback) error {
+	return modifyFeedback(p, feedback, "access denied: only the feedback author can update it")
+}
+
+// DeleteFeedback allows the author to delete an unlocked feedback
+func DeleteFeedback(p Principal, feedback *models.Feedback) error {
+	return modifyFeedback(p, feedback, "access denied: only the feedback author can delete it")
+}
+
+// PublishFeedback allows the author to publish an unlocked draft. Publishing a published
+// feedback again is a no-op and allowed for the author even while it is locked.
+func PublishFeedback(p Principal, feedback *models.Feedback) error {
+	if feedback.IsPublished() && feedback.CanModify(p.UserID) {
+		return nil
+	}
+	return modifyFeedback(p, feedback, "access denied: only the feedback author can publish it")
+}
+
+// AcknowledgeFeedback allows the student a feedback is for to mark it as read, even while it
+// is locked
+func AcknowledgeFeedback(p Principal, feedback *models.Feedback) error {
+	if feedback.StudentID != p.UserID {
+		return ErrNotFeedbackStudent
+	}
+	return nil
+}
+
+// ReactToFeedback allows the student a feedback is for to rate it as helpful or not, even
+// while it is locked
+func ReactToFeedback(p Principal, feedback *models.Feedback) error {
+	if feedback.StudentID != p.UserID {
+		return ErrNotFeedbackStudent.WithMessage("access denied: only the student the feedback is for can react to it")
+	}
+	return nil
+}
+
+// ViewFeedbackRevisions allows the reviewer, and the student once they can see the feedback,
+// to read its edit history, even while it is locked
+func ViewFeedbackRevisions(p Principal, feedback *models.Feedback) error {
+	if feedback.ReviewerID == p.UserID {
+		return nil
+	}
+	if feedback.StudentID == p.UserID && feedback.IsVisibleToStudent() {
+		return nil
+	}
+	return ErrNotFeedbackParticipant
+}
+
+// approvalTransition returns ErrInvalidApprovalTransition unless the feedback may move to the
+// given approval status
+func approvalTransition(feedback *models.Feedback, to string) error {
+	if models.CanTransitionApproval(feedback.ApprovalStatus, to) {
+		return nil
+	}
+	return ErrInvalidApprovalTransition.
+		WithMessage(fmt.Sprintf("cannot move approval status from %s to %s", feedback.ApprovalStatus, to)).
+		WithMetadata("approval_status", feedback.ApprovalStatus)
+}
+
+// RequestFeedbackApproval allows the author to ask a second reviewer to approve an unlocked
+// feedback that is not already awaiting approval
+func RequestFeedbackApproval(p Principal, feedback *models.Feedback) error {
+	if err := modifyFeedback(p, feedback, "access denied: only the feedback author can request its approval"); err != nil {
+		return err
+	}
+	return approvalTransition(feedback, models.ApprovalPending)
+}
+
+// ReviewFeedbackApproval allows the requested approver to approve or request changes to an
+// unlocked feedback awaiting approval. Authors never decide on their own feedback.
+func ReviewFeedbackApproval(p Principal, feedback *models.Feedback) error {
+	if feedback.CanModify(p.UserID) {
+		return ErrSelfApproval
+	}
+	if !feedback.IsApprover(p.UserID) {
+		return ErrNotFeedbackApprover
+	}
+	if err := Unlocked(feedback); err != nil {
+		return err
+	}
+	return approvalTransition(feedback, models.ApprovalApproved)
+}
+
+// ChangeAttachments allows the author to upload, delete and reorder the attachments of an
+// unlocked feedback
+func ChangeAttachments(p Principal, feedback *models.Feedback) error {
+	return modifyFeedback(p, feedback, ErrNotFeedbackAuthor.Message)
+}
+
+// TransferFeedback allows the author or an administrator to hand an unlocked feedback over to
+// another reviewer. The requested approver of a pending feedback cannot take it over, since
+// they would then decide on their own feedback.
+func TransferFeedback(p Principal, feedback *models.Feedback, toReviewerID int64) error {
+	if !p.Admin {
+		if err := modifyFeedback(p, feedback, "access denied: only the feedback author or an administrator can transfer it"); err != nil {
+			return err
+		}
+	} else if err := Unlocked(feedback); err != nil {
+		return err
+	}
+	if feedback.ApprovalStatus == models.ApprovalPending && feedback.IsApprover(toReviewerID) {
+		return ErrSelfApproval.WithMessage("the requested approver cannot take over the feedback they are to approve")
+	}
+	return nil
+}
+
+// ArchiveFeedback allows the author or an administrator to archive a feedback or restore it,
+// even while it is locked, since archiving leaves the feedback itself unchanged
+func ArchiveFeedback(p Principal, feedback *models.Feedback) error {
+	if !p.Admin && !feedback.CanModify(p.UserID) {
+		return ErrNotFeedbackAuthor.WithMessage("access denied: only the feedback author or an administrator can archive it")
+	}
+	return nil
+}
+
+// LockFeedback allows administrators to lock a feedback if its course policy allows locking.
+// Locking a locked feedback again is a no-op and always allowed.
+func LockFeedback(p Principal, feedback *models.Feedback, policy models.EffectivePolicy) error {
+	if !p.Admin {
+		return ErrAdminRequired
+	}
+	if !feedback.Locked && !policy.AllowFeedbackLock {
+		return ErrFeedbackLockDisabled.WithMetadata("course_id", feedback.CourseID)
+	}
+	return nil
+}
+
+// UnlockFeedback allows administrators to lift a lock, regardless of the course policy
+func UnlockFeedback(p Principal) error {
+	if !p.Admin {
+		return ErrAdminRequired
+	}
+	return nil
+}
+
+// UpdateComment allows the author to edit a comment within the edit window of its course, unless
+// a moderator hid it
+func UpdateComment(p Principal, comment *models.Comment, policy models.EffectivePolicy, now time.Time) error {
+	if comment.UserID != p.UserID {
+		return ErrNotCommentAuthor.WithMessage("you can only update your own comments")
+	}
+	if comment.IsHidden() {
+		return ErrCommentHidden
+	}
+	if policy.CommentEditWindow > 0 && now.Sub(comment.CreatedAt) > policy.CommentEditWindow {
+		return ErrCommentEditWindowClosed.WithMessage(fmt.Sprintf("comment edit window has closed: comments can be edited for %s after posting", policy.CommentEditWindow))
+	}
+	return nil
+}
+
+// DeleteComment allows the author to delete a comment and its replies
+func DeleteComment(p Principal, comment *models.Comment) error {
+	if comment.UserID != p.UserID {
+		return ErrNotCommentAuthor.WithMessage("you can only delete your own comments")
+	}
+	return nil
+}
+
+// ViewCommentEditHistory allows the author and administrators, who moderate comments, to read
+// the previous versions of a comment
+func ViewCommentEditHistory(p Principal, comment *models.Comment) error {
+	if comment.UserID != p.UserID && !p.Admin {
+		return ErrNotCommentAuthor.WithMessage("only the comment author and moderators can see its edit history")
+	}
+	return nil
+}
+
+// UseTemplate allows the reviewer who created a feedback template to use, change and delete it
+func UseTemplate(p Principal, template *models.FeedbackTemplate) error {
+	if template.ReviewerID != p.UserID {
+		return ErrNotTemplateOwner
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Package bus is an in-process publish/subscribe bus for "something happened" signals.
+//
+// Services publish events after their changes are persisted; side effects such as cache
+// eviction or notifications subscribe to them instead of being called from the service
+// methods. Publishing is synchronous: when Publish returns, the event is queued for every
+// subscriber of the topic. Delivery is asynchronous: each subscriber has its own bounded queue
+// and goroutine, so it sees the events of a topic in publish order, and a slow or panicking
+// subscriber holds up neither the others nor the publisher (unless it uses the Block policy).
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Topic is a typed event channel. Topics are identified by name, so each name must be used
+// with a single event type.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic creates a topic
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+// Name returns the topic name
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+// Policy decides what Publish does when a subscriber's queue is full
+type Policy int
+
+const (
+	// DropNewest discards the event for this subscriber and counts it as dropped
+	DropNewest Policy = iota
+	// Block waits until the subscriber has room, or drops the event once the publisher's
+	// context ends. Only for subscribers that must see every event and keep up with them.
+	Block
+)
+
+// defaultQueueSize is the queue size of subscribers that do not set one
+const defaultQueueSize = 256
+
+// SubscribeOptions configures a subscription
+type SubscribeOptions struct {
+	Name      string // Used in logs
+	QueueSize int    // Events buffered for the subscriber (default 256)
+	Policy    Policy
+}
+
+// Bus delivers published events to subscribers. It is safe for concurrent use.
+type Bus struct {
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	subs   map[string][]*subscriber
+	closed bool
+
+	workerCtx context.Context // Passed to handlers; canceled if Close gives up draining
+	cancel    context.CancelFunc
+	workers   sync.WaitGroup
+}
+
+// New creates a bus
+func New(logger *slog.Logger) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bus{
+		logger:    logger,
+		subs:      make(map[string][]*subscriber),
+		workerCtx: ctx,
+		cancel:    cancel,
+	}
+}
+
+// subscriber is one subscription with its queue
+type subscriber struct {
+	name    string
+	topic   string
+	policy  Policy
+	queue   chan any
+	deliver func(ctx context.Context, event any)
+	dropped atomic.Int64
+}
+
+// Subscription reports on a subscriber
+type Subscription struct {
+	sub *subscriber
+}
+
+// Dropped returns how many events were discarded because the subscriber's queue was full
+func (s *Subscription) Dropped() int64 {
+	return s.sub.dropped.Load()
+}
+
+// Subscribe registers handler for the events of topic. The handler runs on the subscriber's
+// own goroutine, one event at a time; a panic is logged and delivery continues with the next
+// event. Its context is independent of the publishing request. Subscribing to a closed bus
+// fails.
+func Subscribe[T any](b *Bus, topic Topic[T], opts SubscribeOptions, handler func(ctx context.Context, event T)) (*Subscription, error) {
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	sub := &subscriber{
+		name:   opts.Name,
+		topic:  topic.name,
+		policy: opts.Policy,
+		queue:  make(chan any, queueSize),
+		deliver: func(ctx context.Context, event any) {
+			handler(ctx, event.(T))
+		},
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, fmt.Errorf("bus is closed")
+	}
+	b.subs[topic.name] = append(b.subs[topic.name], sub)
+
+	b.workers.Add(1)
+	go b.run(sub)
+	return &Subscription{sub: sub}, nil
+}
+
+// Publish queues event for every subscriber of topic and returns once it is queued (or
+// dropped, see Policy). Events published after Close are discarded. A nil bus discards
+// everything, so services can run without one.
+func Publish[T any](ctx context.Context, b *Bus, topic Topic[T], event T) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	for _, sub := range b.subs[topic.name] {
+		select {
+		case sub.queue <- event:
+			continue
+		default:
+		}
+
+		if sub.policy == Block {
+			select {
+			case sub.queue <- event:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		if sub.dropped.Add(1) == 1 {
+			b.logger.Warn("Event subscriber queue full, dropping events", "topic", topic.name, "subscriber", sub.name)
+		}
+	}
+}
+
+// run delivers queued events to a subscriber until its queue is closed and drained
+func (b *Bus) run(sub *subscriber) {
+	defer b.workers.Done()
+	for event := range sub.queue {
+		b.deliver(sub, event)
+	}
+}
+
+// deliver calls the subscriber's handler, isolating panics
+func (b *Bus) deliver(sub *subscriber, event any) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Event subscriber panicked", "topic", sub.topic, "subscriber", sub.name, "panic", r)
+		}
+	}()
+	sub.deliver(b.workerCtx, event)
+}
+
+// Close stops accepting events and waits until subscribers have handled everything already
+// queued. If ctx ends first, the handlers' context is canceled and Close returns ctx's error
+// without waiting further.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, subs := range b.subs {
+		for _, sub := range subs {
+			close(sub.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		b.cancel()
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return fmt.Errorf("event subscribers did not drain: %w", ctx.Err())
+	}
+}
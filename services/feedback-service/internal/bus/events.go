@@ -0,0 +1,62 @@
+package bus
+
+import (
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// Events carry copies or pointers of persisted models; subscribers must not modify them.
+
+// FeedbackEvent is published when a feedback is created, updated, published or its approval
+// status changes
+type FeedbackEvent struct {
+	Feedback *models.Feedback
+}
+
+// FeedbackDeletedEvent is published when a feedback is soft or hard deleted
+type FeedbackDeletedEvent struct {
+	FeedbackID uuid.UUID
+	ActorID    int64
+	Purged     bool // Hard deleted together with all of its data
+}
+
+// AttachmentEvent is published when an attachment is uploaded or deleted
+type AttachmentEvent struct {
+	FeedbackID uuid.UUID
+	Attachment models.AttachmentInfo // Only Filename is set for deletions
+}
+
+// AttachmentProgressEvent reports how far an attachment upload to a feedback got. Progress
+// events are ephemeral: they describe an upload in flight and are neither persisted nor
+// replayed. AttachmentUploaded still follows a completed upload.
+type AttachmentProgressEvent struct {
+	Feedback *models.Feedback // As it was when the upload started
+	Progress models.UploadProgress
+}
+
+// CommentEvent is published when a comment is created or updated
+type CommentEvent struct {
+	Comment *models.Comment
+}
+
+// CommentDeletedEvent is published when a comment and its replies are deleted
+type CommentDeletedEvent struct {
+	CommentID string // Stored hex ID
+}
+
+// Topics published by the services
+var (
+	FeedbackCreated           = NewTopic[FeedbackEvent]("feedback.created")
+	FeedbackUpdated           = NewTopic[FeedbackEvent]("feedback.updated")
+	FeedbackPublished         = NewTopic[FeedbackEvent]("feedback.published")
+	FeedbackApprovalRequested = NewTopic[FeedbackEvent]("feedback.approval_requested")
+	FeedbackApproved          = NewTopic[FeedbackEvent]("feedback.approved")
+	FeedbackChangesRequested  = NewTopic[FeedbackEvent]("feedback.changes_requested")
+	FeedbackDeleted           = NewTopic[FeedbackDeletedEvent]("feedback.deleted")
+	AttachmentUploaded        = NewTopic[AttachmentEvent]("attachment.uploaded")
+	AttachmentDeleted         = NewTopic[AttachmentEvent]("attachment.deleted")
+	AttachmentUploadProgress  = NewTopic[AttachmentProgressEvent]("attachment.upload_progress")
+	CommentCreated            = NewTopic[CommentEvent]("comment.created")
+	CommentUpdated            = NewTopic[CommentEvent]("comment.updated")
+	CommentDeleted            = NewTopic[CommentDeletedEvent]("comment.deleted")
+)
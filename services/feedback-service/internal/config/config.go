@@ -4,19 +4,45 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Constants for attachment limits
 const (
-	MaxAttachmentsPerFeedback = 5
+	MaxAttachmentsPerFeedback     = 5
+	MaxAttachmentBytesPerFeedback = 50 * 1024 * 1024 // Combined size checked when an upload session is committed
 )
 
 // Config represents the application configuration
 type Config struct {
-	GRPCPort string
-	Database DatabaseConfig
-	MongoDB  MongoDBConfig
-	MinIO    MinIOConfig
+	GRPCPort     string
+	Keepalive    KeepaliveConfig
+	Database     DatabaseConfig
+	MongoDB      MongoDBConfig
+	MinIO        MinIOConfig
+	Encryption   EncryptionConfig
+	Transfer     TransferConfig
+	PageToken    PageTokenConfig
+	Feedback     FeedbackConfig
+	Comments     CommentConfig
+	Download     DownloadConfig
+	Render       RenderConfig
+	Notification NotificationConfig
+	Retention    RetentionConfig
+	Prefetch     PrefetchConfig
+	Dashboard    DashboardConfig
+	Migration    MigrationConfig
+	Deletion     DeletionConfig
+	Outbox       OutboxConfig
+	Archive      ArchiveConfig
+	Events       EventStreamConfig
+	Upstream     UpstreamConfig
+	Webhook      WebhookConfig
+	Leader       LeaderConfig
+	Flags        FlagsConfig
+	Metadata     AttachmentMetadataConfig
+	Schema       SchemaConfig
 }
 
 // DatabaseConfig represents PostgreSQL database configuration (for feedback metadata)
@@ -41,14 +67,230 @@ type MinIOConfig struct {
 	AccessKey    string
 	SecretKey    string
 	BucketName   string
+	ObjectPrefix string // Prepended to every object key; empty or ending with "/"
 	UseSSL       bool
 	CreateBucket bool
 }
 
+// EncryptionConfig represents client-side envelope encryption of attachments
+type EncryptionConfig struct {
+	Enabled     bool   // Encrypt new attachments; attachments stored before stay readable
+	MasterKeys  string // Comma-separated "id:base64key" pairs of 32-byte master keys
+	ActiveKeyID string // Master key that wraps the data keys of new attachments
+}
+
+// TransferConfig represents per-principal daily transfer accounting configuration
+type TransferConfig struct {
+	SoftDailyBytes int64         // Log a warning once a principal exceeds this many bytes per day (0 disables)
+	HardDailyBytes int64         // Reject transfers beyond this many bytes per day (0 disables)
+	FlushInterval  time.Duration // How often in-memory counters are flushed to PostgreSQL
+}
+
+// PageTokenConfig represents the signing configuration for list page tokens
+type PageTokenConfig struct {
+	Secret string        // HMAC secret used to sign page tokens
+	TTL    time.Duration // How long an issued page token stays valid
+}
+
+// MaxFeedbackTitleLength is the most FeedbackConfig.MaxTitleLength may be: feedbacks.title is
+// VARCHAR(255)
+const MaxFeedbackTitleLength = 255
+
+// FeedbackConfig represents the limits on the text of feedbacks and feedback templates
+type FeedbackConfig struct {
+	MaxTitleLength   int // Longest accepted title, in characters
+	MaxContentLength int // Largest accepted Markdown content, in bytes
+}
+
+// CommentConfig represents comment thread limits
+type CommentConfig struct {
+	MaxDepth   int32         // Deepest allowed reply level; top-level comments have depth 0
+	EditWindow time.Duration // How long after creation a comment can be edited (0 means no limit)
+
+	AcceptHexIDs bool // Accept bare hex comment IDs in requests during their deprecation window
+
+	ListContentMaxBytes int // Comment content above this size is truncated in list responses
+	MaxContentChars     int // Longest content accepted by CreateComment and UpdateComment, in characters
+
+	WatchBufferSize   int           // Events queued per WatchComments stream before it is dropped as lagging
+	WatchPollInterval time.Duration // How often changes are polled for when MongoDB has no change streams
+	WatchReplayWindow time.Duration // How long events are kept for WatchComments streams resuming with resume_after
+}
+
+// DownloadConfig represents per-stream attachment download bandwidth limits (0 means unlimited)
+type DownloadConfig struct {
+	InternalBytesPerSecond int64 // Limit for internal services
+	ExternalBytesPerSecond int64 // Limit for all other callers
+}
+
+// RenderConfig represents limits for server-side rendering of comment previews
+type RenderConfig struct {
+	MaxOutputBytes int // Rendered output above this size is truncated
+	CacheEntries   int // Number of rendered comments kept in memory (0 disables caching)
+}
+
+// NotificationConfig represents rendering of feedback notification bodies
+type NotificationConfig struct {
+	TemplateDir     string // Directory whose templates replace the built-in ones of the same name (empty uses only the built-in ones)
+	MaxContentChars int    // Feedback content longer than this is shortened with a "view more" link
+}
+
+// RetentionConfig represents how long maintenance data is kept before the pruner removes it
+type RetentionConfig struct {
+	Interval        time.Duration // How often the pruner runs (0 disables the periodic pruner)
+	BatchSize       int           // Rows deleted per statement
+	UploadSessions  time.Duration // Committed and aborted upload sessions, by last activity (0 keeps them forever)
+	AuditLog        time.Duration // Feedback audit log entries (0 keeps them forever)
+	TransferUsage   time.Duration // Daily transfer counters (0 keeps them forever)
+	IdempotencyKeys time.Duration // CreateFeedback idempotency keys, by feedback creation (0 keeps them forever)
+}
+
+// PrefetchConfig represents the cache filled by list prefetching
+type PrefetchConfig struct {
+	CacheTTL     time.Duration // How long a prefetched page may be served (0 disables prefetching)
+	CacheEntries int           // Number of prefetched pages kept in memory
+	Timeout      time.Duration // Upper bound for one background prefetch
+}
+
+// DashboardConfig represents the enrichment of reviewer dashboard rows
+type DashboardConfig struct {
+	AttachmentsTTL time.Duration // How long cached attachment counts are served (0 disables caching)
+	CacheEntries   int           // Rows cached per enrichment source
+	SourceTimeout  time.Duration // Upper bound for one enrichment source; slower sources are reported degraded
+	PreviewChars   int           // Length of content previews
+	StatsTimeout   time.Duration // Upper bound for computing feedback statistics
+}
+
+// DeletionConfig represents the recovery of feedback deletions interrupted after their intent
+// was recorded
+type DeletionConfig struct {
+	RecoveryInterval time.Duration // How often interrupted deletions are resumed (0 only resumes them at startup)
+	RecoveryAfter    time.Duration // Age of an intent before it counts as interrupted rather than in progress
+}
+
+// OutboxConfig represents the worker that writes feedback content recorded in the content
+// outbox to MongoDB
+type OutboxConfig struct {
+	Interval      time.Duration // How often due entries are written
+	RetryDelay    time.Duration // Delay before the first retry of a failed entry; doubles with each failure
+	MaxRetryDelay time.Duration // Longest delay between retries
+}
+
+// ArchiveConfig represents the worker that archives old feedbacks
+type ArchiveConfig struct {
+	After     time.Duration // Age after which feedbacks are archived, by creation (0 disables the archiver)
+	Interval  time.Duration // How often the archiver runs
+	BatchSize int           // Feedbacks archived per statement
+}
+
+// EventStreamConfig represents the streams of SubscribeFeedbackEvents
+type EventStreamConfig struct {
+	BufferSize             int           // Events buffered per stream; a stream that falls further behind is ended
+	ReplayWindow           time.Duration // How long events are kept for streams resuming with resume_after
+	UploadProgressInterval time.Duration // Least time between two progress events of one attachment upload
+}
+
+// UpstreamConfig represents the users-service and labs-service clients CreateFeedback checks
+// its IDs with
+type UpstreamConfig struct {
+	UsersAddr string        // users-service gRPC address; empty skips the reviewer check
+	LabsAddr  string        // labs-service gRPC address; empty skips the submission check
+	Validate  bool          // Check the IDs of new feedback; off for local development
+	Timeout   time.Duration // Deadline of each upstream call
+	FailOpen  bool          // Create the feedback unchecked when a service does not answer
+}
+
+// WebhookConfig represents the delivery of feedback events to the HTTP endpoints listed in a
+// file, with the keys their payloads are signed with
+type WebhookConfig struct {
+	EndpointsFile  string        // JSON file of endpoints and signing keys; empty disables webhooks
+	ReloadInterval time.Duration // How often the file is checked for changes; 0 reloads it on SIGHUP only
+	Timeout        time.Duration // Deadline of each delivery attempt
+	MaxAttempts    int           // Attempts per delivery, the first included
+	RetryDelay     time.Duration // Delay before the first retry, doubled after each further failure
+	MaxRetryDelay  time.Duration // Longest delay between two attempts
+	QueueSize      int           // Deliveries waiting to be sent; further events are dropped
+	Workers        int           // Deliveries sent in parallel
+}
+
+// Sources ListAttachments reads attachment metadata from
+const (
+	AttachmentListStorage = "storage" // List the objects in MinIO
+	AttachmentListDual    = "dual"    // Read feedback_assets, listing MinIO for prefixes not backfilled yet
+	AttachmentListTable   = "table"   // Read feedback_assets only
+)
+
+// AttachmentMetadataConfig represents where attachment lists are read from and how the
+// feedback_assets table is backfilled from the objects in MinIO
+type AttachmentMetadataConfig struct {
+	ListSource                string // AttachmentListStorage, AttachmentListDual or AttachmentListTable
+	BackfillConcurrency       int    // Prefixes backfilled in parallel
+	BackfillRequestsPerSecond int64  // MinIO requests per second made by the backfill (0 means unlimited)
+	FilterConcurrency         int    // Prefixes listed in parallel for list attachment filters until the backfill is complete
+	FilterMaxPending          int    // Most feedbacks without backfilled metadata a filtered list may list in MinIO
+}
+
+// KeepaliveConfig represents the connection keepalive of the gRPC server and the idle timeouts of
+// streaming RPCs. Durations of 0 disable the matching limit.
+type KeepaliveConfig struct {
+	MaxConnectionIdle     time.Duration // Close connections without RPCs for this long
+	MaxConnectionAge      time.Duration // Ask clients to reconnect after this long; RPCs in flight continue
+	MaxConnectionAgeGrace time.Duration // Force closed connections past MaxConnectionAge after this long (0 waits for their streams)
+	Time                  time.Duration // Ping clients after this long without activity
+	Timeout               time.Duration // Close connections whose ping is not answered within this long
+	MinTime               time.Duration // Shortest interval between client pings the server accepts
+	TransferIdleTimeout   time.Duration // End upload, download and export streams without a message for this long
+	ReportIdleTimeout     time.Duration // End report streams, which compute between messages, without a message for this long
+}
+
+// SchemaConfig represents the rollout of feedbacks columns added by a migration
+type SchemaConfig struct {
+	DeferredWrites string // Comma-separated rolling columns that are read but not written yet
+}
+
+// FlagsConfig represents the feature flags gating behavior changes
+type FlagsConfig struct {
+	Overrides         string // Comma-separated name=bool pairs overriding the defaults defined in code
+	MetadataOverrides bool   // Allow requests to override flags with x-feature-flags metadata (testing only)
+}
+
+// LeaderConfig represents the election of the replica that runs singleton background jobs
+type LeaderConfig struct {
+	Enabled           bool          // Elect a leader per job; when disabled every replica runs every job
+	RetryInterval     time.Duration // How often a standby replica tries to become leader
+	HeartbeatInterval time.Duration // How often the leader checks that it still holds leadership
+}
+
+// MigrationConfig represents the move of attachments from a legacy bucket or prefix to the one
+// in MinIOConfig. While enabled, writes go to the new location and reads fall back to the legacy
+// one until cutover.
+type MigrationConfig struct {
+	Enabled      bool
+	LegacyBucket string
+	LegacyPrefix string
+	Cutover      bool // Stops reading the legacy location; requires a complete migration journal
+	BatchSize    int  // Objects copied per journaled batch
+}
+
+// ReadsLegacy reports whether attachments are still read from the legacy location
+func (m MigrationConfig) ReadsLegacy() bool {
+	return m.Enabled && !m.Cutover
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
 		GRPCPort: getEnv("GRPC_PORT", "9090"),
+		Keepalive: KeepaliveConfig{
+			MaxConnectionIdle:     getEnvDuration("GRPC_MAX_CONNECTION_IDLE", 30*time.Second),
+			MaxConnectionAge:      getEnvDuration("GRPC_MAX_CONNECTION_AGE", 5*time.Minute),
+			MaxConnectionAgeGrace: getEnvDuration("GRPC_MAX_CONNECTION_AGE_GRACE", 0),
+			Time:                  getEnvDuration("GRPC_KEEPALIVE_TIME", 5*time.Second),
+			Timeout:               getEnvDuration("GRPC_KEEPALIVE_TIMEOUT", 1*time.Second),
+			MinTime:               getEnvDuration("GRPC_KEEPALIVE_MIN_TIME", 5*time.Second),
+			TransferIdleTimeout:   getEnvDuration("GRPC_TRANSFER_STREAM_IDLE_TIMEOUT", 2*time.Minute),
+			ReportIdleTimeout:     getEnvDuration("GRPC_REPORT_STREAM_IDLE_TIMEOUT", 10*time.Minute),
+		},
 		Database: DatabaseConfig{
 			Host:     getEnv("POSTGRES_HOST", "localhost"),
 			Port:     getEnv("POSTGRES_PORT", "5432"),
@@ -66,9 +308,132 @@ func Load() (*Config, error) {
 			AccessKey:    getEnv("MINIO_ACCESS_KEY", "minioadmin"),
 			SecretKey:    getEnv("MINIO_SECRET_KEY", "minioadmin"),
 			BucketName:   getEnv("MINIO_BUCKET_NAME", "feedback"),
+			ObjectPrefix: getEnv("MINIO_OBJECT_PREFIX", ""),
 			UseSSL:       getEnvBool("MINIO_USE_SSL", false),
 			CreateBucket: getEnvBool("MINIO_CREATE_BUCKET", true),
 		},
+		Encryption: EncryptionConfig{
+			Enabled:     getEnvBool("ATTACHMENT_ENCRYPTION_ENABLED", false),
+			MasterKeys:  getEnv("ATTACHMENT_ENCRYPTION_KEYS", ""),
+			ActiveKeyID: getEnv("ATTACHMENT_ENCRYPTION_ACTIVE_KEY", ""),
+		},
+		Transfer: TransferConfig{
+			SoftDailyBytes: getEnvInt64("TRANSFER_SOFT_DAILY_BYTES", 0),
+			HardDailyBytes: getEnvInt64("TRANSFER_HARD_DAILY_BYTES", 0),
+			FlushInterval:  getEnvDuration("TRANSFER_FLUSH_INTERVAL", 30*time.Second),
+		},
+		PageToken: PageTokenConfig{
+			Secret: getEnv("PAGE_TOKEN_SECRET", ""),
+			TTL:    getEnvDuration("PAGE_TOKEN_TTL", 24*time.Hour),
+		},
+		Feedback: FeedbackConfig{
+			MaxTitleLength:   int(getEnvInt64("FEEDBACK_MAX_TITLE_LENGTH", MaxFeedbackTitleLength)),
+			MaxContentLength: int(getEnvInt64("FEEDBACK_MAX_CONTENT_LENGTH", 64*1024)),
+		},
+		Comments: CommentConfig{
+			MaxDepth:   int32(getEnvInt64("COMMENT_MAX_DEPTH", 10)),
+			EditWindow: getEnvDuration("COMMENT_EDIT_WINDOW", 0),
+
+			AcceptHexIDs: getEnvBool("COMMENT_ACCEPT_HEX_IDS", true),
+
+			ListContentMaxBytes: int(getEnvInt64("COMMENT_LIST_CONTENT_MAX_BYTES", 64*1024)),
+			MaxContentChars:     int(getEnvInt64("COMMENT_MAX_CONTENT_CHARS", 10000)),
+
+			WatchBufferSize:   int(getEnvInt64("COMMENT_WATCH_BUFFER_SIZE", 64)),
+			WatchPollInterval: getEnvDuration("COMMENT_WATCH_POLL_INTERVAL", 2*time.Second),
+			WatchReplayWindow: getEnvDuration("COMMENT_WATCH_REPLAY_WINDOW", 5*time.Minute),
+		},
+		Download: loadDownloadConfig(),
+		Render: RenderConfig{
+			MaxOutputBytes: int(getEnvInt64("RENDER_MAX_OUTPUT_BYTES", 64*1024)),
+			CacheEntries:   int(getEnvInt64("RENDER_CACHE_ENTRIES", 10000)),
+		},
+		Notification: NotificationConfig{
+			TemplateDir:     getEnv("NOTIFICATION_TEMPLATE_DIR", ""),
+			MaxContentChars: int(getEnvInt64("NOTIFICATION_MAX_CONTENT_CHARS", 1000)),
+		},
+		Retention: RetentionConfig{
+			Interval:        getEnvDuration("RETENTION_INTERVAL", time.Hour),
+			BatchSize:       int(getEnvInt64("RETENTION_BATCH_SIZE", 500)),
+			UploadSessions:  getEnvDuration("RETENTION_UPLOAD_SESSIONS", 7*24*time.Hour),
+			AuditLog:        getEnvDuration("RETENTION_AUDIT_LOG", 365*24*time.Hour),
+			TransferUsage:   getEnvDuration("RETENTION_TRANSFER_USAGE", 90*24*time.Hour),
+			IdempotencyKeys: getEnvDuration("RETENTION_IDEMPOTENCY_KEYS", 24*time.Hour),
+		},
+		Prefetch: PrefetchConfig{
+			CacheTTL:     getEnvDuration("PREFETCH_CACHE_TTL", 30*time.Second),
+			CacheEntries: int(getEnvInt64("PREFETCH_CACHE_ENTRIES", 1000)),
+			Timeout:      getEnvDuration("PREFETCH_TIMEOUT", 10*time.Second),
+		},
+		Dashboard: DashboardConfig{
+			AttachmentsTTL: getEnvDuration("DASHBOARD_ATTACHMENTS_TTL", 30*time.Second),
+			CacheEntries:   int(getEnvInt64("DASHBOARD_CACHE_ENTRIES", 5000)),
+			SourceTimeout:  getEnvDuration("DASHBOARD_SOURCE_TIMEOUT", 2*time.Second),
+			PreviewChars:   int(getEnvInt64("DASHBOARD_PREVIEW_CHARS", 200)),
+			StatsTimeout:   getEnvDuration("DASHBOARD_STATS_TIMEOUT", 5*time.Second),
+		},
+		Deletion: DeletionConfig{
+			RecoveryInterval: getEnvDuration("DELETION_RECOVERY_INTERVAL", time.Minute),
+			RecoveryAfter:    getEnvDuration("DELETION_RECOVERY_AFTER", 5*time.Minute),
+		},
+		Outbox: OutboxConfig{
+			Interval:      getEnvDuration("CONTENT_OUTBOX_INTERVAL", 5*time.Second),
+			RetryDelay:    getEnvDuration("CONTENT_OUTBOX_RETRY_DELAY", time.Second),
+			MaxRetryDelay: getEnvDuration("CONTENT_OUTBOX_MAX_RETRY_DELAY", 10*time.Minute),
+		},
+		Archive: ArchiveConfig{
+			After:     getEnvDuration("FEEDBACK_ARCHIVE_AFTER", 0),
+			Interval:  getEnvDuration("FEEDBACK_ARCHIVE_INTERVAL", time.Hour),
+			BatchSize: int(getEnvInt64("FEEDBACK_ARCHIVE_BATCH_SIZE", 500)),
+		},
+		Events: EventStreamConfig{
+			BufferSize:             int(getEnvInt64("FEEDBACK_EVENTS_BUFFER_SIZE", 64)),
+			ReplayWindow:           getEnvDuration("FEEDBACK_EVENTS_REPLAY_WINDOW", 5*time.Minute),
+			UploadProgressInterval: getEnvDuration("FEEDBACK_EVENTS_UPLOAD_PROGRESS_INTERVAL", 3*time.Second),
+		},
+		Upstream: UpstreamConfig{
+			UsersAddr: getEnv("USERS_SERVICE_ADDR", ""),
+			LabsAddr:  getEnv("LABS_SERVICE_ADDR", ""),
+			Validate:  getEnvBool("FEEDBACK_UPSTREAM_VALIDATION", true),
+			Timeout:   getEnvDuration("FEEDBACK_UPSTREAM_TIMEOUT", 2*time.Second),
+			FailOpen:  getEnvBool("FEEDBACK_UPSTREAM_FAIL_OPEN", true),
+		},
+		Webhook: WebhookConfig{
+			EndpointsFile:  getEnv("WEBHOOK_ENDPOINTS_FILE", ""),
+			ReloadInterval: getEnvDuration("WEBHOOK_RELOAD_INTERVAL", 10*time.Second),
+			Timeout:        getEnvDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+			MaxAttempts:    int(getEnvInt64("WEBHOOK_MAX_ATTEMPTS", 5)),
+			RetryDelay:     getEnvDuration("WEBHOOK_RETRY_DELAY", time.Second),
+			MaxRetryDelay:  getEnvDuration("WEBHOOK_MAX_RETRY_DELAY", time.Minute),
+			QueueSize:      int(getEnvInt64("WEBHOOK_QUEUE_SIZE", 1000)),
+			Workers:        int(getEnvInt64("WEBHOOK_WORKERS", 4)),
+		},
+		Leader: LeaderConfig{
+			Enabled:           getEnvBool("LEADER_ELECTION_ENABLED", true),
+			RetryInterval:     getEnvDuration("LEADER_RETRY_INTERVAL", 15*time.Second),
+			HeartbeatInterval: getEnvDuration("LEADER_HEARTBEAT_INTERVAL", 5*time.Second),
+		},
+		Flags: FlagsConfig{
+			Overrides:         getEnv("FEATURE_FLAGS", ""),
+			MetadataOverrides: getEnvBool("FEATURE_FLAGS_METADATA_OVERRIDES", false),
+		},
+		Metadata: AttachmentMetadataConfig{
+			ListSource:                getEnv("ATTACHMENT_LIST_SOURCE", AttachmentListStorage),
+			BackfillConcurrency:       int(getEnvInt64("ATTACHMENT_BACKFILL_CONCURRENCY", 4)),
+			BackfillRequestsPerSecond: getEnvInt64("ATTACHMENT_BACKFILL_REQUESTS_PER_SECOND", 50),
+			FilterConcurrency:         int(getEnvInt64("ATTACHMENT_FILTER_CONCURRENCY", 8)),
+			FilterMaxPending:          int(getEnvInt64("ATTACHMENT_FILTER_MAX_PENDING", 500)),
+		},
+		Schema: SchemaConfig{
+			DeferredWrites: getEnv("SCHEMA_DEFERRED_WRITE_COLUMNS", ""),
+		},
+	}
+	cfg.Migration = MigrationConfig{
+		Enabled:      getEnvBool("ATTACHMENT_MIGRATION_ENABLED", false),
+		LegacyBucket: getEnv("ATTACHMENT_MIGRATION_LEGACY_BUCKET", cfg.MinIO.BucketName),
+		LegacyPrefix: getEnv("ATTACHMENT_MIGRATION_LEGACY_PREFIX", ""),
+		Cutover:      getEnvBool("ATTACHMENT_MIGRATION_CUTOVER", false),
+		BatchSize:    int(getEnvInt64("ATTACHMENT_MIGRATION_BATCH_SIZE", 500)),
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -78,6 +443,15 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// loadDownloadConfig reads the global download limit and its per caller class overrides
+func loadDownloadConfig() DownloadConfig {
+	global := getEnvInt64("DOWNLOAD_BYTES_PER_SECOND", 0)
+	return DownloadConfig{
+		InternalBytesPerSecond: getEnvInt64("DOWNLOAD_INTERNAL_BYTES_PER_SECOND", global),
+		ExternalBytesPerSecond: getEnvInt64("DOWNLOAD_EXTERNAL_BYTES_PER_SECOND", global),
+	}
+}
+
 // validate validates the configuration
 func (c *Config) validate() error {
 	if c.GRPCPort == "" {
@@ -116,6 +490,240 @@ func (c *Config) validate() error {
 	if c.MinIO.BucketName == "" {
 		return fmt.Errorf("MINIO_BUCKET_NAME is required")
 	}
+	if err := validObjectPrefix("MINIO_OBJECT_PREFIX", c.MinIO.ObjectPrefix); err != nil {
+		return err
+	}
+	if err := c.validateKeepalive(); err != nil {
+		return err
+	}
+	if c.Encryption.Enabled && (c.Encryption.MasterKeys == "" || c.Encryption.ActiveKeyID == "") {
+		return fmt.Errorf("ATTACHMENT_ENCRYPTION_KEYS and ATTACHMENT_ENCRYPTION_ACTIVE_KEY are required when ATTACHMENT_ENCRYPTION_ENABLED is set")
+	}
+	if c.Transfer.SoftDailyBytes < 0 || c.Transfer.HardDailyBytes < 0 {
+		return fmt.Errorf("TRANSFER_SOFT_DAILY_BYTES and TRANSFER_HARD_DAILY_BYTES must not be negative")
+	}
+	if c.Leader.Enabled && (c.Leader.RetryInterval <= 0 || c.Leader.HeartbeatInterval <= 0) {
+		return fmt.Errorf("LEADER_RETRY_INTERVAL and LEADER_HEARTBEAT_INTERVAL must be positive")
+	}
+	if c.Transfer.FlushInterval <= 0 {
+		return fmt.Errorf("TRANSFER_FLUSH_INTERVAL must be positive")
+	}
+	if c.PageToken.Secret == "" {
+		return fmt.Errorf("PAGE_TOKEN_SECRET is required")
+	}
+	if c.PageToken.TTL <= 0 {
+		return fmt.Errorf("PAGE_TOKEN_TTL must be positive")
+	}
+	if c.Feedback.MaxTitleLength <= 0 || c.Feedback.MaxTitleLength > MaxFeedbackTitleLength {
+		return fmt.Errorf("FEEDBACK_MAX_TITLE_LENGTH must be between 1 and %d", MaxFeedbackTitleLength)
+	}
+	if c.Feedback.MaxContentLength <= 0 {
+		return fmt.Errorf("FEEDBACK_MAX_CONTENT_LENGTH must be positive")
+	}
+	if c.Comments.MaxDepth < 0 {
+		return fmt.Errorf("COMMENT_MAX_DEPTH must not be negative")
+	}
+	if c.Comments.EditWindow < 0 {
+		return fmt.Errorf("COMMENT_EDIT_WINDOW must not be negative")
+	}
+	if c.Comments.ListContentMaxBytes <= 0 {
+		return fmt.Errorf("COMMENT_LIST_CONTENT_MAX_BYTES must be positive")
+	}
+	if c.Comments.MaxContentChars <= 0 {
+		return fmt.Errorf("COMMENT_MAX_CONTENT_CHARS must be positive")
+	}
+	if c.Comments.WatchBufferSize <= 0 {
+		return fmt.Errorf("COMMENT_WATCH_BUFFER_SIZE must be positive")
+	}
+	if c.Comments.WatchPollInterval <= 0 {
+		return fmt.Errorf("COMMENT_WATCH_POLL_INTERVAL must be positive")
+	}
+	if c.Comments.WatchReplayWindow < 0 {
+		return fmt.Errorf("COMMENT_WATCH_REPLAY_WINDOW must not be negative")
+	}
+	if c.Download.InternalBytesPerSecond < 0 || c.Download.ExternalBytesPerSecond < 0 {
+		return fmt.Errorf("download bandwidth limits must not be negative")
+	}
+	if c.Render.MaxOutputBytes <= 0 {
+		return fmt.Errorf("RENDER_MAX_OUTPUT_BYTES must be positive")
+	}
+	if c.Render.CacheEntries < 0 {
+		return fmt.Errorf("RENDER_CACHE_ENTRIES must not be negative")
+	}
+	if c.Notification.MaxContentChars <= 0 {
+		return fmt.Errorf("NOTIFICATION_MAX_CONTENT_CHARS must be positive")
+	}
+	if c.Retention.Interval < 0 {
+		return fmt.Errorf("RETENTION_INTERVAL must not be negative")
+	}
+	if c.Retention.BatchSize <= 0 {
+		return fmt.Errorf("RETENTION_BATCH_SIZE must be positive")
+	}
+	if c.Retention.UploadSessions < 0 || c.Retention.AuditLog < 0 || c.Retention.TransferUsage < 0 || c.Retention.IdempotencyKeys < 0 {
+		return fmt.Errorf("retention durations must not be negative")
+	}
+	if c.Archive.After < 0 {
+		return fmt.Errorf("FEEDBACK_ARCHIVE_AFTER must not be negative")
+	}
+	if c.Archive.Interval <= 0 {
+		return fmt.Errorf("FEEDBACK_ARCHIVE_INTERVAL must be positive")
+	}
+	if c.Archive.BatchSize <= 0 {
+		return fmt.Errorf("FEEDBACK_ARCHIVE_BATCH_SIZE must be positive")
+	}
+	if c.Events.BufferSize <= 0 {
+		return fmt.Errorf("FEEDBACK_EVENTS_BUFFER_SIZE must be positive")
+	}
+	if c.Events.ReplayWindow < 0 {
+		return fmt.Errorf("FEEDBACK_EVENTS_REPLAY_WINDOW must not be negative")
+	}
+	if c.Events.UploadProgressInterval < 0 {
+		return fmt.Errorf("FEEDBACK_EVENTS_UPLOAD_PROGRESS_INTERVAL must not be negative")
+	}
+	if c.Upstream.Timeout <= 0 {
+		return fmt.Errorf("FEEDBACK_UPSTREAM_TIMEOUT must be positive")
+	}
+	if err := c.validateWebhook(); err != nil {
+		return err
+	}
+	if c.Prefetch.CacheTTL < 0 {
+		return fmt.Errorf("PREFETCH_CACHE_TTL must not be negative")
+	}
+	if c.Prefetch.CacheEntries <= 0 {
+		return fmt.Errorf("PREFETCH_CACHE_ENTRIES must be positive")
+	}
+	if c.Prefetch.Timeout <= 0 {
+		return fmt.Errorf("PREFETCH_TIMEOUT must be positive")
+	}
+	if c.Dashboard.AttachmentsTTL < 0 {
+		return fmt.Errorf("DASHBOARD_ATTACHMENTS_TTL must not be negative")
+	}
+	if c.Dashboard.CacheEntries <= 0 {
+		return fmt.Errorf("DASHBOARD_CACHE_ENTRIES must be positive")
+	}
+	if c.Dashboard.SourceTimeout <= 0 {
+		return fmt.Errorf("DASHBOARD_SOURCE_TIMEOUT must be positive")
+	}
+	if c.Dashboard.PreviewChars <= 0 {
+		return fmt.Errorf("DASHBOARD_PREVIEW_CHARS must be positive")
+	}
+	if c.Dashboard.StatsTimeout <= 0 {
+		return fmt.Errorf("DASHBOARD_STATS_TIMEOUT must be positive")
+	}
+	if c.Deletion.RecoveryInterval < 0 {
+		return fmt.Errorf("DELETION_RECOVERY_INTERVAL must not be negative")
+	}
+	if c.Deletion.RecoveryAfter <= 0 {
+		return fmt.Errorf("DELETION_RECOVERY_AFTER must be positive")
+	}
+	if c.Outbox.Interval <= 0 || c.Outbox.RetryDelay <= 0 {
+		return fmt.Errorf("CONTENT_OUTBOX_INTERVAL and CONTENT_OUTBOX_RETRY_DELAY must be positive")
+	}
+	if c.Outbox.MaxRetryDelay < c.Outbox.RetryDelay {
+		return fmt.Errorf("CONTENT_OUTBOX_MAX_RETRY_DELAY must not be less than CONTENT_OUTBOX_RETRY_DELAY")
+	}
+	switch c.Metadata.ListSource {
+	case AttachmentListStorage, AttachmentListDual, AttachmentListTable:
+	default:
+		return fmt.Errorf("ATTACHMENT_LIST_SOURCE must be %q, %q or %q", AttachmentListStorage, AttachmentListDual, AttachmentListTable)
+	}
+	if c.Metadata.BackfillConcurrency <= 0 {
+		return fmt.Errorf("ATTACHMENT_BACKFILL_CONCURRENCY must be positive")
+	}
+	if c.Metadata.BackfillRequestsPerSecond < 0 {
+		return fmt.Errorf("ATTACHMENT_BACKFILL_REQUESTS_PER_SECOND must not be negative")
+	}
+	if c.Metadata.FilterConcurrency <= 0 {
+		return fmt.Errorf("ATTACHMENT_FILTER_CONCURRENCY must be positive")
+	}
+	if c.Metadata.FilterMaxPending < 0 {
+		return fmt.Errorf("ATTACHMENT_FILTER_MAX_PENDING must not be negative")
+	}
+	if err := c.validateMigration(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateKeepalive checks the gRPC keepalive and stream idle timeouts
+func (c *Config) validateKeepalive() error {
+	k := c.Keepalive
+	if k.MaxConnectionIdle < 0 || k.MaxConnectionAge < 0 || k.MaxConnectionAgeGrace < 0 {
+		return fmt.Errorf("GRPC_MAX_CONNECTION_IDLE, GRPC_MAX_CONNECTION_AGE and GRPC_MAX_CONNECTION_AGE_GRACE must not be negative")
+	}
+	if k.Time <= 0 || k.Timeout <= 0 {
+		return fmt.Errorf("GRPC_KEEPALIVE_TIME and GRPC_KEEPALIVE_TIMEOUT must be positive")
+	}
+	if k.MinTime < 0 {
+		return fmt.Errorf("GRPC_KEEPALIVE_MIN_TIME must not be negative")
+	}
+	if k.TransferIdleTimeout < 0 || k.ReportIdleTimeout < 0 {
+		return fmt.Errorf("GRPC_TRANSFER_STREAM_IDLE_TIMEOUT and GRPC_REPORT_STREAM_IDLE_TIMEOUT must not be negative")
+	}
+	// A positive grace ends every stream of an aged connection; a shorter one than the idle
+	// timeout would cut transfers before they could even be found idle
+	if k.MaxConnectionAgeGrace > 0 && k.MaxConnectionAgeGrace < k.TransferIdleTimeout {
+		return fmt.Errorf("GRPC_MAX_CONNECTION_AGE_GRACE must be 0 or at least GRPC_TRANSFER_STREAM_IDLE_TIMEOUT")
+	}
+	return nil
+}
+
+// validateWebhook checks the delivery settings of webhooks
+func (c *Config) validateWebhook() error {
+	w := c.Webhook
+	if w.ReloadInterval < 0 {
+		return fmt.Errorf("WEBHOOK_RELOAD_INTERVAL must not be negative")
+	}
+	if w.Timeout <= 0 || w.RetryDelay <= 0 {
+		return fmt.Errorf("WEBHOOK_TIMEOUT and WEBHOOK_RETRY_DELAY must be positive")
+	}
+	if w.MaxRetryDelay < w.RetryDelay {
+		return fmt.Errorf("WEBHOOK_MAX_RETRY_DELAY must not be less than WEBHOOK_RETRY_DELAY")
+	}
+	if w.MaxAttempts <= 0 || w.QueueSize <= 0 || w.Workers <= 0 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS, WEBHOOK_QUEUE_SIZE and WEBHOOK_WORKERS must be positive")
+	}
+	return nil
+}
+
+// validateMigration checks that the legacy and current attachment locations can be told apart
+func (c *Config) validateMigration() error {
+	m := c.Migration
+	if m.Cutover && !m.Enabled {
+		return fmt.Errorf("ATTACHMENT_MIGRATION_CUTOVER requires ATTACHMENT_MIGRATION_ENABLED")
+	}
+	if !m.Enabled {
+		return nil
+	}
+	if m.LegacyBucket == "" {
+		return fmt.Errorf("ATTACHMENT_MIGRATION_LEGACY_BUCKET is required when ATTACHMENT_MIGRATION_ENABLED is set")
+	}
+	if err := validObjectPrefix("ATTACHMENT_MIGRATION_LEGACY_PREFIX", m.LegacyPrefix); err != nil {
+		return err
+	}
+	if m.BatchSize <= 0 {
+		return fmt.Errorf("ATTACHMENT_MIGRATION_BATCH_SIZE must be positive")
+	}
+	if m.LegacyBucket == c.MinIO.BucketName {
+		if m.LegacyPrefix == c.MinIO.ObjectPrefix {
+			return fmt.Errorf("the legacy attachment location must differ from MINIO_BUCKET_NAME and MINIO_OBJECT_PREFIX")
+		}
+		// Objects at the legacy location would be listed as part of the current one
+		if strings.HasPrefix(m.LegacyPrefix, c.MinIO.ObjectPrefix) {
+			return fmt.Errorf("MINIO_OBJECT_PREFIX must not enclose ATTACHMENT_MIGRATION_LEGACY_PREFIX")
+		}
+	}
+	return nil
+}
+
+// validObjectPrefix checks that an object key prefix is empty or a relative path ending with "/"
+func validObjectPrefix(name, prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if strings.HasPrefix(prefix, "/") || !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("%s must not start with \"/\" and must end with \"/\"", name)
+	}
 	return nil
 }
 
@@ -136,3 +744,23 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvInt64 gets an int64 environment variable with a default value
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDuration gets a duration environment variable (e.g. "30s") with a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
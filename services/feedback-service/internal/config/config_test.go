@@ -0,0 +1,36 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadPageTokenSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{name: "unset", secret: "", wantErr: true},
+		{name: "set", secret: "a-deployment-secret", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGE_TOKEN_SECRET", tt.secret)
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "PAGE_TOKEN_SECRET") {
+					t.Fatalf("Load() error = %v, want PAGE_TOKEN_SECRET error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.PageToken.Secret != tt.secret {
+				t.Errorf("PageToken.Secret = %q, want %q", cfg.PageToken.Secret, tt.secret)
+			}
+		})
+	}
+}
@@ -9,8 +9,21 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
 )
 
+// CommentReactionsCollection holds the reactions of users to comments, one document per user
+// and comment
+const CommentReactionsCollection = "comment_reactions"
+
+// CommentReportsCollection holds the reports of comments for moderation, one document per
+// reporter and comment
+const CommentReportsCollection = "comment_reports"
+
+// CommentDeletionsCollection holds a record of each comment deletion for the replays of comment
+// streams, removed after models.CommentDeletionRetention
+const CommentDeletionsCollection = "comment_deletions"
+
 // MongoDBClient wraps MongoDB client with database and collection
 type MongoDBClient struct {
 	Client   *mongo.Client
@@ -80,11 +93,63 @@ func (m *MongoDBClient) CreateIndexes(ctx context.Context, collectionName string
 		},
 	}
 
+	// Indexes for comment lists of a content, newest first, optionally narrowed to authors
+	contentTimelineIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "content_id", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+	}
+	contentUserIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "content_id", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "user_id", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+	}
+
+	// The same for comments on content referenced by a string ID, such as feedback discussions;
+	// partial, so comments on labs and articles do not take space in them
+	hasContentRef := bson.M{"content_ref": bson.M{"$exists": true}}
+	contentRefTimelineIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "content_ref", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+		Options: options.Index().SetPartialFilterExpression(hasContentRef),
+	}
+	contentRefUserIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "content_ref", Value: 1},
+			{Key: "type", Value: 1},
+			{Key: "user_id", Value: 1},
+			{Key: "created_at", Value: -1},
+		},
+		Options: options.Index().SetPartialFilterExpression(hasContentRef),
+	}
+
+	// Index for the comments changed since a time, read by comment streams that cannot use
+	// change streams; comments written before it existed have no changed_at
+	changedIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "changed_at", Value: 1},
+		},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"changed_at": bson.M{"$exists": true}}),
+	}
+
 	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		contentIDIndex,
 		parentIndex,
 		userIndex,
 		timestampIndex,
+		contentTimelineIndex,
+		contentUserIndex,
+		contentRefTimelineIndex,
+		contentRefUserIndex,
+		changedIndex,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
@@ -93,6 +158,82 @@ func (m *MongoDBClient) CreateIndexes(ctx context.Context, collectionName string
 	return nil
 }
 
+// CreateCommentReactionIndexes creates the indexes of the comment reactions collection. The
+// unique index keeps one reaction per user and comment and serves the per-comment counts.
+func (m *MongoDBClient) CreateCommentReactionIndexes(ctx context.Context) error {
+	collection := m.Database.Collection(CommentReactionsCollection)
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "comment_id", Value: 1},
+			{Key: "user_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create comment reaction indexes: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCommentReportIndexes creates the indexes of the comment reports collection: a unique
+// index that keeps one report per reporter and comment, and one for the moderation queue of
+// open reports
+func (m *MongoDBClient) CreateCommentReportIndexes(ctx context.Context) error {
+	collection := m.Database.Collection(CommentReportsCollection)
+
+	reporterIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "comment_id", Value: 1},
+			{Key: "reporter_id", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	openIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "open", Value: 1},
+			{Key: "comment_id", Value: 1},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{reporterIndex, openIndex})
+	if err != nil {
+		return fmt.Errorf("failed to create comment report indexes: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCommentDeletionIndexes creates the indexes of the comment deletions collection: a TTL
+// index that removes deletions after models.CommentDeletionRetention, which also serves reads
+// of all recent deletions, and one for the deletions on a content
+func (m *MongoDBClient) CreateCommentDeletionIndexes(ctx context.Context) error {
+	collection := m.Database.Collection(CommentDeletionsCollection)
+
+	expiryIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "deleted_at", Value: 1},
+		},
+		Options: options.Index().SetExpireAfterSeconds(int32(models.CommentDeletionRetention.Seconds())),
+	}
+	contentIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "type", Value: 1},
+			{Key: "content_id", Value: 1},
+			{Key: "content_ref", Value: 1},
+			{Key: "deleted_at", Value: 1},
+		},
+	}
+
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{expiryIndex, contentIndex})
+	if err != nil {
+		return fmt.Errorf("failed to create comment deletion indexes: %w", err)
+	}
+
+	return nil
+}
+
 func (m *MongoDBClient) WithTransaction(ctx context.Context, fn func(mongo.SessionContext) error) error {
 	session, err := m.Client.StartSession()
 	if err != nil {
@@ -0,0 +1,314 @@
+// Package envelope implements client-side envelope encryption of attachment objects.
+//
+// Every object is encrypted with its own random data key using AES-256-GCM. The data key is
+// wrapped (encrypted) with a master key from a Keyring and stored next to the object, so the
+// storage operator only ever sees ciphertext and wrapped keys. Rotating the master key only
+// requires re-wrapping the data keys, not re-encrypting the objects.
+//
+// Object bytes are split into chunks of ChunkSize. Each chunk is sealed separately with a nonce
+// made of its index and a flag marking the last chunk, so reordered, truncated or extended
+// ciphertext fails to decrypt just like modified bytes do.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Algorithm identifies the object format written by this package
+const Algorithm = "AES256-GCM-CHUNKED-V1"
+
+// ChunkSize is the number of plaintext bytes sealed per chunk
+const ChunkSize = 64 * 1024
+
+// KeySize is the size in bytes of master and data keys
+const KeySize = 32
+
+// tagSize is the authentication tag added to every chunk
+const tagSize = 16
+
+var (
+	// ErrCorrupted is returned when ciphertext or a wrapped key fails authentication
+	ErrCorrupted = errors.New("encrypted data is corrupted")
+
+	// ErrUnknownKey is returned when a wrapped key names a master key missing from the keyring
+	ErrUnknownKey = errors.New("unknown master key")
+)
+
+// WrappedKey is a data key encrypted with a master key
+type WrappedKey struct {
+	KeyID      string // ID of the master key that wrapped the data key
+	Ciphertext []byte // Nonce followed by the sealed data key
+}
+
+// String encodes the wrapped key ciphertext for storage in object metadata
+func (w WrappedKey) String() string {
+	return base64.RawStdEncoding.EncodeToString(w.Ciphertext)
+}
+
+// ParseWrappedKey decodes a wrapped key stored by WrappedKey.String
+func ParseWrappedKey(keyID, value string) (WrappedKey, error) {
+	ciphertext, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("%w: malformed wrapped key", ErrCorrupted)
+	}
+	return WrappedKey{KeyID: keyID, Ciphertext: ciphertext}, nil
+}
+
+// Keyring holds the master keys. The active key wraps new data keys; every key can unwrap.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// ParseKeyring parses master keys given as comma-separated "id:base64key" pairs. Keys must be
+// KeySize bytes, IDs must be unique and may only contain letters, digits, '.', '_' and '-'.
+// activeID names the key used for new objects.
+func ParseKeyring(spec, activeID string) (*Keyring, error) {
+	k := &Keyring{active: activeID, keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || !validKeyID(id) {
+			return nil, fmt.Errorf("master key entry must be id:base64key with a simple id")
+		}
+		if _, exists := k.keys[id]; exists {
+			return nil, fmt.Errorf("master key %q is listed twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q is not valid base64: %w", id, err)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes, got %d", id, KeySize, len(key))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+
+	if len(k.keys) == 0 {
+		return nil, fmt.Errorf("no master keys configured")
+	}
+	if _, ok := k.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active master key %q is not configured", activeID)
+	}
+	return k, nil
+}
+
+// validKeyID reports whether id is safe to store in object metadata
+func validKeyID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ActiveKeyID returns the ID of the master key that wraps new data keys
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// NewDataKey generates a data key for one object and wraps it with the active master key
+func (k *Keyring) NewDataKey() ([]byte, WrappedKey, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, WrappedKey{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := k.wrap(dataKey)
+	if err != nil {
+		return nil, WrappedKey{}, err
+	}
+	return dataKey, wrapped, nil
+}
+
+// Unwrap decrypts a wrapped data key
+func (k *Keyring) Unwrap(wrapped WrappedKey) ([]byte, error) {
+	aead, ok := k.keys[wrapped.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, wrapped.KeyID)
+	}
+	nonceSize := aead.NonceSize()
+	if len(wrapped.Ciphertext) < nonceSize+tagSize {
+		return nil, fmt.Errorf("%w: wrapped key is too short", ErrCorrupted)
+	}
+	nonce, sealed := wrapped.Ciphertext[:nonceSize], wrapped.Ciphertext[nonceSize:]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(wrapped.KeyID))
+	if err != nil || len(dataKey) != KeySize {
+		return nil, fmt.Errorf("%w: wrapped key failed authentication", ErrCorrupted)
+	}
+	return dataKey, nil
+}
+
+// Rewrap re-wraps a data key with the active master key. It reports false and returns the key
+// unchanged if it is already wrapped with the active key.
+func (k *Keyring) Rewrap(wrapped WrappedKey) (WrappedKey, bool, error) {
+	if wrapped.KeyID == k.active {
+		return wrapped, false, nil
+	}
+	dataKey, err := k.Unwrap(wrapped)
+	if err != nil {
+		return WrappedKey{}, false, err
+	}
+	rewrapped, err := k.wrap(dataKey)
+	if err != nil {
+		return WrappedKey{}, false, err
+	}
+	return rewrapped, true, nil
+}
+
+// wrap encrypts a data key with the active master key, binding it to the key ID
+func (k *Keyring) wrap(dataKey []byte) (WrappedKey, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return WrappedKey{
+		KeyID:      k.active,
+		Ciphertext: aead.Seal(nonce, nonce, dataKey, []byte(k.active)),
+	}, nil
+}
+
+// EncryptedSize returns the stored size of an object with the given plaintext size
+func EncryptedSize(plaintextSize int64) int64 {
+	chunks := (plaintextSize + ChunkSize - 1) / ChunkSize
+	if chunks == 0 {
+		chunks = 1 // An empty object still has one, empty, last chunk
+	}
+	return plaintextSize + chunks*tagSize
+}
+
+// NewEncryptReader returns a reader yielding the encrypted form of src under dataKey
+func NewEncryptReader(src io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{src: src, aead: aead, encrypt: true, in: make([]byte, 0, ChunkSize+tagSize+1)}, nil
+}
+
+// NewDecryptReader returns a reader yielding the plaintext of src encrypted under dataKey. Read
+// fails with ErrCorrupted as soon as a chunk does not authenticate; bytes of earlier chunks have
+// already been returned by then.
+func NewDecryptReader(src io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{src: src, aead: aead, in: make([]byte, 0, ChunkSize+tagSize+1)}, nil
+}
+
+// newAEAD creates an AES-256-GCM cipher
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkReader seals or opens src one chunk at a time. It reads one byte past each chunk to
+// learn whether the chunk is the last one.
+type chunkReader struct {
+	src     io.Reader
+	aead    cipher.AEAD
+	encrypt bool
+
+	index uint64
+	in    []byte // Input read ahead: the next chunk plus at most one byte
+	buf   []byte // Output buffer reused across chunks
+	out   []byte // Output of the current chunk not yet returned
+	done  bool   // The last chunk was processed
+	err   error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// next processes the next chunk into out
+func (r *chunkReader) next() error {
+	chunkLen := ChunkSize
+	if !r.encrypt {
+		chunkLen += tagSize
+	}
+
+	// Fill the buffer up to one chunk plus one byte
+	buffered := len(r.in)
+	r.in = r.in[:chunkLen+1]
+	n, err := io.ReadFull(r.src, r.in[buffered:])
+	r.in = r.in[:buffered+n]
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		r.done = true
+	case err != nil:
+		return err
+	}
+
+	chunk := r.in
+	if !r.done {
+		chunk = r.in[:chunkLen]
+	}
+
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[:8], r.index)
+	if r.done {
+		nonce[11] = 1
+	}
+	r.index++
+
+	if r.encrypt {
+		r.buf = r.aead.Seal(r.buf[:0], nonce[:], chunk, nil)
+		r.out = r.buf
+	} else {
+		if len(chunk) < tagSize {
+			return fmt.Errorf("%w: truncated chunk", ErrCorrupted)
+		}
+		opened, err := r.aead.Open(r.buf[:0], nonce[:], chunk, nil)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d failed authentication", ErrCorrupted, r.index-1)
+		}
+		r.buf, r.out = opened, opened
+	}
+
+	// Keep the read-ahead byte for the next chunk
+	if !r.done {
+		r.in[0] = r.in[chunkLen]
+		r.in = r.in[:1]
+	}
+	return nil
+}
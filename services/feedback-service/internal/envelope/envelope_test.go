@@ -0,0 +1,199 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, KeySize))
+}
+
+func newTestKeyring(t *testing.T, spec, active string) *Keyring {
+	t.Helper()
+	keyring, err := ParseKeyring(spec, active)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	return keyring
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		active  string
+		wantErr bool
+	}{
+		{name: "single key", spec: "k1:" + testKey(1), active: "k1"},
+		{name: "several keys with spaces", spec: " k1:" + testKey(1) + " , k-2.b_c:" + testKey(2) + ",", active: "k-2.b_c"},
+		{name: "empty", spec: "", active: "k1", wantErr: true},
+		{name: "missing id", spec: testKey(1), active: "k1", wantErr: true},
+		{name: "invalid id", spec: "k/1:" + testKey(1), active: "k/1", wantErr: true},
+		{name: "duplicate id", spec: "k1:" + testKey(1) + ",k1:" + testKey(2), active: "k1", wantErr: true},
+		{name: "invalid base64", spec: "k1:not base64", active: "k1", wantErr: true},
+		{name: "short key", spec: "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), active: "k1", wantErr: true},
+		{name: "unknown active key", spec: "k1:" + testKey(1), active: "k2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := ParseKeyring(tt.spec, tt.active)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && keyring.ActiveKeyID() != tt.active {
+				t.Errorf("ActiveKeyID() = %q, want %q", keyring.ActiveKeyID(), tt.active)
+			}
+		})
+	}
+}
+
+func TestWrappedKeys(t *testing.T) {
+	old := newTestKeyring(t, "old:"+testKey(1), "old")
+	rotated := newTestKeyring(t, "old:"+testKey(1)+",new:"+testKey(2), "new")
+	dataKey, wrapped, err := old.NewDataKey()
+	if err != nil {
+		t.Fatalf("NewDataKey() error = %v", err)
+	}
+
+	parsed, err := ParseWrappedKey(wrapped.KeyID, wrapped.String())
+	if err != nil {
+		t.Fatalf("ParseWrappedKey() error = %v", err)
+	}
+	rewrapped, changed, err := rotated.Rewrap(parsed)
+	if err != nil || !changed || rewrapped.KeyID != "new" {
+		t.Fatalf("Rewrap() = %q, %v, %v, want a key wrapped with new", rewrapped.KeyID, changed, err)
+	}
+	if again, changed, err := rotated.Rewrap(rewrapped); err != nil || changed || again.KeyID != "new" {
+		t.Errorf("Rewrap() of an active key = %q, %v, %v, want it unchanged", again.KeyID, changed, err)
+	}
+
+	tampered := bytes.Clone(wrapped.Ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		wrapped WrappedKey
+		wantErr error
+	}{
+		{name: "original key", keyring: old, wrapped: wrapped},
+		{name: "rewrapped key", keyring: rotated, wrapped: rewrapped},
+		{name: "unknown master key", keyring: old, wrapped: rewrapped, wantErr: ErrUnknownKey},
+		{name: "tampered ciphertext", keyring: old, wrapped: WrappedKey{KeyID: "old", Ciphertext: tampered}, wantErr: ErrCorrupted},
+		{name: "truncated ciphertext", keyring: old, wrapped: WrappedKey{KeyID: "old", Ciphertext: wrapped.Ciphertext[:10]}, wantErr: ErrCorrupted},
+		{name: "key ID swapped", keyring: rotated, wrapped: WrappedKey{KeyID: "new", Ciphertext: wrapped.Ciphertext}, wantErr: ErrCorrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.keyring.Unwrap(tt.wrapped)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unwrap() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(got, dataKey) {
+				t.Error("Unwrap() returned another data key")
+			}
+		})
+	}
+
+	if _, err := ParseWrappedKey("old", "not base64!"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("ParseWrappedKey() error = %v, want %v", err, ErrCorrupted)
+	}
+}
+
+// encrypt returns the ciphertext of plaintext under dataKey
+func encrypt(t *testing.T, plaintext, dataKey []byte) []byte {
+	t.Helper()
+	reader, err := NewEncryptReader(bytes.NewReader(plaintext), dataKey)
+	if err != nil {
+		t.Fatalf("NewEncryptReader() error = %v", err)
+	}
+	ciphertext, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("encrypt ReadAll() error = %v", err)
+	}
+	return ciphertext
+}
+
+// decrypt returns the plaintext of ciphertext under dataKey
+func decrypt(t *testing.T, ciphertext, dataKey []byte) ([]byte, error) {
+	t.Helper()
+	reader, err := NewDecryptReader(bytes.NewReader(ciphertext), dataKey)
+	if err != nil {
+		t.Fatalf("NewDecryptReader() error = %v", err)
+	}
+	return io.ReadAll(reader)
+}
+
+func TestRoundTrip(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{7}, KeySize)
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "empty", size: 0},
+		{name: "one byte", size: 1},
+		{name: "just under a chunk", size: ChunkSize - 1},
+		{name: "exactly a chunk", size: ChunkSize},
+		{name: "just over a chunk", size: ChunkSize + 1},
+		{name: "several chunks", size: 3*ChunkSize + 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext := bytes.Repeat([]byte("attachment"), tt.size/10+1)[:tt.size]
+			ciphertext := encrypt(t, plaintext, dataKey)
+			if got := int64(len(ciphertext)); got != EncryptedSize(int64(tt.size)) {
+				t.Errorf("len(ciphertext) = %d, EncryptedSize() = %d", got, EncryptedSize(int64(tt.size)))
+			}
+			got, err := decrypt(t, ciphertext, dataKey)
+			if err != nil {
+				t.Fatalf("decrypt error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("decrypt = %d bytes, want the %d plaintext bytes", len(got), len(plaintext))
+			}
+		})
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{7}, KeySize)
+	ciphertext := encrypt(t, []byte(strings.Repeat("x", 2*ChunkSize+10)), dataKey)
+	sealedChunk := ChunkSize + tagSize
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		dataKey    []byte
+	}{
+		{name: "modified byte", ciphertext: func() []byte {
+			modified := bytes.Clone(ciphertext)
+			modified[ChunkSize/2] ^= 1
+			return modified
+		}()},
+		{name: "truncated last chunk", ciphertext: ciphertext[:len(ciphertext)-1]},
+		{name: "last chunk dropped", ciphertext: ciphertext[:2*sealedChunk]},
+		{name: "chunks reordered", ciphertext: append(append(bytes.Clone(ciphertext[sealedChunk:2*sealedChunk]), ciphertext[:sealedChunk]...), ciphertext[2*sealedChunk:]...)},
+		{name: "extended", ciphertext: append(bytes.Clone(ciphertext), 0)},
+		{name: "shorter than a tag", ciphertext: ciphertext[:tagSize-1]},
+		{name: "wrong data key", ciphertext: ciphertext, dataKey: bytes.Repeat([]byte{8}, KeySize)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := dataKey
+			if tt.dataKey != nil {
+				key = tt.dataKey
+			}
+			if _, err := decrypt(t, tt.ciphertext, key); !errors.Is(err, ErrCorrupted) {
+				t.Errorf("decrypt error = %v, want %v", err, ErrCorrupted)
+			}
+		})
+	}
+
+	if _, err := NewDecryptReader(bytes.NewReader(ciphertext), dataKey[:16]); err == nil {
+		t.Error("NewDecryptReader() accepted a short data key")
+	}
+}
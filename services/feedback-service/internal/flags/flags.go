@@ -0,0 +1,258 @@
+// Package flags gates externally visible behavior changes so they can be rolled out per
+// environment.
+//
+// Flags are defined in code with a default. FEATURE_FLAGS overrides them for the whole
+// process, and, when FEATURE_FLAGS_METADATA_OVERRIDES is enabled, the x-feature-flags request
+// metadata overrides them for a single request, for testing a change before it is switched
+// on. The interceptor resolves the values of a request once; handlers read them with
+// FromContext, which does not allocate.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey carries per-request overrides as comma-separated name=bool pairs
+const MetadataKey = "x-feature-flags"
+
+// Flag is a behavior change that can be switched on per environment
+type Flag uint8
+
+const (
+	// StrictErrorCodes reports not-found and invalid-input errors of the read RPCs with their
+	// own code instead of INTERNAL
+	StrictErrorCodes Flag = iota
+	// StrictLimitValidation rejects negative pages and limits above 100 instead of clamping them
+	StrictLimitValidation
+	// RejectDuplicateIDs rejects repeated IDs in batch requests instead of collapsing them
+	RejectDuplicateIDs
+
+	numFlags
+)
+
+// definition describes a flag
+type definition struct {
+	name        string
+	description string
+	enabled     bool // Default
+}
+
+var definitions = [numFlags]definition{
+	StrictErrorCodes:      {"strict_error_codes", "Read RPCs report NOT_FOUND and INVALID_ARGUMENT instead of INTERNAL", false},
+	StrictLimitValidation: {"strict_limit_validation", "List RPCs reject out-of-range page and limit values instead of clamping them", false},
+	RejectDuplicateIDs:    {"reject_duplicate_ids", "Batch requests with repeated IDs are rejected instead of deduplicated", false},
+}
+
+// Name returns the name a flag is configured by
+func (f Flag) Name() string {
+	return definitions[f].name
+}
+
+// lookup returns the flag with a name
+func lookup(name string) (Flag, bool) {
+	for f := Flag(0); f < numFlags; f++ {
+		if definitions[f].name == name {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// Set holds the value of every flag. It is a plain bit set, so reading it never allocates.
+type Set uint64
+
+// Enabled reports whether a flag is on
+func (s Set) Enabled(f Flag) bool {
+	return s&(1<<f) != 0
+}
+
+// with returns s with a flag set to a value
+func (s Set) with(f Flag, enabled bool) Set {
+	if enabled {
+		return s | 1<<f
+	}
+	return s &^ (1 << f)
+}
+
+// Defaults returns the values defined in code
+func Defaults() Set {
+	var s Set
+	for f := Flag(0); f < numFlags; f++ {
+		s = s.with(f, definitions[f].enabled)
+	}
+	return s
+}
+
+// parse applies comma-separated name=bool overrides to base and returns the flags they set
+func parse(base Set, overrides string) (Set, Set, error) {
+	var set Set
+	for _, pair := range strings.Split(overrides, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return 0, 0, fmt.Errorf("feature flag override %q is not name=bool", pair)
+		}
+		f, ok := lookup(strings.TrimSpace(name))
+		if !ok {
+			return 0, 0, fmt.Errorf("unknown feature flag %q", strings.TrimSpace(name))
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return 0, 0, fmt.Errorf("feature flag %s: invalid value %q", f.Name(), value)
+		}
+		base = base.with(f, enabled)
+		set = set.with(f, true)
+	}
+	return base, set, nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the flag values of a request
+func NewContext(ctx context.Context, s Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the flag values of a request, or the defaults outside of one
+func FromContext(ctx context.Context) Set {
+	if s, ok := ctx.Value(contextKey{}).(Set); ok {
+		return s
+	}
+	return Defaults()
+}
+
+// Status is the value of one flag and where it comes from
+type Status struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Default     bool
+	Source      string // "default" or "config"
+}
+
+// Registry holds the flag values configured for the process
+type Registry struct {
+	values            Set
+	configured        Set // Flags set by FEATURE_FLAGS
+	metadataOverrides bool
+	logger            *slog.Logger
+}
+
+// New creates the registry from the configured overrides and logs every flag that differs
+// from its default
+func New(cfg config.FlagsConfig, logger *slog.Logger) (*Registry, error) {
+	values, configured, err := parse(Defaults(), cfg.Overrides)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FEATURE_FLAGS: %w", err)
+	}
+
+	r := &Registry{values: values, configured: configured, metadataOverrides: cfg.MetadataOverrides, logger: logger}
+	for f := Flag(0); f < numFlags; f++ {
+		if values.Enabled(f) != definitions[f].enabled {
+			logger.Info("Feature flag overridden by configuration", "flag", f.Name(), "enabled", values.Enabled(f))
+		}
+	}
+	if cfg.MetadataOverrides {
+		logger.Warn("Feature flags can be overridden per request", "metadata_key", MetadataKey)
+	}
+	return r, nil
+}
+
+// Values returns the flag values configured for the process
+func (r *Registry) Values() Set {
+	return r.values
+}
+
+// MetadataOverrides reports whether requests may override flags
+func (r *Registry) MetadataOverrides() bool {
+	return r.metadataOverrides
+}
+
+// Statuses returns every flag with its configured value, in definition order
+func (r *Registry) Statuses() []Status {
+	statuses := make([]Status, numFlags)
+	for f := Flag(0); f < numFlags; f++ {
+		source := "default"
+		if r.configured.Enabled(f) {
+			source = "config"
+		}
+		statuses[f] = Status{
+			Name:        definitions[f].name,
+			Description: definitions[f].description,
+			Enabled:     r.values.Enabled(f),
+			Default:     definitions[f].enabled,
+			Source:      source,
+		}
+	}
+	return statuses
+}
+
+// resolve returns the flag values of a request: the configured values with the request's
+// metadata overrides applied, if they are allowed
+func (r *Registry) resolve(ctx context.Context, method string) (Set, error) {
+	if !r.metadataOverrides {
+		return r.values, nil
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return r.values, nil
+	}
+	overrides := md.Get(MetadataKey)
+	if len(overrides) == 0 {
+		return r.values, nil
+	}
+
+	values, _, err := parse(r.values, strings.Join(overrides, ","))
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid %s metadata: %v", MetadataKey, err))
+	}
+	if values != r.values {
+		r.logger.Info("Feature flags overridden by request metadata", "method", method, "overrides", strings.Join(overrides, ","))
+	}
+	return values, nil
+}
+
+// UnaryServerInterceptor attaches the flag values of each request to its context
+func (r *Registry) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		values, err := r.resolve(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(NewContext(ctx, values), req)
+	}
+}
+
+// StreamServerInterceptor attaches the flag values of each stream to its context
+func (r *Registry) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		values, err := r.resolve(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &flaggedStream{ServerStream: ss, ctx: NewContext(ss.Context(), values)})
+	}
+}
+
+// flaggedStream is a server stream whose context carries flag values
+type flaggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *flaggedStream) Context() context.Context {
+	return s.ctx
+}
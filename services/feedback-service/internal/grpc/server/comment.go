@@ -2,11 +2,25 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 
 	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/render"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/textenc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,38 +31,168 @@ import (
 type commentServer struct {
 	pb.UnimplementedCommentServiceServer
 	commentService *service.CommentService
+	renderer       *render.Renderer
+	acceptHexIDs   bool
+	listMaxBytes   int // Content above this size is truncated in list responses
+	pageTokens     *pagetoken.Codec
 	logger         *slog.Logger
 }
 
 // NewCommentServer creates a new comment server
-func NewCommentServer(commentService *service.CommentService, logger *slog.Logger) pb.CommentServiceServer {
+func NewCommentServer(commentService *service.CommentService, renderer *render.Renderer, acceptHexIDs bool, listMaxBytes int, pageTokens *pagetoken.Codec, logger *slog.Logger) pb.CommentServiceServer {
 	return &commentServer{
 		commentService: commentService,
+		renderer:       renderer,
+		acceptHexIDs:   acceptHexIDs,
+		listMaxBytes:   listMaxBytes,
+		pageTokens:     pageTokens,
 		logger:         logger,
 	}
 }
 
-// RegisterCommentServer registers the comment server with gRPC
-func RegisterCommentServer(s *grpc.Server, commentService *service.CommentService, logger *slog.Logger) {
+// RegisterCommentServer registers the comment server with gRPC. acceptHexIDs controls whether
+// bare hex comment IDs are still accepted next to the opaque form, and listMaxBytes is the
+// content size above which list responses truncate comments. pageTokens signs the list
+// cursors.
+func RegisterCommentServer(s *grpc.Server, commentService *service.CommentService, renderer *render.Renderer, acceptHexIDs bool, listMaxBytes int, pageTokens *pagetoken.Codec, logger *slog.Logger) {
 	server := &commentServer{
 		commentService: commentService,
+		renderer:       renderer,
+		acceptHexIDs:   acceptHexIDs,
+		listMaxBytes:   listMaxBytes,
+		pageTokens:     pageTokens,
 		logger:         logger,
 	}
 	pb.RegisterCommentServiceServer(s, server)
 }
 
+// convertToProtoComment converts model Comment to protobuf Comment; IDs are returned in their
+// opaque form, and hidden comments without their content
+func convertToProtoComment(comment *models.Comment) *pb.Comment {
+	var parentID *string
+	if comment.ParentID != nil {
+		opaque := resourcename.CommentID(*comment.ParentID)
+		parentID = &opaque
+	}
+	pbComment := &pb.Comment{
+		Id:         resourcename.CommentID(comment.ID.Hex()),
+		Name:       resourcename.Comment(comment.Type, comment.ContentKey(), comment.ID.Hex()),
+		ContentId:  comment.ContentID,
+		ContentRef: comment.ContentRef,
+		UserId:     comment.UserID,
+		ParentId:   parentID,
+		Content:    comment.Content,
+		CreatedAt:  timestamppb.New(comment.CreatedAt),
+		UpdatedAt:  timestamppb.New(comment.UpdatedAt),
+		Type:       comment.Type,
+		Depth:      comment.Depth,
+		CourseId:   comment.CourseID,
+
+		ReplyCount: comment.ReplyCount,
+
+		LikeCount:      comment.LikeCount,
+		DislikeCount:   comment.DislikeCount,
+		ViewerReaction: protoCommentReactions[comment.ViewerReaction],
+
+		Edited:    comment.Edited,
+		EditCount: comment.EditCount,
+		Hidden:    comment.IsHidden(),
+		Deleted:   comment.IsDeleted(),
+	}
+	if comment.IsHidden() {
+		pbComment.Content = "" // Removed by a moderator; the placeholder keeps the thread intact
+	}
+	return pbComment
+}
+
+// parseCommentID accepts a comment ID or a comment resource name and returns the stored ID.
+// Every comment ID in a request goes through here, so the accepted forms are the same for all
+// RPCs. Failures are InvalidArgument with reason INVALID_COMMENT_ID, or HEX_COMMENT_ID_REJECTED
+// for a bare hex ID after the deprecation window.
+func (s *commentServer) parseCommentID(field, value string) (string, error) {
+	id, err := resourcename.ParseCommentID(value, s.acceptHexIDs)
+	if err != nil {
+		reason := "INVALID_COMMENT_ID"
+		if errors.Is(err, resourcename.ErrHexCommentID) {
+			reason = "HEX_COMMENT_ID_REJECTED"
+		}
+		return "", statusWithReason(codes.InvalidArgument, fmt.Sprintf("invalid %s: %v", field, err), reason, map[string]string{"field": field})
+	}
+	return id, nil
+}
+
+// parseParentID normalizes an optional parent comment ID
+func (s *commentServer) parseParentID(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := s.parseCommentID("parent_id", *value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// commentSorts maps the sorts of comment list requests onto model sorts; UNSPECIFIED selects
+// the list's default
+var commentSorts = map[pb.CommentSort]string{
+	pb.CommentSort_COMMENT_SORT_NEWEST:       models.CommentSortNewest,
+	pb.CommentSort_COMMENT_SORT_OLDEST:       models.CommentSortOldest,
+	pb.CommentSort_COMMENT_SORT_MOST_REPLIES: models.CommentSortMostReplies,
+}
+
+// commentSort converts the sort of a comment list request of the given kind, rejecting values
+// the list does not support
+func commentSort(value pb.CommentSort, kind pagetoken.Kind) (string, error) {
+	if value == pb.CommentSort_COMMENT_SORT_UNSPECIFIED {
+		return commentDefaultSorts[kind], nil
+	}
+	sort, ok := commentSorts[value]
+	if !ok {
+		return "", status.Error(codes.InvalidArgument, fmt.Sprintf("unknown sort %d", value))
+	}
+	if kind == pagetoken.KindCommentReply && sort == models.CommentSortMostReplies {
+		return "", status.Error(codes.InvalidArgument, "replies can only be sorted NEWEST or OLDEST")
+	}
+	return sort, nil
+}
+
+// renderFormats maps protobuf render formats to renderer formats
+var renderFormats = map[pb.RenderFormat]render.Format{
+	pb.RenderFormat_RENDER_FORMAT_RAW:        render.FormatRaw,
+	pb.RenderFormat_RENDER_FORMAT_PLAIN_TEXT: render.FormatPlainText,
+	pb.RenderFormat_RENDER_FORMAT_SAFE_HTML:  render.FormatSafeHTML,
+}
+
+// renderFormat validates a requested render format
+func renderFormat(format pb.RenderFormat) (render.Format, error) {
+	f, ok := renderFormats[format]
+	if !ok {
+		return render.FormatRaw, status.Error(codes.InvalidArgument, "unsupported render format")
+	}
+	return f, nil
+}
+
+// renderComment fills the rendered preview of a comment; RAW and hidden comments leave it unset
+func (s *commentServer) renderComment(pbComment *pb.Comment, comment *models.Comment, format render.Format) {
+	if format == render.FormatRaw || comment.IsHidden() {
+		return
+	}
+	result := s.renderer.Render(pbComment.Id, comment.UpdatedAt, comment.Content, format)
+	pbComment.RenderedContent = &result.Content
+	pbComment.RenderedTruncated = result.Truncated
+}
+
 // CreateComment creates a new comment
 func (s *commentServer) CreateComment(ctx context.Context, req *pb.CreateCommentRequest) (*pb.Comment, error) {
 	s.logger.Info("gRPC CreateComment received",
 		"content_id", req.ContentId,
+		"content_ref", req.ContentRef,
 		"user_id", req.UserId,
 		"parent_id", req.ParentId,
 		"type", req.Type,
 	)
 
-	if req.ContentId <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "content_id is required")
-	}
 	if req.UserId <= 0 {
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
@@ -58,31 +202,34 @@ func (s *commentServer) CreateComment(ctx context.Context, req *pb.CreateComment
 	if req.Type == "" {
 		return nil, status.Error(codes.InvalidArgument, "type is required")
 	}
-	if req.Type != "lab" && req.Type != "article" {
-		return nil, status.Error(codes.InvalidArgument, "type must be 'lab' or 'article'")
+	if !models.IsValidCommentType(req.Type) {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("type must be one of: %s", strings.Join(models.CommentTypes(), ", ")))
+	}
+	if _, err := service.ValidateCommentTarget(req.Type, req.ContentId, req.ContentRef); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+	if err := service.ValidateCourseID(req.CourseId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	var parentID *string
-	if req.ParentId != nil {
-		parentID = req.ParentId
+	parentID, err := s.parseParentID(req.ParentId)
+	if err != nil {
+		return nil, err
 	}
 
-	comment, err := s.commentService.CreateComment(ctx, req.ContentId, req.UserId, parentID, req.Content, req.Type)
+	comment, err := s.commentService.CreateComment(ctx, req.ContentId, req.ContentRef, req.UserId, parentID, req.Content, req.Type, req.CourseId)
+	var depthErr *service.CommentDepthError
+	if errors.As(err, &depthErr) {
+		s.logger.Warn("gRPC CreateComment: reply too deep", "parent_id", req.ParentId, "error", err)
+		return nil, statusWithReason(codes.FailedPrecondition, err.Error(), "COMMENT_DEPTH_EXCEEDED",
+			map[string]string{"max_depth": strconv.Itoa(int(depthErr.MaxDepth))})
+	}
 	if err != nil {
 		s.logger.Error("gRPC CreateComment failed", "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to create comment: %v", err))
+		return nil, storageError(err, "failed to create comment")
 	}
 
-	response := &pb.Comment{
-		Id:        comment.ID.Hex(),
-		ContentId: comment.ContentID,
-		UserId:    comment.UserID,
-		ParentId:  comment.ParentID,
-		Content:   comment.Content,
-		CreatedAt: timestamppb.New(comment.CreatedAt),
-		UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		Type:      comment.Type,
-	}
+	response := convertToProtoComment(comment)
 
 	s.logger.Info("gRPC CreateComment completed", "comment_id", response.Id)
 	return response, nil
@@ -95,23 +242,27 @@ func (s *commentServer) GetComment(ctx context.Context, req *pb.GetCommentReques
 	if req.Id == "" {
 		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
 	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+	format, err := renderFormat(req.Render)
+	if err != nil {
+		return nil, err
+	}
 
-	comment, err := s.commentService.GetComment(ctx, req.Id)
+	if req.ViewerUserId < 0 {
+		return nil, status.Error(codes.InvalidArgument, "viewer_user_id must not be negative")
+	}
+
+	comment, err := s.commentService.GetCommentWithReactions(ctx, id, req.ViewerUserId)
 	if err != nil {
 		s.logger.Error("gRPC GetComment failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get comment: %v", err))
+		return nil, readError(ctx, err, "failed to get comment")
 	}
 
-	response := &pb.Comment{
-		Id:        comment.ID.Hex(),
-		ContentId: comment.ContentID,
-		UserId:    comment.UserID,
-		ParentId:  comment.ParentID,
-		Content:   comment.Content,
-		CreatedAt: timestamppb.New(comment.CreatedAt),
-		UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		Type:      comment.Type,
-	}
+	response := convertToProtoComment(comment)
+	s.renderComment(response, comment, format)
 
 	s.logger.Info("gRPC GetComment completed", "id", response.Id)
 	return response, nil
@@ -134,43 +285,61 @@ func (s *commentServer) UpdateComment(ctx context.Context, req *pb.UpdateComment
 	if req.Content == "" {
 		return nil, status.Error(codes.InvalidArgument, "content is required")
 	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if comment exists and user is authorized
-	existingComment, err := s.commentService.GetComment(ctx, req.Id)
+	// The service checks that the comment exists and the user may still edit it
+	comment, err := s.commentService.UpdateComment(ctx, id, req.UserId, req.Content)
 	if err != nil {
-		s.logger.Warn("gRPC UpdateComment: comment not found", "id", req.Id, "error", err)
-		return nil, status.Error(codes.NotFound, "comment not found")
+		s.logger.Warn("gRPC UpdateComment failed", "id", req.Id, "user_id", req.UserId, "error", err)
+		return nil, storageError(err, "failed to update comment")
 	}
 
-	// Authorization check: only the comment author can update it
-	if existingComment.UserID != req.UserId {
-		s.logger.Warn("gRPC UpdateComment: permission denied",
-			"id", req.Id,
-			"user_id", req.UserId,
-			"owner_id", existingComment.UserID,
-		)
-		return nil, status.Error(codes.PermissionDenied, "you can only update your own comments")
+	response := convertToProtoComment(comment)
+
+	s.logger.Info("gRPC UpdateComment completed", "id", response.Id)
+	return response, nil
+}
+
+// GetCommentEditHistory returns the previous versions of a comment to its author and
+// administrators
+func (s *commentServer) GetCommentEditHistory(ctx context.Context, req *pb.GetCommentEditHistoryRequest) (*pb.GetCommentEditHistoryResponse, error) {
+	s.logger.Info("gRPC GetCommentEditHistory received", "id", req.Id, "user_id", req.UserId)
+
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
 	}
 
-	comment, err := s.commentService.UpdateComment(ctx, req.Id, req.Content)
+	comment, edits, err := s.commentService.GetCommentEditHistory(ctx, id, authz.FromContext(ctx, req.UserId))
 	if err != nil {
-		s.logger.Error("gRPC UpdateComment failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to update comment: %v", err))
+		s.logger.Warn("gRPC GetCommentEditHistory failed", "id", req.Id, "user_id", req.UserId, "error", err)
+		return nil, storageError(err, "failed to get comment edit history")
 	}
 
-	response := &pb.Comment{
-		Id:        comment.ID.Hex(),
-		ContentId: comment.ContentID,
-		UserId:    comment.UserID,
-		ParentId:  comment.ParentID,
-		Content:   comment.Content,
-		CreatedAt: timestamppb.New(comment.CreatedAt),
-		UpdatedAt: timestamppb.New(comment.UpdatedAt),
-		Type:      comment.Type,
+	pbEdits := make([]*pb.CommentEdit, len(edits))
+	for i, edit := range edits {
+		pbEdits[i] = &pb.CommentEdit{
+			Content:    edit.Content,
+			WrittenAt:  timestamppb.New(edit.WrittenAt),
+			ReplacedAt: timestamppb.New(edit.ReplacedAt),
+		}
 	}
 
-	s.logger.Info("gRPC UpdateComment completed", "id", response.Id)
-	return response, nil
+	s.logger.Info("gRPC GetCommentEditHistory completed", "id", req.Id, "edits", len(edits), "edit_count", comment.EditCount)
+	return &pb.GetCommentEditHistoryResponse{
+		Edits:     pbEdits,
+		EditCount: comment.EditCount,
+		Comment:   convertToProtoComment(comment),
+	}, nil
 }
 
 // DeleteComment deletes a comment
@@ -187,27 +356,15 @@ func (s *commentServer) DeleteComment(ctx context.Context, req *pb.DeleteComment
 	if req.Id == "" {
 		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
 	}
-
-	// Check if comment exists and user is authorized
-	existingComment, err := s.commentService.GetComment(ctx, req.Id)
+	id, err := s.parseCommentID("id", req.Id)
 	if err != nil {
-		s.logger.Warn("gRPC DeleteComment: comment not found", "id", req.Id, "error", err)
-		return nil, status.Error(codes.NotFound, "comment not found")
-	}
-
-	// Authorization check: only the comment author can delete it
-	if existingComment.UserID != req.UserId {
-		s.logger.Warn("gRPC DeleteComment: permission denied",
-			"id", req.Id,
-			"user_id", req.UserId,
-			"owner_id", existingComment.UserID,
-		)
-		return nil, status.Error(codes.PermissionDenied, "you can only delete your own comments")
+		return nil, err
 	}
 
-	if err := s.commentService.DeleteComment(ctx, req.Id); err != nil {
-		s.logger.Error("gRPC DeleteComment failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete comment: %v", err))
+	// The service checks that the comment exists and the user may delete it
+	if err := s.commentService.DeleteComment(ctx, id, req.UserId); err != nil {
+		s.logger.Warn("gRPC DeleteComment failed", "id", req.Id, "user_id", req.UserId, "error", err)
+		return nil, storageError(err, "failed to delete comment")
 	}
 
 	response := &pb.DeleteCommentResponse{Success: true}
@@ -219,51 +376,129 @@ func (s *commentServer) DeleteComment(ctx context.Context, req *pb.DeleteComment
 func (s *commentServer) ListComments(ctx context.Context, req *pb.ListCommentsRequest) (*pb.ListCommentsResponse, error) {
 	s.logger.Info("gRPC ListComments received",
 		"content_id", req.ContentId,
+		"content_ref", req.ContentRef,
 		"parent_id", req.ParentId,
+		"user_ids", len(req.UserIds),
+		"unanswered_only", req.UnansweredOnly,
+		"sort", req.Sort,
 		"page", req.Page,
+		"page_token", req.PageToken != "",
 		"limit", req.Limit,
 		"type", req.Type,
+		"expand", req.Expand,
 	)
 
-	if req.ContentId <= 0 {
-		return nil, status.Error(codes.InvalidArgument, "content_id is required")
-	}
 	if req.Type == "" {
 		return nil, status.Error(codes.InvalidArgument, "type is required")
 	}
+	if _, err := service.ValidateCommentTarget(req.Type, req.ContentId, req.ContentRef); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+	expandFeedback, err := commentExpansions(req.Expand, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.UserIds) > models.MaxCommentFilterUsers {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("at most %d user_ids are allowed", models.MaxCommentFilterUsers))
+	}
+	rejectDuplicates := flags.FromContext(ctx).Enabled(flags.RejectDuplicateIDs)
+	for i, userID := range req.UserIds {
+		if userID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "user_ids must be positive")
+		}
+		if rejectDuplicates && slices.Contains(req.UserIds[:i], userID) {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("duplicate user ID %d at user_ids[%d]", userID, i))
+		}
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+	if req.UnansweredOnly && req.ParentId != nil {
+		return nil, status.Error(codes.InvalidArgument, "unanswered_only cannot be combined with parent_id")
+	}
+	if req.ViewerUserId < 0 {
+		return nil, status.Error(codes.InvalidArgument, "viewer_user_id must not be negative")
+	}
+	createdAfter, createdBefore, err := createdRange(req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	format, err := renderFormat(req.Render)
+	if err != nil {
+		return nil, err
+	}
 
-	var parentID *string
-	if req.ParentId != nil {
-		parentID = req.ParentId
+	sort, err := commentSort(req.Sort, pagetoken.KindComment)
+	if err != nil {
+		return nil, err
 	}
 
-	comments, totalCount, err := s.commentService.ListComments(ctx, req.ContentId, parentID, req.Page, req.Limit, req.Type)
+	parentID, err := s.parseParentID(req.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.decodeCommentPageToken(req.PageToken, pagetoken.KindComment, req.Page, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.CommentFilter{
+		ContentID:      req.ContentId,
+		ContentRef:     req.ContentRef,
+		ParentID:       parentID,
+		UserIDs:        req.UserIds,
+		CreatedAfter:   createdAfter,
+		CreatedBefore:  createdBefore,
+		UnansweredOnly: req.UnansweredOnly,
+		After:          after,
+		ViewerUserID:   req.ViewerUserId,
+		Page:           req.Page,
+		Limit:          req.Limit,
+		Type:           req.Type,
+		Sort:           sort,
+	}
+	comments, totalCount, next, err := s.commentService.ListComments(ctx, filter)
 	if err != nil {
 		s.logger.Error("gRPC ListComments failed", "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list comments: %v", err))
+		return nil, storageError(err, "failed to list comments")
+	}
+	nextPageToken, err := s.encodeCommentPageToken(pagetoken.KindComment, next)
+	if err != nil {
+		s.logger.Error("gRPC ListComments failed to encode page token", "error", err)
+		return nil, status.Error(codes.Internal, "failed to encode page token")
+	}
+
+	maxDepth, err := s.commentService.MaxDepthForCourse(ctx, req.CourseId)
+	if err != nil {
+		s.logger.Error("gRPC ListComments: failed to resolve course policy", "course_id", req.CourseId, "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to resolve course policy: %v", err))
 	}
 
 	pbComments := make([]*pb.Comment, len(comments))
 	for i, comment := range comments {
-		pbComments[i] = &pb.Comment{
-			Id:        comment.ID.Hex(),
-			ContentId: comment.ContentID,
-			UserId:    comment.UserID,
-			ParentId:  comment.ParentID,
-			Content:   comment.Content,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-			Type:      comment.Type,
-		}
+		pbComments[i] = convertToProtoComment(comment)
+		s.renderComment(pbComments[i], comment, format)
+		s.truncateListContent(pbComments[i])
+	}
+	var expansionFailed bool
+	if expandFeedback {
+		expansionFailed = !s.expandFeedbacks(ctx, comments, pbComments)
 	}
 
 	s.logger.Info("gRPC ListComments completed",
 		"count", len(comments),
 		"total_count", totalCount,
+		"expansion_failed", expansionFailed,
 	)
+	legacyCount, truncated := legacyTotalCount(totalCount)
 	return &pb.ListCommentsResponse{
-		Comments:   pbComments,
-		TotalCount: totalCount,
+		Comments:        pbComments,
+		TotalCount:      legacyCount,
+		MaxDepth:        maxDepth,
+		TotalCount64:    totalCount,
+		CountTruncated:  truncated,
+		NextPageToken:   nextPageToken,
+		ExpansionFailed: expansionFailed,
 	}, nil
 }
 
@@ -271,37 +506,56 @@ func (s *commentServer) ListComments(ctx context.Context, req *pb.ListCommentsRe
 func (s *commentServer) GetCommentReplies(ctx context.Context, req *pb.GetCommentRepliesRequest) (*pb.GetCommentRepliesResponse, error) {
 	s.logger.Info("gRPC GetCommentReplies received",
 		"comment_id", req.CommentId,
+		"sort", req.Sort,
 		"page", req.Page,
+		"page_token", req.PageToken != "",
 		"limit", req.Limit,
 	)
 
 	if req.CommentId == "" {
 		return nil, status.Error(codes.InvalidArgument, "comment_id is required")
 	}
+	commentID, err := s.parseCommentID("comment_id", req.CommentId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
 
-	comments, totalCount, err := s.commentService.GetCommentReplies(ctx, req.CommentId, req.Page, req.Limit)
+	sort, err := commentSort(req.Sort, pagetoken.KindCommentReply)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.decodeCommentPageToken(req.PageToken, pagetoken.KindCommentReply, req.Page, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, totalCount, next, err := s.commentService.GetCommentReplies(ctx, commentID, sort, after, req.Page, req.Limit)
 	if err != nil {
 		s.logger.Error("gRPC GetCommentReplies failed", "comment_id", req.CommentId, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get comment replies: %v", err))
+		return nil, storageError(err, "failed to get comment replies")
+	}
+	nextPageToken, err := s.encodeCommentPageToken(pagetoken.KindCommentReply, next)
+	if err != nil {
+		s.logger.Error("gRPC GetCommentReplies failed to encode page token", "comment_id", req.CommentId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to encode page token")
 	}
 
 	pbComments := make([]*pb.Comment, len(comments))
 	for i, comment := range comments {
-		pbComments[i] = &pb.Comment{
-			Id:        comment.ID.Hex(),
-			ContentId: comment.ContentID,
-			UserId:    comment.UserID,
-			ParentId:  comment.ParentID,
-			Content:   comment.Content,
-			CreatedAt: timestamppb.New(comment.CreatedAt),
-			UpdatedAt: timestamppb.New(comment.UpdatedAt),
-			Type:      comment.Type,
-		}
+		pbComments[i] = convertToProtoComment(comment)
+		s.truncateListContent(pbComments[i])
 	}
 
+	legacyCount, truncated := legacyTotalCount(totalCount)
 	response := &pb.GetCommentRepliesResponse{
-		Comments:   pbComments,
-		TotalCount: totalCount,
+		Comments:       pbComments,
+		TotalCount:     legacyCount,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+		NextPageToken:  nextPageToken,
 	}
 
 	s.logger.Info("gRPC GetCommentReplies completed",
@@ -311,3 +565,233 @@ func (s *commentServer) GetCommentReplies(ctx context.Context, req *pb.GetCommen
 	)
 	return response, nil
 }
+
+// ListUserComments lists the comments a user wrote, with their content and parent for deep links
+func (s *commentServer) ListUserComments(ctx context.Context, req *pb.ListUserCommentsRequest) (*pb.ListUserCommentsResponse, error) {
+	s.logger.Info("gRPC ListUserComments received",
+		"user_id", req.UserId,
+		"type", req.Type,
+		"page", req.Page,
+		"limit", req.Limit,
+	)
+
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	comments, totalCount, err := s.commentService.ListUserComments(ctx, req.UserId, req.Type, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Error("gRPC ListUserComments failed", "user_id", req.UserId, "error", err)
+		return nil, storageError(err, "failed to list user comments")
+	}
+
+	pbComments := make([]*pb.Comment, len(comments))
+	for i, comment := range comments {
+		pbComments[i] = convertToProtoComment(comment)
+		s.truncateListContent(pbComments[i])
+	}
+
+	legacyCount, truncated := legacyTotalCount(totalCount)
+	response := &pb.ListUserCommentsResponse{
+		Comments:       pbComments,
+		TotalCount:     legacyCount,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}
+
+	s.logger.Info("gRPC ListUserComments completed",
+		"user_id", req.UserId,
+		"count", len(comments),
+		"total_count", totalCount,
+	)
+	return response, nil
+}
+
+// CountComments returns the number of comments on a content
+func (s *commentServer) CountComments(ctx context.Context, req *pb.CountCommentsRequest) (*pb.CountCommentsResponse, error) {
+	s.logger.Info("gRPC CountComments received",
+		"content_id", req.ContentId,
+		"type", req.Type,
+		"include_replies", req.IncludeReplies,
+	)
+
+	if req.ContentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "content_id is required")
+	}
+	if req.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+
+	count, err := s.commentService.CountComments(ctx, req.ContentId, req.Type, req.IncludeReplies)
+	if err != nil {
+		s.logger.Error("gRPC CountComments failed", "content_id", req.ContentId, "error", err)
+		return nil, storageError(err, "failed to count comments")
+	}
+
+	s.logger.Info("gRPC CountComments completed", "content_id", req.ContentId, "count", count)
+	return &pb.CountCommentsResponse{Count: count}, nil
+}
+
+// BatchCountComments returns the number of comments on each of several contents with one query
+func (s *commentServer) BatchCountComments(ctx context.Context, req *pb.BatchCountCommentsRequest) (*pb.BatchCountCommentsResponse, error) {
+	s.logger.Info("gRPC BatchCountComments received",
+		"content_ids", len(req.ContentIds),
+		"type", req.Type,
+		"include_replies", req.IncludeReplies,
+	)
+
+	if len(req.ContentIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "content_ids is required")
+	}
+	if len(req.ContentIds) > models.MaxCommentCountContents {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("at most %d content_ids are allowed", models.MaxCommentCountContents))
+	}
+	if req.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+
+	counts, err := s.commentService.BatchCountComments(ctx, req.ContentIds, req.Type, req.IncludeReplies)
+	if err != nil {
+		s.logger.Error("gRPC BatchCountComments failed", "content_ids", len(req.ContentIds), "error", err)
+		return nil, storageError(err, "failed to count comments")
+	}
+
+	s.logger.Info("gRPC BatchCountComments completed", "contents", len(counts))
+	return &pb.BatchCountCommentsResponse{Counts: counts}, nil
+}
+
+// maxImportRecords limits the number of records accepted by a single import stream
+const maxImportRecords = 10000
+
+// ImportComments imports historical comments streamed by an administrator
+func (s *commentServer) ImportComments(stream pb.CommentService_ImportCommentsServer) error {
+	ctx := stream.Context()
+	s.logger.Info("gRPC ImportComments started")
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC ImportComments: permission denied", "error", err)
+		return err
+	}
+
+	var opts models.CommentImportOptions
+	var records []*models.CommentImportRecord
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.logger.Error("gRPC ImportComments: failed to receive record", "error", err)
+			return status.Error(codes.Internal, fmt.Sprintf("failed to receive record: %v", err))
+		}
+
+		switch data := req.Data.(type) {
+		case *pb.ImportCommentsRequest_Options:
+			if len(records) > 0 {
+				return status.Error(codes.InvalidArgument, "options must be sent before any record")
+			}
+			if err := checkSourceEncoding(data.Options.SourceEncoding); err != nil {
+				return err
+			}
+			opts = models.CommentImportOptions{
+				DryRun:         data.Options.DryRun,
+				SourceEncoding: data.Options.SourceEncoding,
+				Lenient:        data.Options.Lenient,
+			}
+		case *pb.ImportCommentsRequest_Record:
+			if len(records) >= maxImportRecords {
+				return status.Error(codes.InvalidArgument, fmt.Sprintf("import is limited to %d records", maxImportRecords))
+			}
+			record, err := s.importRecordFromProto(data.Record)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+		default:
+			return status.Error(codes.InvalidArgument, "empty import message")
+		}
+	}
+
+	results := s.commentService.ImportComments(ctx, records, opts)
+
+	response := &pb.ImportCommentsResponse{
+		Total:   int32(len(results)),
+		Results: make([]*pb.ImportCommentResult, len(results)),
+		DryRun:  opts.DryRun,
+	}
+	for i, result := range results {
+		pbResult := &pb.ImportCommentResult{
+			Index:              int32(result.Index),
+			SourceId:           result.SourceID,
+			CommentId:          resourcename.CommentID(result.CommentID),
+			Success:            result.Err == nil,
+			SourceEncoding:     result.SourceEncoding,
+			ReplacedCharacters: int32(result.Replaced),
+		}
+		response.ReplacedCharacters += int32(result.Replaced)
+		if result.Err != nil {
+			pbResult.Error = result.Err.Error()
+			response.Failed++
+		} else {
+			response.Imported++
+		}
+		response.Results[i] = pbResult
+	}
+
+	s.logger.Info("gRPC ImportComments completed",
+		"total", response.Total,
+		"imported", response.Imported,
+		"failed", response.Failed,
+		"replaced_characters", response.ReplacedCharacters,
+		"dry_run", opts.DryRun,
+	)
+	return stream.SendAndClose(response)
+}
+
+// importRecordFromProto converts an import record into its model representation. A malformed
+// parent_id rejects the whole stream, like other malformed messages.
+func (s *commentServer) importRecordFromProto(record *pb.ImportCommentRecord) (*models.CommentImportRecord, error) {
+	parentID, err := s.parseParentID(record.ParentId)
+	if err != nil {
+		return nil, err
+	}
+	if record.SourceEncoding != nil {
+		if err := checkSourceEncoding(*record.SourceEncoding); err != nil {
+			return nil, err
+		}
+	}
+
+	comment := models.Comment{
+		ContentID: record.ContentId,
+		UserID:    record.UserId,
+		ParentID:  parentID,
+		Content:   record.Content,
+		Type:      record.Type,
+	}
+	if record.CreatedAt != nil {
+		comment.CreatedAt = record.CreatedAt.AsTime()
+	}
+	if record.UpdatedAt != nil {
+		comment.UpdatedAt = record.UpdatedAt.AsTime()
+	}
+
+	return &models.CommentImportRecord{
+		SourceID:       record.SourceId,
+		ParentSourceID: record.ParentSourceId,
+		Comment:        comment,
+		RawContent:     record.RawContent,
+		SourceEncoding: record.SourceEncoding,
+	}, nil
+}
+
+// checkSourceEncoding rejects an import stream naming an encoding that cannot be decoded,
+// before any of its records are decoded
+func checkSourceEncoding(name string) error {
+	if textenc.Supported(name) {
+		return nil
+	}
+	return status.Error(codes.InvalidArgument, fmt.Sprintf("unsupported source_encoding %q; supported: %s", name, strings.Join(textenc.Names(), ", ")))
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"unicode/utf8"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// commentContentChunkBytes is the size of the content chunks streamed by GetCommentContent
+const commentContentChunkBytes = 32 * 1024
+
+// truncateListContent cuts the content of a comment in a list response to the configured size,
+// without splitting a UTF-8 sequence. Oversized legacy comments would otherwise make list pages
+// huge and can exceed the message size limit; GetCommentContent returns them whole.
+func (s *commentServer) truncateListContent(comment *pb.Comment) {
+	if len(comment.Content) <= s.listMaxBytes {
+		return
+	}
+	limit := s.listMaxBytes
+	for limit > 0 && !utf8.RuneStart(comment.Content[limit]) {
+		limit--
+	}
+	comment.Content = comment.Content[:limit]
+	comment.ContentTruncated = true
+}
+
+// GetCommentContent streams the full content of a comment: an info message with its size,
+// then the content in chunks
+func (s *commentServer) GetCommentContent(req *pb.GetCommentContentRequest, stream pb.CommentService_GetCommentContentServer) error {
+	ctx := stream.Context()
+	s.logger.Info("gRPC GetCommentContent received", "comment_id", req.CommentId)
+
+	if req.CommentId == "" {
+		return status.Error(codes.InvalidArgument, "comment_id is required")
+	}
+	id, err := s.parseCommentID("comment_id", req.CommentId)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.commentService.GetComment(ctx, id)
+	if err != nil {
+		s.logger.Error("gRPC GetCommentContent failed", "comment_id", req.CommentId, "error", err)
+		return readError(ctx, err, "failed to get comment")
+	}
+
+	content := comment.Content
+	if comment.IsHidden() {
+		content = "" // Removed by a moderator, as in list responses
+	}
+	err = stream.Send(&pb.GetCommentContentResponse{
+		Data: &pb.GetCommentContentResponse_Info{
+			Info: &pb.CommentContentInfo{
+				Id:        resourcename.CommentID(comment.ID.Hex()),
+				Name:      resourcename.Comment(comment.Type, comment.ContentKey(), comment.ID.Hex()),
+				Size:      int64(len(content)),
+				UpdatedAt: timestamppb.New(comment.UpdatedAt),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Chunks are raw bytes and may split a UTF-8 sequence; clients join them before decoding
+	for start := 0; start < len(content); start += commentContentChunkBytes {
+		end := min(start+commentContentChunkBytes, len(content))
+		err := stream.Send(&pb.GetCommentContentResponse{
+			Data: &pb.GetCommentContentResponse_Chunk{Chunk: []byte(content[start:end])},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("gRPC GetCommentContent completed", "comment_id", req.CommentId, "size", len(content))
+	return nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// commentErasureModes maps protobuf erasure modes onto the modes of the comment service
+var commentErasureModes = map[pb.CommentErasureMode]string{
+	pb.CommentErasureMode_COMMENT_ERASURE_MODE_ANONYMIZE: models.CommentErasureAnonymize,
+	pb.CommentErasureMode_COMMENT_ERASURE_MODE_DELETE:    models.CommentErasureDelete,
+}
+
+// EraseUserComments erases the comments of a deleted user account (admin only)
+func (s *commentServer) EraseUserComments(ctx context.Context, req *pb.EraseUserCommentsRequest) (*pb.EraseUserCommentsResponse, error) {
+	s.logger.Info("gRPC EraseUserComments received",
+		"user_id", req.UserId,
+		"mode", req.Mode,
+		"actor_id", req.ActorId,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	mode, ok := commentErasureModes[req.Mode]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "mode must be ANONYMIZE or DELETE")
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+
+	result, err := s.commentService.EraseUserComments(ctx, req.UserId, mode, req.ActorId)
+	if err != nil {
+		s.logger.Error("gRPC EraseUserComments failed", "user_id", req.UserId, "error", err)
+		return nil, storageError(err, "failed to erase user comments")
+	}
+
+	response := &pb.EraseUserCommentsResponse{
+		Erased:      result.Erased,
+		TotalErased: result.Erasure.Erased,
+		Attempts:    result.Erasure.Attempts,
+		StartedAt:   timestamppb.New(result.Erasure.StartedAt),
+	}
+	if result.Erasure.CompletedAt != nil {
+		response.CompletedAt = timestamppb.New(*result.Erasure.CompletedAt)
+	}
+
+	s.logger.Info("gRPC EraseUserComments completed",
+		"user_id", req.UserId,
+		"erased", response.Erased,
+		"total_erased", response.TotalErased,
+	)
+	return response, nil
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// commentEventTypes maps the kinds of comment stream events onto protobuf event types
+var commentEventTypes = map[string]pb.CommentEventType{
+	models.CommentEventCreated: pb.CommentEventType_COMMENT_EVENT_TYPE_CREATED,
+	models.CommentEventUpdated: pb.CommentEventType_COMMENT_EVENT_TYPE_UPDATED,
+	models.CommentEventDeleted: pb.CommentEventType_COMMENT_EVENT_TYPE_DELETED,
+}
+
+// convertToProtoCommentEvent converts a model CommentStreamEvent to a protobuf CommentEvent,
+// cutting long content as comment lists do
+func (s *commentServer) convertToProtoCommentEvent(event models.CommentStreamEvent, stream string) (*pb.CommentEvent, error) {
+	comment := convertToProtoComment(event.Comment)
+	if event.Kind == models.CommentEventDeleted {
+		comment.CreatedAt, comment.UpdatedAt = nil, nil // Not kept for deleted comments
+	}
+	s.truncateListContent(comment)
+	resumeToken, err := encodeResumeToken(s.pageTokens, stream, event.Position)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CommentEvent{
+		Type:        commentEventTypes[event.Kind],
+		Comment:     comment,
+		OccurredAt:  timestamppb.New(event.OccurredAt),
+		Replayed:    event.Replayed,
+		Sequence:    event.Position.Sequence,
+		ResumeToken: resumeToken,
+	}, nil
+}
+
+// commentStreamName names the comment stream of a watch request in its resume tokens
+func commentStreamName(req *pb.WatchCommentsRequest) string {
+	return fmt.Sprintf("comments/%s/%d/%s", req.Type, req.ContentId, req.ContentRef)
+}
+
+// WatchComments streams changes to the comments on a content until the client cancels,
+// replacing polling of ListComments
+func (s *commentServer) WatchComments(req *pb.WatchCommentsRequest, stream pb.CommentService_WatchCommentsServer) error {
+	s.logger.Info("gRPC WatchComments received",
+		"content_id", req.ContentId,
+		"content_ref", req.ContentRef,
+		"type", req.Type,
+		"since", req.Since,
+		"resume_after", req.ResumeAfter != "",
+	)
+
+	var since *time.Time
+	if req.Since != nil {
+		if err := req.Since.CheckValid(); err != nil {
+			return status.Error(codes.InvalidArgument, "invalid since: "+err.Error())
+		}
+		t := req.Since.AsTime()
+		since = &t
+	}
+	streamName := commentStreamName(req)
+	resumeAfter, err := decodeResumeToken(s.pageTokens, streamName, req.ResumeAfter)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	opts := service.WatchOptions{Since: since, ResumeAfter: resumeAfter}
+	err = s.commentService.WatchComments(stream.Context(), req.Type, req.ContentId, req.ContentRef, opts, func(event models.CommentStreamEvent) error {
+		pbEvent, err := s.convertToProtoCommentEvent(event, streamName)
+		if err != nil {
+			return err
+		}
+		sent++
+		return stream.Send(pbEvent)
+	})
+	if err != nil {
+		s.logger.Warn("gRPC WatchComments failed", "content_id", req.ContentId, "content_ref", req.ContentRef, "sent", sent, "error", err)
+		return storageError(err, "failed to stream comment events")
+	}
+
+	s.logger.Info("gRPC WatchComments completed", "content_id", req.ContentId, "content_ref", req.ContentRef, "sent", sent)
+	return nil
+}
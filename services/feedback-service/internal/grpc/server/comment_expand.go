@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// commentExpansions validates the expand values of ListComments and reports whether the
+// discussed feedback is to be embedded
+func commentExpansions(expand []string, contentType string) (bool, error) {
+	var feedback bool
+	for _, value := range expand {
+		switch value {
+		case service.CommentExpandFeedback:
+			if contentType != models.CommentTypeFeedback {
+				return false, status.Error(codes.InvalidArgument, fmt.Sprintf("expand %q only applies to %q comments", value, models.CommentTypeFeedback))
+			}
+			feedback = true
+		default:
+			return false, status.Error(codes.InvalidArgument, fmt.Sprintf("unknown expand value %q", value))
+		}
+	}
+	return feedback, nil
+}
+
+// expandFeedbacks sets the feedback of converted comments, pbComments[i] being the conversion
+// of comments[i]. It reports false when the feedbacks could not be read; the comments are then
+// left unexpanded.
+func (s *commentServer) expandFeedbacks(ctx context.Context, comments []*models.Comment, pbComments []*pb.Comment) bool {
+	feedbacks, err := s.commentService.ExpandFeedbacks(ctx, comments)
+	if err != nil {
+		s.logger.Warn("gRPC ListComments: returning comments without their feedback", "error", err)
+		return false
+	}
+	for i, comment := range comments {
+		id, err := uuid.Parse(comment.ContentRef)
+		if err != nil {
+			continue
+		}
+		if feedback := feedbacks[id]; feedback != nil {
+			pbComments[i].Feedback = &pb.FeedbackRef{
+				Id:         feedback.ID.String(),
+				Title:      feedback.Title,
+				ReviewerId: feedback.ReviewerID,
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MergeCommentThreads moves the comments of a duplicate content onto the content it
+// duplicates (admin only)
+func (s *commentServer) MergeCommentThreads(ctx context.Context, req *pb.MergeCommentThreadsRequest) (*pb.MergeCommentThreadsResponse, error) {
+	s.logger.Info("gRPC MergeCommentThreads received",
+		"source_content_id", req.SourceContentId,
+		"target_content_id", req.TargetContentId,
+		"type", req.Type,
+		"actor_id", req.ActorId,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.SourceContentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "source_content_id is required")
+	}
+	if req.TargetContentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "target_content_id is required")
+	}
+	if req.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+
+	result, err := s.commentService.MergeCommentThreads(ctx, req.SourceContentId, req.TargetContentId, req.Type, req.ActorId)
+	if err != nil {
+		s.logger.Error("gRPC MergeCommentThreads failed", "source_content_id", req.SourceContentId, "error", err)
+		return nil, storageError(err, "failed to merge comment threads")
+	}
+
+	response := &pb.MergeCommentThreadsResponse{
+		Moved:          result.Moved,
+		TotalMoved:     result.Merge.Moved,
+		Attempts:       result.Merge.Attempts,
+		TargetComments: result.TargetComments,
+		StartedAt:      timestamppb.New(result.Merge.StartedAt),
+	}
+	if result.Merge.CompletedAt != nil {
+		response.CompletedAt = timestamppb.New(*result.Merge.CompletedAt)
+	}
+
+	s.logger.Info("gRPC MergeCommentThreads completed",
+		"moved", response.Moved,
+		"total_moved", response.TotalMoved,
+		"target_comments", response.TargetComments,
+	)
+	return response, nil
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// commentReportReasons maps protobuf report reasons onto model reasons
+var commentReportReasons = map[pb.CommentReportReason]string{
+	pb.CommentReportReason_COMMENT_REPORT_REASON_SPAM:       models.CommentReportSpam,
+	pb.CommentReportReason_COMMENT_REPORT_REASON_HARASSMENT: models.CommentReportHarassment,
+	pb.CommentReportReason_COMMENT_REPORT_REASON_OFF_TOPIC:  models.CommentReportOffTopic,
+	pb.CommentReportReason_COMMENT_REPORT_REASON_OTHER:      models.CommentReportOther,
+}
+
+// protoCommentReportReasons maps model report reasons back onto protobuf reasons
+var protoCommentReportReasons = map[string]pb.CommentReportReason{
+	models.CommentReportSpam:       pb.CommentReportReason_COMMENT_REPORT_REASON_SPAM,
+	models.CommentReportHarassment: pb.CommentReportReason_COMMENT_REPORT_REASON_HARASSMENT,
+	models.CommentReportOffTopic:   pb.CommentReportReason_COMMENT_REPORT_REASON_OFF_TOPIC,
+	models.CommentReportOther:      pb.CommentReportReason_COMMENT_REPORT_REASON_OTHER,
+}
+
+// commentModerationActions maps protobuf moderation actions onto model decisions
+var commentModerationActions = map[pb.CommentModerationAction]string{
+	pb.CommentModerationAction_COMMENT_MODERATION_ACTION_HIDE:    models.CommentModerationHide,
+	pb.CommentModerationAction_COMMENT_MODERATION_ACTION_DISMISS: models.CommentModerationDismiss,
+	pb.CommentModerationAction_COMMENT_MODERATION_ACTION_RESTORE: models.CommentModerationRestore,
+}
+
+// ReportComment records a user's report of a comment for moderators
+func (s *commentServer) ReportComment(ctx context.Context, req *pb.ReportCommentRequest) (*pb.ReportCommentResponse, error) {
+	s.logger.Info("gRPC ReportComment received", "id", req.Id, "reporter_user_id", req.ReporterUserId, "reason", req.Reason)
+
+	if req.ReporterUserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reporter_user_id is required")
+	}
+	reason, ok := commentReportReasons[req.Reason]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "reason must be SPAM, HARASSMENT, OFF_TOPIC or OTHER")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.commentService.ReportComment(ctx, id, req.ReporterUserId, reason, req.Note)
+	if err != nil {
+		s.logger.Warn("gRPC ReportComment failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to report comment")
+	}
+
+	s.logger.Info("gRPC ReportComment completed", "id", req.Id, "created", created)
+	return &pb.ReportCommentResponse{Created: created}, nil
+}
+
+// ListReportedComments lists the comments with open reports (admin only)
+func (s *commentServer) ListReportedComments(ctx context.Context, req *pb.ListReportedCommentsRequest) (*pb.ListReportedCommentsResponse, error) {
+	s.logger.Info("gRPC ListReportedComments received", "page", req.Page, "limit", req.Limit)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	reported, totalCount, err := s.commentService.ListReportedComments(ctx, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Error("gRPC ListReportedComments failed", "error", err)
+		return nil, storageError(err, "failed to list reported comments")
+	}
+
+	pbReported := make([]*pb.ReportedComment, len(reported))
+	for i, item := range reported {
+		pbComment := convertToProtoComment(item.Comment)
+		pbComment.Content = item.Comment.Content // Moderators see what was reported, hidden or not
+		s.truncateListContent(pbComment)
+
+		reasons := make([]*pb.ReportReasonCount, 0, len(item.ReasonCounts))
+		for reason, count := range item.ReasonCounts {
+			reasons = append(reasons, &pb.ReportReasonCount{Reason: protoCommentReportReasons[reason], Count: count})
+		}
+		slices.SortFunc(reasons, func(a, b *pb.ReportReasonCount) int { return cmp.Compare(a.Reason, b.Reason) })
+
+		reports := make([]*pb.CommentReport, len(item.RecentReports))
+		for j, report := range item.RecentReports {
+			reports[j] = &pb.CommentReport{
+				ReporterUserId: report.ReporterID,
+				Reason:         protoCommentReportReasons[report.Reason],
+				Note:           report.Note,
+				ReportedAt:     timestamppb.New(report.UpdatedAt),
+			}
+		}
+
+		pbReported[i] = &pb.ReportedComment{
+			Comment:        pbComment,
+			ReportCount:    item.ReportCount,
+			Reasons:        reasons,
+			RecentReports:  reports,
+			LastReportedAt: timestamppb.New(item.LastReportedAt),
+		}
+	}
+
+	legacyCount, truncated := legacyTotalCount(totalCount)
+	s.logger.Info("gRPC ListReportedComments completed", "count", len(reported), "total_count", totalCount)
+	return &pb.ListReportedCommentsResponse{
+		Comments:       pbReported,
+		TotalCount:     legacyCount,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}, nil
+}
+
+// ModerateComment hides a comment, dismisses its reports or restores it (admin only)
+func (s *commentServer) ModerateComment(ctx context.Context, req *pb.ModerateCommentRequest) (*pb.ModerateCommentResponse, error) {
+	s.logger.Info("gRPC ModerateComment received", "id", req.Id, "moderator_id", req.ModeratorId, "action", req.Action)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.ModeratorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "moderator_id is required")
+	}
+	action, ok := commentModerationActions[req.Action]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "action must be HIDE, DISMISS or RESTORE")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.commentService.ModerateComment(ctx, id, req.ModeratorId, action)
+	if err != nil {
+		s.logger.Warn("gRPC ModerateComment failed", "id", req.Id, "action", action, "error", err)
+		return nil, storageError(err, "failed to moderate comment")
+	}
+
+	response := &pb.ModerateCommentResponse{
+		Comment:         convertToProtoComment(result.Comment),
+		ResolvedReports: result.ResolvedReports,
+	}
+	s.logger.Info("gRPC ModerateComment completed", "id", req.Id, "action", action, "resolved_reports", result.ResolvedReports)
+	return response, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// commentReactions maps the reactions of ReactToComment requests onto model reactions
+var commentReactions = map[pb.CommentReaction]string{
+	pb.CommentReaction_COMMENT_REACTION_LIKE:    models.CommentReactionLike,
+	pb.CommentReaction_COMMENT_REACTION_DISLIKE: models.CommentReactionDislike,
+}
+
+// protoCommentReactions maps model reactions back onto protobuf reactions; "" is UNSPECIFIED
+var protoCommentReactions = map[string]pb.CommentReaction{
+	models.CommentReactionLike:    pb.CommentReaction_COMMENT_REACTION_LIKE,
+	models.CommentReactionDislike: pb.CommentReaction_COMMENT_REACTION_DISLIKE,
+}
+
+// ReactToComment sets the user's reaction to a comment, replacing an earlier one
+func (s *commentServer) ReactToComment(ctx context.Context, req *pb.ReactToCommentRequest) (*pb.Comment, error) {
+	s.logger.Info("gRPC ReactToComment received", "id", req.Id, "user_id", req.UserId, "reaction", req.Reaction)
+
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	reaction, ok := commentReactions[req.Reaction]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "reaction must be LIKE or DISLIKE")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentService.ReactToComment(ctx, id, req.UserId, reaction)
+	if err != nil {
+		s.logger.Warn("gRPC ReactToComment failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to react to comment")
+	}
+
+	response := convertToProtoComment(comment)
+	s.logger.Info("gRPC ReactToComment completed", "id", response.Id, "like_count", response.LikeCount, "dislike_count", response.DislikeCount)
+	return response, nil
+}
+
+// RemoveCommentReaction removes the user's reaction to a comment; removing none is not an error
+func (s *commentServer) RemoveCommentReaction(ctx context.Context, req *pb.RemoveCommentReactionRequest) (*pb.Comment, error) {
+	s.logger.Info("gRPC RemoveCommentReaction received", "id", req.Id, "user_id", req.UserId)
+
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	id, err := s.parseCommentID("id", req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentService.RemoveCommentReaction(ctx, id, req.UserId)
+	if err != nil {
+		s.logger.Warn("gRPC RemoveCommentReaction failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to remove comment reaction")
+	}
+
+	response := convertToProtoComment(comment)
+	s.logger.Info("gRPC RemoveCommentReaction completed", "id", response.Id, "like_count", response.LikeCount, "dislike_count", response.DislikeCount)
+	return response, nil
+}
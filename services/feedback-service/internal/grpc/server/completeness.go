@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// completenessStatuses maps model completeness states to protobuf statuses
+var completenessStatuses = map[string]pb.CompletenessStatus{
+	models.CompletenessComplete: pb.CompletenessStatus_COMPLETENESS_STATUS_COMPLETE,
+	models.CompletenessPartial:  pb.CompletenessStatus_COMPLETENESS_STATUS_PARTIAL,
+	models.CompletenessMissing:  pb.CompletenessStatus_COMPLETENESS_STATUS_MISSING,
+}
+
+// GetFeedbackCompleteness reports which submissions have feedback and which expected reviewers
+// have not written any (admin only)
+func (s *FeedbackServer) GetFeedbackCompleteness(ctx context.Context, req *pb.GetFeedbackCompletenessRequest) (*pb.GetFeedbackCompletenessResponse, error) {
+	s.logger.Info("gRPC GetFeedbackCompleteness received",
+		"submissions", len(req.SubmissionIds),
+		"expected_reviewers", len(req.ExpectedReviewerIds),
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC GetFeedbackCompleteness: permission denied")
+		return nil, err
+	}
+
+	results, err := s.feedbackService.GetFeedbackCompleteness(ctx, req.SubmissionIds, req.ExpectedReviewerIds)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCompletenessRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		s.logger.Error("gRPC GetFeedbackCompleteness failed", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get feedback completeness: %v", err))
+	}
+
+	response := &pb.GetFeedbackCompletenessResponse{
+		Submissions: make([]*pb.SubmissionCompleteness, len(results)),
+		Summary:     &pb.CompletenessSummary{},
+	}
+	for i, result := range results {
+		response.Submissions[i] = &pb.SubmissionCompleteness{
+			SubmissionId:       result.SubmissionID,
+			FeedbackCount:      result.FeedbackCount,
+			PublishedCount:     result.PublishedCount,
+			DraftCount:         result.DraftCount,
+			ReviewerIds:        result.ReviewerIDs,
+			MissingReviewerIds: result.MissingReviewerIDs,
+			Status:             completenessStatuses[result.Status],
+		}
+		response.Summary.PublishedCount += result.PublishedCount
+		response.Summary.DraftCount += result.DraftCount
+		switch result.Status {
+		case models.CompletenessComplete:
+			response.Summary.Complete++
+		case models.CompletenessPartial:
+			response.Summary.Partial++
+		case models.CompletenessMissing:
+			response.Summary.Missing++
+		}
+	}
+
+	s.logger.Info("gRPC GetFeedbackCompleteness completed",
+		"submissions", len(results),
+		"complete", response.Summary.Complete,
+		"partial", response.Summary.Partial,
+		"missing", response.Summary.Missing,
+	)
+	return response, nil
+}
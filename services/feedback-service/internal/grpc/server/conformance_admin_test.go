@@ -0,0 +1,577 @@
+package server_test
+
+import (
+	"slices"
+	"testing"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server/servertest"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAdminOnlyRPCs(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"SealFeedback", func() error {
+			_, err := srv.Feedback.SealFeedback(ctx, &pb.SealFeedbackRequest{FeedbackId: feedback.Id, ActorId: 1})
+			return err
+		}},
+		{"GetFeedbackSeal", func() error {
+			_, err := srv.Feedback.GetFeedbackSeal(ctx, &pb.GetFeedbackSealRequest{FeedbackId: feedback.Id})
+			return err
+		}},
+		{"VerifyFeedbackSeal", func() error {
+			_, err := srv.Feedback.VerifyFeedbackSeal(ctx, &pb.VerifyFeedbackSealRequest{FeedbackId: feedback.Id})
+			return err
+		}},
+		{"SetCoursePolicy", func() error {
+			_, err := srv.Feedback.SetCoursePolicy(ctx, &pb.SetCoursePolicyRequest{CourseId: "c1", Policy: "{}", ActorId: 1})
+			return err
+		}},
+		{"GetCoursePolicy", func() error {
+			_, err := srv.Feedback.GetCoursePolicy(ctx, &pb.GetCoursePolicyRequest{CourseId: "c1"})
+			return err
+		}},
+		{"ListCoursePolicies", func() error {
+			_, err := srv.Feedback.ListCoursePolicies(ctx, &pb.ListCoursePoliciesRequest{})
+			return err
+		}},
+		{"DeleteCoursePolicy", func() error {
+			_, err := srv.Feedback.DeleteCoursePolicy(ctx, &pb.DeleteCoursePolicyRequest{CourseId: "c1", ActorId: 1})
+			return err
+		}},
+		{"GetFeedbackCreationRate", func() error {
+			_, err := srv.Feedback.GetFeedbackCreationRate(ctx, &pb.GetFeedbackCreationRateRequest{Scope: &pb.GetFeedbackCreationRateRequest_ReviewerId{ReviewerId: reviewerID}})
+			return err
+		}},
+		{"GetFeedbackCompleteness", func() error {
+			_, err := srv.Feedback.GetFeedbackCompleteness(ctx, &pb.GetFeedbackCompletenessRequest{SubmissionIds: []int64{submissionID}})
+			return err
+		}},
+		{"GetTransferLeaderboard", func() error {
+			_, err := srv.Feedback.GetTransferLeaderboard(ctx, &pb.GetTransferLeaderboardRequest{})
+			return err
+		}},
+		{"RunRetention", func() error {
+			_, err := srv.Feedback.RunRetention(ctx, &pb.RunRetentionRequest{DryRun: true})
+			return err
+		}},
+		{"ReconcileFeedback", func() error {
+			_, err := srv.Feedback.ReconcileFeedback(ctx, &pb.ReconcileFeedbackRequest{})
+			return err
+		}},
+		{"GetBackgroundJobs", func() error {
+			_, err := srv.Feedback.GetBackgroundJobs(ctx, &pb.GetBackgroundJobsRequest{})
+			return err
+		}},
+		{"GetDiagnostics", func() error {
+			_, err := srv.Feedback.GetDiagnostics(ctx, &pb.GetDiagnosticsRequest{})
+			return err
+		}},
+		{"ConsistencyReport", func() error {
+			stream, err := srv.Feedback.ConsistencyReport(ctx, &pb.ConsistencyReportRequest{})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		}},
+		{"RenderFeedbackNotification", func() error {
+			_, err := srv.Feedback.RenderFeedbackNotification(servertest.Admin(ctx), &pb.RenderFeedbackNotificationRequest{FeedbackId: feedback.Id})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(), codes.PermissionDenied)
+		})
+	}
+}
+
+func TestFeedbackSeal(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := servertest.Admin(testContext(t))
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	upload(t, srv, feedback.Id, "evidence.txt", []byte("evidence"))
+
+	_, err := srv.Feedback.GetFeedbackSeal(ctx, &pb.GetFeedbackSealRequest{FeedbackId: feedback.Id})
+	wantCode(t, err, codes.NotFound)
+
+	seal, err := srv.Feedback.SealFeedback(ctx, &pb.SealFeedbackRequest{FeedbackId: feedback.Id, ActorId: 1})
+	if err != nil {
+		t.Fatalf("SealFeedback() error = %v", err)
+	}
+	if seal.FeedbackId != feedback.Id || seal.ManifestSha256 == "" || seal.ChainSha256 == "" || seal.PreviousSha256 != "" {
+		t.Errorf("SealFeedback() = %v", seal)
+	}
+	stored, err := srv.Feedback.GetFeedbackSeal(ctx, &pb.GetFeedbackSealRequest{FeedbackId: feedback.Id})
+	if err != nil {
+		t.Fatalf("GetFeedbackSeal() error = %v", err)
+	}
+	if stored.SealId != seal.SealId {
+		t.Errorf("GetFeedbackSeal() seal_id = %d, want %d", stored.SealId, seal.SealId)
+	}
+
+	verified, err := srv.Feedback.VerifyFeedbackSeal(ctx, &pb.VerifyFeedbackSealRequest{FeedbackId: feedback.Id})
+	if err != nil {
+		t.Fatalf("VerifyFeedbackSeal() error = %v", err)
+	}
+	if !verified.Intact {
+		t.Errorf("VerifyFeedbackSeal() divergences = %v, want intact", verified.Divergences)
+	}
+
+	title := "Changed"
+	if _, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: feedback.Id, Title: &title}); err != nil {
+		t.Fatalf("UpdateFeedback() error = %v", err)
+	}
+	verified, err = srv.Feedback.VerifyFeedbackSeal(ctx, &pb.VerifyFeedbackSealRequest{FeedbackId: feedback.Id})
+	if err != nil {
+		t.Fatalf("VerifyFeedbackSeal() error = %v", err)
+	}
+	if verified.Intact || len(verified.Divergences) != 1 || verified.Divergences[0].Component != "title" {
+		t.Errorf("VerifyFeedbackSeal() = %v, want a title divergence", verified.Divergences)
+	}
+}
+
+func TestCoursePolicies(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := servertest.Admin(testContext(t))
+
+	policy, err := srv.Feedback.SetCoursePolicy(ctx, &pb.SetCoursePolicyRequest{CourseId: "course-1", Policy: `{"max_comment_depth": 3}`, ActorId: 1})
+	if err != nil {
+		t.Fatalf("SetCoursePolicy() error = %v", err)
+	}
+	if policy.Effective.GetMaxCommentDepth() != 3 {
+		t.Errorf("SetCoursePolicy() effective max_comment_depth = %d, want 3", policy.Effective.GetMaxCommentDepth())
+	}
+
+	_, err = srv.Feedback.SetCoursePolicy(ctx, &pb.SetCoursePolicyRequest{CourseId: "course-1", Policy: `{"unknown_key": 1}`, ActorId: 1})
+	wantCode(t, err, codes.InvalidArgument)
+
+	stored, err := srv.Feedback.GetCoursePolicy(ctx, &pb.GetCoursePolicyRequest{CourseId: "course-1"})
+	if err != nil {
+		t.Fatalf("GetCoursePolicy() error = %v", err)
+	}
+	if stored.UpdatedBy != 1 || stored.Effective.GetMaxCommentDepth() != 3 {
+		t.Errorf("GetCoursePolicy() = %v", stored)
+	}
+
+	list, err := srv.Feedback.ListCoursePolicies(ctx, &pb.ListCoursePoliciesRequest{})
+	if err != nil {
+		t.Fatalf("ListCoursePolicies() error = %v", err)
+	}
+	if len(list.Policies) != 1 || list.Defaults.GetMaxCommentDepth() != int32(srv.Config.Comments.MaxDepth) {
+		t.Errorf("ListCoursePolicies() = %v", list)
+	}
+
+	deleted, err := srv.Feedback.DeleteCoursePolicy(ctx, &pb.DeleteCoursePolicyRequest{CourseId: "course-1", ActorId: 1})
+	if err != nil {
+		t.Fatalf("DeleteCoursePolicy() error = %v", err)
+	}
+	if !deleted.Success {
+		t.Error("DeleteCoursePolicy() success = false")
+	}
+	_, err = srv.Feedback.GetCoursePolicy(ctx, &pb.GetCoursePolicyRequest{CourseId: "course-1"})
+	wantCode(t, err, codes.NotFound)
+}
+
+// TestCoursePolicyPrecedence checks that the attachment limits of a course policy replace the
+// global ones for the course's feedbacks only
+func TestCoursePolicyPrecedence(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	if _, err := srv.Feedback.SetCoursePolicy(servertest.Admin(ctx), &pb.SetCoursePolicyRequest{
+		CourseId: "course-1",
+		Policy:   `{"max_attachments_per_feedback": 2, "max_attachment_bytes_per_feedback": 10}`,
+		ActorId:  1,
+	}); err != nil {
+		t.Fatalf("SetCoursePolicy() error = %v", err)
+	}
+	create := func(submission int64, courseID string) string {
+		t.Helper()
+		feedback, err := srv.Feedback.CreateFeedback(ctx, &pb.CreateFeedbackRequest{
+			ReviewerId: reviewerID, StudentId: studentID, SubmissionId: submission, Title: "Review", Content: "Content", Publish: true, CourseId: courseID,
+		})
+		if err != nil {
+			t.Fatalf("CreateFeedback() error = %v", err)
+		}
+		return feedback.Id
+	}
+	inCourse, global := create(submissionID, "course-1"), create(submissionID+1, "")
+
+	t.Run("attachment count", func(t *testing.T) {
+		for _, filename := range []string{"1.txt", "2.txt"} {
+			upload(t, srv, inCourse, filename, []byte("x"))
+			upload(t, srv, global, filename, []byte("x"))
+		}
+		_, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+			ReviewerId: reviewerID, FeedbackId: inCourse, Filename: "3.txt", ContentType: "text/plain", TotalSize: 1,
+		}, []byte("x"))
+		wantCode(t, err, codes.FailedPrecondition)
+		wantReason(t, err, "ATTACHMENT_LIMIT_EXCEEDED")
+		// The global limit of 5 still applies elsewhere
+		upload(t, srv, global, "3.txt", []byte("x"))
+	})
+
+	t.Run("attachment bytes", func(t *testing.T) {
+		// Replacing 2.txt with 10 bytes next to the 1 byte of 1.txt exceeds the course's 10
+		data := []byte("1234567890")
+		sessionID := stageFiles(t, srv, inCourse)
+		if _, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+			ReviewerId: reviewerID, FeedbackId: inCourse, Filename: "2.txt", ContentType: "text/plain", TotalSize: int64(len(data)), SessionId: sessionID,
+		}, data); err != nil {
+			t.Fatalf("UploadAttachment(session_id) error = %v", err)
+		}
+		_, err := srv.Feedback.CommitUploadSession(ctx, &pb.CommitUploadSessionRequest{ReviewerId: reviewerID, SessionId: sessionID})
+		wantCode(t, err, codes.FailedPrecondition)
+		wantReason(t, err, "ATTACHMENT_LIMIT_EXCEEDED")
+
+		sessionID = stageFiles(t, srv, global)
+		if _, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+			ReviewerId: reviewerID, FeedbackId: global, Filename: "2.txt", ContentType: "text/plain", TotalSize: int64(len(data)), SessionId: sessionID,
+		}, data); err != nil {
+			t.Fatalf("UploadAttachment(session_id) error = %v", err)
+		}
+		if _, err := srv.Feedback.CommitUploadSession(ctx, &pb.CommitUploadSessionRequest{ReviewerId: reviewerID, SessionId: sessionID}); err != nil {
+			t.Errorf("CommitUploadSession() under the global limit error = %v", err)
+		}
+	})
+}
+
+func TestTemplates(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+
+	template, err := srv.Feedback.CreateTemplate(ctx, &pb.CreateTemplateRequest{ReviewerId: reviewerID, Name: "standard", Title: "Review of {lab}", Content: "Well done"})
+	if err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+	_, err = srv.Feedback.CreateTemplate(ctx, &pb.CreateTemplateRequest{ReviewerId: reviewerID, Name: "standard", Title: "Again"})
+	wantCode(t, err, codes.AlreadyExists)
+	_, err = srv.Feedback.CreateTemplate(ctx, &pb.CreateTemplateRequest{ReviewerId: reviewerID, Name: "empty"})
+	wantCode(t, err, codes.InvalidArgument)
+
+	updated, err := srv.Feedback.UpdateTemplate(ctx, &pb.UpdateTemplateRequest{ReviewerId: reviewerID, Id: template.Id, Name: "standard", Title: "Review", Content: "Well done"})
+	if err != nil {
+		t.Fatalf("UpdateTemplate() error = %v", err)
+	}
+	if updated.Title != "Review" {
+		t.Errorf("UpdateTemplate() title = %q, want Review", updated.Title)
+	}
+	_, err = srv.Feedback.UpdateTemplate(ctx, &pb.UpdateTemplateRequest{ReviewerId: otherUserID, Id: template.Id, Name: "mine", Title: "Mine"})
+	wantCode(t, err, codes.PermissionDenied)
+
+	list, err := srv.Feedback.ListTemplates(ctx, &pb.ListTemplatesRequest{ReviewerId: reviewerID})
+	if err != nil {
+		t.Fatalf("ListTemplates() error = %v", err)
+	}
+	if list.TotalCount != 1 || len(list.Templates) != 1 || list.Templates[0].Id != template.Id {
+		t.Errorf("ListTemplates() = %v", list)
+	}
+
+	feedback, err := srv.Feedback.CreateFeedback(ctx, &pb.CreateFeedbackRequest{ReviewerId: reviewerID, StudentId: studentID, SubmissionId: submissionID, TemplateId: template.Id})
+	if err != nil {
+		t.Fatalf("CreateFeedback(template_id) error = %v", err)
+	}
+	if feedback.Title != "Review" || feedback.Content != "Well done" {
+		t.Errorf("CreateFeedback(template_id) = %q/%q, want the template's title and content", feedback.Title, feedback.Content)
+	}
+
+	deleted, err := srv.Feedback.DeleteTemplate(ctx, &pb.DeleteTemplateRequest{ReviewerId: reviewerID, Id: template.Id})
+	if err != nil {
+		t.Fatalf("DeleteTemplate() error = %v", err)
+	}
+	if !deleted.Success {
+		t.Error("DeleteTemplate() success = false")
+	}
+	_, err = srv.Feedback.DeleteTemplate(ctx, &pb.DeleteTemplateRequest{ReviewerId: reviewerID, Id: template.Id})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestPermissions(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	tests := []struct {
+		name      string
+		principal int64
+		action    string
+		allowed   bool
+	}{
+		{name: "author updates", principal: reviewerID, action: "update", allowed: true},
+		{name: "student updates", principal: studentID, action: "update", allowed: false},
+		{name: "student acknowledges", principal: studentID, action: "acknowledge", allowed: true},
+		{name: "stranger acknowledges", principal: otherUserID, action: "acknowledge", allowed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.Feedback.GetPermissions(ctx, &pb.GetPermissionsRequest{ResourceName: feedback.Name, PrincipalId: tt.principal})
+			if err != nil {
+				t.Fatalf("GetPermissions() error = %v", err)
+			}
+			decision, ok := resp.Permissions[tt.action]
+			if !ok {
+				t.Fatalf("GetPermissions() has no %s decision", tt.action)
+			}
+			if decision.Allowed != tt.allowed {
+				t.Errorf("GetPermissions() %s allowed = %v, want %v", tt.action, decision.Allowed, tt.allowed)
+			}
+			if !decision.Allowed && decision.Reason == "" {
+				t.Errorf("GetPermissions() %s denied without a reason", tt.action)
+			}
+		})
+	}
+
+	_, err := srv.Feedback.GetPermissions(ctx, &pb.GetPermissionsRequest{ResourceName: "feedbacks/" + uuid.NewString(), PrincipalId: reviewerID})
+	wantCode(t, err, codes.NotFound)
+	_, err = srv.Feedback.GetPermissions(ctx, &pb.GetPermissionsRequest{ResourceName: "nonsense", PrincipalId: reviewerID})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestOperationsRPCs(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := servertest.Admin(testContext(t))
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	orphan := uuid.New()
+	srv.Store.SeedObject(orphan, "stray.txt", "text/plain", []byte("stray"))
+
+	stream, err := srv.Feedback.ConsistencyReport(ctx, &pb.ConsistencyReportRequest{FullScan: true})
+	if err != nil {
+		t.Fatalf("ConsistencyReport() error = %v", err)
+	}
+	entries := recvAll(t, stream.Recv)
+	if len(entries) != 2 {
+		t.Fatalf("ConsistencyReport() sent %d entries, want a finding and the summary", len(entries))
+	}
+	finding := entries[0].GetFinding()
+	if finding.GetKind() != "orphan_prefix" || finding.GetFeedbackId() != orphan.String() || finding.GetObjectCount() != 1 {
+		t.Errorf("ConsistencyReport() finding = %v", finding)
+	}
+	summary := entries[1].GetSummary()
+	if summary.GetFeedbacksChecked() != 1 || summary.GetOrphanPrefixes() != 1 {
+		t.Errorf("ConsistencyReport() summary = %v", summary)
+	}
+
+	retention, err := srv.Feedback.RunRetention(ctx, &pb.RunRetentionRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("RunRetention() error = %v", err)
+	}
+	if len(retention.Results) != 4 {
+		t.Errorf("RunRetention() = %d results, want one per dataset", len(retention.Results))
+	}
+	for _, result := range retention.Results {
+		if result.Error != "" {
+			t.Errorf("RunRetention() %s error = %s", result.Dataset, result.Error)
+		}
+	}
+	_, err = srv.Feedback.RunRetention(ctx, &pb.RunRetentionRequest{Dataset: "unknown"})
+	wantCode(t, err, codes.InvalidArgument)
+
+	reconciled, err := srv.Feedback.ReconcileFeedback(ctx, &pb.ReconcileFeedbackRequest{FeedbackId: feedback.Name})
+	if err != nil {
+		t.Fatalf("ReconcileFeedback() error = %v", err)
+	}
+	if reconciled.Applied != 0 || reconciled.Failed != 0 || reconciled.Remaining != 0 {
+		t.Errorf("ReconcileFeedback() = %v, want nothing pending", reconciled)
+	}
+
+	jobs, err := srv.Feedback.GetBackgroundJobs(ctx, &pb.GetBackgroundJobsRequest{})
+	if err != nil {
+		t.Fatalf("GetBackgroundJobs() error = %v", err)
+	}
+	if jobs.ElectionEnabled {
+		t.Error("GetBackgroundJobs() election_enabled = true with LEADER_ELECTION_ENABLED=false")
+	}
+
+	diagnostics, err := srv.Feedback.GetDiagnostics(ctx, &pb.GetDiagnosticsRequest{})
+	if err != nil {
+		t.Fatalf("GetDiagnostics() error = %v", err)
+	}
+	if len(diagnostics.FeatureFlags) == 0 {
+		t.Error("GetDiagnostics() reported no feature flags")
+	}
+}
+
+func TestReportingRPCs(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := servertest.Admin(testContext(t))
+	createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	rate, err := srv.Feedback.GetFeedbackCreationRate(ctx, &pb.GetFeedbackCreationRateRequest{
+		Scope:      &pb.GetFeedbackCreationRateRequest_ReviewerId{ReviewerId: reviewerID},
+		BucketSize: "hour",
+	})
+	if err != nil {
+		t.Fatalf("GetFeedbackCreationRate() error = %v", err)
+	}
+	if rate.TotalCount != 1 || len(rate.Buckets) != 24 && len(rate.Buckets) != 25 {
+		t.Errorf("GetFeedbackCreationRate() total = %d over %d buckets", rate.TotalCount, len(rate.Buckets))
+	}
+	_, err = srv.Feedback.GetFeedbackCreationRate(ctx, &pb.GetFeedbackCreationRateRequest{
+		Scope:      &pb.GetFeedbackCreationRateRequest_ReviewerId{ReviewerId: reviewerID},
+		BucketSize: "fortnight",
+	})
+	wantCode(t, err, codes.InvalidArgument)
+
+	createFeedback(t, srv, submissionID+2, "Draft", "Content", true)
+	completeness, err := srv.Feedback.GetFeedbackCompleteness(ctx, &pb.GetFeedbackCompletenessRequest{
+		SubmissionIds:       []int64{submissionID, submissionID + 1, submissionID + 2},
+		ExpectedReviewerIds: []int64{reviewerID},
+	})
+	if err != nil {
+		t.Fatalf("GetFeedbackCompleteness() error = %v", err)
+	}
+	wantSubmissions := []struct {
+		status    pb.CompletenessStatus
+		published int64
+		drafts    int64
+	}{
+		{pb.CompletenessStatus_COMPLETENESS_STATUS_COMPLETE, 1, 0},
+		{pb.CompletenessStatus_COMPLETENESS_STATUS_MISSING, 0, 0},
+		{pb.CompletenessStatus_COMPLETENESS_STATUS_MISSING, 0, 1},
+	}
+	if len(completeness.Submissions) != len(wantSubmissions) {
+		t.Fatalf("GetFeedbackCompleteness() = %v", completeness.Submissions)
+	}
+	for i, want := range wantSubmissions {
+		got := completeness.Submissions[i]
+		if got.Status != want.status || got.PublishedCount != want.published || got.DraftCount != want.drafts ||
+			got.FeedbackCount != want.published+want.drafts {
+			t.Errorf("GetFeedbackCompleteness() submission %d = %v, want %v with %d published and %d drafts",
+				got.SubmissionId, got, want.status, want.published, want.drafts)
+		}
+	}
+	if got := completeness.Submissions[2]; len(got.ReviewerIds) != 1 || len(got.MissingReviewerIds) != 0 {
+		t.Errorf("GetFeedbackCompleteness() draft-only submission = %v, want its reviewer listed and none missing", got)
+	}
+	if completeness.Summary.GetComplete() != 1 || completeness.Summary.GetMissing() != 2 ||
+		completeness.Summary.GetPublishedCount() != 1 || completeness.Summary.GetDraftCount() != 1 {
+		t.Errorf("GetFeedbackCompleteness() summary = %v", completeness.Summary)
+	}
+
+	// A reviewer with drafts only is not missing, but the submission is not complete either
+	_, err = srv.Feedback.CreateFeedback(testContext(t), &pb.CreateFeedbackRequest{
+		ReviewerId: otherUserID, StudentId: studentID, SubmissionId: submissionID + 2, Title: "Review", Content: "Content", Publish: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedback() error = %v", err)
+	}
+	completeness, err = srv.Feedback.GetFeedbackCompleteness(ctx, &pb.GetFeedbackCompletenessRequest{
+		SubmissionIds:       []int64{submissionID + 2},
+		ExpectedReviewerIds: []int64{reviewerID, otherUserID},
+	})
+	if err != nil {
+		t.Fatalf("GetFeedbackCompleteness() error = %v", err)
+	}
+	if got := completeness.Submissions[0]; got.Status != pb.CompletenessStatus_COMPLETENESS_STATUS_PARTIAL || len(got.MissingReviewerIds) != 0 {
+		t.Errorf("GetFeedbackCompleteness() with a reviewer's draft unpublished = %v, want PARTIAL with none missing", got)
+	}
+	_, err = srv.Feedback.GetFeedbackCompleteness(ctx, &pb.GetFeedbackCompletenessRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestConsistencyReport(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         *pb.ConsistencyReportRequest
+		wantFlagged bool // The feedback with a lost object is marked for re-upload
+		wantDeleted bool // The orphan prefix is removed
+	}{
+		{name: "report only", req: &pb.ConsistencyReportRequest{FullScan: true}},
+		{name: "mark for re-upload", req: &pb.ConsistencyReportRequest{FullScan: true, MarkForReupload: true}, wantFlagged: true},
+		{name: "delete orphans", req: &pb.ConsistencyReportRequest{FullScan: true, DeleteOrphans: true}, wantDeleted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := servertest.New(t, nil)
+			ctx := servertest.Admin(testContext(t))
+
+			intact := createFeedback(t, srv, submissionID, "Intact", "Content", false)
+			upload(t, srv, intact.Id, "kept.txt", []byte("kept"))
+			// A metadata row whose object is gone
+			lost := createFeedback(t, srv, submissionID+1, "Lost", "Content", false)
+			upload(t, srv, lost.Id, "kept.txt", []byte("kept"))
+			upload(t, srv, lost.Id, "lost.txt", []byte("lost"))
+			srv.Store.DropObject(uuid.MustParse(lost.Id), "lost.txt")
+			// An object without a feedback row
+			orphan := uuid.New()
+			srv.Store.SeedObject(orphan, "stray.txt", "text/plain", []byte("stray"))
+
+			report := func(req *pb.ConsistencyReportRequest) (map[string]*pb.ConsistencyFinding, *pb.ConsistencySummary) {
+				t.Helper()
+				stream, err := srv.Feedback.ConsistencyReport(ctx, req)
+				if err != nil {
+					t.Fatalf("ConsistencyReport() error = %v", err)
+				}
+				entries := recvAll(t, stream.Recv)
+				if len(entries) == 0 || entries[len(entries)-1].GetSummary() == nil {
+					t.Fatalf("ConsistencyReport() = %v, want the summary last", entries)
+				}
+				findings := make(map[string]*pb.ConsistencyFinding)
+				for _, entry := range entries[:len(entries)-1] {
+					findings[entry.GetFinding().GetKind()+" "+entry.GetFinding().GetFeedbackId()] = entry.GetFinding()
+				}
+				return findings, entries[len(entries)-1].GetSummary()
+			}
+
+			findings, summary := report(tt.req)
+			if len(findings) != 2 {
+				t.Errorf("ConsistencyReport() findings = %v, want the lost object and the orphan", findings)
+			}
+			missing := findings["missing_objects "+lost.Id]
+			if !slices.Equal(missing.GetMissingFilenames(), []string{"lost.txt"}) || missing.GetRepaired() != tt.wantFlagged || missing.GetRepairError() != "" {
+				t.Errorf("ConsistencyReport() missing finding = %v", missing)
+			}
+			stray := findings["orphan_prefix "+orphan.String()]
+			if stray.GetObjectCount() != 1 || stray.GetBytes() != int64(len("stray")) || stray.GetRepaired() != tt.wantDeleted || stray.GetRepairError() != "" {
+				t.Errorf("ConsistencyReport() orphan finding = %v", stray)
+			}
+			var wantRepaired int64
+			if tt.wantFlagged || tt.wantDeleted {
+				wantRepaired = 1
+			}
+			if summary.FeedbacksChecked != 2 || summary.PrefixesChecked != 3 || summary.Objects != 3 ||
+				summary.MissingObjects != 1 || summary.OrphanPrefixes != 1 || summary.Repaired != wantRepaired {
+				t.Errorf("ConsistencyReport() summary = %v", summary)
+			}
+
+			for _, feedback := range []*pb.Feedback{intact, lost} {
+				got, err := srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: feedback.Id})
+				if err != nil {
+					t.Fatalf("GetFeedbackById() error = %v", err)
+				}
+				if want := tt.wantFlagged && feedback == lost; got.NeedsReupload != want {
+					t.Errorf("GetFeedbackById(%s) needs_reupload = %v, want %v", feedback.Title, got.NeedsReupload, want)
+				}
+			}
+			if got := srv.Store.ObjectExists(orphan, "stray.txt"); got == tt.wantDeleted {
+				t.Errorf("orphan object exists = %v after the report", got)
+			}
+			if !tt.wantFlagged {
+				return
+			}
+
+			// Uploading the lost attachment again clears the flag and the finding
+			upload(t, srv, lost.Id, "lost.txt", []byte("lost"))
+			got, err := srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: lost.Id})
+			if err != nil {
+				t.Fatalf("GetFeedbackById() error = %v", err)
+			}
+			if got.NeedsReupload {
+				t.Error("GetFeedbackById() needs_reupload = true after the re-upload")
+			}
+			findings, summary = report(tt.req)
+			if _, ok := findings["missing_objects "+lost.Id]; ok || summary.MissingObjects != 0 {
+				t.Errorf("ConsistencyReport() after the re-upload = %v, %v", findings, summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,663 @@
+package server_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server/servertest"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository/repotest"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// uploadChunks streams an upload of the given metadata and chunks, in that order, and returns
+// the server's response. Send errors are ignored: a server that rejects the upload early
+// reports why from CloseAndRecv.
+func uploadChunks(t *testing.T, srv *servertest.Server, metadata *pb.AttachmentMetadata, chunks ...[]byte) (*pb.UploadAttachmentResponse, error) {
+	t.Helper()
+	stream, err := srv.Feedback.UploadAttachment(testContext(t))
+	if err != nil {
+		t.Fatalf("UploadAttachment() error = %v", err)
+	}
+	if metadata != nil {
+		stream.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Metadata{Metadata: metadata}})
+	}
+	for _, chunk := range chunks {
+		stream.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Chunk{Chunk: chunk}})
+	}
+	return stream.CloseAndRecv()
+}
+
+// upload stores data as an attachment of a feedback in chunks of at most 16KB
+func upload(t *testing.T, srv *servertest.Server, feedbackID, filename string, data []byte) {
+	t.Helper()
+	var chunks [][]byte
+	for chunk := range slices.Chunk(data, 16*1024) {
+		chunks = append(chunks, chunk)
+	}
+	resp, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+		ReviewerId:  reviewerID,
+		FeedbackId:  feedbackID,
+		Filename:    filename,
+		ContentType: "text/plain",
+		TotalSize:   int64(len(data)),
+	}, chunks...)
+	if err != nil {
+		t.Fatalf("UploadAttachment(%s) error = %v", filename, err)
+	}
+	if !resp.Success || resp.Size != int64(len(data)) {
+		t.Fatalf("UploadAttachment(%s) = %v", filename, resp)
+	}
+}
+
+// listFilenames returns the filenames of a feedback's attachments in listing order
+func listFilenames(t *testing.T, srv *servertest.Server, feedbackID string) []string {
+	t.Helper()
+	resp, err := srv.Feedback.ListAttachments(testContext(t), &pb.ListAttachmentsRequest{FeedbackId: feedbackID})
+	if err != nil {
+		t.Fatalf("ListAttachments() error = %v", err)
+	}
+	filenames := make([]string, len(resp.Attachments))
+	for i, attachment := range resp.Attachments {
+		filenames[i] = attachment.Filename
+	}
+	return filenames
+}
+
+func TestUploadFraming(t *testing.T) {
+	srv := servertest.New(t, nil)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	metadata := func(size int64) *pb.AttachmentMetadata {
+		return &pb.AttachmentMetadata{ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "notes.txt", ContentType: "text/plain", TotalSize: size}
+	}
+
+	tests := []struct {
+		name     string
+		metadata *pb.AttachmentMetadata
+		chunks   [][]byte
+		want     codes.Code
+	}{
+		{name: "no metadata", want: codes.InvalidArgument},
+		{name: "chunk before metadata", chunks: [][]byte{[]byte("data")}, want: codes.InvalidArgument},
+		{name: "metadata without data", metadata: metadata(4), want: codes.InvalidArgument},
+		{name: "fewer bytes than declared", metadata: metadata(8), chunks: [][]byte{[]byte("data")}, want: codes.InvalidArgument},
+		{name: "more bytes than declared", metadata: metadata(4), chunks: [][]byte{[]byte("da"), []byte("tadata")}, want: codes.Internal},
+		{name: "zero size without allow_empty", metadata: metadata(0), want: codes.InvalidArgument},
+		{name: "malformed feedback id", metadata: &pb.AttachmentMetadata{ReviewerId: reviewerID, FeedbackId: "nope", Filename: "a.txt", TotalSize: 1}, chunks: [][]byte{[]byte("a")}, want: codes.InvalidArgument},
+		{name: "another reviewer", metadata: &pb.AttachmentMetadata{ReviewerId: otherUserID, FeedbackId: feedback.Id, Filename: "a.txt", ContentType: "text/plain", TotalSize: 1}, chunks: [][]byte{[]byte("a")}, want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := uploadChunks(t, srv, tt.metadata, tt.chunks...)
+			wantCode(t, err, tt.want)
+		})
+	}
+	if got := listFilenames(t, srv, feedback.Id); len(got) != 0 {
+		t.Errorf("ListAttachments() = %v after rejected uploads, want none", got)
+	}
+
+	resp, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+		ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "empty.txt", ContentType: "text/plain", AllowEmpty: true,
+	})
+	if err != nil {
+		t.Fatalf("UploadAttachment(allow_empty) error = %v", err)
+	}
+	if !resp.Success || resp.Size != 0 {
+		t.Errorf("UploadAttachment(allow_empty) = %v", resp)
+	}
+
+	resp, err = uploadChunks(t, srv, metadata(4), []byte("da"), []byte("ta"))
+	if err != nil {
+		t.Fatalf("UploadAttachment() error = %v", err)
+	}
+	if !resp.Success || resp.Size != 4 || resp.Filename != "notes.txt" {
+		t.Errorf("UploadAttachment() = %v", resp)
+	}
+}
+
+func TestDownloadFraming(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	data := bytes.Repeat([]byte("0123456789abcdef"), 5*1024) // 80KB: two full chunks and a partial one
+	upload(t, srv, feedback.Id, "report.txt", data)
+
+	stream, err := srv.Feedback.DownloadAttachment(ctx, &pb.DownloadAttachmentRequest{FeedbackId: feedback.Id, Filename: "report.txt", RequesterId: studentID})
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	frames := recvAll(t, stream.Recv)
+	if len(frames) != 4 {
+		t.Fatalf("DownloadAttachment() sent %d frames, want an info frame and 3 chunks", len(frames))
+	}
+	info := frames[0].GetInfo()
+	if info == nil || info.Filename != "report.txt" || info.Size != int64(len(data)) {
+		t.Fatalf("DownloadAttachment() first frame = %v, want the attachment info", frames[0])
+	}
+	sum := sha256.Sum256(data)
+	if frames[0].ExpectedSha256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("DownloadAttachment() expected_sha256 = %q", frames[0].ExpectedSha256)
+	}
+	var received []byte
+	for i, frame := range frames[1:] {
+		if frame.GetInfo() != nil {
+			t.Fatalf("DownloadAttachment() frame %d is an info frame", i+1)
+		}
+		if i < 2 && len(frame.GetChunk()) != 32*1024 {
+			t.Errorf("DownloadAttachment() chunk %d = %d bytes, want 32KB", i, len(frame.GetChunk()))
+		}
+		received = append(received, frame.GetChunk()...)
+	}
+	if !bytes.Equal(received, data) {
+		t.Error("DownloadAttachment() content differs from the upload")
+	}
+
+	usage, err := srv.Feedback.GetTransferUsage(ctx, &pb.GetTransferUsageRequest{PrincipalId: studentID})
+	if err != nil {
+		t.Fatalf("GetTransferUsage() error = %v", err)
+	}
+	if usage.TotalBytesDownloaded != int64(len(data)) {
+		t.Errorf("GetTransferUsage() downloaded = %d, want %d", usage.TotalBytesDownloaded, len(data))
+	}
+	leaders, err := srv.Feedback.GetTransferLeaderboard(servertest.Admin(ctx), &pb.GetTransferLeaderboardRequest{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetTransferLeaderboard() error = %v", err)
+	}
+	if len(leaders.Principals) != 2 {
+		t.Errorf("GetTransferLeaderboard() = %v, want the uploader and the downloader", leaders.Principals)
+	}
+
+	missing, err := srv.Feedback.DownloadAttachment(ctx, &pb.DownloadAttachmentRequest{FeedbackId: feedback.Id, Filename: "missing.txt"})
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	_, err = missing.Recv()
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestReuploadChecksumNotRecorded(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	upload(t, srv, feedback.Id, "report.txt", []byte("first version"))
+
+	// download returns the expected checksum and content of the attachment, failing on a
+	// verification error
+	download := func(t *testing.T) (string, []byte, error) {
+		t.Helper()
+		stream, err := srv.Feedback.DownloadAttachment(ctx, &pb.DownloadAttachmentRequest{FeedbackId: feedback.Id, Filename: "report.txt", RequesterId: studentID})
+		if err != nil {
+			t.Fatalf("DownloadAttachment() error = %v", err)
+		}
+		var expected string
+		var content []byte
+		for {
+			frame, err := stream.Recv()
+			if err == io.EOF {
+				return expected, content, nil
+			}
+			if err != nil {
+				return expected, content, err
+			}
+			if frame.GetInfo() != nil {
+				expected = frame.ExpectedSha256
+			}
+			content = append(content, frame.GetChunk()...)
+		}
+	}
+	reupload := func(data []byte) error {
+		_, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+			ReviewerId:  reviewerID,
+			FeedbackId:  feedback.Id,
+			Filename:    "report.txt",
+			ContentType: "text/plain",
+			TotalSize:   int64(len(data)),
+		}, data)
+		return err
+	}
+
+	// Neither the new checksum nor the removal of the old one recorded: the upload fails rather
+	// than leaving a checksum that fails its downloads unnoticed
+	srv.Store.FailAssetUpserts(errors.New("database is unavailable"))
+	srv.Store.FailAssetDeletes(errors.New("database is unavailable"))
+	err := reupload([]byte("second version"))
+	wantCode(t, err, codes.Unavailable)
+	wantReason(t, err, "ATTACHMENT_CHECKSUM_NOT_RECORDED")
+
+	// The stale row is removed instead: the upload succeeds and is served unverified
+	srv.Store.FailAssetDeletes(nil)
+	if err := reupload([]byte("third version")); err != nil {
+		t.Fatalf("UploadAttachment() with a failed upsert error = %v", err)
+	}
+	expected, content, err := download(t)
+	if err != nil {
+		t.Fatalf("DownloadAttachment() after a failed upsert error = %v", err)
+	}
+	if expected != "" || string(content) != "third version" {
+		t.Errorf("DownloadAttachment() = %q with checksum %q, want the new content unverified", content, expected)
+	}
+
+	srv.Store.FailAssetUpserts(nil)
+	if err := reupload([]byte("fourth version")); err != nil {
+		t.Fatalf("UploadAttachment() error = %v", err)
+	}
+	expected, content, err = download(t)
+	if err != nil {
+		t.Fatalf("DownloadAttachment() error = %v", err)
+	}
+	sum := sha256.Sum256([]byte("fourth version"))
+	if expected != hex.EncodeToString(sum[:]) || string(content) != "fourth version" {
+		t.Errorf("DownloadAttachment() = %q with checksum %q, want the new content and its checksum", content, expected)
+	}
+}
+
+func TestAttachmentManagement(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	for _, filename := range []string{"a.txt", "b.txt", "c.txt"} {
+		upload(t, srv, feedback.Id, filename, []byte(filename))
+	}
+
+	ordered, err := srv.Feedback.SetAttachmentOrder(ctx, &pb.SetAttachmentOrderRequest{
+		ReviewerId:       reviewerID,
+		FeedbackId:       feedback.Id,
+		OrderedFilenames: []string{"c.txt", "a.txt", "b.txt"},
+	})
+	if err != nil {
+		t.Fatalf("SetAttachmentOrder() error = %v", err)
+	}
+	if len(ordered.Attachments) != 3 || ordered.Attachments[0].Filename != "c.txt" {
+		t.Errorf("SetAttachmentOrder() = %v", ordered.Attachments)
+	}
+	if got := listFilenames(t, srv, feedback.Id); !slices.Equal(got, []string{"c.txt", "a.txt", "b.txt"}) {
+		t.Errorf("ListAttachments() = %v, want the set order", got)
+	}
+
+	_, err = srv.Feedback.SetAttachmentOrder(ctx, &pb.SetAttachmentOrderRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id, OrderedFilenames: []string{"a.txt", "a.txt"}})
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "ATTACHMENT_ORDER_MISMATCH")
+
+	location, err := srv.Feedback.GetAttachmentLocation(ctx, &pb.GetAttachmentLocationRequest{FeedbackId: feedback.Id, Filename: proto.String("a.txt")})
+	if err != nil {
+		t.Fatalf("GetAttachmentLocation() error = %v", err)
+	}
+	if len(location.Attachments) != 1 || location.Attachments[0].MinioObjectPath == "" {
+		t.Errorf("GetAttachmentLocation() = %v", location.Attachments)
+	}
+
+	_, err = srv.Feedback.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{ReviewerId: otherUserID, FeedbackId: feedback.Id, Filename: "a.txt"})
+	wantCode(t, err, codes.PermissionDenied)
+	deleted, err := srv.Feedback.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "a.txt"})
+	if err != nil {
+		t.Fatalf("DeleteAttachment() error = %v", err)
+	}
+	if !deleted.Success {
+		t.Error("DeleteAttachment() success = false")
+	}
+	if got := listFilenames(t, srv, feedback.Id); !slices.Equal(got, []string{"c.txt", "b.txt"}) {
+		t.Errorf("ListAttachments() = %v after delete, want the remaining order", got)
+	}
+}
+
+func TestUploadSession(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	session, err := srv.Feedback.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id})
+	if err != nil {
+		t.Fatalf("CreateUploadSession() error = %v", err)
+	}
+	if session.Status != "open" {
+		t.Errorf("CreateUploadSession() status = %q, want open", session.Status)
+	}
+	_, err = uploadChunks(t, srv, &pb.AttachmentMetadata{
+		ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "staged.txt", ContentType: "text/plain", TotalSize: 6, SessionId: session.Id,
+	}, []byte("staged"))
+	if err != nil {
+		t.Fatalf("UploadAttachment(session_id) error = %v", err)
+	}
+	if got := listFilenames(t, srv, feedback.Id); len(got) != 0 {
+		t.Errorf("ListAttachments() = %v before commit, want none", got)
+	}
+
+	committed, err := srv.Feedback.CommitUploadSession(ctx, &pb.CommitUploadSessionRequest{ReviewerId: reviewerID, SessionId: session.Id})
+	if err != nil {
+		t.Fatalf("CommitUploadSession() error = %v", err)
+	}
+	if committed.Status != "committed" || len(committed.Files) != 1 || !committed.Files[0].Promoted {
+		t.Errorf("CommitUploadSession() = %v", committed)
+	}
+	if got := listFilenames(t, srv, feedback.Id); !slices.Equal(got, []string{"staged.txt"}) {
+		t.Errorf("ListAttachments() = %v after commit, want the staged file", got)
+	}
+
+	_, err = srv.Feedback.AbortUploadSession(ctx, &pb.AbortUploadSessionRequest{ReviewerId: reviewerID, SessionId: session.Id})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	other, err := srv.Feedback.CreateUploadSession(ctx, &pb.CreateUploadSessionRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id})
+	if err != nil {
+		t.Fatalf("CreateUploadSession() error = %v", err)
+	}
+	aborted, err := srv.Feedback.AbortUploadSession(ctx, &pb.AbortUploadSessionRequest{ReviewerId: reviewerID, SessionId: other.Id})
+	if err != nil {
+		t.Fatalf("AbortUploadSession() error = %v", err)
+	}
+	if !aborted.Success {
+		t.Error("AbortUploadSession() success = false")
+	}
+}
+
+// stageFiles opens an upload session on a feedback and stages a file of each name, with the
+// name as its content
+func stageFiles(t *testing.T, srv *servertest.Server, feedbackID string, filenames ...string) string {
+	t.Helper()
+	session, err := srv.Feedback.CreateUploadSession(testContext(t), &pb.CreateUploadSessionRequest{ReviewerId: reviewerID, FeedbackId: feedbackID})
+	if err != nil {
+		t.Fatalf("CreateUploadSession() error = %v", err)
+	}
+	for _, filename := range filenames {
+		if _, err := uploadChunks(t, srv, &pb.AttachmentMetadata{
+			ReviewerId: reviewerID, FeedbackId: feedbackID, Filename: filename, ContentType: "text/plain", TotalSize: int64(len(filename)), SessionId: session.Id,
+		}, []byte(filename)); err != nil {
+			t.Fatalf("UploadAttachment(session_id) error = %v", err)
+		}
+	}
+	return session.Id
+}
+
+func TestCommitUploadSessionRetry(t *testing.T) {
+	tests := []struct {
+		name  string
+		fail  func(store *repotest.Store)
+		clear func(store *repotest.Store)
+		// Attachments listed after the failed commit
+		wantListed []string
+	}{
+		{
+			name:       "promotion fails after the first file",
+			fail:       func(store *repotest.Store) { store.FailPromotion("b.txt", errors.New("storage is unavailable")) },
+			clear:      func(store *repotest.Store) { store.FailPromotion("b.txt", nil) },
+			wantListed: []string{"a.txt"},
+		},
+		{
+			// The first file is in place but its promotion is not recorded; the retry finds it
+			// already moved
+			name: "checksum not recorded after the first promotion",
+			fail: func(store *repotest.Store) {
+				store.FailAssetUpserts(errors.New("database is unavailable"))
+				store.FailAssetDeletes(errors.New("database is unavailable"))
+			},
+			clear: func(store *repotest.Store) {
+				store.FailAssetUpserts(nil)
+				store.FailAssetDeletes(nil)
+			},
+			wantListed: []string{"a.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := servertest.New(t, nil)
+			ctx := testContext(t)
+			feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+			sessionID := stageFiles(t, srv, feedback.Id, "a.txt", "b.txt", "c.txt")
+			commit := &pb.CommitUploadSessionRequest{ReviewerId: reviewerID, SessionId: sessionID}
+
+			tt.fail(srv.Store)
+			_, err := srv.Feedback.CommitUploadSession(ctx, commit)
+			wantCode(t, err, codes.Internal)
+			if !strings.Contains(err.Error(), "commit again to resume") {
+				t.Errorf("CommitUploadSession() error = %v, want a hint to commit again", err)
+			}
+			if got := listFilenames(t, srv, feedback.Id); !slices.Equal(got, tt.wantListed) {
+				t.Errorf("ListAttachments() = %v after the failed commit, want %v", got, tt.wantListed)
+			}
+			// Once promotion started the session can only be committed
+			_, err = srv.Feedback.AbortUploadSession(ctx, &pb.AbortUploadSessionRequest{ReviewerId: reviewerID, SessionId: sessionID})
+			wantCode(t, err, codes.FailedPrecondition)
+
+			tt.clear(srv.Store)
+			committed, err := srv.Feedback.CommitUploadSession(ctx, commit)
+			if err != nil {
+				t.Fatalf("CommitUploadSession() retry error = %v", err)
+			}
+			if committed.Status != "committed" {
+				t.Errorf("CommitUploadSession() retry status = %q, want committed", committed.Status)
+			}
+			for _, file := range committed.Files {
+				if !file.Promoted {
+					t.Errorf("CommitUploadSession() retry left %s unpromoted", file.Filename)
+				}
+			}
+			if got := listFilenames(t, srv, feedback.Id); !slices.Equal(got, []string{"a.txt", "b.txt", "c.txt"}) {
+				t.Errorf("ListAttachments() = %v after the retry, want every staged file", got)
+			}
+			id := uuid.MustParse(feedback.Id)
+			for _, filename := range []string{"a.txt", "b.txt", "c.txt"} {
+				checksum, err := srv.Store.Assets().GetChecksum(ctx, id, filename)
+				if err != nil || checksum == "" {
+					t.Errorf("GetChecksum(%s) = %q, %v, want the checksum recorded by the retry", filename, checksum, err)
+				}
+			}
+
+			// Committing again changes nothing
+			again, err := srv.Feedback.CommitUploadSession(ctx, commit)
+			if err != nil || again.Status != "committed" {
+				t.Errorf("CommitUploadSession() after commit = %v, %v, want it committed", again, err)
+			}
+		})
+	}
+}
+
+func TestCommitUploadSessionLimits(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	for _, filename := range []string{"1.txt", "2.txt", "3.txt", "4.txt"} {
+		upload(t, srv, feedback.Id, filename, []byte(filename))
+	}
+
+	// Staging is not limited; the combined count is checked when the session is committed.
+	// Replacing an existing attachment does not add to it.
+	sessionID := stageFiles(t, srv, feedback.Id, "4.txt", "5.txt", "6.txt")
+	commit := &pb.CommitUploadSessionRequest{ReviewerId: reviewerID, SessionId: sessionID}
+	_, err := srv.Feedback.CommitUploadSession(ctx, commit)
+	wantCode(t, err, codes.FailedPrecondition)
+	wantReason(t, err, "ATTACHMENT_LIMIT_EXCEEDED")
+	if got := listFilenames(t, srv, feedback.Id); len(got) != 4 {
+		t.Errorf("ListAttachments() = %v after the rejected commit, want nothing promoted", got)
+	}
+
+	// The session stays open, so freeing room lets the same session commit
+	if _, err := srv.Feedback.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "1.txt"}); err != nil {
+		t.Fatalf("DeleteAttachment() error = %v", err)
+	}
+	committed, err := srv.Feedback.CommitUploadSession(ctx, commit)
+	if err != nil {
+		t.Fatalf("CommitUploadSession() after freeing room error = %v", err)
+	}
+	if committed.Status != "committed" {
+		t.Errorf("CommitUploadSession() status = %q, want committed", committed.Status)
+	}
+	if got := listFilenames(t, srv, feedback.Id); len(got) != 5 {
+		t.Errorf("ListAttachments() = %v, want 5 attachments", got)
+	}
+}
+
+func TestDeleteAttachmentAfterReorder(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	for _, filename := range []string{"a.txt", "b.txt"} {
+		upload(t, srv, feedback.Id, filename, []byte(filename))
+	}
+	if _, err := srv.Feedback.SetAttachmentOrder(ctx, &pb.SetAttachmentOrderRequest{
+		ReviewerId:       reviewerID,
+		FeedbackId:       feedback.Id,
+		OrderedFilenames: []string{"b.txt", "a.txt"},
+	}); err != nil {
+		t.Fatalf("SetAttachmentOrder() error = %v", err)
+	}
+	upload(t, srv, feedback.Id, "c.txt", []byte("c.txt"))
+	if got, want := listFilenames(t, srv, feedback.Id), []string{"b.txt", "a.txt", "c.txt"}; !slices.Equal(got, want) {
+		t.Fatalf("ListAttachments() = %v, want %v", got, want)
+	}
+
+	// A failed metadata deletion fails the call and leaves the attachment in place
+	srv.Store.FailAssetDeletes(errors.New("database is unavailable"))
+	_, err := srv.Feedback.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "b.txt"})
+	wantCode(t, err, codes.Internal)
+	if !srv.Store.ObjectExists(uuid.MustParse(feedback.Id), "b.txt") {
+		t.Fatal("DeleteAttachment() removed the object although its metadata was kept")
+	}
+	if got, want := listFilenames(t, srv, feedback.Id), []string{"b.txt", "a.txt", "c.txt"}; !slices.Equal(got, want) {
+		t.Errorf("ListAttachments() = %v after a failed delete, want %v", got, want)
+	}
+
+	srv.Store.FailAssetDeletes(nil)
+	if _, err := srv.Feedback.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{ReviewerId: reviewerID, FeedbackId: feedback.Id, Filename: "b.txt"}); err != nil {
+		t.Fatalf("DeleteAttachment() retry error = %v", err)
+	}
+	if got, want := listFilenames(t, srv, feedback.Id), []string{"a.txt", "c.txt"}; !slices.Equal(got, want) {
+		t.Errorf("ListAttachments() = %v after delete, want %v", got, want)
+	}
+
+	// A file uploaded again under a deleted name is new: it is listed last, not at the old position
+	upload(t, srv, feedback.Id, "b.txt", []byte("b.txt again"))
+	if got, want := listFilenames(t, srv, feedback.Id), []string{"a.txt", "c.txt", "b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("ListAttachments() = %v after upload, want %v", got, want)
+	}
+	ordered, err := srv.Feedback.SetAttachmentOrder(ctx, &pb.SetAttachmentOrderRequest{
+		ReviewerId:       reviewerID,
+		FeedbackId:       feedback.Id,
+		OrderedFilenames: []string{"c.txt", "b.txt", "a.txt"},
+	})
+	if err != nil {
+		t.Fatalf("SetAttachmentOrder() error = %v", err)
+	}
+	if len(ordered.Attachments) != 3 {
+		t.Errorf("SetAttachmentOrder() = %v, want the three current attachments", ordered.Attachments)
+	}
+}
+
+func TestSubscribeFeedbackEventsUploadProgress(t *testing.T) {
+	type progress struct {
+		stage   pb.AttachmentUploadStage
+		percent int32
+	}
+	started := progress{pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_STARTED, 0}
+	completed := progress{pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_COMPLETED, 100}
+	milestone := func(percent int32) progress {
+		return progress{pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_PROGRESS, percent}
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		pause    time.Duration // Before each chunk
+		want     []progress
+	}{
+		{name: "slow upload", interval: 20 * time.Millisecond, pause: 80 * time.Millisecond, want: []progress{started, milestone(25), milestone(50), milestone(75), completed}},
+		{name: "fast upload is rate limited", interval: time.Minute, want: []progress{started, completed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := servertest.New(t, func(cfg *config.Config) {
+				cfg.Events.UploadProgressInterval = tt.interval
+			})
+			ctx := testContext(t)
+			feedback := seedStudentFeedback(t, srv, submissionID)
+			other := seedStudentFeedback(t, srv, submissionID+1)
+
+			stream, err := srv.Feedback.SubscribeFeedbackEvents(ctx, &pb.SubscribeFeedbackEventsRequest{
+				StudentId:  studentID,
+				FeedbackId: feedback.ID.String(),
+				Since:      timestamppb.New(time.Now().Add(-time.Minute)),
+			})
+			if err != nil {
+				t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+			}
+			snapshot, err := stream.Recv()
+			if err != nil {
+				t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+			}
+			if snapshot.Feedback.GetId() != feedback.ID.String() {
+				t.Fatalf("SubscribeFeedbackEvents() first event = %v, want the watched feedback only", snapshot)
+			}
+
+			// Uploads to other feedback are filtered out
+			upload(t, srv, other.ID.String(), "other.txt", []byte("other"))
+
+			data := bytes.Repeat([]byte("x"), 4096)
+			upload, err := srv.Feedback.UploadAttachment(ctx)
+			if err != nil {
+				t.Fatalf("UploadAttachment() error = %v", err)
+			}
+			upload.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Metadata{Metadata: &pb.AttachmentMetadata{
+				ReviewerId:  reviewerID,
+				FeedbackId:  feedback.ID.String(),
+				Filename:    "report.txt",
+				ContentType: "text/plain",
+				TotalSize:   int64(len(data)),
+			}}})
+			// The server receives the first chunk before the upload starts; sent in eighths, every
+			// milestone is passed after a pause
+			for chunk := range slices.Chunk(data, len(data)/8) {
+				time.Sleep(tt.pause)
+				upload.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Chunk{Chunk: chunk}})
+			}
+			if _, err := upload.CloseAndRecv(); err != nil {
+				t.Fatalf("UploadAttachment() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				event, err := stream.Recv()
+				if err != nil {
+					t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+				}
+				got := event.GetUpload()
+				if event.Type != pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_UPLOAD_PROGRESS || got.GetFilename() != "report.txt" ||
+					got.GetStage() != want.stage || got.GetPercent() != want.percent {
+					t.Fatalf("SubscribeFeedbackEvents() event = %v, want %v at %d%% of report.txt", event, want.stage, want.percent)
+				}
+				if got.BytesReceived != int64(want.percent)*got.TotalBytes/100 || got.TotalBytes != int64(len(data)) {
+					t.Errorf("SubscribeFeedbackEvents() upload = %v, want %d%% of %d bytes received", got, want.percent, len(data))
+				}
+				// Progress is not journaled, so it keeps the position of the event before it
+				if event.Replayed || event.Sequence != snapshot.Sequence {
+					t.Errorf("SubscribeFeedbackEvents() event sequence = %d, replayed %v, want %d live", event.Sequence, event.Replayed, snapshot.Sequence)
+				}
+			}
+
+			// A stream resuming from before the upload gets the next change, not the progress
+			srv.FeedbackService.PushFeedbackEvent(models.FeedbackEventUpdated, feedback)
+			resumed, err := srv.Feedback.SubscribeFeedbackEvents(ctx, &pb.SubscribeFeedbackEventsRequest{
+				StudentId:   studentID,
+				FeedbackId:  feedback.ID.String(),
+				ResumeAfter: snapshot.ResumeToken,
+			})
+			if err != nil {
+				t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+			}
+			event, err := resumed.Recv()
+			if err != nil {
+				t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+			}
+			if event.Type != pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_UPDATED || !event.Replayed {
+				t.Errorf("SubscribeFeedbackEvents() resumed event = %v, want the replayed update", event)
+			}
+		})
+	}
+}
@@ -0,0 +1,848 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server/servertest"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const labID = 401
+
+// createComment posts a comment on labID, as a reply if parentID is not empty
+func createComment(t *testing.T, srv *servertest.Server, userID int64, parentID, content string) *pb.Comment {
+	t.Helper()
+	req := &pb.CreateCommentRequest{ContentId: labID, UserId: userID, Content: content, Type: "lab"}
+	if parentID != "" {
+		req.ParentId = proto.String(parentID)
+	}
+	comment, err := srv.Comments.CreateComment(testContext(t), req)
+	if err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+	return comment
+}
+
+func TestCommentLifecycle(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+
+	comment := createComment(t, srv, studentID, "", "Why *this* approach?")
+	if !strings.HasPrefix(comment.Id, "cmt_") || comment.Depth != 0 || comment.Type != "lab" {
+		t.Errorf("CreateComment() = %v", comment)
+	}
+	reply := createComment(t, srv, reviewerID, comment.Id, "Because it is simpler")
+	if reply.Depth != 1 || reply.GetParentId() != comment.Id {
+		t.Errorf("CreateComment(reply) depth = %d, parent_id = %q", reply.Depth, reply.GetParentId())
+	}
+
+	rendered, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: comment.Id, Render: pb.RenderFormat_RENDER_FORMAT_SAFE_HTML})
+	if err != nil {
+		t.Fatalf("GetComment() error = %v", err)
+	}
+	if !strings.Contains(rendered.GetRenderedContent(), "<em>this</em>") {
+		t.Errorf("GetComment() rendered_content = %q", rendered.GetRenderedContent())
+	}
+	byName, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: comment.Name})
+	if err != nil {
+		t.Fatalf("GetComment(name) error = %v", err)
+	}
+	if byName.Id != comment.Id {
+		t.Errorf("GetComment(name) id = %q, want %q", byName.Id, comment.Id)
+	}
+
+	_, err = srv.Comments.UpdateComment(ctx, &pb.UpdateCommentRequest{UserId: otherUserID, Id: comment.Id, Content: "hijacked"})
+	wantCode(t, err, codes.PermissionDenied)
+	updated, err := srv.Comments.UpdateComment(ctx, &pb.UpdateCommentRequest{UserId: studentID, Id: comment.Id, Content: "Why this approach?"})
+	if err != nil {
+		t.Fatalf("UpdateComment() error = %v", err)
+	}
+	if !updated.Edited || updated.EditCount != 1 {
+		t.Errorf("UpdateComment() edited = %v, edit_count = %d", updated.Edited, updated.EditCount)
+	}
+	history, err := srv.Comments.GetCommentEditHistory(ctx, &pb.GetCommentEditHistoryRequest{Id: comment.Id, UserId: studentID})
+	if err != nil {
+		t.Fatalf("GetCommentEditHistory() error = %v", err)
+	}
+	if history.EditCount != 1 || len(history.Edits) != 1 || history.Edits[0].Content != "Why *this* approach?" {
+		t.Errorf("GetCommentEditHistory() = %v", history)
+	}
+	_, err = srv.Comments.GetCommentEditHistory(ctx, &pb.GetCommentEditHistoryRequest{Id: comment.Id, UserId: otherUserID})
+	wantCode(t, err, codes.PermissionDenied)
+
+	liked, err := srv.Comments.ReactToComment(ctx, &pb.ReactToCommentRequest{Id: comment.Id, UserId: reviewerID, Reaction: pb.CommentReaction_COMMENT_REACTION_LIKE})
+	if err != nil {
+		t.Fatalf("ReactToComment() error = %v", err)
+	}
+	if liked.LikeCount != 1 || liked.ViewerReaction != pb.CommentReaction_COMMENT_REACTION_LIKE {
+		t.Errorf("ReactToComment() like_count = %d, viewer_reaction = %v", liked.LikeCount, liked.ViewerReaction)
+	}
+	unliked, err := srv.Comments.RemoveCommentReaction(ctx, &pb.RemoveCommentReactionRequest{Id: comment.Id, UserId: reviewerID})
+	if err != nil {
+		t.Fatalf("RemoveCommentReaction() error = %v", err)
+	}
+	if unliked.LikeCount != 0 {
+		t.Errorf("RemoveCommentReaction() like_count = %d, want 0", unliked.LikeCount)
+	}
+
+	content, err := srv.Comments.GetCommentContent(ctx, &pb.GetCommentContentRequest{CommentId: comment.Id})
+	if err != nil {
+		t.Fatalf("GetCommentContent() error = %v", err)
+	}
+	frames := recvAll(t, content.Recv)
+	if len(frames) < 2 || frames[0].GetInfo().GetSize() != int64(len("Why this approach?")) {
+		t.Fatalf("GetCommentContent() frames = %v, want the info frame and the content", frames)
+	}
+	var body []byte
+	for _, frame := range frames[1:] {
+		body = append(body, frame.GetChunk()...)
+	}
+	if string(body) != "Why this approach?" {
+		t.Errorf("GetCommentContent() content = %q", body)
+	}
+
+	_, err = srv.Comments.DeleteComment(ctx, &pb.DeleteCommentRequest{UserId: otherUserID, Id: reply.Id})
+	wantCode(t, err, codes.PermissionDenied)
+	deleted, err := srv.Comments.DeleteComment(ctx, &pb.DeleteCommentRequest{UserId: reviewerID, Id: reply.Id})
+	if err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+	if !deleted.Success {
+		t.Error("DeleteComment() success = false")
+	}
+	// Without strict_error_codes, read RPCs report every failure as Internal
+	_, err = srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: reply.Id})
+	wantCode(t, err, codes.Internal)
+	_, err = srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: "not-an-id"})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestCommentDepthLimit(t *testing.T) {
+	srv := servertest.New(t, func(cfg *config.Config) { cfg.Comments.MaxDepth = 2 })
+
+	parent := createComment(t, srv, studentID, "", "Top-level")
+	for depth := int32(1); depth <= 2; depth++ {
+		parent = createComment(t, srv, reviewerID, parent.Id, fmt.Sprintf("Reply at depth %d", depth))
+		if parent.Depth != depth {
+			t.Fatalf("CreateComment(reply) depth = %d, want %d", parent.Depth, depth)
+		}
+	}
+
+	// A reply to the comment at the limit would be one level too deep
+	_, err := srv.Comments.CreateComment(testContext(t), &pb.CreateCommentRequest{
+		ContentId: labID, UserId: studentID, Content: "Too deep", Type: "lab", ParentId: proto.String(parent.Id),
+	})
+	wantCode(t, err, codes.FailedPrecondition)
+	wantReason(t, err, "COMMENT_DEPTH_EXCEEDED")
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Metadata["max_depth"] != "2" {
+			t.Errorf("error metadata = %v, want max_depth 2", info.Metadata)
+		}
+	}
+
+	// Siblings at the limit are still allowed
+	if sibling := createComment(t, srv, studentID, parent.GetParentId(), "Another reply at depth 2"); sibling.Depth != 2 {
+		t.Errorf("CreateComment(sibling) depth = %d, want 2", sibling.Depth)
+	}
+}
+
+// commentIDCall calls an RPC that takes a comment ID and returns the ID of the comment its
+// response is about, in the opaque form
+type commentIDCall func(ctx, admin context.Context, srv *servertest.Server, id string) (string, error)
+
+// commentIDCalls lists every comment RPC with a comment ID in its request. Each call gets a
+// top-level comment of studentID with one reply of studentID, addressed by the top-level ID.
+var commentIDCalls = []struct {
+	name string
+	call commentIDCall
+}{
+	{"CreateComment parent_id", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		reply, err := srv.Comments.CreateComment(ctx, &pb.CreateCommentRequest{ContentId: labID, UserId: reviewerID, Content: "reply", Type: "lab", ParentId: proto.String(id)})
+		return reply.GetParentId(), err
+	}},
+	{"GetComment", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		comment, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: id})
+		return comment.GetId(), err
+	}},
+	{"UpdateComment", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		comment, err := srv.Comments.UpdateComment(ctx, &pb.UpdateCommentRequest{UserId: studentID, Id: id, Content: "edited"})
+		return comment.GetId(), err
+	}},
+	{"DeleteComment", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		if _, err := srv.Comments.DeleteComment(ctx, &pb.DeleteCommentRequest{UserId: studentID, Id: id}); err != nil {
+			return "", err
+		}
+		if _, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: id}); err == nil {
+			return "", fmt.Errorf("comment %s still exists", id)
+		}
+		stored, err := resourcename.ParseCommentID(id, true)
+		return resourcename.CommentID(stored), err
+	}},
+	{"ListComments parent_id", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		replies, err := srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentId: labID, Type: "lab", ParentId: proto.String(id)})
+		if len(replies.GetComments()) != 1 {
+			return "", err
+		}
+		return replies.Comments[0].GetParentId(), err
+	}},
+	{"GetCommentReplies", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		replies, err := srv.Comments.GetCommentReplies(ctx, &pb.GetCommentRepliesRequest{CommentId: id})
+		if len(replies.GetComments()) != 1 {
+			return "", err
+		}
+		return replies.Comments[0].GetParentId(), err
+	}},
+	{"ReactToComment", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		comment, err := srv.Comments.ReactToComment(ctx, &pb.ReactToCommentRequest{Id: id, UserId: reviewerID, Reaction: pb.CommentReaction_COMMENT_REACTION_LIKE})
+		return comment.GetId(), err
+	}},
+	{"RemoveCommentReaction", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		comment, err := srv.Comments.RemoveCommentReaction(ctx, &pb.RemoveCommentReactionRequest{Id: id, UserId: reviewerID})
+		return comment.GetId(), err
+	}},
+	{"GetCommentEditHistory", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		if _, err := srv.Comments.GetCommentEditHistory(ctx, &pb.GetCommentEditHistoryRequest{Id: id, UserId: studentID}); err != nil {
+			return "", err
+		}
+		comment, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: id})
+		return comment.GetId(), err
+	}},
+	{"ReportComment", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		if _, err := srv.Comments.ReportComment(ctx, &pb.ReportCommentRequest{Id: id, ReporterUserId: reviewerID, Reason: pb.CommentReportReason_COMMENT_REPORT_REASON_SPAM}); err != nil {
+			return "", err
+		}
+		comment, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: id})
+		return comment.GetId(), err
+	}},
+	{"ModerateComment", func(_, admin context.Context, srv *servertest.Server, id string) (string, error) {
+		moderated, err := srv.Comments.ModerateComment(admin, &pb.ModerateCommentRequest{Id: id, ModeratorId: 1, Action: pb.CommentModerationAction_COMMENT_MODERATION_ACTION_HIDE})
+		return moderated.GetComment().GetId(), err
+	}},
+	{"GetCommentContent", func(ctx, _ context.Context, srv *servertest.Server, id string) (string, error) {
+		stream, err := srv.Comments.GetCommentContent(ctx, &pb.GetCommentContentRequest{CommentId: id})
+		if err != nil {
+			return "", err
+		}
+		// Server streams report request errors on the first receive
+		frame, err := stream.Recv()
+		return frame.GetInfo().GetId(), err
+	}},
+	{"ImportComments parent_id", func(_, admin context.Context, srv *servertest.Server, id string) (string, error) {
+		imports, err := srv.Comments.ImportComments(admin)
+		if err != nil {
+			return "", err
+		}
+		record := &pb.ImportCommentRecord{SourceId: "1", ContentId: labID, UserId: reviewerID, Type: "lab", Content: "imported reply", ParentId: proto.String(id)}
+		if err := imports.Send(&pb.ImportCommentsRequest{Data: &pb.ImportCommentsRequest_Record{Record: record}}); err != nil {
+			return "", err
+		}
+		imported, err := imports.CloseAndRecv()
+		if err != nil {
+			return "", err
+		}
+		if imported.Imported != 1 {
+			return "", fmt.Errorf("import failed: %v", imported.Results)
+		}
+		reply, err := srv.Comments.GetComment(admin, &pb.GetCommentRequest{Id: imported.Results[0].CommentId})
+		return reply.GetParentId(), err
+	}},
+}
+
+// TestCommentIDForms calls every comment RPC with the opaque ID, the resource name and the bare
+// hex ID of a comment; hex IDs are accepted only during their deprecation window
+func TestCommentIDForms(t *testing.T) {
+	for _, acceptHex := range []bool{true, false} {
+		srv := servertest.New(t, func(cfg *config.Config) { cfg.Comments.AcceptHexIDs = acceptHex })
+		for _, rpc := range commentIDCalls {
+			for _, form := range []string{"id", "name", "hex"} {
+				t.Run(fmt.Sprintf("%s/%s/accept_hex=%v", rpc.name, form, acceptHex), func(t *testing.T) {
+					ctx := testContext(t)
+					comment := createComment(t, srv, studentID, "", "Why this approach?")
+					createComment(t, srv, studentID, comment.Id, "Never mind")
+
+					hex, err := resourcename.ParseCommentID(comment.Id, false)
+					if err != nil {
+						t.Fatalf("ParseCommentID(%q) error = %v", comment.Id, err)
+					}
+					id := map[string]string{"id": comment.Id, "name": comment.Name, "hex": hex}[form]
+
+					got, err := rpc.call(ctx, servertest.Admin(ctx), srv, id)
+					if form == "hex" && !acceptHex {
+						wantCode(t, err, codes.InvalidArgument)
+						wantReason(t, err, "HEX_COMMENT_ID_REJECTED")
+						return
+					}
+					if err != nil {
+						t.Fatalf("%s(%q) error = %v", rpc.name, id, err)
+					}
+					if got != comment.Id {
+						t.Errorf("%s(%q) refers to comment %q, want %q", rpc.name, id, got, comment.Id)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestCommentLists(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	parent := createComment(t, srv, studentID, "", "first")
+	createComment(t, srv, studentID, "", "second")
+	createComment(t, srv, reviewerID, "", "third")
+	for _, content := range []string{"reply 1", "reply 2", "reply 3"} {
+		createComment(t, srv, reviewerID, parent.Id, content)
+	}
+
+	var listed []string
+	token := ""
+	for range 3 {
+		resp, err := srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentId: labID, Type: "lab", Limit: 2, PageToken: token})
+		if err != nil {
+			t.Fatalf("ListComments() error = %v", err)
+		}
+		if resp.TotalCount64 != 3 {
+			t.Errorf("ListComments() total = %d, want the 3 top-level comments", resp.TotalCount64)
+		}
+		for _, comment := range resp.Comments {
+			listed = append(listed, comment.Content)
+		}
+		if token = resp.NextPageToken; token == "" {
+			break
+		}
+	}
+	if strings.Join(listed, ",") != "third,second,first" || token != "" {
+		t.Errorf("ListComments() = %v, last next_page_token = %q, want newest first", listed, token)
+	}
+
+	_, err := srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentId: labID, Type: "lab", Page: 2, PageToken: "garbage"})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentId: labID, Type: "lab", PageToken: "garbage"})
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "INVALID_PAGE_TOKEN")
+
+	replies, err := srv.Comments.GetCommentReplies(ctx, &pb.GetCommentRepliesRequest{CommentId: parent.Id, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetCommentReplies() error = %v", err)
+	}
+	if replies.TotalCount64 != 3 || len(replies.Comments) != 2 || replies.Comments[0].Content != "reply 1" || replies.NextPageToken == "" {
+		t.Errorf("GetCommentReplies() = %v", replies)
+	}
+	rest, err := srv.Comments.GetCommentReplies(ctx, &pb.GetCommentRepliesRequest{CommentId: parent.Id, Limit: 2, PageToken: replies.NextPageToken})
+	if err != nil {
+		t.Fatalf("GetCommentReplies(page_token) error = %v", err)
+	}
+	if len(rest.Comments) != 1 || rest.Comments[0].Content != "reply 3" || rest.NextPageToken != "" {
+		t.Errorf("GetCommentReplies(page_token) = %v", rest)
+	}
+	_, err = srv.Comments.GetCommentReplies(ctx, &pb.GetCommentRepliesRequest{CommentId: parent.Id, PageToken: replies.NextPageToken, Sort: pb.CommentSort_COMMENT_SORT_NEWEST})
+	wantReason(t, err, "INVALID_PAGE_TOKEN")
+
+	user, err := srv.Comments.ListUserComments(ctx, &pb.ListUserCommentsRequest{UserId: reviewerID})
+	if err != nil {
+		t.Fatalf("ListUserComments() error = %v", err)
+	}
+	if user.TotalCount64 != 4 {
+		t.Errorf("ListUserComments() total = %d, want 4", user.TotalCount64)
+	}
+
+	count, err := srv.Comments.CountComments(ctx, &pb.CountCommentsRequest{ContentId: labID, Type: "lab", IncludeReplies: true})
+	if err != nil {
+		t.Fatalf("CountComments() error = %v", err)
+	}
+	if count.Count != 6 {
+		t.Errorf("CountComments() = %d, want 6", count.Count)
+	}
+	counts, err := srv.Comments.BatchCountComments(ctx, &pb.BatchCountCommentsRequest{ContentIds: []int64{labID, labID + 1}, Type: "lab"})
+	if err != nil {
+		t.Fatalf("BatchCountComments() error = %v", err)
+	}
+	if counts.Counts[labID] != 3 || counts.Counts[labID+1] != 0 {
+		t.Errorf("BatchCountComments() = %v", counts.Counts)
+	}
+}
+
+func TestListCommentsExpandFeedback(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	kept := createFeedback(t, srv, submissionID, "Recursion review", "Base cases are missing", false)
+	removed := createFeedback(t, srv, submissionID+1, "Sorting review", "Nice and tidy", false)
+	createFeedbackComment(t, srv, kept.Id, studentID, "", "Which base case?")
+	createFeedbackComment(t, srv, removed.Id, studentID, "", "Is this final?")
+	if _, err := srv.Feedback.DeleteFeedback(ctx, &pb.DeleteFeedbackRequest{ReviewerId: reviewerID, Id: removed.Id}); err != nil {
+		t.Fatalf("DeleteFeedback() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		feedbackID string
+		expand     []string
+		want       *pb.FeedbackRef
+	}{
+		{name: "expanded", feedbackID: kept.Id, expand: []string{"feedback"}, want: &pb.FeedbackRef{Id: kept.Id, Title: "Recursion review", ReviewerId: reviewerID}},
+		{name: "deleted feedback", feedbackID: removed.Id, expand: []string{"feedback"}},
+		{name: "not expanded", feedbackID: kept.Id},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentRef: tt.feedbackID, Type: "feedback", Expand: tt.expand})
+			if err != nil {
+				t.Fatalf("ListComments() error = %v", err)
+			}
+			if len(resp.Comments) != 1 || resp.ExpansionFailed {
+				t.Fatalf("ListComments() = %d comments, expansion_failed %v, want 1 comment", len(resp.Comments), resp.ExpansionFailed)
+			}
+			if got := resp.Comments[0].Feedback; !proto.Equal(got, tt.want) {
+				t.Errorf("ListComments() feedback = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	srv.Store.FailBatchFeedbackReads(errors.New("postgres unavailable"))
+	degraded, err := srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentRef: kept.Id, Type: "feedback", Expand: []string{"feedback"}})
+	if err != nil {
+		t.Fatalf("ListComments() with feedback unavailable error = %v", err)
+	}
+	if !degraded.ExpansionFailed || len(degraded.Comments) != 1 || degraded.Comments[0].Feedback != nil {
+		t.Errorf("ListComments() with feedback unavailable = %v, want the comment unexpanded and expansion_failed", degraded)
+	}
+
+	_, err = srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentId: labID, Type: "lab", Expand: []string{"feedback"}})
+	wantCode(t, err, codes.InvalidArgument)
+	_, err = srv.Comments.ListComments(ctx, &pb.ListCommentsRequest{ContentRef: kept.Id, Type: "feedback", Expand: []string{"author"}})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestCommentModeration(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	admin := servertest.Admin(ctx)
+	comment := createComment(t, srv, studentID, "", "spam spam spam")
+
+	report, err := srv.Comments.ReportComment(ctx, &pb.ReportCommentRequest{Id: comment.Id, ReporterUserId: reviewerID, Reason: pb.CommentReportReason_COMMENT_REPORT_REASON_SPAM})
+	if err != nil {
+		t.Fatalf("ReportComment() error = %v", err)
+	}
+	if !report.Created {
+		t.Error("ReportComment() created = false for a first report")
+	}
+	again, err := srv.Comments.ReportComment(ctx, &pb.ReportCommentRequest{Id: comment.Id, ReporterUserId: reviewerID, Reason: pb.CommentReportReason_COMMENT_REPORT_REASON_OTHER})
+	if err != nil {
+		t.Fatalf("ReportComment() again error = %v", err)
+	}
+	if again.Created {
+		t.Error("ReportComment() created = true for a repeated report")
+	}
+
+	_, err = srv.Comments.ListReportedComments(ctx, &pb.ListReportedCommentsRequest{})
+	wantCode(t, err, codes.PermissionDenied)
+	reported, err := srv.Comments.ListReportedComments(admin, &pb.ListReportedCommentsRequest{})
+	if err != nil {
+		t.Fatalf("ListReportedComments() error = %v", err)
+	}
+	if len(reported.Comments) != 1 || reported.Comments[0].ReportCount != 1 {
+		t.Errorf("ListReportedComments() = %v", reported.Comments)
+	}
+
+	_, err = srv.Comments.ModerateComment(ctx, &pb.ModerateCommentRequest{Id: comment.Id, ModeratorId: 1, Action: pb.CommentModerationAction_COMMENT_MODERATION_ACTION_HIDE})
+	wantCode(t, err, codes.PermissionDenied)
+	moderated, err := srv.Comments.ModerateComment(admin, &pb.ModerateCommentRequest{Id: comment.Id, ModeratorId: 1, Action: pb.CommentModerationAction_COMMENT_MODERATION_ACTION_HIDE})
+	if err != nil {
+		t.Fatalf("ModerateComment() error = %v", err)
+	}
+	if !moderated.Comment.Hidden || moderated.ResolvedReports != 1 {
+		t.Errorf("ModerateComment() hidden = %v, resolved_reports = %d", moderated.Comment.Hidden, moderated.ResolvedReports)
+	}
+	hidden, err := srv.Comments.GetComment(ctx, &pb.GetCommentRequest{Id: comment.Id})
+	if err != nil {
+		t.Fatalf("GetComment() error = %v", err)
+	}
+	if !hidden.Hidden || hidden.Content != "" {
+		t.Errorf("GetComment() hidden = %v, content = %q", hidden.Hidden, hidden.Content)
+	}
+}
+
+func TestCommentAdministration(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	admin := servertest.Admin(ctx)
+	createComment(t, srv, studentID, "", "on the duplicate")
+
+	imports, err := srv.Comments.ImportComments(admin)
+	if err != nil {
+		t.Fatalf("ImportComments() error = %v", err)
+	}
+	records := []*pb.ImportCommentRecord{
+		{SourceId: "1", ContentId: labID + 1, UserId: studentID, Type: "lab", Content: "imported"},
+		{SourceId: "2", ContentId: labID + 1, UserId: reviewerID, Type: "lab", Content: "imported reply", ParentSourceId: proto.String("1")},
+		{SourceId: "3", ContentId: labID + 1, UserId: reviewerID, Type: "lab"},
+	}
+	for _, record := range records {
+		if err := imports.Send(&pb.ImportCommentsRequest{Data: &pb.ImportCommentsRequest_Record{Record: record}}); err != nil {
+			t.Fatalf("ImportComments() Send error = %v", err)
+		}
+	}
+	imported, err := imports.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("ImportComments() error = %v", err)
+	}
+	if imported.Total != 3 || imported.Imported != 2 || imported.Failed != 1 || imported.Results[2].Success {
+		t.Errorf("ImportComments() = %v", imported)
+	}
+
+	_, err = srv.Comments.MergeCommentThreads(ctx, &pb.MergeCommentThreadsRequest{SourceContentId: labID, TargetContentId: labID + 1, Type: "lab", ActorId: 1})
+	wantCode(t, err, codes.PermissionDenied)
+	merged, err := srv.Comments.MergeCommentThreads(admin, &pb.MergeCommentThreadsRequest{SourceContentId: labID, TargetContentId: labID + 1, Type: "lab", ActorId: 1})
+	if err != nil {
+		t.Fatalf("MergeCommentThreads() error = %v", err)
+	}
+	if merged.Moved != 1 || merged.TargetComments != 3 || merged.CompletedAt == nil {
+		t.Errorf("MergeCommentThreads() = %v", merged)
+	}
+	_, err = srv.Comments.MergeCommentThreads(admin, &pb.MergeCommentThreadsRequest{SourceContentId: labID, TargetContentId: labID, Type: "lab", ActorId: 1})
+	wantCode(t, err, codes.InvalidArgument)
+
+	rate, err := srv.Comments.GetCommentCreationRate(admin, &pb.GetCommentCreationRateRequest{ContentId: labID + 1, Type: "lab", BucketSize: "day"})
+	if err != nil {
+		t.Fatalf("GetCommentCreationRate() error = %v", err)
+	}
+	if rate.TotalCount != 3 {
+		t.Errorf("GetCommentCreationRate() total = %d, want 3", rate.TotalCount)
+	}
+
+	_, err = srv.Comments.EraseUserComments(ctx, &pb.EraseUserCommentsRequest{UserId: studentID, Mode: pb.CommentErasureMode_COMMENT_ERASURE_MODE_DELETE, ActorId: 1})
+	wantCode(t, err, codes.PermissionDenied)
+	erased, err := srv.Comments.EraseUserComments(admin, &pb.EraseUserCommentsRequest{UserId: studentID, Mode: pb.CommentErasureMode_COMMENT_ERASURE_MODE_DELETE, ActorId: 1})
+	if err != nil {
+		t.Fatalf("EraseUserComments() error = %v", err)
+	}
+	if erased.Erased != 2 || erased.Attempts != 1 {
+		t.Errorf("EraseUserComments() = %v", erased)
+	}
+	retried, err := srv.Comments.EraseUserComments(admin, &pb.EraseUserCommentsRequest{UserId: studentID, Mode: pb.CommentErasureMode_COMMENT_ERASURE_MODE_DELETE, ActorId: 1})
+	if err != nil {
+		t.Fatalf("EraseUserComments() retry error = %v", err)
+	}
+	if retried.Erased != 0 || retried.TotalErased != 2 || retried.Attempts != 2 {
+		t.Errorf("EraseUserComments() retry = %v", retried)
+	}
+}
+
+// nextCommentEvent returns the next event of a comment stream, failing unless it is about
+// commentID
+func nextCommentEvent(t *testing.T, stream pb.CommentService_WatchCommentsClient, commentID string) *pb.CommentEvent {
+	t.Helper()
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("WatchComments() Recv error = %v", err)
+	}
+	if event.Comment.GetId() != commentID {
+		t.Fatalf("WatchComments() event = %v, want one about %s", event, commentID)
+	}
+	return event
+}
+
+func TestWatchComments(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	since := timestamppb.New(time.Now().Add(-time.Minute))
+	earlier := createComment(t, srv, studentID, "", "before the watch")
+
+	stream, err := srv.Comments.WatchComments(ctx, &pb.WatchCommentsRequest{ContentId: labID, Type: "lab", Since: since})
+	if err != nil {
+		t.Fatalf("WatchComments() error = %v", err)
+	}
+	replayed, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("WatchComments() Recv error = %v", err)
+	}
+	if !replayed.Replayed || replayed.Comment.GetId() != earlier.Id || replayed.Type != pb.CommentEventType_COMMENT_EVENT_TYPE_CREATED {
+		t.Fatalf("WatchComments() first event = %v, want the replayed comment", replayed)
+	}
+
+	live := createComment(t, srv, reviewerID, "", "during the watch")
+	event := nextCommentEvent(t, stream, live.Id)
+	if event.Replayed || event.Type != pb.CommentEventType_COMMENT_EVENT_TYPE_CREATED {
+		t.Errorf("WatchComments() live event = %v", event)
+	}
+
+	if _, err := srv.Comments.DeleteComment(ctx, &pb.DeleteCommentRequest{UserId: reviewerID, Id: live.Id}); err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+	event = nextCommentEvent(t, stream, live.Id)
+	if event.Type != pb.CommentEventType_COMMENT_EVENT_TYPE_DELETED {
+		t.Errorf("WatchComments() event = %v, want the deletion", event)
+	}
+
+	invalid, err := srv.Comments.WatchComments(ctx, &pb.WatchCommentsRequest{Type: "lab"})
+	if err != nil {
+		t.Fatalf("WatchComments() error = %v", err)
+	}
+	_, err = invalid.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestSubscribeFeedbackEvents(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	since := timestamppb.New(time.Now().Add(-time.Minute))
+	earlier := createFeedback(t, srv, submissionID, "Earlier", "Content", false)
+	draft := createFeedback(t, srv, submissionID+1, "Draft", "Content", true)
+
+	stream, err := srv.Feedback.SubscribeFeedbackEvents(ctx, &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, Since: since})
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+	}
+	replayed, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+	}
+	if !replayed.Replayed || replayed.Feedback.GetId() != earlier.Id || replayed.Type != pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_PUBLISHED {
+		t.Fatalf("SubscribeFeedbackEvents() first event = %v, want the replayed publication", replayed)
+	}
+
+	if _, err := srv.Feedback.PublishFeedback(ctx, &pb.PublishFeedbackRequest{Id: draft.Id, ReviewerId: reviewerID}); err != nil {
+		t.Fatalf("PublishFeedback() error = %v", err)
+	}
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+	}
+	if event.Replayed || event.Feedback.GetId() != draft.Id || event.Type != pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_PUBLISHED {
+		t.Errorf("SubscribeFeedbackEvents() live event = %v, want the publication", event)
+	}
+
+	invalid, err := srv.Feedback.SubscribeFeedbackEvents(ctx, &pb.SubscribeFeedbackEventsRequest{})
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+	}
+	_, err = invalid.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+// seedStudentFeedback stores a published feedback for studentID on a submission without
+// publishing its events, so a stream only sees it read back for since
+func seedStudentFeedback(t *testing.T, srv *servertest.Server, submission int64) *models.Feedback {
+	t.Helper()
+	feedback := &models.Feedback{ReviewerID: reviewerID, StudentID: studentID, SubmissionID: submission, Title: "v0", Status: models.FeedbackPublished}
+	if err := srv.Store.Feedbacks().Create(testContext(t), feedback); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return feedback
+}
+
+func TestSubscribeFeedbackEventsResume(t *testing.T) {
+	type segment struct {
+		replay int // Replayed events to read before disconnecting; when 0, all of them and then live events
+		live   int // Events made and read while connected
+		missed int // Events made after disconnecting
+	}
+	tests := []struct {
+		name     string
+		segments []segment
+	}{
+		{name: "reconnect after every event", segments: []segment{{live: 1}, {live: 1}, {live: 1}, {}}},
+		{name: "events missed while disconnected", segments: []segment{{live: 2, missed: 3}, {live: 1, missed: 1}, {missed: 2}, {live: 1}}},
+		{name: "disconnect during the replay", segments: []segment{{live: 1, missed: 4}, {replay: 1}, {replay: 2, missed: 1}, {live: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := servertest.New(t, nil)
+			feedback := seedStudentFeedback(t, srv, submissionID)
+			since := timestamppb.New(time.Now().Add(-time.Minute))
+
+			// Events are pushed directly, so each is in the journal when push returns; event i
+			// is titled vi, and v0 is the snapshot read back for since
+			made := 0
+			push := func() {
+				made++
+				next := *feedback
+				next.Title = fmt.Sprintf("v%d", made)
+				srv.FeedbackService.PushFeedbackEvent(models.FeedbackEventUpdated, &next)
+			}
+			var received []*pb.FeedbackEvent
+			recv := func(stream pb.FeedbackService_SubscribeFeedbackEventsClient, wantReplayed bool) {
+				t.Helper()
+				event, err := stream.Recv()
+				if err != nil {
+					t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+				}
+				want := len(received)
+				if event.Feedback.GetTitle() != fmt.Sprintf("v%d", want) || event.Sequence != uint64(want) || event.ResumeToken == "" {
+					t.Fatalf("SubscribeFeedbackEvents() event = %v, want v%d with sequence %d", event, want, want)
+				}
+				if wantReplayed && !event.Replayed {
+					t.Errorf("SubscribeFeedbackEvents() event v%d was not replayed", want)
+				}
+				received = append(received, event)
+			}
+
+			for i, seg := range tt.segments {
+				ctx, cancel := context.WithCancel(testContext(t))
+				req := &pb.SubscribeFeedbackEventsRequest{StudentId: studentID}
+				if i == 0 {
+					req.Since = since
+				} else {
+					req.ResumeAfter = received[len(received)-1].ResumeToken
+				}
+				stream, err := srv.Feedback.SubscribeFeedbackEvents(ctx, req)
+				if err != nil {
+					t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+				}
+
+				backlog := made - (len(received) - 1)
+				if i == 0 {
+					backlog = 1 // The snapshot
+				}
+				if seg.replay > 0 {
+					backlog = seg.replay
+				}
+				for range backlog {
+					recv(stream, true)
+				}
+				if seg.replay == 0 {
+					for range seg.live {
+						push()
+						recv(stream, false) // Replayed if pushed before the stream started
+					}
+				}
+				cancel()
+				for range seg.missed {
+					push()
+				}
+			}
+			if len(received) != made+1 {
+				t.Errorf("received %d events, want %d", len(received), made+1)
+			}
+		})
+	}
+}
+
+func TestSubscribeFeedbackEventsResumeErrors(t *testing.T) {
+	srv := servertest.New(t, func(cfg *config.Config) {
+		cfg.Events.ReplayWindow = 50 * time.Millisecond
+	})
+	ctx := testContext(t)
+	feedback := seedStudentFeedback(t, srv, submissionID)
+
+	stream, err := srv.Feedback.SubscribeFeedbackEvents(ctx, &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, Since: timestamppb.New(time.Now().Add(-time.Minute))})
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+	}
+	snapshot, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("SubscribeFeedbackEvents() Recv error = %v", err)
+	}
+	// Once the event after the token has been pruned from the journal, the token expires
+	srv.FeedbackService.PushFeedbackEvent(models.FeedbackEventUpdated, feedback)
+	time.Sleep(100 * time.Millisecond)
+	srv.FeedbackService.PushFeedbackEvent(models.FeedbackEventUpdated, feedback)
+
+	restarted := servertest.New(t, nil)
+	tests := []struct {
+		name       string
+		srv        *servertest.Server
+		req        *pb.SubscribeFeedbackEventsRequest
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "since and resume_after", srv: srv, req: &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, Since: timestamppb.Now(), ResumeAfter: snapshot.ResumeToken}, wantCode: codes.InvalidArgument},
+		{name: "malformed token", srv: srv, req: &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, ResumeAfter: "garbage"}, wantCode: codes.InvalidArgument, wantReason: "INVALID_RESUME_TOKEN"},
+		{name: "token of another student", srv: srv, req: &pb.SubscribeFeedbackEventsRequest{StudentId: otherUserID, ResumeAfter: snapshot.ResumeToken}, wantCode: codes.InvalidArgument, wantReason: "INVALID_RESUME_TOKEN"},
+		{name: "token older than the replay window", srv: srv, req: &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, ResumeAfter: snapshot.ResumeToken}, wantCode: codes.FailedPrecondition, wantReason: "REPLAY_WINDOW_EXCEEDED"},
+		{name: "token issued before a restart", srv: restarted, req: &pb.SubscribeFeedbackEventsRequest{StudentId: studentID, ResumeAfter: snapshot.ResumeToken}, wantCode: codes.FailedPrecondition, wantReason: "REPLAY_WINDOW_EXCEEDED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := tt.srv.Feedback.SubscribeFeedbackEvents(testContext(t), tt.req)
+			if err != nil {
+				t.Fatalf("SubscribeFeedbackEvents() error = %v", err)
+			}
+			_, err = stream.Recv()
+			wantCode(t, err, tt.wantCode)
+			if tt.wantReason != "" {
+				wantReason(t, err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestWatchCommentsResume(t *testing.T) {
+	srv := servertest.New(t, nil)
+	since := timestamppb.New(time.Now().Add(-time.Minute))
+
+	// Comment events come from the poller, so a comment made while disconnected reaches the
+	// stream from the journal or live; either way each arrives once and in order
+	var comments []*pb.Comment
+	create := func() {
+		comments = append(comments, createComment(t, srv, studentID, "", fmt.Sprintf("c%d", len(comments))))
+	}
+	var received []*pb.CommentEvent
+	recv := func(stream pb.CommentService_WatchCommentsClient) {
+		t.Helper()
+		event := nextCommentEvent(t, stream, comments[len(received)].Id)
+		if event.Type != pb.CommentEventType_COMMENT_EVENT_TYPE_CREATED || event.ResumeToken == "" {
+			t.Fatalf("WatchComments() event = %v, want the creation", event)
+		}
+		if n := len(received); n > 0 && event.Sequence <= received[n-1].Sequence {
+			t.Fatalf("WatchComments() event sequence = %d after %d, want it to increase", event.Sequence, received[n-1].Sequence)
+		}
+		received = append(received, event)
+	}
+	connect := func(req *pb.WatchCommentsRequest) (pb.CommentService_WatchCommentsClient, context.CancelFunc) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(testContext(t))
+		req.ContentId, req.Type = labID, "lab"
+		if req.Since == nil {
+			req.ResumeAfter = received[len(received)-1].ResumeToken
+		}
+		stream, err := srv.Comments.WatchComments(ctx, req)
+		if err != nil {
+			t.Fatalf("WatchComments() error = %v", err)
+		}
+		return stream, cancel
+	}
+
+	stream, disconnect := connect(&pb.WatchCommentsRequest{Since: since})
+	create()
+	recv(stream)
+	create()
+	recv(stream)
+	disconnect()
+
+	// Disconnected while two comments are made; reconnect and disconnect after the first
+	create()
+	create()
+	stream, disconnect = connect(&pb.WatchCommentsRequest{})
+	recv(stream)
+	disconnect()
+
+	create()
+	stream, disconnect = connect(&pb.WatchCommentsRequest{})
+	recv(stream)
+	recv(stream)
+	create()
+	recv(stream)
+	defer disconnect()
+
+	other, err := srv.Comments.WatchComments(testContext(t), &pb.WatchCommentsRequest{ContentId: labID + 1, Type: "lab", ResumeAfter: received[0].ResumeToken})
+	if err != nil {
+		t.Fatalf("WatchComments() error = %v", err)
+	}
+	_, err = other.Recv()
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "INVALID_RESUME_TOKEN")
+}
@@ -0,0 +1,319 @@
+package server_test
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"testing"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server/servertest"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// createFeedbacks creates n published feedbacks on consecutive submissions, oldest first
+func createFeedbacks(t *testing.T, srv *servertest.Server, n int) []*pb.Feedback {
+	t.Helper()
+	feedbacks := make([]*pb.Feedback, n)
+	for i := range feedbacks {
+		feedbacks[i] = createFeedback(t, srv, submissionID+int64(i), fmt.Sprintf("Review %d", i), "Content", false)
+	}
+	return feedbacks
+}
+
+func TestListReviewerFeedbacksPagination(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedbacks := createFeedbacks(t, srv, 3)
+
+	first, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks() error = %v", err)
+	}
+	if len(first.Feedbacks) != 2 || first.TotalCount64 != 3 || first.NextPageToken == "" {
+		t.Fatalf("ListReviewerFeedbacks() = %d feedbacks of %d, next_page_token = %q", len(first.Feedbacks), first.TotalCount64, first.NextPageToken)
+	}
+	if first.Feedbacks[0].Id != feedbacks[2].Id || first.Feedbacks[1].Id != feedbacks[1].Id {
+		t.Errorf("ListReviewerFeedbacks() did not list the newest feedbacks first")
+	}
+
+	last, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Limit: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks(page_token) error = %v", err)
+	}
+	if len(last.Feedbacks) != 1 || last.Feedbacks[0].Id != feedbacks[0].Id {
+		t.Errorf("ListReviewerFeedbacks(page_token) = %v, want the oldest feedback", last.Feedbacks)
+	}
+	if last.NextPageToken != "" {
+		t.Errorf("ListReviewerFeedbacks(page_token) next_page_token = %q on the last page", last.NextPageToken)
+	}
+
+	exact, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Limit: 3})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks(limit=3) error = %v", err)
+	}
+	if len(exact.Feedbacks) != 3 || exact.NextPageToken != "" {
+		t.Errorf("ListReviewerFeedbacks(limit=3) = %d feedbacks, next_page_token = %q", len(exact.Feedbacks), exact.NextPageToken)
+	}
+
+	beyond, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Page: 3, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks(page=3) error = %v", err)
+	}
+	if len(beyond.Feedbacks) != 0 || beyond.TotalCount64 != 3 {
+		t.Errorf("ListReviewerFeedbacks(page=3) = %d feedbacks of %d, want none of 3", len(beyond.Feedbacks), beyond.TotalCount64)
+	}
+
+	_, err = srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Page: 2, Limit: 2, PageToken: first.NextPageToken})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Limit: 2, PageToken: "garbage"})
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "INVALID_PAGE_TOKEN")
+
+	_, err = srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{
+		ReviewerId:    reviewerID,
+		Limit:         2,
+		PageToken:     first.NextPageToken,
+		SortBy:        pb.FeedbackSortField_FEEDBACK_SORT_FIELD_TITLE,
+		SortDirection: pb.SortDirection_SORT_DIRECTION_ASC,
+	})
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "INVALID_PAGE_TOKEN")
+}
+
+func TestListLimits(t *testing.T) {
+	srv := servertest.New(t, func(cfg *config.Config) { cfg.Flags.MetadataOverrides = true })
+	ctx := testContext(t)
+	createFeedbacks(t, srv, 2)
+
+	clamped, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Limit: 1000})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks(limit=1000) error = %v", err)
+	}
+	if len(clamped.Feedbacks) != 2 {
+		t.Errorf("ListReviewerFeedbacks(limit=1000) = %d feedbacks, want 2", len(clamped.Feedbacks))
+	}
+
+	strict := metadata.AppendToOutgoingContext(ctx, flags.MetadataKey, "strict_limit_validation=true")
+	tests := []struct {
+		name  string
+		page  int32
+		limit int32
+		want  codes.Code
+	}{
+		{name: "default limit", page: 0, limit: 0, want: codes.OK},
+		{name: "largest limit", page: 1, limit: 100, want: codes.OK},
+		{name: "limit above maximum", page: 1, limit: 101, want: codes.InvalidArgument},
+		{name: "negative limit", page: 1, limit: -1, want: codes.InvalidArgument},
+		{name: "negative page", page: -1, limit: 10, want: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := srv.Feedback.ListReviewerFeedbacks(strict, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, Page: tt.page, Limit: tt.limit})
+			wantCode(t, err, tt.want)
+		})
+	}
+}
+
+func TestListStudentFeedbacks(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedbacks := createFeedbacks(t, srv, 3)
+	createFeedback(t, srv, submissionID+10, "Draft", "Content", true)
+	if _, err := srv.Feedback.AcknowledgeFeedback(ctx, &pb.AcknowledgeFeedbackRequest{Id: feedbacks[0].Id, StudentId: studentID}); err != nil {
+		t.Fatalf("AcknowledgeFeedback() error = %v", err)
+	}
+
+	var listed []string
+	token := ""
+	for range 3 {
+		resp, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID, Limit: 2, PageToken: token})
+		if err != nil {
+			t.Fatalf("ListStudentFeedbacks() error = %v", err)
+		}
+		if resp.TotalCount64 != 3 {
+			t.Errorf("ListStudentFeedbacks() total = %d, want 3 without the draft", resp.TotalCount64)
+		}
+		for _, feedback := range resp.Feedbacks {
+			listed = append(listed, feedback.Id)
+		}
+		if token = resp.NextPageToken; token == "" {
+			break
+		}
+	}
+	if len(listed) != 3 || token != "" {
+		t.Errorf("ListStudentFeedbacks() listed %d feedbacks, last next_page_token = %q", len(listed), token)
+	}
+
+	unread, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID, UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("ListStudentFeedbacks(unread_only) error = %v", err)
+	}
+	if len(unread.Feedbacks) != 2 {
+		t.Errorf("ListStudentFeedbacks(unread_only) = %d feedbacks, want 2", len(unread.Feedbacks))
+	}
+
+	_, err = srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID, PageToken: "garbage"})
+	wantCode(t, err, codes.InvalidArgument)
+	wantReason(t, err, "INVALID_PAGE_TOKEN")
+
+	_, err = srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestReviewerDashboard(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	createFeedbacks(t, srv, 2)
+
+	dashboard, err := srv.Feedback.GetReviewerDashboard(ctx, &pb.GetReviewerDashboardRequest{ReviewerId: reviewerID})
+	if err != nil {
+		t.Fatalf("GetReviewerDashboard() error = %v", err)
+	}
+	if len(dashboard.Rows) != 2 || dashboard.TotalCount64 != 2 {
+		t.Fatalf("GetReviewerDashboard() = %d rows of %d, want 2", len(dashboard.Rows), dashboard.TotalCount64)
+	}
+	if dashboard.Rows[0].ContentPreview != "Content" || len(dashboard.Degraded) != 0 {
+		t.Errorf("GetReviewerDashboard() preview = %q, degraded = %v", dashboard.Rows[0].ContentPreview, dashboard.Degraded)
+	}
+
+	prefetch, err := srv.Feedback.PrefetchReviewerDashboard(ctx, &pb.PrefetchReviewerDashboardRequest{ReviewerId: reviewerID})
+	if err != nil {
+		t.Fatalf("PrefetchReviewerDashboard() error = %v", err)
+	}
+	if !prefetch.Started {
+		t.Error("PrefetchReviewerDashboard() started = false")
+	}
+
+	_, err = srv.Feedback.GetReviewerDashboard(ctx, &pb.GetReviewerDashboardRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestListAttachmentFilters(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	admin := servertest.Admin(ctx)
+	feedbacks := createFeedbacks(t, srv, 3)
+	upload(t, srv, feedbacks[0].Id, "report.txt", []byte("uploaded"))
+	// Stored before metadata was recorded: only listing MinIO finds them
+	srv.Store.SeedObject(uuid.MustParse(feedbacks[1].Id), "legacy.txt", "text/plain", []byte("stored before metadata"))
+	srv.Store.SeedObject(uuid.MustParse(feedbacks[1].Id), "notes.txt", "text/plain", []byte("also stored before metadata"))
+
+	with, without := true, false
+	one, two := int32(1), int32(2)
+	secondSubmission := int64(submissionID + 1)
+	tests := []struct {
+		name           string
+		submissionID   *int64
+		hasAttachments *bool
+		minAttachments *int32
+		want           []string
+	}{
+		{name: "with attachments", hasAttachments: &with, want: []string{feedbacks[1].Id, feedbacks[0].Id}},
+		{name: "without attachments", hasAttachments: &without, want: []string{feedbacks[2].Id}},
+		{name: "at least one", minAttachments: &one, want: []string{feedbacks[1].Id, feedbacks[0].Id}},
+		{name: "at least two", minAttachments: &two, want: []string{feedbacks[1].Id}},
+		{name: "with attachments and at least two", hasAttachments: &with, minAttachments: &two, want: []string{feedbacks[1].Id}},
+		{name: "composed with the submission", submissionID: &secondSubmission, hasAttachments: &with, want: []string{feedbacks[1].Id}},
+		{name: "composed with the submission, none left", submissionID: &secondSubmission, hasAttachments: &without, want: nil},
+	}
+	check := func(t *testing.T) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ids := func(feedbacks []*pb.Feedback) []string {
+					var got []string
+					for _, feedback := range feedbacks {
+						got = append(got, feedback.Id)
+					}
+					return got
+				}
+
+				list, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{
+					ReviewerId:         reviewerID,
+					SubmissionId:       tt.submissionID,
+					HasAttachments:     tt.hasAttachments,
+					MinAttachmentCount: tt.minAttachments,
+				})
+				if err != nil {
+					t.Fatalf("ListReviewerFeedbacks() error = %v", err)
+				}
+				if got := ids(list.Feedbacks); !slices.Equal(got, tt.want) || list.TotalCount64 != int64(len(tt.want)) {
+					t.Errorf("ListReviewerFeedbacks() = %v of %d, want %v", got, list.TotalCount64, tt.want)
+				}
+
+				all, err := srv.Feedback.ListFeedbacks(admin, &pb.ListFeedbacksRequest{
+					SubmissionId:       tt.submissionID,
+					HasAttachments:     tt.hasAttachments,
+					MinAttachmentCount: tt.minAttachments,
+				})
+				if err != nil {
+					t.Fatalf("ListFeedbacks() error = %v", err)
+				}
+				if got := ids(all.Feedbacks); !slices.Equal(got, tt.want) || all.TotalCount != int64(len(tt.want)) {
+					t.Errorf("ListFeedbacks() = %v of %d, want %v", got, all.TotalCount, tt.want)
+				}
+
+				dashboard, err := srv.Feedback.GetReviewerDashboard(ctx, &pb.GetReviewerDashboardRequest{
+					ReviewerId:         reviewerID,
+					SubmissionId:       tt.submissionID,
+					HasAttachments:     tt.hasAttachments,
+					MinAttachmentCount: tt.minAttachments,
+				})
+				if err != nil {
+					t.Fatalf("GetReviewerDashboard() error = %v", err)
+				}
+				if len(dashboard.Rows) != len(tt.want) {
+					t.Errorf("GetReviewerDashboard() = %d rows, want %d", len(dashboard.Rows), len(tt.want))
+				}
+			})
+		}
+	}
+
+	t.Run("before the backfill", check)
+
+	backfill := service.NewAttachmentBackfillService(srv.Store.Attachments(), srv.Store.Assets(), srv.Store.Backfill(), srv.Config.Metadata, slog.New(slog.DiscardHandler))
+	progress, err := backfill.Run(ctx)
+	if err != nil {
+		t.Fatalf("backfill Run() error = %v", err)
+	}
+	if !progress.Complete {
+		t.Fatalf("backfill Run() = %+v, want complete", progress)
+	}
+	// Listing MinIO would now count an object without metadata; the table must answer alone
+	srv.Store.SeedObject(uuid.MustParse(feedbacks[2].Id), "stray.txt", "text/plain", []byte("no metadata"))
+
+	t.Run("after the backfill", check)
+
+	_, err = srv.Feedback.ListFeedbacks(ctx, &pb.ListFeedbacksRequest{})
+	wantCode(t, err, codes.PermissionDenied)
+}
+
+func TestListAttachmentFiltersPendingLimit(t *testing.T) {
+	srv := servertest.New(t, func(cfg *config.Config) { cfg.Metadata.FilterMaxPending = 2 })
+	ctx := testContext(t)
+	feedbacks := createFeedbacks(t, srv, 3)
+	srv.Store.SeedObject(uuid.MustParse(feedbacks[1].Id), "legacy.txt", "text/plain", []byte("stored before metadata"))
+
+	with := true
+	_, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, HasAttachments: &with})
+	wantCode(t, err, codes.FailedPrecondition)
+	wantReason(t, err, "ATTACHMENT_METADATA_INCOMPLETE")
+	_, err = srv.Feedback.ListFeedbacks(servertest.Admin(ctx), &pb.ListFeedbacksRequest{HasAttachments: &with})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	// A narrower list needs fewer listings
+	submission := int64(submissionID + 1)
+	resp, err := srv.Feedback.ListReviewerFeedbacks(ctx, &pb.ListReviewerFeedbacksRequest{ReviewerId: reviewerID, SubmissionId: &submission, HasAttachments: &with})
+	if err != nil {
+		t.Fatalf("ListReviewerFeedbacks() error = %v", err)
+	}
+	if len(resp.Feedbacks) != 1 || resp.Feedbacks[0].Id != feedbacks[1].Id {
+		t.Errorf("ListReviewerFeedbacks() = %v, want %s", resp.Feedbacks, feedbacks[1].Id)
+	}
+}
@@ -0,0 +1,639 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server/servertest"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	reviewerID   = 101
+	studentID    = 201
+	submissionID = 301
+	otherUserID  = 999
+)
+
+// testContext returns a context bounding a single scenario
+func testContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// wantCode fails the test unless err is a status error with the given code
+func wantCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("error = %v, want code %v", err, want)
+	}
+}
+
+// wantReason fails the test unless err carries an ErrorInfo with the given reason
+func wantReason(t *testing.T, err error, want string) {
+	t.Helper()
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == want {
+			return
+		}
+	}
+	t.Fatalf("error = %v, want reason %s", err, want)
+}
+
+// createFeedback creates a feedback of reviewerID for studentID on a submission, published
+// unless draft is set
+func createFeedback(t *testing.T, srv *servertest.Server, submission int64, title, content string, draft bool) *pb.Feedback {
+	t.Helper()
+	feedback, err := srv.Feedback.CreateFeedback(testContext(t), &pb.CreateFeedbackRequest{
+		ReviewerId:   reviewerID,
+		StudentId:    studentID,
+		SubmissionId: submission,
+		Title:        title,
+		Content:      content,
+		Publish:      !draft,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedback() error = %v", err)
+	}
+	return feedback
+}
+
+// recvAll reads a server stream until it ends
+func recvAll[T any](t *testing.T, recv func() (T, error)) []T {
+	t.Helper()
+	var messages []T
+	for {
+		msg, err := recv()
+		if errors.Is(err, io.EOF) {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func TestFeedbackLifecycle(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+
+	created := createFeedback(t, srv, submissionID, "Lab 1 review", "Solid work", true)
+	if created.Status != pb.FeedbackStatus_FEEDBACK_STATUS_DRAFT {
+		t.Errorf("CreateFeedback() status = %v, want DRAFT", created.Status)
+	}
+	if created.Name != "feedbacks/"+created.Id {
+		t.Errorf("CreateFeedback() name = %q, want feedbacks/%s", created.Name, created.Id)
+	}
+
+	if _, err := srv.Feedback.GetStudentFeedback(ctx, &pb.GetStudentFeedbackRequest{StudentId: studentID, SubmissionId: submissionID}); status.Code(err) == codes.OK {
+		t.Fatal("GetStudentFeedback() returned a draft")
+	}
+
+	title := "Lab 1 review (revised)"
+	updated, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: created.Id, Title: &title})
+	if err != nil {
+		t.Fatalf("UpdateFeedback() error = %v", err)
+	}
+	if updated.Title != title || updated.Content != "Solid work" {
+		t.Errorf("UpdateFeedback() = %q/%q, want %q/%q", updated.Title, updated.Content, title, "Solid work")
+	}
+
+	published, err := srv.Feedback.PublishFeedback(ctx, &pb.PublishFeedbackRequest{Id: created.Id, ReviewerId: reviewerID})
+	if err != nil {
+		t.Fatalf("PublishFeedback() error = %v", err)
+	}
+	if published.Status != pb.FeedbackStatus_FEEDBACK_STATUS_PUBLISHED || published.PublishedAt == nil {
+		t.Errorf("PublishFeedback() status = %v, published_at = %v", published.Status, published.PublishedAt)
+	}
+
+	student, err := srv.Feedback.GetStudentFeedback(ctx, &pb.GetStudentFeedbackRequest{StudentId: studentID, SubmissionId: submissionID})
+	if err != nil {
+		t.Fatalf("GetStudentFeedback() error = %v", err)
+	}
+	if student.Id != created.Id || !student.IsUnread {
+		t.Errorf("GetStudentFeedback() = %s unread=%v, want %s unread", student.Id, student.IsUnread, created.Id)
+	}
+
+	acknowledged, err := srv.Feedback.AcknowledgeFeedback(ctx, &pb.AcknowledgeFeedbackRequest{Id: created.Id, StudentId: studentID})
+	if err != nil {
+		t.Fatalf("AcknowledgeFeedback() error = %v", err)
+	}
+	if acknowledged.ReadAt == nil || acknowledged.IsUnread {
+		t.Errorf("AcknowledgeFeedback() read_at = %v, is_unread = %v", acknowledged.ReadAt, acknowledged.IsUnread)
+	}
+
+	reacted, err := srv.Feedback.ReactToFeedback(ctx, &pb.ReactToFeedbackRequest{Id: created.Id, StudentId: studentID, Reaction: pb.FeedbackReaction_FEEDBACK_REACTION_HELPFUL})
+	if err != nil {
+		t.Fatalf("ReactToFeedback() error = %v", err)
+	}
+	if reacted.HelpfulCount != 1 {
+		t.Errorf("ReactToFeedback() helpful_count = %d, want 1", reacted.HelpfulCount)
+	}
+	unreacted, err := srv.Feedback.RemoveReaction(ctx, &pb.RemoveReactionRequest{Id: created.Id, StudentId: studentID})
+	if err != nil {
+		t.Fatalf("RemoveReaction() error = %v", err)
+	}
+	if unreacted.HelpfulCount != 0 {
+		t.Errorf("RemoveReaction() helpful_count = %d, want 0", unreacted.HelpfulCount)
+	}
+
+	revisions, err := srv.Feedback.ListFeedbackRevisions(ctx, &pb.ListFeedbackRevisionsRequest{Id: created.Id, UserId: reviewerID})
+	if err != nil {
+		t.Fatalf("ListFeedbackRevisions() error = %v", err)
+	}
+	if revisions.TotalCount != 2 || len(revisions.Revisions) != 2 || revisions.Revisions[0].Title != title || revisions.Revisions[1].Title != "Lab 1 review" {
+		t.Errorf("ListFeedbackRevisions() = %v, want the edit and the original version", revisions.Revisions)
+	}
+
+	byID, err := srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: created.Id})
+	if err != nil {
+		t.Fatalf("GetFeedbackById() error = %v", err)
+	}
+	if byID.Title != title || byID.RevisionCount != 1 {
+		t.Errorf("GetFeedbackById() title = %q, revision_count = %d", byID.Title, byID.RevisionCount)
+	}
+
+	deleted, err := srv.Feedback.DeleteFeedback(ctx, &pb.DeleteFeedbackRequest{ReviewerId: reviewerID, Id: created.Id})
+	if err != nil {
+		t.Fatalf("DeleteFeedback() error = %v", err)
+	}
+	if !deleted.Success {
+		t.Errorf("DeleteFeedback() success = false")
+	}
+	_, err = srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: created.Id})
+	if status.Code(err) == codes.OK {
+		t.Fatal("GetFeedbackById() found a deleted feedback")
+	}
+}
+
+func TestFeedbackErrors(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+	other := "Other"
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{
+			name: "create without reviewer",
+			call: func() error {
+				_, err := srv.Feedback.CreateFeedback(ctx, &pb.CreateFeedbackRequest{StudentId: studentID, SubmissionId: submissionID, Title: "T"})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "update with malformed id",
+			call: func() error {
+				_, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: "not-a-uuid", Title: &other})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "update of unknown feedback",
+			call: func() error {
+				_, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: "00000000-0000-0000-0000-000000000001", Title: &other})
+				return err
+			},
+			want: codes.NotFound,
+		},
+		{
+			name: "update by another reviewer",
+			call: func() error {
+				_, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: otherUserID, Id: feedback.Id, Title: &other})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "delete by another reviewer",
+			call: func() error {
+				_, err := srv.Feedback.DeleteFeedback(ctx, &pb.DeleteFeedbackRequest{ReviewerId: otherUserID, Id: feedback.Id})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "acknowledge by another student",
+			call: func() error {
+				_, err := srv.Feedback.AcknowledgeFeedback(ctx, &pb.AcknowledgeFeedbackRequest{Id: feedback.Id, StudentId: otherUserID})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "lock without administrator role",
+			call: func() error {
+				_, err := srv.Feedback.SetFeedbackLock(ctx, &pb.SetFeedbackLockRequest{FeedbackId: feedback.Id, Locked: true, ActorId: 1})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "snapshot update from an external caller",
+			call: func() error {
+				_, err := srv.Feedback.UpdateFeedbackSnapshot(ctx, &pb.UpdateFeedbackSnapshotRequest{})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "submission list from an external caller",
+			call: func() error {
+				_, err := srv.Feedback.ListSubmissionFeedbacks(ctx, &pb.ListSubmissionFeedbacksRequest{SubmissionId: submissionID})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "batch get without ids",
+			call: func() error {
+				_, err := srv.Feedback.BatchGetFeedbacks(ctx, &pb.BatchGetFeedbacksRequest{})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "search with a short query",
+			call: func() error {
+				_, err := srv.Feedback.SearchFeedbacks(ctx, &pb.SearchFeedbacksRequest{Query: "ab", ReviewerId: proto.Int64(reviewerID)})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantCode(t, tt.call(), tt.want)
+		})
+	}
+}
+
+func TestFeedbackLock(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	admin := servertest.Admin(ctx)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	locked, err := srv.Feedback.SetFeedbackLock(admin, &pb.SetFeedbackLockRequest{FeedbackId: feedback.Id, Locked: true, Reason: "appeal", ActorId: 1})
+	if err != nil {
+		t.Fatalf("SetFeedbackLock() error = %v", err)
+	}
+	if !locked.Locked || locked.LockReason != "appeal" {
+		t.Errorf("SetFeedbackLock() locked = %v, reason = %q", locked.Locked, locked.LockReason)
+	}
+
+	title := "Edited"
+	_, err = srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: feedback.Id, Title: &title})
+	wantCode(t, err, codes.FailedPrecondition)
+	_, err = srv.Feedback.DeleteFeedback(ctx, &pb.DeleteFeedbackRequest{ReviewerId: reviewerID, Id: feedback.Id})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	if _, err := srv.Feedback.SetFeedbackLock(admin, &pb.SetFeedbackLockRequest{FeedbackId: feedback.Id, ActorId: 1}); err != nil {
+		t.Fatalf("SetFeedbackLock(unlock) error = %v", err)
+	}
+	if _, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: feedback.Id, Title: &title}); err != nil {
+		t.Fatalf("UpdateFeedback() after unlock error = %v", err)
+	}
+}
+
+func TestFeedbackOwnershipAndArchive(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", false)
+
+	transferred, err := srv.Feedback.TransferFeedbackOwnership(servertest.Admin(ctx), &pb.TransferFeedbackOwnershipRequest{
+		Id:                feedback.Id,
+		CurrentReviewerId: reviewerID,
+		NewReviewerId:     102,
+		ActorId:           1,
+	})
+	if err != nil {
+		t.Fatalf("TransferFeedbackOwnership() error = %v", err)
+	}
+	if transferred.ReviewerId != 102 {
+		t.Errorf("TransferFeedbackOwnership() reviewer_id = %d, want 102", transferred.ReviewerId)
+	}
+
+	archived, err := srv.Feedback.ArchiveFeedback(servertest.Admin(ctx), &pb.ArchiveFeedbackRequest{Id: feedback.Id, ActorId: 1})
+	if err != nil {
+		t.Fatalf("ArchiveFeedback() error = %v", err)
+	}
+	if !archived.Archived || archived.ArchivedAt == nil {
+		t.Errorf("ArchiveFeedback() archived = %v, archived_at = %v", archived.Archived, archived.ArchivedAt)
+	}
+	list, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID})
+	if err != nil {
+		t.Fatalf("ListStudentFeedbacks() error = %v", err)
+	}
+	if len(list.Feedbacks) != 0 {
+		t.Errorf("ListStudentFeedbacks() = %d feedbacks, want archived feedback left out", len(list.Feedbacks))
+	}
+
+	restored, err := srv.Feedback.ArchiveFeedback(servertest.Admin(ctx), &pb.ArchiveFeedbackRequest{Id: feedback.Id, ActorId: 1, Restore: true})
+	if err != nil {
+		t.Fatalf("ArchiveFeedback(restore) error = %v", err)
+	}
+	if restored.Archived {
+		t.Error("ArchiveFeedback(restore) archived = true")
+	}
+}
+
+func TestFeedbackApproval(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	const approverID = 150
+	feedback := createFeedback(t, srv, submissionID, "Review", "Content", true)
+
+	requested, err := srv.Feedback.RequestFeedbackApproval(ctx, &pb.RequestFeedbackApprovalRequest{Id: feedback.Id, ReviewerId: reviewerID, ApproverId: approverID})
+	if err != nil {
+		t.Fatalf("RequestFeedbackApproval() error = %v", err)
+	}
+	if requested.ApprovalStatus != pb.ApprovalStatus_APPROVAL_STATUS_PENDING || requested.GetApproverId() != approverID {
+		t.Errorf("RequestFeedbackApproval() approval = %v by %d", requested.ApprovalStatus, requested.GetApproverId())
+	}
+
+	_, err = srv.Feedback.ApproveFeedback(ctx, &pb.ApproveFeedbackRequest{Id: feedback.Id, ApproverId: otherUserID})
+	wantCode(t, err, codes.PermissionDenied)
+
+	changes, err := srv.Feedback.RequestFeedbackChanges(ctx, &pb.RequestFeedbackChangesRequest{Id: feedback.Id, ApproverId: approverID, Note: "more detail"})
+	if err != nil {
+		t.Fatalf("RequestFeedbackChanges() error = %v", err)
+	}
+	if changes.ApprovalStatus != pb.ApprovalStatus_APPROVAL_STATUS_CHANGES_REQUESTED || changes.ApprovalNote != "more detail" {
+		t.Errorf("RequestFeedbackChanges() approval = %v, note = %q", changes.ApprovalStatus, changes.ApprovalNote)
+	}
+
+	if _, err := srv.Feedback.RequestFeedbackApproval(ctx, &pb.RequestFeedbackApprovalRequest{Id: feedback.Id, ReviewerId: reviewerID, ApproverId: approverID}); err != nil {
+		t.Fatalf("RequestFeedbackApproval() again error = %v", err)
+	}
+	approved, err := srv.Feedback.ApproveFeedback(ctx, &pb.ApproveFeedbackRequest{Id: feedback.Id, ApproverId: approverID})
+	if err != nil {
+		t.Fatalf("ApproveFeedback() error = %v", err)
+	}
+	if approved.ApprovalStatus != pb.ApprovalStatus_APPROVAL_STATUS_APPROVED {
+		t.Errorf("ApproveFeedback() approval = %v, want APPROVED", approved.ApprovalStatus)
+	}
+}
+
+func TestFeedbackReads(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	first := createFeedback(t, srv, submissionID, "Recursion review", "Base cases are missing", false)
+	second := createFeedback(t, srv, submissionID+1, "Sorting review", "Nice and tidy", false)
+
+	batch, err := srv.Feedback.BatchGetFeedbacks(ctx, &pb.BatchGetFeedbacksRequest{
+		Ids: []string{second.Id, "feedbacks/" + first.Id, second.Id, "00000000-0000-0000-0000-000000000001"},
+	})
+	if err != nil {
+		t.Fatalf("BatchGetFeedbacks() error = %v", err)
+	}
+	if len(batch.Feedbacks) != 2 || batch.Feedbacks[0].Id != second.Id || batch.Feedbacks[1].Id != first.Id {
+		t.Errorf("BatchGetFeedbacks() = %v, want the two feedbacks in request order", batch.Feedbacks)
+	}
+	if len(batch.MissingIds) != 1 || batch.MissingIds[0] != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("BatchGetFeedbacks() missing_ids = %v", batch.MissingIds)
+	}
+
+	submission, err := srv.Feedback.GetStudentSubmissionFeedbacks(ctx, &pb.GetStudentSubmissionFeedbacksRequest{StudentId: studentID, SubmissionId: submissionID})
+	if err != nil {
+		t.Fatalf("GetStudentSubmissionFeedbacks() error = %v", err)
+	}
+	if submission.TotalCount64 != 1 {
+		t.Errorf("GetStudentSubmissionFeedbacks() total = %d, want 1", submission.TotalCount64)
+	}
+
+	internal, err := srv.Feedback.ListSubmissionFeedbacks(servertest.Internal(ctx), &pb.ListSubmissionFeedbacksRequest{SubmissionId: submissionID})
+	if err != nil {
+		t.Fatalf("ListSubmissionFeedbacks() error = %v", err)
+	}
+	if internal.TotalCount64 != 1 {
+		t.Errorf("ListSubmissionFeedbacks() total = %d, want 1", internal.TotalCount64)
+	}
+
+	stats, err := srv.Feedback.GetFeedbackStatistics(ctx, &pb.GetFeedbackStatisticsRequest{ReviewerId: proto.Int64(reviewerID)})
+	if err != nil {
+		t.Fatalf("GetFeedbackStatistics() error = %v", err)
+	}
+	if stats.TotalCount != 2 || stats.SubmissionCount != 2 || stats.CreatedLast_7Days != 2 {
+		t.Errorf("GetFeedbackStatistics() = %v", stats)
+	}
+
+	summary, err := srv.Feedback.GetStudentFeedbackSummary(ctx, &pb.GetStudentFeedbackSummaryRequest{StudentId: studentID})
+	if err != nil {
+		t.Fatalf("GetStudentFeedbackSummary() error = %v", err)
+	}
+	if summary.FeedbackCount != 2 || summary.UnreadCount != 2 || summary.SubmissionCount != 2 {
+		t.Errorf("GetStudentFeedbackSummary() = %v", summary)
+	}
+
+	search, err := srv.Feedback.SearchFeedbacks(ctx, &pb.SearchFeedbacksRequest{Query: "recursion", ReviewerId: proto.Int64(reviewerID)})
+	if err != nil {
+		t.Fatalf("SearchFeedbacks() error = %v", err)
+	}
+	if len(search.Hits) != 1 || search.Hits[0].Feedback.Id != first.Id {
+		t.Errorf("SearchFeedbacks() = %v, want the recursion review", search.Hits)
+	}
+
+	rendered, err := srv.Feedback.RenderFeedbackNotification(servertest.Internal(ctx), &pb.RenderFeedbackNotificationRequest{FeedbackId: first.Id, Format: pb.NotificationFormat_NOTIFICATION_FORMAT_HTML})
+	if err != nil {
+		t.Fatalf("RenderFeedbackNotification() error = %v", err)
+	}
+	if rendered.Body == "" || rendered.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("RenderFeedbackNotification() content_type = %q, body empty = %v", rendered.ContentType, rendered.Body == "")
+	}
+}
+
+// createFeedbackComment comments on a feedback, as a reply when parentID is set
+func createFeedbackComment(t *testing.T, srv *servertest.Server, feedbackID string, userID int64, parentID, content string) *pb.Comment {
+	t.Helper()
+	req := &pb.CreateCommentRequest{ContentRef: feedbackID, UserId: userID, Content: content, Type: "feedback"}
+	if parentID != "" {
+		req.ParentId = proto.String(parentID)
+	}
+	comment, err := srv.Comments.CreateComment(testContext(t), req)
+	if err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+	return comment
+}
+
+func TestFeedbackThreadSummaries(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	discussed := createFeedback(t, srv, submissionID, "Recursion review", "Base cases are missing", false)
+	quiet := createFeedback(t, srv, submissionID+1, "Sorting review", "Looks fine", false)
+
+	answered := createFeedbackComment(t, srv, discussed.Id, studentID, "", "Which base case?")
+	createFeedbackComment(t, srv, discussed.Id, reviewerID, answered.Id, "The empty list")
+	createFeedbackComment(t, srv, discussed.Id, studentID, "", "Should I resubmit?")
+	createFeedbackComment(t, srv, discussed.Id, otherUserID, "", "Same question here")
+	last := createFeedbackComment(t, srv, discussed.Id, studentID, answered.Id, "Thanks")
+
+	wantSummaries := func(t *testing.T, feedbacks []*pb.Feedback) {
+		t.Helper()
+		for _, feedback := range feedbacks {
+			summary := feedback.ThreadSummary
+			switch feedback.Id {
+			case discussed.Id:
+				if summary.GetCommentCount() != 5 || summary.GetUnansweredQuestionCount() != 1 || !summary.GetLastActivityAt().AsTime().Equal(last.UpdatedAt.AsTime()) {
+					t.Errorf("thread_summary of %s = %v, want 5 comments, 1 unanswered question, last activity %v", feedback.Id, summary, last.UpdatedAt.AsTime())
+				}
+			case quiet.Id:
+				if summary == nil || summary.CommentCount != 0 || summary.UnansweredQuestionCount != 0 || summary.LastActivityAt != nil {
+					t.Errorf("thread_summary of %s = %v, want an empty summary", feedback.Id, summary)
+				}
+			}
+		}
+	}
+
+	list, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID, IncludeThreadSummary: true})
+	if err != nil {
+		t.Fatalf("ListStudentFeedbacks() error = %v", err)
+	}
+	if len(list.Feedbacks) != 2 {
+		t.Fatalf("ListStudentFeedbacks() = %d feedbacks, want 2", len(list.Feedbacks))
+	}
+	wantSummaries(t, list.Feedbacks)
+
+	submission, err := srv.Feedback.GetStudentSubmissionFeedbacks(ctx, &pb.GetStudentSubmissionFeedbacksRequest{StudentId: studentID, SubmissionId: submissionID, IncludeThreadSummary: true})
+	if err != nil {
+		t.Fatalf("GetStudentSubmissionFeedbacks() error = %v", err)
+	}
+	wantSummaries(t, submission.Feedbacks)
+
+	latest, err := srv.Feedback.GetStudentFeedback(ctx, &pb.GetStudentFeedbackRequest{StudentId: studentID, SubmissionId: submissionID, IncludeThreadSummary: true})
+	if err != nil {
+		t.Fatalf("GetStudentFeedback() error = %v", err)
+	}
+	if latest.ThreadSummary == nil {
+		t.Errorf("GetStudentFeedback() thread_summary unset")
+	}
+
+	plain, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID})
+	if err != nil {
+		t.Fatalf("ListStudentFeedbacks() error = %v", err)
+	}
+	for _, feedback := range plain.Feedbacks {
+		if feedback.ThreadSummary != nil {
+			t.Errorf("ListStudentFeedbacks() without include_thread_summary set thread_summary on %s", feedback.Id)
+		}
+	}
+
+	// Without MongoDB the feedback is still returned, only without summaries
+	srv.Store.FailThreadSummaries(errors.New("mongodb unavailable"))
+	degraded, err := srv.Feedback.ListStudentFeedbacks(ctx, &pb.ListStudentFeedbacksRequest{StudentId: studentID, IncludeThreadSummary: true})
+	if err != nil {
+		t.Fatalf("ListStudentFeedbacks() with comments unavailable error = %v", err)
+	}
+	for _, feedback := range degraded.Feedbacks {
+		if feedback.ThreadSummary != nil {
+			t.Errorf("ListStudentFeedbacks() with comments unavailable set thread_summary on %s", feedback.Id)
+		}
+	}
+}
+
+func TestFeedbackBulkOperations(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+	first := createFeedback(t, srv, submissionID, "First", "Content", false)
+	createFeedback(t, srv, submissionID+1, "Second", "Content", false)
+
+	snapshot, err := srv.Feedback.UpdateFeedbackSnapshot(servertest.Internal(ctx), &pb.UpdateFeedbackSnapshotRequest{
+		Updates: []*pb.SubmissionSnapshotUpdate{{
+			SubmissionId: submissionID,
+			Snapshot:     &pb.SubmissionSnapshot{LabTitle: "Lab 1", StudentDisplayName: "Student"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateFeedbackSnapshot() error = %v", err)
+	}
+	if snapshot.UpdatedCount != 1 {
+		t.Errorf("UpdateFeedbackSnapshot() updated_count = %d, want 1", snapshot.UpdatedCount)
+	}
+	got, err := srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: first.Id})
+	if err != nil {
+		t.Fatalf("GetFeedbackById() error = %v", err)
+	}
+	if got.SubmissionSnapshot.GetLabTitle() != "Lab 1" {
+		t.Errorf("GetFeedbackById() snapshot = %v", got.SubmissionSnapshot)
+	}
+
+	export, err := srv.Feedback.ExportReviewerFeedbacks(ctx, &pb.ExportReviewerFeedbacksRequest{ReviewerId: reviewerID})
+	if err != nil {
+		t.Fatalf("ExportReviewerFeedbacks() error = %v", err)
+	}
+	messages := recvAll(t, export.Recv)
+	if len(messages) == 0 || messages[len(messages)-1].GetTrailer().GetRowCount() != 2 {
+		t.Errorf("ExportReviewerFeedbacks() trailer = %v, want 2 rows", messages)
+	}
+
+	_, err = srv.Feedback.DeleteFeedbacksBySubmission(ctx, &pb.DeleteFeedbacksBySubmissionRequest{SubmissionId: submissionID, ActorId: 1})
+	wantCode(t, err, codes.PermissionDenied)
+	deleted, err := srv.Feedback.DeleteFeedbacksBySubmission(servertest.Admin(ctx), &pb.DeleteFeedbacksBySubmissionRequest{SubmissionId: submissionID, ActorId: 1})
+	if err != nil {
+		t.Fatalf("DeleteFeedbacksBySubmission() error = %v", err)
+	}
+	if deleted.DeletedCount != 1 || deleted.FailedCount != 0 || !deleted.Completed {
+		t.Errorf("DeleteFeedbacksBySubmission() = %v", deleted)
+	}
+}
+
+func TestFeedbackContentOutbox(t *testing.T) {
+	srv := servertest.New(t, nil)
+	ctx := testContext(t)
+
+	srv.Store.FailContentWrites(errors.New("mongodb unavailable"))
+	created := createFeedback(t, srv, submissionID, "Lab 1 review", "Solid work", true)
+	got, err := srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: created.Id})
+	if err != nil {
+		t.Fatalf("GetFeedbackById() error = %v", err)
+	}
+	if got.Content != "Solid work" {
+		t.Errorf("GetFeedbackById() content = %q, want the pending content", got.Content)
+	}
+
+	title := "Lab 1 review, revised"
+	if _, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: created.Id, Title: &title}); err != nil {
+		t.Fatalf("UpdateFeedback(title) error = %v", err)
+	}
+	if _, err := srv.Feedback.UpdateFeedback(ctx, &pb.UpdateFeedbackRequest{ReviewerId: reviewerID, Id: created.Id, Title: &title, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}}}); err != nil {
+		t.Fatalf("UpdateFeedback(update_mask=title) error = %v", err)
+	}
+
+	srv.Store.FailContentWrites(nil)
+	reconciled, err := srv.Feedback.ReconcileFeedback(servertest.Admin(ctx), &pb.ReconcileFeedbackRequest{FeedbackId: created.Name})
+	if err != nil {
+		t.Fatalf("ReconcileFeedback() error = %v", err)
+	}
+	if reconciled.Applied != 1 || reconciled.Remaining != 0 {
+		t.Errorf("ReconcileFeedback() = %v, want the created content applied", reconciled)
+	}
+	if stored, _ := srv.Store.StoredContent(uuid.MustParse(created.Id)); stored != "Solid work" {
+		t.Errorf("stored content = %q, want the created content", stored)
+	}
+	got, err = srv.Feedback.GetFeedbackById(ctx, &pb.GetFeedbackByIdRequest{Id: created.Id})
+	if err != nil {
+		t.Fatalf("GetFeedbackById() error = %v", err)
+	}
+	if got.Title != title || got.Content != "Solid work" {
+		t.Errorf("GetFeedbackById() = %q, %q, want the new title and the created content", got.Title, got.Content)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// convertToProtoConsistencyFinding converts model ConsistencyFinding to protobuf ConsistencyFinding
+func convertToProtoConsistencyFinding(finding *models.ConsistencyFinding) *pb.ConsistencyFinding {
+	pbFinding := &pb.ConsistencyFinding{
+		Kind:             finding.Kind,
+		Prefix:           finding.Prefix,
+		MissingFilenames: finding.Missing,
+		ObjectCount:      int32(finding.ObjectCount),
+		Bytes:            finding.Bytes,
+		Repaired:         finding.Repaired,
+		RepairError:      finding.RepairError,
+	}
+	if finding.FeedbackID != uuid.Nil {
+		pbFinding.FeedbackId = finding.FeedbackID.String()
+	}
+	return pbFinding
+}
+
+// ConsistencyReport streams inconsistencies between feedback rows and attachment storage,
+// followed by a summary (admin only)
+func (s *FeedbackServer) ConsistencyReport(req *pb.ConsistencyReportRequest, stream pb.FeedbackService_ConsistencyReportServer) error {
+	s.logger.Info("gRPC ConsistencyReport received",
+		"full_scan", req.FullScan,
+		"sample_percent", req.SamplePercent,
+		"delete_orphans", req.DeleteOrphans,
+		"mark_for_reupload", req.MarkForReupload,
+	)
+
+	if err := middleware.RequireAdmin(stream.Context()); err != nil {
+		s.logger.Warn("gRPC ConsistencyReport: permission denied")
+		return err
+	}
+	if req.SamplePercent < 0 || req.SamplePercent > 100 {
+		return status.Error(codes.InvalidArgument, "sample_percent must be between 0 and 100")
+	}
+
+	opts := models.ConsistencyOptions{
+		FullScan:        req.FullScan,
+		SamplePercent:   int(req.SamplePercent),
+		DeleteOrphans:   req.DeleteOrphans,
+		MarkForReupload: req.MarkForReupload,
+	}
+
+	var sendErr error
+	summary, err := s.feedbackService.CheckConsistency(stream.Context(), opts, func(finding *models.ConsistencyFinding) error {
+		sendErr = stream.Send(&pb.ConsistencyReportEntry{
+			Entry: &pb.ConsistencyReportEntry_Finding{Finding: convertToProtoConsistencyFinding(finding)},
+		})
+		return sendErr
+	})
+	if err != nil {
+		if sendErr != nil && errors.Is(err, sendErr) {
+			s.logger.Warn("gRPC ConsistencyReport: failed to send finding", "error", err)
+			return err
+		}
+		s.logger.Error("gRPC ConsistencyReport failed", "error", err)
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return status.Error(codes.Internal, fmt.Sprintf("consistency check failed: %v", err))
+	}
+
+	if err := stream.Send(&pb.ConsistencyReportEntry{
+		Entry: &pb.ConsistencyReportEntry_Summary{Summary: &pb.ConsistencySummary{
+			FeedbacksChecked: summary.FeedbacksChecked,
+			PrefixesChecked:  summary.PrefixesChecked,
+			Objects:          summary.Objects,
+			Bytes:            summary.Bytes,
+			MissingObjects:   summary.MissingObjects,
+			OrphanPrefixes:   summary.OrphanPrefixes,
+			Repaired:         summary.Repaired,
+		}},
+	}); err != nil {
+		s.logger.Error("gRPC ConsistencyReport: failed to send summary", "error", err)
+		return err
+	}
+
+	s.logger.Info("gRPC ConsistencyReport completed",
+		"feedbacks_checked", summary.FeedbacksChecked,
+		"missing_objects", summary.MissingObjects,
+		"orphan_prefixes", summary.OrphanPrefixes,
+	)
+	return nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetReviewerDashboard returns a page of a reviewer's dashboard with the enrichments of every row
+func (s *FeedbackServer) GetReviewerDashboard(ctx context.Context, req *pb.GetReviewerDashboardRequest) (*pb.GetReviewerDashboardResponse, error) {
+	s.logger.Info("gRPC GetReviewerDashboard received",
+		"reviewer_id", req.ReviewerId,
+		"page", req.Page,
+		"limit", req.Limit,
+	)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if req.MinAttachmentCount != nil && *req.MinAttachmentCount < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_attachment_count must not be negative")
+	}
+	if req.HasAttachments != nil && !*req.HasAttachments && req.MinAttachmentCount != nil && *req.MinAttachmentCount > 0 {
+		return nil, status.Error(codes.InvalidArgument, "has_attachments=false conflicts with min_attachment_count > 0")
+	}
+
+	filter := models.FeedbackFilter{
+		ReviewerID:      &req.ReviewerId,
+		SubmissionID:    req.SubmissionId,
+		HasAttachments:  req.HasAttachments,
+		MinAttachments:  req.MinAttachmentCount,
+		IncludeArchived: req.IncludeArchived,
+		Page:            int(req.Page),
+		Limit:           int(req.Limit),
+	}
+
+	page, err := s.feedbackService.GetReviewerDashboard(ctx, filter)
+	if err != nil {
+		s.logger.Error("gRPC GetReviewerDashboard failed", "reviewer_id", req.ReviewerId, "error", err)
+		if errors.Is(err, service.ErrAttachmentFiltersUnavailable) {
+			return nil, apperr.ToStatus(err)
+		}
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get reviewer dashboard: %v", err))
+	}
+
+	legacyCount, truncated := legacyTotalCount(page.TotalCount)
+	response := &pb.GetReviewerDashboardResponse{
+		SchemaVersion:  service.DashboardSchemaVersion,
+		Rows:           make([]*pb.ReviewerDashboardRow, len(page.Rows)),
+		TotalCount:     legacyCount,
+		Degraded:       make([]*pb.DegradedSource, len(page.Degraded)),
+		TotalCount64:   page.TotalCount,
+		CountTruncated: truncated,
+	}
+	for i, row := range page.Rows {
+		feedback := convertToProtoFeedback(row.Feedback)
+		feedback.Content = "" // The dashboard only shows the preview
+		response.Rows[i] = &pb.ReviewerDashboardRow{
+			Feedback:        feedback,
+			ContentPreview:  row.ContentPreview,
+			AttachmentCount: row.AttachmentCount,
+		}
+	}
+	for i, degraded := range page.Degraded {
+		response.Degraded[i] = &pb.DegradedSource{Source: degraded.Source, Reason: degraded.Reason}
+	}
+
+	s.logger.Info("gRPC GetReviewerDashboard completed",
+		"reviewer_id", req.ReviewerId,
+		"rows", len(response.Rows),
+		"total_count", response.TotalCount,
+		"degraded", len(response.Degraded),
+	)
+	return response, nil
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+)
+
+// GetDiagnostics reports the configuration of the serving replica that affects behavior (admin only)
+func (s *FeedbackServer) GetDiagnostics(ctx context.Context, req *pb.GetDiagnosticsRequest) (*pb.GetDiagnosticsResponse, error) {
+	s.logger.Info("gRPC GetDiagnostics received")
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	// Flags are reported as this request sees them, so overrides sent with it show up too
+	values := flags.FromContext(ctx)
+	statuses := s.flags.Statuses()
+	response := &pb.GetDiagnosticsResponse{
+		Replica:               s.jobs.Replica(),
+		FeatureFlags:          make([]*pb.FeatureFlag, len(statuses)),
+		FlagMetadataOverrides: s.flags.MetadataOverrides(),
+	}
+	for i, status := range statuses {
+		enabled := values.Enabled(flags.Flag(i))
+		source := status.Source
+		if enabled != status.Enabled {
+			source = "metadata"
+		}
+		response.FeatureFlags[i] = &pb.FeatureFlag{
+			Name:           status.Name,
+			Description:    status.Description,
+			Enabled:        enabled,
+			DefaultEnabled: status.Default,
+			Source:         source,
+		}
+	}
+
+	s.logger.Info("gRPC GetDiagnostics completed", "replica", response.Replica)
+	return response, nil
+}
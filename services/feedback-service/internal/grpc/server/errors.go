@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusWithReason builds a status error carrying a machine-readable ErrorInfo reason
+func statusWithReason(code codes.Code, msg, reason string, metadata map[string]string) error {
+	st := status.New(code, msg)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   apperr.Domain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// validationError converts an attachment metadata validation error into InvalidArgument,
+// reporting the field and its limits in the ErrorInfo metadata
+func validationError(err error) error {
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		return statusWithReason(codes.InvalidArgument, verr.Error(), verr.Reason, verr.Details())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// attachmentOrderError converts a mismatched attachment order into InvalidArgument. The
+// ErrorInfo metadata holds the missing, unknown and duplicated filenames as JSON arrays.
+func attachmentOrderError(err *service.AttachmentOrderError) error {
+	metadata := make(map[string]string)
+	for key, filenames := range map[string][]string{
+		"missing":    err.Missing,
+		"extra":      err.Extra,
+		"duplicates": err.Duplicates,
+	} {
+		if len(filenames) == 0 {
+			continue
+		}
+		encoded, _ := json.Marshal(filenames)
+		metadata[key] = string(encoded)
+	}
+	return statusWithReason(codes.InvalidArgument, err.Error(), "ATTACHMENT_ORDER_MISMATCH", metadata)
+}
+
+// storageError converts a failed attachment listing or deletion into a status. Operations aborted
+// because the caller went away map to Canceled or DeadlineExceeded rather than Internal.
+func storageError(err error, msg string) error {
+	var canceled *repository.ListingCanceledError
+	if errors.As(err, &canceled) {
+		if errors.Is(canceled.Err, context.DeadlineExceeded) {
+			return status.Error(codes.DeadlineExceeded, canceled.Error())
+		}
+		return status.Error(codes.Canceled, canceled.Error())
+	}
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return apperr.ToStatus(err)
+	}
+	return status.Error(codes.Internal, fmt.Sprintf("%s: %v", msg, err))
+}
+
+// readError converts a failed read into a status. With strict_error_codes enabled, not-found and
+// invalid-input errors keep their own code, as in storageError; otherwise every failure is
+// reported as Internal, which is what clients of the older read RPCs expect.
+func readError(ctx context.Context, err error, msg string) error {
+	if flags.FromContext(ctx).Enabled(flags.StrictErrorCodes) {
+		return storageError(err, msg)
+	}
+	return status.Error(codes.Internal, fmt.Sprintf("%s: %v", msg, err))
+}
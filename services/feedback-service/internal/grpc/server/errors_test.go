@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStorageError(t *testing.T) {
+	// Wrapped as the repository and service return them
+	wrap := func(err error) error {
+		return fmt.Errorf("failed to list attachments: %w", err)
+	}
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "listing canceled", err: wrap(&repository.ListingCanceledError{Op: "list attachments", Err: context.Canceled}), want: codes.Canceled},
+		{name: "listing past its deadline", err: wrap(&repository.ListingCanceledError{Op: "list attachments", Err: context.DeadlineExceeded}), want: codes.DeadlineExceeded},
+		{name: "stat canceled", err: wrap(fmt.Errorf("failed to get attachment metadata: %w", &repository.ListingCanceledError{Op: "stat attachment", Err: context.Canceled})), want: codes.Canceled},
+		{name: "storage failure", err: wrap(errors.New("connection refused")), want: codes.Internal},
+		// Only a listing stopped by its caller counts; other context errors are storage failures
+		{name: "bare context error", err: wrap(context.Canceled), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(storageError(tt.err, "failed to list attachments")); got != tt.want {
+				t.Errorf("storageError() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
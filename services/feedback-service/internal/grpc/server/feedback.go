@@ -2,15 +2,30 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/envelope"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/leader"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/notification"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/throttle"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -22,30 +37,307 @@ import (
 type FeedbackServer struct {
 	pb.UnimplementedFeedbackServiceServer
 	feedbackService *service.FeedbackService
+	transfers       *service.TransferAccountant
+	retention       *service.RetentionService
+	policies        *service.CoursePolicyService
+	permissions     *service.PermissionService
+	templates       *service.TemplateService
+	notifications   *notification.Renderer
+	pageTokens      *pagetoken.Codec
+	jobs            *leader.Elector
+	flags           *flags.Registry
+	downloads       config.DownloadConfig
+	limits          config.FeedbackConfig
 	logger          *slog.Logger
 }
 
+// FeedbackServerDeps holds the services and settings the feedback server handles requests with
+type FeedbackServerDeps struct {
+	FeedbackService *service.FeedbackService
+	Transfers       *service.TransferAccountant
+	Retention       *service.RetentionService
+	Policies        *service.CoursePolicyService
+	Permissions     *service.PermissionService
+	Templates       *service.TemplateService
+	Notifications   *notification.Renderer
+	PageTokens      *pagetoken.Codec
+	Jobs            *leader.Elector
+	Flags           *flags.Registry
+	Downloads       config.DownloadConfig
+	Limits          config.FeedbackConfig
+	Logger          *slog.Logger
+}
+
 // RegisterFeedbackServer registers the feedback server with gRPC
-func RegisterFeedbackServer(s *grpc.Server, feedbackService *service.FeedbackService, logger *slog.Logger) {
+func RegisterFeedbackServer(s *grpc.Server, deps FeedbackServerDeps) {
 	server := &FeedbackServer{
-		feedbackService: feedbackService,
-		logger:          logger,
+		feedbackService: deps.FeedbackService,
+		transfers:       deps.Transfers,
+		retention:       deps.Retention,
+		policies:        deps.Policies,
+		permissions:     deps.Permissions,
+		templates:       deps.Templates,
+		notifications:   deps.Notifications,
+		pageTokens:      deps.PageTokens,
+		jobs:            deps.Jobs,
+		flags:           deps.Flags,
+		downloads:       deps.Downloads,
+		limits:          deps.Limits,
+		logger:          deps.Logger,
 	}
 	pb.RegisterFeedbackServiceServer(s, server)
 }
 
+// feedbackStatuses maps model feedback statuses to their protobuf values
+var feedbackStatuses = map[string]pb.FeedbackStatus{
+	models.FeedbackDraft:     pb.FeedbackStatus_FEEDBACK_STATUS_DRAFT,
+	models.FeedbackPublished: pb.FeedbackStatus_FEEDBACK_STATUS_PUBLISHED,
+}
+
+// approvalStatuses maps model approval statuses to their protobuf values
+var approvalStatuses = map[string]pb.ApprovalStatus{
+	models.ApprovalNotRequired:      pb.ApprovalStatus_APPROVAL_STATUS_NOT_REQUIRED,
+	models.ApprovalPending:          pb.ApprovalStatus_APPROVAL_STATUS_PENDING,
+	models.ApprovalApproved:         pb.ApprovalStatus_APPROVAL_STATUS_APPROVED,
+	models.ApprovalChangesRequested: pb.ApprovalStatus_APPROVAL_STATUS_CHANGES_REQUESTED,
+}
+
+// convertFromProtoStatus converts a protobuf status filter to a model status, nil for any status
+func convertFromProtoStatus(value pb.FeedbackStatus) (*string, error) {
+	if value == pb.FeedbackStatus_FEEDBACK_STATUS_UNSPECIFIED {
+		return nil, nil
+	}
+	for status, pbStatus := range feedbackStatuses {
+		if pbStatus == value {
+			return &status, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown feedback status %d", value)
+}
+
+// feedbackSortFields maps the sort fields of list requests onto model sort fields
+var feedbackSortFields = map[pb.FeedbackSortField]string{
+	pb.FeedbackSortField_FEEDBACK_SORT_FIELD_UNSPECIFIED: models.FeedbackSortCreatedAt,
+	pb.FeedbackSortField_FEEDBACK_SORT_FIELD_CREATED_AT:  models.FeedbackSortCreatedAt,
+	pb.FeedbackSortField_FEEDBACK_SORT_FIELD_UPDATED_AT:  models.FeedbackSortUpdatedAt,
+	pb.FeedbackSortField_FEEDBACK_SORT_FIELD_TITLE:       models.FeedbackSortTitle,
+}
+
+// convertFromProtoSort converts the sort of a list request; unspecified values sort newest first
+func convertFromProtoSort(field pb.FeedbackSortField, direction pb.SortDirection) (models.FeedbackSort, error) {
+	by, ok := feedbackSortFields[field]
+	if !ok {
+		return models.FeedbackSort{}, fmt.Errorf("unknown sort_by %d", field)
+	}
+	switch direction {
+	case pb.SortDirection_SORT_DIRECTION_UNSPECIFIED, pb.SortDirection_SORT_DIRECTION_DESC:
+		return models.FeedbackSort{By: by}, nil
+	case pb.SortDirection_SORT_DIRECTION_ASC:
+		return models.FeedbackSort{By: by, Ascending: true}, nil
+	default:
+		return models.FeedbackSort{}, fmt.Errorf("unknown sort_direction %d", direction)
+	}
+}
+
+// feedbackReactions maps the reactions of ReactToFeedback requests onto model reactions
+var feedbackReactions = map[pb.FeedbackReaction]string{
+	pb.FeedbackReaction_FEEDBACK_REACTION_HELPFUL:     models.ReactionHelpful,
+	pb.FeedbackReaction_FEEDBACK_REACTION_NOT_HELPFUL: models.ReactionNotHelpful,
+}
+
 // Helper function to convert model Feedback to protobuf Feedback
 func convertToProtoFeedback(feedback *models.Feedback) *pb.Feedback {
+	var publishedAt, readAt, resubmissionDeadline *timestamppb.Timestamp
+	if feedback.PublishedAt != nil {
+		publishedAt = timestamppb.New(*feedback.PublishedAt)
+	}
+	if feedback.ReadAt != nil {
+		readAt = timestamppb.New(*feedback.ReadAt)
+	}
+	if feedback.ResubmissionDeadline != nil {
+		resubmissionDeadline = timestamppb.New(*feedback.ResubmissionDeadline)
+	}
+	var archivedAt *timestamppb.Timestamp
+	if feedback.ArchivedAt != nil {
+		archivedAt = timestamppb.New(*feedback.ArchivedAt)
+	}
+	rubricScore, rubricMaxScore := feedback.RubricTotal()
 	return &pb.Feedback{
-		Id:           feedback.ID.String(),
-		ReviewerId:   feedback.ReviewerID,
-		StudentId:    feedback.StudentID,
-		SubmissionId: feedback.SubmissionID,
-		Title:        feedback.Title,
-		Content:      feedback.Content,
-		CreatedAt:    timestamppb.New(feedback.CreatedAt),
-		UpdatedAt:    timestamppb.New(feedback.UpdatedAt),
+		Id:                 feedback.ID.String(),
+		Name:               resourcename.Feedback(feedback.ID),
+		ReviewerId:         feedback.ReviewerID,
+		StudentId:          feedback.StudentID,
+		SubmissionId:       feedback.SubmissionID,
+		Title:              feedback.Title,
+		Content:            feedback.Content,
+		CreatedAt:          timestamppb.New(feedback.CreatedAt),
+		UpdatedAt:          timestamppb.New(feedback.UpdatedAt),
+		Locked:             feedback.Locked,
+		LockReason:         feedback.LockReason,
+		NeedsReupload:      feedback.NeedsReupload,
+		SubmissionSnapshot: convertToProtoSnapshot(feedback.Snapshot),
+		CourseId:           feedback.CourseID,
+		Grade:              convertToProtoGrade(feedback.Grade),
+		Status:             feedbackStatuses[feedback.Status],
+		PublishedAt:        publishedAt,
+		ApprovalStatus:     approvalStatuses[feedback.ApprovalStatus],
+		ApproverId:         feedback.ApproverID,
+		ApprovalNote:       feedback.ApprovalNote,
+		ReadAt:             readAt,
+		RevisionCount:      feedback.RevisionCount,
+		RubricItems:        convertToProtoRubric(feedback.RubricItems),
+		RubricScore:        rubricScore,
+		RubricMaxScore:     rubricMaxScore,
+
+		RequiresResubmission: feedback.RequiresResubmission,
+		ResubmissionDeadline: resubmissionDeadline,
+
+		HelpfulCount:    feedback.HelpfulCount,
+		NotHelpfulCount: feedback.NotHelpfulCount,
+
+		Archived:   feedback.ArchivedAt != nil,
+		ArchivedAt: archivedAt,
+
+		IsUnread: feedback.IsUnread(),
+	}
+}
+
+// convertFromProtoDeadline converts an optional protobuf resubmission deadline
+func convertFromProtoDeadline(deadline *timestamppb.Timestamp) (*time.Time, error) {
+	if deadline == nil {
+		return nil, nil
+	}
+	if err := deadline.CheckValid(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid resubmission_deadline")
+	}
+	t := deadline.AsTime()
+	return &t, nil
+}
+
+// convertToProtoSimilarFeedbacks converts model SimilarFeedback entries to protobuf
+func convertToProtoSimilarFeedbacks(matches []*models.SimilarFeedback) []*pb.SimilarFeedback {
+	result := make([]*pb.SimilarFeedback, len(matches))
+	for i, match := range matches {
+		result[i] = &pb.SimilarFeedback{
+			Id:           match.ID.String(),
+			Name:         resourcename.Feedback(match.ID),
+			StudentId:    match.StudentID,
+			SubmissionId: match.SubmissionID,
+			Title:        match.Title,
+			Distance:     int32(match.Distance),
+			CreatedAt:    timestamppb.New(match.CreatedAt),
+		}
+	}
+	return result
+}
+
+// similarFeedbackError reports the matches that blocked a strict CreateFeedback
+func similarFeedbackError(matches []*models.SimilarFeedback) error {
+	ids := make([]string, len(matches))
+	for i, match := range matches {
+		ids[i] = match.ID.String()
+	}
+	return statusWithReason(codes.FailedPrecondition,
+		fmt.Sprintf("%d feedbacks with similar titles already exist", len(matches)),
+		"SIMILAR_FEEDBACK_EXISTS",
+		map[string]string{
+			"similar_feedback_ids": strings.Join(ids, ","),
+			"count":                strconv.Itoa(len(matches)),
+		},
+	)
+}
+
+// convertToProtoAttachmentInfo converts model AttachmentInfo to protobuf AttachmentInfo
+func convertToProtoAttachmentInfo(feedbackID uuid.UUID, info *models.AttachmentInfo) *pb.AttachmentInfo {
+	return &pb.AttachmentInfo{
+		Name:        resourcename.Attachment(feedbackID, info.Filename),
+		Filename:    info.Filename,
+		Size:        info.Size,
+		StoredSize:  info.StoredSize,
+		ContentType: info.ContentType,
+		UploadedAt:  timestamppb.New(info.UploadedAt),
+	}
+}
+
+// convertToProtoDatasets converts per-dataset deletion counts to protobuf
+func convertToProtoDatasets(datasets []models.DatasetDeletion) []*pb.DeletedDataset {
+	pbDatasets := make([]*pb.DeletedDataset, len(datasets))
+	for i, dataset := range datasets {
+		pbDatasets[i] = &pb.DeletedDataset{
+			Dataset: dataset.Dataset,
+			Deleted: dataset.Deleted,
+		}
+		if dataset.Err != nil {
+			pbDatasets[i].Error = dataset.Err.Error()
+		}
+	}
+	return pbDatasets
+}
+
+// convertToProtoSnapshot converts model SubmissionSnapshot to protobuf SubmissionSnapshot
+func convertToProtoSnapshot(snapshot *models.SubmissionSnapshot) *pb.SubmissionSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+	return &pb.SubmissionSnapshot{
+		LabTitle:            snapshot.LabTitle,
+		StudentDisplayName:  snapshot.StudentDisplayName,
+		SubmissionLabel:     snapshot.SubmissionLabel,
+		ReviewerDisplayName: snapshot.ReviewerDisplayName,
+	}
+}
+
+// convertFromProtoSnapshot converts protobuf SubmissionSnapshot to model SubmissionSnapshot
+func convertFromProtoSnapshot(snapshot *pb.SubmissionSnapshot) *models.SubmissionSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+	return &models.SubmissionSnapshot{
+		LabTitle:            snapshot.LabTitle,
+		StudentDisplayName:  snapshot.StudentDisplayName,
+		SubmissionLabel:     snapshot.SubmissionLabel,
+		ReviewerDisplayName: snapshot.ReviewerDisplayName,
+	}
+}
+
+// convertToProtoGrade converts model Grade to protobuf Grade
+func convertToProtoGrade(grade *models.Grade) *pb.Grade {
+	if grade == nil {
+		return nil
+	}
+	return &pb.Grade{Points: grade.Points, MaxPoints: grade.MaxPoints}
+}
+
+// convertFromProtoGrade converts protobuf Grade to model Grade
+func convertFromProtoGrade(grade *pb.Grade) *models.Grade {
+	if grade == nil {
+		return nil
+	}
+	return &models.Grade{Points: grade.Points, MaxPoints: grade.MaxPoints}
+}
+
+// convertToProtoRubric converts model RubricItems to protobuf RubricItems
+func convertToProtoRubric(items []models.RubricItem) []*pb.RubricItem {
+	if len(items) == 0 {
+		return nil
 	}
+	result := make([]*pb.RubricItem, len(items))
+	for i, item := range items {
+		result[i] = &pb.RubricItem{Name: item.Name, Score: item.Score, MaxScore: item.MaxScore, Comment: item.Comment}
+	}
+	return result
+}
+
+// convertFromProtoRubric converts protobuf RubricItems to model RubricItems
+func convertFromProtoRubric(items []*pb.RubricItem) []models.RubricItem {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]models.RubricItem, len(items))
+	for i, item := range items {
+		result[i] = models.RubricItem{Name: item.Name, Score: item.Score, MaxScore: item.MaxScore, Comment: item.Comment}
+	}
+	return result
 }
 
 // Reviewer Operations
@@ -68,18 +360,84 @@ func (s *FeedbackServer) CreateFeedback(ctx context.Context, req *pb.CreateFeedb
 	if req.SubmissionId <= 0 {
 		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
 	}
-	if req.Title == "" {
+	if err := service.CheckTextLimits(s.limits, &req.Title, &req.Content); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+
+	// Fill what the request leaves empty from the reviewer's template
+	title, content := req.Title, req.Content
+	if req.TemplateId != "" {
+		templateID, err := uuid.Parse(req.TemplateId)
+		if err != nil {
+			s.logger.Warn("gRPC CreateFeedback: invalid template ID format", "template_id", req.TemplateId, "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid template ID format")
+		}
+		title, content, err = s.templates.Prefill(ctx, req.ReviewerId, templateID, title, content)
+		if err != nil {
+			s.logger.Warn("gRPC CreateFeedback: template prefill failed", "template_id", req.TemplateId, "error", err)
+			return nil, storageError(err, "failed to get template")
+		}
+	}
+	if title == "" {
 		return nil, status.Error(codes.InvalidArgument, "title is required")
 	}
+	if err := service.ValidateCourseID(req.CourseId); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Look for feedbacks the new one could be confused with
+	var similar []*models.SimilarFeedback
+	if req.WarnOnSimilar {
+		var err error
+		similar, err = s.feedbackService.FindSimilarFeedbacks(ctx, req.ReviewerId, req.SubmissionId, title)
+		if err != nil {
+			s.logger.Error("gRPC CreateFeedback: similarity check failed", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to check for similar feedbacks: %v", err))
+		}
+		// A retry must not be flagged as similar to the feedback its first attempt created
+		created, err := s.feedbackService.IdempotentFeedbackID(ctx, req.ReviewerId, req.IdempotencyKey)
+		if err != nil {
+			s.logger.Error("gRPC CreateFeedback: idempotency key lookup failed", "error", err)
+			return nil, storageError(err, "failed to look up idempotency key")
+		}
+		similar = slices.DeleteFunc(similar, func(f *models.SimilarFeedback) bool { return f.ID == created })
+		if req.Strict && len(similar) > 0 {
+			s.logger.Info("gRPC CreateFeedback: similar feedbacks exist", "reviewer_id", req.ReviewerId, "count", len(similar))
+			return nil, similarFeedbackError(similar)
+		}
+	}
+
+	deadline, err := convertFromProtoDeadline(req.ResubmissionDeadline)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create feedback
-	feedback, err := s.feedbackService.CreateFeedback(ctx, req.ReviewerId, req.StudentId, req.SubmissionId, req.Title, req.Content)
+	feedback, err := s.feedbackService.CreateFeedback(ctx, models.FeedbackCreateRequest{
+		ReviewerID:           req.ReviewerId,
+		StudentID:            req.StudentId,
+		SubmissionID:         req.SubmissionId,
+		Title:                title,
+		Content:              content,
+		Snapshot:             convertFromProtoSnapshot(req.SubmissionSnapshot),
+		CourseID:             req.CourseId,
+		Grade:                convertFromProtoGrade(req.Grade),
+		RubricItems:          convertFromProtoRubric(req.RubricItems),
+		RequiresResubmission: req.RequiresResubmission,
+		ResubmissionDeadline: deadline,
+		Publish:              req.Publish,
+		IdempotencyKey:       req.IdempotencyKey,
+	})
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidSnapshot) || errors.Is(err, service.ErrInvalidCourseID) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 		s.logger.Error("gRPC CreateFeedback failed", "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to create feedback: %v", err))
+		return nil, storageError(err, "failed to create feedback")
 	}
 
 	response := convertToProtoFeedback(feedback)
+	response.SimilarFeedbacks = convertToProtoSimilarFeedbacks(similar)
 	s.logger.Info("gRPC CreateFeedback completed", "id", response.Id)
 	return response, nil
 }
@@ -99,7 +457,7 @@ func (s *FeedbackServer) UpdateFeedback(ctx context.Context, req *pb.UpdateFeedb
 		return nil, status.Error(codes.InvalidArgument, "feedback ID is required")
 	}
 
-	id, err := uuid.Parse(req.Id)
+	id, err := resourcename.ParseFeedbackID(req.Id)
 	if err != nil {
 		s.logger.Warn("gRPC UpdateFeedback: invalid ID format", "id", req.Id, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
@@ -112,11 +470,31 @@ func (s *FeedbackServer) UpdateFeedback(ctx context.Context, req *pb.UpdateFeedb
 	if req.Content != nil {
 		content = req.Content
 	}
-
-	feedback, err := s.feedbackService.UpdateFeedback(ctx, id, req.ReviewerId, title, content)
+	if err := service.CheckTextLimits(s.limits, title, content); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+	deadline, err := convertFromProtoDeadline(req.ResubmissionDeadline)
 	if err != nil {
-		s.logger.Error("gRPC UpdateFeedback failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to update feedback: %v", err))
+		return nil, err
+	}
+
+	feedback, err := s.feedbackService.UpdateFeedback(ctx, models.FeedbackUpdateRequest{
+		ID:                        id,
+		ReviewerID:                req.ReviewerId,
+		Title:                     title,
+		Content:                   content,
+		Grade:                     convertFromProtoGrade(req.Grade),
+		ClearGrade:                req.ClearGrade,
+		RubricItems:               convertFromProtoRubric(req.RubricItems),
+		ClearRubricItems:          req.ClearRubricItems,
+		RequiresResubmission:      req.RequiresResubmission,
+		ResubmissionDeadline:      deadline,
+		ClearResubmissionDeadline: req.ClearResubmissionDeadline,
+		Mask:                      req.UpdateMask.GetPaths(),
+	})
+	if err != nil {
+		s.logger.Warn("gRPC UpdateFeedback failed", "id", req.Id, "error", err)
+		return nil, apperr.ToStatus(err)
 	}
 
 	response := convertToProtoFeedback(feedback)
@@ -139,45 +517,627 @@ func (s *FeedbackServer) DeleteFeedback(ctx context.Context, req *pb.DeleteFeedb
 		return nil, status.Error(codes.InvalidArgument, "feedback ID is required")
 	}
 
-	id, err := uuid.Parse(req.Id)
+	id, err := resourcename.ParseFeedbackID(req.Id)
 	if err != nil {
 		s.logger.Warn("gRPC DeleteFeedback: invalid ID format", "id", req.Id, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
 	}
 
-	err = s.feedbackService.DeleteFeedback(ctx, id, req.ReviewerId)
+	datasets, err := s.feedbackService.DeleteFeedback(ctx, id, req.ReviewerId)
 	if err != nil {
 		s.logger.Error("gRPC DeleteFeedback failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete feedback: %v", err))
+		return nil, storageError(err, "failed to delete feedback")
 	}
 
-	response := &pb.DeleteFeedbackResponse{Success: true}
+	response := &pb.DeleteFeedbackResponse{
+		Success:  true,
+		Datasets: convertToProtoDatasets(datasets),
+		Pending:  models.DeletionPending(datasets),
+	}
 	s.logger.Info("gRPC DeleteFeedback completed", "id", req.Id)
 	return response, nil
 }
 
+// DeleteFeedbacksBySubmission deletes all feedbacks of a submission (admin only)
+func (s *FeedbackServer) DeleteFeedbacksBySubmission(ctx context.Context, req *pb.DeleteFeedbacksBySubmissionRequest) (*pb.DeleteFeedbacksBySubmissionResponse, error) {
+	s.logger.Info("gRPC DeleteFeedbacksBySubmission received",
+		"submission_id", req.SubmissionId,
+		"actor_id", req.ActorId,
+		"purge_attachments", req.PurgeAttachments,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC DeleteFeedbacksBySubmission: permission denied", "actor_id", req.ActorId)
+		return nil, err
+	}
+	if req.SubmissionId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+
+	results, err := s.feedbackService.DeleteFeedbacksBySubmission(ctx, req.SubmissionId, req.ActorId, req.PurgeAttachments)
+	if err != nil && len(results) == 0 {
+		s.logger.Error("gRPC DeleteFeedbacksBySubmission failed", "submission_id", req.SubmissionId, "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete submission feedbacks: %v", err))
+	}
+
+	response := &pb.DeleteFeedbacksBySubmissionResponse{
+		Results:   make([]*pb.FeedbackDeletionResult, len(results)),
+		Completed: err == nil,
+	}
+	for i, result := range results {
+		pbResult := &pb.FeedbackDeletionResult{
+			FeedbackId: result.FeedbackID.String(),
+			Deleted:    result.Deleted,
+			Datasets:   convertToProtoDatasets(result.Datasets),
+			Pending:    models.DeletionPending(result.Datasets),
+		}
+		if result.Err != nil {
+			pbResult.Error = result.Err.Error()
+			response.FailedCount++
+		} else {
+			response.DeletedCount++
+		}
+		response.Results[i] = pbResult
+	}
+
+	s.logger.Info("gRPC DeleteFeedbacksBySubmission completed",
+		"submission_id", req.SubmissionId,
+		"deleted", response.DeletedCount,
+		"failed", response.FailedCount,
+		"completed", response.Completed,
+	)
+	return response, nil
+}
+
+// UpdateFeedbackSnapshot refreshes the submission snapshots of feedbacks in bulk (internal services only)
+func (s *FeedbackServer) UpdateFeedbackSnapshot(ctx context.Context, req *pb.UpdateFeedbackSnapshotRequest) (*pb.UpdateFeedbackSnapshotResponse, error) {
+	s.logger.Info("gRPC UpdateFeedbackSnapshot received", "submissions", len(req.Updates))
+
+	if err := middleware.RequireInternalCaller(ctx); err != nil {
+		s.logger.Warn("gRPC UpdateFeedbackSnapshot: permission denied")
+		return nil, err
+	}
+
+	updates := make([]models.SubmissionSnapshotUpdate, len(req.Updates))
+	for i, update := range req.Updates {
+		updates[i].SubmissionID = update.SubmissionId
+		if snapshot := convertFromProtoSnapshot(update.Snapshot); snapshot != nil {
+			updates[i].Snapshot = *snapshot
+		}
+	}
+
+	updated, err := s.feedbackService.UpdateSubmissionSnapshots(ctx, updates)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSnapshot) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		s.logger.Error("gRPC UpdateFeedbackSnapshot failed", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to update feedback snapshots: %v", err))
+	}
+
+	s.logger.Info("gRPC UpdateFeedbackSnapshot completed", "updated", updated)
+	return &pb.UpdateFeedbackSnapshotResponse{UpdatedCount: updated}, nil
+}
+
+// SetFeedbackLock locks or unlocks a feedback, e.g. while a grade appeal is open (admin only)
+func (s *FeedbackServer) SetFeedbackLock(ctx context.Context, req *pb.SetFeedbackLockRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC SetFeedbackLock received",
+		"feedback_id", req.FeedbackId,
+		"locked", req.Locked,
+		"actor_id", req.ActorId,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC SetFeedbackLock: permission denied", "actor_id", req.ActorId)
+		return nil, err
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+	if req.Locked && req.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "reason is required when locking")
+	}
+
+	id, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC SetFeedbackLock: invalid ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.SetFeedbackLock(ctx, id, req.Locked, req.Reason, authz.FromContext(ctx, req.ActorId))
+	if err != nil {
+		s.logger.Warn("gRPC SetFeedbackLock failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC SetFeedbackLock completed", "feedback_id", response.Id, "locked", response.Locked)
+	return response, nil
+}
+
+// TransferFeedbackOwnership hands a feedback over to another reviewer (author or admin)
+func (s *FeedbackServer) TransferFeedbackOwnership(ctx context.Context, req *pb.TransferFeedbackOwnershipRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC TransferFeedbackOwnership received",
+		"id", req.Id,
+		"current_reviewer_id", req.CurrentReviewerId,
+		"new_reviewer_id", req.NewReviewerId,
+		"actor_id", req.ActorId,
+	)
+
+	actorID := req.ActorId
+	if actorID == 0 {
+		actorID = req.CurrentReviewerId
+	}
+	if actorID <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "current_reviewer_id or actor_id is required")
+	}
+	if req.NewReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "new_reviewer_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC TransferFeedbackOwnership: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.TransferFeedbackOwnership(ctx, id, req.CurrentReviewerId, req.NewReviewerId, authz.FromContext(ctx, actorID))
+	if err != nil {
+		s.logger.Warn("gRPC TransferFeedbackOwnership failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to transfer feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC TransferFeedbackOwnership completed", "id", response.Id, "reviewer_id", response.ReviewerId)
+	return response, nil
+}
+
+// ArchiveFeedback archives a feedback or restores it (author or admin)
+func (s *FeedbackServer) ArchiveFeedback(ctx context.Context, req *pb.ArchiveFeedbackRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC ArchiveFeedback received", "id", req.Id, "actor_id", req.ActorId, "restore", req.Restore)
+
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC ArchiveFeedback: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.SetFeedbackArchived(ctx, id, !req.Restore, authz.FromContext(ctx, req.ActorId))
+	if err != nil {
+		s.logger.Warn("gRPC ArchiveFeedback failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to archive feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC ArchiveFeedback completed", "id", response.Id, "archived", response.Archived)
+	return response, nil
+}
+
+// PublishFeedback makes a draft feedback visible to its student (author only)
+func (s *FeedbackServer) PublishFeedback(ctx context.Context, req *pb.PublishFeedbackRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC PublishFeedback received", "id", req.Id, "reviewer_id", req.ReviewerId)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC PublishFeedback: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.PublishFeedback(ctx, id, req.ReviewerId)
+	if err != nil {
+		s.logger.Warn("gRPC PublishFeedback failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to publish feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC PublishFeedback completed", "id", response.Id, "published_at", feedback.PublishedAt)
+	return response, nil
+}
+
+// AcknowledgeFeedback marks a feedback as read by its student
+func (s *FeedbackServer) AcknowledgeFeedback(ctx context.Context, req *pb.AcknowledgeFeedbackRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC AcknowledgeFeedback received", "id", req.Id, "student_id", req.StudentId)
+
+	if req.StudentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "student_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC AcknowledgeFeedback: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.AcknowledgeFeedback(ctx, id, req.StudentId)
+	if err != nil {
+		s.logger.Warn("gRPC AcknowledgeFeedback failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to acknowledge feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC AcknowledgeFeedback completed", "id", response.Id, "read_at", feedback.ReadAt)
+	return response, nil
+}
+
+// ReactToFeedback rates a feedback as helpful or not (the feedback's student only)
+func (s *FeedbackServer) ReactToFeedback(ctx context.Context, req *pb.ReactToFeedbackRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC ReactToFeedback received", "id", req.Id, "student_id", req.StudentId, "reaction", req.Reaction)
+
+	if req.StudentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "student_id is required")
+	}
+	reaction, ok := feedbackReactions[req.Reaction]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "reaction must be HELPFUL or NOT_HELPFUL")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC ReactToFeedback: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.ReactToFeedback(ctx, id, req.StudentId, reaction)
+	if err != nil {
+		s.logger.Warn("gRPC ReactToFeedback failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to react to feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC ReactToFeedback completed", "id", response.Id, "helpful_count", response.HelpfulCount, "not_helpful_count", response.NotHelpfulCount)
+	return response, nil
+}
+
+// RemoveReaction removes the reaction of the feedback's student
+func (s *FeedbackServer) RemoveReaction(ctx context.Context, req *pb.RemoveReactionRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC RemoveReaction received", "id", req.Id, "student_id", req.StudentId)
+
+	if req.StudentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "student_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC RemoveReaction: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.RemoveFeedbackReaction(ctx, id, req.StudentId)
+	if err != nil {
+		s.logger.Warn("gRPC RemoveReaction failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to remove reaction")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC RemoveReaction completed", "id", response.Id, "helpful_count", response.HelpfulCount, "not_helpful_count", response.NotHelpfulCount)
+	return response, nil
+}
+
+// ListFeedbackRevisions lists the edit history of a feedback (its reviewer and student only)
+func (s *FeedbackServer) ListFeedbackRevisions(ctx context.Context, req *pb.ListFeedbackRevisionsRequest) (*pb.ListFeedbackRevisionsResponse, error) {
+	s.logger.Info("gRPC ListFeedbackRevisions received", "id", req.Id, "user_id", req.UserId, "page", req.Page, "limit", req.Limit)
+
+	if req.UserId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC ListFeedbackRevisions: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	revisions, totalCount, err := s.feedbackService.ListFeedbackRevisions(ctx, id, req.UserId, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Warn("gRPC ListFeedbackRevisions failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to list feedback revisions")
+	}
+
+	response := &pb.ListFeedbackRevisionsResponse{
+		Revisions:  make([]*pb.FeedbackRevision, len(revisions)),
+		TotalCount: totalCount,
+	}
+	for i, revision := range revisions {
+		response.Revisions[i] = &pb.FeedbackRevision{
+			Revision: revision.Revision,
+			Title:    revision.Title,
+			Content:  revision.Content,
+			EditedAt: timestamppb.New(revision.EditedAt),
+			EditedBy: revision.EditedBy,
+		}
+	}
+
+	s.logger.Info("gRPC ListFeedbackRevisions completed", "id", req.Id, "count", len(revisions), "total_count", totalCount)
+	return response, nil
+}
+
+// RequestFeedbackApproval asks a second reviewer to approve a feedback (author only)
+func (s *FeedbackServer) RequestFeedbackApproval(ctx context.Context, req *pb.RequestFeedbackApprovalRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC RequestFeedbackApproval received", "id", req.Id, "reviewer_id", req.ReviewerId, "approver_id", req.ApproverId)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if req.ApproverId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "approver_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC RequestFeedbackApproval: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.RequestFeedbackApproval(ctx, id, req.ReviewerId, req.ApproverId)
+	if err != nil {
+		s.logger.Warn("gRPC RequestFeedbackApproval failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to request feedback approval")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC RequestFeedbackApproval completed", "id", response.Id, "approval_status", feedback.ApprovalStatus)
+	return response, nil
+}
+
+// ApproveFeedback approves a feedback awaiting approval (requested approver only)
+func (s *FeedbackServer) ApproveFeedback(ctx context.Context, req *pb.ApproveFeedbackRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC ApproveFeedback received", "id", req.Id, "approver_id", req.ApproverId)
+
+	if req.ApproverId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "approver_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC ApproveFeedback: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.ApproveFeedback(ctx, id, req.ApproverId, req.Note)
+	if err != nil {
+		s.logger.Warn("gRPC ApproveFeedback failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to approve feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC ApproveFeedback completed", "id", response.Id)
+	return response, nil
+}
+
+// RequestFeedbackChanges sends a feedback awaiting approval back to its author (requested approver only)
+func (s *FeedbackServer) RequestFeedbackChanges(ctx context.Context, req *pb.RequestFeedbackChangesRequest) (*pb.Feedback, error) {
+	s.logger.Info("gRPC RequestFeedbackChanges received", "id", req.Id, "approver_id", req.ApproverId)
+
+	if req.ApproverId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "approver_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC RequestFeedbackChanges: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.RequestFeedbackChanges(ctx, id, req.ApproverId, req.Note)
+	if err != nil {
+		s.logger.Warn("gRPC RequestFeedbackChanges failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to request feedback changes")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	s.logger.Info("gRPC RequestFeedbackChanges completed", "id", response.Id)
+	return response, nil
+}
+
+// createdRange converts the optional creation range of a list request. The range is
+// [after, before); after must not be later than before.
+func createdRange(after, before *timestamppb.Timestamp) (*time.Time, *time.Time, error) {
+	var createdAfter, createdBefore *time.Time
+	if after != nil {
+		if err := after.CheckValid(); err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, "invalid created_after: "+err.Error())
+		}
+		t := after.AsTime()
+		createdAfter = &t
+	}
+	if before != nil {
+		if err := before.CheckValid(); err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, "invalid created_before: "+err.Error())
+		}
+		t := before.AsTime()
+		createdBefore = &t
+	}
+	if createdAfter != nil && createdBefore != nil && createdAfter.After(*createdBefore) {
+		return nil, nil, status.Error(codes.InvalidArgument, "created_after must not be after created_before")
+	}
+	return createdAfter, createdBefore, nil
+}
+
+// ListSubmissionFeedbacks lists the feedbacks of every reviewer for a submission (internal
+// services and admins only)
+func (s *FeedbackServer) ListSubmissionFeedbacks(ctx context.Context, req *pb.ListSubmissionFeedbacksRequest) (*pb.ListSubmissionFeedbacksResponse, error) {
+	s.logger.Info("gRPC ListSubmissionFeedbacks received",
+		"submission_id", req.SubmissionId,
+		"status", req.Status,
+		"include_archived", req.IncludeArchived,
+		"page", req.Page,
+		"limit", req.Limit,
+	)
+
+	if !middleware.IsInternalCaller(ctx) && !middleware.IsAdmin(ctx) {
+		s.logger.Warn("gRPC ListSubmissionFeedbacks: permission denied", "submission_id", req.SubmissionId)
+		return nil, status.Error(codes.PermissionDenied, "internal caller or administrator role required")
+	}
+	if req.SubmissionId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
+	}
+	feedbackStatus, err := convertFromProtoStatus(req.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	feedbacks, totalCount, err := s.feedbackService.ListSubmissionFeedbacks(ctx, req.SubmissionId, feedbackStatus, req.IncludeArchived, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Error("gRPC ListSubmissionFeedbacks failed", "submission_id", req.SubmissionId, "error", err)
+		return nil, readError(ctx, err, "failed to list submission feedbacks")
+	}
+
+	pbFeedbacks := make([]*pb.Feedback, len(feedbacks))
+	for i, feedback := range feedbacks {
+		pbFeedbacks[i] = convertToProtoFeedback(feedback)
+	}
+
+	s.logger.Info("gRPC ListSubmissionFeedbacks completed", "submission_id", req.SubmissionId, "count", len(feedbacks), "total_count", totalCount)
+	legacyCount, truncated := legacyTotalCount(totalCount)
+	return &pb.ListSubmissionFeedbacksResponse{
+		Feedbacks:      pbFeedbacks,
+		TotalCount:     legacyCount,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}, nil
+}
+
+// ListFeedbacks lists feedbacks across reviewers and students for admins
+func (s *FeedbackServer) ListFeedbacks(ctx context.Context, req *pb.ListFeedbacksRequest) (*pb.ListFeedbacksResponse, error) {
+	s.logger.Info("gRPC ListFeedbacks received",
+		"reviewer_id", req.ReviewerId,
+		"student_id", req.StudentId,
+		"submission_id", req.SubmissionId,
+		"status", req.Status,
+		"has_attachments", req.HasAttachments,
+		"min_attachment_count", req.MinAttachmentCount,
+		"page", req.Page,
+		"limit", req.Limit,
+	)
+
+	if !middleware.IsAdmin(ctx) {
+		s.logger.Warn("gRPC ListFeedbacks: permission denied")
+		return nil, status.Error(codes.PermissionDenied, "administrator role required")
+	}
+	if req.MinAttachmentCount != nil && *req.MinAttachmentCount < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_attachment_count must not be negative")
+	}
+	if req.HasAttachments != nil && !*req.HasAttachments && req.MinAttachmentCount != nil && *req.MinAttachmentCount > 0 {
+		return nil, status.Error(codes.InvalidArgument, "has_attachments=false conflicts with min_attachment_count > 0")
+	}
+	feedbackStatus, err := convertFromProtoStatus(req.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	createdAfter, createdBefore, err := createdRange(req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	feedbacks, totalCount, err := s.feedbackService.ListFeedbacks(ctx, models.FeedbackFilter{
+		ReviewerID:      req.ReviewerId,
+		StudentID:       req.StudentId,
+		SubmissionID:    req.SubmissionId,
+		HasAttachments:  req.HasAttachments,
+		MinAttachments:  req.MinAttachmentCount,
+		Status:          feedbackStatus,
+		IncludeArchived: req.IncludeArchived,
+		CreatedAfter:    createdAfter,
+		CreatedBefore:   createdBefore,
+		Page:            int(req.Page),
+		Limit:           int(req.Limit),
+	})
+	if err != nil {
+		s.logger.Error("gRPC ListFeedbacks failed", "error", err)
+		if errors.Is(err, service.ErrAttachmentFiltersUnavailable) {
+			return nil, apperr.ToStatus(err)
+		}
+		return nil, readError(ctx, err, "failed to list feedbacks")
+	}
+
+	pbFeedbacks := make([]*pb.Feedback, len(feedbacks))
+	for i, feedback := range feedbacks {
+		pbFeedbacks[i] = convertToProtoFeedback(feedback)
+	}
+
+	s.logger.Info("gRPC ListFeedbacks completed", "count", len(feedbacks), "total_count", totalCount)
+	return &pb.ListFeedbacksResponse{
+		Feedbacks:  pbFeedbacks,
+		TotalCount: totalCount,
+	}, nil
+}
+
 // ListReviewerFeedbacks lists feedbacks created by a reviewer
 func (s *FeedbackServer) ListReviewerFeedbacks(ctx context.Context, req *pb.ListReviewerFeedbacksRequest) (*pb.ListReviewerFeedbacksResponse, error) {
 	s.logger.Info("gRPC ListReviewerFeedbacks received",
 		"reviewer_id", req.ReviewerId,
 		"submission_id", req.SubmissionId,
+		"status", req.Status,
+		"sort_by", req.SortBy,
+		"sort_direction", req.SortDirection,
 		"page", req.Page,
+		"page_token", req.PageToken != "",
 		"limit", req.Limit,
 	)
 
 	if req.ReviewerId <= 0 {
 		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
 	}
+	if req.MinAttachmentCount != nil && *req.MinAttachmentCount < 0 {
+		return nil, status.Error(codes.InvalidArgument, "min_attachment_count must not be negative")
+	}
+	if req.HasAttachments != nil && !*req.HasAttachments && req.MinAttachmentCount != nil && *req.MinAttachmentCount > 0 {
+		return nil, status.Error(codes.InvalidArgument, "has_attachments=false conflicts with min_attachment_count > 0")
+	}
+	feedbackStatus, err := convertFromProtoStatus(req.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	createdAfter, createdBefore, err := createdRange(req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := convertFromProtoSort(req.SortBy, req.SortDirection)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+	after, err := s.decodeFeedbackPageToken(req.PageToken, req.Page, sort)
+	if err != nil {
+		return nil, err
+	}
 
-	var submissionID *int64
-	if req.SubmissionId != nil {
-		submissionID = req.SubmissionId
+	filter := models.FeedbackFilter{
+		ReviewerID:      &req.ReviewerId,
+		SubmissionID:    req.SubmissionId,
+		HasAttachments:  req.HasAttachments,
+		MinAttachments:  req.MinAttachmentCount,
+		Status:          feedbackStatus,
+		IncludeArchived: req.IncludeArchived,
+		CreatedAfter:    createdAfter,
+		CreatedBefore:   createdBefore,
+		Sort:            sort,
+		After:           after,
+		Page:            int(req.Page),
+		Limit:           int(req.Limit),
 	}
 
-	feedbacks, totalCount, err := s.feedbackService.ListReviewerFeedbacks(ctx, req.ReviewerId, submissionID, req.Page, req.Limit)
+	feedbacks, totalCount, next, err := s.feedbackService.ListReviewerFeedbacks(ctx, filter, req.PrefetchNextPage)
 	if err != nil {
 		s.logger.Error("gRPC ListReviewerFeedbacks failed", "reviewer_id", req.ReviewerId, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list reviewer feedbacks: %v", err))
+		if errors.Is(err, service.ErrAttachmentFiltersUnavailable) {
+			return nil, apperr.ToStatus(err)
+		}
+		return nil, readError(ctx, err, "failed to list reviewer feedbacks")
+	}
+	nextPageToken, err := s.encodeFeedbackPageToken(next)
+	if err != nil {
+		s.logger.Error("gRPC ListReviewerFeedbacks failed to encode page token", "reviewer_id", req.ReviewerId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to encode page token")
 	}
 
 	pbFeedbacks := make([]*pb.Feedback, len(feedbacks))
@@ -185,26 +1145,47 @@ func (s *FeedbackServer) ListReviewerFeedbacks(ctx context.Context, req *pb.List
 		pbFeedbacks[i] = convertToProtoFeedback(feedback)
 	}
 
+	legacyCount, truncated := legacyTotalCount(totalCount)
 	response := &pb.ListReviewerFeedbacksResponse{
-		Feedbacks:  pbFeedbacks,
-		TotalCount: totalCount,
+		Feedbacks:      pbFeedbacks,
+		TotalCount:     legacyCount,
+		NextPageToken:  nextPageToken,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}
+
+	s.logger.Info("gRPC ListReviewerFeedbacks completed",
+		"reviewer_id", req.ReviewerId,
+		"count", len(feedbacks),
+		"total_count", totalCount,
+	)
+	return response, nil
+}
+
+// PrefetchReviewerDashboard warms the first page of a reviewer's feedback list in the background
+func (s *FeedbackServer) PrefetchReviewerDashboard(ctx context.Context, req *pb.PrefetchReviewerDashboardRequest) (*pb.PrefetchReviewerDashboardResponse, error) {
+	s.logger.Info("gRPC PrefetchReviewerDashboard received", "reviewer_id", req.ReviewerId)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
 	}
 
-	s.logger.Info("gRPC ListReviewerFeedbacks completed",
-		"reviewer_id", req.ReviewerId,
-		"count", len(feedbacks),
-		"total_count", totalCount,
-	)
-	return response, nil
+	started, err := s.feedbackService.PrefetchReviewerDashboard(req.ReviewerId, req.Limit)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &pb.PrefetchReviewerDashboardResponse{Started: started}, nil
 }
 
 // Student Operations
 
-// GetStudentFeedback retrieves feedback for a student by submission
+// GetStudentFeedback retrieves the most recent feedback for a student by submission
 func (s *FeedbackServer) GetStudentFeedback(ctx context.Context, req *pb.GetStudentFeedbackRequest) (*pb.Feedback, error) {
 	s.logger.Info("gRPC GetStudentFeedback received",
 		"student_id", req.StudentId,
 		"submission_id", req.SubmissionId,
+		"include_thread_summary", req.IncludeThreadSummary,
 	)
 
 	if req.StudentId <= 0 {
@@ -214,28 +1195,71 @@ func (s *FeedbackServer) GetStudentFeedback(ctx context.Context, req *pb.GetStud
 		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
 	}
 
-	feedbacks, err := s.feedbackService.GetStudentFeedback(ctx, req.StudentId, req.SubmissionId)
+	feedback, err := s.feedbackService.GetStudentFeedback(ctx, req.StudentId, req.SubmissionId)
 	if err != nil {
 		s.logger.Error("gRPC GetStudentFeedback failed",
 			"student_id", req.StudentId,
 			"submission_id", req.SubmissionId,
 			"error", err,
 		)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get student feedback: %v", err))
+		return nil, storageError(err, "failed to get student feedback")
+	}
+
+	response := convertToProtoFeedback(feedback)
+	if req.IncludeThreadSummary {
+		s.attachThreadSummaries(ctx, []*models.Feedback{feedback}, []*pb.Feedback{response})
+	}
+	s.logger.Info("gRPC GetStudentFeedback completed", "id", response.Id)
+	return response, nil
+}
+
+// GetStudentSubmissionFeedbacks lists the feedbacks of all reviewers for a student's submission
+func (s *FeedbackServer) GetStudentSubmissionFeedbacks(ctx context.Context, req *pb.GetStudentSubmissionFeedbacksRequest) (*pb.GetStudentSubmissionFeedbacksResponse, error) {
+	s.logger.Info("gRPC GetStudentSubmissionFeedbacks received",
+		"student_id", req.StudentId,
+		"submission_id", req.SubmissionId,
+		"page", req.Page,
+		"limit", req.Limit,
+		"include_thread_summary", req.IncludeThreadSummary,
+	)
+
+	if req.StudentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "student_id is required")
+	}
+	if req.SubmissionId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "submission_id is required")
 	}
 
-	if len(feedbacks) == 0 {
-		s.logger.Warn("gRPC GetStudentFeedback: no feedback found",
+	feedbacks, totalCount, err := s.feedbackService.GetStudentSubmissionFeedbacks(ctx, req.StudentId, req.SubmissionId, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Error("gRPC GetStudentSubmissionFeedbacks failed",
 			"student_id", req.StudentId,
 			"submission_id", req.SubmissionId,
+			"error", err,
 		)
-		return nil, status.Error(codes.NotFound, "no feedback found for this submission")
+		return nil, storageError(err, "failed to get student submission feedbacks")
 	}
 
-	// Return the first feedback (assuming one feedback per submission)
-	// If multiple feedbacks are expected, this should be changed to return all
-	response := convertToProtoFeedback(feedbacks[0])
-	s.logger.Info("gRPC GetStudentFeedback completed", "id", response.Id)
+	legacyCount, truncated := legacyTotalCount(totalCount)
+	response := &pb.GetStudentSubmissionFeedbacksResponse{
+		Feedbacks:      make([]*pb.Feedback, len(feedbacks)),
+		TotalCount:     legacyCount,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}
+	for i, feedback := range feedbacks {
+		response.Feedbacks[i] = convertToProtoFeedback(feedback)
+	}
+	if req.IncludeThreadSummary {
+		s.attachThreadSummaries(ctx, feedbacks, response.Feedbacks)
+	}
+
+	s.logger.Info("gRPC GetStudentSubmissionFeedbacks completed",
+		"student_id", req.StudentId,
+		"submission_id", req.SubmissionId,
+		"count", len(feedbacks),
+		"total_count", totalCount,
+	)
 	return response, nil
 }
 
@@ -244,33 +1268,84 @@ func (s *FeedbackServer) ListStudentFeedbacks(ctx context.Context, req *pb.ListS
 	s.logger.Info("gRPC ListStudentFeedbacks received",
 		"student_id", req.StudentId,
 		"submission_id", req.SubmissionId,
+		"unread_only", req.UnreadOnly,
+		"requires_resubmission_only", req.RequiresResubmissionOnly,
+		"include_archived", req.IncludeArchived,
+		"include_thread_summary", req.IncludeThreadSummary,
+		"sort_by", req.SortBy,
+		"sort_direction", req.SortDirection,
 		"page", req.Page,
+		"page_token", req.PageToken != "",
 		"limit", req.Limit,
 	)
 
 	if req.StudentId <= 0 {
 		return nil, status.Error(codes.InvalidArgument, "student_id is required")
 	}
+	createdAfter, createdBefore, err := createdRange(req.CreatedAfter, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := convertFromProtoSort(req.SortBy, req.SortDirection)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	after, err := s.decodeFeedbackPageToken(req.PageToken, req.Page, sort)
+	if err != nil {
+		return nil, err
+	}
 
 	var submissionID *int64
 	if req.SubmissionId != nil {
 		submissionID = req.SubmissionId
 	}
 
-	feedbacks, totalCount, err := s.feedbackService.ListStudentFeedbacks(ctx, req.StudentId, submissionID, req.Page, req.Limit)
+	feedbacks, totalCount, next, lastActivity, err := s.feedbackService.ListStudentFeedbacks(ctx, models.StudentFeedbackListOptions{
+		StudentID:        req.StudentId,
+		SubmissionID:     submissionID,
+		UnreadOnly:       req.UnreadOnly,
+		ResubmissionOnly: req.RequiresResubmissionOnly,
+		IncludeArchived:  req.IncludeArchived,
+		CreatedAfter:     createdAfter,
+		CreatedBefore:    createdBefore,
+		Sort:             sort,
+		Page:             req.Page,
+		Limit:            req.Limit,
+		After:            after,
+		PrefetchNext:     req.PrefetchNextPage,
+	})
 	if err != nil {
 		s.logger.Error("gRPC ListStudentFeedbacks failed", "student_id", req.StudentId, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list student feedbacks: %v", err))
+		return nil, readError(ctx, err, "failed to list student feedbacks")
+	}
+	nextPageToken, err := s.encodeFeedbackPageToken(next)
+	if err != nil {
+		s.logger.Error("gRPC ListStudentFeedbacks failed to encode page token", "student_id", req.StudentId, "error", err)
+		return nil, status.Error(codes.Internal, "failed to encode page token")
 	}
 
 	pbFeedbacks := make([]*pb.Feedback, len(feedbacks))
 	for i, feedback := range feedbacks {
 		pbFeedbacks[i] = convertToProtoFeedback(feedback)
 	}
+	if req.IncludeThreadSummary {
+		s.attachThreadSummaries(ctx, feedbacks, pbFeedbacks)
+	}
 
+	legacyCount, truncated := legacyTotalCount(totalCount)
 	response := &pb.ListStudentFeedbacksResponse{
-		Feedbacks:  pbFeedbacks,
-		TotalCount: totalCount,
+		Feedbacks:      pbFeedbacks,
+		TotalCount:     legacyCount,
+		NextPageToken:  nextPageToken,
+		TotalCount64:   totalCount,
+		CountTruncated: truncated,
+	}
+	if lastActivity != nil {
+		response.LastActivityAt = timestamppb.New(*lastActivity)
 	}
 
 	s.logger.Info("gRPC ListStudentFeedbacks completed",
@@ -281,6 +1356,54 @@ func (s *FeedbackServer) ListStudentFeedbacks(ctx context.Context, req *pb.ListS
 	return response, nil
 }
 
+// SearchFeedbacks finds a reviewer's or student's feedbacks by keywords, most relevant first
+func (s *FeedbackServer) SearchFeedbacks(ctx context.Context, req *pb.SearchFeedbacksRequest) (*pb.SearchFeedbacksResponse, error) {
+	s.logger.Info("gRPC SearchFeedbacks received",
+		"reviewer_id", req.ReviewerId,
+		"student_id", req.StudentId,
+		"submission_id", req.SubmissionId,
+		"status", req.Status,
+		"page", req.Page,
+		"limit", req.Limit,
+	)
+
+	feedbackStatus, err := convertFromProtoStatus(req.Status)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	filter := models.FeedbackSearchFilter{
+		Query:        req.Query,
+		ReviewerID:   req.ReviewerId,
+		StudentID:    req.StudentId,
+		SubmissionID: req.SubmissionId,
+		Status:       feedbackStatus,
+		Page:         int(req.Page),
+		Limit:        int(req.Limit),
+	}
+
+	results, totalCount, err := s.feedbackService.SearchFeedbacks(ctx, filter)
+	if err != nil {
+		s.logger.Error("gRPC SearchFeedbacks failed", "reviewer_id", req.ReviewerId, "student_id", req.StudentId, "error", err)
+		return nil, storageError(err, "failed to search feedbacks")
+	}
+
+	hits := make([]*pb.FeedbackSearchHit, len(results))
+	for i, result := range results {
+		hits[i] = &pb.FeedbackSearchHit{
+			Feedback: convertToProtoFeedback(result.Feedback),
+			Snippet:  result.Snippet,
+			Rank:     result.Rank,
+		}
+	}
+
+	s.logger.Info("gRPC SearchFeedbacks completed",
+		"count", len(results),
+		"total_count", totalCount,
+	)
+	return &pb.SearchFeedbacksResponse{Hits: hits, TotalCount: totalCount}, nil
+}
+
 // GetFeedbackById retrieves feedback by its ID
 func (s *FeedbackServer) GetFeedbackById(ctx context.Context, req *pb.GetFeedbackByIdRequest) (*pb.Feedback, error) {
 	s.logger.Info("gRPC GetFeedbackById received", "id", req.Id)
@@ -289,7 +1412,7 @@ func (s *FeedbackServer) GetFeedbackById(ctx context.Context, req *pb.GetFeedbac
 		return nil, status.Error(codes.InvalidArgument, "feedback id is required")
 	}
 
-	feedbackID, err := uuid.Parse(req.Id)
+	feedbackID, err := resourcename.ParseFeedbackID(req.Id)
 	if err != nil {
 		s.logger.Warn("gRPC GetFeedbackById: invalid ID format", "id", req.Id, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
@@ -297,8 +1420,8 @@ func (s *FeedbackServer) GetFeedbackById(ctx context.Context, req *pb.GetFeedbac
 
 	feedback, err := s.feedbackService.GetFeedbackByID(ctx, feedbackID)
 	if err != nil {
-		s.logger.Error("gRPC GetFeedbackById failed", "id", req.Id, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get feedback: %v", err))
+		s.logger.Warn("gRPC GetFeedbackById failed", "id", req.Id, "error", err)
+		return nil, apperr.ToStatus(err)
 	}
 
 	if feedback == nil {
@@ -311,6 +1434,187 @@ func (s *FeedbackServer) GetFeedbackById(ctx context.Context, req *pb.GetFeedbac
 	return response, nil
 }
 
+// BatchGetFeedbacks gets up to 100 feedbacks by ID in one call
+func (s *FeedbackServer) BatchGetFeedbacks(ctx context.Context, req *pb.BatchGetFeedbacksRequest) (*pb.BatchGetFeedbacksResponse, error) {
+	s.logger.Info("gRPC BatchGetFeedbacks received", "count", len(req.Ids))
+
+	if len(req.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ids is required")
+	}
+
+	rejectDuplicates := flags.FromContext(ctx).Enabled(flags.RejectDuplicateIDs)
+	ids := make([]uuid.UUID, len(req.Ids))
+	for i, value := range req.Ids {
+		id, err := resourcename.ParseFeedbackID(value)
+		if err != nil {
+			s.logger.Warn("gRPC BatchGetFeedbacks: invalid ID format", "id", validation.LogValue(value), "error", err)
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid feedback ID %q at ids[%d]", value, i))
+		}
+		if rejectDuplicates && slices.Contains(ids[:i], id) {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("duplicate feedback ID %q at ids[%d]", value, i))
+		}
+		ids[i] = id
+	}
+
+	feedbacks, missing, err := s.feedbackService.BatchGetFeedbacks(ctx, ids)
+	if err != nil {
+		s.logger.Warn("gRPC BatchGetFeedbacks failed", "count", len(ids), "error", err)
+		return nil, storageError(err, "failed to get feedbacks")
+	}
+
+	response := &pb.BatchGetFeedbacksResponse{
+		Feedbacks:  make([]*pb.Feedback, len(feedbacks)),
+		MissingIds: make([]string, len(missing)),
+	}
+	for i, feedback := range feedbacks {
+		response.Feedbacks[i] = convertToProtoFeedback(feedback)
+	}
+	for i, id := range missing {
+		response.MissingIds[i] = id.String()
+	}
+
+	s.logger.Info("gRPC BatchGetFeedbacks completed", "found", len(feedbacks), "missing", len(missing))
+	return response, nil
+}
+
+// GetFeedbackStatistics returns aggregate feedback counts of a reviewer or student
+func (s *FeedbackServer) GetFeedbackStatistics(ctx context.Context, req *pb.GetFeedbackStatisticsRequest) (*pb.GetFeedbackStatisticsResponse, error) {
+	s.logger.Info("gRPC GetFeedbackStatistics received", "reviewer_id", req.ReviewerId, "student_id", req.StudentId)
+
+	if req.ReviewerId == nil && req.StudentId == nil {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id or student_id is required")
+	}
+
+	stats, err := s.feedbackService.GetFeedbackStatistics(ctx, req.ReviewerId, req.StudentId)
+	if err != nil {
+		s.logger.Warn("gRPC GetFeedbackStatistics failed", "reviewer_id", req.ReviewerId, "student_id", req.StudentId, "error", err)
+		return nil, storageError(err, "failed to get feedback statistics")
+	}
+
+	response := &pb.GetFeedbackStatisticsResponse{
+		TotalCount:           stats.Total,
+		CreatedLast_7Days:    stats.CreatedLast7Days,
+		CreatedLast_30Days:   stats.CreatedLast30Days,
+		AverageContentLength: stats.AverageContentLength,
+		SubmissionCount:      stats.SubmissionCount,
+		PerSubmission:        make([]*pb.SubmissionFeedbackCount, len(stats.PerSubmission)),
+	}
+	for i, count := range stats.PerSubmission {
+		response.PerSubmission[i] = &pb.SubmissionFeedbackCount{SubmissionId: count.SubmissionID, Count: count.Count}
+	}
+
+	s.logger.Info("gRPC GetFeedbackStatistics completed", "total_count", stats.Total)
+	return response, nil
+}
+
+// gradePercent converts an average grade ratio to a percentage, keeping nil for no grades
+func gradePercent(ratio *float64) *float64 {
+	if ratio == nil {
+		return nil
+	}
+	percent := *ratio * 100
+	return &percent
+}
+
+// GetStudentFeedbackSummary summarizes the feedback a student received, per submission and overall
+func (s *FeedbackServer) GetStudentFeedbackSummary(ctx context.Context, req *pb.GetStudentFeedbackSummaryRequest) (*pb.GetStudentFeedbackSummaryResponse, error) {
+	s.logger.Info("gRPC GetStudentFeedbackSummary received", "student_id", req.StudentId)
+
+	if req.StudentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "student_id is required")
+	}
+
+	summary, err := s.feedbackService.GetStudentFeedbackSummary(ctx, req.StudentId)
+	if err != nil {
+		s.logger.Warn("gRPC GetStudentFeedbackSummary failed", "student_id", req.StudentId, "error", err)
+		return nil, storageError(err, "failed to get student feedback summary")
+	}
+
+	response := &pb.GetStudentFeedbackSummaryResponse{
+		Submissions:         make([]*pb.SubmissionFeedbackSummary, len(summary.Submissions)),
+		FeedbackCount:       summary.FeedbackCount,
+		UnreadCount:         summary.UnreadCount,
+		SubmissionCount:     int64(len(summary.Submissions)),
+		GradedCount:         summary.GradedCount,
+		AverageGradePercent: gradePercent(summary.AverageGradeRatio),
+	}
+	if summary.LatestFeedbackAt != nil {
+		response.LatestFeedbackAt = timestamppb.New(*summary.LatestFeedbackAt)
+	}
+	for i, submission := range summary.Submissions {
+		response.Submissions[i] = &pb.SubmissionFeedbackSummary{
+			SubmissionId:        submission.SubmissionID,
+			FeedbackCount:       submission.FeedbackCount,
+			UnreadCount:         submission.UnreadCount,
+			LatestFeedbackAt:    timestamppb.New(submission.LatestFeedbackAt),
+			GradedCount:         submission.GradedCount,
+			AverageGradePercent: gradePercent(submission.AverageGradeRatio),
+		}
+	}
+
+	s.logger.Info("gRPC GetStudentFeedbackSummary completed", "student_id", req.StudentId, "feedback_count", summary.FeedbackCount)
+	return response, nil
+}
+
+// notificationFormats maps the proto notification formats to renderer formats
+var notificationFormats = map[pb.NotificationFormat]notification.Format{
+	pb.NotificationFormat_NOTIFICATION_FORMAT_UNSPECIFIED: notification.FormatText,
+	pb.NotificationFormat_NOTIFICATION_FORMAT_TEXT:        notification.FormatText,
+	pb.NotificationFormat_NOTIFICATION_FORMAT_HTML:        notification.FormatHTML,
+}
+
+// RenderFeedbackNotification renders the email body announcing a feedback (internal services only)
+func (s *FeedbackServer) RenderFeedbackNotification(ctx context.Context, req *pb.RenderFeedbackNotificationRequest) (*pb.RenderFeedbackNotificationResponse, error) {
+	s.logger.Info("gRPC RenderFeedbackNotification received", "feedback_id", req.FeedbackId, "format", req.Format)
+
+	if err := middleware.RequireInternalCaller(ctx); err != nil {
+		s.logger.Warn("gRPC RenderFeedbackNotification: permission denied")
+		return nil, err
+	}
+	if req.FeedbackId == "" {
+		return nil, status.Error(codes.InvalidArgument, "feedback_id is required")
+	}
+	format, ok := notificationFormats[req.Format]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unsupported notification format")
+	}
+
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC RenderFeedbackNotification: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	feedback, err := s.feedbackService.GetFeedbackByID(ctx, feedbackID)
+	if err != nil {
+		s.logger.Warn("gRPC RenderFeedbackNotification: failed to get feedback", "feedback_id", feedbackID, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+
+	attachments, err := s.feedbackService.ListAttachments(ctx, feedbackID)
+	if err != nil {
+		s.logger.Error("gRPC RenderFeedbackNotification: failed to list attachments", "feedback_id", feedbackID, "error", err)
+		return nil, storageError(err, "failed to list attachments")
+	}
+
+	result, err := s.notifications.Render(feedback, attachments, format)
+	if err != nil {
+		s.logger.Error("gRPC RenderFeedbackNotification: render failed", "feedback_id", feedbackID, "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to render notification: %v", err))
+	}
+
+	s.logger.Info("gRPC RenderFeedbackNotification completed",
+		"feedback_id", feedbackID,
+		"bytes", len(result.Body),
+		"truncated", result.Truncated,
+	)
+	return &pb.RenderFeedbackNotificationResponse{
+		Body:             result.Body,
+		ContentType:      result.ContentType,
+		ContentTruncated: result.Truncated,
+	}, nil
+}
+
 // Attachment Operations
 
 // UploadAttachment uploads an attachment to a feedback (reviewer only)
@@ -337,15 +1641,15 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 		s.logger.Error("gRPC UploadAttachment: metadata is required in first chunk")
 		return status.Error(codes.InvalidArgument, "metadata is required in first chunk")
 	}
-	
+
 	s.logger.Info("gRPC UploadAttachment received",
 		"reviewer_id", metadata.ReviewerId,
 		"feedback_id", metadata.FeedbackId,
-		"filename", metadata.Filename,
+		"filename", validation.LogValue(metadata.Filename),
 		"size", metadata.TotalSize,
-		"content_type", metadata.ContentType,
+		"content_type", validation.LogValue(metadata.ContentType),
 	)
-	
+
 	if metadata.ReviewerId <= 0 {
 		s.logger.Error("gRPC UploadAttachment: reviewer_id is required")
 		return status.Error(codes.InvalidArgument, "reviewer_id is required")
@@ -358,26 +1662,74 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 		s.logger.Error("gRPC UploadAttachment: filename is required")
 		return status.Error(codes.InvalidArgument, "filename is required")
 	}
-	if metadata.TotalSize <= 0 {
+	if err := validation.Filename(metadata.Filename); err != nil {
+		s.logger.Warn("gRPC UploadAttachment: invalid filename", "filename", validation.LogValue(metadata.Filename), "error", err)
+		return validationError(err)
+	}
+	if err := validation.ContentType(metadata.ContentType); err != nil {
+		s.logger.Warn("gRPC UploadAttachment: invalid content type", "content_type", validation.LogValue(metadata.ContentType), "error", err)
+		return validationError(err)
+	}
+	if metadata.TotalSize < 0 || (metadata.TotalSize == 0 && !metadata.AllowEmpty) {
 		s.logger.Error("gRPC UploadAttachment: total_size must be positive")
-		return status.Error(codes.InvalidArgument, "total_size must be positive")
+		return status.Error(codes.InvalidArgument, "total_size must be positive unless allow_empty is set")
 	}
 
-	feedbackID, err := uuid.Parse(metadata.FeedbackId)
+	feedbackID, err := resourcename.ParseFeedbackID(metadata.FeedbackId)
 	if err != nil {
 		s.logger.Warn("gRPC UploadAttachment: invalid feedback ID format", "feedback_id", metadata.FeedbackId, "error", err)
 		return status.Error(codes.InvalidArgument, "invalid feedback ID format")
 	}
 
-	// Check attachment count limit
-	existingAttachments, err := s.feedbackService.ListAttachments(ctx, feedbackID)
-	if err != nil {
-		s.logger.Error("gRPC UploadAttachment: failed to check existing attachments", "error", err)
-		return status.Error(codes.Internal, fmt.Sprintf("failed to check existing attachments: %v", err))
+	// Enforce the reviewer's daily transfer cap before accepting any bytes
+	if err := s.transfers.Check(ctx, metadata.ReviewerId, metadata.TotalSize); err != nil {
+		return transferQuotaError(err)
+	}
+
+	// Reject uploads by anyone but the author and to locked feedback before accepting any bytes
+	if err := s.feedbackService.AuthorizeAttachmentChange(ctx, feedbackID, metadata.ReviewerId); err != nil {
+		s.logger.Warn("gRPC UploadAttachment: feedback does not accept uploads", "feedback_id", feedbackID, "error", err)
+		return apperr.ToStatus(err)
+	}
+
+	// Files staged in an upload session are checked against the limits when it is committed
+	var sessionID uuid.UUID
+	if metadata.SessionId != "" {
+		sessionID, err = uuid.Parse(metadata.SessionId)
+		if err != nil {
+			s.logger.Warn("gRPC UploadAttachment: invalid session ID format", "session_id", metadata.SessionId, "error", err)
+			return status.Error(codes.InvalidArgument, "invalid session ID format")
+		}
+	} else {
+		// Check the attachment count limit of the feedback's course
+		if err := s.feedbackService.CheckAttachmentCount(ctx, feedbackID); err != nil {
+			if errors.Is(err, service.ErrAttachmentLimitExceeded) {
+				s.logger.Warn("gRPC UploadAttachment: maximum attachments reached", "feedback_id", feedbackID, "error", err)
+				return statusWithReason(codes.FailedPrecondition, err.Error(), "ATTACHMENT_LIMIT_EXCEEDED", nil)
+			}
+			s.logger.Error("gRPC UploadAttachment: failed to check existing attachments", "error", err)
+			return status.Error(codes.Internal, fmt.Sprintf("failed to check existing attachments: %v", err))
+		}
+	}
+
+	// Empty files need no data stream, only the end of it
+	if metadata.TotalSize == 0 {
+		return s.uploadEmptyAttachment(ctx, stream, req, metadata, feedbackID, sessionID)
 	}
-	if len(existingAttachments) >= config.MaxAttachmentsPerFeedback {
-		s.logger.Warn("gRPC UploadAttachment: maximum attachments reached", "max_attachments", config.MaxAttachmentsPerFeedback)
-		return status.Error(codes.FailedPrecondition, fmt.Sprintf("maximum %d attachments allowed per feedback", config.MaxAttachmentsPerFeedback))
+
+	// Clients that send the metadata alone are expected to follow it with data. Read the next
+	// message before starting the upload, so a stream that ends right after the metadata fails
+	// without creating an object.
+	if len(req.GetChunk()) == 0 {
+		req, err = stream.Recv()
+		if err == io.EOF {
+			s.logger.Warn("gRPC UploadAttachment: stream ended after metadata", "feedback_id", feedbackID, "total_expected", metadata.TotalSize)
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("declared %d bytes, received 0", metadata.TotalSize))
+		}
+		if err != nil {
+			s.logger.Error("gRPC UploadAttachment: error receiving chunk", "error", err)
+			return status.Error(codes.Internal, fmt.Sprintf("failed to receive chunk: %v", err))
+		}
 	}
 
 	// Create pipe for streaming data
@@ -394,10 +1746,17 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 				uploadErrCh <- fmt.Errorf("upload panic: %v", r)
 			}
 		}()
-		
+
 		s.logger.Info("gRPC UploadAttachment: starting upload goroutine")
-		err := s.feedbackService.UploadAttachment(ctx, feedbackID, metadata.Filename, metadata.ContentType, pipeReader, metadata.TotalSize)
-		s.logger.Info("gRPC UploadAttachment: upload goroutine finished", "feedback_id", feedbackID, "filename", metadata.Filename, "error", err)
+		var err error
+		if sessionID != uuid.Nil {
+			err = s.feedbackService.UploadSessionFile(ctx, sessionID, feedbackID, metadata.ReviewerId, metadata.Filename, metadata.ContentType, pipeReader, metadata.TotalSize)
+		} else {
+			err = s.feedbackService.UploadAttachment(ctx, feedbackID, metadata.ReviewerId, metadata.Filename, metadata.ContentType, pipeReader, metadata.TotalSize)
+		}
+		// Unblock the stream reader if the upload stopped consuming data early
+		pipeReader.CloseWithError(err)
+		s.logger.Info("gRPC UploadAttachment: upload goroutine finished", "feedback_id", feedbackID, "filename", validation.LogValue(metadata.Filename), "error", err)
 		uploadErrCh <- err
 	}()
 
@@ -407,10 +1766,10 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 
 	// Check if the first request also contains chunk data
 	firstChunk := req.GetChunk()
-	
+
 	// Read chunks from stream
 	s.logger.Info("gRPC UploadAttachment: starting to read chunks from stream")
-	
+
 	func() {
 		// Ensure pipe writer is closed when we exit this function
 		defer func() {
@@ -426,7 +1785,7 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 		// Handle first chunk if it exists
 		if firstChunk != nil && len(firstChunk) > 0 {
 			s.logger.Info("gRPC UploadAttachment: processing first chunk", "chunk_size", len(firstChunk))
-			
+
 			// Validate total size
 			if int64(len(firstChunk)) > metadata.TotalSize {
 				streamErr = fmt.Errorf("first chunk larger than total size: %d > %d", len(firstChunk), metadata.TotalSize)
@@ -443,7 +1802,7 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 			}
 			totalReceived += int64(n)
 			s.logger.Info("gRPC UploadAttachment: written first chunk", "chunk_size", n, "total_received", totalReceived, "total_expected", metadata.TotalSize)
-			
+
 			// Check if we've received all expected data from first chunk
 			if totalReceived >= metadata.TotalSize {
 				s.logger.Info("gRPC UploadAttachment: received all expected data from first chunk, ending stream", "total_received", totalReceived)
@@ -512,7 +1871,7 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 			}
 			totalReceived += int64(n)
 			s.logger.Info("gRPC UploadAttachment: written chunk", "chunk_size", n, "total_received", totalReceived, "total_expected", metadata.TotalSize)
-			
+
 			// Check if we've received all expected data
 			if totalReceived >= metadata.TotalSize {
 				s.logger.Info("gRPC UploadAttachment: received all expected data, ending stream", "total_received", totalReceived)
@@ -548,11 +1907,15 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 	// Wait for upload to complete
 	select {
 	case uploadErr := <-uploadErrCh:
+		if errors.Is(uploadErr, service.ErrUploadSessionClosed) {
+			return status.Error(codes.FailedPrecondition, uploadErr.Error())
+		}
 		if uploadErr != nil {
 			s.logger.Error("gRPC UploadAttachment: upload failed", "feedback_id", feedbackID, "error", uploadErr)
-			return status.Error(codes.Internal, fmt.Sprintf("failed to upload attachment: %v", uploadErr))
+			return apperr.ToStatus(uploadErr)
 		}
 		s.logger.Info("gRPC UploadAttachment: upload completed successfully", "feedback_id", feedbackID)
+		s.transfers.Record(metadata.ReviewerId, service.TransferUpload, totalReceived)
 	case <-ctx.Done():
 		s.logger.Error("gRPC UploadAttachment: upload cancelled due to context", "feedback_id", feedbackID, "error", ctx.Err())
 		return status.Error(codes.Canceled, "upload cancelled")
@@ -562,7 +1925,7 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 		return status.Error(codes.DeadlineExceeded, "upload timeout")
 	}
 
-	s.logger.Info("gRPC UploadAttachment response", "filename", metadata.Filename, "size", totalReceived)
+	s.logger.Info("gRPC UploadAttachment response", "filename", validation.LogValue(metadata.Filename), "size", totalReceived)
 	return stream.SendAndClose(&pb.UploadAttachmentResponse{
 		Filename: metadata.Filename,
 		Size:     totalReceived,
@@ -570,12 +1933,54 @@ func (s *FeedbackServer) UploadAttachment(stream pb.FeedbackService_UploadAttach
 	})
 }
 
+// uploadEmptyAttachment stores a zero-byte file declared with allow_empty. The stream must end
+// after the metadata; any data in it is rejected.
+func (s *FeedbackServer) uploadEmptyAttachment(ctx context.Context, stream pb.FeedbackService_UploadAttachmentServer, first *pb.UploadAttachmentRequest, metadata *pb.AttachmentMetadata, feedbackID, sessionID uuid.UUID) error {
+	if len(first.GetChunk()) > 0 {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("declared 0 bytes, received %d", len(first.GetChunk())))
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.logger.Error("gRPC UploadAttachment: error receiving chunk", "error", err)
+			return status.Error(codes.Internal, fmt.Sprintf("failed to receive chunk: %v", err))
+		}
+		if len(req.GetChunk()) > 0 {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("declared 0 bytes, received %d", len(req.GetChunk())))
+		}
+	}
+
+	var err error
+	if sessionID != uuid.Nil {
+		err = s.feedbackService.UploadSessionFile(ctx, sessionID, feedbackID, metadata.ReviewerId, metadata.Filename, metadata.ContentType, strings.NewReader(""), 0)
+	} else {
+		err = s.feedbackService.UploadAttachment(ctx, feedbackID, metadata.ReviewerId, metadata.Filename, metadata.ContentType, strings.NewReader(""), 0)
+	}
+	if errors.Is(err, service.ErrUploadSessionClosed) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.Error("gRPC UploadAttachment: upload failed", "feedback_id", feedbackID, "error", err)
+		return apperr.ToStatus(err)
+	}
+
+	s.logger.Info("gRPC UploadAttachment response", "filename", validation.LogValue(metadata.Filename), "size", 0)
+	return stream.SendAndClose(&pb.UploadAttachmentResponse{
+		Filename: metadata.Filename,
+		Size:     0,
+		Success:  true,
+	})
+}
+
 // DeleteAttachment deletes an attachment (reviewer only)
 func (s *FeedbackServer) DeleteAttachment(ctx context.Context, req *pb.DeleteAttachmentRequest) (*pb.DeleteAttachmentResponse, error) {
 	s.logger.Info("gRPC DeleteAttachment received",
 		"reviewer_id", req.ReviewerId,
 		"feedback_id", req.FeedbackId,
-		"filename", req.Filename,
+		"filename", validation.LogValue(req.Filename),
 	)
 
 	// Validate request
@@ -591,17 +1996,20 @@ func (s *FeedbackServer) DeleteAttachment(ctx context.Context, req *pb.DeleteAtt
 		s.logger.Error("gRPC DeleteAttachment: filename is required")
 		return nil, status.Error(codes.InvalidArgument, "filename is required")
 	}
+	if err := validation.Filename(req.Filename); err != nil {
+		return nil, validationError(err)
+	}
 
-	feedbackID, err := uuid.Parse(req.FeedbackId)
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
 	if err != nil {
 		s.logger.Warn("gRPC DeleteAttachment: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
 	}
 
-	err = s.feedbackService.DeleteAttachment(ctx, feedbackID, req.Filename)
+	err = s.feedbackService.DeleteAttachment(ctx, feedbackID, req.ReviewerId, req.Filename)
 	if err != nil {
-		s.logger.Error("gRPC DeleteAttachment failed", "feedback_id", feedbackID, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete attachment: %v", err))
+		s.logger.Warn("gRPC DeleteAttachment failed", "feedback_id", feedbackID, "error", err)
+		return nil, apperr.ToStatus(err)
 	}
 
 	response := &pb.DeleteAttachmentResponse{Success: true}
@@ -613,7 +2021,8 @@ func (s *FeedbackServer) DeleteAttachment(ctx context.Context, req *pb.DeleteAtt
 func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, stream pb.FeedbackService_DownloadAttachmentServer) error {
 	s.logger.Info("gRPC DownloadAttachment received",
 		"feedback_id", req.FeedbackId,
-		"filename", req.Filename,
+		"filename", validation.LogValue(req.Filename),
+		"requester_id", req.RequesterId,
 	)
 
 	if req.FeedbackId == "" {
@@ -624,8 +2033,11 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 		s.logger.Error("gRPC DownloadAttachment: filename is required")
 		return status.Error(codes.InvalidArgument, "filename is required")
 	}
+	if err := validation.Filename(req.Filename); err != nil {
+		return validationError(err)
+	}
 
-	feedbackID, err := uuid.Parse(req.FeedbackId)
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
 	if err != nil {
 		s.logger.Warn("gRPC DownloadAttachment: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
 		return status.Error(codes.InvalidArgument, "invalid feedback ID format")
@@ -635,7 +2047,7 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 	attachments, err := s.feedbackService.ListAttachments(stream.Context(), feedbackID)
 	if err != nil {
 		s.logger.Error("gRPC DownloadAttachment: failed to get attachment info", "feedback_id", feedbackID, "error", err)
-		return status.Error(codes.Internal, fmt.Sprintf("failed to get attachment info: %v", err))
+		return storageError(err, "failed to get attachment info")
 	}
 
 	var attachmentInfo *models.AttachmentInfo
@@ -647,7 +2059,7 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 	}
 
 	if attachmentInfo == nil {
-		s.logger.Warn("gRPC DownloadAttachment: attachment not found", "filename", req.Filename)
+		s.logger.Warn("gRPC DownloadAttachment: attachment not found", "filename", validation.LogValue(req.Filename))
 		return status.Error(codes.NotFound, "attachment not found")
 	}
 
@@ -657,33 +2069,41 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 		"content_type", attachmentInfo.ContentType,
 	)
 
+	// Enforce the requester's daily transfer cap before streaming
+	if err := s.transfers.Check(stream.Context(), req.RequesterId, attachmentInfo.Size); err != nil {
+		return transferQuotaError(err)
+	}
+
+	// Open the download before the info frame, which carries the recorded checksum
+	reader, downloadInfo, err := s.feedbackService.DownloadAttachment(stream.Context(), feedbackID, req.Filename)
+	if err != nil {
+		s.logger.Error("gRPC DownloadAttachment: failed to download attachment", "feedback_id", feedbackID, "error", err)
+		return storageError(err, "failed to download attachment")
+	}
+	defer reader.Close()
+
+	buffer := make([]byte, 32*1024) // 32KB chunks
+	limiter := throttle.New(s.downloadBytesPerSecond(stream.Context()), len(buffer))
+
 	// Send attachment info first
 	err = stream.Send(&pb.DownloadAttachmentResponse{
 		Data: &pb.DownloadAttachmentResponse_Info{
-			Info: &pb.AttachmentInfo{
-				Filename:    attachmentInfo.Filename,
-				Size:        attachmentInfo.Size,
-				ContentType: attachmentInfo.ContentType,
-				UploadedAt:  timestamppb.New(attachmentInfo.UploadedAt),
-			},
+			Info: convertToProtoAttachmentInfo(feedbackID, attachmentInfo),
 		},
+		BytesPerSecondLimit: limiter.BytesPerSecond(),
+		ExpectedSha256:      downloadInfo.SHA256,
 	})
 	if err != nil {
 		s.logger.Error("gRPC DownloadAttachment: failed to send attachment info", "error", err)
 		return status.Error(codes.Internal, fmt.Sprintf("failed to send attachment info: %v", err))
 	}
 
-	// Download and stream file content
-	reader, _, err := s.feedbackService.DownloadAttachment(stream.Context(), feedbackID, req.Filename)
-	if err != nil {
-		s.logger.Error("gRPC DownloadAttachment: failed to download attachment", "feedback_id", feedbackID, "error", err)
-		return status.Error(codes.Internal, fmt.Sprintf("failed to download attachment: %v", err))
-	}
-	defer reader.Close()
-
 	s.logger.Info("gRPC DownloadAttachment: starting to stream file content")
 	var totalSent int64
-	buffer := make([]byte, 32*1024) // 32KB chunks
+	// Account for whatever was actually sent, including partially streamed downloads
+	defer func() {
+		s.transfers.Record(req.RequesterId, service.TransferDownload, totalSent)
+	}()
 	for {
 		n, err := reader.Read(buffer)
 		if err == io.EOF {
@@ -691,9 +2111,21 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 		}
 		if err != nil {
 			s.logger.Error("gRPC DownloadAttachment: failed to read attachment", "error", err)
+			if errors.Is(err, envelope.ErrCorrupted) {
+				return status.Error(codes.DataLoss, "attachment is corrupted")
+			}
+			if errors.Is(err, service.ErrAttachmentCorrupted) {
+				// Every byte was sent; clients must discard them
+				return apperr.ToStatus(err)
+			}
 			return status.Error(codes.Internal, fmt.Sprintf("failed to read attachment: %v", err))
 		}
 
+		if err := limiter.WaitN(stream.Context(), n); err != nil {
+			s.logger.Warn("gRPC DownloadAttachment: cancelled while throttled", "total_sent", totalSent, "error", err)
+			return status.FromContextError(err).Err()
+		}
+
 		err = stream.Send(&pb.DownloadAttachmentResponse{
 			Data: &pb.DownloadAttachmentResponse_Chunk{
 				Chunk: buffer[:n],
@@ -710,6 +2142,14 @@ func (s *FeedbackServer) DownloadAttachment(req *pb.DownloadAttachmentRequest, s
 	return nil
 }
 
+// downloadBytesPerSecond returns the per-stream download limit for the caller's class
+func (s *FeedbackServer) downloadBytesPerSecond(ctx context.Context) int64 {
+	if middleware.IsInternalCaller(ctx) {
+		return s.downloads.InternalBytesPerSecond
+	}
+	return s.downloads.ExternalBytesPerSecond
+}
+
 // ListAttachments lists attachments for a feedback (both roles)
 func (s *FeedbackServer) ListAttachments(ctx context.Context, req *pb.ListAttachmentsRequest) (*pb.ListAttachmentsResponse, error) {
 	s.logger.Info("gRPC ListAttachments received", "feedback_id", req.FeedbackId)
@@ -719,7 +2159,7 @@ func (s *FeedbackServer) ListAttachments(ctx context.Context, req *pb.ListAttach
 		return nil, status.Error(codes.InvalidArgument, "feedback_id is required")
 	}
 
-	feedbackID, err := uuid.Parse(req.FeedbackId)
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
 	if err != nil {
 		s.logger.Warn("gRPC ListAttachments: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
@@ -728,17 +2168,12 @@ func (s *FeedbackServer) ListAttachments(ctx context.Context, req *pb.ListAttach
 	attachments, err := s.feedbackService.ListAttachments(ctx, feedbackID)
 	if err != nil {
 		s.logger.Error("gRPC ListAttachments failed", "feedback_id", feedbackID, "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list attachments: %v", err))
+		return nil, storageError(err, "failed to list attachments")
 	}
 
 	pbAttachments := make([]*pb.AttachmentInfo, len(attachments))
 	for i, attachment := range attachments {
-		pbAttachments[i] = &pb.AttachmentInfo{
-			Filename:    attachment.Filename,
-			Size:        attachment.Size,
-			ContentType: attachment.ContentType,
-			UploadedAt:  timestamppb.New(attachment.UploadedAt),
-		}
+		pbAttachments[i] = convertToProtoAttachmentInfo(feedbackID, attachment)
 	}
 
 	response := &pb.ListAttachmentsResponse{Attachments: pbAttachments}
@@ -746,19 +2181,69 @@ func (s *FeedbackServer) ListAttachments(ctx context.Context, req *pb.ListAttach
 	return response, nil
 }
 
+// SetAttachmentOrder sets the listing order of a feedback's attachments (reviewer only)
+func (s *FeedbackServer) SetAttachmentOrder(ctx context.Context, req *pb.SetAttachmentOrderRequest) (*pb.SetAttachmentOrderResponse, error) {
+	s.logger.Info("gRPC SetAttachmentOrder received",
+		"reviewer_id", req.ReviewerId,
+		"feedback_id", req.FeedbackId,
+		"count", len(req.OrderedFilenames),
+	)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if req.FeedbackId == "" {
+		return nil, status.Error(codes.InvalidArgument, "feedback_id is required")
+	}
+	for _, filename := range req.OrderedFilenames {
+		if err := validation.Filename(filename); err != nil {
+			return nil, validationError(err)
+		}
+	}
+
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC SetAttachmentOrder: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	attachments, err := s.feedbackService.SetAttachmentOrder(ctx, feedbackID, req.ReviewerId, req.OrderedFilenames)
+	var orderErr *service.AttachmentOrderError
+	switch {
+	case errors.As(err, &orderErr):
+		return nil, attachmentOrderError(orderErr)
+	case err != nil:
+		s.logger.Warn("gRPC SetAttachmentOrder failed", "feedback_id", feedbackID, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+
+	pbAttachments := make([]*pb.AttachmentInfo, len(attachments))
+	for i, attachment := range attachments {
+		pbAttachments[i] = convertToProtoAttachmentInfo(feedbackID, attachment)
+	}
+
+	s.logger.Info("gRPC SetAttachmentOrder completed", "feedback_id", feedbackID, "count", len(attachments))
+	return &pb.SetAttachmentOrderResponse{Attachments: pbAttachments}, nil
+}
+
 // GetAttachmentLocation returns location information for attachments (both roles)
 func (s *FeedbackServer) GetAttachmentLocation(ctx context.Context, req *pb.GetAttachmentLocationRequest) (*pb.GetAttachmentLocationResponse, error) {
 	s.logger.Info("gRPC GetAttachmentLocation received",
 		"feedback_id", req.FeedbackId,
-		"filename", req.Filename,
+		"filename", validation.LogValue(req.GetFilename()),
 	)
 
 	if req.FeedbackId == "" {
 		s.logger.Error("gRPC GetAttachmentLocation: feedback_id is required")
 		return nil, status.Error(codes.InvalidArgument, "feedback_id is required")
 	}
+	if req.Filename != nil {
+		if err := validation.Filename(*req.Filename); err != nil {
+			return nil, validationError(err)
+		}
+	}
 
-	feedbackID, err := uuid.Parse(req.FeedbackId)
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
 	if err != nil {
 		s.logger.Warn("gRPC GetAttachmentLocation: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
 		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
@@ -767,12 +2252,12 @@ func (s *FeedbackServer) GetAttachmentLocation(ctx context.Context, req *pb.GetA
 	var locationInfos []*models.AttachmentLocationInfo
 
 	if req.Filename != nil && *req.Filename != "" {
-		s.logger.Info("gRPC GetAttachmentLocation: getting location for specific file", "filename", *req.Filename)
+		s.logger.Info("gRPC GetAttachmentLocation: getting location for specific file", "filename", validation.LogValue(*req.Filename))
 		// Get location info for specific attachment
 		locationInfo, err := s.feedbackService.GetAttachmentLocation(ctx, feedbackID, *req.Filename)
 		if err != nil {
 			s.logger.Error("gRPC GetAttachmentLocation: failed to get attachment location", "feedback_id", feedbackID, "error", err)
-			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get attachment location: %v", err))
+			return nil, storageError(err, "failed to get attachment location")
 		}
 		locationInfos = []*models.AttachmentLocationInfo{locationInfo}
 	} else {
@@ -781,7 +2266,7 @@ func (s *FeedbackServer) GetAttachmentLocation(ctx context.Context, req *pb.GetA
 		infos, err := s.feedbackService.ListAttachmentLocations(ctx, feedbackID)
 		if err != nil {
 			s.logger.Error("gRPC GetAttachmentLocation: failed to list attachment locations", "feedback_id", feedbackID, "error", err)
-			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list attachment locations: %v", err))
+			return nil, storageError(err, "failed to list attachment locations")
 		}
 		locationInfos = infos
 	}
@@ -789,14 +2274,14 @@ func (s *FeedbackServer) GetAttachmentLocation(ctx context.Context, req *pb.GetA
 	pbLocationInfos := make([]*pb.AttachmentLocationInfo, len(locationInfos))
 	for i, info := range locationInfos {
 		pbLocationInfos[i] = &pb.AttachmentLocationInfo{
-			Filename:         info.Filename,
-			Size:             info.Size,
-			ContentType:      info.ContentType,
-			UploadedAt:       timestamppb.New(info.UploadedAt),
-			MinioBucket:      info.MinioBucket,
-			MinioObjectPath:  info.MinioObjectPath,
-			MinioEndpoint:    info.MinioEndpoint,
-			UseSsl:           info.UseSSL,
+			Filename:        info.Filename,
+			Size:            info.Size,
+			ContentType:     info.ContentType,
+			UploadedAt:      timestamppb.New(info.UploadedAt),
+			MinioBucket:     info.MinioBucket,
+			MinioObjectPath: info.MinioObjectPath,
+			MinioEndpoint:   info.MinioEndpoint,
+			UseSsl:          info.UseSSL,
 		}
 	}
 
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// feedbackEventTypes maps the kinds of stream events onto protobuf event types
+var feedbackEventTypes = map[string]pb.FeedbackEventType{
+	models.FeedbackEventCreated:   pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_CREATED,
+	models.FeedbackEventUpdated:   pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_UPDATED,
+	models.FeedbackEventPublished: pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_PUBLISHED,
+	models.FeedbackEventUpload:    pb.FeedbackEventType_FEEDBACK_EVENT_TYPE_UPLOAD_PROGRESS,
+}
+
+// uploadStages maps the stages of an attachment upload onto protobuf upload stages
+var uploadStages = map[string]pb.AttachmentUploadStage{
+	models.UploadStageStarted:   pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_STARTED,
+	models.UploadStageProgress:  pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_PROGRESS,
+	models.UploadStageCompleted: pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_COMPLETED,
+	models.UploadStageFailed:    pb.AttachmentUploadStage_ATTACHMENT_UPLOAD_STAGE_FAILED,
+}
+
+// convertToProtoUploadProgress converts a model UploadProgress to a protobuf
+// AttachmentUploadProgress, nil for events of other kinds
+func convertToProtoUploadProgress(progress *models.UploadProgress) *pb.AttachmentUploadProgress {
+	if progress == nil {
+		return nil
+	}
+	return &pb.AttachmentUploadProgress{
+		Filename:      progress.Filename,
+		Stage:         uploadStages[progress.Stage],
+		Percent:       progress.Percent,
+		BytesReceived: progress.BytesReceived,
+		TotalBytes:    progress.TotalBytes,
+	}
+}
+
+// convertToProtoFeedbackEvent converts a model FeedbackStreamEvent to a protobuf FeedbackEvent
+func (s *FeedbackServer) convertToProtoFeedbackEvent(event models.FeedbackStreamEvent, stream string) (*pb.FeedbackEvent, error) {
+	resumeToken, err := encodeResumeToken(s.pageTokens, stream, event.Position)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FeedbackEvent{
+		Type:        feedbackEventTypes[event.Kind],
+		Feedback:    convertToProtoFeedback(event.Feedback),
+		OccurredAt:  timestamppb.New(event.OccurredAt),
+		Replayed:    event.Replayed,
+		Sequence:    event.Position.Sequence,
+		ResumeToken: resumeToken,
+		Upload:      convertToProtoUploadProgress(event.Upload),
+	}, nil
+}
+
+// SubscribeFeedbackEvents streams changes to the feedback a student can see, and the progress
+// of attachment uploads to it, until the client cancels, replacing polling of
+// ListStudentFeedbacks. With feedback_id, only the events of that feedback are sent.
+func (s *FeedbackServer) SubscribeFeedbackEvents(req *pb.SubscribeFeedbackEventsRequest, stream pb.FeedbackService_SubscribeFeedbackEventsServer) error {
+	s.logger.Info("gRPC SubscribeFeedbackEvents received", "student_id", req.StudentId, "feedback_id", req.FeedbackId, "since", req.Since, "resume_after", req.ResumeAfter != "")
+
+	if req.StudentId <= 0 {
+		return status.Error(codes.InvalidArgument, "student_id is required")
+	}
+	var feedbackID uuid.UUID
+	if req.FeedbackId != "" {
+		id, err := resourcename.ParseFeedbackID(req.FeedbackId)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "invalid feedback ID format")
+		}
+		feedbackID = id
+	}
+	var since *time.Time
+	if req.Since != nil {
+		if err := req.Since.CheckValid(); err != nil {
+			return status.Error(codes.InvalidArgument, "invalid since: "+err.Error())
+		}
+		t := req.Since.AsTime()
+		since = &t
+	}
+	streamName := fmt.Sprintf("students/%d/feedback", req.StudentId)
+	resumeAfter, err := decodeResumeToken(s.pageTokens, streamName, req.ResumeAfter)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	opts := service.WatchOptions{Since: since, ResumeAfter: resumeAfter}
+	err = s.feedbackService.WatchStudentFeedback(stream.Context(), req.StudentId, opts, func(event models.FeedbackStreamEvent) error {
+		if feedbackID != uuid.Nil && event.Feedback.ID != feedbackID {
+			return nil
+		}
+		pbEvent, err := s.convertToProtoFeedbackEvent(event, streamName)
+		if err != nil {
+			return err
+		}
+		sent++
+		return stream.Send(pbEvent)
+	})
+	if err != nil {
+		s.logger.Warn("gRPC SubscribeFeedbackEvents failed", "student_id", req.StudentId, "sent", sent, "error", err)
+		return storageError(err, "failed to stream feedback events")
+	}
+
+	s.logger.Info("gRPC SubscribeFeedbackEvents completed", "student_id", req.StudentId, "sent", sent)
+	return nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportChunkBytes is the CSV size buffered before a chunk is sent
+const exportChunkBytes = 32 * 1024
+
+// exportHeader is the header row of a feedback export
+var exportHeader = []string{"id", "student_id", "submission_id", "title", "content", "created_at"}
+
+// errExportSend marks a failed send, so the stream error is returned as it is
+var errExportSend = errors.New("failed to send export chunk")
+
+// ExportReviewerFeedbacks streams every feedback of a reviewer as CSV, oldest first, followed by
+// a trailer with the number of rows. Fields with commas, quotes or newlines are quoted.
+func (s *FeedbackServer) ExportReviewerFeedbacks(req *pb.ExportReviewerFeedbacksRequest, stream pb.FeedbackService_ExportReviewerFeedbacksServer) error {
+	ctx := stream.Context()
+	s.logger.Info("gRPC ExportReviewerFeedbacks received", "reviewer_id", req.ReviewerId, "course_id", req.CourseId)
+
+	if req.ReviewerId <= 0 {
+		return status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	var sendErr error
+	send := func() error {
+		chunk := bytes.Clone(buf.Bytes())
+		buf.Reset()
+		if err := stream.Send(&pb.ExportReviewerFeedbacksResponse{
+			Data: &pb.ExportReviewerFeedbacksResponse_Chunk{Chunk: chunk},
+		}); err != nil {
+			sendErr = err
+			return errExportSend
+		}
+		return nil
+	}
+
+	// Rows go through the buffer and are sent once it holds a full chunk
+	writer.Write(exportHeader)
+	rows, err := s.feedbackService.ExportReviewerFeedbacks(ctx, req.ReviewerId, req.CourseId, func(feedbacks []*models.Feedback) error {
+		for _, feedback := range feedbacks {
+			writer.Write(exportRow(feedback))
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			if buf.Len() >= exportChunkBytes {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errExportSend) {
+		s.logger.Warn("gRPC ExportReviewerFeedbacks: stream ended by the client", "reviewer_id", req.ReviewerId, "rows", rows, "error", sendErr)
+		return sendErr
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		s.logger.Error("gRPC ExportReviewerFeedbacks failed", "reviewer_id", req.ReviewerId, "rows", rows, "error", err)
+		return storageError(err, "failed to export feedbacks")
+	}
+
+	writer.Flush()
+	if buf.Len() > 0 {
+		if err := send(); err != nil {
+			return sendErr
+		}
+	}
+	if err := stream.Send(&pb.ExportReviewerFeedbacksResponse{
+		Data: &pb.ExportReviewerFeedbacksResponse_Trailer{
+			Trailer: &pb.ExportReviewerFeedbacksTrailer{RowCount: rows},
+		},
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info("gRPC ExportReviewerFeedbacks completed", "reviewer_id", req.ReviewerId, "rows", rows)
+	return nil
+}
+
+// exportRow returns the CSV fields of a feedback in the order of exportHeader
+func exportRow(feedback *models.Feedback) []string {
+	return []string{
+		feedback.ID.String(),
+		strconv.FormatInt(feedback.StudentID, 10),
+		strconv.FormatInt(feedback.SubmissionID, 10),
+		feedback.Title,
+		feedback.Content,
+		feedback.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SealFeedback records the current content and attachment hashes of a feedback (admin only)
+func (s *FeedbackServer) SealFeedback(ctx context.Context, req *pb.SealFeedbackRequest) (*pb.FeedbackSeal, error) {
+	s.logger.Info("gRPC SealFeedback received", "feedback_id", req.FeedbackId, "actor_id", req.ActorId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC SealFeedback: permission denied", "actor_id", req.ActorId)
+		return nil, err
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+	id, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC SealFeedback: invalid ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	seal, err := s.feedbackService.SealFeedback(ctx, id, req.ActorId)
+	if err != nil {
+		s.logger.Warn("gRPC SealFeedback failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+
+	s.logger.Info("gRPC SealFeedback completed", "feedback_id", req.FeedbackId, "seal_id", seal.ID)
+	return convertToProtoSeal(seal), nil
+}
+
+// GetFeedbackSeal returns the latest seal of a feedback (admin only)
+func (s *FeedbackServer) GetFeedbackSeal(ctx context.Context, req *pb.GetFeedbackSealRequest) (*pb.FeedbackSeal, error) {
+	s.logger.Info("gRPC GetFeedbackSeal received", "feedback_id", req.FeedbackId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC GetFeedbackSeal: permission denied")
+		return nil, err
+	}
+	id, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC GetFeedbackSeal: invalid ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	seal, err := s.feedbackService.GetFeedbackSeal(ctx, id)
+	if err != nil {
+		s.logger.Warn("gRPC GetFeedbackSeal failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+	return convertToProtoSeal(seal), nil
+}
+
+// VerifyFeedbackSeal compares a feedback with its latest seal (admin only)
+func (s *FeedbackServer) VerifyFeedbackSeal(ctx context.Context, req *pb.VerifyFeedbackSealRequest) (*pb.VerifyFeedbackSealResponse, error) {
+	s.logger.Info("gRPC VerifyFeedbackSeal received", "feedback_id", req.FeedbackId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC VerifyFeedbackSeal: permission denied")
+		return nil, err
+	}
+	id, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC VerifyFeedbackSeal: invalid ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	verification, err := s.feedbackService.VerifyFeedbackSeal(ctx, id)
+	if err != nil {
+		s.logger.Warn("gRPC VerifyFeedbackSeal failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, apperr.ToStatus(err)
+	}
+
+	response := &pb.VerifyFeedbackSealResponse{
+		Seal:        convertToProtoSeal(verification.Seal),
+		Intact:      len(verification.Divergences) == 0,
+		Divergences: make([]*pb.SealDivergence, len(verification.Divergences)),
+	}
+	for i, divergence := range verification.Divergences {
+		response.Divergences[i] = &pb.SealDivergence{
+			Component: divergence.Component,
+			Filename:  divergence.Filename,
+			Sealed:    divergence.Sealed,
+			Current:   divergence.Current,
+		}
+	}
+
+	s.logger.Info("gRPC VerifyFeedbackSeal completed", "feedback_id", req.FeedbackId, "intact", response.Intact, "divergences", len(response.Divergences))
+	return response, nil
+}
+
+// convertToProtoSeal converts a feedback seal to its protobuf message
+func convertToProtoSeal(seal *models.FeedbackSeal) *pb.FeedbackSeal {
+	return &pb.FeedbackSeal{
+		SealId:         seal.ID,
+		FeedbackId:     seal.FeedbackID.String(),
+		ActorId:        seal.ActorID,
+		Manifest:       seal.Manifest,
+		ManifestSha256: seal.ManifestSHA256,
+		PreviousSha256: seal.PreviousSHA256,
+		ChainSha256:    seal.ChainSHA256,
+		SealedAt:       timestamppb.New(seal.SealedAt),
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/leader"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoBackgroundJob converts a job leadership status to protobuf BackgroundJob
+func convertToProtoBackgroundJob(status leader.Status) *pb.BackgroundJob {
+	return &pb.BackgroundJob{
+		Name:         status.Job,
+		Leader:       status.Leader,
+		Since:        timestamppb.New(status.Since),
+		Acquisitions: status.Acquisitions,
+		LastError:    status.LastError,
+	}
+}
+
+// GetBackgroundJobs reports which singleton background jobs the serving replica leads (admin only)
+func (s *FeedbackServer) GetBackgroundJobs(ctx context.Context, req *pb.GetBackgroundJobsRequest) (*pb.GetBackgroundJobsResponse, error) {
+	s.logger.Info("gRPC GetBackgroundJobs received")
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	statuses := s.jobs.Statuses()
+	response := &pb.GetBackgroundJobsResponse{
+		Replica:         s.jobs.Replica(),
+		ElectionEnabled: s.jobs.Enabled(),
+		Jobs:            make([]*pb.BackgroundJob, len(statuses)),
+	}
+	for i, status := range statuses {
+		response.Jobs[i] = convertToProtoBackgroundJob(status)
+	}
+
+	s.logger.Info("gRPC GetBackgroundJobs completed", "replica", response.Replica, "jobs", len(response.Jobs))
+	return response, nil
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServerOptions returns the options of the gRPC server: message limits, the interceptor chains
+// and keepalive. Aged connections are asked to reconnect but, unless a grace is configured,
+// wait for their streams, which end on their own or once idle.
+func ServerOptions(flagRegistry *flags.Registry, ka config.KeepaliveConfig) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(32 * 1024 * 1024), // 32MB max message size for file uploads
+		grpc.MaxSendMsgSize(32 * 1024 * 1024), // 32MB max message size for file downloads
+		grpc.ChainUnaryInterceptor(middleware.UnaryServerInterceptor(), flagRegistry.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(middleware.StreamingServerInterceptor(), flagRegistry.StreamServerInterceptor(), middleware.StreamIdleInterceptor(StreamIdleTimeouts(ka))),
+		grpc.ConnectionTimeout(30 * time.Second), // Connection timeout
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     ka.MaxConnectionIdle,     // Connection idle timeout (0 disables)
+			MaxConnectionAge:      ka.MaxConnectionAge,      // Max connection age (0 disables)
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace, // Grace period for connection age (0 waits for streams)
+			Time:                  ka.Time,                  // Keepalive ping interval
+			Timeout:               ka.Timeout,               // Keepalive ping timeout
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime, // Min time between keepalive pings
+			PermitWithoutStream: true,       // Allow keepalive without active streams
+		}),
+	}
+}
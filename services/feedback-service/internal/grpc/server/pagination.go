@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// feedbackCursorVersion is the payload version of feedback list page tokens
+const feedbackCursorVersion = 1
+
+// commentCursorVersion is the payload version of comment and reply list page tokens
+const commentCursorVersion = 1
+
+// maxPageLimit is the largest page size the list RPCs return
+const maxPageLimit = 100
+
+// legacyTotalCount returns a total count for the int32 total_count fields kept for older
+// clients, clamped to the int32 range, and whether it was clamped. Newer clients read
+// total_count64 instead.
+func legacyTotalCount(total int64) (int32, bool) {
+	if total > math.MaxInt32 {
+		return math.MaxInt32, true
+	}
+	return int32(total), false
+}
+
+// checkPagination rejects a negative page or a limit outside 0..maxPageLimit when
+// strict_limit_validation is enabled. Zero still selects the default. Otherwise the services
+// clamp out-of-range values, which hides client bugs.
+func checkPagination(ctx context.Context, page, limit int32) error {
+	if !flags.FromContext(ctx).Enabled(flags.StrictLimitValidation) {
+		return nil
+	}
+	if page < 0 {
+		return status.Error(codes.InvalidArgument, "page must not be negative")
+	}
+	if limit < 0 || limit > maxPageLimit {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("limit must be between 0 and %d", maxPageLimit))
+	}
+	return nil
+}
+
+// pageTokenError converts a rejected page token into InvalidArgument with the rejection reason.
+// It returns nil if err is not a page token error.
+func pageTokenError(err error) error {
+	var tokenErr *pagetoken.Error
+	if !errors.As(err, &tokenErr) {
+		return nil
+	}
+
+	return statusWithReason(codes.InvalidArgument, tokenErr.Error(), "INVALID_PAGE_TOKEN",
+		map[string]string{"reason": tokenErr.Reason})
+}
+
+// decodeFeedbackPageToken returns the cursor a feedback list continues after, nil without a
+// token. A token replaces the page number, so both cannot be sent together, and only continues
+// a list with the sort it was issued for.
+func (s *FeedbackServer) decodeFeedbackPageToken(token string, page int32, sort models.FeedbackSort) (*models.FeedbackCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if page > 1 {
+		return nil, status.Error(codes.InvalidArgument, "page and page_token cannot be combined")
+	}
+
+	decoded, err := s.pageTokens.Decode(token, pagetoken.KindFeedback, feedbackCursorVersion)
+	if err != nil {
+		return nil, pageTokenError(err)
+	}
+	var cursor models.FeedbackCursor
+	if err := decoded.Unmarshal(&cursor); err != nil {
+		return nil, pageTokenError(err)
+	}
+	if cursor.Sort.Field() != sort.Field() || cursor.Sort.Ascending != sort.Ascending {
+		return nil, statusWithReason(codes.InvalidArgument, "page_token was issued for a different sort order", "INVALID_PAGE_TOKEN",
+			map[string]string{"reason": "sort_mismatch"})
+	}
+	return &cursor, nil
+}
+
+// encodeFeedbackPageToken returns the page token of a feedback list cursor, empty on the last page
+func (s *FeedbackServer) encodeFeedbackPageToken(cursor *models.FeedbackCursor) (string, error) {
+	if cursor == nil {
+		return "", nil
+	}
+
+	token, err := s.pageTokens.Encode(pagetoken.KindFeedback, feedbackCursorVersion, cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode feedback page token: %w", err)
+	}
+	return token, nil
+}
+
+// commentDefaultSorts is the order of each comment list when a request sets none, which is also
+// the order of the tokens issued before comment lists could be sorted
+var commentDefaultSorts = map[pagetoken.Kind]string{
+	pagetoken.KindComment:      models.CommentSortNewest,
+	pagetoken.KindCommentReply: models.CommentSortOldest,
+}
+
+// decodeCommentPageToken returns the cursor a comment list of the given kind continues after,
+// nil without a token. A token replaces the page number, so both cannot be sent together, and
+// only continues a list with the sort it was issued for.
+func (s *commentServer) decodeCommentPageToken(token string, kind pagetoken.Kind, page int32, sort string) (*models.CommentCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if page > 1 {
+		return nil, status.Error(codes.InvalidArgument, "page and page_token cannot be combined")
+	}
+
+	decoded, err := s.pageTokens.Decode(token, kind, commentCursorVersion)
+	if err != nil {
+		return nil, pageTokenError(err)
+	}
+	var cursor models.CommentCursor
+	if err := decoded.Unmarshal(&cursor); err != nil {
+		return nil, pageTokenError(err)
+	}
+	if cursor.Sort == "" {
+		cursor.Sort = commentDefaultSorts[kind]
+	}
+	if cursor.Sort != sort {
+		return nil, statusWithReason(codes.InvalidArgument, "page_token was issued for a different sort order", "INVALID_PAGE_TOKEN",
+			map[string]string{"reason": "sort_mismatch"})
+	}
+	return &cursor, nil
+}
+
+// encodeCommentPageToken returns the page token of a comment list cursor, empty on the last page
+func (s *commentServer) encodeCommentPageToken(kind pagetoken.Kind, cursor *models.CommentCursor) (string, error) {
+	if cursor == nil {
+		return "", nil
+	}
+
+	token, err := s.pageTokens.Encode(kind, commentCursorVersion, cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode comment page token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+)
+
+// convertToProtoDecision converts the outcome of an authorization predicate to a PermissionDecision
+func convertToProtoDecision(err error) *pb.PermissionDecision {
+	if err == nil {
+		return &pb.PermissionDecision{Allowed: true}
+	}
+	decision := &pb.PermissionDecision{Message: err.Error()}
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		decision.Reason = appErr.Reason
+		decision.Message = appErr.Message
+	}
+	return decision
+}
+
+// GetPermissions reports which actions the principal may perform on a feedback, attachment or comment
+func (s *FeedbackServer) GetPermissions(ctx context.Context, req *pb.GetPermissionsRequest) (*pb.GetPermissionsResponse, error) {
+	s.logger.Info("gRPC GetPermissions received", "resource_name", req.ResourceName, "principal_id", req.PrincipalId)
+
+	decisions, err := s.permissions.GetPermissions(ctx, req.ResourceName, authz.FromContext(ctx, req.PrincipalId))
+	if err != nil {
+		s.logger.Error("Failed to get permissions", "resource_name", req.ResourceName, "error", err)
+		return nil, storageError(err, "failed to get permissions")
+	}
+
+	permissions := make(map[string]*pb.PermissionDecision, len(decisions))
+	for action, decision := range decisions {
+		permissions[action] = convertToProtoDecision(decision)
+	}
+	return &pb.GetPermissionsResponse{Permissions: permissions}, nil
+}
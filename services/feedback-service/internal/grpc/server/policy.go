@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoEffectivePolicy converts model EffectivePolicy to protobuf EffectivePolicy
+func convertToProtoEffectivePolicy(policy models.EffectivePolicy) *pb.EffectivePolicy {
+	return &pb.EffectivePolicy{
+		MaxAttachmentsPerFeedback:     int32(policy.MaxAttachmentsPerFeedback),
+		MaxAttachmentBytesPerFeedback: policy.MaxAttachmentBytesPerFeedback,
+		MaxCommentDepth:               policy.MaxCommentDepth,
+		CommentEditWindowSeconds:      int64(policy.CommentEditWindow.Seconds()),
+		AllowFeedbackLock:             policy.AllowFeedbackLock,
+	}
+}
+
+// convertToProtoCoursePolicy converts model CoursePolicy to protobuf CoursePolicy
+func (s *FeedbackServer) convertToProtoCoursePolicy(policy *models.CoursePolicy) *pb.CoursePolicy {
+	document, err := json.Marshal(policy.Overrides)
+	if err != nil {
+		s.logger.Error("Failed to encode course policy", "course_id", policy.CourseID, "error", err)
+	}
+	return &pb.CoursePolicy{
+		CourseId:  policy.CourseID,
+		Policy:    string(document),
+		UpdatedBy: policy.UpdatedBy,
+		CreatedAt: timestamppb.New(policy.CreatedAt),
+		UpdatedAt: timestamppb.New(policy.UpdatedAt),
+		Effective: convertToProtoEffectivePolicy(s.policies.Effective(policy)),
+	}
+}
+
+// coursePolicyError maps course policy errors to gRPC status errors
+func coursePolicyError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPolicy):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrCoursePolicyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// SetCoursePolicy creates or replaces the policy of a course (admin only)
+func (s *FeedbackServer) SetCoursePolicy(ctx context.Context, req *pb.SetCoursePolicyRequest) (*pb.CoursePolicy, error) {
+	s.logger.Info("gRPC SetCoursePolicy received", "course_id", req.CourseId, "actor_id", req.ActorId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC SetCoursePolicy: permission denied", "actor_id", req.ActorId)
+		return nil, err
+	}
+	if req.CourseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "course_id is required")
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+
+	policy, err := s.policies.SetCoursePolicy(ctx, req.CourseId, req.Policy, req.ActorId)
+	if err != nil {
+		s.logger.Warn("gRPC SetCoursePolicy failed", "course_id", req.CourseId, "error", err)
+		return nil, coursePolicyError(err)
+	}
+
+	s.logger.Info("gRPC SetCoursePolicy completed", "course_id", req.CourseId)
+	return s.convertToProtoCoursePolicy(policy), nil
+}
+
+// GetCoursePolicy returns the policy of a course (admin only)
+func (s *FeedbackServer) GetCoursePolicy(ctx context.Context, req *pb.GetCoursePolicyRequest) (*pb.CoursePolicy, error) {
+	s.logger.Info("gRPC GetCoursePolicy received", "course_id", req.CourseId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.CourseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "course_id is required")
+	}
+
+	policy, err := s.policies.GetCoursePolicy(ctx, req.CourseId)
+	if err != nil {
+		if !errors.Is(err, service.ErrCoursePolicyNotFound) {
+			s.logger.Error("gRPC GetCoursePolicy failed", "course_id", req.CourseId, "error", err)
+		}
+		return nil, coursePolicyError(err)
+	}
+
+	return s.convertToProtoCoursePolicy(policy), nil
+}
+
+// ListCoursePolicies returns every course policy and the global limits (admin only)
+func (s *FeedbackServer) ListCoursePolicies(ctx context.Context, req *pb.ListCoursePoliciesRequest) (*pb.ListCoursePoliciesResponse, error) {
+	s.logger.Info("gRPC ListCoursePolicies received")
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	policies, err := s.policies.ListCoursePolicies(ctx)
+	if err != nil {
+		s.logger.Error("gRPC ListCoursePolicies failed", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to list course policies: %v", err))
+	}
+
+	pbPolicies := make([]*pb.CoursePolicy, len(policies))
+	for i, policy := range policies {
+		pbPolicies[i] = s.convertToProtoCoursePolicy(policy)
+	}
+
+	s.logger.Info("gRPC ListCoursePolicies completed", "count", len(policies))
+	return &pb.ListCoursePoliciesResponse{
+		Policies: pbPolicies,
+		Defaults: convertToProtoEffectivePolicy(s.policies.Defaults()),
+	}, nil
+}
+
+// DeleteCoursePolicy removes the policy of a course (admin only)
+func (s *FeedbackServer) DeleteCoursePolicy(ctx context.Context, req *pb.DeleteCoursePolicyRequest) (*pb.DeleteCoursePolicyResponse, error) {
+	s.logger.Info("gRPC DeleteCoursePolicy received", "course_id", req.CourseId, "actor_id", req.ActorId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC DeleteCoursePolicy: permission denied", "actor_id", req.ActorId)
+		return nil, err
+	}
+	if req.CourseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "course_id is required")
+	}
+	if req.ActorId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "actor_id is required")
+	}
+
+	if err := s.policies.DeleteCoursePolicy(ctx, req.CourseId, req.ActorId); err != nil {
+		s.logger.Warn("gRPC DeleteCoursePolicy failed", "course_id", req.CourseId, "error", err)
+		return nil, coursePolicyError(err)
+	}
+
+	s.logger.Info("gRPC DeleteCoursePolicy completed", "course_id", req.CourseId)
+	return &pb.DeleteCoursePolicyResponse{Success: true}, nil
+}
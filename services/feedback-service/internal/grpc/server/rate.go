@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultRateWindow is the creation rate window used when since is not provided
+const defaultRateWindow = 24 * time.Hour
+
+// rateRequest resolves the optional bucket size and since/until timestamps of a creation rate request
+func rateRequest(bucketSize string, since, until *timestamppb.Timestamp) (string, time.Time, time.Time) {
+	if bucketSize == "" {
+		bucketSize = models.RateBucketHour
+	}
+	end := time.Now().UTC()
+	if until != nil {
+		end = until.AsTime()
+	}
+	start := end.Add(-defaultRateWindow)
+	if since != nil {
+		start = since.AsTime()
+	}
+	return bucketSize, start, end
+}
+
+// rateError maps creation rate errors to gRPC status codes
+func rateError(err error) error {
+	if errors.Is(err, service.ErrInvalidRateRange) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// GetFeedbackCreationRate returns feedbacks created per time bucket by a reviewer or for a submission (admin only)
+func (s *FeedbackServer) GetFeedbackCreationRate(ctx context.Context, req *pb.GetFeedbackCreationRateRequest) (*pb.GetFeedbackCreationRateResponse, error) {
+	s.logger.Info("gRPC GetFeedbackCreationRate received",
+		"reviewer_id", req.GetReviewerId(),
+		"submission_id", req.GetSubmissionId(),
+		"bucket_size", req.BucketSize,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var reviewerID, submissionID *int64
+	switch scope := req.Scope.(type) {
+	case *pb.GetFeedbackCreationRateRequest_ReviewerId:
+		if scope.ReviewerId <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "reviewer_id must be positive")
+		}
+		reviewerID = &scope.ReviewerId
+	case *pb.GetFeedbackCreationRateRequest_SubmissionId:
+		if scope.SubmissionId <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "submission_id must be positive")
+		}
+		submissionID = &scope.SubmissionId
+	default:
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id or submission_id is required")
+	}
+
+	bucketSize, since, until := rateRequest(req.BucketSize, req.Since, req.Until)
+	buckets, err := s.feedbackService.GetFeedbackCreationRate(ctx, reviewerID, submissionID, bucketSize, since, until)
+	if err != nil {
+		s.logger.Error("gRPC GetFeedbackCreationRate failed", "error", err)
+		return nil, rateError(err)
+	}
+
+	response := &pb.GetFeedbackCreationRateResponse{Buckets: make([]*pb.CreationRateBucket, len(buckets))}
+	for i, bucket := range buckets {
+		response.Buckets[i] = &pb.CreationRateBucket{Start: timestamppb.New(bucket.Start), Count: bucket.Count}
+		response.TotalCount += bucket.Count
+	}
+
+	s.logger.Info("gRPC GetFeedbackCreationRate completed", "buckets", len(buckets), "total_count", response.TotalCount)
+	return response, nil
+}
+
+// GetCommentCreationRate returns comments created per time bucket on a content (admin only)
+func (s *commentServer) GetCommentCreationRate(ctx context.Context, req *pb.GetCommentCreationRateRequest) (*pb.GetCommentCreationRateResponse, error) {
+	s.logger.Info("gRPC GetCommentCreationRate received",
+		"content_id", req.ContentId,
+		"type", req.Type,
+		"bucket_size", req.BucketSize,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.ContentId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "content_id is required")
+	}
+	if req.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "type is required")
+	}
+
+	bucketSize, since, until := rateRequest(req.BucketSize, req.Since, req.Until)
+	buckets, err := s.commentService.GetCommentCreationRate(ctx, req.ContentId, req.Type, bucketSize, since, until)
+	if err != nil {
+		s.logger.Error("gRPC GetCommentCreationRate failed", "content_id", req.ContentId, "error", err)
+		return nil, rateError(err)
+	}
+
+	response := &pb.GetCommentCreationRateResponse{Buckets: make([]*pb.CommentRateBucket, len(buckets))}
+	for i, bucket := range buckets {
+		response.Buckets[i] = &pb.CommentRateBucket{Start: timestamppb.New(bucket.Start), Count: bucket.Count}
+		response.TotalCount += bucket.Count
+	}
+
+	s.logger.Info("gRPC GetCommentCreationRate completed", "buckets", len(buckets), "total_count", response.TotalCount)
+	return response, nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReconcileFeedback writes feedback content pending in the content outbox to MongoDB (admin only)
+func (s *FeedbackServer) ReconcileFeedback(ctx context.Context, req *pb.ReconcileFeedbackRequest) (*pb.ReconcileFeedbackResponse, error) {
+	s.logger.Info("gRPC ReconcileFeedback received", "feedback_id", req.FeedbackId)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		s.logger.Warn("gRPC ReconcileFeedback: permission denied")
+		return nil, err
+	}
+	id := uuid.Nil
+	if req.FeedbackId != "" {
+		var err error
+		if id, err = resourcename.ParseFeedbackID(req.FeedbackId); err != nil {
+			s.logger.Warn("gRPC ReconcileFeedback: invalid ID format", "feedback_id", req.FeedbackId, "error", err)
+			return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+		}
+	}
+
+	result, err := s.feedbackService.ReconcileFeedback(ctx, id)
+	if err != nil {
+		s.logger.Error("gRPC ReconcileFeedback failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to reconcile feedback content: %v", err))
+	}
+
+	s.logger.Info("gRPC ReconcileFeedback completed",
+		"feedback_id", req.FeedbackId,
+		"applied", result.Applied,
+		"failed", result.Failed,
+		"remaining", result.Remaining,
+	)
+	return &pb.ReconcileFeedbackResponse{
+		Applied:   int32(result.Applied),
+		Failed:    int32(result.Failed),
+		Remaining: result.Remaining,
+	}, nil
+}
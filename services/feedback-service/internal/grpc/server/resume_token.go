@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+)
+
+// resumeTokenVersion is the payload version of event stream resume tokens
+const resumeTokenVersion = 1
+
+// resumeToken is the payload of an event stream resume token. Positions are only meaningful
+// on the stream they were sent on, so the token names it.
+type resumeToken struct {
+	Stream   string                `json:"s"`
+	Position models.StreamPosition `json:"p"`
+}
+
+// decodeResumeToken returns the position a stream resumes after, nil without a token. A token
+// that outlived the page token TTL is past any replay window, so it is reported as such.
+func decodeResumeToken(codec *pagetoken.Codec, stream, token string) (*models.StreamPosition, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	decoded, err := codec.Decode(token, pagetoken.KindEventStream, resumeTokenVersion)
+	if err != nil {
+		var tokenErr *pagetoken.Error
+		if errors.As(err, &tokenErr) && tokenErr.Reason == pagetoken.ReasonExpired {
+			return nil, storageError(service.ErrReplayWindowExceeded, "")
+		}
+		return nil, resumeTokenError(err)
+	}
+	var payload resumeToken
+	if err := decoded.Unmarshal(&payload); err != nil {
+		return nil, resumeTokenError(err)
+	}
+	if payload.Stream != stream {
+		return nil, statusWithReason(codes.InvalidArgument, "resume_after was issued for a different stream", "INVALID_RESUME_TOKEN",
+			map[string]string{"reason": "stream_mismatch"})
+	}
+	return &payload.Position, nil
+}
+
+// resumeTokenError converts a rejected resume token into InvalidArgument with the rejection reason
+func resumeTokenError(err error) error {
+	var tokenErr *pagetoken.Error
+	if !errors.As(err, &tokenErr) {
+		return statusWithReason(codes.InvalidArgument, err.Error(), "INVALID_RESUME_TOKEN", nil)
+	}
+	return statusWithReason(codes.InvalidArgument, tokenErr.Error(), "INVALID_RESUME_TOKEN",
+		map[string]string{"reason": tokenErr.Reason})
+}
+
+// encodeResumeToken returns the resume token of an event position on a stream
+func encodeResumeToken(codec *pagetoken.Codec, stream string, position models.StreamPosition) (string, error) {
+	token, err := codec.Encode(pagetoken.KindEventStream, resumeTokenVersion, resumeToken{Stream: stream, Position: position})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode resume token: %w", err)
+	}
+	return token, nil
+}
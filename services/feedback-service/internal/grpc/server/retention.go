@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoRetentionResult converts model RetentionResult to protobuf RetentionResult
+func convertToProtoRetentionResult(result *models.RetentionResult) *pb.RetentionResult {
+	pbResult := &pb.RetentionResult{
+		Dataset:    result.Dataset,
+		Disabled:   result.Disabled,
+		Pruned:     result.Pruned,
+		Protected:  result.Protected,
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	if !result.Cutoff.IsZero() {
+		pbResult.Cutoff = timestamppb.New(result.Cutoff)
+	}
+	if result.Oldest != nil {
+		pbResult.OldestRemaining = timestamppb.New(*result.Oldest)
+	}
+	if result.Err != nil {
+		pbResult.Error = result.Err.Error()
+	}
+	return pbResult
+}
+
+// RunRetention prunes expired maintenance data of one or all datasets (admin only)
+func (s *FeedbackServer) RunRetention(ctx context.Context, req *pb.RunRetentionRequest) (*pb.RunRetentionResponse, error) {
+	s.logger.Info("gRPC RunRetention received",
+		"dataset", req.Dataset,
+		"dry_run", req.DryRun,
+		"force", req.Force,
+	)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var results []*models.RetentionResult
+	if req.Dataset == "" {
+		results = s.retention.RunAll(ctx, req.DryRun, req.Force)
+	} else {
+		result, err := s.retention.RunDataset(ctx, req.Dataset, req.DryRun, req.Force)
+		if errors.Is(err, service.ErrUnknownRetentionDataset) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if result == nil {
+			s.logger.Error("gRPC RunRetention failed", "dataset", req.Dataset, "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to run retention: %v", err))
+		}
+		results = append(results, result)
+	}
+
+	response := &pb.RunRetentionResponse{Results: make([]*pb.RetentionResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = convertToProtoRetentionResult(result)
+	}
+
+	s.logger.Info("gRPC RunRetention completed", "datasets", len(results))
+	return response, nil
+}
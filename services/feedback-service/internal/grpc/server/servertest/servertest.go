@@ -0,0 +1,177 @@
+// Package servertest runs the feedback-service gRPC server in-process for tests. The server is
+// wired as cmd/feedback-service wires it, over an in-memory listener and on the repositories of
+// a repotest.Store, so tests call it through the generated clients and can seed or break its
+// storage underneath.
+package servertest
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/flags"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/grpc/server"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/leader"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/notification"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/pagetoken"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/render"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository/repotest"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufferSize is the size of the in-memory connection buffer
+const bufferSize = 1024 * 1024
+
+// Server is a running in-process server with clients connected to it
+type Server struct {
+	Store    *repotest.Store
+	Config   *config.Config
+	Feedback pb.FeedbackServiceClient
+	Comments pb.CommentServiceClient
+
+	FeedbackService *service.FeedbackService
+	CommentService  *service.CommentService
+}
+
+// New starts a server for the duration of a test. The configuration is loaded from the
+// environment with a page token secret set, leader election and upstream validation off and
+// comment streams polled every 10ms; configure, if given, adjusts it before the server is built.
+// New sets environment variables, so tests using it cannot run in parallel.
+func New(t testing.TB, configure func(*config.Config)) *Server {
+	t.Helper()
+	t.Setenv("PAGE_TOKEN_SECRET", "servertest-page-token-secret")
+	t.Setenv("LEADER_ELECTION_ENABLED", "false")
+	t.Setenv("FEEDBACK_UPSTREAM_VALIDATION", "false")
+	t.Setenv("COMMENT_WATCH_POLL_INTERVAL", "10ms")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+
+	logger := slog.New(slog.DiscardHandler)
+	store := repotest.New()
+	notifications, err := notification.NewRenderer(cfg.Notification.TemplateDir, cfg.Notification.MaxContentChars)
+	if err != nil {
+		t.Fatalf("notification.NewRenderer() error = %v", err)
+	}
+	flagRegistry, err := flags.New(cfg.Flags, logger)
+	if err != nil {
+		t.Fatalf("flags.New() error = %v", err)
+	}
+
+	events := bus.New(logger)
+	policies := service.NewCoursePolicyService(store.Policies(), cfg.Comments, logger)
+	feedbackService := service.NewFeedbackService(service.FeedbackServiceDeps{
+		FeedbackRepo:    store.Feedbacks(),
+		AttachmentRepo:  store.Attachments(),
+		AssetRepo:       store.Assets(),
+		AuditRepo:       store.Audit(),
+		SessionRepo:     store.Sessions(),
+		IntentRepo:      store.Intents(),
+		BackfillJournal: store.Backfill(),
+		SealRepo:        store.Seals(),
+		OutboxRepo:      store.Outbox(),
+		CommentRepo:     store.Comments(),
+		Policies:        policies,
+		Upstream:        service.NewUpstreamValidator(cfg.Upstream, nil, nil, logger),
+		Events:          events,
+		DeletionCfg:     cfg.Deletion,
+		PrefetchCfg:     cfg.Prefetch,
+		DashboardCfg:    cfg.Dashboard,
+		MetadataCfg:     cfg.Metadata,
+		OutboxCfg:       cfg.Outbox,
+		ArchiveCfg:      cfg.Archive,
+		EventsCfg:       cfg.Events,
+		Limits:          cfg.Feedback,
+		IdempotencyTTL:  cfg.Retention.IdempotencyKeys,
+		Logger:          logger,
+	})
+	commentService := service.NewCommentService(store.Comments(), cfg.Comments, policies, feedbackService, events, logger)
+	renderer := render.NewRenderer(cfg.Render.MaxOutputBytes, cfg.Render.CacheEntries)
+	pageTokens := pagetoken.NewCodec([]byte(cfg.PageToken.Secret), cfg.PageToken.TTL)
+	if err := service.SubscribeSideEffects(events, feedbackService, renderer); err != nil {
+		t.Fatalf("SubscribeSideEffects() error = %v", err)
+	}
+
+	s := grpc.NewServer(server.ServerOptions(flagRegistry, cfg.Keepalive)...)
+	server.RegisterFeedbackServer(s, server.FeedbackServerDeps{
+		FeedbackService: feedbackService,
+		Transfers:       service.NewTransferAccountant(store.Transfers(), cfg.Transfer, logger),
+		Retention:       service.NewRetentionService(store.Retention(), store.Attachments(), cfg.Retention, logger),
+		Policies:        policies,
+		Permissions:     service.NewPermissionService(store.Feedbacks(), store.Comments(), policies, cfg.Comments, logger),
+		Templates:       service.NewTemplateService(store.Templates(), cfg.Feedback, logger),
+		Notifications:   notifications,
+		PageTokens:      pageTokens,
+		Jobs:            leader.New(nil, cfg.Leader, logger),
+		Flags:           flagRegistry,
+		Downloads:       cfg.Download,
+		Limits:          cfg.Feedback,
+		Logger:          logger,
+	})
+	server.RegisterCommentServer(s, commentService, renderer, cfg.Comments.AcceptHexIDs, cfg.Comments.ListContentMaxBytes, pageTokens, logger)
+
+	listener := bufconn.Listen(bufferSize)
+	go s.Serve(listener)
+
+	// Comment streams are fed by the watch worker; the leader's workers are left to the tests,
+	// which run them through their RPCs
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		commentService.RunCommentWatch(watchCtx)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		feedbackService.CloseEventStreams()
+		commentService.CloseCommentStreams()
+		conn.Close()
+		s.Stop()
+		stopWatch()
+		<-watchDone
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		events.Close(ctx)
+	})
+
+	return &Server{
+		Store:           store,
+		Config:          cfg,
+		Feedback:        pb.NewFeedbackServiceClient(conn),
+		Comments:        pb.NewCommentServiceClient(conn),
+		FeedbackService: feedbackService,
+		CommentService:  commentService,
+	}
+}
+
+// Admin returns ctx carrying the metadata of a request made by an administrator
+func Admin(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, middleware.UserRoleMetadataKey, middleware.AdminRole)
+}
+
+// Internal returns ctx carrying the metadata of a request made by an internal service
+func Internal(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, middleware.CallerClassMetadataKey, middleware.InternalCallerClass)
+}
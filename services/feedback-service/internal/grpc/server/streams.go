@@ -0,0 +1,21 @@
+package server
+
+import (
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+)
+
+// StreamIdleTimeouts returns the idle timeout of every streaming RPC by its class. Transfers
+// move a message at least every chunk; reports may compute for a long time between messages.
+func StreamIdleTimeouts(cfg config.KeepaliveConfig) map[string]time.Duration {
+	return map[string]time.Duration{
+		pb.FeedbackService_UploadAttachment_FullMethodName:        cfg.TransferIdleTimeout,
+		pb.FeedbackService_DownloadAttachment_FullMethodName:      cfg.TransferIdleTimeout,
+		pb.FeedbackService_ExportReviewerFeedbacks_FullMethodName: cfg.TransferIdleTimeout,
+		pb.CommentService_GetCommentContent_FullMethodName:        cfg.TransferIdleTimeout,
+		pb.CommentService_ImportComments_FullMethodName:           cfg.TransferIdleTimeout,
+		pb.FeedbackService_ConsistencyReport_FullMethodName:       cfg.ReportIdleTimeout,
+	}
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoTemplate converts model FeedbackTemplate to protobuf FeedbackTemplate
+func convertToProtoTemplate(template *models.FeedbackTemplate) *pb.FeedbackTemplate {
+	return &pb.FeedbackTemplate{
+		Id:         template.ID.String(),
+		ReviewerId: template.ReviewerID,
+		Name:       template.Name,
+		Title:      template.Title,
+		Content:    template.Content,
+		CreatedAt:  timestamppb.New(template.CreatedAt),
+		UpdatedAt:  timestamppb.New(template.UpdatedAt),
+	}
+}
+
+// CreateTemplate stores a feedback template for a reviewer
+func (s *FeedbackServer) CreateTemplate(ctx context.Context, req *pb.CreateTemplateRequest) (*pb.FeedbackTemplate, error) {
+	s.logger.Info("gRPC CreateTemplate received", "reviewer_id", req.ReviewerId, "name", req.Name)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if err := service.CheckTextLimits(s.limits, &req.Title, &req.Content); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+
+	template := &models.FeedbackTemplate{
+		ReviewerID: req.ReviewerId,
+		Name:       req.Name,
+		Title:      req.Title,
+		Content:    req.Content,
+	}
+	if err := s.templates.CreateTemplate(ctx, template); err != nil {
+		s.logger.Warn("gRPC CreateTemplate failed", "reviewer_id", req.ReviewerId, "error", err)
+		return nil, storageError(err, "failed to create template")
+	}
+
+	s.logger.Info("gRPC CreateTemplate completed", "id", template.ID)
+	return convertToProtoTemplate(template), nil
+}
+
+// ListTemplates lists a reviewer's feedback templates
+func (s *FeedbackServer) ListTemplates(ctx context.Context, req *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	s.logger.Info("gRPC ListTemplates received", "reviewer_id", req.ReviewerId, "page", req.Page, "limit", req.Limit)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if err := checkPagination(ctx, req.Page, req.Limit); err != nil {
+		return nil, err
+	}
+
+	templates, totalCount, err := s.templates.ListTemplates(ctx, req.ReviewerId, req.Page, req.Limit)
+	if err != nil {
+		s.logger.Error("gRPC ListTemplates failed", "reviewer_id", req.ReviewerId, "error", err)
+		return nil, storageError(err, "failed to list templates")
+	}
+
+	response := &pb.ListTemplatesResponse{
+		Templates:  make([]*pb.FeedbackTemplate, len(templates)),
+		TotalCount: totalCount,
+	}
+	for i, template := range templates {
+		response.Templates[i] = convertToProtoTemplate(template)
+	}
+
+	s.logger.Info("gRPC ListTemplates completed", "reviewer_id", req.ReviewerId, "count", len(templates), "total_count", totalCount)
+	return response, nil
+}
+
+// UpdateTemplate replaces the name, title and content of a template (owning reviewer only)
+func (s *FeedbackServer) UpdateTemplate(ctx context.Context, req *pb.UpdateTemplateRequest) (*pb.FeedbackTemplate, error) {
+	s.logger.Info("gRPC UpdateTemplate received", "id", req.Id, "reviewer_id", req.ReviewerId)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC UpdateTemplate: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid template ID format")
+	}
+	if err := service.CheckTextLimits(s.limits, &req.Title, &req.Content); err != nil {
+		return nil, apperr.ToStatus(err)
+	}
+
+	template, err := s.templates.UpdateTemplate(ctx, &models.FeedbackTemplate{
+		ID:         id,
+		ReviewerID: req.ReviewerId,
+		Name:       req.Name,
+		Title:      req.Title,
+		Content:    req.Content,
+	})
+	if err != nil {
+		s.logger.Warn("gRPC UpdateTemplate failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to update template")
+	}
+
+	s.logger.Info("gRPC UpdateTemplate completed", "id", req.Id)
+	return convertToProtoTemplate(template), nil
+}
+
+// DeleteTemplate deletes a template (owning reviewer only)
+func (s *FeedbackServer) DeleteTemplate(ctx context.Context, req *pb.DeleteTemplateRequest) (*pb.DeleteTemplateResponse, error) {
+	s.logger.Info("gRPC DeleteTemplate received", "id", req.Id, "reviewer_id", req.ReviewerId)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		s.logger.Warn("gRPC DeleteTemplate: invalid ID format", "id", req.Id, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid template ID format")
+	}
+
+	if err := s.templates.DeleteTemplate(ctx, id, req.ReviewerId); err != nil {
+		s.logger.Warn("gRPC DeleteTemplate failed", "id", req.Id, "error", err)
+		return nil, storageError(err, "failed to delete template")
+	}
+
+	s.logger.Info("gRPC DeleteTemplate completed", "id", req.Id)
+	return &pb.DeleteTemplateResponse{Success: true}, nil
+}
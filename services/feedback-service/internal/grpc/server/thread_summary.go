@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoThreadSummary converts a comment thread summary to protobuf format
+func convertToProtoThreadSummary(summary *models.FeedbackThreadSummary) *pb.FeedbackThreadSummary {
+	pbSummary := &pb.FeedbackThreadSummary{
+		CommentCount:            summary.CommentCount,
+		UnansweredQuestionCount: summary.UnansweredQuestions,
+	}
+	if !summary.LastActivityAt.IsZero() {
+		pbSummary.LastActivityAt = timestamppb.New(summary.LastActivityAt)
+	}
+	return pbSummary
+}
+
+// attachThreadSummaries fills thread_summary of converted feedbacks, pbFeedbacks[i] being the
+// conversion of feedbacks[i]. The summaries are left unset when comments are unavailable.
+func (s *FeedbackServer) attachThreadSummaries(ctx context.Context, feedbacks []*models.Feedback, pbFeedbacks []*pb.Feedback) {
+	summaries := s.feedbackService.FeedbackThreadSummaries(ctx, feedbacks)
+	for i, feedback := range feedbacks {
+		if summary := summaries[feedback.ID]; summary != nil {
+			pbFeedbacks[i].ThreadSummary = convertToProtoThreadSummary(summary)
+		}
+	}
+}
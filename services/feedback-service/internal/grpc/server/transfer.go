@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/middleware"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultTransferWindow is the reporting window used when since is not provided
+const defaultTransferWindow = 30 * 24 * time.Hour
+
+// transferQuotaError converts a quota error into ResourceExhausted with a machine-readable reason
+func transferQuotaError(err error) error {
+	return statusWithReason(codes.ResourceExhausted, err.Error(), "TRANSFER_QUOTA_EXCEEDED", nil)
+}
+
+// transferRange resolves the optional since/until timestamps of a usage request
+func transferRange(since, until *timestamppb.Timestamp) (time.Time, time.Time, error) {
+	end := time.Now().UTC()
+	if until != nil {
+		end = until.AsTime()
+	}
+	start := end.Add(-defaultTransferWindow)
+	if since != nil {
+		start = since.AsTime()
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, status.Error(codes.InvalidArgument, "since must not be after until")
+	}
+	return start, end, nil
+}
+
+// convertToProtoTransferUsage converts model TransferUsage to protobuf TransferUsage
+func convertToProtoTransferUsage(usage *models.TransferUsage) *pb.TransferUsage {
+	return &pb.TransferUsage{
+		PrincipalId:     usage.PrincipalID,
+		Day:             timestamppb.New(usage.Day),
+		BytesUploaded:   usage.BytesUploaded,
+		BytesDownloaded: usage.BytesDownloaded,
+	}
+}
+
+// GetTransferUsage returns daily transfer counters for a principal
+func (s *FeedbackServer) GetTransferUsage(ctx context.Context, req *pb.GetTransferUsageRequest) (*pb.GetTransferUsageResponse, error) {
+	s.logger.Info("gRPC GetTransferUsage received", "principal_id", req.PrincipalId)
+
+	if req.PrincipalId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "principal_id is required")
+	}
+
+	since, until, err := transferRange(req.Since, req.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.transfers.GetUsage(ctx, req.PrincipalId, since, until)
+	if err != nil {
+		s.logger.Error("gRPC GetTransferUsage failed", "principal_id", req.PrincipalId, "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get transfer usage: %v", err))
+	}
+
+	response := &pb.GetTransferUsageResponse{Days: make([]*pb.TransferUsage, len(usage))}
+	for i, u := range usage {
+		response.Days[i] = convertToProtoTransferUsage(u)
+		response.TotalBytesUploaded += u.BytesUploaded
+		response.TotalBytesDownloaded += u.BytesDownloaded
+	}
+
+	s.logger.Info("gRPC GetTransferUsage completed", "principal_id", req.PrincipalId, "days", len(usage))
+	return response, nil
+}
+
+// GetTransferLeaderboard returns the principals with the most bytes transferred (admin only)
+func (s *FeedbackServer) GetTransferLeaderboard(ctx context.Context, req *pb.GetTransferLeaderboardRequest) (*pb.GetTransferLeaderboardResponse, error) {
+	s.logger.Info("gRPC GetTransferLeaderboard received", "limit", req.Limit)
+
+	if err := middleware.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	since, until, err := transferRange(req.Since, req.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := s.transfers.GetLeaderboard(ctx, since, until, int(req.Limit))
+	if err != nil {
+		s.logger.Error("gRPC GetTransferLeaderboard failed", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get transfer leaderboard: %v", err))
+	}
+
+	response := &pb.GetTransferLeaderboardResponse{Principals: make([]*pb.TransferUsage, len(usage))}
+	for i, u := range usage {
+		response.Principals[i] = convertToProtoTransferUsage(u)
+	}
+
+	s.logger.Info("gRPC GetTransferLeaderboard completed", "count", len(usage))
+	return response, nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// convertToProtoUploadSession converts model UploadSession to protobuf UploadSession
+func convertToProtoUploadSession(session *models.UploadSession) *pb.UploadSession {
+	pbSession := &pb.UploadSession{
+		Id:         session.ID.String(),
+		FeedbackId: session.FeedbackID.String(),
+		ReviewerId: session.ReviewerID,
+		Status:     session.Status,
+		Files:      make([]*pb.UploadSessionFile, len(session.Files)),
+		CreatedAt:  timestamppb.New(session.CreatedAt),
+		UpdatedAt:  timestamppb.New(session.UpdatedAt),
+	}
+	for i, file := range session.Files {
+		pbSession.Files[i] = &pb.UploadSessionFile{
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			Completed:   file.Completed,
+			Promoted:    file.Promoted,
+		}
+	}
+	return pbSession
+}
+
+// uploadSessionError maps upload session errors to gRPC status codes
+func uploadSessionError(err error, action string) error {
+	switch {
+	case errors.Is(err, service.ErrFeedbackLocked), errors.Is(err, service.ErrNotFeedbackAuthor):
+		return apperr.ToStatus(err)
+	case errors.Is(err, service.ErrAttachmentLimitExceeded):
+		return statusWithReason(codes.FailedPrecondition, err.Error(), "ATTACHMENT_LIMIT_EXCEEDED", nil)
+	case errors.Is(err, service.ErrUploadSessionClosed), errors.Is(err, service.ErrUploadSessionIncomplete):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, fmt.Sprintf("failed to %s upload session: %v", action, err))
+	}
+}
+
+// parseSessionRequest validates the reviewer and session ID shared by session requests
+func parseSessionRequest(reviewerID int64, sessionID string) (uuid.UUID, error) {
+	if reviewerID <= 0 {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	if sessionID == "" {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid session ID format")
+	}
+	return id, nil
+}
+
+// CreateUploadSession opens a session for attaching several files at once (reviewer only).
+// Files are uploaded with UploadAttachment and session_id set, then attached together by
+// CommitUploadSession or discarded by AbortUploadSession.
+func (s *FeedbackServer) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.UploadSession, error) {
+	s.logger.Info("gRPC CreateUploadSession received",
+		"reviewer_id", req.ReviewerId,
+		"feedback_id", req.FeedbackId,
+	)
+
+	if req.ReviewerId <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "reviewer_id is required")
+	}
+	feedbackID, err := resourcename.ParseFeedbackID(req.FeedbackId)
+	if err != nil {
+		s.logger.Warn("gRPC CreateUploadSession: invalid feedback ID format", "feedback_id", req.FeedbackId, "error", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid feedback ID format")
+	}
+
+	session, err := s.feedbackService.CreateUploadSession(ctx, feedbackID, req.ReviewerId)
+	if err != nil {
+		s.logger.Error("gRPC CreateUploadSession failed", "feedback_id", req.FeedbackId, "error", err)
+		return nil, uploadSessionError(err, "create")
+	}
+
+	s.logger.Info("gRPC CreateUploadSession completed", "session_id", session.ID)
+	return convertToProtoUploadSession(session), nil
+}
+
+// CommitUploadSession attaches all files of a session to its feedback, or none of them (reviewer only)
+func (s *FeedbackServer) CommitUploadSession(ctx context.Context, req *pb.CommitUploadSessionRequest) (*pb.UploadSession, error) {
+	s.logger.Info("gRPC CommitUploadSession received",
+		"reviewer_id", req.ReviewerId,
+		"session_id", req.SessionId,
+	)
+
+	sessionID, err := parseSessionRequest(req.ReviewerId, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.feedbackService.CommitUploadSession(ctx, sessionID, req.ReviewerId)
+	if err != nil {
+		s.logger.Error("gRPC CommitUploadSession failed", "session_id", req.SessionId, "error", err)
+		return nil, uploadSessionError(err, "commit")
+	}
+
+	s.logger.Info("gRPC CommitUploadSession completed", "session_id", session.ID, "files", len(session.Files))
+	return convertToProtoUploadSession(session), nil
+}
+
+// AbortUploadSession discards all files staged in a session (reviewer only)
+func (s *FeedbackServer) AbortUploadSession(ctx context.Context, req *pb.AbortUploadSessionRequest) (*pb.AbortUploadSessionResponse, error) {
+	s.logger.Info("gRPC AbortUploadSession received",
+		"reviewer_id", req.ReviewerId,
+		"session_id", req.SessionId,
+	)
+
+	sessionID, err := parseSessionRequest(req.ReviewerId, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.feedbackService.AbortUploadSession(ctx, sessionID, req.ReviewerId); err != nil {
+		s.logger.Error("gRPC AbortUploadSession failed", "session_id", req.SessionId, "error", err)
+		return nil, uploadSessionError(err, "abort")
+	}
+
+	s.logger.Info("gRPC AbortUploadSession completed", "session_id", req.SessionId)
+	return &pb.AbortUploadSessionResponse{Success: true}, nil
+}
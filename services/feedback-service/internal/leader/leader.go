@@ -0,0 +1,270 @@
+// Package leader runs singleton background jobs on one replica at a time.
+//
+// Leadership of a job is a PostgreSQL session-level advisory lock held on a dedicated
+// connection for as long as the job runs. Replicas that do not get the lock stand by and try
+// again periodically. The leader checks that it still holds the lock on every heartbeat and
+// stops its job when the check fails, e.g. because the connection broke and the server ended
+// the session. On a clean stop the lock is released explicitly so a standby takes over on its
+// next attempt.
+//
+// Leadership narrows but cannot rule out overlap: a leader cut off from the database keeps
+// running until its next heartbeat. Jobs must stay safe to run concurrently; the lock only
+// keeps replicas from repeating the same work.
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+)
+
+// lockNamespace is prepended to job names before they are hashed into advisory lock keys, so
+// the keys do not collide with advisory locks taken by other applications on the database
+const lockNamespace = "feedback-service/"
+
+// releaseTimeout bounds the unlock on a clean stop, which runs after the job context ended
+const releaseTimeout = 5 * time.Second
+
+// Status is the leadership of one job on this replica
+type Status struct {
+	Job          string    `json:"job"`
+	Leader       bool      `json:"leader"`
+	Since        time.Time `json:"since"`        // When this replica became leader or started standing by
+	Acquisitions int64     `json:"acquisitions"` // Times this replica acquired leadership since startup
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// Elector acquires and holds job leadership for this replica
+type Elector struct {
+	db      *sql.DB
+	cfg     config.LeaderConfig
+	replica string
+	logger  *slog.Logger
+
+	mu   sync.Mutex
+	jobs map[string]*Status
+}
+
+// New creates an elector that takes advisory locks on db
+func New(db *sql.DB, cfg config.LeaderConfig, logger *slog.Logger) *Elector {
+	replica, err := os.Hostname()
+	if err != nil {
+		replica = "unknown"
+	}
+	return &Elector{
+		db:      db,
+		cfg:     cfg,
+		replica: replica,
+		logger:  logger,
+		jobs:    make(map[string]*Status),
+	}
+}
+
+// Replica returns the name this replica is known by in logs and statuses
+func (e *Elector) Replica() string {
+	return e.replica
+}
+
+// Enabled reports whether jobs are led by one replica rather than run on every replica
+func (e *Elector) Enabled() bool {
+	return e.cfg.Enabled
+}
+
+// lockKey returns the advisory lock key of a job
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(lockNamespace + name))
+	return int64(h.Sum64())
+}
+
+// RunWhileLeader runs fn while this replica is the leader of the named job, and stands by
+// otherwise. The context passed to fn is canceled when leadership is lost; fn is started again
+// once leadership is regained. RunWhileLeader returns when ctx is done or when fn returns on
+// its own while leading. With leader election disabled, fn simply runs on every replica.
+func (e *Elector) RunWhileLeader(ctx context.Context, name string, fn func(ctx context.Context)) {
+	e.setStatus(name, false, nil)
+	if !e.cfg.Enabled {
+		e.setStatus(name, true, nil)
+		fn(ctx)
+		e.setStatus(name, false, nil)
+		return
+	}
+
+	for {
+		done, err := e.lead(ctx, name, fn)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("Job leadership attempt failed", "job", name, "replica", e.replica, "error", err)
+			e.setStatus(name, false, err)
+		}
+		if done || ctx.Err() != nil {
+			return
+		}
+
+		timer := time.NewTimer(e.cfg.RetryInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// lead makes one attempt to lead a job. If the lock is acquired, fn runs until it returns, ctx
+// is done or a heartbeat finds the lock lost. done reports that the job should not be led
+// again: fn returned on its own or ctx is done.
+func (e *Elector) lead(ctx context.Context, name string, fn func(ctx context.Context)) (bool, error) {
+	key := lockKey(name)
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get a connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		discard(conn)
+		return false, fmt.Errorf("failed to try the advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	e.setStatus(name, true, nil)
+	e.logger.Info("Acquired job leadership", "job", name, "replica", e.replica)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		fn(jobCtx)
+	}()
+
+	ticker := time.NewTicker(e.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	var lost error
+	returned := false
+wait:
+	for {
+		select {
+		case <-finished:
+			returned = true
+			break wait
+		case <-ctx.Done():
+			break wait
+		case <-ticker.C:
+			if err := e.heartbeat(ctx, conn, key); err != nil && ctx.Err() == nil {
+				lost = err
+				break wait
+			}
+		}
+	}
+
+	// Stop the job before giving the lock up, so the next leader never overlaps with it
+	cancel()
+	<-finished
+	e.setStatus(name, false, lost)
+
+	if lost != nil {
+		// The session may still be alive and holding the lock; closing it releases the lock
+		discard(conn)
+		e.logger.Warn("Lost job leadership", "job", name, "replica", e.replica, "error", lost)
+		return false, fmt.Errorf("lost leadership: %w", lost)
+	}
+
+	e.release(ctx, conn, key)
+	e.logger.Info("Released job leadership", "job", name, "replica", e.replica)
+	return returned || ctx.Err() != nil, nil
+}
+
+// heartbeat checks that the connection still holds the advisory lock. Bigint advisory locks
+// appear in pg_locks split into their high (classid) and low (objid) 32 bits.
+func (e *Elector) heartbeat(ctx context.Context, conn *sql.Conn, key int64) error {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.HeartbeatInterval)
+	defer cancel()
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid()
+				AND classid = (($1::bigint >> 32) & 4294967295)::oid
+				AND objid = ($1::bigint & 4294967295)::oid
+				AND objsubid = 1
+		)
+	`
+	var held bool
+	if err := conn.QueryRowContext(ctx, query, key).Scan(&held); err != nil {
+		return fmt.Errorf("heartbeat failed: %w", err)
+	}
+	if !held {
+		return fmt.Errorf("advisory lock is no longer held")
+	}
+	return nil
+}
+
+// release unlocks a job after a clean stop and returns the connection to the pool. If the
+// unlock fails, the connection is discarded, which releases the lock with the session.
+func (e *Elector) release(ctx context.Context, conn *sql.Conn, key int64) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+
+	var released bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_advisory_unlock($1)`, key).Scan(&released); err != nil || !released {
+		e.logger.Warn("Failed to release the advisory lock; closing its connection", "key", key, "error", err)
+		discard(conn)
+		return
+	}
+	conn.Close()
+}
+
+// discard closes the physical connection behind conn instead of returning it to the pool, so
+// the server ends the session and its advisory locks are released
+func discard(conn *sql.Conn) {
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+// setStatus records a leadership change of a job. A nil err keeps the last error.
+func (e *Elector) setStatus(name string, leader bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status, ok := e.jobs[name]
+	if !ok {
+		status = &Status{Job: name, Since: time.Now()}
+		e.jobs[name] = status
+	}
+	if leader && !status.Leader {
+		status.Acquisitions++
+	}
+	if leader != status.Leader {
+		status.Leader = leader
+		status.Since = time.Now()
+	}
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// Statuses returns the leadership of every job run on this replica, ordered by job name
+func (e *Elector) Statuses() []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	statuses := make([]Status, 0, len(e.jobs))
+	for _, status := range e.jobs {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Job < statuses[j].Job })
+	return statuses
+}
@@ -0,0 +1,263 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+)
+
+// fakeLocks stands in for the advisory locks of a PostgreSQL server shared by several replicas.
+// A lock belongs to the session (connection) that took it and is released when that session
+// ends.
+type fakeLocks struct {
+	mu      sync.Mutex
+	holders map[int64]*fakeSession
+}
+
+func newFakeLocks() *fakeLocks {
+	return &fakeLocks{holders: make(map[int64]*fakeSession)}
+}
+
+// open returns a connection pool of one replica on the shared server
+func (l *fakeLocks) open() *sql.DB {
+	return sql.OpenDB(l)
+}
+
+// revoke drops a lock from under its holder, as when the server ends the holder's session
+func (l *fakeLocks) revoke(key int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.holders, key)
+}
+
+// held reports whether any session holds a lock
+func (l *fakeLocks) held(key int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.holders[key] != nil
+}
+
+func (l *fakeLocks) Connect(context.Context) (driver.Conn, error) { return &fakeSession{locks: l}, nil }
+func (l *fakeLocks) Driver() driver.Driver                        { return nil }
+
+// fakeSession answers the three statements the elector sends
+type fakeSession struct {
+	locks *fakeLocks
+}
+
+func (s *fakeSession) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	key := args[0].Value.(int64)
+	l := s.locks
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var result bool
+	switch {
+	case strings.Contains(query, "pg_try_advisory_lock"):
+		if holder := l.holders[key]; holder == nil || holder == s {
+			l.holders[key] = s
+			result = true
+		}
+	case strings.Contains(query, "pg_locks"):
+		result = l.holders[key] == s
+	case strings.Contains(query, "pg_advisory_unlock"):
+		if l.holders[key] == s {
+			delete(l.holders, key)
+			result = true
+		}
+	default:
+		return nil, errors.New("fake database: unexpected query")
+	}
+	return &boolRows{value: result}, nil
+}
+
+func (s *fakeSession) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake database: prepared statements are not supported")
+}
+func (s *fakeSession) Begin() (driver.Tx, error) {
+	return nil, errors.New("fake database: transactions are not supported")
+}
+
+// Close ends the session, releasing its locks
+func (s *fakeSession) Close() error {
+	s.locks.mu.Lock()
+	defer s.locks.mu.Unlock()
+	for key, holder := range s.locks.holders {
+		if holder == s {
+			delete(s.locks.holders, key)
+		}
+	}
+	return nil
+}
+
+type boolRows struct {
+	value bool
+	read  bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"result"} }
+func (r *boolRows) Close() error      { return nil }
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+var testLeaderConfig = config.LeaderConfig{
+	Enabled:           true,
+	RetryInterval:     5 * time.Millisecond,
+	HeartbeatInterval: 5 * time.Millisecond,
+}
+
+func newTestElector(db *sql.DB, replica string) *Elector {
+	e := New(db, testLeaderConfig, slog.New(slog.DiscardHandler))
+	e.replica = replica
+	return e
+}
+
+// runJob runs a job on an elector until ctx is done, reporting each start of the job on started
+func runJob(ctx context.Context, e *Elector, started chan<- string) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.RunWhileLeader(ctx, "retention", func(jobCtx context.Context) {
+			started <- e.Replica()
+			<-jobCtx.Done()
+		})
+	}()
+	return done
+}
+
+// waitFor returns the next job start, failing the test when none comes
+func waitFor(t *testing.T, started <-chan string) string {
+	t.Helper()
+	select {
+	case replica := <-started:
+		return replica
+	case <-time.After(5 * time.Second):
+		t.Fatal("no replica started the job")
+		return ""
+	}
+}
+
+func status(e *Elector) Status {
+	return e.Statuses()[0]
+}
+
+func TestHandover(t *testing.T) {
+	locks := newFakeLocks()
+	first, second := locks.open(), locks.open()
+	defer first.Close()
+	defer second.Close()
+	a, b := newTestElector(first, "a"), newTestElector(second, "b")
+
+	started := make(chan string, 4)
+	ctxA, stopA := context.WithCancel(context.Background())
+	doneA := runJob(ctxA, a, started)
+	if replica := waitFor(t, started); replica != "a" {
+		t.Fatalf("job started on %s, want a", replica)
+	}
+
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	doneB := runJob(ctxB, b, started)
+	// The standby keeps trying while the leader holds the lock
+	time.Sleep(10 * testLeaderConfig.RetryInterval)
+	select {
+	case replica := <-started:
+		t.Fatalf("job started on %s while a leads", replica)
+	default:
+	}
+	if s := status(b); s.Leader || s.Acquisitions != 0 {
+		t.Errorf("standby status = %+v, want standing by", s)
+	}
+
+	// A clean stop releases the lock and the standby takes over on its next attempt
+	stopA()
+	<-doneA
+	if replica := waitFor(t, started); replica != "b" {
+		t.Fatalf("job started on %s after handover, want b", replica)
+	}
+	if s := status(a); s.Leader || s.Acquisitions != 1 || s.LastError != "" {
+		t.Errorf("previous leader status = %+v, want one clean term", s)
+	}
+	if s := status(b); !s.Leader || s.Acquisitions != 1 {
+		t.Errorf("new leader status = %+v, want leading", s)
+	}
+
+	stopB()
+	<-doneB
+	if locks.held(lockKey("retention")) {
+		t.Error("lock still held after every replica stopped")
+	}
+}
+
+func TestLostLeadership(t *testing.T) {
+	locks := newFakeLocks()
+	db := locks.open()
+	defer db.Close()
+	e := newTestElector(db, "a")
+
+	started := make(chan string, 4)
+	ctx, stop := context.WithCancel(context.Background())
+	done := runJob(ctx, e, started)
+	waitFor(t, started)
+
+	// The heartbeat notices the lock is gone, stops the job and acquires leadership again
+	locks.revoke(lockKey("retention"))
+	waitFor(t, started)
+	s := status(e)
+	if !s.Leader || s.Acquisitions != 2 {
+		t.Errorf("status = %+v, want leading a second time", s)
+	}
+	if !strings.Contains(s.LastError, "advisory lock is no longer held") {
+		t.Errorf("status error = %q, want the lost lock", s.LastError)
+	}
+
+	stop()
+	<-done
+}
+
+func TestJobReturns(t *testing.T) {
+	locks := newFakeLocks()
+	db := locks.open()
+	defer db.Close()
+	e := newTestElector(db, "a")
+
+	runs := 0
+	e.RunWhileLeader(context.Background(), "backfill", func(context.Context) { runs++ })
+	if runs != 1 {
+		t.Errorf("job ran %d times, want once", runs)
+	}
+	if locks.held(lockKey("backfill")) {
+		t.Error("lock still held after the job returned")
+	}
+	if s := status(e); s.Leader || s.Acquisitions != 1 {
+		t.Errorf("status = %+v, want one finished term", s)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	cfg := testLeaderConfig
+	cfg.Enabled = false
+	// Without election the database is never used
+	e := New(nil, cfg, slog.New(slog.DiscardHandler))
+
+	runs := 0
+	e.RunWhileLeader(context.Background(), "backfill", func(context.Context) { runs++ })
+	if runs != 1 {
+		t.Errorf("job ran %d times, want once", runs)
+	}
+}
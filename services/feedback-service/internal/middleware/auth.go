@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// UserRoleMetadataKey carries the caller's role, set by the API gateway after authentication
+	UserRoleMetadataKey = "x-user-role"
+	// AdminRole is the role granted to administrators (matches users-service Role)
+	AdminRole = "ROLE_ADMIN"
+
+	// CallerClassMetadataKey marks requests from other backend services; anything else is external
+	CallerClassMetadataKey = "x-caller-class"
+	// InternalCallerClass is the caller class value used by internal services
+	InternalCallerClass = "internal"
+)
+
+// IsAdmin reports whether the incoming request was made by an administrator
+func IsAdmin(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, role := range md.Get(UserRoleMetadataKey) {
+		if role == AdminRole {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAdmin returns a PermissionDenied status error unless the caller is an administrator
+func RequireAdmin(ctx context.Context) error {
+	if !IsAdmin(ctx) {
+		return status.Error(codes.PermissionDenied, "administrator role required")
+	}
+	return nil
+}
+
+// IsInternalCaller reports whether the incoming request was made by an internal service
+func IsInternalCaller(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, class := range md.Get(CallerClassMetadataKey) {
+		if class == InternalCallerClass {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireInternalCaller returns a PermissionDenied status error unless the caller is an internal service
+func RequireInternalCaller(ctx context.Context) error {
+	if !IsInternalCaller(ctx) {
+		return status.Error(codes.PermissionDenied, "internal caller required")
+	}
+	return nil
+}
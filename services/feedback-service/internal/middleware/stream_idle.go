@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamIdleInterceptor ends the streams of the listed methods once they have sent and received
+// no message for the method's idle timeout. Streams that keep moving data are never ended, so
+// connections past their maximum age can wait for them instead of cutting them. Methods that
+// are not listed, or listed with 0, have no idle timeout.
+func StreamIdleInterceptor(timeouts map[string]time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		idle := timeouts[info.FullMethod]
+		if idle <= 0 {
+			return handler(srv, ss)
+		}
+
+		ctx, cancel := context.WithCancel(ss.Context())
+		defer cancel()
+		stream := &activityServerStream{ServerStream: ss, ctx: ctx}
+		stream.touch()
+
+		// The watchdog sleeps until the stream could first be idle and rearms for the time left
+		// whenever a message moved in between
+		expired := make(chan struct{})
+		var watchdog *time.Timer
+		watchdog = time.AfterFunc(idle, func() {
+			if left := idle - stream.sinceActivity(); left > 0 {
+				watchdog.Reset(left)
+				return
+			}
+			close(expired)
+			cancel()
+		})
+		defer watchdog.Stop()
+
+		// A handler blocked in Recv only returns once the stream is finished, which happens when
+		// this interceptor returns
+		done := make(chan error, 1)
+		go func() {
+			done <- handler(srv, stream)
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-expired:
+			slog.Warn("Ending idle stream", "method", info.FullMethod, "idle_timeout", idle)
+			return status.Errorf(codes.Unavailable, "stream idle for %s", idle)
+		}
+	}
+}
+
+// activityServerStream records when a message was last sent or received on a stream
+type activityServerStream struct {
+	grpc.ServerStream
+	ctx          context.Context
+	lastActivity atomic.Int64 // Unix nanoseconds
+}
+
+func (s *activityServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *activityServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.touch()
+	}
+	return err
+}
+
+func (s *activityServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.touch()
+	}
+	return err
+}
+
+// touch records activity now
+func (s *activityServerStream) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// sinceActivity returns the time since the last message
+func (s *activityServerStream) sinceActivity() time.Duration {
+	return time.Duration(time.Now().UnixNano() - s.lastActivity.Load())
+}
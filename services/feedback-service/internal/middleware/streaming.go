@@ -2,12 +2,11 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"io"
 	"log/slog"
 
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -29,25 +28,29 @@ func StreamingServerInterceptor() grpc.StreamServerInterceptor {
 				// If it needs to be an error, the service handler should convert it.
 				return nil
 			}
-			if err == context.Canceled {
-				return status.Error(codes.Canceled, "request was cancelled by the client")
-			}
-			if err == context.DeadlineExceeded {
-				return status.Error(codes.DeadlineExceeded, "request deadline exceeded")
-			}
 
-			// If it's already a gRPC status error, return it directly
-			if _, ok := status.FromError(err); ok {
-				return err
-			}
-
-			// For other errors, convert to a generic internal error
-			return status.Error(codes.Internal, fmt.Sprintf("an unexpected error occurred: %v", err))
+			// Status errors pass through; domain, context and other errors are converted
+			return apperr.ToStatus(err)
 		}
 		return nil
 	}
 }
 
+// UnaryServerInterceptor converts errors that a handler returned without mapping them to a
+// gRPC status, so domain errors keep their code and other errors never leak their details
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); !ok {
+			slog.Error("Unary RPC returned an unmapped error", "method", info.FullMethod, "error", err)
+		}
+		return resp, apperr.ToStatus(err)
+	}
+}
+
 // wrappedServerStream wraps the grpc.ServerStream to provide additional functionality
 type wrappedServerStream struct {
 	grpc.ServerStream
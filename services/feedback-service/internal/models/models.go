@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,14 +14,200 @@ import (
 // Feedback represents a feedback entry - simplified structure
 // PostgreSQL storage for metadata, MongoDB for content
 type Feedback struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	ReviewerID   int64     `json:"reviewer_id" db:"reviewer_id"`
-	StudentID    int64     `json:"student_id" db:"student_id"`
-	SubmissionID int64     `json:"submission_id" db:"submission_id"`
-	Title        string    `json:"title" db:"title"`
-	Content      string    `json:"content"` // Markdown content stored in MongoDB
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID `json:"id" db:"id"`
+	ReviewerID    int64     `json:"reviewer_id" db:"reviewer_id"`
+	StudentID     int64     `json:"student_id" db:"student_id"`
+	SubmissionID  int64     `json:"submission_id" db:"submission_id"`
+	Title         string    `json:"title" db:"title"`
+	Content       string    `json:"content"`                            // Markdown content stored in MongoDB
+	Locked        bool      `json:"locked" db:"locked"`                 // Frozen (e.g. under academic appeal): no edits, deletion or attachment changes
+	LockReason    string    `json:"lock_reason" db:"lock_reason"`       // Why the feedback is locked
+	NeedsReupload bool      `json:"needs_reupload" db:"needs_reupload"` // Attachments went missing from storage and should be uploaded again
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+
+	Snapshot *SubmissionSnapshot `json:"submission_snapshot,omitempty" db:"submission_snapshot"` // Display data copied from upstream services, nil if none was provided
+	CourseID string              `json:"course_id,omitempty" db:"course_id"`                     // Course whose policy applies; empty means global limits only
+	Grade    *Grade              `json:"grade,omitempty"`                                        // Numeric grade given by the reviewer, nil if ungraded
+
+	RubricItems []RubricItem `json:"rubric_items,omitempty"` // Per-criterion scores in rubric order, empty without a rubric
+
+	Status      string     `json:"status" db:"status"`                       // FeedbackDraft or FeedbackPublished
+	PublishedAt *time.Time `json:"published_at,omitempty" db:"published_at"` // When the feedback was published, nil for drafts
+	ReadAt      *time.Time `json:"read_at,omitempty" db:"read_at"`           // When the student first acknowledged the feedback, nil while unread
+
+	RevisionCount int32 `json:"revision_count" db:"revision_count"` // Number of edits since creation; see FeedbackRevision
+
+	RequiresResubmission bool       `json:"requires_resubmission" db:"requires_resubmission"`           // The reviewer asks the student to fix and resubmit
+	ResubmissionDeadline *time.Time `json:"resubmission_deadline,omitempty" db:"resubmission_deadline"` // When the resubmission is due, nil for no deadline
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"` // When the feedback was archived; nil while it is active
+
+	HelpfulCount    int32 `json:"helpful_count"`     // ReactionHelpful reactions; see FeedbackReaction
+	NotHelpfulCount int32 `json:"not_helpful_count"` // ReactionNotHelpful reactions
+
+	ApprovalStatus string `json:"approval_status" db:"approval_status"`       // One of the Approval* statuses
+	ApproverID     *int64 `json:"approver_id,omitempty" db:"approver_id"`     // Second reviewer asked to approve, nil if approval was never requested
+	ApprovalNote   string `json:"approval_note,omitempty" db:"approval_note"` // Note of the approver's last decision
+
+	IdempotencyKey         string `json:"-" db:"idempotency_key"`         // Client key of the creating request; only written on creation
+	IdempotencyFingerprint string `json:"-" db:"idempotency_fingerprint"` // Hash of the creating request's payload
+}
+
+// Reactions to a feedback
+const (
+	ReactionHelpful    = "HELPFUL"
+	ReactionNotHelpful = "NOT_HELPFUL"
+)
+
+// IsValidReaction reports whether a reaction is one of the Reaction* values
+func IsValidReaction(reaction string) bool {
+	return reaction == ReactionHelpful || reaction == ReactionNotHelpful
+}
+
+// CountReaction adds delta to the count of a reaction
+func (f *Feedback) CountReaction(reaction string, delta int32) {
+	switch reaction {
+	case ReactionHelpful:
+		f.HelpfulCount += delta
+	case ReactionNotHelpful:
+		f.NotHelpfulCount += delta
+	}
+}
+
+// FeedbackRevision is the title and content of a feedback after an edit. Revision 0 is the
+// text as created, recorded along with the first edit.
+type FeedbackRevision struct {
+	FeedbackID uuid.UUID `json:"feedback_id" db:"feedback_id"`
+	Revision   int32     `json:"revision" db:"revision"`
+	Title      string    `json:"title" db:"title"`
+	Content    string    `json:"content" db:"content"`
+	EditedAt   time.Time `json:"edited_at" db:"edited_at"`
+	EditedBy   int64     `json:"edited_by" db:"edited_by"`
+}
+
+// FeedbackTemplate is a reviewer's reusable title and content that new feedback can be
+// pre-filled from
+type FeedbackTemplate struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	ReviewerID int64     `json:"reviewer_id" db:"reviewer_id"` // Only this reviewer may use or change the template
+	Name       string    `json:"name" db:"name"`               // Unique per reviewer
+	Title      string    `json:"title" db:"title"`
+	Content    string    `json:"content" db:"content"` // Markdown content
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IdempotencyRecord is the feedback created under an idempotency key
+type IdempotencyRecord struct {
+	FeedbackID  uuid.UUID
+	Fingerprint string    // Hash of the creating request's payload
+	CreatedAt   time.Time // The key expires a retention period after creation
+}
+
+// Feedback statuses. Drafts are only visible to their reviewer; students see published feedback.
+const (
+	FeedbackDraft     = "DRAFT"
+	FeedbackPublished = "PUBLISHED"
+)
+
+// IsPublished reports whether the feedback was published by its reviewer
+func (f *Feedback) IsPublished() bool {
+	return f.Status == FeedbackPublished
+}
+
+// Approval statuses. Feedback awaiting approval or with changes requested is hidden from
+// students like a draft, even if it is published.
+const (
+	ApprovalNotRequired      = "NOT_REQUIRED"
+	ApprovalPending          = "PENDING"
+	ApprovalApproved         = "APPROVED"
+	ApprovalChangesRequested = "CHANGES_REQUESTED"
+)
+
+// approvalTransitions lists the approval statuses each status may move to. Approval can be
+// requested again after changes were requested or after an approval, e.g. following edits.
+var approvalTransitions = map[string][]string{
+	ApprovalNotRequired:      {ApprovalPending},
+	ApprovalPending:          {ApprovalApproved, ApprovalChangesRequested},
+	ApprovalChangesRequested: {ApprovalPending},
+	ApprovalApproved:         {ApprovalPending},
+}
+
+// CanTransitionApproval reports whether a feedback may move from one approval status to another
+func CanTransitionApproval(from, to string) bool {
+	for _, next := range approvalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsApproved reports whether the feedback needs no approval or was approved
+func (f *Feedback) IsApproved() bool {
+	return f.ApprovalStatus == ApprovalNotRequired || f.ApprovalStatus == ApprovalApproved
+}
+
+// IsVisibleToStudent reports whether students can see the feedback: it is published and needs
+// no approval or was approved
+func (f *Feedback) IsVisibleToStudent() bool {
+	return f.IsPublished() && f.IsApproved()
+}
+
+// IsUnread reports whether the student can see the feedback but has not acknowledged it yet
+func (f *Feedback) IsUnread() bool {
+	return f.IsVisibleToStudent() && f.ReadAt == nil
+}
+
+// IsApprover reports whether userID was asked to approve the feedback
+func (f *Feedback) IsApprover(userID int64) bool {
+	return f.ApproverID != nil && *f.ApproverID == userID
+}
+
+// Grade is a score on a scale from 0 to MaxPoints
+type Grade struct {
+	Points    float64 `json:"points" db:"points"`
+	MaxPoints float64 `json:"max_points" db:"max_points"`
+}
+
+// RubricItem is the score of a feedback on one rubric criterion
+type RubricItem struct {
+	Name     string  `json:"name" db:"name"` // Unique within a feedback
+	Score    float64 `json:"score" db:"score"`
+	MaxScore float64 `json:"max_score" db:"max_score"`
+	Comment  string  `json:"comment,omitempty" db:"comment"`
+}
+
+// RubricTotal returns the sums of the scores and maximum scores of the rubric items
+func (f *Feedback) RubricTotal() (score, maxScore float64) {
+	for _, item := range f.RubricItems {
+		score += item.Score
+		maxScore += item.MaxScore
+	}
+	return score, maxScore
+}
+
+// SubmissionSnapshot is denormalized display data about the reviewed submission, supplied by
+// upstream services so listings need no extra lookups. It is advisory only and never used
+// for authorization; empty fields are unknown.
+type SubmissionSnapshot struct {
+	LabTitle           string `json:"lab_title,omitempty"`
+	StudentDisplayName string `json:"student_display_name,omitempty"`
+	SubmissionLabel    string `json:"submission_label,omitempty"`
+
+	ReviewerDisplayName string `json:"reviewer_display_name,omitempty"`
+}
+
+// IsEmpty reports whether the snapshot carries no data
+func (s *SubmissionSnapshot) IsEmpty() bool {
+	return s == nil || *s == SubmissionSnapshot{}
+}
+
+// SubmissionSnapshotUpdate refreshes the snapshot of every feedback of a submission
+type SubmissionSnapshotUpdate struct {
+	SubmissionID int64
+	Snapshot     SubmissionSnapshot
 }
 
 // FeedbackContent represents the MongoDB document for feedback content
@@ -28,22 +218,330 @@ type FeedbackContent struct {
 
 // Comment represents a comment - stored in MongoDB
 type Comment struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ContentID int64              `bson:"content_id" json:"content_id"`
-	UserID    int64              `bson:"user_id" json:"user_id"`
-	ParentID  *string            `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
-	Content   string             `bson:"content" json:"content"` // Markdown content
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-	Type      string             `bson:"type" json:"type"` // Type of content (e.g., "lab", "article")
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ContentID  int64              `bson:"content_id" json:"content_id"`
+	ContentRef string             `bson:"content_ref,omitempty" json:"content_ref,omitempty"` // String ID of the content for types referenced by one, such as a feedback UUID; ContentID is 0 then
+	UserID     int64              `bson:"user_id" json:"user_id"`
+	ParentID   *string            `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Content    string             `bson:"content" json:"content"` // Markdown content
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	Type       string             `bson:"type" json:"type"`                               // Type of content (e.g., "lab", "article")
+	Depth      int32              `bson:"depth" json:"depth"`                             // Reply level: 0 for top-level comments, parent depth + 1 for replies
+	CourseID   string             `bson:"course_id,omitempty" json:"course_id,omitempty"` // Course whose policy applies; empty means global limits only
+
+	Edited    bool          `bson:"edited,omitempty" json:"edited"`         // Content was changed after posting
+	EditCount int32         `bson:"edit_count,omitempty" json:"edit_count"` // Edits ever made, including those dropped from Edits
+	Edits     []CommentEdit `bson:"edits,omitempty" json:"-"`               // Previous versions, oldest first, at most MaxCommentEdits; not read by lists
+
+	HiddenAt *time.Time `bson:"hidden_at,omitempty" json:"hidden_at,omitempty"` // When a moderator hid the comment; nil while it is shown
+	HiddenBy int64      `bson:"hidden_by,omitempty" json:"hidden_by,omitempty"` // Moderator who hid it
+
+	ChangedAt time.Time `bson:"changed_at,omitempty" json:"-"` // Last write comment streams report: creation, edit, hiding or showing, a merge move or an erasure
+
+	ErasedAt  *time.Time `bson:"erased_at,omitempty" json:"erased_at,omitempty"`   // When the author's comments were erased; UserID is 0 from then on
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // When the comment was soft deleted by an erasure; its content is empty
+
+	ReplyCount int64 `bson:"-" json:"reply_count"` // Direct replies; only filled by comment lists
+
+	LikeCount      int64  `bson:"-" json:"like_count"`                // CommentReactionLike reactions
+	DislikeCount   int64  `bson:"-" json:"dislike_count"`             // CommentReactionDislike reactions
+	ViewerReaction string `bson:"-" json:"viewer_reaction,omitempty"` // Reaction of the user the comment was read for, "" if none
+}
+
+// ContentKey returns the ID of the content the comment is on as it appears in resource names:
+// ContentRef for the types referenced by one, ContentID otherwise
+func (c *Comment) ContentKey() string {
+	if c.ContentRef != "" {
+		return c.ContentRef
+	}
+	return strconv.FormatInt(c.ContentID, 10)
+}
+
+// Types of content comments are attached to
+const (
+	CommentTypeLab      = "lab"
+	CommentTypeArticle  = "article"
+	CommentTypeFeedback = "feedback" // Discussion of a feedback entry, referenced by its UUID
+)
+
+// commentTypeRefs lists the comment types and whether their content is referenced by
+// ContentRef, a string ID, rather than by the numeric ContentID. A new type is added here, and
+// to CommentService.checkTarget if new comments on it must point at content that exists.
+var commentTypeRefs = map[string]bool{
+	CommentTypeLab:      false,
+	CommentTypeArticle:  false,
+	CommentTypeFeedback: true,
+}
+
+// IsValidCommentType reports whether comments may be attached to a type of content
+func IsValidCommentType(contentType string) bool {
+	_, ok := commentTypeRefs[contentType]
+	return ok
+}
+
+// CommentTypeUsesRef reports whether the content of a comment type is referenced by
+// ContentRef rather than ContentID
+func CommentTypeUsesRef(contentType string) bool {
+	return commentTypeRefs[contentType]
+}
+
+// CommentTypes returns the valid comment types in alphabetical order
+func CommentTypes() []string {
+	types := make([]string, 0, len(commentTypeRefs))
+	for contentType := range commentTypeRefs {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// FeedbackThread names the feedback whose comment thread is summarized, with its reviewer and
+// student, whose comments are told apart as answers and questions
+type FeedbackThread struct {
+	FeedbackID string
+	ReviewerID int64
+	StudentID  int64
+}
+
+// FeedbackThreadSummary summarizes the comment thread of a feedback
+type FeedbackThreadSummary struct {
+	CommentCount        int64     // Comments and replies
+	UnansweredQuestions int64     // Top-level comments by the student without a direct reply by the reviewer
+	LastActivityAt      time.Time // Latest creation or edit of a comment; zero without comments
+}
+
+// IsHidden reports whether a moderator hid the comment. Hidden comments stay in their thread as
+// removed placeholders, so their replies keep a parent.
+func (c *Comment) IsHidden() bool {
+	return c.HiddenAt != nil
+}
+
+// IsDeleted reports whether the comment was soft deleted; it stays in its thread as a
+// placeholder so its replies keep their parent
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// Reasons for reporting a comment
+const (
+	CommentReportSpam       = "SPAM"
+	CommentReportHarassment = "HARASSMENT"
+	CommentReportOffTopic   = "OFF_TOPIC"
+	CommentReportOther      = "OTHER"
+)
+
+// IsValidCommentReportReason reports whether a reason is one of the CommentReport* values
+func IsValidCommentReportReason(reason string) bool {
+	switch reason {
+	case CommentReportSpam, CommentReportHarassment, CommentReportOffTopic, CommentReportOther:
+		return true
+	}
+	return false
+}
+
+// MaxCommentReportNoteLength is the longest free-text note of a report, in characters
+const MaxCommentReportNoteLength = 500
+
+// Decisions of a moderator on a comment
+const (
+	CommentModerationHide    = "HIDE"    // Hide the comment and close its reports
+	CommentModerationDismiss = "DISMISS" // Close the comment's reports and leave it shown
+	CommentModerationRestore = "RESTORE" // Show a hidden comment again
+)
+
+// CommentReport is a user's report of a comment; a user has at most one report per comment
+type CommentReport struct {
+	CommentID  primitive.ObjectID `bson:"comment_id" json:"comment_id"`
+	ReporterID int64              `bson:"reporter_id" json:"reporter_id"`
+	Reason     string             `bson:"reason" json:"reason"` // One of the CommentReport* reasons
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
+	Open       bool               `bson:"open" json:"open"`                                 // Awaiting a moderator's decision
+	Resolution string             `bson:"resolution,omitempty" json:"resolution,omitempty"` // CommentModerationHide or CommentModerationDismiss once closed
+	ResolvedBy int64              `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"` // When the report was last made; reporting again updates it
+}
+
+// ReportedComment is a comment with open reports, as moderators review it
+type ReportedComment struct {
+	Comment        *Comment
+	ReportCount    int64            // Open reports
+	ReasonCounts   map[string]int64 // Open reports per reason
+	RecentReports  []*CommentReport // The most recent open reports, newest first
+	LastReportedAt time.Time
+}
+
+// ModerationResult is the outcome of a moderator's decision on a comment
+type ModerationResult struct {
+	Comment         *Comment
+	ResolvedReports int64 // Open reports the decision closed
+}
+
+// MaxCommentEdits is the number of previous versions kept on a comment; older ones are dropped
+const MaxCommentEdits = 10
+
+// CommentEdit is a previous version of a comment's content
+type CommentEdit struct {
+	Content    string    `bson:"content" json:"content"`
+	WrittenAt  time.Time `bson:"written_at" json:"written_at"`   // When this version was posted or last saved
+	ReplacedAt time.Time `bson:"replaced_at" json:"replaced_at"` // When the edit that replaced it was saved
+}
+
+// Reactions to a comment; a user has at most one reaction per comment
+const (
+	CommentReactionLike    = "LIKE"
+	CommentReactionDislike = "DISLIKE"
+)
+
+// IsValidCommentReaction reports whether a reaction is one of the CommentReaction* values
+func IsValidCommentReaction(reaction string) bool {
+	return reaction == CommentReactionLike || reaction == CommentReactionDislike
+}
+
+// CountReaction adds delta to the count of a reaction
+func (c *Comment) CountReaction(reaction string, delta int64) {
+	switch reaction {
+	case CommentReactionLike:
+		c.LikeCount += delta
+	case CommentReactionDislike:
+		c.DislikeCount += delta
+	}
+}
+
+// ValidateImportTimestamps fills missing timestamps of an imported comment and checks that
+// they are in the past and ordered
+func (c *Comment) ValidateImportTimestamps(now time.Time) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = c.CreatedAt
+	}
+	c.CreatedAt = c.CreatedAt.UTC()
+	c.UpdatedAt = c.UpdatedAt.UTC()
+
+	if c.CreatedAt.After(now) || c.UpdatedAt.After(now) {
+		return fmt.Errorf("timestamps must not be in the future")
+	}
+	if c.UpdatedAt.Before(c.CreatedAt) {
+		return fmt.Errorf("updated_at must not be before created_at")
+	}
+	return nil
+}
+
+// CommentMerge is the audit record of moving the comments of a duplicate content onto the
+// content it duplicates. Retries of a merge update the same record.
+type CommentMerge struct {
+	ID              string     `bson:"_id" json:"id"` // "<type>:<source>:<target>"
+	SourceContentID int64      `bson:"source_content_id" json:"source_content_id"`
+	TargetContentID int64      `bson:"target_content_id" json:"target_content_id"`
+	Type            string     `bson:"type" json:"type"`
+	ActorID         int64      `bson:"actor_id" json:"actor_id"`         // Administrator of the latest attempt
+	Moved           int64      `bson:"moved" json:"moved"`               // Comments moved over all attempts
+	Attempts        int32      `bson:"attempts" json:"attempts"`         // Calls that worked on the merge
+	StartedAt       time.Time  `bson:"started_at" json:"started_at"`     // First attempt
+	CompletedAt     *time.Time `bson:"completed_at" json:"completed_at"` // Latest attempt that left no comments on the source, nil while unfinished
+}
+
+// CommentMergeResult reports one attempt of a comment thread merge
+type CommentMergeResult struct {
+	Merge          *CommentMerge
+	Moved          int64 // Comments moved by this attempt
+	TargetComments int64 // Comments on the target once the attempt finished
+}
+
+// Modes of erasing the comments of a user
+const (
+	CommentErasureAnonymize = "anonymize" // Replace the content with ErasedCommentContent
+	CommentErasureDelete    = "delete"    // Soft delete: empty the content and set DeletedAt
+)
+
+// ErasedCommentContent replaces the content of comments anonymized by an erasure
+const ErasedCommentContent = "[removed at the author's request]"
+
+// CommentErasure is the audit record of erasing the comments of a user, whose ID is dropped
+// from them. Retries of an erasure update the same record.
+type CommentErasure struct {
+	ID          string     `bson:"_id" json:"id"` // The user ID
+	UserID      int64      `bson:"user_id" json:"user_id"`
+	Mode        string     `bson:"mode" json:"mode"`                 // Mode of the latest attempt
+	ActorID     int64      `bson:"actor_id" json:"actor_id"`         // Administrator of the latest attempt
+	Erased      int64      `bson:"erased" json:"erased"`             // Comments erased over all attempts
+	Attempts    int32      `bson:"attempts" json:"attempts"`         // Calls that worked on the erasure
+	StartedAt   time.Time  `bson:"started_at" json:"started_at"`     // First attempt
+	CompletedAt *time.Time `bson:"completed_at" json:"completed_at"` // Latest attempt that left no comments by the user, nil while unfinished
+}
+
+// CommentErasureResult reports one attempt of erasing the comments of a user
+type CommentErasureResult struct {
+	Erasure *CommentErasure
+	Erased  int64 // Comments erased by this attempt
+}
+
+// CommentImportOptions apply to every record of an import stream
+type CommentImportOptions struct {
+	DryRun         bool   // Validate records without writing them
+	SourceEncoding string // Encoding of raw content; empty means UTF-8
+	Lenient        bool   // Replace undecodable bytes instead of failing the record
+}
+
+// CommentImportRecord represents a single comment in an import stream
+type CommentImportRecord struct {
+	SourceID       string  // ID in the source system, used to resolve replies within the import
+	ParentSourceID *string // Parent's SourceID within the same import
+	Comment        Comment // Comment to create; ParentID may reference an existing comment
+	RawContent     []byte  // Content in the source encoding, decoded into Comment.Content
+	SourceEncoding *string // Encoding of RawContent, overriding the stream's
+}
+
+// CommentImportResult represents the outcome of importing a single record
+type CommentImportResult struct {
+	Index          int
+	SourceID       string
+	CommentID      string
+	SourceEncoding string // Encoding RawContent was decoded from
+	Replaced       int    // Undecodable bytes replaced in lenient mode
+	Err            error
 }
 
 // AttachmentInfo represents metadata about attachments stored in MinIO
 type AttachmentInfo struct {
 	Filename    string    `json:"filename"`
-	Size        int64     `json:"size"`
+	Size        int64     `json:"size"`                  // Bytes returned by a download
+	StoredSize  int64     `json:"stored_size,omitempty"` // Bytes in object storage; larger than Size for encrypted attachments
 	ContentType string    `json:"content_type"`
 	UploadedAt  time.Time `json:"uploaded_at"`
+	SHA256      string    `json:"sha256,omitempty"` // Hex SHA-256 of the content recorded at upload; empty when unknown
+}
+
+// Upload session statuses
+const (
+	UploadSessionOpen       = "open"       // Accepting files
+	UploadSessionCommitting = "committing" // Promotion started; only commit may continue
+	UploadSessionCommitted  = "committed"
+	UploadSessionAborted    = "aborted"
+)
+
+// UploadSession groups files that are attached to a feedback together or not at all
+type UploadSession struct {
+	ID         uuid.UUID            `json:"id" db:"id"`
+	FeedbackID uuid.UUID            `json:"feedback_id" db:"feedback_id"`
+	ReviewerID int64                `json:"reviewer_id" db:"reviewer_id"`
+	Status     string               `json:"status" db:"status"`
+	Files      []*UploadSessionFile `json:"files"`
+	CreatedAt  time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// UploadSessionFile represents a file staged in an upload session
+type UploadSessionFile struct {
+	Filename    string `json:"filename" db:"filename"`
+	ContentType string `json:"content_type" db:"content_type"`
+	Size        int64  `json:"size" db:"file_size"`
+	Completed   bool   `json:"completed" db:"completed"` // Fully uploaded to the staging prefix
+	Promoted    bool   `json:"promoted" db:"promoted"`   // Copied to the feedback's attachments during commit
+	SHA256      string `json:"sha256" db:"sha256"`       // Hex SHA-256 of the content, set once completed
 }
 
 // AttachmentLocationInfo represents location metadata for MinIO direct access
@@ -60,11 +558,185 @@ type AttachmentLocationInfo struct {
 
 // FeedbackFilter represents filtering options for feedback queries
 type FeedbackFilter struct {
-	ReviewerID   *int64 `json:"reviewer_id,omitempty"`
-	StudentID    *int64 `json:"student_id,omitempty"`
-	SubmissionID *int64 `json:"submission_id,omitempty"`
-	Page         int    `json:"page"`
-	Limit        int    `json:"limit"`
+	ReviewerID       *int64              `json:"reviewer_id,omitempty"`
+	StudentID        *int64              `json:"student_id,omitempty"`
+	SubmissionID     *int64              `json:"submission_id,omitempty"`
+	CourseID         *string             `json:"course_id,omitempty"`         // Feedbacks recorded under this course
+	HasAttachments   *bool               `json:"has_attachments,omitempty"`   // Feedbacks with (true) or without (false) attachments
+	MinAttachments   *int32              `json:"min_attachments,omitempty"`   // Feedbacks with at least this many attachments
+	AttachmentCounts map[uuid.UUID]int64 `json:"-"`                           // Counted in MinIO for feedbacks whose metadata is not backfilled; overrides feedback_assets
+	Status           *string             `json:"status,omitempty"`            // Feedbacks with this status (FeedbackDraft or FeedbackPublished)
+	ApprovedOnly     bool                `json:"approved_only,omitempty"`     // Only feedbacks that need no approval or were approved
+	UnreadOnly       bool                `json:"unread_only,omitempty"`       // Only feedbacks the student has not acknowledged
+	ResubmissionOnly bool                `json:"resubmission_only,omitempty"` // Only feedbacks that ask for a resubmission
+	IncludeArchived  bool                `json:"include_archived,omitempty"`  // Also list archived feedbacks
+	ChangedSince     *time.Time          `json:"changed_since,omitempty"`     // Feedbacks updated, published or approved at or after this time
+	CreatedAfter     *time.Time          `json:"created_after,omitempty"`     // Feedbacks created at or after this time
+	CreatedBefore    *time.Time          `json:"created_before,omitempty"`    // Feedbacks created before this time
+	Sort             FeedbackSort        `json:"sort"`                        // List order; the zero value lists newest first
+	After            *FeedbackCursor     `json:"after,omitempty"`             // Keyset pagination: feedbacks listed after this one; replaces Page
+	Page             int                 `json:"page"`
+	Limit            int                 `json:"limit"`
+}
+
+// FeedbackCreateRequest describes a feedback to create. It is saved as a draft unless Publish
+// is set.
+type FeedbackCreateRequest struct {
+	ReviewerID           int64
+	StudentID            int64
+	SubmissionID         int64
+	Title                string
+	Content              string
+	Snapshot             *SubmissionSnapshot
+	CourseID             string
+	Grade                *Grade
+	RubricItems          []RubricItem
+	RequiresResubmission bool       // Asks the student to fix and resubmit
+	ResubmissionDeadline *time.Time // Optional deadline of the resubmission
+	Publish              bool       // Publish at once instead of saving a draft
+	IdempotencyKey       string     // Retries with the same key and payload return the same feedback
+}
+
+// FeedbackUpdateRequest describes changes to a feedback. Without a Mask, nil fields are left
+// unchanged and the Clear fields remove a value; with a Mask, exactly the named fields are
+// replaced and named nil fields are cleared.
+type FeedbackUpdateRequest struct {
+	ID                        uuid.UUID
+	ReviewerID                int64
+	Title                     *string
+	Content                   *string
+	Grade                     *Grade
+	ClearGrade                bool
+	RubricItems               []RubricItem // Replaces the stored items when not empty
+	ClearRubricItems          bool
+	RequiresResubmission      *bool
+	ResubmissionDeadline      *time.Time
+	ClearResubmissionDeadline bool
+	Mask                      []string // Field paths to replace, such as "title" or "grade"
+}
+
+// StudentFeedbackListOptions selects and pages the feedbacks listed for a student
+type StudentFeedbackListOptions struct {
+	StudentID        int64
+	SubmissionID     *int64
+	UnreadOnly       bool         // Only feedbacks the student has not acknowledged
+	ResubmissionOnly bool         // Only feedbacks that ask for a resubmission
+	IncludeArchived  bool         // Also list archived feedbacks
+	CreatedAfter     *time.Time   // Feedbacks created at or after this time
+	CreatedBefore    *time.Time   // Feedbacks created before this time
+	Sort             FeedbackSort // List order; the zero value lists newest first
+	Page             int32
+	Limit            int32
+	After            *FeedbackCursor // Continues the list from a cursor; replaces Page
+	PrefetchNext     bool            // Warm the cache with the following page
+}
+
+// Kinds of FeedbackStreamEvent
+const (
+	FeedbackEventCreated   = "created"
+	FeedbackEventUpdated   = "updated"
+	FeedbackEventPublished = "published" // Published, or approved after publishing; the student can see it from now on
+	FeedbackEventUpload    = "upload"    // An attachment upload in flight; not journaled, so never replayed
+)
+
+// Stages of an attachment upload reported by UploadProgress
+const (
+	UploadStageStarted   = "started"
+	UploadStageProgress  = "progress" // A milestone of 25, 50 or 75 percent was passed
+	UploadStageCompleted = "completed"
+	UploadStageFailed    = "failed"
+)
+
+// UploadProgress reports how far an attachment upload to a feedback got. It describes an
+// upload in flight and is neither persisted nor replayed.
+type UploadProgress struct {
+	Filename      string `json:"filename"`
+	Stage         string `json:"stage"`
+	Percent       int32  `json:"percent"` // Last milestone passed; 100 once completed
+	BytesReceived int64  `json:"bytes_received"`
+	TotalBytes    int64  `json:"total_bytes"`
+}
+
+// FeedbackStreamEvent is a change to a feedback pushed to the student it addresses, or the
+// progress of an attachment upload to it
+type FeedbackStreamEvent struct {
+	Kind       string          `json:"kind"`
+	Feedback   *Feedback       `json:"feedback"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Replayed   bool            `json:"replayed"`         // Read back for a since time or a resume position rather than observed live
+	Position   StreamPosition  `json:"position"`         // Where the stream resumes after this event
+	Upload     *UploadProgress `json:"upload,omitempty"` // Set for FeedbackEventUpload
+}
+
+// StreamPosition is the position of an event in the journal of an event stream. Sequence
+// numbers grow with every event; the epoch changes whenever the journal is lost, such as on a
+// restart, so positions of an earlier epoch cannot be resumed from.
+type StreamPosition struct {
+	Epoch    string `json:"epoch"`
+	Sequence uint64 `json:"sequence"`
+}
+
+// FeedbackSearchFilter represents a full-text search over feedback titles and content
+type FeedbackSearchFilter struct {
+	Query        string  `json:"query"` // Web search syntax: words, "quoted phrases", or, -excluded
+	ReviewerID   *int64  `json:"reviewer_id,omitempty"`
+	StudentID    *int64  `json:"student_id,omitempty"`
+	SubmissionID *int64  `json:"submission_id,omitempty"`
+	Status       *string `json:"status,omitempty"`
+	ApprovedOnly bool    `json:"approved_only,omitempty"` // Only feedbacks that need no approval or were approved
+	Page         int     `json:"page"`
+	Limit        int     `json:"limit"`
+}
+
+// FeedbackSearchResult is a feedback matching a search, with its relevance and a snippet of the
+// matched text
+type FeedbackSearchResult struct {
+	Feedback *Feedback `json:"feedback"`
+	Rank     float32   `json:"rank"`
+	Snippet  string    `json:"snippet"` // Matched content, or the title, with matches wrapped in **
+}
+
+// Sort fields of feedback lists
+const (
+	FeedbackSortCreatedAt = "created_at"
+	FeedbackSortUpdatedAt = "updated_at"
+	FeedbackSortTitle     = "title"
+)
+
+// FeedbackSort is the order of a feedback list. Ties are broken by ID in the same direction.
+// The zero value lists the newest feedbacks first.
+type FeedbackSort struct {
+	By        string `json:"by,omitempty"` // One of the FeedbackSort fields; empty sorts by creation time
+	Ascending bool   `json:"ascending,omitempty"`
+}
+
+// Field returns the sort field, FeedbackSortCreatedAt if none is set
+func (s FeedbackSort) Field() string {
+	if s.By == "" {
+		return FeedbackSortCreatedAt
+	}
+	return s.By
+}
+
+// FeedbackCursor is the position of a feedback in a list with the given sort. It holds the
+// values of every sort field; the ID breaks ties between feedbacks with equal values.
+type FeedbackCursor struct {
+	Sort      FeedbackSort `json:"sort"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Title     string       `json:"title,omitempty"`
+	ID        uuid.UUID    `json:"id"`
+}
+
+// CursorOf returns the position of a feedback in a list with the given sort
+func CursorOf(feedback *Feedback, sort FeedbackSort) *FeedbackCursor {
+	return &FeedbackCursor{
+		Sort:      sort,
+		CreatedAt: feedback.CreatedAt,
+		UpdatedAt: feedback.UpdatedAt,
+		Title:     feedback.Title,
+		ID:        feedback.ID,
+	}
 }
 
 // CanModify checks if a reviewer can modify the feedback (only reviewers who created it)
@@ -80,11 +752,636 @@ func (f *Feedback) CanView(userID int64, isReviewer bool) bool {
 	return f.StudentID == userID
 }
 
+// MaxCommentFilterUsers is the most authors a comment query may filter by
+const MaxCommentFilterUsers = 50
+
+// MaxCommentCountContents is the most contents BatchCountComments counts in one call
+const MaxCommentCountContents = 100
+
 // CommentFilter represents filtering options for comment queries
 type CommentFilter struct {
-	ContentID int64
-	ParentID  *string
-	Page      int32
-	Limit     int32
-	Type      string
+	ContentID      int64
+	ContentRef     string // Instead of ContentID for the types referenced by a string ID
+	ParentID       *string
+	UserIDs        []int64        // Only comments by these authors; empty means any author
+	CreatedAfter   *time.Time     // Only comments created at or after this time
+	CreatedBefore  *time.Time     // Only comments created before this time
+	UnansweredOnly bool           // Only top-level comments without replies
+	After          *CommentCursor // Continue after this comment instead of skipping to Page
+	ViewerUserID   int64          // Report this user's own reaction on each comment; 0 for none
+	Page           int32
+	Limit          int32
+	Type           string
+	Sort           string // One of the CommentSort values; empty lists newest first
+}
+
+// Orders of comment lists. Ties are broken by ID in the direction of the creation time, newest
+// first for CommentSortMostReplies.
+const (
+	CommentSortNewest      = "newest"
+	CommentSortOldest      = "oldest"
+	CommentSortMostReplies = "most_replies" // Most direct replies first, then newest first
+)
+
+// CommentCursor is the position of a comment in a list with the given sort; the ID breaks ties
+// between comments created in the same instant
+type CommentCursor struct {
+	Sort       string             `json:"sort,omitempty"` // Empty in tokens issued before lists could be sorted
+	ReplyCount int64              `json:"reply_count,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ID         primitive.ObjectID `json:"id"`
+}
+
+// CommentCursorOf returns the position of a comment in a list with the given sort
+func CommentCursorOf(comment *Comment, sort string) *CommentCursor {
+	return &CommentCursor{Sort: sort, ReplyCount: comment.ReplyCount, CreatedAt: comment.CreatedAt, ID: comment.ID}
+}
+
+// CommentDeletionRetention is how long deleted comments are remembered for the replays of
+// comment streams; a stream cannot replay from further back
+const CommentDeletionRetention = 7 * 24 * time.Hour
+
+// Kinds of CommentStreamEvent
+const (
+	CommentEventCreated = "created"
+	CommentEventUpdated = "updated" // Edited, hidden or shown by a moderator, or moved here by a thread merge
+	CommentEventDeleted = "deleted" // Deleted, or a reply deleted with its parent; the comment holds only its ID and content
+)
+
+// CommentStreamEvent is a change to a comment pushed to the streams of its content
+type CommentStreamEvent struct {
+	Kind       string         `json:"kind"`
+	Comment    *Comment       `json:"comment"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Replayed   bool           `json:"replayed"` // Read back for a since time or a resume position rather than observed live
+	Position   StreamPosition `json:"position"` // Where the stream resumes after this event
+}
+
+// CommentDeletion records the deletion of a comment and its replies so comment streams can
+// report it; it is kept for CommentDeletionRetention
+type CommentDeletion struct {
+	ID         primitive.ObjectID   `bson:"_id,omitempty"`
+	CommentIDs []primitive.ObjectID `bson:"comment_ids"` // The comment and its replies
+	ContentID  int64                `bson:"content_id"`
+	ContentRef string               `bson:"content_ref,omitempty"`
+	Type       string               `bson:"type"`
+	DeletedAt  time.Time            `bson:"deleted_at"`
+}
+
+// Events returns a deleted event for each comment of the deletion
+func (d *CommentDeletion) Events() []CommentStreamEvent {
+	events := make([]CommentStreamEvent, len(d.CommentIDs))
+	for i, id := range d.CommentIDs {
+		events[i] = CommentStreamEvent{
+			Kind:       CommentEventDeleted,
+			Comment:    &Comment{ID: id, ContentID: d.ContentID, ContentRef: d.ContentRef, Type: d.Type},
+			OccurredAt: d.DeletedAt,
+		}
+	}
+	return events
+}
+
+// CommentChangeFilter selects the comment changes read back for comment streams, oldest first
+type CommentChangeFilter struct {
+	Type       string // Only changes on this content; empty for changes on any content
+	ContentID  int64
+	ContentRef string
+	Since      time.Time            // Changes at or after this time
+	After      *CommentChangeCursor // Continue after this change
+	Limit      int
+}
+
+// CommentChangeCursor is the position of a change in the list of comment changes: the time of
+// the change and the ID of the comment or deletion record
+type CommentChangeCursor struct {
+	At time.Time
+	ID primitive.ObjectID
+}
+
+// PolicyOverrides holds the limits a course policy overrides; nil fields keep the global value.
+// The JSON names are the policy keys accepted by SetCoursePolicy.
+type PolicyOverrides struct {
+	MaxAttachmentsPerFeedback     *int      `json:"max_attachments_per_feedback,omitempty"`
+	MaxAttachmentBytesPerFeedback *int64    `json:"max_attachment_bytes_per_feedback,omitempty"`
+	MaxCommentDepth               *int32    `json:"max_comment_depth,omitempty"`
+	CommentEditWindow             *Duration `json:"comment_edit_window,omitempty"`
+	AllowFeedbackLock             *bool     `json:"allow_feedback_lock,omitempty"`
+}
+
+// Duration is a time.Duration written in JSON as a Go duration string, e.g. "30m"
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30m\"")
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// CoursePolicy overrides global limits for the feedbacks and comments of one course
+type CoursePolicy struct {
+	CourseID  string
+	Overrides PolicyOverrides
+	UpdatedBy int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// EffectivePolicy is the global configuration merged with a course policy
+type EffectivePolicy struct {
+	CourseID                      string // Empty when only global limits apply
+	MaxAttachmentsPerFeedback     int
+	MaxAttachmentBytesPerFeedback int64
+	MaxCommentDepth               int32
+	CommentEditWindow             time.Duration // 0 means comments can always be edited
+	AllowFeedbackLock             bool
+}
+
+// Retention datasets
+const (
+	RetentionUploadSessions  = "upload_sessions"
+	RetentionAuditLog        = "audit_log"
+	RetentionTransferUsage   = "transfer_usage"
+	RetentionIdempotencyKeys = "idempotency_keys"
+)
+
+// RetentionResult reports a retention run over one dataset
+type RetentionResult struct {
+	Dataset   string
+	Disabled  bool          // No retention is configured; nothing was pruned
+	Cutoff    time.Time     // Rows older than this are expired
+	Pruned    int64         // Rows deleted, or that would be deleted in a dry run
+	Protected int64         // Expired rows kept because they are still in use
+	Duration  time.Duration // Time spent on the run
+	Oldest    *time.Time    // Oldest remaining row, nil if the dataset is empty
+	Err       error
+}
+
+// Creation rate bucket sizes, named after the PostgreSQL date_trunc / MongoDB $dateTrunc units
+const (
+	RateBucketMinute = "minute"
+	RateBucketHour   = "hour"
+	RateBucketDay    = "day"
+	RateBucketWeek   = "week" // Weeks start on Monday
+)
+
+// RateBucket is the number of items created in the UTC time bucket starting at Start
+type RateBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// Completeness states of a submission
+const (
+	CompletenessComplete = "complete" // Every expected reviewer published feedback; without expected reviewers, any feedback is published
+	CompletenessPartial  = "partial"  // Some feedback is published, but expected reviewers have not published theirs
+	CompletenessMissing  = "missing"  // No feedback is published; drafts may exist
+)
+
+// ReviewerFeedbackCount is the number of feedbacks a reviewer wrote for a submission
+type ReviewerFeedbackCount struct {
+	SubmissionID   int64
+	ReviewerID     int64
+	Count          int64
+	PublishedCount int64
+	DraftCount     int64
+}
+
+// FeedbackStatsFilter selects the feedbacks aggregated by FeedbackRepository.Stats. At least
+// one of ReviewerID and StudentID is set.
+type FeedbackStatsFilter struct {
+	ReviewerID     *int64
+	StudentID      *int64
+	VisibleOnly    bool      // Only published feedbacks that need no approval or were approved
+	Since7Days     time.Time // Start of the last 7 days
+	Since30Days    time.Time // Start of the last 30 days
+	MaxSubmissions int       // Most submissions listed in PerSubmission
+}
+
+// SubmissionFeedbackCount is the number of feedbacks on a submission
+type SubmissionFeedbackCount struct {
+	SubmissionID int64
+	Count        int64
+}
+
+// FeedbackStats aggregates the feedbacks of a reviewer or student
+type FeedbackStats struct {
+	Total                int64
+	CreatedLast7Days     int64
+	CreatedLast30Days    int64
+	AverageContentLength float64                   // In characters; 0 without feedbacks
+	SubmissionCount      int64                     // Distinct submissions with feedback
+	PerSubmission        []SubmissionFeedbackCount // Submissions with the most feedbacks first, ties by ID
+}
+
+// SubmissionFeedbackSummary summarizes the feedback a student received on one submission
+type SubmissionFeedbackSummary struct {
+	SubmissionID      int64
+	FeedbackCount     int64
+	UnreadCount       int64     // Feedbacks the student has not acknowledged
+	LatestFeedbackAt  time.Time // Publication of the newest feedback
+	GradedCount       int64
+	AverageGradeRatio *float64 // Mean of points / max_points over graded feedbacks, nil if none is graded
+}
+
+// StudentFeedbackSummary summarizes the feedback a student can see, per submission and overall
+type StudentFeedbackSummary struct {
+	Submissions       []SubmissionFeedbackSummary // Most recent feedback first, ties by submission ID
+	FeedbackCount     int64
+	UnreadCount       int64
+	LatestFeedbackAt  *time.Time // nil without feedback
+	GradedCount       int64
+	AverageGradeRatio *float64 // Over every graded feedback, not the mean of the submission averages
+}
+
+// SubmissionCompleteness summarizes the feedback written for a submission
+type SubmissionCompleteness struct {
+	SubmissionID       int64
+	FeedbackCount      int64 // Drafts included
+	PublishedCount     int64
+	DraftCount         int64
+	ReviewerIDs        []int64 // Reviewers who wrote feedback, drafts included, ascending
+	MissingReviewerIDs []int64 // Expected reviewers without feedback, ascending
+	Status             string
+}
+
+// Dashboard enrichment sources
+const (
+	DashboardSourceAttachments = "attachments"
+)
+
+// DashboardRow is a reviewer dashboard row: a feedback with everything the dashboard shows for it
+type DashboardRow struct {
+	Feedback        *Feedback
+	ContentPreview  string
+	AttachmentCount *int32 // Nil when the attachments source is degraded
+}
+
+// DegradedSource reports an enrichment source that could not fill a dashboard page
+type DegradedSource struct {
+	Source string
+	Reason string
+}
+
+// DashboardPage is one page of a reviewer dashboard
+type DashboardPage struct {
+	Rows       []*DashboardRow
+	TotalCount int64
+	Degraded   []DegradedSource
+}
+
+// TransferUsage represents bytes transferred by a principal on a given UTC day
+type TransferUsage struct {
+	PrincipalID     int64     `json:"principal_id" db:"principal_id"`
+	Day             time.Time `json:"day" db:"day"`
+	BytesUploaded   int64     `json:"bytes_uploaded" db:"bytes_uploaded"`
+	BytesDownloaded int64     `json:"bytes_downloaded" db:"bytes_downloaded"`
+}
+
+// Audit log actions
+const (
+	AuditActionSoftDeleted = "feedback.soft_deleted"
+	AuditActionPurged      = "feedback.purged"
+	AuditActionLocked      = "feedback.locked"
+	AuditActionUnlocked    = "feedback.unlocked"
+	AuditActionPublished   = "feedback.published"
+	AuditActionSealed      = "feedback.sealed"
+	AuditActionTransferred = "feedback.transferred"
+	AuditActionArchived    = "feedback.archived"
+	AuditActionRestored    = "feedback.restored"
+
+	AuditActionApprovalRequested = "feedback.approval_requested"
+	AuditActionApproved          = "feedback.approved"
+	AuditActionChangesRequested  = "feedback.changes_requested"
+
+	AuditActionAttachmentCorrupted = "attachment.corrupted"
+)
+
+// AuditEntry represents a row of the feedback audit log
+type AuditEntry struct {
+	ID         int64     `json:"id" db:"id"`
+	FeedbackID uuid.UUID `json:"feedback_id" db:"feedback_id"`
+	ActorID    int64     `json:"actor_id" db:"actor_id"`
+	Action     string    `json:"action" db:"action"`
+	Details    string    `json:"details" db:"details"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SealManifest is the recorded state of a feedback at sealing. It is stored and hashed in its
+// JSON encoding, which anyone can hash again to check the seal.
+type SealManifest struct {
+	FeedbackID    uuid.UUID        `json:"feedback_id"`
+	Title         string           `json:"title"`
+	ContentSHA256 string           `json:"content_sha256"` // Hex SHA-256 of the Markdown content
+	ContentBytes  int64            `json:"content_bytes"`
+	UpdatedAt     time.Time        `json:"updated_at"`  // Last change of the feedback row
+	Attachments   []SealAttachment `json:"attachments"` // Ordered by filename
+}
+
+// SealAttachment is an attachment recorded in a seal manifest, hashed from its stored content
+type SealAttachment struct {
+	Filename   string    `json:"filename"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// FeedbackSeal is an append-only record of a feedback's manifest, chained to the seal before it
+type FeedbackSeal struct {
+	ID             int64     `json:"id" db:"id"`
+	FeedbackID     uuid.UUID `json:"feedback_id" db:"feedback_id"`
+	ActorID        int64     `json:"actor_id" db:"actor_id"`
+	Manifest       string    `json:"manifest" db:"manifest"`               // JSON encoding of a SealManifest, as hashed
+	ManifestSHA256 string    `json:"manifest_sha256" db:"manifest_sha256"` // Hex SHA-256 of Manifest
+	PreviousSHA256 string    `json:"previous_sha256" db:"previous_sha256"` // ChainSHA256 of the previous seal; empty for the first
+	ChainSHA256    string    `json:"chain_sha256" db:"chain_sha256"`       // Hex SHA-256 of PreviousSHA256 followed by ManifestSHA256
+	SealedAt       time.Time `json:"sealed_at" db:"sealed_at"`
+}
+
+// Components of a feedback that can diverge from its seal
+const (
+	SealComponentSeal       = "seal" // The stored seal does not match its own hashes
+	SealComponentFeedback   = "feedback"
+	SealComponentTitle      = "title"
+	SealComponentContent    = "content"
+	SealComponentAttachment = "attachment"
+)
+
+// SealDivergence is a difference between a seal and the current state of its feedback. Sealed
+// and Current are empty when the component did not exist at that point.
+type SealDivergence struct {
+	Component string `json:"component"`
+	Filename  string `json:"filename,omitempty"` // Set for attachments
+	Sealed    string `json:"sealed"`
+	Current   string `json:"current"`
+}
+
+// SealVerification is the outcome of checking a feedback against its latest seal
+type SealVerification struct {
+	Seal        *FeedbackSeal    `json:"seal"`
+	Divergences []SealDivergence `json:"divergences"` // Empty when nothing changed since sealing
+}
+
+// FeedbackDeletionResult represents the outcome of deleting one feedback in a bulk operation
+type FeedbackDeletionResult struct {
+	FeedbackID uuid.UUID
+	Deleted    bool
+	Err        error
+	Datasets   []DatasetDeletion // Hard deletions only
+}
+
+// DeletionIntent records that a feedback is being hard deleted. It is written before any data
+// is removed and deleted once the feedback is gone, so an interrupted deletion can be resumed.
+type DeletionIntent struct {
+	FeedbackID  uuid.UUID `json:"feedback_id" db:"feedback_id"`
+	RequestedBy int64     `json:"requested_by" db:"requested_by"`
+	Action      string    `json:"action" db:"action"`   // Audit action recorded when the deletion completes
+	Details     string    `json:"details" db:"details"` // Audit details recorded when the deletion completes
+	Attempts    int       `json:"attempts" db:"attempts"`
+	LastError   string    `json:"last_error" db:"last_error"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ContentOutboxEntry is feedback content committed to PostgreSQL and waiting to be written to
+// MongoDB. Version grows with every write of the feedback's content, so an entry is only
+// completed by the write of its latest content.
+type ContentOutboxEntry struct {
+	FeedbackID    uuid.UUID `json:"feedback_id" db:"feedback_id"`
+	Content       string    `json:"content" db:"content"`
+	Version       int64     `json:"version" db:"version"`
+	Attempts      int       `json:"attempts" db:"attempts"`
+	LastError     string    `json:"last_error" db:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ContentReconciliation reports a run over the content outbox
+type ContentReconciliation struct {
+	Applied   int   // Entries whose content was written to MongoDB
+	Failed    int   // Entries that failed again and were rescheduled
+	Remaining int64 // Entries still pending after the run
+}
+
+// Datasets removed when a feedback is hard deleted, in deletion order. The feedback row goes
+// last, so a deletion that failed midway can be retried until it completes.
+const (
+	DatasetStagedFiles        = "staged_files"        // Objects staged by the feedback's upload sessions
+	DatasetUploadSessions     = "upload_sessions"     // Upload session rows with their file records
+	DatasetAttachments        = "attachments"         // Objects under {feedbackID}/ in MinIO
+	DatasetAttachmentMetadata = "attachment_metadata" // feedback_assets rows
+	DatasetContentOutbox      = "content_outbox"      // Content still waiting to be written to MongoDB
+	DatasetContent            = "content"             // Content document in MongoDB
+	DatasetFeedback           = "feedback"            // The feedbacks row
+)
+
+// DatasetDeletion reports the deletion of one dataset of a feedback
+type DatasetDeletion struct {
+	Dataset  string
+	Deleted  int64 // Objects, rows or documents removed by this run
+	Attempts int
+	Err      error // Set if the dataset could not be deleted; later datasets were not attempted
+}
+
+// DeletionPending reports whether a hard deletion stopped at a dataset. The feedback is already
+// hidden; its remaining data is deleted by deletion recovery.
+func DeletionPending(datasets []DatasetDeletion) bool {
+	for _, dataset := range datasets {
+		if dataset.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachmentPrefix represents all objects stored under one {feedbackID}/ prefix in MinIO
+type AttachmentPrefix struct {
+	Prefix     string           // Raw prefix without the trailing slash
+	FeedbackID uuid.UUID        // uuid.Nil if the prefix is not a valid feedback ID
+	Objects    []AttachmentInfo // Filename and size of each object
+	Err        error            // Set if listing failed; no further prefixes follow
+}
+
+// FeedbackRef is a minimal reference to a feedback row used by maintenance scans
+type FeedbackRef struct {
+	ID      uuid.UUID
+	Deleted bool
+}
+
+// SimilarFeedback is an existing feedback of a reviewer whose title closely matches the title
+// of a feedback being created
+type SimilarFeedback struct {
+	ID           uuid.UUID
+	StudentID    int64
+	SubmissionID int64
+	Title        string
+	Distance     int // Edit distance between the normalized titles; 0 is an exact match
+	CreatedAt    time.Time
+}
+
+// ConsistencyOptions controls a Postgres/MinIO consistency check
+type ConsistencyOptions struct {
+	FullScan        bool // Check every feedback instead of a deterministic sample
+	SamplePercent   int  // Share of feedback IDs checked when FullScan is false (1-100)
+	DeleteOrphans   bool // Remove objects under prefixes with no feedback row
+	MarkForReupload bool // Flag feedbacks with missing objects as needing re-upload
+}
+
+// Consistency finding kinds
+const (
+	FindingMissingObjects = "missing_objects"
+	FindingOrphanPrefix   = "orphan_prefix"
+)
+
+// ConsistencyFinding describes one inconsistency between Postgres and MinIO
+type ConsistencyFinding struct {
+	Kind        string    `json:"kind"`
+	FeedbackID  uuid.UUID `json:"feedback_id"`            // Feedback with missing objects, or the orphan prefix ID if valid
+	Prefix      string    `json:"prefix,omitempty"`       // Orphan prefix as stored in MinIO
+	Missing     []string  `json:"missing,omitempty"`      // Filenames expected but not found
+	ObjectCount int       `json:"object_count,omitempty"` // Objects under an orphan prefix
+	Bytes       int64     `json:"bytes,omitempty"`        // Bytes under an orphan prefix
+	Repaired    bool      `json:"repaired"`               // The requested repair was applied
+	RepairError string    `json:"repair_error,omitempty"` // Why the repair failed
+}
+
+// ConsistencySummary totals a consistency check
+type ConsistencySummary struct {
+	FeedbacksChecked int64 `json:"feedbacks_checked"`
+	PrefixesChecked  int64 `json:"prefixes_checked"`
+	Objects          int64 `json:"objects"`
+	Bytes            int64 `json:"bytes"`
+	MissingObjects   int64 `json:"missing_objects"` // Feedbacks with missing objects
+	OrphanPrefixes   int64 `json:"orphan_prefixes"`
+	Repaired         int64 `json:"repaired"`
+}
+
+// AttachmentVerifyOptions controls a re-hash of stored attachments
+type AttachmentVerifyOptions struct {
+	FeedbackID *uuid.UUID // Verify only this feedback's attachments; nil verifies all
+	SampleRate float64    // Share of attachments verified (0-1]; 0 verifies all
+}
+
+// AttachmentChecksum is the recorded checksum of one attachment
+type AttachmentChecksum struct {
+	FeedbackID uuid.UUID
+	Filename   string
+	SHA256     string
+}
+
+// AttachmentMismatch describes a stored attachment that does not match its recorded checksum,
+// or could not be read to check it
+type AttachmentMismatch struct {
+	FeedbackID uuid.UUID `json:"feedback_id"`
+	Filename   string    `json:"filename"`
+	Expected   string    `json:"expected_sha256"`
+	Actual     string    `json:"actual_sha256,omitempty"`
+	Error      string    `json:"error,omitempty"` // Why the attachment could not be read
+}
+
+// AttachmentVerifySummary totals a re-hash of stored attachments
+type AttachmentVerifySummary struct {
+	Checked    int64 `json:"checked"`
+	Skipped    int64 `json:"skipped"` // Not sampled
+	Bytes      int64 `json:"bytes"`
+	Mismatches int64 `json:"mismatches"`
+	Failed     int64 `json:"failed"` // Could not be read
+}
+
+// Attachment migration journal statuses
+const (
+	MigrationCopied  = "copied"  // Copied and verified
+	MigrationSkipped = "skipped" // Already present at the new location, written after migration started
+	MigrationMissing = "missing" // Deleted from the legacy location after it was listed
+	MigrationFailed  = "failed"  // Retried by the next run
+)
+
+// MigrationEntry is the journaled outcome of migrating one attachment object
+type MigrationEntry struct {
+	Key      string `json:"key"` // Relative to the attachment location
+	Status   string `json:"status"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum,omitempty"` // "md5:<etag>" or "sha256:<hex>" the copy was verified with
+	Error    string `json:"error,omitempty"`
+}
+
+// MigrationCursor is how far the listing of the legacy location has progressed
+type MigrationCursor struct {
+	Key             string // Last listed key; listing resumes after it
+	ListingComplete bool   // The legacy location was listed to the end
+}
+
+// MigrationProgress summarizes the attachment migration journal
+type MigrationProgress struct {
+	Cursor          string  `json:"cursor"`
+	ListingComplete bool    `json:"listing_complete"`
+	Copied          int64   `json:"copied"`
+	Skipped         int64   `json:"skipped"`
+	Missing         int64   `json:"missing"`
+	Failed          int64   `json:"failed"`
+	Percent         float64 `json:"percent"`  // Share of journaled objects that are done; below 100 until listing is complete
+	Complete        bool    `json:"complete"` // Every legacy object is migrated, so the fallback can be disabled
+}
+
+// Finish computes Percent and Complete from the counts. Percent only reaches 100 once the
+// migration is complete.
+func (p *MigrationProgress) Finish() {
+	done := p.Copied + p.Skipped + p.Missing
+	p.Complete = p.ListingComplete && p.Failed == 0
+	if total := done + p.Failed; total > 0 {
+		p.Percent = float64(done) / float64(total) * 100
+	}
+	if p.Complete {
+		p.Percent = 100
+	} else if p.Percent >= 100 {
+		p.Percent = 99.9
+	}
+}
+
+// KeyRotationSummary totals a run re-wrapping attachment data keys with the active master key
+type KeyRotationSummary struct {
+	ActiveKeyID string `json:"active_key_id"`
+	Objects     int64  `json:"objects"`
+	Rewrapped   int64  `json:"rewrapped"`
+	Current     int64  `json:"current"`   // Already wrapped with the active key
+	Plaintext   int64  `json:"plaintext"` // Stored before encryption was enabled
+	Failed      int64  `json:"failed"`    // Wrapped with an unknown key or corrupted; logged
+}
+
+// Attachment metadata backfill outcomes of a prefix
+const (
+	BackfillDone     = "done"     // Every object of the prefix has a feedback_assets row
+	BackfillOrphaned = "orphaned" // The prefix belongs to no feedback row; nothing was recorded
+	BackfillFailed   = "failed"   // Retried by the next run
+)
+
+// BackfillEntry is the journaled outcome of backfilling the metadata of one attachment prefix
+type BackfillEntry struct {
+	Prefix      string `json:"prefix"`
+	Status      string `json:"status"`
+	RowsCreated int64  `json:"rows_created"`
+	Conflicts   int64  `json:"conflicts"` // Objects that already had a row, which was kept
+	Error       string `json:"error,omitempty"`
+}
+
+// BackfillProgress summarizes the attachment metadata backfill journal
+type BackfillProgress struct {
+	PrefixesDone     int64 `json:"prefixes_done"` // Including orphaned prefixes
+	PrefixesFailed   int64 `json:"prefixes_failed"`
+	PrefixesTotal    int64 `json:"prefixes_total"` // Found by the last complete listing; 0 before the first one
+	RowsCreated      int64 `json:"rows_created"`
+	ConflictsSkipped int64 `json:"conflicts_skipped"`
+	Complete         bool  `json:"complete"` // Every prefix is backfilled, so attachment lists can be read from PostgreSQL alone
 }
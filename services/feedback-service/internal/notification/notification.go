@@ -0,0 +1,245 @@
+// Package notification renders the body of feedback notification emails, so every email sent
+// by the notification service has the same layout.
+//
+// The templates are shipped in the binary. A file with the same name in the configured
+// template directory replaces the built-in one. All templates are parsed and test-rendered
+// when the renderer is created, so a broken override fails startup rather than a render.
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/google/uuid"
+)
+
+// Format is the representation requested by the notification service
+type Format int
+
+// Supported formats
+const (
+	FormatText Format = iota
+	FormatHTML
+)
+
+// Template file names, in the built-in templates and in the override directory
+const (
+	textTemplateName = "feedback.txt.tmpl"
+	htmlTemplateName = "feedback.html.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Attachment is an attachment as shown in a notification
+type Attachment struct {
+	Filename string
+	Size     int64
+	Name     string // Resource name for deep links
+}
+
+// Feedback is the data available to the templates
+type Feedback struct {
+	Name            string // Resource name for deep links
+	Title           string
+	ReviewerName    string // Empty when the reviewer's display name is unknown
+	LabTitle        string
+	StudentName     string
+	SubmissionLabel string
+	Content         string // Shortened to the configured length when Truncated is set
+	Truncated       bool
+	Attachments     []Attachment
+}
+
+// Result is a rendered notification body
+type Result struct {
+	Body        string
+	ContentType string
+	Truncated   bool // The feedback content was shortened
+}
+
+// executor is implemented by both text and HTML templates
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Renderer renders feedback notifications. It is safe for concurrent use.
+type Renderer struct {
+	text            executor
+	html            executor
+	maxContentChars int
+}
+
+// templateFuncs are available in both templates
+var templateFuncs = map[string]any{
+	"size": formatSize,
+}
+
+// NewRenderer parses the notification templates, preferring files in templateDir over the
+// built-in ones (an empty templateDir uses only the built-in templates). Feedback content is
+// shortened to maxContentChars characters.
+func NewRenderer(templateDir string, maxContentChars int) (*Renderer, error) {
+	if maxContentChars <= 0 {
+		return nil, fmt.Errorf("maximum content length must be positive")
+	}
+
+	source, err := loadTemplate(templateDir, textTemplateName)
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.New(textTemplateName).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification template: %w", err)
+	}
+
+	source, err = loadTemplate(templateDir, htmlTemplateName)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(htmlTemplateName).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification template: %w", err)
+	}
+
+	r := &Renderer{text: text, html: html, maxContentChars: maxContentChars}
+
+	// Templates only report unknown fields when executed, so render a sample once
+	for _, format := range []Format{FormatText, FormatHTML} {
+		if _, err := r.execute(sampleFeedback(), format); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// loadTemplate returns the override of a template if templateDir has one, otherwise the
+// built-in template
+func loadTemplate(templateDir, name string) (string, error) {
+	if templateDir != "" {
+		data, err := os.ReadFile(filepath.Join(templateDir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to read notification template override: %w", err)
+		}
+	}
+
+	data, err := builtinTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read built-in notification template: %w", err)
+	}
+	return string(data), nil
+}
+
+// sampleFeedback is rendered at startup to catch template errors
+func sampleFeedback() Feedback {
+	return Feedback{
+		Name:            resourcename.Feedback(uuid.Nil),
+		Title:           "Sample feedback",
+		ReviewerName:    "Sample reviewer",
+		LabTitle:        "Sample lab",
+		StudentName:     "Sample student",
+		SubmissionLabel: "Sample submission",
+		Content:         "Sample content",
+		Truncated:       true,
+		Attachments: []Attachment{{
+			Filename: "sample.txt",
+			Size:     1024,
+			Name:     resourcename.Attachment(uuid.Nil, "sample.txt"),
+		}},
+	}
+}
+
+// Render renders the notification for a feedback and its attachments, listed in the given order
+func (r *Renderer) Render(feedback *models.Feedback, attachments []*models.AttachmentInfo, format Format) (Result, error) {
+	data := Feedback{
+		Name:  resourcename.Feedback(feedback.ID),
+		Title: feedback.Title,
+	}
+	if snapshot := feedback.Snapshot; snapshot != nil {
+		data.ReviewerName = snapshot.ReviewerDisplayName
+		data.LabTitle = snapshot.LabTitle
+		data.StudentName = snapshot.StudentDisplayName
+		data.SubmissionLabel = snapshot.SubmissionLabel
+	}
+	data.Content, data.Truncated = truncate(strings.TrimSpace(feedback.Content), r.maxContentChars)
+	for _, attachment := range attachments {
+		data.Attachments = append(data.Attachments, Attachment{
+			Filename: attachment.Filename,
+			Size:     attachment.Size,
+			Name:     resourcename.Attachment(feedback.ID, attachment.Filename),
+		})
+	}
+
+	body, err := r.execute(data, format)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Body: body, Truncated: data.Truncated}
+	if format == FormatHTML {
+		result.ContentType = "text/html; charset=utf-8"
+	} else {
+		result.ContentType = "text/plain; charset=utf-8"
+	}
+	return result, nil
+}
+
+// execute runs the template of a format
+func (r *Renderer) execute(data Feedback, format Format) (string, error) {
+	tmpl := r.text
+	if format == FormatHTML {
+		tmpl = r.html
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render notification: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// truncate shortens content to at most maxChars characters, cutting at the last whitespace
+// when there is one in the second half so words are not split
+func truncate(content string, maxChars int) (string, bool) {
+	runes := []rune(content)
+	if len(runes) <= maxChars {
+		return content, false
+	}
+
+	cut := maxChars
+	for i := maxChars; i > maxChars/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace), true
+}
+
+// formatSize formats a byte count for display, e.g. "1.5 MB"
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, exp := float64(size)/unit, 0
+	for value >= unit && exp < 3 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGT"[exp])
+}
@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the rendered output")
+
+// goldenFeedbackID keeps resource names stable across runs
+var goldenFeedbackID = uuid.MustParse("0b6f0a3c-5d2e-4f1a-9c8b-7e6d5c4b3a21")
+
+func TestRenderGolden(t *testing.T) {
+	snapshot := &models.SubmissionSnapshot{
+		LabTitle:            "Lab 3: Sorting",
+		StudentDisplayName:  "Alex Kim",
+		SubmissionLabel:     "Attempt 2",
+		ReviewerDisplayName: "Dr. Rivera",
+	}
+	attachments := []*models.AttachmentInfo{
+		{Filename: "report.pdf", Size: 1536 * 1024},
+		{Filename: "notes.txt", Size: 512},
+	}
+
+	tests := []struct {
+		name        string
+		feedback    *models.Feedback
+		attachments []*models.AttachmentInfo
+		maxChars    int
+	}{
+		{
+			name:        "full",
+			feedback:    &models.Feedback{Title: "Good work", Content: "The merge step is correct.\n\nConsider the empty input.", Snapshot: snapshot},
+			attachments: attachments,
+			maxChars:    500,
+		},
+		{
+			name:     "no snapshot or attachments",
+			feedback: &models.Feedback{Title: "Good work", Content: "Well done."},
+			maxChars: 500,
+		},
+		{
+			name:     "truncated",
+			feedback: &models.Feedback{Title: "Long review", Content: "The first part is fine but the second part repeats the same loop twice", Snapshot: snapshot},
+			maxChars: 40,
+		},
+		{
+			name: "markup in the data",
+			feedback: &models.Feedback{
+				Title:   `<script>alert("x")</script> & more`,
+				Content: "Use a < b && c > d",
+				Snapshot: &models.SubmissionSnapshot{
+					LabTitle:           "Lab <1>",
+					StudentDisplayName: "O'Brien & Co",
+				},
+			},
+			attachments: []*models.AttachmentInfo{{Filename: `"quoted" <name>.txt`, Size: 3 * 1024 * 1024 * 1024}},
+			maxChars:    500,
+		},
+	}
+	formats := []struct {
+		ext    string
+		format Format
+	}{
+		{"txt", FormatText},
+		{"html", FormatHTML},
+	}
+	for _, tt := range tests {
+		for _, f := range formats {
+			t.Run(tt.name+"/"+f.ext, func(t *testing.T) {
+				r, err := NewRenderer("", tt.maxChars)
+				if err != nil {
+					t.Fatalf("NewRenderer() error = %v", err)
+				}
+				feedback := *tt.feedback
+				feedback.ID = goldenFeedbackID
+				result, err := r.Render(&feedback, tt.attachments, f.format)
+				if err != nil {
+					t.Fatalf("Render() error = %v", err)
+				}
+
+				golden := filepath.Join("testdata", strings.ReplaceAll(tt.name, " ", "_")+"."+f.ext+".golden")
+				if *update {
+					if err := os.WriteFile(golden, []byte(result.Body), 0o644); err != nil {
+						t.Fatalf("write golden file: %v", err)
+					}
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("read golden file (run with -update to create it): %v", err)
+				}
+				if result.Body != string(want) {
+					t.Errorf("Render() does not match %s (run with -update if the change is intended):\n%s", golden, result.Body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package pagetoken encodes list cursors as opaque, signed page tokens.
+//
+// A token is the URL-safe base64 encoding of a JSON envelope followed by a dot and
+// the URL-safe base64 HMAC-SHA256 of that envelope. The envelope records the kind of
+// list the token belongs to, the payload version and an expiry, so tokens cannot be
+// altered, replayed after they expire or presented to a different list endpoint.
+package pagetoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kind identifies the list a token was issued for
+type Kind string
+
+// Kinds of lists that issue page tokens
+const (
+	KindFeedback     Kind = "feedback"
+	KindComment      Kind = "comment"
+	KindCommentReply Kind = "comment_reply"
+	KindAttachment   Kind = "attachment"
+	KindEventStream  Kind = "event_stream" // resume tokens of the watch streams
+)
+
+// Reasons reported by Error
+const (
+	ReasonMalformed  = "malformed"
+	ReasonTampered   = "tampered"
+	ReasonExpired    = "expired"
+	ReasonWrongKind  = "wrong_kind"
+	ReasonBadVersion = "unsupported_version"
+	ReasonBadPayload = "invalid_payload"
+)
+
+// Error is returned when a page token cannot be accepted.
+// gRPC handlers report it as InvalidArgument.
+type Error struct {
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid page token (%s): %v", e.Reason, e.Err)
+	}
+	return fmt.Sprintf("invalid page token (%s)", e.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsInvalid reports whether err was caused by an unacceptable page token
+func IsInvalid(err error) bool {
+	var tokenErr *Error
+	return errors.As(err, &tokenErr)
+}
+
+// envelope is the signed part of a token
+type envelope struct {
+	Kind      Kind            `json:"k"`
+	Version   int             `json:"v"`
+	ExpiresAt int64           `json:"e"`
+	Payload   json.RawMessage `json:"p"`
+}
+
+// Token is a decoded and verified page token
+type Token struct {
+	Kind    Kind
+	Version int
+	payload json.RawMessage
+}
+
+// Unmarshal decodes the token payload into v. Callers pick v based on Version.
+func (t *Token) Unmarshal(v any) error {
+	if err := json.Unmarshal(t.payload, v); err != nil {
+		return &Error{Reason: ReasonBadPayload, Err: err}
+	}
+	return nil
+}
+
+// Codec signs and verifies page tokens with a shared secret
+type Codec struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewCodec creates a codec signing tokens with secret. Tokens expire after ttl;
+// a non-positive ttl issues tokens that never expire.
+func NewCodec(secret []byte, ttl time.Duration) *Codec {
+	return &Codec{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Encode signs payload as a token for the given list kind and payload version
+func (c *Codec) Encode(kind Kind, version int, payload any) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal page token payload: %w", err)
+	}
+
+	env := envelope{
+		Kind:    kind,
+		Version: version,
+		Payload: raw,
+	}
+	if c.ttl > 0 {
+		env.ExpiresAt = c.now().Add(c.ttl).Unix()
+	}
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal page token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(body) + "." + base64.RawURLEncoding.EncodeToString(c.sign(body)), nil
+}
+
+// Decode verifies a token issued for kind and returns it. maxVersion is the newest
+// payload version the caller understands; newer tokens are rejected.
+func (c *Codec) Decode(token string, kind Kind, maxVersion int) (*Token, error) {
+	encodedBody, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, &Error{Reason: ReasonMalformed}
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encodedBody)
+	if err != nil {
+		return nil, &Error{Reason: ReasonMalformed, Err: err}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, &Error{Reason: ReasonMalformed, Err: err}
+	}
+	if !hmac.Equal(sig, c.sign(body)) {
+		return nil, &Error{Reason: ReasonTampered}
+	}
+
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, &Error{Reason: ReasonMalformed, Err: err}
+	}
+	if env.Kind != kind {
+		return nil, &Error{Reason: ReasonWrongKind, Err: fmt.Errorf("token issued for %q lists", env.Kind)}
+	}
+	if env.Version < 1 || env.Version > maxVersion {
+		return nil, &Error{Reason: ReasonBadVersion, Err: fmt.Errorf("version %d", env.Version)}
+	}
+	if env.ExpiresAt != 0 && c.now().Unix() > env.ExpiresAt {
+		return nil, &Error{Reason: ReasonExpired}
+	}
+
+	return &Token{
+		Kind:    env.Kind,
+		Version: env.Version,
+		payload: env.Payload,
+	}, nil
+}
+
+// sign computes the HMAC-SHA256 of body
+func (c *Codec) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
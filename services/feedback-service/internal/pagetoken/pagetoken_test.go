@@ -0,0 +1,145 @@
+package pagetoken
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type cursor struct {
+	ID    int64  `json:"id"`
+	After string `json:"after"`
+}
+
+func newTestCodec(secret string, ttl time.Duration, now time.Time) *Codec {
+	codec := NewCodec([]byte(secret), ttl)
+	codec.now = func() time.Time { return now }
+	return codec
+}
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		kind    Kind
+		version int
+		payload cursor
+	}{
+		{name: "feedback", ttl: time.Hour, kind: KindFeedback, version: 1, payload: cursor{ID: 42, After: "2025-06-01T11:00:00Z"}},
+		{name: "comment", ttl: time.Hour, kind: KindComment, version: 2, payload: cursor{ID: 7, After: "abc"}},
+		{name: "never expires", ttl: 0, kind: KindAttachment, version: 1, payload: cursor{ID: 1}},
+		{name: "empty payload", ttl: time.Minute, kind: KindCommentReply, version: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec := newTestCodec("secret", tt.ttl, now)
+			token, err := codec.Encode(tt.kind, tt.version, tt.payload)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			decoded, err := codec.Decode(token, tt.kind, tt.version)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.Kind != tt.kind || decoded.Version != tt.version {
+				t.Errorf("Decode() = kind %q version %d, want %q %d", decoded.Kind, decoded.Version, tt.kind, tt.version)
+			}
+			var got cursor
+			if err := decoded.Unmarshal(&got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.payload {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.payload)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	codec := newTestCodec("secret", time.Hour, now)
+	token, err := codec.Encode(KindFeedback, 1, cursor{ID: 42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	body, sig, _ := strings.Cut(token, ".")
+
+	// A body re-signed with another secret, and one with a payload changed but the old signature
+	forged, err := newTestCodec("other-secret", time.Hour, now).Encode(KindFeedback, 1, cursor{ID: 43})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	altered := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(raw), `"id":42`, `"id":43`, 1))) + "." + sig
+
+	tests := []struct {
+		name       string
+		codec      *Codec
+		token      string
+		kind       Kind
+		maxVersion int
+		reason     string
+	}{
+		{name: "no signature", codec: codec, token: body, kind: KindFeedback, maxVersion: 1, reason: ReasonMalformed},
+		{name: "bad body encoding", codec: codec, token: "!!." + sig, kind: KindFeedback, maxVersion: 1, reason: ReasonMalformed},
+		{name: "bad signature encoding", codec: codec, token: body + ".!!", kind: KindFeedback, maxVersion: 1, reason: ReasonMalformed},
+		{name: "altered payload", codec: codec, token: altered, kind: KindFeedback, maxVersion: 1, reason: ReasonTampered},
+		{name: "truncated signature", codec: codec, token: token[:len(token)-3], kind: KindFeedback, maxVersion: 1, reason: ReasonTampered},
+		{name: "other secret", codec: codec, token: forged, kind: KindFeedback, maxVersion: 1, reason: ReasonTampered},
+		{name: "comment list", codec: codec, token: token, kind: KindComment, maxVersion: 1, reason: ReasonWrongKind},
+		{name: "reply list", codec: codec, token: token, kind: KindCommentReply, maxVersion: 1, reason: ReasonWrongKind},
+		{name: "newer version", codec: codec, token: token, kind: KindFeedback, maxVersion: 0, reason: ReasonBadVersion},
+		{name: "expired", codec: newTestCodec("secret", time.Hour, now.Add(time.Hour+time.Second)), token: token, kind: KindFeedback, maxVersion: 1, reason: ReasonExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.codec.Decode(tt.token, tt.kind, tt.maxVersion)
+			var tokenErr *Error
+			if !errors.As(err, &tokenErr) {
+				t.Fatalf("Decode() error = %v, want *Error", err)
+			}
+			if tokenErr.Reason != tt.reason {
+				t.Errorf("Decode() reason = %q, want %q", tokenErr.Reason, tt.reason)
+			}
+			if !IsInvalid(err) {
+				t.Errorf("IsInvalid(%v) = false", err)
+			}
+		})
+	}
+}
+
+func TestDecodeAtExpiry(t *testing.T) {
+	issued := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	token, err := newTestCodec("secret", time.Hour, issued).Encode(KindComment, 1, cursor{ID: 1})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if _, err := newTestCodec("secret", time.Hour, issued.Add(time.Hour)).Decode(token, KindComment, 1); err != nil {
+		t.Errorf("Decode() at expiry error = %v, want nil", err)
+	}
+}
+
+func TestUnmarshalBadPayload(t *testing.T) {
+	codec := newTestCodec("secret", time.Hour, time.Now())
+	token, err := codec.Encode(KindFeedback, 1, []string{"not", "a", "cursor"})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	decoded, err := codec.Decode(token, KindFeedback, 1)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	var got cursor
+	err = decoded.Unmarshal(&got)
+	var tokenErr *Error
+	if !errors.As(err, &tokenErr) || tokenErr.Reason != ReasonBadPayload {
+		t.Errorf("Unmarshal() error = %v, want reason %q", err, ReasonBadPayload)
+	}
+}
@@ -0,0 +1,218 @@
+// Package render converts Markdown content into client-friendly representations.
+//
+// SAFE_HTML output is produced by goldmark (raw HTML in the source is dropped) and then
+// sanitized with bluemonday's user-generated-content policy. PLAIN_TEXT output walks the
+// Markdown AST and keeps only the text. Both are capped at a maximum output size.
+package render
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Format is the representation requested by a client
+type Format int
+
+// Supported formats
+const (
+	FormatRaw Format = iota
+	FormatPlainText
+	FormatSafeHTML
+)
+
+// truncationMarker is appended to output cut by the size guard
+const truncationMarker = "…"
+
+// Result is a rendered representation of a piece of content
+type Result struct {
+	Content   string
+	Truncated bool // The output exceeded the size limit and was shortened
+}
+
+// cacheKey identifies a rendering of a specific revision of a piece of content
+type cacheKey struct {
+	id        string
+	updatedAt int64
+	format    Format
+}
+
+// Renderer renders Markdown and caches results per content revision.
+// It is safe for concurrent use.
+type Renderer struct {
+	markdown       goldmark.Markdown
+	policy         *bluemonday.Policy
+	maxOutputBytes int
+	maxEntries     int
+
+	mu    sync.Mutex
+	cache map[cacheKey]Result
+}
+
+// NewRenderer creates a renderer whose output is limited to maxOutputBytes and which
+// caches up to maxEntries results (0 disables caching)
+func NewRenderer(maxOutputBytes, maxEntries int) *Renderer {
+	return &Renderer{
+		markdown:       goldmark.New(),
+		policy:         bluemonday.UGCPolicy(),
+		maxOutputBytes: maxOutputBytes,
+		maxEntries:     maxEntries,
+		cache:          make(map[cacheKey]Result),
+	}
+}
+
+// Render renders content in the given format. id and updatedAt identify the revision for
+// caching, so an edited comment is rendered again. FormatRaw returns content unchanged.
+func (r *Renderer) Render(id string, updatedAt time.Time, content string, format Format) Result {
+	if format == FormatRaw {
+		return Result{Content: content}
+	}
+
+	key := cacheKey{id: id, updatedAt: updatedAt.UnixNano(), format: format}
+	if r.maxEntries > 0 {
+		r.mu.Lock()
+		cached, ok := r.cache[key]
+		r.mu.Unlock()
+		if ok {
+			return cached
+		}
+	}
+
+	var result Result
+	switch format {
+	case FormatSafeHTML:
+		result = r.safeHTML(content)
+	default:
+		result = r.plainText(content)
+	}
+
+	if r.maxEntries > 0 {
+		r.mu.Lock()
+		// Bounded by resetting: cheap, and renders are cheap to redo
+		if len(r.cache) >= r.maxEntries {
+			r.cache = make(map[cacheKey]Result)
+		}
+		r.cache[key] = result
+		r.mu.Unlock()
+	}
+
+	return result
+}
+
+// Forget drops the cached renderings of every revision of a piece of content. Stale revisions
+// are never served, so this only frees their space early.
+func (r *Renderer) Forget(id string) {
+	if r.maxEntries == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.cache {
+		if key.id == id {
+			delete(r.cache, key)
+		}
+	}
+}
+
+// safeHTML renders Markdown to sanitized HTML. Output over the size limit is replaced
+// with the escaped, truncated plain text so no tag is ever cut in half.
+func (r *Renderer) safeHTML(content string) Result {
+	var buf bytes.Buffer
+	if err := r.markdown.Convert([]byte(content), &buf); err != nil {
+		return r.escapedPlainText(content)
+	}
+
+	sanitized := r.policy.SanitizeBytes(buf.Bytes())
+	if r.maxOutputBytes > 0 && len(sanitized) > r.maxOutputBytes {
+		return r.escapedPlainText(content)
+	}
+	return Result{Content: string(sanitized)}
+}
+
+// escapedPlainText renders content as plain text wrapped in a single escaped paragraph
+func (r *Renderer) escapedPlainText(content string) Result {
+	plain := r.plainText(content)
+	escaped := "<p>" + html.EscapeString(plain.Content) + "</p>"
+	if r.maxOutputBytes > 0 && len(escaped) > r.maxOutputBytes {
+		// Escaping grows the text; keep the longest prefix whose escaped form leaves room for
+		// the wrapper
+		limit := r.maxOutputBytes - len("<p></p>") - len(truncationMarker)
+		cut, size := plain.Content, 0
+		for i, c := range plain.Content {
+			if size += len(html.EscapeString(string(c))); size > limit {
+				cut = plain.Content[:i]
+				break
+			}
+		}
+		escaped = "<p>" + html.EscapeString(cut) + truncationMarker + "</p>"
+		plain.Truncated = true
+	}
+	return Result{Content: escaped, Truncated: plain.Truncated}
+}
+
+// plainText extracts the text of a Markdown document, one block per line
+func (r *Renderer) plainText(content string) Result {
+	source := []byte(content)
+	doc := r.markdown.Parser().Parse(text.NewReader(source))
+
+	var sb strings.Builder
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock && n.Kind() != ast.KindDocument && n.NextSibling() != nil {
+				sb.WriteString("\n")
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			sb.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				sb.WriteString("\n")
+			}
+		case *ast.String:
+			sb.Write(node.Value)
+		case *ast.AutoLink:
+			sb.Write(node.URL(source))
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				line := lines.At(i)
+				sb.Write(line.Value(source))
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.RawHTML, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	plain := strings.TrimSpace(sb.String())
+	if r.maxOutputBytes > 0 && len(plain) > r.maxOutputBytes {
+		limit := r.maxOutputBytes - len(truncationMarker)
+		return Result{Content: truncate(plain, limit) + truncationMarker, Truncated: true}
+	}
+	return Result{Content: plain}
+}
+
+// truncate cuts s to at most limit bytes without splitting a UTF-8 sequence
+func truncate(s string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
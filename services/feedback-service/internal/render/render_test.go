@@ -0,0 +1,103 @@
+package render
+
+import (
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBytes      int
+		content       string
+		format        Format
+		want          string
+		wantTruncated bool
+	}{
+		{name: "raw", content: "**bold** <b>x</b>", format: FormatRaw, want: "**bold** <b>x</b>"},
+		{name: "plain text emphasis", content: "Some **bold** and _italic_ text", format: FormatPlainText, want: "Some bold and italic text"},
+		{name: "plain text blocks", content: "# Title\n\nFirst paragraph\n\n- one\n- two", format: FormatPlainText, want: "Title\nFirst paragraph\none\ntwo"},
+		{name: "plain text link", content: "See [the docs](https://example.com) or <https://example.org>", format: FormatPlainText, want: "See the docs or https://example.org"},
+		{name: "plain text code block", content: "```go\nx := 1\n```", format: FormatPlainText, want: "x := 1"},
+		{name: "plain text drops raw HTML", content: "a <span>b</span> c\n\n<div>block</div>", format: FormatPlainText, want: "a b c"},
+		{name: "plain text truncated", maxBytes: 10, content: "abcdefghijklmnop", format: FormatPlainText, want: "abcdefg…", wantTruncated: true},
+		{name: "plain text truncated at a rune", maxBytes: 8, content: "ééééé", format: FormatPlainText, want: "éé…", wantTruncated: true},
+		{name: "safe HTML", content: "Some **bold** text", format: FormatSafeHTML, want: "<p>Some <strong>bold</strong> text</p>\n"},
+		{name: "safe HTML drops raw HTML", content: "<script>alert(1)</script>\n\nhi <img src=x onerror=alert(1)>", format: FormatSafeHTML, want: "\n<p>hi </p>\n"},
+		{name: "safe HTML drops javascript links", content: "[x](javascript:alert(1))", format: FormatSafeHTML, want: "<p>x</p>\n"},
+		{name: "safe HTML over the limit falls back to text", maxBytes: 40, content: "Some **bold** text & more", format: FormatSafeHTML, want: "<p>Some bold text &amp; more</p>"},
+		{name: "safe HTML fallback truncated", maxBytes: 20, content: "**a & b & c & d & e**", format: FormatSafeHTML, want: "<p>a &amp; b …</p>", wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRenderer(tt.maxBytes, 0).Render("id", time.Time{}, tt.content, tt.format)
+			if got.Content != tt.want || got.Truncated != tt.wantTruncated {
+				t.Errorf("Render() = %q, truncated %v, want %q, truncated %v", got.Content, got.Truncated, tt.want, tt.wantTruncated)
+			}
+			if tt.maxBytes > 0 && len(got.Content) > tt.maxBytes {
+				t.Errorf("Render() = %d bytes, want at most %d", len(got.Content), tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestRenderCache(t *testing.T) {
+	r := NewRenderer(0, 2)
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	edited := created.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		id        string
+		updatedAt time.Time
+		content   string
+		format    Format
+		want      string
+	}{
+		{name: "first render", id: "a", updatedAt: created, content: "**one**", format: FormatPlainText, want: "one"},
+		{name: "cached revision", id: "a", updatedAt: created, content: "**changed**", format: FormatPlainText, want: "one"},
+		{name: "other format", id: "a", updatedAt: created, content: "**one**", format: FormatSafeHTML, want: "<p><strong>one</strong></p>\n"},
+		{name: "edited revision", id: "a", updatedAt: edited, content: "**two**", format: FormatPlainText, want: "two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Render(tt.id, tt.updatedAt, tt.content, tt.format); got.Content != tt.want {
+				t.Errorf("Render() = %q, want %q", got.Content, tt.want)
+			}
+			if len(r.cache) > 2 {
+				t.Errorf("cache holds %d entries, want at most 2", len(r.cache))
+			}
+		})
+	}
+
+	r.Render("b", created, "**b**", FormatPlainText)
+	r.Forget("b")
+	if got := r.Render("b", created, "**forgotten**", FormatPlainText); got.Content != "forgotten" {
+		t.Errorf("Render() after Forget() = %q, want a fresh rendering", got.Content)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		limit int
+		want  string
+	}{
+		{name: "shorter", s: "abc", limit: 5, want: "abc"},
+		{name: "exact", s: "abc", limit: 3, want: "abc"},
+		{name: "cut", s: "abcdef", limit: 4, want: "abcd"},
+		{name: "inside a rune", s: "aé", limit: 2, want: "a"},
+		{name: "zero", s: "abc", limit: 0, want: ""},
+		{name: "negative", s: "abc", limit: -1, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.limit)
+			if got != tt.want || !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
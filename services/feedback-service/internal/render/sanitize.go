@@ -0,0 +1,115 @@
+package render
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sourceTags are the HTML tags kept in stored Markdown, without their attributes. They are
+// inline formatting Markdown has no syntax for, or the HTML spelling of what it has.
+var sourceTags = map[atom.Atom]bool{
+	atom.B: true, atom.Strong: true, atom.I: true, atom.Em: true,
+	atom.Code: true, atom.Pre: true, atom.Kbd: true,
+	atom.Sub: true, atom.Sup: true, atom.Del: true, atom.S: true,
+	atom.Br: true,
+}
+
+// droppedTags are removed from stored Markdown together with everything inside them
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Noscript: true, atom.Template: true, atom.Textarea: true,
+	atom.Title: true, atom.Xmp: true, atom.Noembed: true, atom.Noframes: true,
+}
+
+// SanitizeSource removes unsafe HTML from Markdown before it is stored. Tags in sourceTags
+// are kept without attributes, tags in droppedTags are removed with their content, and every
+// other HTML tag, comment and doctype is removed while its text is kept. Everything else,
+// including entities and text such as "a < b" or "List<T>" whose tag name is not HTML, is
+// kept byte for byte, unless such a "tag" has attributes browsers would act on. Tags inside
+// Markdown code spans are HTML too and are treated the same.
+func SanitizeSource(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+
+	z := html.NewTokenizer(strings.NewReader(content))
+	var dropping atom.Atom // Tag whose content is being dropped, 0 when none
+	depth := 0             // Nesting of dropping inside itself
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF && dropping == 0 {
+				b.Write(z.Raw()) // An unterminated "<tag" at the end is text
+			}
+			return b.String()
+		}
+
+		// TagName lowercases the token in place, so the raw bytes are copied first
+		raw := string(z.Raw())
+		var tag atom.Atom
+		var unsafeAttr bool
+		switch tt {
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag = atom.Lookup(name)
+			unsafeAttr = hasAttr && hasUnsafeAttr(z)
+		}
+
+		if dropping != 0 {
+			switch {
+			case tag == dropping && tt == html.StartTagToken:
+				depth++
+			case tag == dropping && tt == html.EndTagToken:
+				if depth--; depth == 0 {
+					dropping = 0
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			b.WriteString(raw)
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			switch {
+			case tag == 0 && !unsafeAttr:
+				b.WriteString(raw) // Not an HTML tag, such as a generic type
+			case droppedTags[tag]:
+				if tt == html.StartTagToken {
+					dropping, depth = tag, 1
+				}
+			case sourceTags[tag]:
+				b.WriteString(canonicalTag(tag, tt))
+			}
+		}
+		// Comments, doctypes and other tags are removed
+	}
+}
+
+// hasUnsafeAttr reports whether the current tag has an event handler, style or URL attribute,
+// which browsers honor even on elements they do not know
+func hasUnsafeAttr(z *html.Tokenizer) bool {
+	for {
+		key, _, more := z.TagAttr()
+		switch name := string(key); {
+		case strings.HasPrefix(name, "on"), name == "style", name == "href", name == "src", name == "srcset", name == "action", name == "formaction":
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// canonicalTag writes an allowed tag without attributes
+func canonicalTag(tag atom.Atom, tt html.TokenType) string {
+	switch tt {
+	case html.EndTagToken:
+		return "</" + tag.String() + ">"
+	case html.SelfClosingTagToken:
+		return "<" + tag.String() + " />"
+	}
+	return "<" + tag.String() + ">"
+}
@@ -0,0 +1,34 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain Markdown", content: "**bold** and `code`\n\n- item", want: "**bold** and `code`\n\n- item"},
+		{name: "text that looks like tags", content: "a < b and List<T> & Map<K, V>", want: "a < b and List<T> & Map<K, V>"},
+		{name: "entities kept", content: "&lt;b&gt; &amp;", want: "&lt;b&gt; &amp;"},
+		{name: "allowed tags lose attributes", content: `<b class="x">bold</b> x<sup title="t">2</sup><br/>`, want: "<b>bold</b> x<sup>2</sup><br />"},
+		{name: "script dropped with content", content: "a<script>alert(1)</script>b", want: "ab"},
+		{name: "nested dropped tags", content: "a<object><object>x</object>y</object>b", want: "ab"},
+		{name: "other tags removed, text kept", content: `<div><span onclick="x()">text</span></div>`, want: "text"},
+		{name: "unknown tag with handler removed", content: `<foo onmouseover="x()">hover</foo>`, want: "hover</foo>"},
+		{name: "comment removed", content: "a<!-- hidden -->b", want: "ab"},
+		{name: "unterminated tag kept as text", content: "x <b", want: "x <b"},
+		{name: "tags in code spans", content: "`<script>x</script>`", want: "``"},
+		{name: "multibyte text", content: "<i>" + strings.Repeat("é", 3) + "</i>", want: "<i>ééé</i>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeSource(tt.content); got != tt.want {
+				t.Errorf("SanitizeSource(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetArchived archives a feedback at archivedAt, or restores it when archived is false. It
+// reports whether the feedback changed; archiving an archived feedback keeps its archive time.
+func (r *feedbackRepository) SetArchived(ctx context.Context, id uuid.UUID, archived bool, archivedAt time.Time) (bool, error) {
+	if !r.schema.writable("archived_at") {
+		return false, columnUnavailable("archived_at")
+	}
+
+	query := `
+		UPDATE feedbacks
+		SET archived_at = $2
+		WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NULL
+	`
+	args := []interface{}{id, archivedAt}
+	if !archived {
+		query = `
+			UPDATE feedbacks
+			SET archived_at = NULL
+			WHERE id = $1 AND deleted_at IS NULL AND archived_at IS NOT NULL
+		`
+		args = args[:1]
+	}
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to set feedback archive state: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ArchiveCreatedBefore archives up to limit active feedbacks created before cutoff and returns
+// how many it archived. Rows being written are skipped and left for a later batch.
+func (r *feedbackRepository) ArchiveCreatedBefore(ctx context.Context, cutoff, archivedAt time.Time, limit int) (int64, error) {
+	if !r.schema.writable("archived_at") {
+		return 0, columnUnavailable("archived_at")
+	}
+
+	query := `
+		UPDATE feedbacks SET archived_at = $2
+		WHERE id IN (
+			SELECT id FROM feedbacks
+			WHERE archived_at IS NULL AND deleted_at IS NULL AND created_at < $1
+			ORDER BY created_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query, cutoff.UTC(), archivedAt, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive feedbacks: %w", err)
+	}
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return archived, nil
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// assetRepository implements AssetRepository using the feedback_assets table
+// It mirrors attachment metadata so attachment counts are queryable from SQL
+type assetRepository struct {
+	db *sql.DB
+}
+
+// NewAssetRepository creates a new asset metadata repository
+func NewAssetRepository(db *sql.DB) AssetRepository {
+	return &assetRepository{
+		db: db,
+	}
+}
+
+// Upsert records metadata for an uploaded attachment. The checksum of a replaced attachment is
+// replaced too, even by an empty one.
+func (r *assetRepository) Upsert(ctx context.Context, feedbackID uuid.UUID, info *models.AttachmentInfo) error {
+	query := `
+		INSERT INTO feedback_assets (feedback_id, filename, file_size, content_type, created_at, sha256)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (feedback_id, filename)
+		DO UPDATE SET file_size = EXCLUDED.file_size, content_type = EXCLUDED.content_type, created_at = EXCLUDED.created_at, sha256 = EXCLUDED.sha256
+	`
+	_, err := r.db.ExecContext(ctx, query, feedbackID, info.Filename, info.Size, info.ContentType, info.UploadedAt, info.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to record attachment metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes metadata for a deleted attachment
+func (r *assetRepository) Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error {
+	query := `DELETE FROM feedback_assets WHERE feedback_id = $1 AND filename = $2`
+	if _, err := r.db.ExecContext(ctx, query, feedbackID, filename); err != nil {
+		return fmt.Errorf("failed to delete attachment metadata: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll removes metadata for all attachments of a feedback
+func (r *assetRepository) DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	query := `DELETE FROM feedback_assets WHERE feedback_id = $1`
+	result, err := r.db.ExecContext(ctx, query, feedbackID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete attachment metadata: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListFilenames returns the recorded attachment filenames of the given feedbacks
+func (r *assetRepository) ListFilenames(ctx context.Context, feedbackIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	ids := make([]string, len(feedbackIDs))
+	for i, id := range feedbackIDs {
+		ids[i] = id.String()
+	}
+
+	query := `SELECT feedback_id, filename FROM feedback_assets WHERE feedback_id = ANY($1::uuid[])`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachment metadata: %w", err)
+	}
+	defer rows.Close()
+
+	filenames := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var feedbackID uuid.UUID
+		var filename string
+		if err := rows.Scan(&feedbackID, &filename); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment metadata: %w", err)
+		}
+		filenames[feedbackID] = append(filenames[feedbackID], filename)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment metadata rows: %w", err)
+	}
+
+	return filenames, nil
+}
+
+// SetOrder replaces the display order of a feedback's attachments with the given order.
+// Attachments missing from feedback_assets are recorded on the way, and any other rows of the
+// feedback lose their position.
+func (r *assetRepository) SetOrder(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE feedback_assets SET sort_position = NULL WHERE feedback_id = $1`, feedbackID); err != nil {
+		return fmt.Errorf("failed to clear attachment order: %w", err)
+	}
+
+	query := `
+		INSERT INTO feedback_assets (feedback_id, filename, file_size, content_type, created_at, sort_position)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (feedback_id, filename)
+		DO UPDATE SET sort_position = EXCLUDED.sort_position
+	`
+	for i, info := range attachments {
+		if _, err := tx.ExecContext(ctx, query, feedbackID, info.Filename, info.Size, info.ContentType, info.UploadedAt, i); err != nil {
+			return fmt.Errorf("failed to set attachment order: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit attachment order: %w", err)
+	}
+	return nil
+}
+
+// ListOrder returns the display position of each ordered attachment of a feedback
+func (r *assetRepository) ListOrder(ctx context.Context, feedbackID uuid.UUID) (map[string]int, error) {
+	query := `SELECT filename, sort_position FROM feedback_assets WHERE feedback_id = $1 AND sort_position IS NOT NULL`
+	rows, err := r.db.QueryContext(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachment order: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make(map[string]int)
+	for rows.Next() {
+		var filename string
+		var position int
+		if err := rows.Scan(&filename, &position); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment order: %w", err)
+		}
+		positions[filename] = position
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment order rows: %w", err)
+	}
+
+	return positions, nil
+}
+
+// GetChecksum returns the recorded SHA-256 of an attachment, empty if none was recorded
+func (r *assetRepository) GetChecksum(ctx context.Context, feedbackID uuid.UUID, filename string) (string, error) {
+	query := `SELECT sha256 FROM feedback_assets WHERE feedback_id = $1 AND filename = $2`
+	var checksum string
+	err := r.db.QueryRowContext(ctx, query, feedbackID, filename).Scan(&checksum)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get attachment checksum: %w", err)
+	}
+
+	return checksum, nil
+}
+
+// ListChecksums returns up to limit recorded checksums in (feedback_id, filename) order, starting
+// after the given attachment. With a feedback ID only that feedback's attachments are listed.
+// Attachments without a checksum and attachments of deleted feedbacks are left out.
+func (r *assetRepository) ListChecksums(ctx context.Context, feedbackID *uuid.UUID, after *models.AttachmentChecksum, limit int) ([]*models.AttachmentChecksum, error) {
+	query := `
+		SELECT a.feedback_id, a.filename, a.sha256
+		FROM feedback_assets a
+		JOIN feedbacks f ON f.id = a.feedback_id
+		WHERE a.sha256 <> '' AND f.deleted_at IS NULL
+	`
+	var args []interface{}
+	if feedbackID != nil {
+		args = append(args, *feedbackID)
+		query += fmt.Sprintf(" AND a.feedback_id = $%d", len(args))
+	}
+	if after != nil {
+		args = append(args, after.FeedbackID, after.Filename)
+		query += fmt.Sprintf(" AND (a.feedback_id, a.filename) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	query += fmt.Sprintf(" ORDER BY a.feedback_id, a.filename LIMIT %d", limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachment checksums: %w", err)
+	}
+	defer rows.Close()
+
+	var checksums []*models.AttachmentChecksum
+	for rows.Next() {
+		checksum := &models.AttachmentChecksum{}
+		if err := rows.Scan(&checksum.FeedbackID, &checksum.Filename, &checksum.SHA256); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment checksum: %w", err)
+		}
+		checksums = append(checksums, checksum)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment checksum rows: %w", err)
+	}
+
+	return checksums, nil
+}
+
+// List returns the recorded attachments of a feedback in filename order. Sizes are the sizes
+// reported to clients; the stored size of encrypted attachments is not recorded.
+func (r *assetRepository) List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
+	query := `
+		SELECT filename, file_size, content_type, COALESCE(created_at, NOW()), sha256
+		FROM feedback_assets
+		WHERE feedback_id = $1
+		ORDER BY filename
+	`
+	rows, err := r.db.QueryContext(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachment metadata: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*models.AttachmentInfo{}
+	for rows.Next() {
+		info := &models.AttachmentInfo{}
+		if err := rows.Scan(&info.Filename, &info.Size, &info.ContentType, &info.UploadedAt, &info.SHA256); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment metadata: %w", err)
+		}
+		info.UploadedAt = info.UploadedAt.UTC()
+		attachments = append(attachments, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attachment metadata rows: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// InsertMissing records the attachments of a feedback that have no metadata row yet, keeping
+// existing rows as they are. It returns the number of rows created and whether the feedback
+// row exists; without it nothing is recorded.
+func (r *assetRepository) InsertMissing(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) (int64, bool, error) {
+	filenames := make([]string, len(attachments))
+	sizes := make([]int64, len(attachments))
+	contentTypes := make([]string, len(attachments))
+	uploadedAt := make([]string, len(attachments))
+	for i, info := range attachments {
+		filenames[i] = info.Filename
+		sizes[i] = info.Size
+		contentTypes[i] = info.ContentType
+		uploadedAt[i] = info.UploadedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := `
+		WITH feedback AS (
+			SELECT id FROM feedbacks WHERE id = $1
+		), inserted AS (
+			INSERT INTO feedback_assets (feedback_id, filename, file_size, content_type, created_at)
+			SELECT feedback.id, a.filename, a.file_size, a.content_type, a.created_at
+			FROM feedback, unnest($2::text[], $3::bigint[], $4::text[], $5::timestamp[]) AS a(filename, file_size, content_type, created_at)
+			ON CONFLICT (feedback_id, filename) DO NOTHING
+			RETURNING 1
+		)
+		SELECT EXISTS (SELECT 1 FROM feedback), (SELECT COUNT(*) FROM inserted)
+	`
+	var found bool
+	var created int64
+	err := r.db.QueryRowContext(ctx, query, feedbackID, pq.Array(filenames), pq.Array(sizes), pq.Array(contentTypes), pq.Array(uploadedAt)).Scan(&found, &created)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to record attachment metadata: %w", err)
+	}
+
+	return created, found, nil
+}
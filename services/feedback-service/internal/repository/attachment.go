@@ -4,30 +4,377 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/envelope"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 )
 
+// stagingPrefix holds objects of open upload sessions as {stagingPrefix}/{sessionID}/{filename};
+// they only become attachments once the session is committed
+const stagingPrefix = "_staging"
+
+// uploadedAtMetadataKey is the object metadata written by this service when an attachment is
+// uploaded. It is the authoritative upload time; the storage server's LastModified is only a
+// fallback, since it comes from a different clock and changes when objects are copied.
+const uploadedAtMetadataKey = "X-Uploaded-At"
+
+// uploadedAtSkewTolerance is how far in the future an uploaded-at value may lie before it is
+// treated as clock skew
+const uploadedAtSkewTolerance = 2 * time.Minute
+
+// Object metadata written for attachments encrypted with envelope encryption. The plaintext size
+// is the size reported to clients; the object size in storage includes the chunk tags.
+const (
+	encryptionMetadataKey    = "X-Encryption"             // envelope.Algorithm
+	keyIDMetadataKey         = "X-Encryption-Key-Id"      // Master key that wrapped the data key
+	wrappedKeyMetadataKey    = "X-Encryption-Wrapped-Key" // Wrapped data key
+	plaintextSizeMetadataKey = "X-Plaintext-Size"
+)
+
+// uploadedAtMetadata returns the metadata value recording an upload at the current time
+func uploadedAtMetadata() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// resolveUploadedAt returns the upload time of an object: the service-written metadata when
+// present and valid, otherwise the storage server's LastModified. Values further in the
+// future than uploadedAtSkewTolerance are clamped to the earlier of LastModified and now.
+func resolveUploadedAt(objectName string, metadata map[string]string, lastModified, now time.Time) time.Time {
+	uploadedAt := lastModified.UTC()
+	if value, ok := metadata[uploadedAtMetadataKey]; ok {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			slog.Warn("Ignoring malformed uploaded-at metadata", "object", objectName, "value", value, "error", err)
+		} else {
+			uploadedAt = parsed.UTC()
+		}
+	}
+
+	if limit := now.Add(uploadedAtSkewTolerance); uploadedAt.After(limit) {
+		clamped := now.UTC()
+		if !lastModified.IsZero() && lastModified.Before(clamped) {
+			clamped = lastModified.UTC()
+		}
+		slog.Warn("Clamping future-dated uploaded-at timestamp",
+			"object", objectName,
+			"uploaded_at", uploadedAt,
+			"clamped_to", clamped,
+		)
+		uploadedAt = clamped
+	}
+
+	return uploadedAt
+}
+
+// ListingCanceledError is returned when the caller's context ends while objects are being listed.
+// Err is context.Canceled or context.DeadlineExceeded.
+type ListingCanceledError struct {
+	Op  string // The operation that was listing, e.g. "list attachments"
+	Err error
+}
+
+func (e *ListingCanceledError) Error() string {
+	return fmt.Sprintf("%s aborted: %v", e.Op, e.Err)
+}
+
+func (e *ListingCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// listingCanceled returns a ListingCanceledError if ctx has ended, otherwise err unchanged.
+// Calls failing because of a canceled context are reported as canceled rather than as
+// storage errors.
+func listingCanceled(ctx context.Context, op string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &ListingCanceledError{Op: op, Err: ctxErr}
+	}
+	return err
+}
+
+// nextObject receives the next listed object, giving up as soon as ctx ends. The minio client
+// stops listing once ctx is canceled, but a loop ranging over the channel would keep consuming
+// objects it had already buffered. ok is false when the listing is complete.
+func nextObject(ctx context.Context, op string, objectCh <-chan minio.ObjectInfo) (object minio.ObjectInfo, ok bool, err error) {
+	// Check first: select picks randomly when both an object and ctx.Done() are ready
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return object, false, &ListingCanceledError{Op: op, Err: ctxErr}
+	}
+	select {
+	case <-ctx.Done():
+		return object, false, &ListingCanceledError{Op: op, Err: ctx.Err()}
+	case object, ok = <-objectCh:
+		if ok && object.Err != nil {
+			return object, false, listingCanceled(ctx, op, object.Err)
+		}
+		return object, ok, nil
+	}
+}
+
+// forwardObjects copies listed objects into a channel for RemoveObjects until the listing ends
+// or ctx does, counting them in forwarded. The returned channel is closed either way.
+func forwardObjects(ctx context.Context, objectCh <-chan minio.ObjectInfo, forwarded *atomic.Int64) <-chan minio.ObjectInfo {
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		for object := range objectCh {
+			select {
+			case objectsCh <- object:
+				forwarded.Add(1)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return objectsCh
+}
+
+// ObjectLocation is where attachment objects are stored: a bucket and a key prefix. Attachments
+// are stored as {Prefix}{feedbackID}/{filename} and staged files as
+// {Prefix}_staging/{sessionID}/{filename}.
+type ObjectLocation struct {
+	Bucket string
+	Prefix string // Empty, or ending in "/"
+}
+
+// AttachmentLocations returns the attachment location to use and, while attachments are being
+// migrated and not yet cut over, the legacy location to fall back to
+func AttachmentLocations(minioCfg config.MinIOConfig, migration config.MigrationConfig) (ObjectLocation, *ObjectLocation) {
+	location := ObjectLocation{Bucket: minioCfg.BucketName, Prefix: minioCfg.ObjectPrefix}
+	if !migration.ReadsLegacy() {
+		return location, nil
+	}
+	return location, &ObjectLocation{Bucket: migration.LegacyBucket, Prefix: migration.LegacyPrefix}
+}
+
+// key returns the object key of a key relative to the location
+func (l ObjectLocation) key(relative string) string {
+	return l.Prefix + relative
+}
+
+// contains reports whether an object key of bucket lies inside the location
+func (l ObjectLocation) contains(bucket, key string) bool {
+	return bucket == l.Bucket && strings.HasPrefix(key, l.Prefix)
+}
+
+// attachmentKey returns the key of an attachment relative to its location
+func attachmentKey(feedbackID uuid.UUID, filename string) string {
+	return fmt.Sprintf("%s/%s", feedbackID.String(), filename)
+}
+
+// isNoSuchKey reports whether a storage error means the object does not exist
+func isNoSuchKey(err error) bool {
+	return minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
+
 // attachmentRepository implements AttachmentRepository using MinIO
 type attachmentRepository struct {
 	minioClient   *minio.Client
-	bucketName    string
+	location      ObjectLocation
+	legacy        *ObjectLocation // Read as a fallback while attachments are migrated; nil otherwise
 	minioEndpoint string
 	useSSL        bool
+	keyring       *envelope.Keyring // Encrypts new objects when set
+
+	fallbackReads atomic.Int64
 }
 
-// NewAttachmentRepository creates a new attachment repository
-func NewAttachmentRepository(minioClient *minio.Client, bucketName, endpoint string, useSSL bool) AttachmentRepository {
+// NewAttachmentRepository creates a new attachment repository storing objects at location.
+// With a legacy location, objects that are not found at location are read from there until
+// they are migrated, and deletions remove both copies. With a keyring, new objects are stored
+// encrypted and direct object locations are not handed out.
+func NewAttachmentRepository(minioClient *minio.Client, location ObjectLocation, legacy *ObjectLocation, endpoint string, useSSL bool, keyring *envelope.Keyring) AttachmentRepository {
 	return &attachmentRepository{
 		minioClient:   minioClient,
-		bucketName:    bucketName,
+		location:      location,
+		legacy:        legacy,
 		minioEndpoint: endpoint,
 		useSSL:        useSSL,
+		keyring:       keyring,
+	}
+}
+
+// locations returns the locations holding attachments, the current one first
+func (r *attachmentRepository) locations() []ObjectLocation {
+	if r.legacy == nil {
+		return []ObjectLocation{r.location}
+	}
+	return []ObjectLocation{r.location, *r.legacy}
+}
+
+// countFallback records a read served from the legacy location
+func (r *attachmentRepository) countFallback(relative string) {
+	r.fallbackReads.Add(1)
+	slog.Debug("Attachment read from legacy location", "object", relative)
+}
+
+// FallbackReads returns how many objects were read from the legacy location since startup
+func (r *attachmentRepository) FallbackReads() int64 {
+	return r.fallbackReads.Load()
+}
+
+// statObject finds an object by its relative key, at the current location first and then at
+// the legacy location. A missing object is reported with the current location's error.
+func (r *attachmentRepository) statObject(ctx context.Context, relative string) (ObjectLocation, minio.ObjectInfo, error) {
+	objInfo, err := r.minioClient.StatObject(ctx, r.location.Bucket, r.location.key(relative), minio.StatObjectOptions{})
+	if err == nil || r.legacy == nil || !isNoSuchKey(err) {
+		return r.location, objInfo, err
 	}
+
+	legacyInfo, legacyErr := r.minioClient.StatObject(ctx, r.legacy.Bucket, r.legacy.key(relative), minio.StatObjectOptions{})
+	if legacyErr != nil {
+		return r.location, objInfo, err
+	}
+	r.countFallback(relative)
+	return *r.legacy, legacyInfo, nil
+}
+
+// listedObject is an object found by listObjects
+type listedObject struct {
+	location ObjectLocation
+	name     string // Key relative to the listed prefix
+	object   minio.ObjectInfo
+}
+
+// listObjects lists the objects under a relative prefix at every location. An object present
+// at both locations is only reported from the current one.
+func (r *attachmentRepository) listObjects(ctx context.Context, op, relativePrefix string) ([]listedObject, error) {
+	var listed []listedObject
+	seen := make(map[string]bool)
+	for i, location := range r.locations() {
+		prefix := location.key(relativePrefix)
+		objectCh := r.minioClient.ListObjects(ctx, location.Bucket, minio.ListObjectsOptions{
+			Prefix:    prefix,
+			Recursive: true,
+		})
+		for {
+			object, ok, err := nextObject(ctx, op, objectCh)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+
+			name := strings.TrimPrefix(object.Key, prefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if i > 0 {
+				r.countFallback(relativePrefix + name)
+			}
+			listed = append(listed, listedObject{location: location, name: name, object: object})
+		}
+	}
+	return listed, nil
+}
+
+// removePrefix deletes every object under a relative prefix at every location and returns how
+// many were deleted
+func (r *attachmentRepository) removePrefix(ctx context.Context, op, relativePrefix string) (int64, error) {
+	var deleted int64
+	for _, location := range r.locations() {
+		objectCh := r.minioClient.ListObjects(ctx, location.Bucket, minio.ListObjectsOptions{
+			Prefix:    location.key(relativePrefix),
+			Recursive: true,
+		})
+
+		// Create a channel of objects to remove
+		var forwarded atomic.Int64
+		objectsCh := forwardObjects(ctx, objectCh, &forwarded)
+
+		opts := minio.RemoveObjectsOptions{
+			GovernanceBypass: true,
+		}
+		for rErr := range r.minioClient.RemoveObjects(ctx, location.Bucket, objectsCh, opts) {
+			if rErr.Err != nil {
+				return 0, fmt.Errorf("failed to delete %s: %w", rErr.ObjectName, listingCanceled(ctx, op, rErr.Err))
+			}
+		}
+
+		// A canceled listing closes the channel early, which looks like a complete deletion
+		if err := ctx.Err(); err != nil {
+			return 0, &ListingCanceledError{Op: op, Err: err}
+		}
+		deleted += forwarded.Load()
+	}
+	return deleted, nil
+}
+
+// putObject stores an object at the current location, encrypting it first when a keyring is
+// configured
+func (r *attachmentRepository) putObject(ctx context.Context, relative, contentType string, metaData map[string]string, data io.Reader, size int64) error {
+	if r.keyring != nil {
+		if size < 0 {
+			return fmt.Errorf("encrypted uploads need their size in advance")
+		}
+		dataKey, wrapped, err := r.keyring.NewDataKey()
+		if err != nil {
+			return err
+		}
+		if data, err = envelope.NewEncryptReader(data, dataKey); err != nil {
+			return err
+		}
+		metaData[encryptionMetadataKey] = envelope.Algorithm
+		metaData[keyIDMetadataKey] = wrapped.KeyID
+		metaData[wrappedKeyMetadataKey] = wrapped.String()
+		metaData[plaintextSizeMetadataKey] = strconv.FormatInt(size, 10)
+		size = envelope.EncryptedSize(size)
+	}
+
+	_, err := r.minioClient.PutObject(ctx, r.location.Bucket, r.location.key(relative), data, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: metaData,
+	})
+	return err
+}
+
+// isEncrypted reports whether an object was stored with envelope encryption
+func isEncrypted(metadata map[string]string) bool {
+	return metadata[encryptionMetadataKey] != ""
+}
+
+// objectSizes returns the size of an object as seen by clients and its size in storage
+func objectSizes(objectName string, metadata map[string]string, storedSize int64) (size, stored int64) {
+	if !isEncrypted(metadata) {
+		return storedSize, storedSize
+	}
+	size, err := strconv.ParseInt(metadata[plaintextSizeMetadataKey], 10, 64)
+	if err != nil || size < 0 {
+		slog.Warn("Ignoring malformed plaintext size metadata", "object", objectName, "value", metadata[plaintextSizeMetadataKey])
+		return storedSize, storedSize
+	}
+	return size, storedSize
+}
+
+// dataKey unwraps the data key of an encrypted object
+func (r *attachmentRepository) dataKey(metadata map[string]string) ([]byte, error) {
+	if r.keyring == nil {
+		return nil, fmt.Errorf("attachment is encrypted but no encryption keys are configured")
+	}
+	if algorithm := metadata[encryptionMetadataKey]; algorithm != envelope.Algorithm {
+		return nil, fmt.Errorf("unsupported attachment encryption %q", algorithm)
+	}
+	wrapped, err := envelope.ParseWrappedKey(metadata[keyIDMetadataKey], metadata[wrappedKeyMetadataKey])
+	if err != nil {
+		return nil, err
+	}
+	return r.keyring.Unwrap(wrapped)
+}
+
+// decryptingObject reads the plaintext of an encrypted object and closes the object
+type decryptingObject struct {
+	io.Reader
+	io.Closer
 }
 
 // Upload uploads an attachment file to MinIO
@@ -39,21 +386,14 @@ func (r *attachmentRepository) Upload(ctx context.Context, feedbackID uuid.UUID,
 	default:
 	}
 
-	// Create object name: {feedbackID}/{filename}
-	objectName := fmt.Sprintf("%s/%s", feedbackID.String(), filename)
-
 	// Set custom metadata (excluding Content-Type which is set separately)
 	metaData := map[string]string{
-		"X-Feedback-ID": feedbackID.String(),
-		"X-Uploaded-At": time.Now().UTC().Format(time.RFC3339),
+		"X-Feedback-ID":       feedbackID.String(),
+		uploadedAtMetadataKey: uploadedAtMetadata(),
 	}
 
 	// Upload object with context monitoring
-	_, err := r.minioClient.PutObject(ctx, r.bucketName, objectName, data, size, minio.PutObjectOptions{
-		ContentType:  contentType,
-		UserMetadata: metaData,
-	})
-	if err != nil {
+	if err := r.putObject(ctx, attachmentKey(feedbackID, filename), contentType, metaData, data, size); err != nil {
 		return fmt.Errorf("failed to upload attachment: %w", err)
 	}
 
@@ -62,79 +402,71 @@ func (r *attachmentRepository) Upload(ctx context.Context, feedbackID uuid.UUID,
 
 // Download downloads an attachment file from MinIO
 func (r *attachmentRepository) Download(ctx context.Context, feedbackID uuid.UUID, filename string) (io.ReadCloser, *models.AttachmentInfo, error) {
-	// Create object name: {feedbackID}/{filename}
-	objectName := fmt.Sprintf("%s/%s", feedbackID.String(), filename)
-
 	// Get object info first
-	objInfo, err := r.minioClient.StatObject(ctx, r.bucketName, objectName, minio.StatObjectOptions{})
+	location, objInfo, err := r.statObject(ctx, attachmentKey(feedbackID, filename))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get attachment info: %w", err)
 	}
 
+	var dataKey []byte
+	if isEncrypted(objInfo.UserMetadata) {
+		if dataKey, err = r.dataKey(objInfo.UserMetadata); err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt attachment: %w", err)
+		}
+	}
+
 	// Get object
-	object, err := r.minioClient.GetObject(ctx, r.bucketName, objectName, minio.GetObjectOptions{})
+	object, err := r.minioClient.GetObject(ctx, location.Bucket, objInfo.Key, minio.GetObjectOptions{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
 	}
 
-	// Parse uploaded time from metadata
-	uploadedAt := objInfo.LastModified
-	if uploadedAtStr, ok := objInfo.UserMetadata["X-Uploaded-At"]; ok {
-		if parsedTime, err := time.Parse(time.RFC3339, uploadedAtStr); err == nil {
-			uploadedAt = parsedTime
+	var reader io.ReadCloser = object
+	if dataKey != nil {
+		plaintext, err := envelope.NewDecryptReader(object, dataKey)
+		if err != nil {
+			object.Close()
+			return nil, nil, fmt.Errorf("failed to decrypt attachment: %w", err)
 		}
+		reader = decryptingObject{Reader: plaintext, Closer: object}
 	}
 
+	uploadedAt := resolveUploadedAt(objInfo.Key, objInfo.UserMetadata, objInfo.LastModified, time.Now())
+	size, storedSize := objectSizes(objInfo.Key, objInfo.UserMetadata, objInfo.Size)
+
 	attachmentInfo := &models.AttachmentInfo{
 		Filename:    filename,
-		Size:        objInfo.Size,
+		Size:        size,
+		StoredSize:  storedSize,
 		ContentType: objInfo.ContentType,
 		UploadedAt:  uploadedAt,
 	}
 
-	return object, attachmentInfo, nil
+	return reader, attachmentInfo, nil
 }
 
 // List lists all attachments for a specific feedback
 func (r *attachmentRepository) List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
-	// Create prefix for this feedback: {feedbackID}/
-	prefix := fmt.Sprintf("%s/", feedbackID.String())
-
-	// List objects with the prefix
-	objectCh := r.minioClient.ListObjects(ctx, r.bucketName, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: true,
-	})
-
-	var attachments []*models.AttachmentInfo
-	for object := range objectCh {
-		if object.Err != nil {
-			return nil, fmt.Errorf("failed to list attachments: %w", object.Err)
-		}
-
-		// Extract filename from object key (remove prefix)
-		filename := strings.TrimPrefix(object.Key, prefix)
-		if filename == "" {
-			continue // Skip if no filename (shouldn't happen)
-		}
+	listed, err := r.listObjects(ctx, "list attachments", feedbackID.String()+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
 
+	attachments := make([]*models.AttachmentInfo, 0, len(listed))
+	for _, item := range listed {
 		// Get additional metadata
-		objInfo, err := r.minioClient.StatObject(ctx, r.bucketName, object.Key, minio.StatObjectOptions{})
+		objInfo, err := r.minioClient.StatObject(ctx, item.location.Bucket, item.object.Key, minio.StatObjectOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to get attachment metadata: %w", err)
+			return nil, fmt.Errorf("failed to get attachment metadata: %w", listingCanceled(ctx, "stat attachment", err))
 		}
 
-		// Parse uploaded time from metadata
-		uploadedAt := object.LastModified
-		if uploadedAtStr, ok := objInfo.UserMetadata["X-Uploaded-At"]; ok {
-			if parsedTime, err := time.Parse(time.RFC3339, uploadedAtStr); err == nil {
-				uploadedAt = parsedTime
-			}
-		}
+		uploadedAt := resolveUploadedAt(item.object.Key, objInfo.UserMetadata, item.object.LastModified, time.Now())
+		size, storedSize := objectSizes(item.object.Key, objInfo.UserMetadata, item.object.Size)
 
 		attachmentInfo := &models.AttachmentInfo{
-			Filename:    filename,
-			Size:        object.Size,
+			Filename:    item.name,
+			Size:        size,
+			StoredSize:  storedSize,
 			ContentType: objInfo.ContentType,
 			UploadedAt:  uploadedAt,
 		}
@@ -145,84 +477,61 @@ func (r *attachmentRepository) List(ctx context.Context, feedbackID uuid.UUID) (
 	return attachments, nil
 }
 
+// Count returns how many attachments a feedback has, from one listing of its prefix
+func (r *attachmentRepository) Count(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	listed, err := r.listObjects(ctx, "count attachments", feedbackID.String()+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count attachments: %w", err)
+	}
+	return int64(len(listed)), nil
+}
+
 // Delete deletes a specific attachment
 func (r *attachmentRepository) Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error {
-	// Create object name: {feedbackID}/{filename}
-	objectName := fmt.Sprintf("%s/%s", feedbackID.String(), filename)
-
-	// Remove object
+	// Remove the object from every location, so a legacy copy cannot reappear
 	opts := minio.RemoveObjectOptions{
 		ForceDelete: true,
 	}
-	err := r.minioClient.RemoveObject(ctx, r.bucketName, objectName, opts)
-	if err != nil {
-		return fmt.Errorf("failed to delete attachment: %w", err)
+	for _, location := range r.locations() {
+		err := r.minioClient.RemoveObject(ctx, location.Bucket, location.key(attachmentKey(feedbackID, filename)), opts)
+		if err != nil {
+			return fmt.Errorf("failed to delete attachment: %w", err)
+		}
 	}
 
 	return nil
 }
 
-// DeleteAll deletes all attachments for a specific feedback
-func (r *attachmentRepository) DeleteAll(ctx context.Context, feedbackID uuid.UUID) error {
-	// Create prefix for this feedback: {feedbackID}/
-	prefix := fmt.Sprintf("%s/", feedbackID.String())
-
-	// List all objects with the prefix
-	objectCh := r.minioClient.ListObjects(ctx, r.bucketName, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: true,
-	})
-
-	// Create a channel of objects to remove
-	objectsCh := make(chan minio.ObjectInfo)
-
-	go func() {
-		defer close(objectsCh)
-		for object := range objectCh {
-			objectsCh <- object
-		}
-	}()
-
-	// Delete all objects
-	opts := minio.RemoveObjectsOptions{
-		GovernanceBypass: true,
-	}
-
-	for rErr := range r.minioClient.RemoveObjects(ctx, r.bucketName, objectsCh, opts) {
-		if rErr.Err != nil {
-			return fmt.Errorf("failed to delete attachment %s: %w", rErr.ObjectName, rErr.Err)
-		}
+// DeleteAll deletes all attachments for a specific feedback and returns how many were deleted
+func (r *attachmentRepository) DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	deleted, err := r.removePrefix(ctx, "delete attachments", feedbackID.String()+"/")
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete attachments: %w", err)
 	}
-
-	return nil
+	return deleted, nil
 }
 
 // GetLocationInfo returns location information for a specific attachment
 func (r *attachmentRepository) GetLocationInfo(ctx context.Context, feedbackID uuid.UUID, filename string) (*models.AttachmentLocationInfo, error) {
-	// Create object name: {feedbackID}/{filename}
-	objectName := fmt.Sprintf("%s/%s", feedbackID.String(), filename)
+	if r.keyring != nil {
+		return nil, ErrAttachmentsEncrypted
+	}
 
 	// Get object info
-	objInfo, err := r.minioClient.StatObject(ctx, r.bucketName, objectName, minio.StatObjectOptions{})
+	location, objInfo, err := r.statObject(ctx, attachmentKey(feedbackID, filename))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attachment info: %w", err)
 	}
 
-	// Parse uploaded time from metadata
-	uploadedAt := objInfo.LastModified
-	if uploadedAtStr, ok := objInfo.UserMetadata["X-Uploaded-At"]; ok {
-		if parsedTime, err := time.Parse(time.RFC3339, uploadedAtStr); err == nil {
-			uploadedAt = parsedTime
-		}
-	}
+	uploadedAt := resolveUploadedAt(objInfo.Key, objInfo.UserMetadata, objInfo.LastModified, time.Now())
 
 	locationInfo := &models.AttachmentLocationInfo{
 		Filename:        filename,
 		Size:            objInfo.Size,
 		ContentType:     objInfo.ContentType,
 		UploadedAt:      uploadedAt,
-		MinioBucket:     r.bucketName,
-		MinioObjectPath: objectName,
+		MinioBucket:     location.Bucket,
+		MinioObjectPath: objInfo.Key,
 		MinioEndpoint:   r.minioEndpoint,
 		UseSSL:          r.useSSL,
 	}
@@ -232,48 +541,32 @@ func (r *attachmentRepository) GetLocationInfo(ctx context.Context, feedbackID u
 
 // ListLocationInfo returns location information for all attachments of a specific feedback
 func (r *attachmentRepository) ListLocationInfo(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentLocationInfo, error) {
-	// Create prefix for this feedback: {feedbackID}/
-	prefix := fmt.Sprintf("%s/", feedbackID.String())
-
-	// List objects with the prefix
-	objectCh := r.minioClient.ListObjects(ctx, r.bucketName, minio.ListObjectsOptions{
-		Prefix:    prefix,
-		Recursive: true,
-	})
-
-	var locationInfos []*models.AttachmentLocationInfo
-	for object := range objectCh {
-		if object.Err != nil {
-			return nil, fmt.Errorf("failed to list attachments: %w", object.Err)
-		}
+	if r.keyring != nil {
+		return nil, ErrAttachmentsEncrypted
+	}
 
-		// Extract filename from object key (remove prefix)
-		filename := strings.TrimPrefix(object.Key, prefix)
-		if filename == "" {
-			continue // Skip if no filename (shouldn't happen)
-		}
+	listed, err := r.listObjects(ctx, "list attachment locations", feedbackID.String()+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
 
+	locationInfos := make([]*models.AttachmentLocationInfo, 0, len(listed))
+	for _, item := range listed {
 		// Get additional metadata
-		objInfo, err := r.minioClient.StatObject(ctx, r.bucketName, object.Key, minio.StatObjectOptions{})
+		objInfo, err := r.minioClient.StatObject(ctx, item.location.Bucket, item.object.Key, minio.StatObjectOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to get attachment metadata: %w", err)
+			return nil, fmt.Errorf("failed to get attachment metadata: %w", listingCanceled(ctx, "stat attachment", err))
 		}
 
-		// Parse uploaded time from metadata
-		uploadedAt := object.LastModified
-		if uploadedAtStr, ok := objInfo.UserMetadata["X-Uploaded-At"]; ok {
-			if parsedTime, err := time.Parse(time.RFC3339, uploadedAtStr); err == nil {
-				uploadedAt = parsedTime
-			}
-		}
+		uploadedAt := resolveUploadedAt(item.object.Key, objInfo.UserMetadata, item.object.LastModified, time.Now())
 
 		locationInfo := &models.AttachmentLocationInfo{
-			Filename:        filename,
-			Size:            object.Size,
+			Filename:        item.name,
+			Size:            item.object.Size,
 			ContentType:     objInfo.ContentType,
 			UploadedAt:      uploadedAt,
-			MinioBucket:     r.bucketName,
-			MinioObjectPath: object.Key,
+			MinioBucket:     item.location.Bucket,
+			MinioObjectPath: item.object.Key,
 			MinioEndpoint:   r.minioEndpoint,
 			UseSSL:          r.useSSL,
 		}
@@ -283,3 +576,210 @@ func (r *attachmentRepository) ListLocationInfo(ctx context.Context, feedbackID
 
 	return locationInfos, nil
 }
+
+// RewrapKeys re-wraps the data key of every encrypted object, staged files and objects not yet
+// migrated included, with the active master key. Only the object metadata is rewritten, with a
+// server-side copy onto the object itself; the ciphertext stays as it is. Objects changed since
+// they were stat'ed are left alone and picked up by the next run.
+func (r *attachmentRepository) RewrapKeys(ctx context.Context) (*models.KeyRotationSummary, error) {
+	if r.keyring == nil {
+		return nil, fmt.Errorf("attachment encryption is not configured")
+	}
+
+	summary := &models.KeyRotationSummary{ActiveKeyID: r.keyring.ActiveKeyID()}
+	for i, location := range r.locations() {
+		objectCh := r.minioClient.ListObjects(ctx, location.Bucket, minio.ListObjectsOptions{Prefix: location.Prefix, Recursive: true})
+		for {
+			object, ok, err := nextObject(ctx, "rewrap keys", objectCh)
+			if err != nil {
+				return summary, fmt.Errorf("failed to list attachments: %w", err)
+			}
+			if !ok {
+				break
+			}
+			if i > 0 && r.location.contains(location.Bucket, object.Key) {
+				continue // The legacy location encloses the current one, which was done already
+			}
+			summary.Objects++
+
+			if err := r.rewrapObject(ctx, location.Bucket, object.Key, summary); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// rewrapObject re-wraps the data key of one object and counts the outcome in summary
+func (r *attachmentRepository) rewrapObject(ctx context.Context, bucket, key string, summary *models.KeyRotationSummary) error {
+	objInfo, err := r.minioClient.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get attachment metadata: %w", listingCanceled(ctx, "stat attachment", err))
+	}
+	if !isEncrypted(objInfo.UserMetadata) {
+		summary.Plaintext++
+		return nil
+	}
+
+	wrapped, err := envelope.ParseWrappedKey(objInfo.UserMetadata[keyIDMetadataKey], objInfo.UserMetadata[wrappedKeyMetadataKey])
+	changed := false
+	if err == nil {
+		wrapped, changed, err = r.keyring.Rewrap(wrapped)
+	}
+	if err != nil {
+		slog.Warn("Cannot rewrap attachment data key", "object", key, "error", err)
+		summary.Failed++
+		return nil
+	}
+	if !changed {
+		summary.Current++
+		return nil
+	}
+
+	metadata := maps.Clone(objInfo.UserMetadata)
+	metadata[keyIDMetadataKey] = wrapped.KeyID
+	metadata[wrappedKeyMetadataKey] = wrapped.String()
+	_, err = r.minioClient.CopyObject(ctx,
+		minio.CopyDestOptions{
+			Bucket:          bucket,
+			Object:          key,
+			UserMetadata:    metadata,
+			ReplaceMetadata: true,
+			ContentType:     objInfo.ContentType,
+		},
+		minio.CopySrcOptions{Bucket: bucket, Object: key, MatchETag: objInfo.ETag},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite metadata of %s: %w", key, listingCanceled(ctx, "rewrap keys", err))
+	}
+	summary.Rewrapped++
+	return nil
+}
+
+// StreamPrefixes lists the current location and sends the objects grouped by their first path
+// segment, in lexical order. Only one prefix is held in memory at a time. The channel is
+// closed when listing ends; a listing error is sent as a final prefix with Err set. While
+// attachments are migrated, listing fails with ErrAttachmentsMigrating: objects still at the
+// legacy location would be reported as missing.
+func (r *attachmentRepository) StreamPrefixes(ctx context.Context) <-chan models.AttachmentPrefix {
+	out := make(chan models.AttachmentPrefix)
+
+	go func() {
+		defer close(out)
+
+		send := func(prefix models.AttachmentPrefix) bool {
+			select {
+			case out <- prefix:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if r.legacy != nil {
+			send(models.AttachmentPrefix{Err: ErrAttachmentsMigrating})
+			return
+		}
+
+		var current *models.AttachmentPrefix
+		objectCh := r.minioClient.ListObjects(ctx, r.location.Bucket, minio.ListObjectsOptions{Prefix: r.location.Prefix, Recursive: true})
+		for object := range objectCh {
+			if object.Err != nil {
+				send(models.AttachmentPrefix{Err: fmt.Errorf("failed to list attachments: %w", object.Err)})
+				return
+			}
+
+			prefix, filename, _ := strings.Cut(strings.TrimPrefix(object.Key, r.location.Prefix), "/")
+			if prefix == stagingPrefix {
+				continue // Staged session uploads are not attachments
+			}
+			if current == nil || current.Prefix != prefix {
+				if current != nil && !send(*current) {
+					return
+				}
+				current = &models.AttachmentPrefix{Prefix: prefix}
+				if id, err := uuid.Parse(prefix); err == nil && id.String() == prefix {
+					current.FeedbackID = id
+				}
+			}
+			current.Objects = append(current.Objects, models.AttachmentInfo{
+				Filename: filename,
+				Size:     object.Size,
+			})
+		}
+
+		if current != nil {
+			send(*current)
+		}
+	}()
+
+	return out
+}
+
+// stagedObjectName returns the key of a file staged in an upload session, relative to its location
+func stagedObjectName(sessionID uuid.UUID, filename string) string {
+	return fmt.Sprintf("%s/%s/%s", stagingPrefix, sessionID.String(), filename)
+}
+
+// UploadStaged uploads a file of an upload session to the staging prefix. The object carries
+// the final attachment metadata so promotion is a plain server-side copy.
+func (r *attachmentRepository) UploadStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string, contentType string, data io.Reader, size int64) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("upload cancelled before starting: %w", ctx.Err())
+	default:
+	}
+
+	metaData := map[string]string{
+		"X-Feedback-ID":       feedbackID.String(),
+		uploadedAtMetadataKey: uploadedAtMetadata(),
+	}
+
+	if err := r.putObject(ctx, stagedObjectName(sessionID, filename), contentType, metaData, data, size); err != nil {
+		return fmt.Errorf("failed to upload staged file: %w", err)
+	}
+
+	return nil
+}
+
+// PromoteStaged copies a staged file to {feedbackID}/{filename} and removes the staged copy.
+// It is safe to retry: if the staged object is already gone but the attachment exists, the
+// file was promoted by an earlier attempt.
+func (r *attachmentRepository) PromoteStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string) error {
+	objectName := r.location.key(attachmentKey(feedbackID, filename))
+
+	source, sourceInfo, err := r.statObject(ctx, stagedObjectName(sessionID, filename))
+	if err != nil {
+		if !isNoSuchKey(err) {
+			return fmt.Errorf("failed to get staged file info: %w", err)
+		}
+		if _, err := r.minioClient.StatObject(ctx, r.location.Bucket, objectName, minio.StatObjectOptions{}); err != nil {
+			return fmt.Errorf("staged file %s is missing: %w", filename, err)
+		}
+		return nil
+	}
+
+	_, err = r.minioClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: r.location.Bucket, Object: objectName},
+		minio.CopySrcOptions{Bucket: source.Bucket, Object: sourceInfo.Key},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to promote staged file: %w", err)
+	}
+
+	if err := r.minioClient.RemoveObject(ctx, source.Bucket, sourceInfo.Key, minio.RemoveObjectOptions{ForceDelete: true}); err != nil {
+		return fmt.Errorf("failed to remove staged file: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteStaged removes all staged files of an upload session and returns how many were removed
+func (r *attachmentRepository) DeleteStaged(ctx context.Context, sessionID uuid.UUID) (int64, error) {
+	deleted, err := r.removePrefix(ctx, "delete staged files", fmt.Sprintf("%s/%s/", stagingPrefix, sessionID.String()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete staged files: %w", err)
+	}
+	return deleted, nil
+}
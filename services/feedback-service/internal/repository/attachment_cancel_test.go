@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// fakeS3 serves the listing, stat and bulk delete calls of the attachment repository. Listings
+// are served in pages; a request for blockPage, or the blockStat-th stat, is held until the
+// client goes away, after signalling blocked.
+type fakeS3 struct {
+	pages     [][]string // Object keys of each listing page
+	blockPage int        // -1 for none
+	blockStat int32      // 0 for none
+	blocked   chan struct{}
+	stats     atomic.Int32
+	deletes   atomic.Int32
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		page, _ := strconv.Atoi(query.Get("continuation-token"))
+		if page == f.blockPage {
+			f.blocked <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		var body strings.Builder
+		fmt.Fprintf(&body, `<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Name>attachments</Name><KeyCount>%d</KeyCount><MaxKeys>1000</MaxKeys>`, len(f.pages[page]))
+		if page < len(f.pages)-1 {
+			fmt.Fprintf(&body, `<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>`, page+1)
+		} else {
+			body.WriteString(`<IsTruncated>false</IsTruncated>`)
+		}
+		for _, key := range f.pages[page] {
+			fmt.Fprintf(&body, `<Contents><Key>%s</Key><LastModified>2025-03-01T10:00:00.000Z</LastModified><ETag>"etag"</ETag><Size>1</Size></Contents>`, key)
+		}
+		body.WriteString(`</ListBucketResult>`)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(body.String()))
+	case r.Method == http.MethodHead:
+		if f.stats.Add(1) == f.blockStat {
+			f.blocked <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Content-Length", "1")
+		w.Header().Set("Last-Modified", "Sat, 01 Mar 2025 10:00:00 GMT")
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodPost && query.Has("delete"):
+		f.deletes.Add(1)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></DeleteResult>`))
+	default:
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+	}
+}
+
+// newFakeS3Repository returns an attachment repository on a fake S3 server
+func newFakeS3Repository(t *testing.T, s3 *fakeS3) *attachmentRepository {
+	t.Helper()
+	server := httptest.NewServer(s3)
+	t.Cleanup(server.Close)
+	client, err := minio.New(strings.TrimPrefix(server.URL, "http://"), &minio.Options{
+		Creds:        credentials.NewStaticV4("access", "secret", ""),
+		Region:       "us-east-1",
+		BucketLookup: minio.BucketLookupPath,
+		MaxRetries:   1,
+	})
+	if err != nil {
+		t.Fatalf("minio.New() error = %v", err)
+	}
+	return &attachmentRepository{minioClient: client, location: ObjectLocation{Bucket: "attachments"}}
+}
+
+// waitGoroutinesGone waits until no goroutine is running any of the given functions
+func waitGoroutinesGone(t *testing.T, functions ...string) {
+	t.Helper()
+	buf := make([]byte, 1<<20)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stacks := string(buf[:runtime.Stack(buf, true)])
+		var running []string
+		for _, function := range functions {
+			if strings.Contains(stacks, function) {
+				running = append(running, function)
+			}
+		}
+		if len(running) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines still running %v:\n%s", running, stacks)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAttachmentListingCanceled(t *testing.T) {
+	feedbackID := uuid.New()
+	keys := func(names ...string) []string {
+		for i, name := range names {
+			names[i] = attachmentKey(feedbackID, name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		s3        *fakeS3
+		call      func(ctx context.Context, r *attachmentRepository) error
+		wantStats int32
+	}{
+		{
+			name: "list, on the second page",
+			s3:   &fakeS3{pages: [][]string{keys("a", "b"), keys("c", "d")}, blockPage: 1},
+			call: func(ctx context.Context, r *attachmentRepository) error {
+				_, err := r.List(ctx, feedbackID)
+				return err
+			},
+		},
+		{
+			name: "list, while reading metadata",
+			s3:   &fakeS3{pages: [][]string{keys("a", "b", "c")}, blockPage: -1, blockStat: 2},
+			call: func(ctx context.Context, r *attachmentRepository) error {
+				_, err := r.List(ctx, feedbackID)
+				return err
+			},
+			wantStats: 2,
+		},
+		{
+			name: "count, on the second page",
+			s3:   &fakeS3{pages: [][]string{keys("a"), keys("b")}, blockPage: 1},
+			call: func(ctx context.Context, r *attachmentRepository) error {
+				_, err := r.Count(ctx, feedbackID)
+				return err
+			},
+		},
+		{
+			name: "delete all, on the second page",
+			s3:   &fakeS3{pages: [][]string{keys("a", "b"), keys("c")}, blockPage: 1},
+			call: func(ctx context.Context, r *attachmentRepository) error {
+				_, err := r.DeleteAll(ctx, feedbackID)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.s3.blocked = make(chan struct{}, 1)
+			r := newFakeS3Repository(t, tt.s3)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- tt.call(ctx, r) }()
+
+			select {
+			case <-tt.s3.blocked:
+			case err := <-done:
+				t.Fatalf("call returned %v before reaching the held request", err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the held request never arrived")
+			}
+			cancel()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("call did not return after cancellation")
+			}
+			var canceled *ListingCanceledError
+			if !errors.As(err, &canceled) || !errors.Is(err, context.Canceled) {
+				t.Fatalf("error = %v, want a ListingCanceledError wrapping context.Canceled", err)
+			}
+			if got := tt.s3.stats.Load(); got != tt.wantStats {
+				t.Errorf("stat requests = %d, want %d: no object is stat'ed after cancellation", got, tt.wantStats)
+			}
+			if got := tt.s3.deletes.Load(); got != 0 {
+				t.Errorf("bulk delete requests = %d, want none for a canceled listing", got)
+			}
+			waitGoroutinesGone(t, "minio-go/v7.(*Client).ListObjects", "repository.forwardObjects")
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/minio/minio-go/v7"
+)
+
+// ListLegacyObjects returns up to limit keys at the legacy location, relative to it, in lexical
+// order after startAfter. Objects inside the current location are skipped when the legacy
+// location encloses it.
+func (r *attachmentRepository) ListLegacyObjects(ctx context.Context, startAfter string, limit int) ([]string, error) {
+	if r.legacy == nil {
+		return nil, fmt.Errorf("no legacy attachment location is configured")
+	}
+
+	// Stop the listing once enough keys were read
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := minio.ListObjectsOptions{Prefix: r.legacy.Prefix, Recursive: true}
+	if startAfter != "" {
+		opts.StartAfter = r.legacy.key(startAfter)
+	}
+	objectCh := r.minioClient.ListObjects(ctx, r.legacy.Bucket, opts)
+
+	var keys []string
+	for len(keys) < limit {
+		object, ok, err := nextObject(ctx, "list legacy attachments", objectCh)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list legacy attachments: %w", err)
+		}
+		if !ok {
+			break
+		}
+		if r.location.contains(r.legacy.Bucket, object.Key) {
+			continue
+		}
+		keys = append(keys, strings.TrimPrefix(object.Key, r.legacy.Prefix))
+	}
+	return keys, nil
+}
+
+// MigrateObject copies one object, given by its key relative to the legacy location, to the
+// current location and verifies the copy. An object that already exists at the current
+// location was written after migration started and is left alone; an object that is gone from
+// the legacy location was deleted since it was listed. The legacy copy is kept until the
+// legacy location is retired.
+func (r *attachmentRepository) MigrateObject(ctx context.Context, relative string) (*models.MigrationEntry, error) {
+	if r.legacy == nil {
+		return nil, fmt.Errorf("no legacy attachment location is configured")
+	}
+	entry := &models.MigrationEntry{Key: relative}
+	source, destination := r.legacy.key(relative), r.location.key(relative)
+
+	sourceInfo, err := r.minioClient.StatObject(ctx, r.legacy.Bucket, source, minio.StatObjectOptions{})
+	if err != nil {
+		if isNoSuchKey(err) {
+			entry.Status = models.MigrationMissing
+			return entry, nil
+		}
+		return nil, fmt.Errorf("failed to get legacy object info: %w", err)
+	}
+	entry.Size = sourceInfo.Size
+
+	if _, err := r.minioClient.StatObject(ctx, r.location.Bucket, destination, minio.StatObjectOptions{}); err == nil {
+		entry.Status = models.MigrationSkipped
+		return entry, nil
+	} else if !isNoSuchKey(err) {
+		return nil, fmt.Errorf("failed to get object info: %w", err)
+	}
+
+	// Server-side copy keeps the metadata and, for encrypted objects, the ciphertext as it is
+	_, err = r.minioClient.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: r.location.Bucket, Object: destination},
+		minio.CopySrcOptions{Bucket: r.legacy.Bucket, Object: source, MatchETag: sourceInfo.ETag},
+	)
+	if err != nil && minio.ToErrorResponse(err).Code == "NotImplemented" {
+		err = r.streamCopy(ctx, sourceInfo, destination)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy object: %w", err)
+	}
+
+	checksum, err := r.verifyCopy(ctx, sourceInfo, destination)
+	if err != nil {
+		// Leave nothing unverified behind; the next run copies the object again
+		if rmErr := r.minioClient.RemoveObject(ctx, r.location.Bucket, destination, minio.RemoveObjectOptions{ForceDelete: true}); rmErr != nil {
+			return nil, fmt.Errorf("%w (and failed to remove the copy: %v)", err, rmErr)
+		}
+		return nil, err
+	}
+
+	entry.Status = models.MigrationCopied
+	entry.Checksum = checksum
+	return entry, nil
+}
+
+// streamCopy copies an object through the service when the storage cannot copy it server-side
+func (r *attachmentRepository) streamCopy(ctx context.Context, sourceInfo minio.ObjectInfo, destination string) error {
+	object, err := r.minioClient.GetObject(ctx, r.legacy.Bucket, sourceInfo.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	_, err = r.minioClient.PutObject(ctx, r.location.Bucket, destination, object, sourceInfo.Size, minio.PutObjectOptions{
+		ContentType:  sourceInfo.ContentType,
+		UserMetadata: sourceInfo.UserMetadata,
+	})
+	return err
+}
+
+// verifyCopy checks that a copied object matches its source and returns the checksum it was
+// verified with. Matching single-part ETags are MD5 digests of the content; otherwise both
+// objects are read and their SHA-256 digests compared.
+func (r *attachmentRepository) verifyCopy(ctx context.Context, sourceInfo minio.ObjectInfo, destination string) (string, error) {
+	copyInfo, err := r.minioClient.StatObject(ctx, r.location.Bucket, destination, minio.StatObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get copied object info: %w", err)
+	}
+	if copyInfo.Size != sourceInfo.Size {
+		return "", fmt.Errorf("copy has %d bytes, source has %d", copyInfo.Size, sourceInfo.Size)
+	}
+	if copyInfo.ETag == sourceInfo.ETag && !strings.Contains(sourceInfo.ETag, "-") {
+		return "md5:" + sourceInfo.ETag, nil
+	}
+
+	sourceSum, err := r.objectSHA256(ctx, r.legacy.Bucket, sourceInfo.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum legacy object: %w", err)
+	}
+	copySum, err := r.objectSHA256(ctx, r.location.Bucket, destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum copied object: %w", err)
+	}
+	if !bytes.Equal(sourceSum, copySum) {
+		return "", fmt.Errorf("checksum mismatch between copy and source")
+	}
+	return "sha256:" + hex.EncodeToString(copySum), nil
+}
+
+// objectSHA256 reads an object and returns its SHA-256 digest
+func (r *attachmentRepository) objectSHA256(ctx context.Context, bucket, key string) ([]byte, error) {
+	object, err := r.minioClient.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, object); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
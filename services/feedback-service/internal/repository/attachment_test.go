@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveUploadedAt(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	lastModified := now.Add(-time.Hour)
+	zone := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name         string
+		metadata     map[string]string
+		lastModified time.Time
+		want         time.Time
+	}{
+		{
+			name:         "written by this service",
+			metadata:     map[string]string{uploadedAtMetadataKey: "2025-03-01T10:15:30.123456789Z"},
+			lastModified: lastModified,
+			want:         time.Date(2025, 3, 1, 10, 15, 30, 123456789, time.UTC),
+		},
+		{
+			name:         "offset converted to UTC",
+			metadata:     map[string]string{uploadedAtMetadataKey: "2025-03-01T13:15:30+03:00"},
+			lastModified: lastModified,
+			want:         time.Date(2025, 3, 1, 10, 15, 30, 0, time.UTC),
+		},
+		{name: "missing", metadata: nil, lastModified: lastModified, want: lastModified},
+		{name: "missing among other metadata", metadata: map[string]string{"X-Other": "value"}, lastModified: lastModified.In(zone), want: lastModified},
+		{name: "empty", metadata: map[string]string{uploadedAtMetadataKey: ""}, lastModified: lastModified, want: lastModified},
+		{name: "malformed", metadata: map[string]string{uploadedAtMetadataKey: "yesterday"}, lastModified: lastModified, want: lastModified},
+		{name: "date only", metadata: map[string]string{uploadedAtMetadataKey: "2025-03-01"}, lastModified: lastModified, want: lastModified},
+		{name: "without a zone", metadata: map[string]string{uploadedAtMetadataKey: "2025-03-01T10:15:30"}, lastModified: lastModified, want: lastModified},
+		{
+			name:         "within the skew tolerance",
+			metadata:     map[string]string{uploadedAtMetadataKey: now.Add(uploadedAtSkewTolerance).Format(time.RFC3339Nano)},
+			lastModified: lastModified,
+			want:         now.Add(uploadedAtSkewTolerance),
+		},
+		{
+			name:         "future, clamped to LastModified",
+			metadata:     map[string]string{uploadedAtMetadataKey: now.Add(uploadedAtSkewTolerance + time.Second).Format(time.RFC3339Nano)},
+			lastModified: lastModified,
+			want:         lastModified,
+		},
+		{
+			name:         "future, LastModified also future, clamped to now",
+			metadata:     map[string]string{uploadedAtMetadataKey: now.AddDate(1, 0, 0).Format(time.RFC3339)},
+			lastModified: now.Add(time.Hour),
+			want:         now,
+		},
+		{
+			name:         "future, no LastModified, clamped to now",
+			metadata:     map[string]string{uploadedAtMetadataKey: now.AddDate(1, 0, 0).Format(time.RFC3339)},
+			lastModified: time.Time{},
+			want:         now,
+		},
+		{name: "missing, LastModified in the future, clamped to now", lastModified: now.Add(time.Hour), want: now},
+		{
+			name:         "malformed, LastModified in the future, clamped to now",
+			metadata:     map[string]string{uploadedAtMetadataKey: "not a time"},
+			lastModified: now.Add(time.Hour),
+			want:         now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveUploadedAt("feedback/report.txt", tt.metadata, tt.lastModified, now)
+			if !got.Equal(tt.want) {
+				t.Errorf("resolveUploadedAt() = %v, want %v", got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("resolveUploadedAt() = %v, want it in UTC", got)
+			}
+		})
+	}
+}
+
+func TestUploadedAtMetadataRoundTrip(t *testing.T) {
+	before := time.Now().UTC()
+	value := uploadedAtMetadata()
+	after := time.Now().UTC()
+
+	got := resolveUploadedAt("feedback/report.txt", map[string]string{uploadedAtMetadataKey: value}, time.Time{}, after)
+	if got.Before(before) || got.After(after) {
+		t.Errorf("resolveUploadedAt(%q) = %v, want between %v and %v", value, got, before, after)
+	}
+}
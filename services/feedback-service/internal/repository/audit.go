@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// auditRepository implements AuditRepository using the feedback_audit_log table
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit log repository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+// Record appends an entry to the audit log
+func (r *auditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+
+	query := `
+		INSERT INTO feedback_audit_log (feedback_id, actor_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		entry.FeedbackID, entry.ActorID, entry.Action, entry.Details, entry.CreatedAt,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// ListByFeedback returns the audit trail of a feedback, oldest first
+func (r *auditRepository) ListByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]*models.AuditEntry, error) {
+	query := `
+		SELECT id, feedback_id, actor_id, action, details, created_at
+		FROM feedback_audit_log
+		WHERE feedback_id = $1
+		ORDER BY created_at, id
+	`
+	rows, err := r.db.QueryContext(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditEntry
+	for rows.Next() {
+		entry := &models.AuditEntry{}
+		if err := rows.Scan(&entry.ID, &entry.FeedbackID, &entry.ActorID, &entry.Action, &entry.Details, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit rows: %w", err)
+	}
+
+	return entries, nil
+}
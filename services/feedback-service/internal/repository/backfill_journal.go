@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// backfillJournalRepository implements BackfillJournalRepository using the
+// attachment_backfill_state and attachment_backfill_journal tables
+type backfillJournalRepository struct {
+	db *sql.DB
+}
+
+// NewBackfillJournalRepository creates a new attachment metadata backfill journal repository
+func NewBackfillJournalRepository(db *sql.DB) BackfillJournalRepository {
+	return &backfillJournalRepository{
+		db: db,
+	}
+}
+
+// finishedStatuses are the outcomes after which a prefix is not backfilled again
+var finishedStatuses = pq.Array([]string{models.BackfillDone, models.BackfillOrphaned})
+
+// Record journals the outcome of backfilling a prefix
+func (r *backfillJournalRepository) Record(ctx context.Context, entry *models.BackfillEntry) error {
+	query := `
+		INSERT INTO attachment_backfill_journal (prefix, status, rows_created, conflicts, error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (prefix) DO UPDATE SET
+			status = EXCLUDED.status,
+			rows_created = EXCLUDED.rows_created,
+			conflicts = EXCLUDED.conflicts,
+			error = EXCLUDED.error,
+			attempts = attachment_backfill_journal.attempts + 1,
+			updated_at = NOW()
+	`
+	if _, err := r.db.ExecContext(ctx, query, entry.Prefix, entry.Status, entry.RowsCreated, entry.Conflicts, entry.Error); err != nil {
+		return fmt.Errorf("failed to journal prefix %s: %w", entry.Prefix, err)
+	}
+	return nil
+}
+
+// ListFinished returns the prefixes that were backfilled or found orphaned
+func (r *backfillJournalRepository) ListFinished(ctx context.Context) (map[string]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT prefix FROM attachment_backfill_journal WHERE status = ANY($1)`, finishedStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backfilled prefixes: %w", err)
+	}
+	defer rows.Close()
+
+	finished := make(map[string]bool)
+	for rows.Next() {
+		var prefix string
+		if err := rows.Scan(&prefix); err != nil {
+			return nil, fmt.Errorf("failed to scan backfilled prefix: %w", err)
+		}
+		finished[prefix] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backfilled prefixes: %w", err)
+	}
+	return finished, nil
+}
+
+// IsFinished reports whether a prefix was backfilled or found orphaned, and whether the whole
+// backfill is complete
+func (r *backfillJournalRepository) IsFinished(ctx context.Context, prefix string) (bool, bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM attachment_backfill_journal WHERE prefix = $1 AND status = ANY($2)), complete
+		FROM attachment_backfill_state
+		WHERE id = 1
+	`
+	var finished, complete bool
+	if err := r.db.QueryRowContext(ctx, query, prefix, finishedStatuses).Scan(&finished, &complete); err != nil {
+		return false, false, fmt.Errorf("failed to check backfilled prefix: %w", err)
+	}
+	return finished, complete, nil
+}
+
+// FinishListing records the number of prefixes found by a complete listing and whether all of
+// them are backfilled
+func (r *backfillJournalRepository) FinishListing(ctx context.Context, totalPrefixes int64, complete bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE attachment_backfill_state SET total_prefixes = $1, complete = $2, updated_at = NOW() WHERE id = 1`,
+		totalPrefixes, complete,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update backfill state: %w", err)
+	}
+	return nil
+}
+
+// GetProgress summarizes the journal
+func (r *backfillJournalRepository) GetProgress(ctx context.Context) (*models.BackfillProgress, error) {
+	progress := &models.BackfillProgress{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT total_prefixes, complete FROM attachment_backfill_state WHERE id = 1`,
+	).Scan(&progress.PrefixesTotal, &progress.Complete)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backfill state: %w", err)
+	}
+
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = ANY($1)),
+			COUNT(*) FILTER (WHERE status = $2),
+			COALESCE(SUM(rows_created), 0),
+			COALESCE(SUM(conflicts), 0)
+		FROM attachment_backfill_journal
+	`
+	err = r.db.QueryRowContext(ctx, query, finishedStatuses, models.BackfillFailed).Scan(
+		&progress.PrefixesDone, &progress.PrefixesFailed, &progress.RowsCreated, &progress.ConflictsSkipped,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize backfill journal: %w", err)
+	}
+	return progress, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -23,9 +24,22 @@ type commentRepository struct {
 	collectionName string
 }
 
+// WithTransaction runs fn with a repository whose operations are part of one transaction,
+// committed when fn returns nil. Called on such a repository, it joins its transaction.
 func (r *commentRepository) WithTransaction(ctx context.Context, fn func(CommentTxRepository) error) error {
+	return r.inTransaction(ctx, func(ctx context.Context, tx *commentRepository) error {
+		return fn(tx)
+	})
+}
+
+// inTransaction runs fn in the transaction of r, or in a new one when r is not in one. fn gets
+// the repository bound to the session and ctx bound to it too.
+func (r *commentRepository) inTransaction(ctx context.Context, fn func(ctx context.Context, tx *commentRepository) error) error {
+	if r.mongodb.Session != nil {
+		return fn(r.bind(ctx), r)
+	}
 	return r.mongodb.WithTransaction(ctx, func(sc mongo.SessionContext) error {
-		repo := &commentRepository{
+		tx := &commentRepository{
 			mongodb: &database.MongoDBClient{
 				Client:   r.mongodb.Client,
 				Database: r.mongodb.Database,
@@ -33,11 +47,20 @@ func (r *commentRepository) WithTransaction(ctx context.Context, fn func(Comment
 			},
 			collectionName: r.collectionName,
 		}
-
-		return fn(repo)
+		return fn(sc, tx)
 	})
 }
 
+// bind returns ctx carrying the repository's session, if any. Operations only join a
+// transaction when run with such a context, so every method binds the context it is given;
+// callers of a transactional repository pass their own.
+func (r *commentRepository) bind(ctx context.Context) context.Context {
+	if r.mongodb.Session == nil {
+		return ctx
+	}
+	return mongo.NewSessionContext(ctx, mongo.SessionFromContext(r.mongodb.Session))
+}
+
 // NewCommentRepository creates a new comment repository
 func NewCommentRepository(mongodb *database.MongoDBClient, collectionName string) CommentRepository {
 	return &commentRepository{
@@ -46,18 +69,19 @@ func NewCommentRepository(mongodb *database.MongoDBClient, collectionName string
 	}
 }
 
+// collection returns the comments collection; operations join a transaction through their
+// context, see bind
 func (r *commentRepository) collection() *mongo.Collection {
-	if r.mongodb.Session != nil {
-		return r.mongodb.Session.Client().Database(r.mongodb.Database.Name()).Collection(r.collectionName)
-	}
 	return r.mongodb.Database.Collection(r.collectionName)
 }
 
 // Create creates a new comment
 func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	ctx = r.bind(ctx)
 	comment.ID = primitive.NewObjectID()
 	comment.CreatedAt = time.Now().UTC()
 	comment.UpdatedAt = comment.CreatedAt
+	comment.ChangedAt = comment.CreatedAt
 
 	_, err := r.collection().InsertOne(ctx, comment)
 	if err != nil {
@@ -67,8 +91,57 @@ func (r *commentRepository) Create(ctx context.Context, comment *models.Comment)
 	return nil
 }
 
+// CreateWithTimestamps inserts imported comments keeping their supplied timestamps.
+// Comments are inserted in a single unordered batch; the returned slice holds one
+// error per comment (nil on success).
+func (r *commentRepository) CreateWithTimestamps(ctx context.Context, comments []*models.Comment) []error {
+	ctx = r.bind(ctx)
+	errs := make([]error, len(comments))
+	now := time.Now().UTC()
+
+	var docs []interface{}
+	var positions []int
+	for i, comment := range comments {
+		if err := comment.ValidateImportTimestamps(now); err != nil {
+			errs[i] = err
+			continue
+		}
+		comment.ID = primitive.NewObjectID()
+		comment.ChangedAt = now // Imported comments reach comment streams as updates
+		docs = append(docs, comment)
+		positions = append(positions, i)
+	}
+
+	if len(docs) == 0 {
+		return errs
+	}
+
+	_, err := r.collection().InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) {
+			for _, writeErr := range bulkErr.WriteErrors {
+				errs[positions[writeErr.Index]] = fmt.Errorf("failed to insert comment: %s", writeErr.Message)
+			}
+		} else {
+			for _, position := range positions {
+				errs[position] = fmt.Errorf("failed to insert comment: %w", err)
+			}
+		}
+	}
+
+	for i, comment := range comments {
+		if errs[i] != nil {
+			comment.ID = primitive.NilObjectID
+		}
+	}
+
+	return errs
+}
+
 // GetByID retrieves a comment by ID
 func (r *commentRepository) GetByID(ctx context.Context, id string) (*models.Comment, error) {
+	ctx = r.bind(ctx)
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid comment ID: %w", err)
@@ -78,7 +151,7 @@ func (r *commentRepository) GetByID(ctx context.Context, id string) (*models.Com
 	err = r.collection().FindOne(ctx, bson.M{"_id": objectID}).Decode(&comment)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("comment not found")
+			return nil, ErrCommentNotFound
 		}
 		return nil, fmt.Errorf("failed to get comment: %w", err)
 	}
@@ -86,78 +159,124 @@ func (r *commentRepository) GetByID(ctx context.Context, id string) (*models.Com
 	return &comment, nil
 }
 
-// Update updates an existing comment
+// commentListProjection leaves the edit history out of comment lists, which never show it
+var commentListProjection = bson.M{"edits": 0}
+
+// Update updates the content of an existing comment and marks it edited. The stored content
+// is pushed onto the comment's edits in the same update, keeping the last
+// models.MaxCommentEdits versions, so concurrent edits cannot lose a version.
 func (r *commentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	ctx = r.bind(ctx)
 	comment.UpdatedAt = time.Now().UTC()
+	comment.ChangedAt = comment.UpdatedAt
 
-	filter := bson.M{"_id": comment.ID}
-	update := bson.M{
-		"$set": bson.M{
+	// Field paths in a $set stage read the document as it was before the stage
+	previous := bson.M{"content": "$content", "written_at": "$updated_at", "replaced_at": comment.UpdatedAt}
+	edits := bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$edits", bson.A{}}}, bson.A{previous}}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
 			"content":    comment.Content,
 			"updated_at": comment.UpdatedAt,
-		},
+			"changed_at": comment.ChangedAt,
+			"edited":     true,
+			"edit_count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$edit_count", 0}}, 1}},
+			"edits":      bson.M{"$slice": bson.A{edits, -models.MaxCommentEdits}},
+		}}},
 	}
 
-	result, err := r.collection().UpdateOne(ctx, filter, update)
+	var updated models.Comment
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"edited": 1, "edit_count": 1, "edits": 1})
+	err := r.collection().FindOneAndUpdate(ctx, bson.M{"_id": comment.ID}, update, opts).Decode(&updated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrCommentNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("comment not found")
-	}
+	comment.Edited = updated.Edited
+	comment.EditCount = updated.EditCount
+	comment.Edits = updated.Edits
 
 	return nil
 }
 
-// Delete deletes a comment and all its replies
+// Delete deletes a comment and all its replies, and records the deletion for comment streams
 func (r *commentRepository) Delete(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return fmt.Errorf("invalid comment ID: %w", err)
 	}
 
-	// Start a transaction
-	return r.WithTransaction(ctx, func(txRepo CommentTxRepository) error {
+	// Replies and the comment are deleted in one transaction, joining the caller's if any
+	return r.inTransaction(ctx, func(ctx context.Context, tx *commentRepository) error {
 		// Delete all replies first (recursive)
-		if err := txRepo.DeleteReplies(ctx, id); err != nil {
+		replyIDs, err := tx.deleteReplies(ctx, id)
+		if err != nil {
 			return fmt.Errorf("failed to delete replies: %w", err)
 		}
 
-		// Delete the comment itself
-		result, err := r.collection().DeleteOne(ctx, bson.M{"_id": objectID})
+		// Delete the comment itself, keeping what the deletion record needs
+		var deleted models.Comment
+		opts := options.FindOneAndDelete().SetProjection(bson.M{"content_id": 1, "content_ref": 1, "type": 1})
+		err = tx.collection().FindOneAndDelete(ctx, bson.M{"_id": objectID}, opts).Decode(&deleted)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrCommentNotFound
+		}
 		if err != nil {
 			return fmt.Errorf("failed to delete comment: %w", err)
 		}
-
-		if result.DeletedCount == 0 {
-			return fmt.Errorf("comment not found")
+		if err := tx.deleteDependents(ctx, []primitive.ObjectID{objectID}); err != nil {
+			return err
 		}
-		return nil
+
+		return tx.recordDeletion(ctx, &models.CommentDeletion{
+			CommentIDs: append([]primitive.ObjectID{objectID}, replyIDs...),
+			ContentID:  deleted.ContentID,
+			ContentRef: deleted.ContentRef,
+			Type:       deleted.Type,
+			DeletedAt:  time.Now().UTC(),
+		})
 	})
 }
 
 // DeleteReplies deletes all replies to a specific comment (iterative approach)
 func (r *commentRepository) DeleteReplies(ctx context.Context, parentID string) error {
+	_, err := r.deleteReplies(ctx, parentID)
+	return err
+}
+
+// deleteReplies deletes all replies to a comment and returns their IDs
+func (r *commentRepository) deleteReplies(ctx context.Context, parentID string) ([]primitive.ObjectID, error) {
+	ctx = r.bind(ctx)
 	// Get all descendant IDs iteratively
 	descendantIDs, err := r.getAllDescendantIDs(ctx, parentID)
 	if err != nil {
-		return fmt.Errorf("failed to get descendant IDs: %w", err)
+		return nil, fmt.Errorf("failed to get descendant IDs: %w", err)
 	}
 
 	// If no descendants, nothing to delete
 	if len(descendantIDs) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Delete all descendants in a single operation
 	filter := bson.M{"_id": bson.M{"$in": descendantIDs}}
 	_, err = r.collection().DeleteMany(ctx, filter)
 	if err != nil {
-		return fmt.Errorf("failed to delete replies: %w", err)
+		return nil, fmt.Errorf("failed to delete replies: %w", err)
 	}
 
-	return nil
+	return descendantIDs, r.deleteDependents(ctx, descendantIDs)
+}
+
+// deleteDependents deletes the reactions and reports of comments, which are being deleted
+func (r *commentRepository) deleteDependents(ctx context.Context, commentIDs []primitive.ObjectID) error {
+	if err := r.deleteReactions(ctx, commentIDs); err != nil {
+		return err
+	}
+	return r.deleteReports(ctx, commentIDs)
 }
 
 // getAllDescendantIDs iteratively collects all descendant comment IDs using an aggregation pipeline
@@ -200,24 +319,56 @@ func (r *commentRepository) getAllDescendantIDs(ctx context.Context, parentID st
 	return result.DescendantIDs, nil
 }
 
-// ListByContext lists comments by content ID
-func (r *commentRepository) ListByContext(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int32, error) {
+// topLevelClause matches top-level comments: documents where parent_id doesn't exist, is null,
+// or is empty string. Use it as the value of "$or".
+func topLevelClause() []bson.M {
+	return []bson.M{
+		{"parent_id": bson.M{"$exists": false}},
+		{"parent_id": nil},
+		{"parent_id": ""},
+	}
+}
+
+// ListByContext lists comments by content ID, or by content reference when the filter has one,
+// in the order of filter.Sort, newest first by default, from filter.After when set and from
+// filter.Page otherwise; the total ignores the cursor
+func (r *commentRepository) ListByContext(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int64, error) {
+	ctx = r.bind(ctx)
 	// Build base filter
-	mongoFilter := bson.M{
-		"content_id": filter.ContentID,
-		"type":       filter.Type,
+	mongoFilter := bson.M{"type": filter.Type}
+	if filter.ContentRef != "" {
+		mongoFilter["content_ref"] = filter.ContentRef
+	} else {
+		mongoFilter["content_id"] = filter.ContentID
+	}
+	if len(filter.UserIDs) > 0 {
+		mongoFilter["user_id"] = bson.M{"$in": filter.UserIDs}
+	}
+	if filter.CreatedAfter != nil || filter.CreatedBefore != nil {
+		createdAt := bson.M{}
+		if filter.CreatedAfter != nil {
+			createdAt["$gte"] = filter.CreatedAfter.UTC()
+		}
+		if filter.CreatedBefore != nil {
+			createdAt["$lt"] = filter.CreatedBefore.UTC()
+		}
+		mongoFilter["created_at"] = createdAt
 	}
 
 	// Add parent filter (null for top-level comments, specific ID for replies)
 	if filter.ParentID != nil {
 		mongoFilter["parent_id"] = *filter.ParentID
 	} else {
-		// For top-level comments, look for documents where parent_id doesn't exist, is null, or is empty string
-		mongoFilter["$or"] = []bson.M{
-			{"parent_id": bson.M{"$exists": false}},
-			{"parent_id": nil},
-			{"parent_id": ""},
-		}
+		mongoFilter["$or"] = topLevelClause()
+	}
+
+	// Unanswered comments have no replies to order by, so most replies lists them newest first
+	ascending := filter.Sort == models.CommentSortOldest
+	if filter.UnansweredOnly {
+		return r.listUnanswered(ctx, mongoFilter, filter.After, ascending, filter.Page, filter.Limit)
+	}
+	if filter.Sort == models.CommentSortMostReplies {
+		return r.listMostReplied(ctx, mongoFilter, filter.After, filter.Page, filter.Limit)
 	}
 
 	// Get total count
@@ -227,13 +378,20 @@ func (r *commentRepository) ListByContext(ctx context.Context, filter models.Com
 	}
 
 	// Set up find options with pagination and sorting
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{"created_at", -1}}) // Newest first
-	findOptions.SetSkip(int64((filter.Page - 1) * filter.Limit))
+	findOptions := options.Find().SetProjection(commentListProjection)
+	// The ID breaks ties between comments created in the same instant, so pages neither repeat
+	// nor skip them
+	findOptions.SetSort(creationOrder(ascending))
 	findOptions.SetLimit(int64(filter.Limit))
+	pageFilter := mongoFilter
+	if filter.After != nil {
+		pageFilter = bson.M{"$and": []bson.M{mongoFilter, afterCursor(filter.After, ascending)}}
+	} else {
+		findOptions.SetSkip(int64((filter.Page - 1) * filter.Limit))
+	}
 
 	// Find comments
-	cursor, err := r.collection().Find(ctx, mongoFilter, findOptions)
+	cursor, err := r.collection().Find(ctx, pageFilter, findOptions)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to find comments: %w", err)
 	}
@@ -252,11 +410,203 @@ func (r *commentRepository) ListByContext(ctx context.Context, filter models.Com
 		return nil, 0, fmt.Errorf("cursor error: %w", err)
 	}
 
-	return comments, int32(totalCount), nil
+	if err := r.loadReplyCounts(ctx, comments); err != nil {
+		return nil, 0, err
+	}
+	return comments, totalCount, nil
+}
+
+// loadReplyCounts fills the number of direct replies of a page of comments with a single
+// aggregation over the parent_id index, rather than one count per comment. Deleting a comment
+// deletes its replies, so every reply counted exists.
+func (r *commentRepository) loadReplyCounts(ctx context.Context, comments []*models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Comment, len(comments))
+	ids := make([]string, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID.Hex()
+		byID[ids[i]] = comment
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"parent_id": bson.M{"$in": ids}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$parent_id", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to count replies: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			ParentID string `bson:"_id"`
+			Count    int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return fmt.Errorf("failed to decode reply count: %w", err)
+		}
+		if comment := byID[group.ParentID]; comment != nil {
+			comment.ReplyCount = group.Count
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+	return nil
+}
+
+// creationOrder sorts comments by creation time and ID, newest first unless ascending is set
+func creationOrder(ascending bool) bson.D {
+	direction := -1
+	if ascending {
+		direction = 1
+	}
+	return bson.D{{Key: "created_at", Value: direction}, {Key: "_id", Value: direction}}
 }
 
-// ListReplies lists replies to a specific comment
-func (r *commentRepository) ListReplies(ctx context.Context, parentID string, page, limit int32) ([]*models.Comment, int32, error) {
+// afterCursor matches the comments that follow a cursor in a list sorted by creation time and
+// ID, newest first unless ascending is set. It is a range on the created_at indexes rather than
+// a skip, so deep pages stay fast and comments added meanwhile do not shift them.
+func afterCursor(cursor *models.CommentCursor, ascending bool) bson.M {
+	op := "$lt"
+	if ascending {
+		op = "$gt"
+	}
+	createdAt := cursor.CreatedAt.UTC()
+	return bson.M{"$or": []bson.M{
+		{"created_at": bson.M{op: createdAt}},
+		{"created_at": createdAt, "_id": bson.M{op: cursor.ID}},
+	}}
+}
+
+// listUnanswered lists the comments matching mongoFilter that have no replies, newest first
+// unless ascending is set. Replies reference their parent by its hex ID, so each candidate is
+// looked up in the parent_id index with a single-document probe.
+func (r *commentRepository) listUnanswered(ctx context.Context, mongoFilter bson.M, after *models.CommentCursor, ascending bool, page, limit int32) ([]*models.Comment, int64, error) {
+	window := bson.A{bson.M{"$sort": creationOrder(ascending)}}
+	if after != nil {
+		window = append(window, bson.M{"$match": afterCursor(after, ascending)})
+	} else {
+		window = append(window, bson.M{"$skip": int64((page - 1) * limit)})
+	}
+	window = append(window, bson.M{"$limit": int64(limit)})
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: mongoFilter}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.collectionName,
+			"let":  bson.M{"comment_id": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$parent_id", "$$comment_id"}}}},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "replies",
+		}}},
+		{{Key: "$match", Value: bson.M{"replies": bson.M{"$size": 0}}}},
+		{{Key: "$project", Value: bson.M{"replies": 0, "edits": 0}}},
+		{{Key: "$facet", Value: bson.M{
+			"total":    bson.A{bson.M{"$count": "count"}},
+			"comments": window,
+		}}},
+	}
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list unanswered comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Comments []*models.Comment `bson:"comments"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode unanswered comments: %w", err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, fmt.Errorf("cursor error: %w", err)
+	}
+
+	var totalCount int64
+	if len(result.Total) > 0 {
+		totalCount = result.Total[0].Count
+	}
+	return result.Comments, totalCount, nil
+}
+
+// listMostReplied lists the comments matching mongoFilter with the most direct replies first,
+// then newest first, and fills their reply counts. The counts are joined with one $count over
+// the parent_id index per matching comment, since the order depends on all of them; pages
+// continue after a cursor on the count, creation time and ID.
+func (r *commentRepository) listMostReplied(ctx context.Context, mongoFilter bson.M, after *models.CommentCursor, page, limit int32) ([]*models.Comment, int64, error) {
+	totalCount, err := r.collection().CountDocuments(ctx, mongoFilter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: mongoFilter}},
+		{{Key: "$project", Value: commentListProjection}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": r.collectionName,
+			"let":  bson.M{"comment_id": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$parent_id", "$$comment_id"}}}},
+				bson.M{"$count": "count"},
+			},
+			"as": "replies",
+		}}},
+		{{Key: "$set", Value: bson.M{"reply_count": bson.M{"$ifNull": bson.A{bson.M{"$first": "$replies.count"}, 0}}}}},
+		{{Key: "$project", Value: bson.M{"replies": 0}}},
+		{{Key: "$sort", Value: bson.D{{Key: "reply_count", Value: -1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}}},
+	}
+	if after != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"$or": []bson.M{
+			{"reply_count": bson.M{"$lt": after.ReplyCount}},
+			{"$and": []bson.M{{"reply_count": after.ReplyCount}, afterCursor(after, false)}},
+		}}}})
+	} else {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64((page - 1) * limit)}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$limit", Value: int64(limit)}})
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list most replied comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var comments []*models.Comment
+	for cursor.Next(ctx) {
+		var doc struct {
+			models.Comment `bson:",inline"`
+			ReplyCount     int64 `bson:"reply_count"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode comment: %w", err)
+		}
+		doc.Comment.ReplyCount = doc.ReplyCount
+		comments = append(comments, &doc.Comment)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, fmt.Errorf("cursor error: %w", err)
+	}
+	return comments, totalCount, nil
+}
+
+// ListReplies lists replies to a specific comment in the order of sort, oldest first unless it
+// is models.CommentSortNewest, after a cursor when set and from page otherwise; the total
+// ignores the cursor
+func (r *commentRepository) ListReplies(ctx context.Context, parentID string, sort string, after *models.CommentCursor, page, limit int32) ([]*models.Comment, int64, error) {
+	ctx = r.bind(ctx)
 	// Build filter for replies
 	mongoFilter := bson.M{"parent_id": parentID}
 
@@ -267,13 +617,20 @@ func (r *commentRepository) ListReplies(ctx context.Context, parentID string, pa
 	}
 
 	// Set up find options with pagination and sorting
-	findOptions := options.Find()
-	findOptions.SetSort(bson.D{{"created_at", 1}}) // Oldest first for replies
-	findOptions.SetSkip(int64((page - 1) * limit))
+	findOptions := options.Find().SetProjection(commentListProjection)
+	// Oldest first for replies by default, with the ID breaking ties as in ListByContext
+	ascending := sort != models.CommentSortNewest
+	findOptions.SetSort(creationOrder(ascending))
 	findOptions.SetLimit(int64(limit))
+	pageFilter := mongoFilter
+	if after != nil {
+		pageFilter = bson.M{"$and": []bson.M{mongoFilter, afterCursor(after, ascending)}}
+	} else {
+		findOptions.SetSkip(int64((page - 1) * limit))
+	}
 
 	// Find replies
-	cursor, err := r.collection().Find(ctx, mongoFilter, findOptions)
+	cursor, err := r.collection().Find(ctx, pageFilter, findOptions)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to find replies: %w", err)
 	}
@@ -292,5 +649,167 @@ func (r *commentRepository) ListReplies(ctx context.Context, parentID string, pa
 		return nil, 0, fmt.Errorf("cursor error: %w", err)
 	}
 
-	return comments, int32(totalCount), nil
+	return comments, totalCount, nil
+}
+
+// ListByUser lists the comments a user wrote, on any content or on contentType only when it is
+// set, newest first with the ID breaking ties. The user_id index narrows the scan; a user's
+// comments are few enough to sort in memory.
+func (r *commentRepository) ListByUser(ctx context.Context, userID int64, contentType string, page, limit int32) ([]*models.Comment, int64, error) {
+	ctx = r.bind(ctx)
+	mongoFilter := bson.M{"user_id": userID}
+	if contentType != "" {
+		mongoFilter["type"] = contentType
+	}
+
+	totalCount, err := r.collection().CountDocuments(ctx, mongoFilter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count user comments: %w", err)
+	}
+
+	findOptions := options.Find().
+		SetProjection(commentListProjection).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+	cursor, err := r.collection().Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find user comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	comments := []*models.Comment{}
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode user comments: %w", err)
+	}
+	return comments, totalCount, nil
+}
+
+// BackfillDepth computes the depth of every comment level by level: top-level comments get
+// depth 0, then the replies of each level are updated in batches of parent IDs. It returns
+// the number of documents modified.
+func (r *commentRepository) BackfillDepth(ctx context.Context, batchSize int) (int64, error) {
+	ctx = r.bind(ctx)
+	var modified int64
+
+	result, err := r.collection().UpdateMany(ctx, bson.M{"$or": []bson.M{
+		{"parent_id": bson.M{"$exists": false}},
+		{"parent_id": nil},
+		{"parent_id": ""},
+	}}, bson.M{"$set": bson.M{"depth": 0}})
+	if err != nil {
+		return modified, fmt.Errorf("failed to set depth of top-level comments: %w", err)
+	}
+	modified += result.ModifiedCount
+
+	// Walk the tree one level at a time using the previous level's IDs as parents
+	level, err := r.levelIDs(ctx, bson.M{"$or": []bson.M{
+		{"parent_id": bson.M{"$exists": false}},
+		{"parent_id": nil},
+		{"parent_id": ""},
+	}})
+	if err != nil {
+		return modified, err
+	}
+
+	for depth := int32(1); len(level) > 0; depth++ {
+		var next []string
+		for start := 0; start < len(level); start += batchSize {
+			if err := ctx.Err(); err != nil {
+				return modified, fmt.Errorf("depth backfill interrupted: %w", err)
+			}
+
+			parents := level[start:min(start+batchSize, len(level))]
+			filter := bson.M{"parent_id": bson.M{"$in": parents}}
+
+			result, err := r.collection().UpdateMany(ctx, filter, bson.M{"$set": bson.M{"depth": depth}})
+			if err != nil {
+				return modified, fmt.Errorf("failed to set depth %d: %w", depth, err)
+			}
+			modified += result.ModifiedCount
+
+			children, err := r.levelIDs(ctx, filter)
+			if err != nil {
+				return modified, err
+			}
+			next = append(next, children...)
+		}
+		level = next
+	}
+
+	return modified, nil
+}
+
+// levelIDs returns the hex IDs of all comments matching filter
+func (r *commentRepository) levelIDs(ctx context.Context, filter bson.M) ([]string, error) {
+	cursor, err := r.collection().Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find comment IDs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode comment ID: %w", err)
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CountCreatedByBucket counts comments on a content created in [since, until) grouped by
+// $dateTrunc bucket in UTC, with weeks starting on Monday to match PostgreSQL date_trunc.
+// Only non-empty buckets are returned.
+func (r *commentRepository) CountCreatedByBucket(ctx context.Context, contentID int64, contentType, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	ctx = r.bind(ctx)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"content_id": contentID,
+			"type":       contentType,
+			"created_at": bson.M{"$gte": since.UTC(), "$lt": until.UTC()},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateTrunc": bson.M{
+				"date":        "$created_at",
+				"unit":        bucketSize,
+				"timezone":    "UTC",
+				"startOfWeek": "monday",
+			}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments by bucket: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []*models.RateBucket
+	for cursor.Next(ctx) {
+		var doc struct {
+			Start time.Time `bson:"_id"`
+			Count int64     `bson:"count"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode comment bucket: %w", err)
+		}
+		buckets = append(buckets, &models.RateBucket{Start: doc.Start.UTC(), Count: doc.Count})
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return buckets, nil
 }
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// commentErasuresCollection holds the audit record of each erasure of a user's comments
+const commentErasuresCollection = "comment_erasures"
+
+// erasures returns the comment erasures collection
+func (r *commentRepository) erasures() *mongo.Collection {
+	return r.mongodb.Database.Collection(commentErasuresCollection)
+}
+
+// EraseUserBatch erases up to limit comments of a user with one update over the user_id index
+// and returns how many it erased. Erased comments lose their user_id and edit history, so a
+// retry after an interruption finds only the comments that are left. Soft deleted comments
+// also lose their reactions and reports, in the same transaction. Replies are not touched and
+// keep their erased parent.
+func (r *commentRepository) EraseUserBatch(ctx context.Context, userID int64, mode string, limit int) (int64, error) {
+	var erased int64
+	err := r.inTransaction(ctx, func(ctx context.Context, tx *commentRepository) error {
+		filter := bson.M{"user_id": userID}
+		opts := options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit))
+		cursor, err := tx.collection().Find(ctx, filter, opts)
+		if err != nil {
+			return fmt.Errorf("failed to find comments to erase: %w", err)
+		}
+		var docs []struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.All(ctx, &docs); err != nil {
+			return fmt.Errorf("failed to decode comment IDs: %w", err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		ids := make([]primitive.ObjectID, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc.ID
+		}
+
+		// updated_at moves so cached renderings of the old content are not reused
+		now := time.Now().UTC()
+		set := bson.M{"erased_at": now, "updated_at": now, "changed_at": now}
+		switch mode {
+		case models.CommentErasureAnonymize:
+			set["content"] = models.ErasedCommentContent
+		case models.CommentErasureDelete:
+			set["content"] = ""
+			set["deleted_at"] = now
+		default:
+			return fmt.Errorf("unknown erasure mode %q", mode)
+		}
+		update := bson.M{"$set": set, "$unset": bson.M{"user_id": "", "edits": ""}}
+
+		filter["_id"] = bson.M{"$in": ids}
+		result, err := tx.collection().UpdateMany(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("failed to erase comments: %w", err)
+		}
+		erased = result.ModifiedCount
+
+		if mode == models.CommentErasureDelete {
+			return tx.deleteDependents(ctx, ids)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return erased, nil
+}
+
+// StartErasure records an attempt of an erasure, creating its audit record on the first attempt
+func (r *commentRepository) StartErasure(ctx context.Context, userID int64, mode string, actorID int64) (*models.CommentErasure, error) {
+	ctx = r.bind(ctx)
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"user_id":    userID,
+			"erased":     int64(0),
+			"started_at": time.Now().UTC(),
+		},
+		"$set": bson.M{"mode": mode, "actor_id": actorID},
+		"$inc": bson.M{"attempts": int32(1)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	erasure := &models.CommentErasure{}
+	id := strconv.FormatInt(userID, 10)
+	if err := r.erasures().FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(erasure); err != nil {
+		return nil, fmt.Errorf("failed to record comment erasure: %w", err)
+	}
+	return erasure, nil
+}
+
+// AddErasureProgress adds comments erased by a batch to the audit record of an erasure
+func (r *commentRepository) AddErasureProgress(ctx context.Context, erasureID string, erased int64) error {
+	ctx = r.bind(ctx)
+	_, err := r.erasures().UpdateOne(ctx, bson.M{"_id": erasureID}, bson.M{"$inc": bson.M{"erased": erased}})
+	if err != nil {
+		return fmt.Errorf("failed to record comment erasure progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteErasure marks an erasure as complete and returns its audit record
+func (r *commentRepository) CompleteErasure(ctx context.Context, erasureID string) (*models.CommentErasure, error) {
+	ctx = r.bind(ctx)
+	update := bson.M{"$set": bson.M{"completed_at": time.Now().UTC()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	erasure := &models.CommentErasure{}
+	if err := r.erasures().FindOneAndUpdate(ctx, bson.M{"_id": erasureID}, update, opts).Decode(erasure); err != nil {
+		return nil, fmt.Errorf("failed to complete comment erasure: %w", err)
+	}
+	return erasure, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// commentMergesCollection holds the audit records of comment thread merges
+const commentMergesCollection = "comment_thread_merges"
+
+// CountByContent returns the number of comments on a content, or of its top-level comments
+// without includeReplies
+func (r *commentRepository) CountByContent(ctx context.Context, contentID int64, contentType string, includeReplies bool) (int64, error) {
+	ctx = r.bind(ctx)
+	filter := bson.M{"content_id": contentID, "type": contentType}
+	if !includeReplies {
+		filter["$or"] = topLevelClause()
+	}
+	count, err := r.collection().CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+	return count, nil
+}
+
+// CountByContents returns the number of comments on each of several contents, or of their
+// top-level comments without includeReplies, with one aggregation. Contents without comments
+// are missing from the result.
+func (r *commentRepository) CountByContents(ctx context.Context, contentIDs []int64, contentType string, includeReplies bool) (map[int64]int64, error) {
+	ctx = r.bind(ctx)
+	match := bson.M{"content_id": bson.M{"$in": contentIDs}, "type": contentType}
+	if !includeReplies {
+		match["$or"] = topLevelClause()
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{"_id": "$content_id", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[int64]int64, len(contentIDs))
+	for cursor.Next(ctx) {
+		var group struct {
+			ContentID int64 `bson:"_id"`
+			Count     int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("failed to decode comment count: %w", err)
+		}
+		counts[group.ContentID] = group.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	return counts, nil
+}
+
+// SummarizeFeedbackThreads summarizes the comment threads of several feedbacks with one
+// aggregation. A top-level comment by the feedback's student is an unanswered question until
+// the feedback's reviewer replies to it directly. Feedbacks without comments are missing from
+// the result.
+func (r *commentRepository) SummarizeFeedbackThreads(ctx context.Context, threads []models.FeedbackThread) (map[string]*models.FeedbackThreadSummary, error) {
+	summaries := make(map[string]*models.FeedbackThreadSummary, len(threads))
+	if len(threads) == 0 {
+		return summaries, nil
+	}
+	ctx = r.bind(ctx)
+
+	// Each comment is classified by who wrote it relative to its own feedback
+	refs := make([]string, len(threads))
+	branches := make(bson.A, 0, 2*len(threads))
+	for i, thread := range threads {
+		refs[i] = thread.FeedbackID
+		onThread := bson.M{"$eq": bson.A{"$content_ref", thread.FeedbackID}}
+		branches = append(branches,
+			bson.M{"case": bson.M{"$and": bson.A{onThread, bson.M{"$eq": bson.A{"$user_id", thread.StudentID}}}}, "then": "student"},
+			bson.M{"case": bson.M{"$and": bson.A{onThread, bson.M{"$eq": bson.A{"$user_id", thread.ReviewerID}}}}, "then": "reviewer"},
+		)
+	}
+	topLevel := bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$parent_id", ""}}, ""}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"type": models.CommentTypeFeedback, "content_ref": bson.M{"$in": refs}}}},
+		{{Key: "$addFields", Value: bson.M{"role": bson.M{"$switch": bson.M{"branches": branches, "default": ""}}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$content_ref",
+			"comments":      bson.M{"$sum": 1},
+			"last_activity": bson.M{"$max": "$updated_at"},
+			"questions": bson.M{"$addToSet": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{topLevel, bson.M{"$eq": bson.A{"$role", "student"}}}},
+				bson.M{"$toString": "$_id"},
+				"$$REMOVE",
+			}}},
+			"answered": bson.M{"$addToSet": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{bson.M{"$not": bson.A{topLevel}}, bson.M{"$eq": bson.A{"$role", "reviewer"}}}},
+				"$parent_id",
+				"$$REMOVE",
+			}}},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"comments":      1,
+			"last_activity": 1,
+			"unanswered":    bson.M{"$size": bson.M{"$setDifference": bson.A{"$questions", "$answered"}}},
+		}}},
+	}
+	cursor, err := r.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize comment threads: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			FeedbackID   string    `bson:"_id"`
+			Comments     int64     `bson:"comments"`
+			LastActivity time.Time `bson:"last_activity"`
+			Unanswered   int64     `bson:"unanswered"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return nil, fmt.Errorf("failed to decode comment thread summary: %w", err)
+		}
+		summaries[group.FeedbackID] = &models.FeedbackThreadSummary{
+			CommentCount:        group.Comments,
+			UnansweredQuestions: group.Unanswered,
+			LastActivityAt:      group.LastActivity,
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	return summaries, nil
+}
+
+// MoveContentBatch re-points up to limit comments of the source content to the target and
+// returns how many were moved. Comments are taken shallowest first, so a thread's parents move
+// no later than their replies. Replies keep their parent_id, so threads stay intact.
+func (r *commentRepository) MoveContentBatch(ctx context.Context, sourceContentID, targetContentID int64, contentType string, limit int) (int64, error) {
+	ctx = r.bind(ctx)
+	filter := bson.M{"content_id": sourceContentID, "type": contentType}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "depth", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find comments to move: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return 0, fmt.Errorf("failed to decode comment ID: %w", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, fmt.Errorf("cursor error: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Matching the source again keeps a concurrent attempt from moving a comment twice
+	filter["_id"] = bson.M{"$in": ids}
+	update := bson.M{"$set": bson.M{"content_id": targetContentID, "changed_at": time.Now().UTC()}}
+	result, err := r.collection().UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move comments: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// mergeID returns the ID of the audit record of a merge
+func mergeID(sourceContentID, targetContentID int64, contentType string) string {
+	return fmt.Sprintf("%s:%d:%d", contentType, sourceContentID, targetContentID)
+}
+
+// StartMerge records an attempt of a merge, creating its audit record on the first attempt
+func (r *commentRepository) StartMerge(ctx context.Context, sourceContentID, targetContentID int64, contentType string, actorID int64) (*models.CommentMerge, error) {
+	ctx = r.bind(ctx)
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"source_content_id": sourceContentID,
+			"target_content_id": targetContentID,
+			"type":              contentType,
+			"moved":             int64(0),
+			"started_at":        time.Now().UTC(),
+		},
+		"$set": bson.M{"actor_id": actorID},
+		"$inc": bson.M{"attempts": int32(1)},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	merge := &models.CommentMerge{}
+	id := mergeID(sourceContentID, targetContentID, contentType)
+	if err := r.mongodb.Database.Collection(commentMergesCollection).FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(merge); err != nil {
+		return nil, fmt.Errorf("failed to record comment merge: %w", err)
+	}
+	return merge, nil
+}
+
+// AddMergeProgress adds comments moved by a batch to the audit record of a merge
+func (r *commentRepository) AddMergeProgress(ctx context.Context, mergeID string, moved int64) error {
+	ctx = r.bind(ctx)
+	_, err := r.mongodb.Database.Collection(commentMergesCollection).UpdateOne(ctx, bson.M{"_id": mergeID}, bson.M{"$inc": bson.M{"moved": moved}})
+	if err != nil {
+		return fmt.Errorf("failed to record comment merge progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteMerge marks a merge as complete and returns its audit record
+func (r *commentRepository) CompleteMerge(ctx context.Context, mergeID string) (*models.CommentMerge, error) {
+	ctx = r.bind(ctx)
+	update := bson.M{"$set": bson.M{"completed_at": time.Now().UTC()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	merge := &models.CommentMerge{}
+	if err := r.mongodb.Database.Collection(commentMergesCollection).FindOneAndUpdate(ctx, bson.M{"_id": mergeID}, update, opts).Decode(merge); err != nil {
+		return nil, fmt.Errorf("failed to complete comment merge: %w", err)
+	}
+	return merge, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// commentReaction is the stored reaction of a user to a comment; the unique index on
+// (comment_id, user_id) keeps one per user and comment
+type commentReaction struct {
+	CommentID primitive.ObjectID `bson:"comment_id"`
+	UserID    int64              `bson:"user_id"`
+	Reaction  string             `bson:"reaction"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+// reactions returns the comment reactions collection
+func (r *commentRepository) reactions() *mongo.Collection {
+	return r.mongodb.Database.Collection(database.CommentReactionsCollection)
+}
+
+// SetReaction sets the reaction of a user to a comment, replacing an earlier one in a single
+// findOneAndUpdate, and returns the reaction it replaced or "" if there was none. The server
+// retries an upsert that loses the race to insert the same user and comment.
+func (r *commentRepository) SetReaction(ctx context.Context, commentID string, userID int64, reaction string) (string, error) {
+	ctx = r.bind(ctx)
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return "", fmt.Errorf("invalid comment ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{"comment_id": objectID, "user_id": userID}
+	update := bson.M{
+		"$set":         bson.M{"reaction": reaction, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous commentReaction
+	err = r.reactions().FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set comment reaction: %w", err)
+	}
+	return previous.Reaction, nil
+}
+
+// RemoveReaction deletes the reaction of a user to a comment and returns it, or "" if the user
+// had not reacted
+func (r *commentRepository) RemoveReaction(ctx context.Context, commentID string, userID int64) (string, error) {
+	ctx = r.bind(ctx)
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return "", fmt.Errorf("invalid comment ID: %w", err)
+	}
+
+	var previous commentReaction
+	err = r.reactions().FindOneAndDelete(ctx, bson.M{"comment_id": objectID, "user_id": userID}).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to remove comment reaction: %w", err)
+	}
+	return previous.Reaction, nil
+}
+
+// LoadReactions sets the reaction counts of comments with one aggregation and, for a viewer
+// other than 0, the viewer's own reactions with one more query
+func (r *commentRepository) LoadReactions(ctx context.Context, comments []*models.Comment, viewerUserID int64) error {
+	ctx = r.bind(ctx)
+	if len(comments) == 0 {
+		return nil
+	}
+	byID := make(map[primitive.ObjectID]*models.Comment, len(comments))
+	ids := make([]primitive.ObjectID, len(comments))
+	for i, comment := range comments {
+		byID[comment.ID] = comment
+		ids[i] = comment.ID
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"comment_id": bson.M{"$in": ids}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"comment_id": "$comment_id", "reaction": "$reaction"},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err := r.reactions().Aggregate(ctx, pipeline)
+	if err != nil {
+		return fmt.Errorf("failed to count comment reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var group struct {
+			ID struct {
+				CommentID primitive.ObjectID `bson:"comment_id"`
+				Reaction  string             `bson:"reaction"`
+			} `bson:"_id"`
+			Count int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return fmt.Errorf("failed to decode comment reaction count: %w", err)
+		}
+		if comment, ok := byID[group.ID.CommentID]; ok {
+			comment.CountReaction(group.ID.Reaction, group.Count)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+
+	if viewerUserID == 0 {
+		return nil
+	}
+	viewerCursor, err := r.reactions().Find(ctx, bson.M{"comment_id": bson.M{"$in": ids}, "user_id": viewerUserID})
+	if err != nil {
+		return fmt.Errorf("failed to find viewer reactions: %w", err)
+	}
+	defer viewerCursor.Close(ctx)
+
+	for viewerCursor.Next(ctx) {
+		var reaction commentReaction
+		if err := viewerCursor.Decode(&reaction); err != nil {
+			return fmt.Errorf("failed to decode viewer reaction: %w", err)
+		}
+		if comment, ok := byID[reaction.CommentID]; ok {
+			comment.ViewerReaction = reaction.Reaction
+		}
+	}
+	if err := viewerCursor.Err(); err != nil {
+		return fmt.Errorf("cursor error: %w", err)
+	}
+	return nil
+}
+
+// deleteReactions deletes the reactions to comments, which are being deleted
+func (r *commentRepository) deleteReactions(ctx context.Context, commentIDs []primitive.ObjectID) error {
+	ctx = r.bind(ctx)
+	if _, err := r.reactions().DeleteMany(ctx, bson.M{"comment_id": bson.M{"$in": commentIDs}}); err != nil {
+		return fmt.Errorf("failed to delete comment reactions: %w", err)
+	}
+	return nil
+}
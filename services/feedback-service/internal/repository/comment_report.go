@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recentReportsPerComment is the number of open reports listed with each reported comment
+const recentReportsPerComment = 10
+
+// reports returns the comment reports collection
+func (r *commentRepository) reports() *mongo.Collection {
+	return r.mongodb.Database.Collection(database.CommentReportsCollection)
+}
+
+// SaveReport records a user's report of a comment and reports whether it is new. Reporting a
+// comment again replaces the reason and note and reopens the report, so each reporter counts
+// once per comment.
+func (r *commentRepository) SaveReport(ctx context.Context, report *models.CommentReport) (bool, error) {
+	ctx = r.bind(ctx)
+	now := time.Now().UTC()
+	report.Open = true
+	report.UpdatedAt = now
+
+	filter := bson.M{"comment_id": report.CommentID, "reporter_id": report.ReporterID}
+	update := bson.M{
+		"$set": bson.M{
+			"reason":     report.Reason,
+			"note":       report.Note,
+			"open":       true,
+			"updated_at": now,
+		},
+		"$unset":       bson.M{"resolution": "", "resolved_by": "", "resolved_at": ""},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+	result, err := r.reports().UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("failed to save comment report: %w", err)
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// ListReported lists the comments with open reports, most reported first and then most
+// recently reported, with their report counts per reason and their most recent reports
+func (r *commentRepository) ListReported(ctx context.Context, page, limit int32) ([]*models.ReportedComment, int64, error) {
+	ctx = r.bind(ctx)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"open": true}}},
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":              "$comment_id",
+			"count":            bson.M{"$sum": 1},
+			"last_reported_at": bson.M{"$max": "$updated_at"},
+			"reasons":          bson.M{"$push": "$reason"},
+			"reports":          bson.M{"$push": "$$ROOT"},
+		}}},
+		{{Key: "$set", Value: bson.M{"reports": bson.M{"$slice": bson.A{"$reports", recentReportsPerComment}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "last_reported_at", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$facet", Value: bson.M{
+			"total": bson.A{bson.M{"$count": "count"}},
+			"comments": bson.A{
+				bson.M{"$skip": int64((page - 1) * limit)},
+				bson.M{"$limit": int64(limit)},
+			},
+		}}},
+	}
+	cursor, err := r.reports().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reported comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Comments []struct {
+			CommentID      primitive.ObjectID      `bson:"_id"`
+			Count          int64                   `bson:"count"`
+			LastReportedAt time.Time               `bson:"last_reported_at"`
+			Reasons        []string                `bson:"reasons"`
+			Reports        []*models.CommentReport `bson:"reports"`
+		} `bson:"comments"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode reported comments: %w", err)
+	}
+	if len(result) == 0 || len(result[0].Total) == 0 {
+		return []*models.ReportedComment{}, 0, nil
+	}
+
+	groups := result[0].Comments
+	ids := make([]primitive.ObjectID, len(groups))
+	for i, group := range groups {
+		ids[i] = group.CommentID
+	}
+	comments, err := r.findByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reported := make([]*models.ReportedComment, 0, len(groups))
+	for _, group := range groups {
+		comment, ok := comments[group.CommentID]
+		if !ok {
+			continue // Deleted while the list was read; its reports go with it
+		}
+		reasonCounts := make(map[string]int64)
+		for _, reason := range group.Reasons {
+			reasonCounts[reason]++
+		}
+		reported = append(reported, &models.ReportedComment{
+			Comment:        comment,
+			ReportCount:    group.Count,
+			ReasonCounts:   reasonCounts,
+			RecentReports:  group.Reports,
+			LastReportedAt: group.LastReportedAt,
+		})
+	}
+	return reported, result[0].Total[0].Count, nil
+}
+
+// findByIDs returns the comments with the given IDs, without their edit history
+func (r *commentRepository) findByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Comment, error) {
+	comments := make(map[primitive.ObjectID]*models.Comment, len(ids))
+	if len(ids) == 0 {
+		return comments, nil
+	}
+	cursor, err := r.collection().Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(commentListProjection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find comments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var comment models.Comment
+		if err := cursor.Decode(&comment); err != nil {
+			return nil, fmt.Errorf("failed to decode comment: %w", err)
+		}
+		comments[comment.ID] = &comment
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+	return comments, nil
+}
+
+// CloseReports closes the open reports of a comment with a moderator's decision and returns
+// how many it closed
+func (r *commentRepository) CloseReports(ctx context.Context, commentID string, resolution string, moderatorID int64) (int64, error) {
+	ctx = r.bind(ctx)
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid comment ID: %w", err)
+	}
+
+	update := bson.M{"$set": bson.M{
+		"open":        false,
+		"resolution":  resolution,
+		"resolved_by": moderatorID,
+		"resolved_at": time.Now().UTC(),
+	}}
+	result, err := r.reports().UpdateMany(ctx, bson.M{"comment_id": objectID, "open": true}, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close comment reports: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// SetHidden hides a comment on behalf of a moderator, or shows it again, and returns the
+// updated comment
+func (r *commentRepository) SetHidden(ctx context.Context, commentID string, hidden bool, moderatorID int64) (*models.Comment, error) {
+	ctx = r.bind(ctx)
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comment ID: %w", err)
+	}
+
+	now := time.Now().UTC()
+	update := bson.M{"$unset": bson.M{"hidden_at": "", "hidden_by": ""}, "$set": bson.M{"changed_at": now}}
+	if hidden {
+		update = bson.M{"$set": bson.M{"hidden_at": now, "hidden_by": moderatorID, "changed_at": now}}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(commentListProjection)
+
+	var comment models.Comment
+	err = r.collection().FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&comment)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update comment visibility: %w", err)
+	}
+	return &comment, nil
+}
+
+// deleteReports deletes the reports of comments, which are being deleted
+func (r *commentRepository) deleteReports(ctx context.Context, commentIDs []primitive.ObjectID) error {
+	ctx = r.bind(ctx)
+	if _, err := r.reports().DeleteMany(ctx, bson.M{"comment_id": bson.M{"$in": commentIDs}}); err != nil {
+		return fmt.Errorf("failed to delete comment reports: %w", err)
+	}
+	return nil
+}
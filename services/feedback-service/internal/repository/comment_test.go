@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testMongoDBEnv names a MongoDB server the comment repository tests create their databases on.
+// The tests needing it are skipped when it is unset.
+const testMongoDBEnv = "FEEDBACK_TEST_MONGODB_URI"
+
+// openTestMongoDB returns a connection to a fresh database of the test server, dropped when the
+// test ends
+func openTestMongoDB(t *testing.T) *database.MongoDBClient {
+	t.Helper()
+	uri := os.Getenv(testMongoDBEnv)
+	if uri == "" {
+		t.Skipf("%s is not set", testMongoDBEnv)
+	}
+	ctx := context.Background()
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	mongodb, err := database.ConnectMongoDB(ctx, config.MongoDBConfig{URI: uri, Database: "feedback_test_" + hex.EncodeToString(suffix)})
+	if err != nil {
+		t.Fatalf("connect to test MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		mongodb.Database.Drop(ctx)
+		mongodb.Client.Disconnect(ctx)
+	})
+	return mongodb
+}
+
+// TestBackfillDepthMongo backfills a thread tree stored before comments had a depth, with
+// batches smaller than its levels
+func TestBackfillDepthMongo(t *testing.T) {
+	mongodb := openTestMongoDB(t)
+	ctx := context.Background()
+	r := NewCommentRepository(mongodb, "comments")
+
+	ids := make(map[string]primitive.ObjectID)
+	for _, name := range []string{"a", "b", "c", "a1", "a2", "a1x", "a1xy", "b1", "b1x"} {
+		ids[name] = primitive.NewObjectID()
+	}
+	// Each top-level form a stored comment may have, replies without a depth, and stored depths
+	// that are already right or wrong
+	fixture := []bson.M{
+		{"_id": ids["a"]},
+		{"_id": ids["b"], "parent_id": nil, "depth": 0},
+		{"_id": ids["c"], "parent_id": ""},
+		{"_id": ids["a1"], "parent_id": ids["a"].Hex(), "depth": 5},
+		{"_id": ids["a2"], "parent_id": ids["a"].Hex()},
+		{"_id": ids["a1x"], "parent_id": ids["a1"].Hex()},
+		{"_id": ids["a1xy"], "parent_id": ids["a1x"].Hex()},
+		{"_id": ids["b1"], "parent_id": ids["b"].Hex(), "depth": 1},
+		{"_id": ids["b1x"], "parent_id": ids["b1"].Hex()},
+	}
+	documents := make([]interface{}, len(fixture))
+	for i, doc := range fixture {
+		doc["content_id"], doc["type"], doc["content"] = int64(401), "lab", "comment"
+		documents[i] = doc
+	}
+	if _, err := mongodb.Database.Collection("comments").InsertMany(ctx, documents); err != nil {
+		t.Fatalf("seed comments: %v", err)
+	}
+
+	modified, err := r.BackfillDepth(ctx, 2)
+	if err != nil {
+		t.Fatalf("BackfillDepth() error = %v", err)
+	}
+	// b and b1 already had the right depth
+	if modified != 7 {
+		t.Errorf("BackfillDepth() modified = %d, want 7", modified)
+	}
+
+	want := map[string]int32{"a": 0, "b": 0, "c": 0, "a1": 1, "a2": 1, "a1x": 2, "a1xy": 3, "b1": 1, "b1x": 2}
+	for name, depth := range want {
+		comment, err := r.GetByID(ctx, ids[name].Hex())
+		if err != nil {
+			t.Fatalf("GetByID(%s) error = %v", name, err)
+		}
+		if comment.Depth != depth {
+			t.Errorf("comment %s depth = %d, want %d", name, comment.Depth, depth)
+		}
+	}
+
+	if modified, err := r.BackfillDepth(ctx, 2); err != nil || modified != 0 {
+		t.Errorf("second BackfillDepth() = %d, %v, want nothing left to modify", modified, err)
+	}
+}
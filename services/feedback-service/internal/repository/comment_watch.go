@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deletions returns the comment deletions collection
+func (r *commentRepository) deletions() *mongo.Collection {
+	return r.mongodb.Database.Collection(database.CommentDeletionsCollection)
+}
+
+// recordDeletion stores the record of a deletion, in the transaction of the deletion
+func (r *commentRepository) recordDeletion(ctx context.Context, deletion *models.CommentDeletion) error {
+	ctx = r.bind(ctx)
+	if _, err := r.deletions().InsertOne(ctx, deletion); err != nil {
+		return fmt.Errorf("failed to record comment deletion: %w", err)
+	}
+	return nil
+}
+
+// ChangeStreamsSupported reports whether the deployment can serve change streams: a replica set
+// or a sharded cluster, but not a standalone server
+func (r *commentRepository) ChangeStreamsSupported(ctx context.Context) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := r.mongodb.Database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, fmt.Errorf("failed to describe the MongoDB deployment: %w", err)
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// commentChangeEvent returns the event of a comment's last change: created when it has not
+// changed since it was written, updated otherwise
+func commentChangeEvent(comment *models.Comment) models.CommentStreamEvent {
+	kind := models.CommentEventUpdated
+	if comment.ChangedAt.Equal(comment.CreatedAt) {
+		kind = models.CommentEventCreated
+	}
+	comment.Edits = nil
+	return models.CommentStreamEvent{Kind: kind, Comment: comment, OccurredAt: comment.ChangedAt}
+}
+
+// WatchChanges passes the changes to comments to fn as they are committed, on any content,
+// until ctx is done, when it returns nil, or the change stream fails. opened is called once the
+// stream is open, before any change is passed. Writes that do not set changed_at, such as
+// depth backfills, are left out. It needs a deployment for which ChangeStreamsSupported is true.
+func (r *commentRepository) WatchChanges(ctx context.Context, opened func(), fn func(event models.CommentStreamEvent)) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"ns.coll": r.collectionName, "operationType": "insert"},
+			bson.M{"ns.coll": r.collectionName, "operationType": "update", "updateDescription.updatedFields.changed_at": bson.M{"$exists": true}},
+			bson.M{"ns.coll": database.CommentDeletionsCollection, "operationType": "insert"},
+		}}}},
+		{{Key: "$project", Value: bson.M{"fullDocument.edits": 0}}},
+	}
+	stream, err := r.mongodb.Database.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return fmt.Errorf("failed to open comment change stream: %w", err)
+	}
+	defer stream.Close(context.Background())
+	opened()
+
+	for stream.Next(ctx) {
+		var change struct {
+			NS struct {
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+			FullDocument bson.RawValue `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return fmt.Errorf("failed to decode comment change: %w", err)
+		}
+		if change.FullDocument.Type != bson.TypeEmbeddedDocument {
+			continue // Updated, then deleted before the update was looked up; the deletion follows
+		}
+
+		if change.NS.Coll == database.CommentDeletionsCollection {
+			var deletion models.CommentDeletion
+			if err := change.FullDocument.Unmarshal(&deletion); err != nil {
+				return fmt.Errorf("failed to decode comment deletion: %w", err)
+			}
+			for _, event := range deletion.Events() {
+				fn(event)
+			}
+			continue
+		}
+		var comment models.Comment
+		if err := change.FullDocument.Unmarshal(&comment); err != nil {
+			return fmt.Errorf("failed to decode changed comment: %w", err)
+		}
+		fn(commentChangeEvent(&comment))
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("comment change stream failed: %w", stream.Err())
+}
+
+// commentChange is a changed comment or a deletion record at its position in the list of changes
+type commentChange struct {
+	at     models.CommentChangeCursor
+	events []models.CommentStreamEvent
+}
+
+// ListChanges lists the changes to comments made since filter.Since, or after filter.After,
+// oldest first, reading at most filter.Limit changed comments and deletion records. Comments
+// come in their current state, once however often they changed. The returned cursor continues
+// the list and is nil at its end.
+func (r *commentRepository) ListChanges(ctx context.Context, filter models.CommentChangeFilter) ([]models.CommentStreamEvent, *models.CommentChangeCursor, error) {
+	ctx = r.bind(ctx)
+	opts := func(field string) *options.FindOptions {
+		return options.Find().
+			SetSort(bson.D{{Key: field, Value: 1}, {Key: "_id", Value: 1}}).
+			SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := r.collection().Find(ctx, changeFilter(filter, "changed_at"), opts("changed_at").SetProjection(commentListProjection))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list changed comments: %w", err)
+	}
+	var comments []*models.Comment
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode changed comments: %w", err)
+	}
+
+	cursor, err = r.deletions().Find(ctx, changeFilter(filter, "deleted_at"), opts("deleted_at"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list comment deletions: %w", err)
+	}
+	var deletions []*models.CommentDeletion
+	if err := cursor.All(ctx, &deletions); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode comment deletions: %w", err)
+	}
+
+	changes := make([]commentChange, 0, len(comments)+len(deletions))
+	for _, comment := range comments {
+		changes = append(changes, commentChange{
+			at:     models.CommentChangeCursor{At: comment.ChangedAt, ID: comment.ID},
+			events: []models.CommentStreamEvent{commentChangeEvent(comment)},
+		})
+	}
+	for _, deletion := range deletions {
+		changes = append(changes, commentChange{
+			at:     models.CommentChangeCursor{At: deletion.DeletedAt, ID: deletion.ID},
+			events: deletion.Events(),
+		})
+	}
+	slices.SortFunc(changes, func(a, b commentChange) int {
+		if c := a.at.At.Compare(b.at.At); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.at.ID[:], b.at.ID[:])
+	})
+
+	// Either list may end before the other; the changes after the last one of a full list are
+	// read with the next page
+	more := len(comments) == filter.Limit || len(deletions) == filter.Limit
+	if len(changes) > filter.Limit {
+		changes = changes[:filter.Limit]
+		more = true
+	}
+	var events []models.CommentStreamEvent
+	for _, change := range changes {
+		events = append(events, change.events...)
+	}
+	if !more {
+		return events, nil, nil
+	}
+	next := changes[len(changes)-1].at
+	return events, &next, nil
+}
+
+// changeFilter builds the query of ListChanges for a collection whose change time is in field
+func changeFilter(filter models.CommentChangeFilter, field string) bson.M {
+	query := bson.M{field: bson.M{"$gte": filter.Since}}
+	if filter.After != nil {
+		query = bson.M{"$or": bson.A{
+			bson.M{field: bson.M{"$gt": filter.After.At}},
+			bson.M{field: filter.After.At, "_id": bson.M{"$gt": filter.After.ID}},
+		}}
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+		query["content_id"] = filter.ContentID
+		if filter.ContentRef != "" {
+			query["content_ref"] = filter.ContentRef
+		}
+	}
+	return query
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// contentOutboxRepository implements ContentOutboxRepository using the feedback_content_outbox
+// table. Entries are written by feedbackRepository inside its transactions, see enqueueContent.
+type contentOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewContentOutboxRepository creates a new feedback content outbox repository
+func NewContentOutboxRepository(db *sql.DB) ContentOutboxRepository {
+	return &contentOutboxRepository{
+		db: db,
+	}
+}
+
+// enqueueContent records the content of a feedback for MongoDB in a transaction, replacing any
+// content still pending for it. It returns the version of the entry.
+func enqueueContent(ctx context.Context, tx *sql.Tx, feedbackID uuid.UUID, content string) (int64, error) {
+	var version int64
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO feedback_content_outbox (feedback_id, content)
+		VALUES ($1, $2)
+		ON CONFLICT (feedback_id) DO UPDATE
+		SET content = EXCLUDED.content, version = feedback_content_outbox.version + 1,
+			attempts = 0, last_error = '', next_attempt_at = NOW(), updated_at = NOW()
+		RETURNING version
+	`, feedbackID, content).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record feedback content in outbox: %w", err)
+	}
+	return version, nil
+}
+
+// completeContent removes an outbox entry whose content was written, unless newer content was
+// recorded since
+func completeContent(ctx context.Context, db *sql.DB, feedbackID uuid.UUID, version int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM feedback_content_outbox WHERE feedback_id = $1 AND version = $2`, feedbackID, version)
+	if err != nil {
+		return fmt.Errorf("failed to complete content outbox entry: %w", err)
+	}
+	return nil
+}
+
+// pendingContent returns the content recorded for a feedback that MongoDB may not hold yet
+func pendingContent(ctx context.Context, db *sql.DB, feedbackID uuid.UUID) (string, bool, error) {
+	var content string
+	err := db.QueryRowContext(ctx, `SELECT content FROM feedback_content_outbox WHERE feedback_id = $1`, feedbackID).Scan(&content)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get pending feedback content: %w", err)
+	}
+	return content, true, nil
+}
+
+// pendingContents returns the content pending in the outbox for any of the feedbacks, keyed by
+// feedback ID
+func pendingContents(ctx context.Context, db *sql.DB, feedbackIDs []string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT feedback_id, content FROM feedback_content_outbox WHERE feedback_id = ANY($1::uuid[])`, pq.Array(feedbackIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending feedback contents: %w", err)
+	}
+	defer rows.Close()
+
+	contents := make(map[string]string)
+	for rows.Next() {
+		var feedbackID uuid.UUID
+		var content string
+		if err := rows.Scan(&feedbackID, &content); err != nil {
+			return nil, fmt.Errorf("failed to scan pending feedback content: %w", err)
+		}
+		contents[feedbackID.String()] = content
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending feedback contents: %w", err)
+	}
+	return contents, nil
+}
+
+// ListPending returns up to limit entries after a feedback ID, in feedback ID order. With due
+// set, only entries whose next attempt is due are returned. A feedbackID other than uuid.Nil
+// restricts the list to that feedback.
+func (r *contentOutboxRepository) ListPending(ctx context.Context, feedbackID uuid.UUID, after uuid.UUID, due bool, limit int) ([]*models.ContentOutboxEntry, error) {
+	query := `
+		SELECT feedback_id, content, version, attempts, last_error, next_attempt_at, created_at
+		FROM feedback_content_outbox
+		WHERE feedback_id > $1
+			AND ($2 = '00000000-0000-0000-0000-000000000000'::uuid OR feedback_id = $2)
+			AND (NOT $3 OR next_attempt_at <= NOW())
+		ORDER BY feedback_id
+		LIMIT $4
+	`
+	rows, err := r.db.QueryContext(ctx, query, after, feedbackID, due, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.ContentOutboxEntry
+	for rows.Next() {
+		entry := &models.ContentOutboxEntry{}
+		err := rows.Scan(&entry.FeedbackID, &entry.Content, &entry.Version, &entry.Attempts,
+			&entry.LastError, &entry.NextAttemptAt, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan content outbox entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content outbox entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Complete removes an entry whose content was written to MongoDB. An entry replaced by newer
+// content in the meantime is kept for that content.
+func (r *contentOutboxRepository) Complete(ctx context.Context, entry *models.ContentOutboxEntry) error {
+	return completeContent(ctx, r.db, entry.FeedbackID, entry.Version)
+}
+
+// RecordFailure counts a failed attempt to write an entry and schedules the next one after
+// retryIn, unless the entry was replaced by newer content in the meantime
+func (r *contentOutboxRepository) RecordFailure(ctx context.Context, entry *models.ContentOutboxEntry, message string, retryIn time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE feedback_content_outbox
+		SET attempts = attempts + 1, last_error = $3, next_attempt_at = NOW() + make_interval(secs => $4), updated_at = NOW()
+		WHERE feedback_id = $1 AND version = $2
+	`, entry.FeedbackID, entry.Version, message, retryIn.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to record content outbox failure: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of pending entries
+func (r *contentOutboxRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feedback_content_outbox`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count content outbox entries: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteByFeedback removes the pending entry of a feedback and returns how many were removed
+func (r *contentOutboxRepository) DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM feedback_content_outbox WHERE feedback_id = $1`, feedbackID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete content outbox entry: %w", err)
+	}
+	return result.RowsAffected()
+}
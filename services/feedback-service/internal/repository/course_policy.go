@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// coursePolicyRepository implements CoursePolicyRepository using the course_policies table
+type coursePolicyRepository struct {
+	db *sql.DB
+}
+
+// NewCoursePolicyRepository creates a new course policy repository
+func NewCoursePolicyRepository(db *sql.DB) CoursePolicyRepository {
+	return &coursePolicyRepository{
+		db: db,
+	}
+}
+
+// scanCoursePolicy scans a course_policies row
+func scanCoursePolicy(scan func(dest ...interface{}) error) (*models.CoursePolicy, error) {
+	policy := &models.CoursePolicy{}
+	var overrides []byte
+	if err := scan(&policy.CourseID, &overrides, &policy.UpdatedBy, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(overrides, &policy.Overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode policy of course %s: %w", policy.CourseID, err)
+	}
+	return policy, nil
+}
+
+// Get returns the policy of a course, or nil if the course has none
+func (r *coursePolicyRepository) Get(ctx context.Context, courseID string) (*models.CoursePolicy, error) {
+	query := `
+		SELECT course_id, policy, updated_by, created_at, updated_at
+		FROM course_policies
+		WHERE course_id = $1
+	`
+	policy, err := scanCoursePolicy(r.db.QueryRowContext(ctx, query, courseID).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course policy: %w", err)
+	}
+	return policy, nil
+}
+
+// List returns all course policies ordered by course ID
+func (r *coursePolicyRepository) List(ctx context.Context) ([]*models.CoursePolicy, error) {
+	query := `
+		SELECT course_id, policy, updated_by, created_at, updated_at
+		FROM course_policies
+		ORDER BY course_id
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list course policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*models.CoursePolicy
+	for rows.Next() {
+		policy, err := scanCoursePolicy(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating course policy rows: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Upsert creates or replaces the policy of a course and fills its timestamps
+func (r *coursePolicyRepository) Upsert(ctx context.Context, policy *models.CoursePolicy) error {
+	overrides, err := json.Marshal(policy.Overrides)
+	if err != nil {
+		return fmt.Errorf("failed to encode course policy: %w", err)
+	}
+
+	query := `
+		INSERT INTO course_policies (course_id, policy, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (course_id)
+		DO UPDATE SET policy = EXCLUDED.policy, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	err = r.db.QueryRowContext(ctx, query, policy.CourseID, overrides, policy.UpdatedBy).Scan(&policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save course policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the policy of a course and reports whether one existed
+func (r *coursePolicyRepository) Delete(ctx context.Context, courseID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM course_policies WHERE course_id = $1`, courseID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete course policy: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// deletionIntentRepository implements DeletionIntentRepository using the
+// feedback_deletion_intents table
+type deletionIntentRepository struct {
+	db *sql.DB
+}
+
+// NewDeletionIntentRepository creates a new feedback deletion intent repository
+func NewDeletionIntentRepository(db *sql.DB) DeletionIntentRepository {
+	return &deletionIntentRepository{
+		db: db,
+	}
+}
+
+// Begin records the intent to hard delete a feedback and hides the feedback from reads in the
+// same transaction. Beginning a deletion that already has an intent keeps the original request
+// and succeeds even if the feedback row is already gone, so an interrupted deletion can be resumed.
+func (r *deletionIntentRepository) Begin(ctx context.Context, intent *models.DeletionIntent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE feedbacks
+		SET deleted_at = COALESCE(deleted_at, NOW()), deleted_by = COALESCE(deleted_by, $2)
+		WHERE id = $1
+	`, intent.FeedbackID, intent.RequestedBy)
+	if err != nil {
+		return fmt.Errorf("failed to hide feedback: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		// Without a feedback row only an existing intent may be resumed
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM feedback_deletion_intents WHERE feedback_id = $1)`,
+			intent.FeedbackID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check deletion intent: %w", err)
+		}
+		if !exists {
+			return ErrFeedbackNotFound
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO feedback_deletion_intents (feedback_id, requested_by, action, details)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (feedback_id) DO UPDATE SET updated_at = NOW()
+	`, intent.FeedbackID, intent.RequestedBy, intent.Action, intent.Details)
+	if err != nil {
+		return fmt.Errorf("failed to record deletion intent: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit deletion intent: %w", err)
+	}
+	return nil
+}
+
+// ListStale returns up to limit intents that were not touched for olderThan, oldest first
+func (r *deletionIntentRepository) ListStale(ctx context.Context, olderThan time.Duration, limit int) ([]*models.DeletionIntent, error) {
+	query := `
+		SELECT feedback_id, requested_by, action, details, attempts, last_error, created_at, updated_at
+		FROM feedback_deletion_intents
+		WHERE updated_at < NOW() - make_interval(secs => $1)
+		ORDER BY updated_at, feedback_id
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, olderThan.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale deletion intents: %w", err)
+	}
+	defer rows.Close()
+
+	var intents []*models.DeletionIntent
+	for rows.Next() {
+		intent := &models.DeletionIntent{}
+		err := rows.Scan(&intent.FeedbackID, &intent.RequestedBy, &intent.Action, &intent.Details,
+			&intent.Attempts, &intent.LastError, &intent.CreatedAt, &intent.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deletion intent: %w", err)
+		}
+		intents = append(intents, intent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deletion intents: %w", err)
+	}
+
+	return intents, nil
+}
+
+// RecordFailure counts a failed attempt to complete a deletion. It also touches the intent, so
+// the next recovery run retries it only after it is stale again.
+func (r *deletionIntentRepository) RecordFailure(ctx context.Context, feedbackID uuid.UUID, message string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE feedback_deletion_intents
+		SET attempts = attempts + 1, last_error = $2, updated_at = NOW()
+		WHERE feedback_id = $1
+	`, feedbackID, message)
+	if err != nil {
+		return fmt.Errorf("failed to record deletion failure: %w", err)
+	}
+	return nil
+}
+
+// Complete removes the intent of a finished deletion; completing twice is a no-op
+func (r *deletionIntentRepository) Complete(ctx context.Context, feedbackID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM feedback_deletion_intents WHERE feedback_id = $1`, feedbackID)
+	if err != nil {
+		return fmt.Errorf("failed to complete deletion intent: %w", err)
+	}
+	return nil
+}
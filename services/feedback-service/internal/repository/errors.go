@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrFeedbackNotFound is returned when a feedback does not exist or is deleted
+	ErrFeedbackNotFound = apperr.NotFound("FEEDBACK_NOT_FOUND", "feedback not found")
+
+	// ErrCommentNotFound is returned when a comment does not exist
+	ErrCommentNotFound = apperr.NotFound("COMMENT_NOT_FOUND", "comment not found")
+
+	// ErrFeedbackSealNotFound is returned when a feedback has never been sealed
+	ErrFeedbackSealNotFound = apperr.NotFound("FEEDBACK_SEAL_NOT_FOUND", "feedback has not been sealed")
+
+	// ErrTemplateNotFound is returned when a feedback template does not exist
+	ErrTemplateNotFound = apperr.NotFound("TEMPLATE_NOT_FOUND", "feedback template not found")
+
+	// ErrTemplateNameTaken is returned when a reviewer already has a template with the name
+	ErrTemplateNameTaken = apperr.AlreadyExists("TEMPLATE_NAME_TAKEN", "a feedback template with this name already exists")
+
+	// ErrUploadSessionNotFound is returned when an upload session does not exist
+	ErrUploadSessionNotFound = apperr.NotFound("UPLOAD_SESSION_NOT_FOUND", "upload session not found")
+
+	// ErrAttachmentsEncrypted is returned for direct object locations while attachments are
+	// encrypted, since reading the objects directly would only yield ciphertext
+	ErrAttachmentsEncrypted = apperr.FailedPrecondition("ATTACHMENTS_ENCRYPTED", "attachment locations are unavailable while attachments are encrypted")
+
+	// ErrAttachmentsMigrating is returned by the consistency-check listing while attachments
+	// are read from two locations, since a prefix could be listed twice or under the wrong name
+	ErrAttachmentsMigrating = apperr.FailedPrecondition("ATTACHMENTS_MIGRATING", "attachment storage is being migrated; the consistency check is unavailable until cutover")
+)
+
+// ErrIdempotencyKeyInUse is returned when a feedback is created with an idempotency key that
+// another feedback of the reviewer was created with, e.g. by a concurrent retry
+var ErrIdempotencyKeyInUse = errors.New("idempotency key is already in use")
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err violates the named unique index
+func isUniqueViolation(err error, index string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == index
+}
+
+// foreignKeyViolation is the PostgreSQL error code of a foreign key constraint violation
+const foreignKeyViolation = "23503"
+
+// isForeignKeyViolation reports whether err references a row that does not exist
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation
+}
+
+// DuplicateFeedbackError is returned when a reviewer creates a second feedback for a submission.
+// ExistingID is uuid.Nil if the existing feedback was deleted in the meantime.
+type DuplicateFeedbackError struct {
+	ExistingID uuid.UUID
+}
+
+func (e *DuplicateFeedbackError) Error() string {
+	return fmt.Sprintf("reviewer already has feedback %s for this submission", e.ExistingID)
+}
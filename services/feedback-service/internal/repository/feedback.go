@@ -1,14 +1,19 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -19,23 +24,43 @@ import (
 type feedbackRepository struct {
 	db      *sql.DB
 	mongodb *database.MongoDBClient
+	schema  *Schema // Shape of the feedbacks table; nil for the latest
 }
 
-// NewFeedbackRepository creates a new feedback repository
-func NewFeedbackRepository(db *sql.DB, mongodb *database.MongoDBClient) FeedbackRepository {
+// NewFeedbackRepository creates a new feedback repository. schema is the shape of the feedbacks
+// table found by LoadSchema, or nil to assume the latest one.
+func NewFeedbackRepository(db *sql.DB, mongodb *database.MongoDBClient, schema *Schema) FeedbackRepository {
 	return &feedbackRepository{
 		db:      db,
 		mongodb: mongodb,
+		schema:  schema,
 	}
 }
 
-// Create creates a new feedback entry
+// Create creates a new feedback entry. Its content is recorded in the content outbox in the
+// same transaction as the row and then written to MongoDB; if that write fails, the outbox
+// worker retries it, see service.FeedbackService.RunContentOutbox.
 func (r *feedbackRepository) Create(ctx context.Context, feedback *models.Feedback) error {
 	// Generate UUID
 	feedback.ID = uuid.New()
 	now := time.Now()
 	feedback.CreatedAt = now
 	feedback.UpdatedAt = now
+	if feedback.IsPublished() {
+		feedback.PublishedAt = &now
+	} else {
+		feedback.Status = models.FeedbackDraft
+		feedback.PublishedAt = nil
+	}
+	// Approval is requested separately, after creation
+	feedback.ApprovalStatus = models.ApprovalNotRequired
+	feedback.ApproverID = nil
+	feedback.ApprovalNote = ""
+
+	snapshot, err := encodeSnapshot(feedback.Snapshot)
+	if err != nil {
+		return err
+	}
 
 	// Start transaction for PostgreSQL
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -45,54 +70,122 @@ func (r *feedbackRepository) Create(ctx context.Context, feedback *models.Feedba
 	defer tx.Rollback()
 
 	// Insert metadata into PostgreSQL
-	query := `
-		INSERT INTO feedbacks (id, reviewer_id, student_id, submission_id, title, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`
-	_, err = tx.ExecContext(ctx, query,
+	columns := "id, reviewer_id, student_id, submission_id, title, submission_snapshot, course_id, points, max_points, status, published_at, created_at, updated_at"
+	values := "$1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, $12, $13"
+	points, maxPoints := encodeGrade(feedback.Grade)
+	args := []interface{}{
 		feedback.ID, feedback.ReviewerID, feedback.StudentID, feedback.SubmissionID, feedback.Title,
-		feedback.CreatedAt, feedback.UpdatedAt,
-	)
+		snapshot, feedback.CourseID, points, maxPoints, feedback.Status, feedback.PublishedAt, feedback.CreatedAt, feedback.UpdatedAt,
+	}
+	// Idempotency keys are only recorded once their columns are written
+	if r.schema.writable("idempotency_key") && r.schema.writable("idempotency_fingerprint") {
+		columns += ", idempotency_key, idempotency_fingerprint"
+		values += ", NULLIF($14, ''), NULLIF($15, '')"
+		args = append(args, feedback.IdempotencyKey, feedback.IdempotencyFingerprint)
+	}
+	if r.resubmissionWritable() {
+		columns += ", requires_resubmission, resubmission_deadline"
+		values += fmt.Sprintf(", $%d, $%d", len(args)+1, len(args)+2)
+		args = append(args, feedback.RequiresResubmission, feedback.ResubmissionDeadline)
+	} else if feedback.RequiresResubmission {
+		return columnUnavailable("requires_resubmission")
+	}
+	query := fmt.Sprintf("INSERT INTO feedbacks (%s) VALUES (%s)", columns, values)
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
+		if isUniqueViolation(err, "idx_feedbacks_idempotency_key") {
+			return ErrIdempotencyKeyInUse
+		}
+		if isUniqueViolation(err, "idx_feedbacks_reviewer_submission") {
+			tx.Rollback()
+			return &DuplicateFeedbackError{ExistingID: r.activeFeedbackID(ctx, feedback.ReviewerID, feedback.SubmissionID)}
+		}
 		return fmt.Errorf("failed to create feedback metadata: %w", err)
 	}
 
+	if err := replaceRubricItems(ctx, tx, feedback.ID, feedback.RubricItems); err != nil {
+		return err
+	}
+
+	// Record content for MongoDB if provided
+	var version int64
+	if feedback.Content != "" {
+		if version, err = enqueueContent(ctx, tx, feedback.ID, feedback.Content); err != nil {
+			return err
+		}
+	}
+
 	// Commit PostgreSQL transaction
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit feedback metadata: %w", err)
 	}
 
-	// Store content in MongoDB if provided
 	if feedback.Content != "" {
-		if err := r.SetContent(ctx, feedback.ID, feedback.Content); err != nil {
-			// Rollback: delete from PostgreSQL if MongoDB fails
-			r.Delete(ctx, feedback.ID)
-			return fmt.Errorf("failed to store feedback content: %w", err)
-		}
+		r.applyContent(ctx, feedback.ID, feedback.Content, version)
 	}
 
 	return nil
 }
 
+// applyContent writes committed content to MongoDB and completes its outbox entry. A failure
+// leaves the entry for the outbox worker, so the write that committed it still succeeds.
+func (r *feedbackRepository) applyContent(ctx context.Context, id uuid.UUID, content string, version int64) {
+	if err := r.SetContent(ctx, id, content); err != nil {
+		return
+	}
+	// An entry left behind is written again by the worker, which is harmless
+	_ = completeContent(ctx, r.db, id, version)
+}
+
+// activeFeedbackID returns the ID of a reviewer's feedback for a submission, or uuid.Nil if
+// there is none or it cannot be read
+func (r *feedbackRepository) activeFeedbackID(ctx context.Context, reviewerID, submissionID int64) uuid.UUID {
+	var id uuid.UUID
+	query := `SELECT id FROM feedbacks WHERE reviewer_id = $1 AND submission_id = $2 AND deleted_at IS NULL`
+	if err := r.db.QueryRowContext(ctx, query, reviewerID, submissionID).Scan(&id); err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
 // GetByID retrieves a feedback by ID
 func (r *feedbackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Feedback, error) {
 	query := `
-		SELECT id, reviewer_id, student_id, submission_id, title, created_at, updated_at
+		SELECT ` + r.schema.selectColumns() + `
 		FROM feedbacks
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	feedback := &models.Feedback{}
+	var snapshot []byte
+	var points, maxPoints sql.NullFloat64
+	var publishedAt, readAt, resubmissionDeadline, archivedAt sql.NullTime
+	var approverID sql.NullInt64
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&feedback.ID, &feedback.ReviewerID, &feedback.StudentID, &feedback.SubmissionID,
-		&feedback.Title, &feedback.CreatedAt, &feedback.UpdatedAt,
+		&feedback.Title, &feedback.Locked, &feedback.LockReason, &feedback.NeedsReupload, &snapshot, &feedback.CourseID, &points, &maxPoints, &feedback.Status, &publishedAt, &feedback.ApprovalStatus, &approverID, &feedback.ApprovalNote, &readAt, &feedback.RevisionCount, &feedback.RequiresResubmission, &resubmissionDeadline, &archivedAt, &feedback.CreatedAt, &feedback.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("feedback not found: %w", err)
+			return nil, ErrFeedbackNotFound.Wrap(err)
 		}
 		return nil, fmt.Errorf("failed to get feedback: %w", err)
 	}
+	if feedback.Snapshot, err = decodeSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	feedback.Grade = decodeGrade(points, maxPoints)
+	feedback.PublishedAt = decodeTime(publishedAt)
+	feedback.ReadAt = decodeTime(readAt)
+	feedback.ResubmissionDeadline = decodeTime(resubmissionDeadline)
+	feedback.ArchivedAt = decodeTime(archivedAt)
+	feedback.ApproverID = decodeInt64(approverID)
+	if err := r.loadRubricItems(ctx, []*models.Feedback{feedback}); err != nil {
+		return nil, err
+	}
+	if err := r.loadReactionCounts(ctx, []*models.Feedback{feedback}); err != nil {
+		return nil, err
+	}
 
 	// Get content from MongoDB
 	content, err := r.GetContent(ctx, id)
@@ -104,40 +197,262 @@ func (r *feedbackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return feedback, nil
 }
 
-// Update updates an existing feedback
-func (r *feedbackRepository) Update(ctx context.Context, feedback *models.Feedback) error {
-	feedback.UpdatedAt = time.Now()
+// GetByIDs retrieves the feedbacks with the given IDs in one query, in no particular order.
+// Deleted and unknown IDs are left out.
+func (r *feedbackRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Feedback, error) {
+	if len(ids) == 0 {
+		return []*models.Feedback{}, nil
+	}
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
 
-	// Update metadata in PostgreSQL
 	query := `
-		UPDATE feedbacks 
-		SET title = $2, updated_at = $3
-		WHERE id = $1
+		SELECT ` + r.schema.selectColumns() + `
+		FROM feedbacks
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
 	`
-	result, err := r.db.ExecContext(ctx, query, feedback.ID, feedback.Title, feedback.UpdatedAt)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(values))
 	if err != nil {
-		return fmt.Errorf("failed to update feedback metadata: %w", err)
+		return nil, fmt.Errorf("failed to get feedbacks: %w", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	feedbacks := make([]*models.Feedback, 0, len(ids))
+	for rows.Next() {
+		feedback := &models.Feedback{}
+		var snapshot []byte
+		var points, maxPoints sql.NullFloat64
+		var publishedAt, readAt, resubmissionDeadline, archivedAt sql.NullTime
+		var approverID sql.NullInt64
+		err := rows.Scan(
+			&feedback.ID, &feedback.ReviewerID, &feedback.StudentID, &feedback.SubmissionID,
+			&feedback.Title, &feedback.Locked, &feedback.LockReason, &feedback.NeedsReupload, &snapshot, &feedback.CourseID, &points, &maxPoints, &feedback.Status, &publishedAt, &feedback.ApprovalStatus, &approverID, &feedback.ApprovalNote, &readAt, &feedback.RevisionCount, &feedback.RequiresResubmission, &resubmissionDeadline, &archivedAt, &feedback.CreatedAt, &feedback.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		if feedback.Snapshot, err = decodeSnapshot(snapshot); err != nil {
+			return nil, err
+		}
+		feedback.Grade = decodeGrade(points, maxPoints)
+		feedback.PublishedAt = decodeTime(publishedAt)
+		feedback.ReadAt = decodeTime(readAt)
+		feedback.ResubmissionDeadline = decodeTime(resubmissionDeadline)
+		feedback.ArchivedAt = decodeTime(archivedAt)
+		feedback.ApproverID = decodeInt64(approverID)
+		feedbacks = append(feedbacks, feedback)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback rows: %w", err)
+	}
+	if len(feedbacks) == 0 {
+		return feedbacks, nil
+	}
+	if err := r.loadRubricItems(ctx, feedbacks); err != nil {
+		return nil, err
+	}
+	if err := r.loadReactionCounts(ctx, feedbacks); err != nil {
+		return nil, err
+	}
+
+	// Get content from MongoDB in a single query
+	feedbackIDs := make([]string, len(feedbacks))
+	for i, feedback := range feedbacks {
+		feedbackIDs[i] = feedback.ID.String()
+	}
+	contentMap, err := r.GetContents(ctx, feedbackIDs)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to get feedback contents: %w", err)
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("feedback not found")
+	for _, feedback := range feedbacks {
+		feedback.Content = contentMap[feedback.ID.String()]
 	}
 
-	// Update content in MongoDB if provided
-	if feedback.Content != "" {
-		if err := r.SetContent(ctx, feedback.ID, feedback.Content); err != nil {
-			return fmt.Errorf("failed to update feedback content: %w", err)
+	return feedbacks, nil
+}
+
+// Update updates an existing feedback on behalf of editorID. The edit is recorded as the next
+// revision in the same transaction, and the first edit also records the text as created as
+// revision 0. The rubric items are replaced as a whole in the same transaction. Content is only
+// written with contentSet, through the content outbox as in Create, so an edit of other fields
+// never replaces content another edit has queued.
+func (r *feedbackRepository) Update(ctx context.Context, feedback *models.Feedback, editorID int64, contentSet bool) error {
+	feedback.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Revisions are only recorded once revision_count is written, so counts and rows agree
+	recordRevisions := r.schema.writable("revision_count")
+	if recordRevisions {
+		if err := r.recordOriginalRevision(ctx, tx, feedback.ID); err != nil {
+			return err
+		}
+	}
+
+	// Update metadata in PostgreSQL
+	points, maxPoints := encodeGrade(feedback.Grade)
+	set := "title = $2, points = $3, max_points = $4, updated_at = $5"
+	args := []interface{}{feedback.ID, feedback.Title, points, maxPoints, feedback.UpdatedAt}
+	returning := r.schema.readExpr("revision_count")
+	if recordRevisions {
+		set += ", revision_count = revision_count + 1"
+		returning = "revision_count"
+	}
+	if r.resubmissionWritable() {
+		set += ", requires_resubmission = $6, resubmission_deadline = $7"
+		args = append(args, feedback.RequiresResubmission, feedback.ResubmissionDeadline)
+	} else if feedback.RequiresResubmission {
+		return columnUnavailable("requires_resubmission")
+	}
+	query := fmt.Sprintf(`
+		UPDATE feedbacks
+		SET %s
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING %s`, set, returning)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&feedback.RevisionCount)
+	if err == sql.ErrNoRows {
+		return ErrFeedbackNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update feedback metadata: %w", err)
+	}
+
+	// The feedback carries its full rubric, which replaces the stored one
+	if err := replaceRubricItems(ctx, tx, feedback.ID, feedback.RubricItems); err != nil {
+		return err
+	}
+
+	var version int64
+	if contentSet {
+		if version, err = enqueueContent(ctx, tx, feedback.ID, feedback.Content); err != nil {
+			return err
 		}
 	}
 
+	if recordRevisions {
+		revision := &models.FeedbackRevision{
+			FeedbackID: feedback.ID,
+			Revision:   feedback.RevisionCount,
+			Title:      feedback.Title,
+			Content:    feedback.Content,
+			EditedAt:   feedback.UpdatedAt,
+			EditedBy:   editorID,
+		}
+		if err := insertRevision(ctx, tx, revision); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feedback update: %w", err)
+	}
+
+	// Content that was set is written in full, so an empty one clears it
+	if contentSet {
+		r.applyContent(ctx, feedback.ID, feedback.Content, version)
+	}
+
 	return nil
 }
 
-// Delete deletes a feedback and its content
+// resubmissionWritable reports whether the resubmission columns may be written; they are
+// written together, so a feedback never has a deadline without the flag
+func (r *feedbackRepository) resubmissionWritable() bool {
+	return r.schema.writable("requires_resubmission") && r.schema.writable("resubmission_deadline")
+}
+
+// recordOriginalRevision locks a feedback row for an edit and, before its first edit, records
+// its stored title and content as revision 0
+func (r *feedbackRepository) recordOriginalRevision(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	original := &models.FeedbackRevision{FeedbackID: id}
+	var revisionCount int32
+	err := tx.QueryRowContext(ctx, `
+		SELECT title, revision_count, created_at, reviewer_id
+		FROM feedbacks
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, id).Scan(&original.Title, &revisionCount, &original.EditedAt, &original.EditedBy)
+	if err == sql.ErrNoRows {
+		return ErrFeedbackNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock feedback: %w", err)
+	}
+	if revisionCount > 0 {
+		return nil
+	}
+
+	// Before the first edit, the stored or pending content is still the original one
+	if original.Content, err = r.GetContent(ctx, id); err != nil {
+		return fmt.Errorf("failed to get original feedback content: %w", err)
+	}
+	return insertRevision(ctx, tx, original)
+}
+
+// insertRevision stores a feedback revision
+func insertRevision(ctx context.Context, tx *sql.Tx, revision *models.FeedbackRevision) error {
+	query := `
+		INSERT INTO feedback_revisions (feedback_id, revision, title, content, edited_at, edited_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := tx.ExecContext(ctx, query, revision.FeedbackID, revision.Revision, revision.Title, revision.Content, revision.EditedAt, revision.EditedBy)
+	if err != nil {
+		return fmt.Errorf("failed to record feedback revision %d: %w", revision.Revision, err)
+	}
+	return nil
+}
+
+// ListRevisions lists the revisions of a feedback, newest first, with the total number of
+// revisions
+func (r *feedbackRepository) ListRevisions(ctx context.Context, feedbackID uuid.UUID, page, limit int32) ([]*models.FeedbackRevision, int32, error) {
+	if !r.schema.has("revision_count") {
+		return []*models.FeedbackRevision{}, 0, nil
+	}
+
+	var totalCount int32
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feedback_revisions WHERE feedback_id = $1`, feedbackID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count feedback revisions: %w", err)
+	}
+	if totalCount == 0 {
+		return []*models.FeedbackRevision{}, 0, nil
+	}
+
+	query := `
+		SELECT feedback_id, revision, title, content, edited_at, edited_by
+		FROM feedback_revisions
+		WHERE feedback_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, feedbackID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list feedback revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.FeedbackRevision, 0, limit)
+	for rows.Next() {
+		revision := &models.FeedbackRevision{}
+		if err := rows.Scan(&revision.FeedbackID, &revision.Revision, &revision.Title, &revision.Content, &revision.EditedAt, &revision.EditedBy); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan feedback revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating feedback revision rows: %w", err)
+	}
+	return revisions, totalCount, nil
+}
+
+// Delete deletes a feedback row; its content and other data are removed separately, see
+// service.FeedbackService.deleteFeedbackData
 func (r *feedbackRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	// Delete from PostgreSQL
 	query := `DELETE FROM feedbacks WHERE id = $1`
@@ -151,19 +466,407 @@ func (r *feedbackRepository) Delete(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("feedback not found")
+		return ErrFeedbackNotFound
 	}
 
-	// Delete content from MongoDB (ignore if doesn't exist)
-	r.DeleteContent(ctx, id)
+	return nil
+}
+
+// SoftDelete marks a feedback as deleted without removing its data
+func (r *feedbackRepository) SoftDelete(ctx context.Context, id uuid.UUID, actorID int64) error {
+	query := `
+		UPDATE feedbacks
+		SET deleted_at = $2, deleted_by = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id, time.Now(), actorID)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete feedback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrFeedbackNotFound
+	}
 
 	return nil
 }
 
+// SetLock sets the lock state of a feedback. It reports whether the state changed,
+// so repeating the same call is a no-op.
+func (r *feedbackRepository) SetLock(ctx context.Context, id uuid.UUID, locked bool, reason string) (bool, error) {
+	query := `
+		UPDATE feedbacks
+		SET locked = $2, lock_reason = $3
+		WHERE id = $1 AND deleted_at IS NULL AND (locked <> $2 OR lock_reason <> $3)
+	`
+	result, err := r.db.ExecContext(ctx, query, id, locked, reason)
+	if err != nil {
+		return false, fmt.Errorf("failed to set feedback lock: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// TransferOwnership moves a feedback from one reviewer to another. It reports whether the
+// feedback still belonged to fromReviewerID, so a concurrent transfer is not overwritten, and
+// returns a DuplicateFeedbackError if the new reviewer already has feedback on the submission.
+func (r *feedbackRepository) TransferOwnership(ctx context.Context, id uuid.UUID, fromReviewerID, toReviewerID int64, updatedAt time.Time) (bool, error) {
+	query := `
+		UPDATE feedbacks
+		SET reviewer_id = $3, updated_at = $4
+		WHERE id = $1 AND reviewer_id = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id, fromReviewerID, toReviewerID, updatedAt)
+	if isUniqueViolation(err, "idx_feedbacks_reviewer_submission") {
+		return false, &DuplicateFeedbackError{ExistingID: r.activeFeedbackIDForFeedback(ctx, id, toReviewerID)}
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to transfer feedback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// activeFeedbackIDForFeedback returns the ID of a reviewer's feedback for the submission of
+// another feedback, or uuid.Nil if there is none or it cannot be read
+func (r *feedbackRepository) activeFeedbackIDForFeedback(ctx context.Context, id uuid.UUID, reviewerID int64) uuid.UUID {
+	var submissionID int64
+	if err := r.db.QueryRowContext(ctx, `SELECT submission_id FROM feedbacks WHERE id = $1`, id).Scan(&submissionID); err != nil {
+		return uuid.Nil
+	}
+	return r.activeFeedbackID(ctx, reviewerID, submissionID)
+}
+
+// Publish marks a draft feedback as published at the given time. It reports whether the status
+// changed, so publishing a published feedback again is a no-op.
+func (r *feedbackRepository) Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) (bool, error) {
+	query := `
+		UPDATE feedbacks
+		SET status = $2, published_at = $3
+		WHERE id = $1 AND deleted_at IS NULL AND status <> $2
+	`
+	result, err := r.db.ExecContext(ctx, query, id, models.FeedbackPublished, publishedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to publish feedback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Acknowledge records when the student read a feedback. It reports whether the feedback was
+// still unread, so the first acknowledgement's time is kept.
+func (r *feedbackRepository) Acknowledge(ctx context.Context, id uuid.UUID, readAt time.Time) (bool, error) {
+	if !r.schema.writable("read_at") {
+		return false, columnUnavailable("read_at")
+	}
+	query := `
+		UPDATE feedbacks
+		SET read_at = $2
+		WHERE id = $1 AND deleted_at IS NULL AND read_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id, readAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to acknowledge feedback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// TransitionApproval moves a feedback from one approval status to another, recording the
+// approver and the note of the decision. It reports whether the feedback was still in the
+// expected status, so concurrent transitions cannot both succeed.
+func (r *feedbackRepository) TransitionApproval(ctx context.Context, id uuid.UUID, from, to string, approverID int64, note string) (bool, error) {
+	query := `
+		UPDATE feedbacks
+		SET approval_status = $3, approver_id = $4, approval_note = $5
+		WHERE id = $1 AND deleted_at IS NULL AND approval_status = $2
+	`
+	result, err := r.db.ExecContext(ctx, query, id, from, to, approverID, note)
+	if err != nil {
+		return false, fmt.Errorf("failed to update feedback approval: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// IsLocked reports whether a feedback is locked, including soft-deleted feedbacks
+func (r *feedbackRepository) IsLocked(ctx context.Context, id uuid.UUID) (bool, error) {
+	var locked bool
+	err := r.db.QueryRowContext(ctx, `SELECT locked FROM feedbacks WHERE id = $1`, id).Scan(&locked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrFeedbackNotFound.Wrap(err)
+		}
+		return false, fmt.Errorf("failed to get feedback lock: %w", err)
+	}
+	return locked, nil
+}
+
+// SetNeedsReupload flags or clears a feedback whose attachments must be uploaded again
+func (r *feedbackRepository) SetNeedsReupload(ctx context.Context, id uuid.UUID, needsReupload bool) error {
+	query := `UPDATE feedbacks SET needs_reupload = $2 WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id, needsReupload)
+	if err != nil {
+		return fmt.Errorf("failed to set needs_reupload: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrFeedbackNotFound
+	}
+
+	return nil
+}
+
+// UpdateSnapshots merges each update into the snapshot of every live feedback of its
+// submission in a single transaction; empty fields keep their stored value. The feedbacks'
+// updated_at is left alone since snapshots are display data, not reviewer edits.
+func (r *feedbackRepository) UpdateSnapshots(ctx context.Context, updates []models.SubmissionSnapshotUpdate) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE feedbacks
+		SET submission_snapshot = COALESCE(submission_snapshot, '{}'::jsonb) || $2::jsonb
+		WHERE submission_id = $1 AND deleted_at IS NULL
+	`
+	var updated int64
+	for _, update := range updates {
+		snapshot, err := json.Marshal(update.Snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode submission snapshot: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, query, update.SubmissionID, snapshot)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update snapshot of submission %d: %w", update.SubmissionID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		updated += rowsAffected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit snapshot updates: %w", err)
+	}
+
+	return updated, nil
+}
+
+// encodeSnapshot converts a snapshot to its JSONB column value; an empty snapshot is stored as NULL
+func encodeSnapshot(snapshot *models.SubmissionSnapshot) (interface{}, error) {
+	if snapshot.IsEmpty() {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode submission snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// decodeSnapshot converts a JSONB column value to a snapshot, returning nil for NULL
+func decodeSnapshot(data []byte) (*models.SubmissionSnapshot, error) {
+	if data == nil {
+		return nil, nil
+	}
+	snapshot := &models.SubmissionSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode submission snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// encodeGrade converts a grade to its column values; no grade is stored as NULL
+func encodeGrade(grade *models.Grade) (sql.NullFloat64, sql.NullFloat64) {
+	if grade == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: grade.Points, Valid: true}, sql.NullFloat64{Float64: grade.MaxPoints, Valid: true}
+}
+
+// decodeTime converts a nullable timestamp column value, returning nil for NULL
+func decodeTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+// decodeInt64 converts a nullable integer column value, returning nil for NULL
+func decodeInt64(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+// decodeGrade converts grade column values to a grade, returning nil for NULL
+func decodeGrade(points, maxPoints sql.NullFloat64) *models.Grade {
+	if !points.Valid || !maxPoints.Valid {
+		return nil
+	}
+	return &models.Grade{Points: points.Float64, MaxPoints: maxPoints.Float64}
+}
+
+// ListRefsAfter returns up to limit feedback references ordered by ID, starting after the
+// given ID. Soft-deleted feedbacks are included and flagged.
+func (r *feedbackRepository) ListRefsAfter(ctx context.Context, after uuid.UUID, limit int) ([]models.FeedbackRef, error) {
+	query := `
+		SELECT id, deleted_at IS NOT NULL
+		FROM feedbacks
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback references: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []models.FeedbackRef
+	for rows.Next() {
+		var ref models.FeedbackRef
+		if err := rows.Scan(&ref.ID, &ref.Deleted); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback rows: %w", err)
+	}
+
+	return refs, nil
+}
+
+// ListIDsBySubmission returns up to limit feedback IDs of a submission ordered by ID,
+// starting after the given ID. Soft-deleted feedbacks are only included when includeDeleted is set.
+func (r *feedbackRepository) ListIDsBySubmission(ctx context.Context, submissionID int64, after uuid.UUID, limit int, includeDeleted bool) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM feedbacks
+		WHERE submission_id = $1 AND id > $2 AND ($4 OR deleted_at IS NULL)
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, submissionID, after, limit, includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list submission feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListTitleCandidates lists a reviewer's feedbacks that may have a title similar to a new one:
+// feedbacks on the same submission or created since the given time, with a title length in
+// [minLength, maxLength] characters, newest first
+func (r *feedbackRepository) ListTitleCandidates(ctx context.Context, reviewerID, submissionID int64, since time.Time, minLength, maxLength, limit int) ([]*models.SimilarFeedback, error) {
+	query := `
+		SELECT id, student_id, submission_id, title, created_at
+		FROM feedbacks
+		WHERE reviewer_id = $1 AND deleted_at IS NULL
+			AND (submission_id = $2 OR created_at >= $3)
+			AND char_length(title) BETWEEN $4 AND $5
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6
+	`
+	rows, err := r.db.QueryContext(ctx, query, reviewerID, submissionID, since, minLength, maxLength, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list title candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*models.SimilarFeedback
+	for rows.Next() {
+		candidate := &models.SimilarFeedback{}
+		if err := rows.Scan(&candidate.ID, &candidate.StudentID, &candidate.SubmissionID, &candidate.Title, &candidate.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan title candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating title candidates: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// cursorTimeLayout formats a cursor's time for comparison with a TIMESTAMP column
+const cursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+// feedbackSortColumns maps list sort fields onto their columns. ORDER BY and keyset clauses are
+// only built from this whitelist.
+var feedbackSortColumns = map[string]string{
+	models.FeedbackSortCreatedAt: "created_at",
+	models.FeedbackSortUpdatedAt: "updated_at",
+	models.FeedbackSortTitle:     "title",
+}
+
+// sortCursorValue returns the value of a cursor for a sort field, with the cast of its placeholder.
+// Time columns are TIMESTAMP, so times are compared by their wall clock.
+func sortCursorValue(cursor *models.FeedbackCursor, field string) (interface{}, string) {
+	switch field {
+	case models.FeedbackSortUpdatedAt:
+		return cursor.UpdatedAt.Format(cursorTimeLayout), "::timestamp"
+	case models.FeedbackSortTitle:
+		return cursor.Title, ""
+	default:
+		return cursor.CreatedAt.Format(cursorTimeLayout), "::timestamp"
+	}
+}
+
 // listFeedbacks is a helper function to list feedbacks based on a filter
-func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, countQuery string, args []interface{}, filter models.FeedbackFilter) ([]*models.Feedback, int32, error) {
+func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, countQuery string, args []interface{}, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
 	// Get total count
-	var totalCount int32
+	var totalCount int64
 	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count feedbacks: %w", err)
@@ -173,8 +876,28 @@ func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, count
 		return []*models.Feedback{}, 0, nil
 	}
 
-	// Add pagination
-	paginatedQuery := fmt.Sprintf("%s ORDER BY created_at DESC LIMIT %d OFFSET %d", baseQuery, filter.Limit, (filter.Page-1)*filter.Limit)
+	// Order by the sort column, ties broken by ID in the same direction
+	field := filter.Sort.Field()
+	column, ok := feedbackSortColumns[field]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported sort field %q", field)
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Sort.Ascending {
+		direction, comparison = "ASC", ">"
+	}
+	orderBy := fmt.Sprintf("ORDER BY %s %s, id %s", column, direction, direction)
+
+	// Add pagination: keyset after a cursor, otherwise by page offset
+	var paginatedQuery string
+	if filter.After != nil {
+		value, cast := sortCursorValue(filter.After, field)
+		args = append(args[:len(args):len(args)], value, filter.After.ID)
+		paginatedQuery = fmt.Sprintf("%s AND (%s, id) %s ($%d%s, $%d) %s LIMIT %d",
+			baseQuery, column, comparison, len(args)-1, cast, len(args), orderBy, filter.Limit)
+	} else {
+		paginatedQuery = fmt.Sprintf("%s %s LIMIT %d OFFSET %d", baseQuery, orderBy, filter.Limit, (filter.Page-1)*filter.Limit)
+	}
 
 	rows, err := r.db.QueryContext(ctx, paginatedQuery, args...)
 	if err != nil {
@@ -186,13 +909,26 @@ func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, count
 	var feedbackIDs []string
 	for rows.Next() {
 		feedback := &models.Feedback{}
+		var snapshot []byte
+		var points, maxPoints sql.NullFloat64
+		var publishedAt, readAt, resubmissionDeadline, archivedAt sql.NullTime
+		var approverID sql.NullInt64
 		err := rows.Scan(
 			&feedback.ID, &feedback.ReviewerID, &feedback.StudentID, &feedback.SubmissionID,
-			&feedback.Title, &feedback.CreatedAt, &feedback.UpdatedAt,
+			&feedback.Title, &feedback.Locked, &feedback.LockReason, &feedback.NeedsReupload, &snapshot, &feedback.CourseID, &points, &maxPoints, &feedback.Status, &publishedAt, &feedback.ApprovalStatus, &approverID, &feedback.ApprovalNote, &readAt, &feedback.RevisionCount, &feedback.RequiresResubmission, &resubmissionDeadline, &archivedAt, &feedback.CreatedAt, &feedback.UpdatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan feedback: %w", err)
 		}
+		if feedback.Snapshot, err = decodeSnapshot(snapshot); err != nil {
+			return nil, 0, err
+		}
+		feedback.Grade = decodeGrade(points, maxPoints)
+		feedback.PublishedAt = decodeTime(publishedAt)
+		feedback.ReadAt = decodeTime(readAt)
+		feedback.ResubmissionDeadline = decodeTime(resubmissionDeadline)
+		feedback.ArchivedAt = decodeTime(archivedAt)
+		feedback.ApproverID = decodeInt64(approverID)
 		feedbacks = append(feedbacks, feedback)
 		feedbackIDs = append(feedbackIDs, feedback.ID.String())
 	}
@@ -200,9 +936,15 @@ func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, count
 	if err := rows.Err(); err != nil {
 		return nil, 0, fmt.Errorf("error iterating feedback rows: %w", err)
 	}
+	if err := r.loadRubricItems(ctx, feedbacks); err != nil {
+		return nil, 0, err
+	}
+	if err := r.loadReactionCounts(ctx, feedbacks); err != nil {
+		return nil, 0, err
+	}
 
 	// Get content from MongoDB in a single query
-	contentMap, err := r.getContents(ctx, feedbackIDs)
+	contentMap, err := r.GetContents(ctx, feedbackIDs)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get feedback contents: %w", err)
 	}
@@ -217,50 +959,230 @@ func (r *feedbackRepository) listFeedbacks(ctx context.Context, baseQuery, count
 	return feedbacks, totalCount, nil
 }
 
+// applyScopeClauses narrows a list of every feedback to the reviewer and student of a filter,
+// when set
+func applyScopeClauses(baseQuery, countQuery string, args []interface{}, filter models.FeedbackFilter) (string, string, []interface{}) {
+	if filter.ReviewerID != nil {
+		args = append(args, *filter.ReviewerID)
+		clause := fmt.Sprintf(" AND reviewer_id = $%d", len(args))
+		baseQuery += clause
+		countQuery += clause
+	}
+	if filter.StudentID != nil {
+		args = append(args, *filter.StudentID)
+		clause := fmt.Sprintf(" AND student_id = $%d", len(args))
+		baseQuery += clause
+		countQuery += clause
+	}
+	return baseQuery, countQuery, args
+}
+
+// applyFilterClauses appends the optional filter predicates shared by the list queries
+func (r *feedbackRepository) applyFilterClauses(baseQuery, countQuery string, args []interface{}, filter models.FeedbackFilter) (string, string, []interface{}) {
+	var clauses []string
+
+	if filter.SubmissionID != nil {
+		args = append(args, *filter.SubmissionID)
+		clauses = append(clauses, fmt.Sprintf("submission_id = $%d", len(args)))
+	}
+
+	if filter.CourseID != nil {
+		args = append(args, *filter.CourseID)
+		clauses = append(clauses, fmt.Sprintf("course_id = $%d", len(args)))
+	}
+
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		clauses = append(clauses, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if filter.ApprovedOnly {
+		args = append(args, pq.Array([]string{models.ApprovalNotRequired, models.ApprovalApproved}))
+		clauses = append(clauses, fmt.Sprintf("approval_status = ANY($%d)", len(args)))
+	}
+
+	if filter.UnreadOnly {
+		clauses = append(clauses, r.schema.readExpr("read_at")+" IS NULL")
+	}
+
+	if filter.ResubmissionOnly {
+		clauses = append(clauses, r.schema.readExpr("requires_resubmission"))
+	}
+
+	if !filter.IncludeArchived {
+		clauses = append(clauses, r.schema.readExpr("archived_at")+" IS NULL")
+	}
+
+	// Approval does not touch the row's timestamps, so approvals are found in the audit log
+	if filter.ChangedSince != nil {
+		args = append(args, filter.ChangedSince.UTC(), models.AuditActionApproved)
+		clauses = append(clauses, fmt.Sprintf(`(updated_at >= $%[1]d OR published_at >= $%[1]d OR EXISTS (
+			SELECT 1 FROM feedback_audit_log l WHERE l.feedback_id = feedbacks.id AND l.action = $%[2]d AND l.created_at >= $%[1]d
+		))`, len(args)-1, len(args)))
+	}
+
+	// Creation range [CreatedAfter, CreatedBefore), applied to the count as well
+	if filter.CreatedAfter != nil {
+		args = append(args, filter.CreatedAfter.UTC())
+		clauses = append(clauses, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, filter.CreatedBefore.UTC())
+		clauses = append(clauses, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	// Attachment filters are answered from the feedback_assets metadata table, except for the
+	// feedbacks whose attachments were counted in MinIO because their metadata is not backfilled
+	if len(filter.AttachmentCounts) > 0 && (filter.HasAttachments != nil || filter.MinAttachments != nil) {
+		ids := make([]string, 0, len(filter.AttachmentCounts))
+		counts := make([]int64, 0, len(filter.AttachmentCounts))
+		for _, id := range slices.SortedFunc(maps.Keys(filter.AttachmentCounts), func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) }) {
+			ids = append(ids, id.String())
+			counts = append(counts, filter.AttachmentCounts[id])
+		}
+		args = append(args, pq.Array(ids), pq.Array(counts))
+		count := fmt.Sprintf(`COALESCE(
+			(SELECT o.n FROM unnest($%d::uuid[], $%d::bigint[]) AS o(id, n) WHERE o.id = feedbacks.id),
+			(SELECT COUNT(*) FROM feedback_assets a WHERE a.feedback_id = feedbacks.id)
+		)`, len(args)-1, len(args))
+		if filter.HasAttachments != nil {
+			if *filter.HasAttachments {
+				clauses = append(clauses, count+" > 0")
+			} else {
+				clauses = append(clauses, count+" = 0")
+			}
+		}
+		if filter.MinAttachments != nil {
+			args = append(args, *filter.MinAttachments)
+			clauses = append(clauses, fmt.Sprintf("%s >= $%d", count, len(args)))
+		}
+	} else {
+		if filter.HasAttachments != nil {
+			clause := "EXISTS (SELECT 1 FROM feedback_assets a WHERE a.feedback_id = feedbacks.id)"
+			if !*filter.HasAttachments {
+				clause = "NOT " + clause
+			}
+			clauses = append(clauses, clause)
+		}
+		if filter.MinAttachments != nil {
+			args = append(args, *filter.MinAttachments)
+			clauses = append(clauses, fmt.Sprintf("(SELECT COUNT(*) FROM feedback_assets a WHERE a.feedback_id = feedbacks.id) >= $%d", len(args)))
+		}
+	}
+
+	for _, clause := range clauses {
+		baseQuery += " AND " + clause
+		countQuery += " AND " + clause
+	}
+
+	return baseQuery, countQuery, args
+}
 
 // ListByUser lists feedbacks created by a specific user
-func (r *feedbackRepository) ListByUser(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int32, error) {
+func (r *feedbackRepository) ListByUser(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
 	baseQuery := `
-		SELECT id, reviewer_id, student_id, submission_id, title, created_at, updated_at
+		SELECT ` + r.schema.selectColumns() + `
 		FROM feedbacks
-		WHERE reviewer_id = $1
+		WHERE reviewer_id = $1 AND deleted_at IS NULL
 	`
-	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE reviewer_id = $1`
+	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE reviewer_id = $1 AND deleted_at IS NULL`
 
 	args := []interface{}{filter.ReviewerID}
 
-	// Add submission filter if specified
-	if filter.SubmissionID != nil {
-		baseQuery += " AND submission_id = $2"
-		countQuery += " AND submission_id = $2"
-		args = append(args, *filter.SubmissionID)
-	}
+	baseQuery, countQuery, args = r.applyFilterClauses(baseQuery, countQuery, args, filter)
+
+	return r.listFeedbacks(ctx, baseQuery, countQuery, args, filter)
+}
+
+// ListBySubmission lists the feedbacks of every reviewer for the submission in
+// filter.SubmissionID
+func (r *feedbackRepository) ListBySubmission(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	baseQuery := `
+		SELECT ` + r.schema.selectColumns() + `
+		FROM feedbacks
+		WHERE submission_id = $1 AND deleted_at IS NULL
+	`
+	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE submission_id = $1 AND deleted_at IS NULL`
+
+	args := []interface{}{*filter.SubmissionID}
+
+	// The submission keys the list; it is not applied again as a filter
+	filter.SubmissionID = nil
+	baseQuery, countQuery, args = r.applyFilterClauses(baseQuery, countQuery, args, filter)
 
 	return r.listFeedbacks(ctx, baseQuery, countQuery, args, filter)
 }
 
+// List lists the feedbacks of every reviewer and student, narrowed to filter.ReviewerID and
+// filter.StudentID when set
+func (r *feedbackRepository) List(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	baseQuery := `
+		SELECT ` + r.schema.selectColumns() + `
+		FROM feedbacks
+		WHERE deleted_at IS NULL
+	`
+	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE deleted_at IS NULL`
+
+	var args []interface{}
+	baseQuery, countQuery, args = applyScopeClauses(baseQuery, countQuery, args, filter)
+	baseQuery, countQuery, args = r.applyFilterClauses(baseQuery, countQuery, args, filter)
+
+	return r.listFeedbacks(ctx, baseQuery, countQuery, args, filter)
+}
+
+// ListAttachmentPending returns the IDs of up to limit feedbacks matching a list filter, apart
+// from its attachment filters, whose attachment prefix the metadata backfill has not finished
+func (r *feedbackRepository) ListAttachmentPending(ctx context.Context, filter models.FeedbackFilter, limit int) ([]uuid.UUID, error) {
+	filter.HasAttachments, filter.MinAttachments, filter.AttachmentCounts = nil, nil, nil
+
+	query := `SELECT id FROM feedbacks WHERE deleted_at IS NULL`
+	var args []interface{}
+	query, _, args = applyScopeClauses(query, "", args, filter)
+	query, _, args = r.applyFilterClauses(query, "", args, filter)
+	args = append(args, finishedStatuses, limit)
+	query += fmt.Sprintf(` AND NOT EXISTS (
+			SELECT 1 FROM attachment_backfill_journal j WHERE j.prefix = feedbacks.id::text AND j.status = ANY($%d)
+		)
+		ORDER BY id
+		LIMIT $%d`, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedbacks pending attachment backfill: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback IDs: %w", err)
+	}
+	return ids, nil
+}
+
 // ListByStudent lists feedbacks for a specific student
-func (r *feedbackRepository) ListByStudent(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int32, error) {
+func (r *feedbackRepository) ListByStudent(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
 	baseQuery := `
-		SELECT id, reviewer_id, student_id, submission_id, title, created_at, updated_at
+		SELECT ` + r.schema.selectColumns() + `
 		FROM feedbacks
-		WHERE student_id = $1
+		WHERE student_id = $1 AND deleted_at IS NULL
 	`
-	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE student_id = $1`
+	countQuery := `SELECT COUNT(*) FROM feedbacks WHERE student_id = $1 AND deleted_at IS NULL`
 
 	args := []interface{}{filter.StudentID}
 
-	// Add submission filter if specified
-	if filter.SubmissionID != nil {
-		baseQuery += " AND submission_id = $2"
-		countQuery += " AND submission_id = $2"
-		args = append(args, *filter.SubmissionID)
-	}
+	baseQuery, countQuery, args = r.applyFilterClauses(baseQuery, countQuery, args, filter)
 
 	return r.listFeedbacks(ctx, baseQuery, countQuery, args, filter)
 }
 
-// SetContent stores feedback content in MongoDB
+// SetContent stores feedback content in MongoDB and indexes it for search
 func (r *feedbackRepository) SetContent(ctx context.Context, id uuid.UUID, content string) error {
 	feedbackContent := models.FeedbackContent{
 		ID:      id.String(),
@@ -281,11 +1203,17 @@ func (r *feedbackRepository) SetContent(ctx context.Context, id uuid.UUID, conte
 		return fmt.Errorf("failed to store feedback content: %w", err)
 	}
 
-	return nil
+	// Keep the full-text search index in PostgreSQL in step with the stored content
+	return r.IndexContent(ctx, id, content)
 }
 
-// GetContent retrieves feedback content from MongoDB
+// GetContent retrieves feedback content from MongoDB. Content still pending in the outbox is
+// newer than the stored document, so it is returned instead.
 func (r *feedbackRepository) GetContent(ctx context.Context, id uuid.UUID) (string, error) {
+	if content, ok, err := pendingContent(ctx, r.db, id); err != nil || ok {
+		return content, err
+	}
+
 	collection := r.mongodb.Database.Collection("feedback_content")
 	
 	var feedbackContent models.FeedbackContent
@@ -300,20 +1228,26 @@ func (r *feedbackRepository) GetContent(ctx context.Context, id uuid.UUID) (stri
 	return feedbackContent.Content, nil
 }
 
-// DeleteContent removes feedback content from MongoDB
-func (r *feedbackRepository) DeleteContent(ctx context.Context, id uuid.UUID) error {
+// DeleteContent removes feedback content from MongoDB and returns how many documents were removed
+func (r *feedbackRepository) DeleteContent(ctx context.Context, id uuid.UUID) (int64, error) {
 	collection := r.mongodb.Database.Collection("feedback_content")
 	
-	_, err := collection.DeleteOne(ctx, bson.M{"_id": id.String()})
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": id.String()})
 	if err != nil {
-		return fmt.Errorf("failed to delete feedback content: %w", err)
+		return 0, fmt.Errorf("failed to delete feedback content: %w", err)
 	}
 
-	return nil
+	return result.DeletedCount, nil
 }
 
-// getContents retrieves multiple feedback contents from MongoDB
-func (r *feedbackRepository) getContents(ctx context.Context, ids []string) (map[string]string, error) {
+// GetContents retrieves multiple feedback contents from MongoDB, keyed by feedback ID. As with
+// GetContent, content pending in the outbox takes precedence over the stored documents.
+func (r *feedbackRepository) GetContents(ctx context.Context, ids []string) (map[string]string, error) {
+	pending, err := pendingContents(ctx, r.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
 	collection := r.mongodb.Database.Collection("feedback_content")
 
 	filter := bson.M{"_id": bson.M{"$in": ids}}
@@ -336,5 +1270,86 @@ func (r *feedbackRepository) getContents(ctx context.Context, ids []string) (map
 		return nil, fmt.Errorf("cursor error on feedback contents: %w", err)
 	}
 
+	for id, content := range pending {
+		contentMap[id] = content
+	}
 	return contentMap, nil
 }
+
+// CountCreatedByBucket counts feedbacks created in [since, until) grouped by date_trunc bucket,
+// optionally restricted to a reviewer or submission. Soft-deleted feedbacks are counted too,
+// since their creation still happened. Only non-empty buckets are returned.
+func (r *feedbackRepository) CountCreatedByBucket(ctx context.Context, reviewerID, submissionID *int64, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	query := `
+		SELECT date_trunc($1, created_at) AS bucket, COUNT(*)
+		FROM feedbacks
+		WHERE created_at >= $2 AND created_at < $3
+	`
+	args := []interface{}{bucketSize, since.UTC(), until.UTC()}
+	if reviewerID != nil {
+		args = append(args, *reviewerID)
+		query += fmt.Sprintf(" AND reviewer_id = $%d", len(args))
+	}
+	if submissionID != nil {
+		args = append(args, *submissionID)
+		query += fmt.Sprintf(" AND submission_id = $%d", len(args))
+	}
+	query += " GROUP BY bucket ORDER BY bucket"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count feedbacks by bucket: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*models.RateBucket
+	for rows.Next() {
+		bucket := &models.RateBucket{}
+		if err := rows.Scan(&bucket.Start, &bucket.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback bucket: %w", err)
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback buckets: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// CountBySubmissionReviewer counts the feedbacks of the given submissions grouped by submission
+// and reviewer, in one query, with the published ones and the drafts apart. Soft-deleted
+// feedbacks are not counted; submissions without feedback have no rows.
+func (r *feedbackRepository) CountBySubmissionReviewer(ctx context.Context, submissionIDs []int64) ([]models.ReviewerFeedbackCount, error) {
+	query := `
+		SELECT submission_id, reviewer_id, COUNT(*),
+			COUNT(*) FILTER (WHERE status = $2),
+			COUNT(*) FILTER (WHERE status = $3)
+		FROM feedbacks
+		WHERE submission_id = ANY($1::bigint[]) AND deleted_at IS NULL
+		GROUP BY submission_id, reviewer_id
+		ORDER BY submission_id, reviewer_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(submissionIDs), models.FeedbackPublished, models.FeedbackDraft)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count feedbacks by submission: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []models.ReviewerFeedbackCount
+	for rows.Next() {
+		var count models.ReviewerFeedbackCount
+		if err := rows.Scan(&count.SubmissionID, &count.ReviewerID, &count.Count, &count.PublishedCount, &count.DraftCount); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestCountBySubmissionReviewerQuery(t *testing.T) {
+	db, fake := openFakeDB(func(string, []interface{}) fakeResult {
+		return fakeResult{
+			columns: []string{"submission_id", "reviewer_id", "count", "published", "drafts"},
+			rows:    [][]driver.Value{{int64(301), int64(101), int64(3), int64(2), int64(1)}},
+		}
+	})
+	defer db.Close()
+	r := &feedbackRepository{db: db}
+
+	counts, err := r.CountBySubmissionReviewer(context.Background(), []int64{301, 302})
+	if err != nil {
+		t.Fatalf("CountBySubmissionReviewer() error = %v", err)
+	}
+	want := models.ReviewerFeedbackCount{SubmissionID: 301, ReviewerID: 101, Count: 3, PublishedCount: 2, DraftCount: 1}
+	if len(counts) != 1 || counts[0] != want {
+		t.Errorf("CountBySubmissionReviewer() = %+v, want [%+v]", counts, want)
+	}
+
+	queries := fake.recorded()
+	query, args := queries[len(queries)-1].query, queries[len(queries)-1].args
+	checkPlaceholders(t, query, args)
+	for _, want := range []string{
+		"COUNT(*) FILTER (WHERE status = $2)",
+		"COUNT(*) FILTER (WHERE status = $3)",
+		"submission_id = ANY($1::bigint[])",
+		"deleted_at IS NULL",
+		"GROUP BY submission_id, reviewer_id",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query = %s, want it to contain %q", query, want)
+		}
+	}
+	// The published count comes before the draft count, matching the scan order
+	if strings.Index(query, "status = $2") > strings.Index(query, "status = $3") {
+		t.Errorf("query = %s, want the $2 (published) count first", query)
+	}
+	if got := arrayValue(t, args[0]); got != "{301,302}" {
+		t.Errorf("submission IDs argument = %s, want {301,302}", got)
+	}
+	if args[1] != models.FeedbackPublished || args[2] != models.FeedbackDraft {
+		t.Errorf("status arguments = %v, %v, want %s, %s", args[1], args[2], models.FeedbackPublished, models.FeedbackDraft)
+	}
+}
+
+// TestCountBySubmissionReviewerPostgres counts seeded drafts, published and deleted feedbacks of
+// several reviewers
+func TestCountBySubmissionReviewerPostgres(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	r := &feedbackRepository{db: db}
+
+	seed := func(submissionID, reviewerID int64, status string, deleted bool) {
+		t.Helper()
+		publishedAt := "NOW()"
+		if status == models.FeedbackDraft {
+			publishedAt = "NULL"
+		}
+		deletedAt := "NULL"
+		if deleted {
+			deletedAt = "NOW()"
+		}
+		mustExec(t, db, `INSERT INTO feedbacks (id, reviewer_id, student_id, submission_id, title, status, published_at, deleted_at)
+			VALUES ($1, $2, 201, $3, 'feedback', $4, `+publishedAt+`, `+deletedAt+`)`, uuid.New(), reviewerID, submissionID, status)
+	}
+	seed(301, 101, models.FeedbackPublished, false)
+	seed(301, 101, models.FeedbackPublished, false)
+	seed(301, 101, models.FeedbackDraft, false)
+	seed(301, 101, models.FeedbackDraft, true) // Deleted feedbacks are not counted
+	seed(301, 102, models.FeedbackDraft, false)
+	seed(302, 101, models.FeedbackPublished, true)
+	seed(303, 101, models.FeedbackPublished, false) // Not requested
+
+	tests := []struct {
+		name          string
+		submissionIDs []int64
+		want          []models.ReviewerFeedbackCount
+	}{
+		{
+			name:          "drafts and published per reviewer",
+			submissionIDs: []int64{301},
+			want: []models.ReviewerFeedbackCount{
+				{SubmissionID: 301, ReviewerID: 101, Count: 3, PublishedCount: 2, DraftCount: 1},
+				{SubmissionID: 301, ReviewerID: 102, Count: 1, DraftCount: 1},
+			},
+		},
+		{
+			name:          "only deleted feedbacks",
+			submissionIDs: []int64{302},
+		},
+		{
+			name:          "several submissions",
+			submissionIDs: []int64{303, 302, 301, 304},
+			want: []models.ReviewerFeedbackCount{
+				{SubmissionID: 301, ReviewerID: 101, Count: 3, PublishedCount: 2, DraftCount: 1},
+				{SubmissionID: 301, ReviewerID: 102, Count: 1, DraftCount: 1},
+				{SubmissionID: 303, ReviewerID: 101, Count: 1, PublishedCount: 1},
+			},
+		},
+		{
+			name: "no submissions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, err := r.CountBySubmissionReviewer(ctx, tt.submissionIDs)
+			if err != nil {
+				t.Fatalf("CountBySubmissionReviewer() error = %v", err)
+			}
+			if len(counts) != len(tt.want) {
+				t.Fatalf("CountBySubmissionReviewer() = %+v, want %+v", counts, tt.want)
+			}
+			for i := range counts {
+				if counts[i] != tt.want[i] {
+					t.Errorf("CountBySubmissionReviewer()[%d] = %+v, want %+v", i, counts[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
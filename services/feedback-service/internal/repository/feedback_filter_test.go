@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// checkPlaceholders fails when a query refers to an argument it was not given, or is given one
+// it never refers to
+func checkPlaceholders(t *testing.T, query string, args []interface{}) {
+	t.Helper()
+	used := make(map[int]bool)
+	for _, match := range placeholder.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(match[1])
+		used[n] = true
+	}
+	for n := range used {
+		if n < 1 || n > len(args) {
+			t.Errorf("query refers to $%d with %d arguments: %s", n, len(args), query)
+		}
+	}
+	for n := 1; n <= len(args); n++ {
+		if !used[n] {
+			t.Errorf("argument $%d (%v) is not used by the query: %s", n, args[n-1], query)
+		}
+	}
+}
+
+// arrayValue returns the PostgreSQL literal of a pq.Array argument, as built or as received by
+// a driver
+func arrayValue(t *testing.T, arg interface{}) string {
+	t.Helper()
+	if literal, ok := arg.(string); ok {
+		return literal
+	}
+	valuer, ok := arg.(driver.Valuer)
+	if !ok {
+		t.Fatalf("argument %T is not an array", arg)
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		t.Fatalf("array Value() error = %v", err)
+	}
+	return fmt.Sprint(value)
+}
+
+func TestApplyFilterClausesAttachments(t *testing.T) {
+	const (
+		exists = "EXISTS (SELECT 1 FROM feedback_assets a WHERE a.feedback_id = feedbacks.id)"
+		count  = "(SELECT COUNT(*) FROM feedback_assets a WHERE a.feedback_id = feedbacks.id)"
+		counts = "FROM unnest("
+	)
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-0000-0000-000000000001")
+	with, without := true, false
+	two := int32(2)
+
+	tests := []struct {
+		name      string
+		filter    models.FeedbackFilter
+		want      []string
+		wantNot   []string
+		wantArray []string // The override arrays, IDs then counts
+	}{
+		{name: "no attachment filter", wantNot: []string{exists, count, counts}},
+		{name: "with attachments", filter: models.FeedbackFilter{HasAttachments: &with}, want: []string{" AND " + exists}, wantNot: []string{"NOT EXISTS", counts}},
+		{name: "without attachments", filter: models.FeedbackFilter{HasAttachments: &without}, want: []string{" AND NOT " + exists}, wantNot: []string{counts}},
+		{name: "at least two", filter: models.FeedbackFilter{MinAttachments: &two}, want: []string{count + " >= $"}, wantNot: []string{exists, counts}},
+		{
+			name:    "storage counts without attachment filters",
+			filter:  models.FeedbackFilter{AttachmentCounts: map[uuid.UUID]int64{low: 1}},
+			wantNot: []string{exists, count, counts},
+		},
+		{
+			name:      "storage counts, with attachments",
+			filter:    models.FeedbackFilter{HasAttachments: &with, AttachmentCounts: map[uuid.UUID]int64{high: 3, low: 0}},
+			want:      []string{"COALESCE(", counts, count, ") > 0"},
+			wantNot:   []string{exists},
+			wantArray: []string{"{\"" + low.String() + "\",\"" + high.String() + "\"}", "{0,3}"},
+		},
+		{
+			name:      "storage counts, without attachments and at least two",
+			filter:    models.FeedbackFilter{HasAttachments: &without, MinAttachments: &two, AttachmentCounts: map[uuid.UUID]int64{low: 2}},
+			want:      []string{") = 0", ") >= $"},
+			wantNot:   []string{exists},
+			wantArray: []string{"{\"" + low.String() + "\"}", "{2}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &feedbackRepository{}
+			// The list queries start with their own arguments
+			base, countQuery, args := r.applyFilterClauses(
+				"SELECT id FROM feedbacks WHERE reviewer_id = $1",
+				"SELECT COUNT(*) FROM feedbacks WHERE reviewer_id = $1",
+				[]interface{}{int64(101)}, tt.filter)
+
+			checkPlaceholders(t, base, args)
+			if strings.TrimPrefix(base, "SELECT id") != strings.TrimPrefix(countQuery, "SELECT COUNT(*)") {
+				t.Errorf("count query %q does not apply the clauses of %q", countQuery, base)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(base, want) {
+					t.Errorf("query = %s, want it to contain %q", base, want)
+				}
+			}
+			for _, not := range tt.wantNot {
+				if strings.Contains(base, not) {
+					t.Errorf("query = %s, want it without %q", base, not)
+				}
+			}
+			if tt.filter.MinAttachments != nil && args[len(args)-1] != *tt.filter.MinAttachments {
+				t.Errorf("last argument = %v, want the minimum %d", args[len(args)-1], *tt.filter.MinAttachments)
+			}
+			if tt.wantArray != nil {
+				// The arrays follow the reviewer and precede the minimum
+				if got := []string{arrayValue(t, args[1]), arrayValue(t, args[2])}; !slices.Equal(got, tt.wantArray) {
+					t.Errorf("override arrays = %v, want %v", got, tt.wantArray)
+				}
+			}
+		})
+	}
+}
+
+func TestListAttachmentPendingQuery(t *testing.T) {
+	pending := []uuid.UUID{uuid.New(), uuid.New()}
+	db, fake := openFakeDB(func(string, []interface{}) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{pending[0].String()}, {pending[1].String()}}}
+	})
+	defer db.Close()
+	r := &feedbackRepository{db: db}
+
+	reviewer, submission, two := int64(101), int64(301), int32(2)
+	got, err := r.ListAttachmentPending(context.Background(), models.FeedbackFilter{
+		ReviewerID:       &reviewer,
+		SubmissionID:     &submission,
+		MinAttachments:   &two,
+		AttachmentCounts: map[uuid.UUID]int64{uuid.New(): 1},
+	}, 11)
+	if err != nil {
+		t.Fatalf("ListAttachmentPending() error = %v", err)
+	}
+	if !slices.Equal(got, pending) {
+		t.Errorf("ListAttachmentPending() = %v, want %v", got, pending)
+	}
+
+	queries := fake.recorded()
+	if len(queries) != 1 {
+		t.Fatalf("ListAttachmentPending() ran %d queries, want 1", len(queries))
+	}
+	query, args := queries[0].query, queries[0].args
+	checkPlaceholders(t, query, args)
+	for _, want := range []string{"deleted_at IS NULL", "reviewer_id = $", "submission_id = $", "attachment_backfill_journal", "ORDER BY id", "LIMIT $"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query = %s, want it to contain %q", query, want)
+		}
+	}
+	// The attachment filters are what the pending feedbacks are counted for
+	if strings.Contains(query, "feedback_assets") || strings.Contains(query, "unnest") {
+		t.Errorf("query = %s, want it without the attachment filters", query)
+	}
+	if limit := args[len(args)-1]; limit != int64(11) {
+		t.Errorf("limit argument = %v, want 11", limit)
+	}
+	if statuses := arrayValue(t, args[len(args)-2]); statuses != `{"`+models.BackfillDone+`","`+models.BackfillOrphaned+`"}` {
+		t.Errorf("finished statuses argument = %s", statuses)
+	}
+}
+
+// TestAttachmentFiltersPostgres runs the attachment filter subqueries against seeded rows
+func TestAttachmentFiltersPostgres(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	r := &feedbackRepository{db: db}
+
+	none := seedFeedbackRow(t, db, 1)
+	one := seedFeedbackRow(t, db, 2)
+	seedAssetRows(t, db, one, 1)
+	two := seedFeedbackRow(t, db, 3)
+	seedAssetRows(t, db, two, 2)
+	deleted := seedFeedbackRow(t, db, 4)
+	seedAssetRows(t, db, deleted, 2)
+	mustExec(t, db, `UPDATE feedbacks SET deleted_at = NOW() WHERE id = $1`, deleted)
+
+	with, without := true, false
+	minOne, minTwo := int32(1), int32(2)
+	tests := []struct {
+		name   string
+		filter models.FeedbackFilter
+		want   []uuid.UUID
+	}{
+		{name: "with attachments", filter: models.FeedbackFilter{HasAttachments: &with}, want: []uuid.UUID{one, two}},
+		{name: "without attachments", filter: models.FeedbackFilter{HasAttachments: &without}, want: []uuid.UUID{none}},
+		{name: "at least one", filter: models.FeedbackFilter{MinAttachments: &minOne}, want: []uuid.UUID{one, two}},
+		{name: "at least two", filter: models.FeedbackFilter{MinAttachments: &minTwo}, want: []uuid.UUID{two}},
+		{name: "with attachments and at least two", filter: models.FeedbackFilter{HasAttachments: &with, MinAttachments: &minTwo}, want: []uuid.UUID{two}},
+		{
+			name:   "storage count raises a feedback without rows",
+			filter: models.FeedbackFilter{MinAttachments: &minTwo, AttachmentCounts: map[uuid.UUID]int64{none: 3}},
+			want:   []uuid.UUID{none, two},
+		},
+		{
+			name:   "storage count overrides the rows",
+			filter: models.FeedbackFilter{HasAttachments: &with, AttachmentCounts: map[uuid.UUID]int64{one: 0}},
+			want:   []uuid.UUID{two},
+		},
+		{
+			name:   "storage count finds no attachments",
+			filter: models.FeedbackFilter{HasAttachments: &without, AttachmentCounts: map[uuid.UUID]int64{two: 0}},
+			want:   []uuid.UUID{none, two},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, args := r.applyFilterClauses(`SELECT id FROM feedbacks WHERE deleted_at IS NULL`, "", nil, tt.filter)
+			rows, err := db.QueryContext(ctx, query, args...)
+			if err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			defer rows.Close()
+			var got []uuid.UUID
+			for rows.Next() {
+				var id uuid.UUID
+				if err := rows.Scan(&id); err != nil {
+					t.Fatalf("scan error = %v", err)
+				}
+				got = append(got, id)
+			}
+			if err := rows.Err(); err != nil {
+				t.Fatalf("rows error = %v", err)
+			}
+			slices.SortFunc(got, compareUUIDs)
+			want := slices.SortedFunc(slices.Values(tt.want), compareUUIDs)
+			if !slices.Equal(got, want) {
+				t.Errorf("filtered feedbacks = %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("pending backfill", func(t *testing.T) {
+		mustExec(t, db, `INSERT INTO attachment_backfill_journal (prefix, status) VALUES ($1, $2), ($3, $4), ($5, $6)`,
+			one.String(), models.BackfillDone, two.String(), models.BackfillOrphaned, none.String(), models.BackfillFailed)
+
+		got, err := r.ListAttachmentPending(ctx, models.FeedbackFilter{HasAttachments: &with}, 10)
+		if err != nil {
+			t.Fatalf("ListAttachmentPending() error = %v", err)
+		}
+		// A failed prefix is retried, so its feedback is still pending
+		if !slices.Equal(got, []uuid.UUID{none}) {
+			t.Errorf("ListAttachmentPending() = %v, want %v", got, []uuid.UUID{none})
+		}
+	})
+}
+
+func compareUUIDs(a, b uuid.UUID) int {
+	return strings.Compare(a.String(), b.String())
+}
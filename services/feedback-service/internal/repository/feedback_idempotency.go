@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// GetByIdempotencyKey returns the feedback a reviewer created with an idempotency key, or nil
+// if the key is unused or was cleared. Deleted feedbacks keep their key until it expires.
+func (r *feedbackRepository) GetByIdempotencyKey(ctx context.Context, reviewerID int64, key string) (*models.IdempotencyRecord, error) {
+	if !r.schema.has("idempotency_key") {
+		return nil, nil // No key can have been recorded yet
+	}
+	query := `
+		SELECT id, COALESCE(` + r.schema.readExpr("idempotency_fingerprint") + `, ''), created_at
+		FROM feedbacks
+		WHERE reviewer_id = $1 AND idempotency_key = $2
+	`
+	record := &models.IdempotencyRecord{}
+	err := r.db.QueryRowContext(ctx, query, reviewerID, key).Scan(&record.FeedbackID, &record.Fingerprint, &record.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback by idempotency key: %w", err)
+	}
+	return record, nil
+}
+
+// ClearIdempotencyKey releases the idempotency key of a feedback, so it can be used again
+func (r *feedbackRepository) ClearIdempotencyKey(ctx context.Context, id uuid.UUID) error {
+	if !r.schema.writable("idempotency_key") || !r.schema.writable("idempotency_fingerprint") {
+		return nil
+	}
+	query := `UPDATE feedbacks SET idempotency_key = NULL, idempotency_fingerprint = NULL WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to clear idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// searchConfig is the text search configuration used for indexing and queries. Feedback is
+// written in several languages, so words are indexed as they are, without stemming.
+const searchConfig = "simple"
+
+// maxIndexedContentBytes caps the content indexed per feedback, keeping its lexemes well
+// below the 1 MB tsvector limit. Text beyond it is stored but not searchable.
+const maxIndexedContentBytes = 256 * 1024
+
+// maxSnippetSourceBytes caps the text a snippet is cut from, since ts_headline parses it whole
+const maxSnippetSourceBytes = 32 * 1024
+
+// snippetOptions are the ts_headline options of search snippets. Matches are wrapped in
+// Markdown bold, as the content they are cut from is Markdown.
+const snippetOptions = `StartSel="**", StopSel="**", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+// truncateUTF8 returns at most n bytes of s without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// IndexContent writes the search lexemes and the length in characters of a feedback's content
+func (r *feedbackRepository) IndexContent(ctx context.Context, id uuid.UUID, content string) error {
+	query := fmt.Sprintf(`UPDATE feedbacks SET content_search = to_tsvector('%s', $2), content_length = $3 WHERE id = $1`, searchConfig)
+	if _, err := r.db.ExecContext(ctx, query, id, truncateUTF8(content, maxIndexedContentBytes), utf8.RuneCountInString(content)); err != nil {
+		return fmt.Errorf("failed to index feedback content: %w", err)
+	}
+	return nil
+}
+
+// Search returns the feedbacks whose title or content match a web search query, most relevant
+// first, with the total number of matches. Title matches rank above content matches.
+func (r *feedbackRepository) Search(ctx context.Context, filter models.FeedbackSearchFilter) ([]*models.FeedbackSearchResult, int32, error) {
+	tsQuery := fmt.Sprintf("websearch_to_tsquery('%s', $1)", searchConfig)
+	where := "deleted_at IS NULL AND search_vector @@ " + tsQuery
+	args := []interface{}{filter.Query}
+
+	if filter.ReviewerID != nil {
+		args = append(args, *filter.ReviewerID)
+		where += fmt.Sprintf(" AND reviewer_id = $%d", len(args))
+	}
+	if filter.StudentID != nil {
+		args = append(args, *filter.StudentID)
+		where += fmt.Sprintf(" AND student_id = $%d", len(args))
+	}
+	if filter.SubmissionID != nil {
+		args = append(args, *filter.SubmissionID)
+		where += fmt.Sprintf(" AND submission_id = $%d", len(args))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.ApprovedOnly {
+		args = append(args, pq.Array([]string{models.ApprovalNotRequired, models.ApprovalApproved}))
+		where += fmt.Sprintf(" AND approval_status = ANY($%d)", len(args))
+	}
+
+	var totalCount int32
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM feedbacks WHERE "+where, args...).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+	if totalCount == 0 {
+		return []*models.FeedbackSearchResult{}, 0, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT `+r.schema.selectColumns()+`,
+			ts_rank(search_vector, %s) AS rank
+		FROM feedbacks
+		WHERE %s
+		ORDER BY rank DESC, created_at DESC, id DESC
+		LIMIT %d OFFSET %d
+	`, tsQuery, where, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*models.FeedbackSearchResult
+	var feedbackIDs []string
+	for rows.Next() {
+		feedback := &models.Feedback{}
+		result := &models.FeedbackSearchResult{Feedback: feedback}
+		var snapshot []byte
+		var points, maxPoints sql.NullFloat64
+		var publishedAt, readAt, resubmissionDeadline, archivedAt sql.NullTime
+		var approverID sql.NullInt64
+		err := rows.Scan(
+			&feedback.ID, &feedback.ReviewerID, &feedback.StudentID, &feedback.SubmissionID,
+			&feedback.Title, &feedback.Locked, &feedback.LockReason, &feedback.NeedsReupload, &snapshot, &feedback.CourseID, &points, &maxPoints, &feedback.Status, &publishedAt, &feedback.ApprovalStatus, &approverID, &feedback.ApprovalNote, &readAt, &feedback.RevisionCount, &feedback.RequiresResubmission, &resubmissionDeadline, &archivedAt, &feedback.CreatedAt, &feedback.UpdatedAt,
+			&result.Rank,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		if feedback.Snapshot, err = decodeSnapshot(snapshot); err != nil {
+			return nil, 0, err
+		}
+		feedback.Grade = decodeGrade(points, maxPoints)
+		feedback.PublishedAt = decodeTime(publishedAt)
+		feedback.ReadAt = decodeTime(readAt)
+		feedback.ResubmissionDeadline = decodeTime(resubmissionDeadline)
+		feedback.ArchivedAt = decodeTime(archivedAt)
+		feedback.ApproverID = decodeInt64(approverID)
+		results = append(results, result)
+		feedbackIDs = append(feedbackIDs, feedback.ID.String())
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating search results: %w", err)
+	}
+	if len(results) == 0 {
+		// Past the last page
+		return []*models.FeedbackSearchResult{}, totalCount, nil
+	}
+
+	feedbacks := make([]*models.Feedback, len(results))
+	for i, result := range results {
+		feedbacks[i] = result.Feedback
+	}
+	if err := r.loadRubricItems(ctx, feedbacks); err != nil {
+		return nil, 0, err
+	}
+	if err := r.loadReactionCounts(ctx, feedbacks); err != nil {
+		return nil, 0, err
+	}
+
+	contentMap, err := r.GetContents(ctx, feedbackIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get feedback contents: %w", err)
+	}
+	for _, result := range results {
+		result.Feedback.Content = contentMap[result.Feedback.ID.String()]
+	}
+
+	if err := r.searchSnippets(ctx, filter.Query, results); err != nil {
+		return nil, 0, err
+	}
+
+	return results, totalCount, nil
+}
+
+// searchSnippets highlights the query in the content of each result, or in the title when the
+// feedback has no content, using a single ts_headline query for the whole page
+func (r *feedbackRepository) searchSnippets(ctx context.Context, searchQuery string, results []*models.FeedbackSearchResult) error {
+	sources := make([]string, len(results))
+	for i, result := range results {
+		source := result.Feedback.Content
+		if strings.TrimSpace(source) == "" {
+			source = result.Feedback.Title
+		}
+		sources[i] = truncateUTF8(source, maxSnippetSourceBytes)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT ts_headline('%s', source, websearch_to_tsquery('%s', $2), $3)
+		FROM unnest($1::text[]) WITH ORDINALITY AS s(source, position)
+		ORDER BY position
+	`, searchConfig, searchConfig)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(sources), searchQuery, snippetOptions)
+	if err != nil {
+		return fmt.Errorf("failed to build search snippets: %w", err)
+	}
+	defer rows.Close()
+
+	for i := 0; rows.Next(); i++ {
+		if i >= len(results) {
+			return fmt.Errorf("unexpected number of search snippets")
+		}
+		if err := rows.Scan(&results[i].Snippet); err != nil {
+			return fmt.Errorf("failed to scan search snippet: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating search snippets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// Stats aggregates the feedbacks matching a filter with two grouped queries, so the cost does
+// not depend on loading rows: one for the totals and one for the busiest submissions.
+func (r *feedbackRepository) Stats(ctx context.Context, filter models.FeedbackStatsFilter) (*models.FeedbackStats, error) {
+	where := "deleted_at IS NULL"
+	var args []interface{}
+	if filter.ReviewerID != nil {
+		args = append(args, *filter.ReviewerID)
+		where += fmt.Sprintf(" AND reviewer_id = $%d", len(args))
+	}
+	if filter.StudentID != nil {
+		args = append(args, *filter.StudentID)
+		where += fmt.Sprintf(" AND student_id = $%d", len(args))
+	}
+	if filter.VisibleOnly {
+		args = append(args, models.FeedbackPublished, pq.Array([]string{models.ApprovalNotRequired, models.ApprovalApproved}))
+		where += fmt.Sprintf(" AND status = $%d AND approval_status = ANY($%d)", len(args)-1, len(args))
+	}
+
+	stats := &models.FeedbackStats{}
+	totalsArgs := append(args[:len(args):len(args)], filter.Since7Days.UTC(), filter.Since30Days.UTC())
+	totalsQuery := fmt.Sprintf(`
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE created_at >= $%d),
+			COUNT(*) FILTER (WHERE created_at >= $%d),
+			COALESCE(AVG(content_length), 0),
+			COUNT(DISTINCT submission_id)
+		FROM feedbacks
+		WHERE %s
+	`, len(totalsArgs)-1, len(totalsArgs), where)
+	err := r.db.QueryRowContext(ctx, totalsQuery, totalsArgs...).Scan(
+		&stats.Total, &stats.CreatedLast7Days, &stats.CreatedLast30Days, &stats.AverageContentLength, &stats.SubmissionCount,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate feedbacks: %w", err)
+	}
+
+	stats.PerSubmission = []models.SubmissionFeedbackCount{}
+	if stats.Total == 0 || filter.MaxSubmissions <= 0 {
+		return stats, nil
+	}
+
+	perSubmissionQuery := fmt.Sprintf(`
+		SELECT submission_id, COUNT(*) AS feedbacks
+		FROM feedbacks
+		WHERE %s
+		GROUP BY submission_id
+		ORDER BY feedbacks DESC, submission_id
+		LIMIT %d
+	`, where, filter.MaxSubmissions)
+	rows, err := r.db.QueryContext(ctx, perSubmissionQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count feedbacks per submission: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count models.SubmissionFeedbackCount
+		if err := rows.Scan(&count.SubmissionID, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan submission feedback count: %w", err)
+		}
+		stats.PerSubmission = append(stats.PerSubmission, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating submission feedback counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// StudentSummary summarizes the feedbacks a student can see, published, approved if approval
+// was requested and not deleted, per submission and overall. One grouped query computes both
+// through grouping sets; the overall row is the one not grouped by submission, and it is
+// returned even when the student has no feedback.
+func (r *feedbackRepository) StudentSummary(ctx context.Context, studentID int64) (*models.StudentFeedbackSummary, error) {
+	query := fmt.Sprintf(`
+		SELECT GROUPING(submission_id) = 1, COALESCE(submission_id, 0),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE %s IS NULL),
+			MAX(COALESCE(published_at, created_at)),
+			COUNT(*) FILTER (WHERE points IS NOT NULL AND max_points > 0),
+			AVG(points / max_points) FILTER (WHERE points IS NOT NULL AND max_points > 0)
+		FROM feedbacks
+		WHERE student_id = $1 AND deleted_at IS NULL AND status = $2 AND approval_status = ANY($3)
+		GROUP BY GROUPING SETS ((submission_id), ())
+		ORDER BY MAX(COALESCE(published_at, created_at)) DESC, submission_id
+	`, r.schema.readExpr("read_at"))
+	visible := pq.Array([]string{models.ApprovalNotRequired, models.ApprovalApproved})
+	rows, err := r.db.QueryContext(ctx, query, studentID, models.FeedbackPublished, visible)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize student feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &models.StudentFeedbackSummary{Submissions: []models.SubmissionFeedbackSummary{}}
+	for rows.Next() {
+		var overall bool
+		var submission models.SubmissionFeedbackSummary
+		var latest sql.NullTime
+		var average sql.NullFloat64
+		err := rows.Scan(&overall, &submission.SubmissionID, &submission.FeedbackCount, &submission.UnreadCount,
+			&latest, &submission.GradedCount, &average)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student feedback summary: %w", err)
+		}
+		if average.Valid {
+			submission.AverageGradeRatio = &average.Float64
+		}
+		if overall {
+			summary.FeedbackCount = submission.FeedbackCount
+			summary.UnreadCount = submission.UnreadCount
+			summary.LatestFeedbackAt = decodeTime(latest)
+			summary.GradedCount = submission.GradedCount
+			summary.AverageGradeRatio = submission.AverageGradeRatio
+			continue
+		}
+		submission.LatestFeedbackAt = latest.Time
+		summary.Submissions = append(summary.Submissions, submission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating student feedback summary: %w", err)
+	}
+	return summary, nil
+}
+
+// StudentLastActivity returns when the feedbacks matching a student list filter last saw
+// activity: publication, an edit or the student reading them. It is one aggregate over the
+// filter, the page bounds aside, answered from idx_feedbacks_student_activity; nil when
+// nothing matches.
+func (r *feedbackRepository) StudentLastActivity(ctx context.Context, filter models.FeedbackFilter) (*time.Time, error) {
+	query := `SELECT MAX(` + r.activityExpr() + `) FROM feedbacks WHERE student_id = $1 AND deleted_at IS NULL`
+	args := []interface{}{filter.StudentID}
+	query, _, args = r.applyFilterClauses(query, "", args, filter)
+
+	var lastActivity sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&lastActivity); err != nil {
+		return nil, fmt.Errorf("failed to get student feedback activity: %w", err)
+	}
+	return decodeTime(lastActivity), nil
+}
+
+// activityExpr is the time a feedback last saw activity, the expression indexed by
+// idx_feedbacks_student_activity; GREATEST skips the timestamps that are NULL
+func (r *feedbackRepository) activityExpr() string {
+	return fmt.Sprintf("GREATEST(COALESCE(published_at, created_at), updated_at, %s)", r.schema.readExpr("read_at"))
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+func TestCountCreatedByBucketQuery(t *testing.T) {
+	bucket := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	db, fake := openFakeDB(func(string, []interface{}) fakeResult {
+		return fakeResult{columns: []string{"bucket", "count"}, rows: [][]driver.Value{{bucket, int64(3)}}}
+	})
+	defer db.Close()
+	r := &feedbackRepository{db: db}
+
+	// 13:00 in UTC+3 is the 10:00 UTC bucket edge
+	zone := time.FixedZone("UTC+3", 3*60*60)
+	since := time.Date(2025, 3, 1, 13, 0, 0, 0, zone)
+	until := since.Add(2 * time.Hour)
+	reviewer, submission := int64(101), int64(301)
+
+	tests := []struct {
+		name                string
+		reviewerID          *int64
+		submissionID        *int64
+		wantClause, wantNot string
+		wantScopeArgs       []interface{}
+	}{
+		{name: "reviewer", reviewerID: &reviewer, wantClause: "reviewer_id = $4", wantNot: "submission_id", wantScopeArgs: []interface{}{reviewer}},
+		{name: "submission", submissionID: &submission, wantClause: "submission_id = $4", wantNot: "reviewer_id", wantScopeArgs: []interface{}{submission}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets, err := r.CountCreatedByBucket(context.Background(), tt.reviewerID, tt.submissionID, models.RateBucketHour, since, until)
+			if err != nil {
+				t.Fatalf("CountCreatedByBucket() error = %v", err)
+			}
+			if len(buckets) != 1 || !buckets[0].Start.Equal(bucket) || buckets[0].Count != 3 {
+				t.Errorf("CountCreatedByBucket() = %v, want the scanned bucket", buckets)
+			}
+
+			queries := fake.recorded()
+			query, args := queries[len(queries)-1].query, queries[len(queries)-1].args
+			checkPlaceholders(t, query, args)
+			// Half-open range: a feedback created exactly at until belongs to the next request
+			for _, want := range []string{"date_trunc($1, created_at)", "created_at >= $2 AND created_at < $3", tt.wantClause, "GROUP BY bucket ORDER BY bucket"} {
+				if !strings.Contains(query, want) {
+					t.Errorf("query = %s, want it to contain %q", query, want)
+				}
+			}
+			if strings.Contains(query, tt.wantNot) {
+				t.Errorf("query = %s, want it without %q", query, tt.wantNot)
+			}
+			// The range is sent in UTC, which the timestamps are stored in
+			if args[0] != models.RateBucketHour {
+				t.Errorf("bucket size argument = %v", args[0])
+			}
+			for i, want := range []time.Time{since, until} {
+				got, ok := args[i+1].(time.Time)
+				if !ok || !got.Equal(want) || got.Location() != time.UTC {
+					t.Errorf("range argument %d = %v, want %v in UTC", i+1, args[i+1], want.UTC())
+				}
+			}
+			if !slices.Equal(args[3:], tt.wantScopeArgs) {
+				t.Errorf("scope arguments = %v, want %v", args[3:], tt.wantScopeArgs)
+			}
+		})
+	}
+}
+
+// TestCountCreatedByBucketPostgres groups seeded creation times by date_trunc, in a session
+// whose time zone is not UTC
+func TestCountCreatedByBucketPostgres(t *testing.T) {
+	db := openTestDB(t, "timezone=Asia/Tokyo")
+	ctx := context.Background()
+	r := &feedbackRepository{db: db}
+
+	at := func(value string) time.Time {
+		ts, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	created := []string{
+		"2025-03-01T09:59:59.999999Z", // Last microsecond before the first hour
+		"2025-03-01T10:00:00Z",        // Exactly on a bucket edge and on since
+		"2025-03-01T10:59:59.999999Z",
+		"2025-03-01T11:00:00Z",
+		"2025-03-01T23:59:59.999999Z", // Either side of UTC midnight
+		"2025-03-02T00:00:00Z",
+		"2025-03-02T01:00:00Z", // Exactly on until of the day range
+	}
+	for i, value := range created {
+		id := seedFeedbackRow(t, db, int64(1000+i))
+		mustExec(t, db, `UPDATE feedbacks SET created_at = $2 WHERE id = $1`, id, at(value))
+	}
+
+	reviewer := int64(101)
+	tests := []struct {
+		name         string
+		size         string
+		since, until string
+		want         map[string]int64
+	}{
+		{
+			name:  "hours, edges included at the start and excluded at the end",
+			size:  models.RateBucketHour,
+			since: "2025-03-01T10:00:00Z", until: "2025-03-01T11:00:00Z",
+			want: map[string]int64{"2025-03-01T10:00:00Z": 2},
+		},
+		{
+			name:  "hours, both edges",
+			size:  models.RateBucketHour,
+			since: "2025-03-01T09:00:00Z", until: "2025-03-01T12:00:00Z",
+			want: map[string]int64{"2025-03-01T09:00:00Z": 1, "2025-03-01T10:00:00Z": 2, "2025-03-01T11:00:00Z": 1},
+		},
+		{
+			name:  "days split at UTC midnight",
+			size:  models.RateBucketDay,
+			since: "2025-03-01T11:00:00Z", until: "2025-03-02T01:00:00Z",
+			want: map[string]int64{"2025-03-01T00:00:00Z": 2, "2025-03-02T00:00:00Z": 1},
+		},
+		{
+			name:  "minutes across midnight",
+			size:  models.RateBucketMinute,
+			since: "2025-03-01T23:59:00Z", until: "2025-03-02T00:01:00Z",
+			want: map[string]int64{"2025-03-01T23:59:00Z": 1, "2025-03-02T00:00:00Z": 1},
+		},
+		{
+			name:  "no feedbacks in the range",
+			size:  models.RateBucketHour,
+			since: "2025-03-03T00:00:00Z", until: "2025-03-04T00:00:00Z",
+			want: map[string]int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets, err := r.CountCreatedByBucket(ctx, &reviewer, nil, tt.size, at(tt.since), at(tt.until))
+			if err != nil {
+				t.Fatalf("CountCreatedByBucket() error = %v", err)
+			}
+			got := make(map[string]int64, len(buckets))
+			for i, bucket := range buckets {
+				if i > 0 && !buckets[i-1].Start.Before(bucket.Start) {
+					t.Errorf("CountCreatedByBucket() buckets out of order: %v", buckets)
+				}
+				got[bucket.Start.UTC().Format(time.RFC3339)] = bucket.Count
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("CountCreatedByBucket() = %v, want %v", got, tt.want)
+			}
+			for start, count := range tt.want {
+				if got[start] != count {
+					t.Errorf("CountCreatedByBucket()[%s] = %d, want %d", start, got[start], count)
+				}
+			}
+		})
+	}
+}
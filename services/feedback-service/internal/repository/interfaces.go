@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
 	"github.com/google/uuid"
@@ -13,40 +14,209 @@ import (
 type FeedbackRepository interface {
 	Create(ctx context.Context, feedback *models.Feedback) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Feedback, error)
-	Update(ctx context.Context, feedback *models.Feedback) error
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Feedback, error)
+	Update(ctx context.Context, feedback *models.Feedback, editorID int64, contentSet bool) error
+	ListRevisions(ctx context.Context, feedbackID uuid.UUID, page, limit int32) ([]*models.FeedbackRevision, int32, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	ListByUser(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int32, error)
-	ListByStudent(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int32, error)
+	ListByUser(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error)
+	ListByStudent(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error)
+	ListBySubmission(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error)
+	List(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error)
+	ListAttachmentPending(ctx context.Context, filter models.FeedbackFilter, limit int) ([]uuid.UUID, error)
+	ListIDsBySubmission(ctx context.Context, submissionID int64, after uuid.UUID, limit int, includeDeleted bool) ([]uuid.UUID, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, actorID int64) error
+	SetLock(ctx context.Context, id uuid.UUID, locked bool, reason string) (bool, error)
+	TransferOwnership(ctx context.Context, id uuid.UUID, fromReviewerID, toReviewerID int64, updatedAt time.Time) (bool, error)
+	SetArchived(ctx context.Context, id uuid.UUID, archived bool, archivedAt time.Time) (bool, error)
+	ArchiveCreatedBefore(ctx context.Context, cutoff, archivedAt time.Time, limit int) (int64, error)
+	Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) (bool, error)
+	Acknowledge(ctx context.Context, id uuid.UUID, readAt time.Time) (bool, error)
+	SetReaction(ctx context.Context, id uuid.UUID, userID int64, reaction string) (string, error)
+	RemoveReaction(ctx context.Context, id uuid.UUID, userID int64) (string, error)
+	TransitionApproval(ctx context.Context, id uuid.UUID, from, to string, approverID int64, note string) (bool, error)
+	IsLocked(ctx context.Context, id uuid.UUID) (bool, error)
+	SetNeedsReupload(ctx context.Context, id uuid.UUID, needsReupload bool) error
+	UpdateSnapshots(ctx context.Context, updates []models.SubmissionSnapshotUpdate) (int64, error)
+	ListRefsAfter(ctx context.Context, after uuid.UUID, limit int) ([]models.FeedbackRef, error)
+	ListTitleCandidates(ctx context.Context, reviewerID, submissionID int64, since time.Time, minLength, maxLength, limit int) ([]*models.SimilarFeedback, error)
+	CountCreatedByBucket(ctx context.Context, reviewerID, submissionID *int64, bucketSize string, since, until time.Time) ([]*models.RateBucket, error)
+	CountBySubmissionReviewer(ctx context.Context, submissionIDs []int64) ([]models.ReviewerFeedbackCount, error)
+	Search(ctx context.Context, filter models.FeedbackSearchFilter) ([]*models.FeedbackSearchResult, int32, error)
+	Stats(ctx context.Context, filter models.FeedbackStatsFilter) (*models.FeedbackStats, error)
+	StudentSummary(ctx context.Context, studentID int64) (*models.StudentFeedbackSummary, error)
+	StudentLastActivity(ctx context.Context, filter models.FeedbackFilter) (*time.Time, error)
+	IndexContent(ctx context.Context, id uuid.UUID, content string) error
+	GetByIdempotencyKey(ctx context.Context, reviewerID int64, key string) (*models.IdempotencyRecord, error)
+	ClearIdempotencyKey(ctx context.Context, id uuid.UUID) error
 	
 	// Content operations (MongoDB)
 	SetContent(ctx context.Context, id uuid.UUID, content string) error
 	GetContent(ctx context.Context, id uuid.UUID) (string, error)
-	DeleteContent(ctx context.Context, id uuid.UUID) error
+	GetContents(ctx context.Context, ids []string) (map[string]string, error)
+	DeleteContent(ctx context.Context, id uuid.UUID) (int64, error)
 }
 
 type CommentTxRepository interface {
 	CommentRepository
 }
 
+// ContentOutboxRepository defines the interface for feedback content waiting to be written to
+// MongoDB. Entries are recorded by FeedbackRepository in the transaction of the feedback row.
+type ContentOutboxRepository interface {
+	ListPending(ctx context.Context, feedbackID uuid.UUID, after uuid.UUID, due bool, limit int) ([]*models.ContentOutboxEntry, error)
+	Complete(ctx context.Context, entry *models.ContentOutboxEntry) error
+	RecordFailure(ctx context.Context, entry *models.ContentOutboxEntry, message string, retryIn time.Duration) error
+	Count(ctx context.Context) (int64, error)
+	DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error)
+}
+
 // AttachmentRepository defines the interface for attachment operations in MinIO
 type AttachmentRepository interface {
 	Upload(ctx context.Context, feedbackID uuid.UUID, filename string, contentType string, data io.Reader, size int64) error
 	Download(ctx context.Context, feedbackID uuid.UUID, filename string) (io.ReadCloser, *models.AttachmentInfo, error)
 	List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error)
+	Count(ctx context.Context, feedbackID uuid.UUID) (int64, error)
 	Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error
-	DeleteAll(ctx context.Context, feedbackID uuid.UUID) error
+	DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error)
 	GetLocationInfo(ctx context.Context, feedbackID uuid.UUID, filename string) (*models.AttachmentLocationInfo, error)
 	ListLocationInfo(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentLocationInfo, error)
+	StreamPrefixes(ctx context.Context) <-chan models.AttachmentPrefix
+	UploadStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string, contentType string, data io.Reader, size int64) error
+	PromoteStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string) error
+	DeleteStaged(ctx context.Context, sessionID uuid.UUID) (int64, error)
+	RewrapKeys(ctx context.Context) (*models.KeyRotationSummary, error)
+	ListLegacyObjects(ctx context.Context, startAfter string, limit int) ([]string, error)
+	MigrateObject(ctx context.Context, relative string) (*models.MigrationEntry, error)
+	FallbackReads() int64
+}
+
+// MigrationJournalRepository defines the interface for the attachment storage migration journal
+type MigrationJournalRepository interface {
+	RecordBatch(ctx context.Context, entries []*models.MigrationEntry, cursor *models.MigrationCursor) error
+	ListFailed(ctx context.Context) ([]string, error)
+	GetProgress(ctx context.Context) (*models.MigrationProgress, error)
+	RestartListing(ctx context.Context) error
+}
+
+// BackfillJournalRepository defines the interface for the attachment metadata backfill journal
+type BackfillJournalRepository interface {
+	Record(ctx context.Context, entry *models.BackfillEntry) error
+	ListFinished(ctx context.Context) (map[string]bool, error)
+	IsFinished(ctx context.Context, prefix string) (bool, bool, error)
+	FinishListing(ctx context.Context, totalPrefixes int64, complete bool) error
+	GetProgress(ctx context.Context) (*models.BackfillProgress, error)
+}
+
+// DeletionIntentRepository defines the interface for the intents of feedback hard deletions
+// stored in PostgreSQL
+type DeletionIntentRepository interface {
+	Begin(ctx context.Context, intent *models.DeletionIntent) error
+	ListStale(ctx context.Context, olderThan time.Duration, limit int) ([]*models.DeletionIntent, error)
+	RecordFailure(ctx context.Context, feedbackID uuid.UUID, message string) error
+	Complete(ctx context.Context, feedbackID uuid.UUID) error
+}
+
+// UploadSessionRepository defines the interface for multi-file upload sessions stored in PostgreSQL
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *models.UploadSession) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error)
+	SetStatus(ctx context.Context, id uuid.UUID, from, to string) (bool, error)
+	UpsertFile(ctx context.Context, sessionID uuid.UUID, file *models.UploadSessionFile) error
+	MarkFilePromoted(ctx context.Context, sessionID uuid.UUID, filename string) error
+	ListIDsByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]uuid.UUID, error)
+	DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error)
+}
+
+// AssetRepository defines the interface for attachment metadata stored in PostgreSQL
+type AssetRepository interface {
+	Upsert(ctx context.Context, feedbackID uuid.UUID, info *models.AttachmentInfo) error
+	Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error
+	DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error)
+	ListFilenames(ctx context.Context, feedbackIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	SetOrder(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) error
+	ListOrder(ctx context.Context, feedbackID uuid.UUID) (map[string]int, error)
+	GetChecksum(ctx context.Context, feedbackID uuid.UUID, filename string) (string, error)
+	ListChecksums(ctx context.Context, feedbackID *uuid.UUID, after *models.AttachmentChecksum, limit int) ([]*models.AttachmentChecksum, error)
+	List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error)
+	InsertMissing(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) (int64, bool, error)
+}
+
+// AuditRepository defines the interface for the feedback audit log
+type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) error
+	ListByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]*models.AuditEntry, error)
+}
+
+// SealRepository defines the interface for the append-only feedback seals
+type SealRepository interface {
+	Append(ctx context.Context, seal *models.FeedbackSeal) error
+	Latest(ctx context.Context, feedbackID uuid.UUID) (*models.FeedbackSeal, error)
+}
+
+// RetentionRepository defines the interface for pruning expired maintenance data in PostgreSQL
+type RetentionRepository interface {
+	PruneBatch(ctx context.Context, dataset string, cutoff time.Time, limit int, force bool) ([]string, error)
+	CountExpired(ctx context.Context, dataset string, cutoff time.Time, force bool) (int64, int64, error)
+	Oldest(ctx context.Context, dataset string) (*time.Time, error)
+}
+
+// TemplateRepository defines the interface for reviewers' feedback templates
+type TemplateRepository interface {
+	Create(ctx context.Context, template *models.FeedbackTemplate) error
+	Get(ctx context.Context, id uuid.UUID) (*models.FeedbackTemplate, error)
+	ListByReviewer(ctx context.Context, reviewerID int64, page, limit int32) ([]*models.FeedbackTemplate, int32, error)
+	Update(ctx context.Context, template *models.FeedbackTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CoursePolicyRepository defines the interface for per-course policy overrides stored in PostgreSQL
+type CoursePolicyRepository interface {
+	Get(ctx context.Context, courseID string) (*models.CoursePolicy, error)
+	List(ctx context.Context) ([]*models.CoursePolicy, error)
+	Upsert(ctx context.Context, policy *models.CoursePolicy) error
+	Delete(ctx context.Context, courseID string) (bool, error)
+}
+
+// TransferUsageRepository defines the interface for per-principal daily transfer counters
+type TransferUsageRepository interface {
+	AddUsage(ctx context.Context, deltas []*models.TransferUsage) error
+	GetUsage(ctx context.Context, principalID int64, since, until time.Time) ([]*models.TransferUsage, error)
+	TopPrincipals(ctx context.Context, since, until time.Time, limit int) ([]*models.TransferUsage, error)
 }
 
 // CommentRepository defines the interface for comment operations in MongoDB
 type CommentRepository interface {
 	Create(ctx context.Context, comment *models.Comment) error
+	CreateWithTimestamps(ctx context.Context, comments []*models.Comment) []error
 	GetByID(ctx context.Context, id string) (*models.Comment, error)
 	Update(ctx context.Context, comment *models.Comment) error
 	Delete(ctx context.Context, id string) error
 	DeleteReplies(ctx context.Context, parentID string) error
-	ListByContext(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int32, error)
-	ListReplies(ctx context.Context, parentID string, page, limit int32) ([]*models.Comment, int32, error)
+	ListByContext(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int64, error)
+	ListReplies(ctx context.Context, parentID string, sort string, after *models.CommentCursor, page, limit int32) ([]*models.Comment, int64, error)
+	ListByUser(ctx context.Context, userID int64, contentType string, page, limit int32) ([]*models.Comment, int64, error)
+	SetReaction(ctx context.Context, commentID string, userID int64, reaction string) (string, error)
+	RemoveReaction(ctx context.Context, commentID string, userID int64) (string, error)
+	LoadReactions(ctx context.Context, comments []*models.Comment, viewerUserID int64) error
+	SaveReport(ctx context.Context, report *models.CommentReport) (bool, error)
+	ListReported(ctx context.Context, page, limit int32) ([]*models.ReportedComment, int64, error)
+	CloseReports(ctx context.Context, commentID string, resolution string, moderatorID int64) (int64, error)
+	SetHidden(ctx context.Context, commentID string, hidden bool, moderatorID int64) (*models.Comment, error)
+	BackfillDepth(ctx context.Context, batchSize int) (int64, error)
+	CountCreatedByBucket(ctx context.Context, contentID int64, contentType, bucketSize string, since, until time.Time) ([]*models.RateBucket, error)
+	CountByContent(ctx context.Context, contentID int64, contentType string, includeReplies bool) (int64, error)
+	CountByContents(ctx context.Context, contentIDs []int64, contentType string, includeReplies bool) (map[int64]int64, error)
+	SummarizeFeedbackThreads(ctx context.Context, threads []models.FeedbackThread) (map[string]*models.FeedbackThreadSummary, error)
+	MoveContentBatch(ctx context.Context, sourceContentID, targetContentID int64, contentType string, limit int) (int64, error)
+	StartMerge(ctx context.Context, sourceContentID, targetContentID int64, contentType string, actorID int64) (*models.CommentMerge, error)
+	AddMergeProgress(ctx context.Context, mergeID string, moved int64) error
+	CompleteMerge(ctx context.Context, mergeID string) (*models.CommentMerge, error)
+	EraseUserBatch(ctx context.Context, userID int64, mode string, limit int) (int64, error)
+	StartErasure(ctx context.Context, userID int64, mode string, actorID int64) (*models.CommentErasure, error)
+	AddErasureProgress(ctx context.Context, erasureID string, erased int64) error
+	CompleteErasure(ctx context.Context, erasureID string) (*models.CommentErasure, error)
+	ChangeStreamsSupported(ctx context.Context) (bool, error)
+	WatchChanges(ctx context.Context, opened func(), fn func(event models.CommentStreamEvent)) error
+	ListChanges(ctx context.Context, filter models.CommentChangeFilter) ([]models.CommentStreamEvent, *models.CommentChangeCursor, error)
 	WithTransaction(ctx context.Context, fn func(CommentTxRepository) error) error
 }
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// migrationJournalRepository implements MigrationJournalRepository using the
+// attachment_migration_state and attachment_migration_journal tables
+type migrationJournalRepository struct {
+	db *sql.DB
+}
+
+// NewMigrationJournalRepository creates a new attachment migration journal repository
+func NewMigrationJournalRepository(db *sql.DB) MigrationJournalRepository {
+	return &migrationJournalRepository{
+		db: db,
+	}
+}
+
+// RecordBatch journals the outcome of migrated objects and, when cursor is set, advances the
+// listing cursor in the same transaction, so a resumed run never skips unjournaled objects
+func (r *migrationJournalRepository) RecordBatch(ctx context.Context, entries []*models.MigrationEntry, cursor *models.MigrationCursor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO attachment_migration_journal (object_key, status, size, checksum, error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (object_key) DO UPDATE SET
+			status = EXCLUDED.status,
+			size = EXCLUDED.size,
+			checksum = EXCLUDED.checksum,
+			error = EXCLUDED.error,
+			attempts = attachment_migration_journal.attempts + 1,
+			updated_at = NOW()
+	`
+	for _, entry := range entries {
+		if _, err := tx.ExecContext(ctx, query, entry.Key, entry.Status, entry.Size, entry.Checksum, entry.Error); err != nil {
+			return fmt.Errorf("failed to journal %s: %w", entry.Key, err)
+		}
+	}
+
+	if cursor != nil {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE attachment_migration_state SET cursor = $1, listing_complete = $2, updated_at = NOW() WHERE id = 1`,
+			cursor.Key, cursor.ListingComplete,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to advance migration cursor: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration journal: %w", err)
+	}
+	return nil
+}
+
+// ListFailed returns the keys of objects whose last migration attempt failed
+func (r *migrationJournalRepository) ListFailed(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT object_key FROM attachment_migration_journal WHERE status = $1 ORDER BY object_key`,
+		models.MigrationFailed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan failed migration: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failed migrations: %w", err)
+	}
+	return keys, nil
+}
+
+// GetProgress summarizes the journal
+func (r *migrationJournalRepository) GetProgress(ctx context.Context) (*models.MigrationProgress, error) {
+	progress := &models.MigrationProgress{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT cursor, listing_complete FROM attachment_migration_state WHERE id = 1`,
+	).Scan(&progress.Cursor, &progress.ListingComplete)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration state: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM attachment_migration_journal GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count migration journal: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan migration journal count: %w", err)
+		}
+		switch status {
+		case models.MigrationCopied:
+			progress.Copied = count
+		case models.MigrationSkipped:
+			progress.Skipped = count
+		case models.MigrationMissing:
+			progress.Missing = count
+		case models.MigrationFailed:
+			progress.Failed = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating migration journal counts: %w", err)
+	}
+
+	progress.Finish()
+	return progress, nil
+}
+
+// RestartListing rewinds the listing cursor so the next run lists the legacy location from the
+// start. Journal entries are kept; objects already copied are skipped quickly.
+func (r *migrationJournalRepository) RestartListing(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE attachment_migration_state SET cursor = '', listing_complete = FALSE, updated_at = NOW() WHERE id = 1`)
+	if err != nil {
+		return fmt.Errorf("failed to restart migration listing: %w", err)
+	}
+	return nil
+}
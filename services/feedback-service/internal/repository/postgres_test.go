@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/database"
+	"github.com/google/uuid"
+)
+
+// testDatabaseEnv names a PostgreSQL database the repository tests create their schemas in. The
+// tests needing a real database are skipped when it is unset.
+const testDatabaseEnv = "FEEDBACK_TEST_DATABASE_URL"
+
+// openTestDB returns a connection to a fresh schema of the test database with every migration
+// applied, and with the given "name=value" session settings. The schema is dropped when the test
+// ends.
+func openTestDB(t *testing.T, settings ...string) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "feedback_test_" + hex.EncodeToString(suffix)
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	// Unknown connection parameters are sent to the server as run-time settings
+	for _, setting := range append([]string{"search_path=" + schema + ",public"}, settings...) {
+		switch {
+		case !strings.Contains(dsn, "://"):
+			dsn += " " + setting
+		case strings.Contains(dsn, "?"):
+			dsn += "&" + setting
+		default:
+			dsn += "?" + setting
+		}
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open test schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.Migrate(ctx, db, "../../migrations"); err != nil {
+		t.Fatalf("migrate test schema: %v", err)
+	}
+	return db
+}
+
+// mustExec runs a statement seeding the test database
+func mustExec(t *testing.T, db *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.ExecContext(context.Background(), query, args...); err != nil {
+		t.Fatalf("seed %q: %v", query, err)
+	}
+}
+
+// seedFeedbackRow inserts a published feedback of reviewer 101 for student 201 and returns its ID
+func seedFeedbackRow(t *testing.T, db *sql.DB, submissionID int64) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	mustExec(t, db, `INSERT INTO feedbacks (id, reviewer_id, student_id, submission_id, title, published_at)
+		VALUES ($1, 101, 201, $2, 'feedback', NOW())`, id, submissionID)
+	return id
+}
+
+// seedAssetRows inserts n attachment metadata rows for a feedback
+func seedAssetRows(t *testing.T, db *sql.DB, feedbackID uuid.UUID, n int) {
+	t.Helper()
+	for i := range n {
+		mustExec(t, db, `INSERT INTO feedback_assets (feedback_id, filename, file_size, content_type)
+			VALUES ($1, $2, 1, 'text/plain')`, feedbackID, fmt.Sprintf("file-%d.txt", i))
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetReaction sets the reaction of a user to a feedback, replacing an earlier one, and returns
+// the reaction it replaced or "" if there was none
+func (r *feedbackRepository) SetReaction(ctx context.Context, id uuid.UUID, userID int64, reaction string) (string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := lockReaction(ctx, tx, id, userID)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO feedback_reactions (feedback_id, user_id, reaction, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (feedback_id, user_id) DO UPDATE
+		SET reaction = EXCLUDED.reaction, updated_at = EXCLUDED.updated_at
+	`, id, userID, reaction, now)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return "", ErrFeedbackNotFound.Wrap(err)
+		}
+		return "", fmt.Errorf("failed to set reaction: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit reaction: %w", err)
+	}
+	return previous, nil
+}
+
+// RemoveReaction deletes the reaction of a user to a feedback and returns it, or "" if the user
+// had not reacted
+func (r *feedbackRepository) RemoveReaction(ctx context.Context, id uuid.UUID, userID int64) (string, error) {
+	var previous string
+	err := r.db.QueryRowContext(ctx, `
+		DELETE FROM feedback_reactions
+		WHERE feedback_id = $1 AND user_id = $2
+		RETURNING reaction
+	`, id, userID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return previous, nil
+}
+
+// lockReaction returns the current reaction of a user to a feedback, locking its row
+func lockReaction(ctx context.Context, tx *sql.Tx, id uuid.UUID, userID int64) (string, error) {
+	var reaction string
+	err := tx.QueryRowContext(ctx, `
+		SELECT reaction FROM feedback_reactions
+		WHERE feedback_id = $1 AND user_id = $2
+		FOR UPDATE
+	`, id, userID).Scan(&reaction)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get reaction: %w", err)
+	}
+	return reaction, nil
+}
+
+// loadReactionCounts sets the reaction counts of feedbacks with one query
+func (r *feedbackRepository) loadReactionCounts(ctx context.Context, feedbacks []*models.Feedback) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.Feedback, len(feedbacks))
+	ids := make([]string, len(feedbacks))
+	for i, feedback := range feedbacks {
+		byID[feedback.ID] = feedback
+		ids[i] = feedback.ID.String()
+	}
+
+	query := `
+		SELECT feedback_id,
+			COUNT(*) FILTER (WHERE reaction = $2),
+			COUNT(*) FILTER (WHERE reaction = $3)
+		FROM feedback_reactions
+		WHERE feedback_id = ANY($1::uuid[])
+		GROUP BY feedback_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), models.ReactionHelpful, models.ReactionNotHelpful)
+	if err != nil {
+		return fmt.Errorf("failed to get reaction counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feedbackID uuid.UUID
+		var helpful, notHelpful int32
+		if err := rows.Scan(&feedbackID, &helpful, &notHelpful); err != nil {
+			return fmt.Errorf("failed to scan reaction counts: %w", err)
+		}
+		if feedback, ok := byID[feedbackID]; ok {
+			feedback.HelpfulCount = helpful
+			feedback.NotHelpfulCount = notHelpful
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating reaction count rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,573 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// object is a stored attachment or staged file
+type object struct {
+	data        []byte
+	contentType string
+	uploadedAt  time.Time
+}
+
+// stagingPrefix is the prefix of the files staged by upload sessions
+const stagingPrefix = "staging"
+
+// attachmentKey returns the key of an attachment object
+func attachmentKey(feedbackID uuid.UUID, filename string) string {
+	return feedbackID.String() + "/" + filename
+}
+
+// stagedKey returns the key of a file staged in an upload session
+func stagedKey(sessionID uuid.UUID, filename string) string {
+	return fmt.Sprintf("%s/%s/%s", stagingPrefix, sessionID, filename)
+}
+
+// attachmentRepository implements repository.AttachmentRepository on a Store. Objects are kept
+// in plaintext at one location, so there is nothing to rewrap or migrate.
+type attachmentRepository struct {
+	s *Store
+}
+
+// put stores an object, reading exactly size bytes of data
+func (r *attachmentRepository) put(ctx context.Context, key, contentType string, data io.Reader, size int64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("upload cancelled before starting: %w", err)
+	}
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return fmt.Errorf("failed to upload attachment: %w", err)
+	}
+	if int64(len(content)) != size {
+		return fmt.Errorf("failed to upload attachment: read %d bytes, expected %d", len(content), size)
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.objects[key] = &object{data: content, contentType: contentType, uploadedAt: time.Now().UTC()}
+	return nil
+}
+
+// info returns the attachment info of a stored object
+func (o *object) info(filename string) *models.AttachmentInfo {
+	return &models.AttachmentInfo{
+		Filename:    filename,
+		Size:        int64(len(o.data)),
+		StoredSize:  int64(len(o.data)),
+		ContentType: o.contentType,
+		UploadedAt:  o.uploadedAt,
+	}
+}
+
+func (r *attachmentRepository) Upload(ctx context.Context, feedbackID uuid.UUID, filename string, contentType string, data io.Reader, size int64) error {
+	return r.put(ctx, attachmentKey(feedbackID, filename), contentType, data, size)
+}
+
+func (r *attachmentRepository) Download(ctx context.Context, feedbackID uuid.UUID, filename string) (io.ReadCloser, *models.AttachmentInfo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.objects[attachmentKey(feedbackID, filename)]
+	if stored == nil {
+		return nil, nil, fmt.Errorf("failed to get attachment info: the specified key does not exist")
+	}
+	return io.NopCloser(bytes.NewReader(stored.data)), stored.info(filename), nil
+}
+
+// listPrefix returns the filenames under a prefix in key order. The caller holds the lock.
+func (s *Store) listPrefix(prefix string) []string {
+	var names []string
+	for key := range s.objects {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (r *attachmentRepository) List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	prefix := feedbackID.String() + "/"
+	names := r.s.listPrefix(prefix)
+	attachments := make([]*models.AttachmentInfo, len(names))
+	for i, name := range names {
+		attachments[i] = r.s.objects[prefix+name].info(name)
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) Count(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.listPrefix(feedbackID.String() + "/"))), nil
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.objects, attachmentKey(feedbackID, filename))
+	return nil
+}
+
+// deletePrefix removes the objects under a prefix and returns how many it removed
+func (r *attachmentRepository) deletePrefix(prefix string) int64 {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	names := r.s.listPrefix(prefix)
+	for _, name := range names {
+		delete(r.s.objects, prefix+name)
+	}
+	return int64(len(names))
+}
+
+func (r *attachmentRepository) DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	return r.deletePrefix(feedbackID.String() + "/"), nil
+}
+
+// locationInfo returns the location of a stored object
+func locationInfo(feedbackID uuid.UUID, info *models.AttachmentInfo) *models.AttachmentLocationInfo {
+	return &models.AttachmentLocationInfo{
+		Filename:        info.Filename,
+		Size:            info.Size,
+		ContentType:     info.ContentType,
+		UploadedAt:      info.UploadedAt,
+		MinioBucket:     "attachments",
+		MinioObjectPath: attachmentKey(feedbackID, info.Filename),
+		MinioEndpoint:   "repotest",
+	}
+}
+
+func (r *attachmentRepository) GetLocationInfo(ctx context.Context, feedbackID uuid.UUID, filename string) (*models.AttachmentLocationInfo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.objects[attachmentKey(feedbackID, filename)]
+	if stored == nil {
+		return nil, fmt.Errorf("failed to get attachment info: the specified key does not exist")
+	}
+	return locationInfo(feedbackID, stored.info(filename)), nil
+}
+
+func (r *attachmentRepository) ListLocationInfo(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentLocationInfo, error) {
+	attachments, err := r.List(ctx, feedbackID)
+	if err != nil {
+		return nil, err
+	}
+	locations := make([]*models.AttachmentLocationInfo, len(attachments))
+	for i, info := range attachments {
+		locations[i] = locationInfo(feedbackID, info)
+	}
+	return locations, nil
+}
+
+func (r *attachmentRepository) StreamPrefixes(ctx context.Context) <-chan models.AttachmentPrefix {
+	r.s.mu.Lock()
+	var prefixes []models.AttachmentPrefix
+	for _, key := range r.s.listPrefix("") {
+		name, filename, _ := strings.Cut(key, "/")
+		if name == stagingPrefix {
+			continue
+		}
+		if len(prefixes) == 0 || prefixes[len(prefixes)-1].Prefix != name {
+			prefix := models.AttachmentPrefix{Prefix: name}
+			if id, err := uuid.Parse(name); err == nil && id.String() == name {
+				prefix.FeedbackID = id
+			}
+			prefixes = append(prefixes, prefix)
+		}
+		current := &prefixes[len(prefixes)-1]
+		current.Objects = append(current.Objects, models.AttachmentInfo{Filename: filename, Size: int64(len(r.s.objects[key].data))})
+	}
+	r.s.mu.Unlock()
+
+	out := make(chan models.AttachmentPrefix)
+	go func() {
+		defer close(out)
+		for _, prefix := range prefixes {
+			select {
+			case out <- prefix:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (r *attachmentRepository) UploadStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string, contentType string, data io.Reader, size int64) error {
+	return r.put(ctx, stagedKey(sessionID, filename), contentType, data, size)
+}
+
+func (r *attachmentRepository) PromoteStaged(ctx context.Context, sessionID, feedbackID uuid.UUID, filename string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.s.promoteErr[filename]; err != nil {
+		return err
+	}
+	staged := r.s.objects[stagedKey(sessionID, filename)]
+	if staged == nil {
+		if r.s.objects[attachmentKey(feedbackID, filename)] == nil {
+			return fmt.Errorf("staged file %s is missing", filename)
+		}
+		return nil // Promoted by an earlier attempt
+	}
+	r.s.objects[attachmentKey(feedbackID, filename)] = staged
+	delete(r.s.objects, stagedKey(sessionID, filename))
+	return nil
+}
+
+func (r *attachmentRepository) DeleteStaged(ctx context.Context, sessionID uuid.UUID) (int64, error) {
+	return r.deletePrefix(fmt.Sprintf("%s/%s/", stagingPrefix, sessionID)), nil
+}
+
+func (r *attachmentRepository) RewrapKeys(ctx context.Context) (*models.KeyRotationSummary, error) {
+	return nil, fmt.Errorf("attachment encryption is not configured")
+}
+
+func (r *attachmentRepository) ListLegacyObjects(ctx context.Context, startAfter string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (r *attachmentRepository) MigrateObject(ctx context.Context, relative string) (*models.MigrationEntry, error) {
+	return nil, unsupported("MigrateObject")
+}
+
+func (r *attachmentRepository) FallbackReads() int64 {
+	return 0
+}
+
+// assetRow is a row of the feedback_assets table
+type assetRow struct {
+	info     models.AttachmentInfo
+	position *int
+}
+
+// assetRepository implements repository.AssetRepository on a Store
+type assetRepository struct {
+	s *Store
+}
+
+// rows returns the metadata rows of a feedback, creating the map. The caller holds the lock.
+func (s *Store) assetRows(feedbackID uuid.UUID) map[string]*assetRow {
+	rows := s.assets[feedbackID]
+	if rows == nil {
+		rows = make(map[string]*assetRow)
+		s.assets[feedbackID] = rows
+	}
+	return rows
+}
+
+func (r *assetRepository) Upsert(ctx context.Context, feedbackID uuid.UUID, info *models.AttachmentInfo) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.assetUpsertErr != nil {
+		return r.s.assetUpsertErr
+	}
+	rows := r.s.assetRows(feedbackID)
+	row := rows[info.Filename]
+	if row == nil {
+		row = &assetRow{}
+		rows[info.Filename] = row
+	}
+	row.info = *info
+	row.info.StoredSize = 0
+	return nil
+}
+
+func (r *assetRepository) Delete(ctx context.Context, feedbackID uuid.UUID, filename string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.assetDeleteErr != nil {
+		return r.s.assetDeleteErr
+	}
+	delete(r.s.assets[feedbackID], filename)
+	return nil
+}
+
+func (r *assetRepository) DeleteAll(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	deleted := int64(len(r.s.assets[feedbackID]))
+	delete(r.s.assets, feedbackID)
+	return deleted, nil
+}
+
+func (r *assetRepository) ListFilenames(ctx context.Context, feedbackIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	filenames := make(map[uuid.UUID][]string)
+	for _, id := range feedbackIDs {
+		for filename := range r.s.assets[id] {
+			filenames[id] = append(filenames[id], filename)
+		}
+		slices.Sort(filenames[id])
+	}
+	return filenames, nil
+}
+
+func (r *assetRepository) SetOrder(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rows := r.s.assetRows(feedbackID)
+	for _, row := range rows {
+		row.position = nil
+	}
+	for i, info := range attachments {
+		row := rows[info.Filename]
+		if row == nil {
+			row = &assetRow{info: *info}
+			rows[info.Filename] = row
+		}
+		position := i
+		row.position = &position
+	}
+	return nil
+}
+
+func (r *assetRepository) ListOrder(ctx context.Context, feedbackID uuid.UUID) (map[string]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	order := make(map[string]int)
+	for filename, row := range r.s.assets[feedbackID] {
+		if row.position != nil {
+			order[filename] = *row.position
+		}
+	}
+	return order, nil
+}
+
+func (r *assetRepository) GetChecksum(ctx context.Context, feedbackID uuid.UUID, filename string) (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if row := r.s.assets[feedbackID][filename]; row != nil {
+		return row.info.SHA256, nil
+	}
+	return "", nil
+}
+
+func (r *assetRepository) ListChecksums(ctx context.Context, feedbackID *uuid.UUID, after *models.AttachmentChecksum, limit int) ([]*models.AttachmentChecksum, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var checksums []*models.AttachmentChecksum
+	for id, rows := range r.s.assets {
+		if (feedbackID != nil && id != *feedbackID) || r.s.liveFeedback(id) == nil {
+			continue
+		}
+		for filename, row := range rows {
+			if row.info.SHA256 != "" {
+				checksums = append(checksums, &models.AttachmentChecksum{FeedbackID: id, Filename: filename, SHA256: row.info.SHA256})
+			}
+		}
+	}
+	compare := func(a, b *models.AttachmentChecksum) int {
+		if c := compareIDs(a.FeedbackID, b.FeedbackID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Filename, b.Filename)
+	}
+	slices.SortFunc(checksums, compare)
+	if after != nil {
+		checksums = slices.DeleteFunc(checksums, func(c *models.AttachmentChecksum) bool { return compare(c, after) <= 0 })
+	}
+	return checksums[:min(limit, len(checksums))], nil
+}
+
+func (r *assetRepository) List(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	attachments := make([]*models.AttachmentInfo, 0, len(r.s.assets[feedbackID]))
+	for _, row := range r.s.assets[feedbackID] {
+		info := row.info
+		attachments = append(attachments, &info)
+	}
+	slices.SortFunc(attachments, func(a, b *models.AttachmentInfo) int { return strings.Compare(a.Filename, b.Filename) })
+	return attachments, nil
+}
+
+func (r *assetRepository) InsertMissing(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) (int64, bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.feedbacks[feedbackID] == nil {
+		return 0, false, nil
+	}
+	rows := r.s.assetRows(feedbackID)
+	var created int64
+	for _, info := range attachments {
+		if rows[info.Filename] == nil {
+			rows[info.Filename] = &assetRow{info: *info}
+			created++
+		}
+	}
+	return created, true, nil
+}
+
+// sessionRepository implements repository.UploadSessionRepository on a Store
+type sessionRepository struct {
+	s *Store
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.UploadSession) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session.ID = uuid.New()
+	session.Status = models.UploadSessionOpen
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+	stored := *session
+	stored.Files = nil
+	r.s.sessions[session.ID] = &stored
+	return nil
+}
+
+func (r *sessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.sessions[id]
+	if stored == nil {
+		return nil, repository.ErrUploadSessionNotFound
+	}
+	session := *stored
+	session.Files = make([]*models.UploadSessionFile, len(stored.Files))
+	for i, file := range stored.Files {
+		copied := *file
+		session.Files[i] = &copied
+	}
+	slices.SortFunc(session.Files, func(a, b *models.UploadSessionFile) int { return strings.Compare(a.Filename, b.Filename) })
+	return &session, nil
+}
+
+func (r *sessionRepository) SetStatus(ctx context.Context, id uuid.UUID, from, to string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session := r.s.sessions[id]
+	if session == nil || session.Status != from {
+		return false, nil
+	}
+	session.Status = to
+	session.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *sessionRepository) UpsertFile(ctx context.Context, sessionID uuid.UUID, file *models.UploadSessionFile) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	session := r.s.sessions[sessionID]
+	if session == nil {
+		return repository.ErrUploadSessionNotFound
+	}
+	copied := *file
+	session.Files = slices.DeleteFunc(session.Files, func(f *models.UploadSessionFile) bool { return f.Filename == file.Filename })
+	session.Files = append(session.Files, &copied)
+	return nil
+}
+
+func (r *sessionRepository) MarkFilePromoted(ctx context.Context, sessionID uuid.UUID, filename string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if session := r.s.sessions[sessionID]; session != nil {
+		for _, file := range session.Files {
+			if file.Filename == filename {
+				file.Promoted = true
+			}
+		}
+	}
+	return nil
+}
+
+func (r *sessionRepository) ListIDsByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ids []uuid.UUID
+	for id, session := range r.s.sessions {
+		if session.FeedbackID == feedbackID {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, compareIDs)
+	return ids, nil
+}
+
+func (r *sessionRepository) DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var deleted int64
+	for id, session := range r.s.sessions {
+		if session.FeedbackID == feedbackID {
+			delete(r.s.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// backfillRepository implements repository.BackfillJournalRepository on a Store
+type backfillRepository struct {
+	s *Store
+}
+
+// finished reports whether a journaled prefix is not backfilled again
+func finished(entry *models.BackfillEntry) bool {
+	return entry.Status == models.BackfillDone || entry.Status == models.BackfillOrphaned
+}
+
+func (r *backfillRepository) Record(ctx context.Context, entry *models.BackfillEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	copied := *entry
+	r.s.backfill[entry.Prefix] = &copied
+	return nil
+}
+
+func (r *backfillRepository) ListFinished(ctx context.Context) (map[string]bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	prefixes := make(map[string]bool)
+	for prefix, entry := range r.s.backfill {
+		if finished(entry) {
+			prefixes[prefix] = true
+		}
+	}
+	return prefixes, nil
+}
+
+func (r *backfillRepository) IsFinished(ctx context.Context, prefix string) (bool, bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	entry := r.s.backfill[prefix]
+	return entry != nil && finished(entry), r.s.listing.Complete, nil
+}
+
+func (r *backfillRepository) FinishListing(ctx context.Context, totalPrefixes int64, complete bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.listing.PrefixesTotal = totalPrefixes
+	r.s.listing.Complete = complete
+	return nil
+}
+
+func (r *backfillRepository) GetProgress(ctx context.Context) (*models.BackfillProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	progress := &models.BackfillProgress{PrefixesTotal: r.s.listing.PrefixesTotal, Complete: r.s.listing.Complete}
+	for _, entry := range r.s.backfill {
+		switch {
+		case finished(entry):
+			progress.PrefixesDone++
+		case entry.Status == models.BackfillFailed:
+			progress.PrefixesFailed++
+		}
+		progress.RowsCreated += entry.RowsCreated
+		progress.ConflictsSkipped += entry.Conflicts
+	}
+	return progress, nil
+}
@@ -0,0 +1,759 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reportKey identifies a user's report of a comment
+type reportKey struct {
+	commentID  primitive.ObjectID
+	reporterID int64
+}
+
+// commentRepository implements repository.CommentRepository on a Store. Change streams are not
+// supported, so comment streams poll ListChanges. Transactions run fn on the repository itself;
+// the store's lock is not held across fn, so they are not isolated.
+type commentRepository struct {
+	s *Store
+}
+
+// now returns the current time at MongoDB's millisecond precision
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// compareObjectIDs orders comment IDs as MongoDB does
+func compareObjectIDs(a, b primitive.ObjectID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// cloneComment copies a comment, leaving out its edit history unless withEdits is set
+func cloneComment(comment *models.Comment, withEdits bool) *models.Comment {
+	copied := *comment
+	copied.Edits = nil
+	if withEdits {
+		copied.Edits = slices.Clone(comment.Edits)
+	}
+	return &copied
+}
+
+// parseID parses the hex ID of a comment
+func parseID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid comment ID: %w", err)
+	}
+	return objectID, nil
+}
+
+// isTopLevel reports whether a comment is not a reply
+func isTopLevel(comment *models.Comment) bool {
+	return comment.ParentID == nil || *comment.ParentID == ""
+}
+
+// replyCount returns the number of direct replies to a comment. The caller holds the lock.
+func (s *Store) replyCount(id primitive.ObjectID) int64 {
+	var count int64
+	for _, comment := range s.comments {
+		if comment.ParentID != nil && *comment.ParentID == id.Hex() {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment.ID = primitive.NewObjectID()
+	comment.CreatedAt = now()
+	comment.UpdatedAt = comment.CreatedAt
+	comment.ChangedAt = comment.CreatedAt
+	r.s.comments[comment.ID] = cloneComment(comment, true)
+	return nil
+}
+
+func (r *commentRepository) CreateWithTimestamps(ctx context.Context, comments []*models.Comment) []error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	errs := make([]error, len(comments))
+	changedAt := now()
+	for i, comment := range comments {
+		if err := comment.ValidateImportTimestamps(changedAt); err != nil {
+			errs[i] = err
+			continue
+		}
+		comment.ID = primitive.NewObjectID()
+		comment.ChangedAt = changedAt
+		r.s.comments[comment.ID] = cloneComment(comment, true)
+	}
+	return errs
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id string) (*models.Comment, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment := r.s.comments[objectID]
+	if comment == nil {
+		return nil, repository.ErrCommentNotFound
+	}
+	return cloneComment(comment, true), nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.comments[comment.ID]
+	if stored == nil {
+		return repository.ErrCommentNotFound
+	}
+	comment.UpdatedAt = now()
+	comment.ChangedAt = comment.UpdatedAt
+	stored.Edits = append(stored.Edits, models.CommentEdit{Content: stored.Content, WrittenAt: stored.UpdatedAt, ReplacedAt: comment.UpdatedAt})
+	if len(stored.Edits) > models.MaxCommentEdits {
+		stored.Edits = stored.Edits[len(stored.Edits)-models.MaxCommentEdits:]
+	}
+	stored.Content = comment.Content
+	stored.UpdatedAt = comment.UpdatedAt
+	stored.ChangedAt = comment.ChangedAt
+	stored.Edited = true
+	stored.EditCount++
+	comment.Edited = stored.Edited
+	comment.EditCount = stored.EditCount
+	comment.Edits = slices.Clone(stored.Edits)
+	return nil
+}
+
+// descendants returns the IDs of every reply below a comment. The caller holds the lock.
+func (s *Store) descendants(id primitive.ObjectID) []primitive.ObjectID {
+	var ids []primitive.ObjectID
+	parents := []string{id.Hex()}
+	for len(parents) > 0 {
+		var next []string
+		for childID, comment := range s.comments {
+			if comment.ParentID != nil && slices.Contains(parents, *comment.ParentID) {
+				ids = append(ids, childID)
+				next = append(next, childID.Hex())
+			}
+		}
+		parents = next
+	}
+	slices.SortFunc(ids, compareObjectIDs)
+	return ids
+}
+
+// deleteDependents deletes the reactions and reports of comments. The caller holds the lock.
+func (s *Store) deleteDependents(ids []primitive.ObjectID) {
+	for _, id := range ids {
+		delete(s.commentReactions, id)
+	}
+	for key := range s.reports {
+		if slices.Contains(ids, key.commentID) {
+			delete(s.reports, key)
+		}
+	}
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment := r.s.comments[objectID]
+	if comment == nil {
+		return repository.ErrCommentNotFound
+	}
+	ids := append([]primitive.ObjectID{objectID}, r.s.descendants(objectID)...)
+	for _, deleted := range ids {
+		delete(r.s.comments, deleted)
+	}
+	r.s.deleteDependents(ids)
+	r.s.deletions = append(r.s.deletions, &models.CommentDeletion{
+		ID:         primitive.NewObjectID(),
+		CommentIDs: ids,
+		ContentID:  comment.ContentID,
+		ContentRef: comment.ContentRef,
+		Type:       comment.Type,
+		DeletedAt:  now(),
+	})
+	return nil
+}
+
+func (r *commentRepository) DeleteReplies(ctx context.Context, parentID string) error {
+	objectID, err := parseID(parentID)
+	if err != nil {
+		return err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	ids := r.s.descendants(objectID)
+	for _, id := range ids {
+		delete(r.s.comments, id)
+	}
+	r.s.deleteDependents(ids)
+	return nil
+}
+
+// compareCreation orders comments by creation time and ID, newest first unless ascending is set
+func compareCreation(a, b *models.Comment, ascending bool) int {
+	c := a.CreatedAt.Compare(b.CreatedAt)
+	if c == 0 {
+		c = compareObjectIDs(a.ID, b.ID)
+	}
+	if !ascending {
+		c = -c
+	}
+	return c
+}
+
+// listComments selects the comments matching keep, sorts them and returns a page of them with
+// the total, as ListByContext, ListReplies and ListByUser do. The caller holds the lock.
+func (s *Store) listComments(keep func(*models.Comment) bool, sort string, ascending bool, after *models.CommentCursor, pageNumber, limit int32) ([]*models.Comment, int64) {
+	var comments []*models.Comment
+	for _, stored := range s.comments {
+		if keep(stored) {
+			comment := cloneComment(stored, false)
+			comment.ReplyCount = s.replyCount(comment.ID)
+			comments = append(comments, comment)
+		}
+	}
+	compare := func(a, b *models.Comment) int { return compareCreation(a, b, ascending) }
+	if sort == models.CommentSortMostReplies {
+		compare = func(a, b *models.Comment) int {
+			if c := compareInt64(b.ReplyCount, a.ReplyCount); c != 0 {
+				return c
+			}
+			return compareCreation(a, b, false)
+		}
+	}
+	slices.SortFunc(comments, compare)
+	total := int64(len(comments))
+	if after == nil {
+		return page(comments, int(pageNumber), int(limit)), total
+	}
+	cursor := &models.Comment{ID: after.ID, CreatedAt: after.CreatedAt, ReplyCount: after.ReplyCount}
+	comments = slices.DeleteFunc(comments, func(c *models.Comment) bool { return compare(c, cursor) <= 0 })
+	return comments[:min(int(limit), len(comments))], total
+}
+
+// onContent reports whether a comment is on the content of a filter
+func onContent(comment *models.Comment, contentType string, contentID int64, contentRef string) bool {
+	if comment.Type != contentType {
+		return false
+	}
+	if contentRef != "" {
+		return comment.ContentRef == contentRef
+	}
+	return comment.ContentID == contentID
+}
+
+func (r *commentRepository) ListByContext(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keep := func(comment *models.Comment) bool {
+		switch {
+		case !onContent(comment, filter.Type, filter.ContentID, filter.ContentRef):
+			return false
+		case len(filter.UserIDs) > 0 && !slices.Contains(filter.UserIDs, comment.UserID):
+			return false
+		case filter.CreatedAfter != nil && comment.CreatedAt.Before(*filter.CreatedAfter):
+			return false
+		case filter.CreatedBefore != nil && !comment.CreatedAt.Before(*filter.CreatedBefore):
+			return false
+		case filter.ParentID != nil:
+			return comment.ParentID != nil && *comment.ParentID == *filter.ParentID
+		case filter.UnansweredOnly && r.s.replyCount(comment.ID) > 0:
+			return false
+		}
+		return isTopLevel(comment)
+	}
+	sort := filter.Sort
+	if filter.UnansweredOnly {
+		sort = ""
+	}
+	comments, total := r.s.listComments(keep, sort, filter.Sort == models.CommentSortOldest, filter.After, filter.Page, filter.Limit)
+	return comments, total, nil
+}
+
+func (r *commentRepository) ListReplies(ctx context.Context, parentID string, sort string, after *models.CommentCursor, pageNumber, limit int32) ([]*models.Comment, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keep := func(comment *models.Comment) bool { return comment.ParentID != nil && *comment.ParentID == parentID }
+	comments, total := r.s.listComments(keep, "", sort != models.CommentSortNewest, after, pageNumber, limit)
+	for _, comment := range comments {
+		comment.ReplyCount = 0 // Only comment lists fill reply counts
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) ListByUser(ctx context.Context, userID int64, contentType string, pageNumber, limit int32) ([]*models.Comment, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	keep := func(comment *models.Comment) bool {
+		return comment.UserID == userID && (contentType == "" || comment.Type == contentType)
+	}
+	comments, total := r.s.listComments(keep, "", false, nil, pageNumber, limit)
+	for _, comment := range comments {
+		comment.ReplyCount = 0
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) SetReaction(ctx context.Context, commentID string, userID int64, reaction string) (string, error) {
+	objectID, err := parseID(commentID)
+	if err != nil {
+		return "", err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reactions := r.s.commentReactions[objectID]
+	if reactions == nil {
+		reactions = make(map[int64]string)
+		r.s.commentReactions[objectID] = reactions
+	}
+	previous := reactions[userID]
+	reactions[userID] = reaction
+	return previous, nil
+}
+
+func (r *commentRepository) RemoveReaction(ctx context.Context, commentID string, userID int64) (string, error) {
+	objectID, err := parseID(commentID)
+	if err != nil {
+		return "", err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	previous := r.s.commentReactions[objectID][userID]
+	delete(r.s.commentReactions[objectID], userID)
+	return previous, nil
+}
+
+func (r *commentRepository) LoadReactions(ctx context.Context, comments []*models.Comment, viewerUserID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, comment := range comments {
+		for userID, reaction := range r.s.commentReactions[comment.ID] {
+			comment.CountReaction(reaction, 1)
+			if viewerUserID != 0 && userID == viewerUserID {
+				comment.ViewerReaction = reaction
+			}
+		}
+	}
+	return nil
+}
+
+func (r *commentRepository) SaveReport(ctx context.Context, report *models.CommentReport) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	report.Open = true
+	report.UpdatedAt = now()
+	key := reportKey{report.CommentID, report.ReporterID}
+	stored := r.s.reports[key]
+	created := stored == nil
+	if created {
+		stored = &models.CommentReport{CommentID: report.CommentID, ReporterID: report.ReporterID, CreatedAt: report.UpdatedAt}
+		r.s.reports[key] = stored
+	}
+	stored.Reason, stored.Note, stored.Open, stored.UpdatedAt = report.Reason, report.Note, true, report.UpdatedAt
+	stored.Resolution, stored.ResolvedBy = "", 0
+	return created, nil
+}
+
+func (r *commentRepository) ListReported(ctx context.Context, pageNumber, limit int32) ([]*models.ReportedComment, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	byComment := make(map[primitive.ObjectID]*models.ReportedComment)
+	var reported []*models.ReportedComment
+	for _, report := range r.s.reports {
+		if !report.Open {
+			continue
+		}
+		group := byComment[report.CommentID]
+		if group == nil {
+			group = &models.ReportedComment{Comment: &models.Comment{ID: report.CommentID}, ReasonCounts: make(map[string]int64)}
+			byComment[report.CommentID] = group
+			reported = append(reported, group)
+		}
+		copied := *report
+		group.ReportCount++
+		group.ReasonCounts[report.Reason]++
+		group.RecentReports = append(group.RecentReports, &copied)
+		if report.UpdatedAt.After(group.LastReportedAt) {
+			group.LastReportedAt = report.UpdatedAt
+		}
+	}
+	slices.SortFunc(reported, func(a, b *models.ReportedComment) int {
+		if c := compareInt64(b.ReportCount, a.ReportCount); c != 0 {
+			return c
+		}
+		if c := b.LastReportedAt.Compare(a.LastReportedAt); c != 0 {
+			return c
+		}
+		return compareObjectIDs(a.Comment.ID, b.Comment.ID)
+	})
+	total := int64(len(reported))
+	reported = page(reported, int(pageNumber), int(limit))
+	result := make([]*models.ReportedComment, 0, len(reported))
+	for _, group := range reported {
+		comment := r.s.comments[group.Comment.ID]
+		if comment == nil {
+			continue
+		}
+		group.Comment = cloneComment(comment, false)
+		slices.SortFunc(group.RecentReports, func(a, b *models.CommentReport) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+		group.RecentReports = group.RecentReports[:min(10, len(group.RecentReports))]
+		result = append(result, group)
+	}
+	return result, total, nil
+}
+
+func (r *commentRepository) CloseReports(ctx context.Context, commentID string, resolution string, moderatorID int64) (int64, error) {
+	objectID, err := parseID(commentID)
+	if err != nil {
+		return 0, err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var closed int64
+	for key, report := range r.s.reports {
+		if key.commentID == objectID && report.Open {
+			report.Open, report.Resolution, report.ResolvedBy = false, resolution, moderatorID
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+func (r *commentRepository) SetHidden(ctx context.Context, commentID string, hidden bool, moderatorID int64) (*models.Comment, error) {
+	objectID, err := parseID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	comment := r.s.comments[objectID]
+	if comment == nil {
+		return nil, repository.ErrCommentNotFound
+	}
+	comment.ChangedAt = now()
+	comment.HiddenAt, comment.HiddenBy = nil, 0
+	if hidden {
+		hiddenAt := comment.ChangedAt
+		comment.HiddenAt, comment.HiddenBy = &hiddenAt, moderatorID
+	}
+	return cloneComment(comment, false), nil
+}
+
+func (r *commentRepository) BackfillDepth(ctx context.Context, batchSize int) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var modified int64
+	for _, comment := range r.s.comments {
+		var depth int32
+		for parent := comment; !isTopLevel(parent); depth++ {
+			id, err := primitive.ObjectIDFromHex(*parent.ParentID)
+			if err != nil || r.s.comments[id] == nil {
+				break
+			}
+			parent = r.s.comments[id]
+		}
+		if comment.Depth != depth {
+			comment.Depth = depth
+			modified++
+		}
+	}
+	return modified, nil
+}
+
+func (r *commentRepository) CountCreatedByBucket(ctx context.Context, contentID int64, contentType, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var times []time.Time
+	for _, comment := range r.s.comments {
+		if comment.ContentID == contentID && comment.Type == contentType {
+			times = append(times, comment.CreatedAt)
+		}
+	}
+	return countByBucket(times, bucketSize, since, until), nil
+}
+
+func (r *commentRepository) CountByContent(ctx context.Context, contentID int64, contentType string, includeReplies bool) (int64, error) {
+	counts, err := r.CountByContents(ctx, []int64{contentID}, contentType, includeReplies)
+	return counts[contentID], err
+}
+
+func (r *commentRepository) CountByContents(ctx context.Context, contentIDs []int64, contentType string, includeReplies bool) (map[int64]int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	counts := make(map[int64]int64, len(contentIDs))
+	for _, comment := range r.s.comments {
+		if comment.Type == contentType && slices.Contains(contentIDs, comment.ContentID) && (includeReplies || isTopLevel(comment)) {
+			counts[comment.ContentID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *commentRepository) SummarizeFeedbackThreads(ctx context.Context, threads []models.FeedbackThread) (map[string]*models.FeedbackThreadSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.summaryErr != nil {
+		return nil, r.s.summaryErr
+	}
+	participants := make(map[string]models.FeedbackThread, len(threads))
+	for _, thread := range threads {
+		participants[thread.FeedbackID] = thread
+	}
+
+	summaries := make(map[string]*models.FeedbackThreadSummary)
+	questions := make(map[string]string) // Feedback of each question by comment ID
+	answered := make(map[string]bool)
+	for _, comment := range r.s.comments {
+		thread, ok := participants[comment.ContentRef]
+		if comment.Type != models.CommentTypeFeedback || !ok {
+			continue
+		}
+		summary := summaries[thread.FeedbackID]
+		if summary == nil {
+			summary = &models.FeedbackThreadSummary{}
+			summaries[thread.FeedbackID] = summary
+		}
+		summary.CommentCount++
+		if comment.UpdatedAt.After(summary.LastActivityAt) {
+			summary.LastActivityAt = comment.UpdatedAt
+		}
+		switch {
+		case isTopLevel(comment) && comment.UserID == thread.StudentID:
+			questions[comment.ID.Hex()] = thread.FeedbackID
+		case !isTopLevel(comment) && comment.UserID == thread.ReviewerID:
+			answered[*comment.ParentID] = true
+		}
+	}
+	for id, feedbackID := range questions {
+		if !answered[id] {
+			summaries[feedbackID].UnansweredQuestions++
+		}
+	}
+	return summaries, nil
+}
+
+func (r *commentRepository) MoveContentBatch(ctx context.Context, sourceContentID, targetContentID int64, contentType string, limit int) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var comments []*models.Comment
+	for _, comment := range r.s.comments {
+		if comment.ContentID == sourceContentID && comment.Type == contentType {
+			comments = append(comments, comment)
+		}
+	}
+	slices.SortFunc(comments, func(a, b *models.Comment) int {
+		if a.Depth != b.Depth {
+			return int(a.Depth - b.Depth)
+		}
+		return compareObjectIDs(a.ID, b.ID)
+	})
+	comments = comments[:min(limit, len(comments))]
+	changedAt := now()
+	for _, comment := range comments {
+		comment.ContentID = targetContentID
+		comment.ChangedAt = changedAt
+	}
+	return int64(len(comments)), nil
+}
+
+func (r *commentRepository) StartMerge(ctx context.Context, sourceContentID, targetContentID int64, contentType string, actorID int64) (*models.CommentMerge, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	id := fmt.Sprintf("%s:%d:%d", contentType, sourceContentID, targetContentID)
+	merge := r.s.merges[id]
+	if merge == nil {
+		merge = &models.CommentMerge{ID: id, SourceContentID: sourceContentID, TargetContentID: targetContentID, Type: contentType, StartedAt: now()}
+		r.s.merges[id] = merge
+	}
+	merge.ActorID = actorID
+	merge.Attempts++
+	copied := *merge
+	return &copied, nil
+}
+
+func (r *commentRepository) AddMergeProgress(ctx context.Context, mergeID string, moved int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if merge := r.s.merges[mergeID]; merge != nil {
+		merge.Moved += moved
+	}
+	return nil
+}
+
+func (r *commentRepository) CompleteMerge(ctx context.Context, mergeID string) (*models.CommentMerge, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	merge := r.s.merges[mergeID]
+	if merge == nil {
+		return nil, fmt.Errorf("failed to complete comment merge: merge %s not found", mergeID)
+	}
+	completedAt := now()
+	merge.CompletedAt = &completedAt
+	copied := *merge
+	return &copied, nil
+}
+
+func (r *commentRepository) EraseUserBatch(ctx context.Context, userID int64, mode string, limit int) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ids []primitive.ObjectID
+	for id, comment := range r.s.comments {
+		if comment.UserID == userID {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, compareObjectIDs)
+	ids = ids[:min(limit, len(ids))]
+	erasedAt := now()
+	for _, id := range ids {
+		comment := r.s.comments[id]
+		switch mode {
+		case models.CommentErasureAnonymize:
+			comment.Content = models.ErasedCommentContent
+		case models.CommentErasureDelete:
+			comment.Content = ""
+			comment.DeletedAt = &erasedAt
+		default:
+			return 0, fmt.Errorf("unknown erasure mode %q", mode)
+		}
+		comment.ErasedAt = &erasedAt
+		comment.UpdatedAt, comment.ChangedAt = erasedAt, erasedAt
+		comment.UserID = 0
+		comment.Edits = nil
+	}
+	if mode == models.CommentErasureDelete {
+		r.s.deleteDependents(ids)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *commentRepository) StartErasure(ctx context.Context, userID int64, mode string, actorID int64) (*models.CommentErasure, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	id := strconv.FormatInt(userID, 10)
+	erasure := r.s.erasures[id]
+	if erasure == nil {
+		erasure = &models.CommentErasure{ID: id, UserID: userID, StartedAt: now()}
+		r.s.erasures[id] = erasure
+	}
+	erasure.Mode, erasure.ActorID = mode, actorID
+	erasure.Attempts++
+	copied := *erasure
+	return &copied, nil
+}
+
+func (r *commentRepository) AddErasureProgress(ctx context.Context, erasureID string, erased int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if erasure := r.s.erasures[erasureID]; erasure != nil {
+		erasure.Erased += erased
+	}
+	return nil
+}
+
+func (r *commentRepository) CompleteErasure(ctx context.Context, erasureID string) (*models.CommentErasure, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	erasure := r.s.erasures[erasureID]
+	if erasure == nil {
+		return nil, fmt.Errorf("failed to complete comment erasure: erasure %s not found", erasureID)
+	}
+	completedAt := now()
+	erasure.CompletedAt = &completedAt
+	copied := *erasure
+	return &copied, nil
+}
+
+func (r *commentRepository) ChangeStreamsSupported(ctx context.Context) (bool, error) {
+	return false, nil
+}
+
+func (r *commentRepository) WatchChanges(ctx context.Context, opened func(), fn func(event models.CommentStreamEvent)) error {
+	return unsupported("WatchChanges")
+}
+
+// commentChange is a changed comment or a deletion record at its position in the list of changes
+type commentChange struct {
+	at     models.CommentChangeCursor
+	events []models.CommentStreamEvent
+}
+
+// changeEvent returns the event of a comment's last change, as the MongoDB repository does
+func changeEvent(comment *models.Comment) models.CommentStreamEvent {
+	kind := models.CommentEventUpdated
+	if comment.ChangedAt.Equal(comment.CreatedAt) {
+		kind = models.CommentEventCreated
+	}
+	return models.CommentStreamEvent{Kind: kind, Comment: comment, OccurredAt: comment.ChangedAt}
+}
+
+func (r *commentRepository) ListChanges(ctx context.Context, filter models.CommentChangeFilter) ([]models.CommentStreamEvent, *models.CommentChangeCursor, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	selected := func(at models.CommentChangeCursor, contentType string, contentID int64, contentRef string) bool {
+		if filter.After != nil {
+			if c := at.At.Compare(filter.After.At); c < 0 || (c == 0 && compareObjectIDs(at.ID, filter.After.ID) <= 0) {
+				return false
+			}
+		} else if at.At.Before(filter.Since) {
+			return false
+		}
+		return filter.Type == "" || onContent(&models.Comment{Type: contentType, ContentID: contentID, ContentRef: contentRef}, filter.Type, filter.ContentID, filter.ContentRef)
+	}
+
+	var changes []commentChange
+	for _, comment := range r.s.comments {
+		at := models.CommentChangeCursor{At: comment.ChangedAt, ID: comment.ID}
+		if selected(at, comment.Type, comment.ContentID, comment.ContentRef) {
+			changes = append(changes, commentChange{at: at, events: []models.CommentStreamEvent{changeEvent(cloneComment(comment, false))}})
+		}
+	}
+	for _, deletion := range r.s.deletions {
+		at := models.CommentChangeCursor{At: deletion.DeletedAt, ID: deletion.ID}
+		if selected(at, deletion.Type, deletion.ContentID, deletion.ContentRef) {
+			changes = append(changes, commentChange{at: at, events: deletion.Events()})
+		}
+	}
+	slices.SortFunc(changes, func(a, b commentChange) int {
+		if c := a.at.At.Compare(b.at.At); c != 0 {
+			return c
+		}
+		return compareObjectIDs(a.at.ID, b.at.ID)
+	})
+
+	more := len(changes) > filter.Limit
+	changes = changes[:min(filter.Limit, len(changes))]
+	var events []models.CommentStreamEvent
+	for _, change := range changes {
+		events = append(events, change.events...)
+	}
+	if !more {
+		return events, nil, nil
+	}
+	next := changes[len(changes)-1].at
+	return events, &next, nil
+}
+
+func (r *commentRepository) WithTransaction(ctx context.Context, fn func(repository.CommentTxRepository) error) error {
+	return fn(r)
+}
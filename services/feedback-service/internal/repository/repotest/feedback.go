@@ -0,0 +1,1069 @@
+package repotest
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// feedbackRow is a row of the feedbacks table; the feedback's Content is not part of it
+type feedbackRow struct {
+	feedback  models.Feedback
+	deletedAt *time.Time
+}
+
+// feedbackRepository implements repository.FeedbackRepository on a Store
+type feedbackRepository struct {
+	s *Store
+}
+
+// cloneFeedback returns a copy of a feedback that shares no slices or pointers with it
+func cloneFeedback(feedback *models.Feedback) *models.Feedback {
+	clone := *feedback
+	clone.RubricItems = slices.Clone(feedback.RubricItems)
+	if feedback.Grade != nil {
+		grade := *feedback.Grade
+		clone.Grade = &grade
+	}
+	if feedback.Snapshot != nil {
+		snapshot := *feedback.Snapshot
+		clone.Snapshot = &snapshot
+	}
+	return &clone
+}
+
+// compareIDs orders UUIDs as PostgreSQL does
+func compareIDs(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// liveFeedback returns the row of a feedback that is not deleted. The caller holds the lock.
+func (s *Store) liveFeedback(id uuid.UUID) *feedbackRow {
+	row := s.feedbacks[id]
+	if row == nil || row.deletedAt != nil {
+		return nil
+	}
+	return row
+}
+
+// activeFeedbackID returns the live feedback of a reviewer on a submission, or uuid.Nil. The
+// caller holds the lock.
+func (s *Store) activeFeedbackID(reviewerID, submissionID int64) uuid.UUID {
+	for id, row := range s.feedbacks {
+		if row.deletedAt == nil && row.feedback.ReviewerID == reviewerID && row.feedback.SubmissionID == submissionID {
+			return id
+		}
+	}
+	return uuid.Nil
+}
+
+// loadFeedback returns a copy of a row with its reaction counts and stored content. The caller
+// holds the lock.
+func (s *Store) loadFeedback(row *feedbackRow) *models.Feedback {
+	feedback := cloneFeedback(&row.feedback)
+	for _, reaction := range s.reactions[feedback.ID] {
+		feedback.CountReaction(reaction, 1)
+	}
+	feedback.Content = s.content(feedback.ID)
+	return feedback
+}
+
+// content returns the content of a feedback, preferring content pending in the outbox over the
+// stored one. The caller holds the lock.
+func (s *Store) content(id uuid.UUID) string {
+	if entry := s.outbox[id]; entry != nil {
+		return entry.Content
+	}
+	return s.contents[id]
+}
+
+// enqueueContent records content in the outbox, replacing pending content, and returns the
+// version of the entry. The caller holds the lock.
+func (s *Store) enqueueContent(id uuid.UUID, content string) int64 {
+	now := time.Now()
+	entry := s.outbox[id]
+	if entry == nil {
+		entry = &models.ContentOutboxEntry{FeedbackID: id, CreatedAt: now}
+		s.outbox[id] = entry
+	}
+	entry.Content = content
+	entry.Version++
+	entry.Attempts = 0
+	entry.LastError = ""
+	entry.NextAttemptAt = now
+	return entry.Version
+}
+
+// applyContent writes committed content and completes its outbox entry; a failure leaves the
+// entry for the outbox worker
+func (r *feedbackRepository) applyContent(ctx context.Context, id uuid.UUID, content string, version int64) {
+	if err := r.SetContent(ctx, id, content); err != nil {
+		return
+	}
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.completeContent(id, version)
+}
+
+// completeContent removes an outbox entry unless newer content was recorded since. The caller
+// holds the lock.
+func (s *Store) completeContent(id uuid.UUID, version int64) {
+	if entry := s.outbox[id]; entry != nil && entry.Version == version {
+		delete(s.outbox, id)
+	}
+}
+
+func (r *feedbackRepository) Create(ctx context.Context, feedback *models.Feedback) error {
+	feedback.ID = uuid.New()
+	now := time.Now()
+	feedback.CreatedAt = now
+	feedback.UpdatedAt = now
+	if feedback.IsPublished() {
+		feedback.PublishedAt = &now
+	} else {
+		feedback.Status = models.FeedbackDraft
+		feedback.PublishedAt = nil
+	}
+	feedback.ApprovalStatus = models.ApprovalNotRequired
+	feedback.ApproverID = nil
+	feedback.ApprovalNote = ""
+
+	r.s.mu.Lock()
+	if existing := r.s.activeFeedbackID(feedback.ReviewerID, feedback.SubmissionID); existing != uuid.Nil {
+		r.s.mu.Unlock()
+		return &repository.DuplicateFeedbackError{ExistingID: existing}
+	}
+	if feedback.IdempotencyKey != "" {
+		for _, row := range r.s.feedbacks {
+			if row.feedback.ReviewerID == feedback.ReviewerID && row.feedback.IdempotencyKey == feedback.IdempotencyKey {
+				r.s.mu.Unlock()
+				return repository.ErrIdempotencyKeyInUse
+			}
+		}
+	}
+	row := &feedbackRow{feedback: *cloneFeedback(feedback)}
+	row.feedback.Content = ""
+	r.s.feedbacks[feedback.ID] = row
+	var version int64
+	if feedback.Content != "" {
+		version = r.s.enqueueContent(feedback.ID, feedback.Content)
+	}
+	r.s.mu.Unlock()
+
+	if feedback.Content != "" {
+		r.applyContent(ctx, feedback.ID, feedback.Content, version)
+	}
+	return nil
+}
+
+func (r *feedbackRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Feedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.liveFeedback(id)
+	if row == nil {
+		return nil, repository.ErrFeedbackNotFound
+	}
+	return r.s.loadFeedback(row), nil
+}
+
+func (r *feedbackRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Feedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.batchReadErr != nil {
+		return nil, r.s.batchReadErr
+	}
+	feedbacks := make([]*models.Feedback, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if row := r.s.liveFeedback(id); row != nil && !seen[id] {
+			seen[id] = true
+			feedbacks = append(feedbacks, r.s.loadFeedback(row))
+		}
+	}
+	return feedbacks, nil
+}
+
+func (r *feedbackRepository) Update(ctx context.Context, feedback *models.Feedback, editorID int64, contentSet bool) error {
+	feedback.UpdatedAt = time.Now()
+
+	r.s.mu.Lock()
+	row := r.s.liveFeedback(feedback.ID)
+	if row == nil {
+		r.s.mu.Unlock()
+		return repository.ErrFeedbackNotFound
+	}
+	if row.feedback.RevisionCount == 0 {
+		r.s.revisions[feedback.ID] = append(r.s.revisions[feedback.ID], &models.FeedbackRevision{
+			FeedbackID: feedback.ID,
+			Title:      row.feedback.Title,
+			Content:    r.s.content(feedback.ID),
+			EditedAt:   row.feedback.CreatedAt,
+			EditedBy:   row.feedback.ReviewerID,
+		})
+	}
+	updated := cloneFeedback(feedback)
+	row.feedback.Title = updated.Title
+	row.feedback.Grade = updated.Grade
+	row.feedback.RubricItems = updated.RubricItems
+	row.feedback.RequiresResubmission = updated.RequiresResubmission
+	row.feedback.ResubmissionDeadline = updated.ResubmissionDeadline
+	row.feedback.UpdatedAt = updated.UpdatedAt
+	row.feedback.RevisionCount++
+	feedback.RevisionCount = row.feedback.RevisionCount
+
+	var version int64
+	if contentSet {
+		version = r.s.enqueueContent(feedback.ID, feedback.Content)
+	}
+	r.s.revisions[feedback.ID] = append(r.s.revisions[feedback.ID], &models.FeedbackRevision{
+		FeedbackID: feedback.ID,
+		Revision:   feedback.RevisionCount,
+		Title:      feedback.Title,
+		Content:    feedback.Content,
+		EditedAt:   feedback.UpdatedAt,
+		EditedBy:   editorID,
+	})
+	r.s.mu.Unlock()
+
+	if contentSet {
+		r.applyContent(ctx, feedback.ID, feedback.Content, version)
+	}
+	return nil
+}
+
+func (r *feedbackRepository) ListRevisions(ctx context.Context, feedbackID uuid.UUID, pageNumber, limit int32) ([]*models.FeedbackRevision, int32, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	revisions := slices.Clone(r.s.revisions[feedbackID])
+	slices.Reverse(revisions)
+	listed := make([]*models.FeedbackRevision, 0, limit)
+	for _, revision := range page(revisions, int(pageNumber), int(limit)) {
+		copied := *revision
+		listed = append(listed, &copied)
+	}
+	return listed, int32(len(revisions)), nil
+}
+
+func (r *feedbackRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.feedbacks[id] == nil {
+		return repository.ErrFeedbackNotFound
+	}
+	delete(r.s.feedbacks, id)
+	delete(r.s.revisions, id)
+	delete(r.s.reactions, id)
+	return nil
+}
+
+// matches reports whether a live row matches the optional predicates of a list filter. The
+// caller holds the lock.
+func (s *Store) matches(row *feedbackRow, filter models.FeedbackFilter) bool {
+	feedback := &row.feedback
+	switch {
+	case row.deletedAt != nil:
+		return false
+	case filter.SubmissionID != nil && feedback.SubmissionID != *filter.SubmissionID:
+		return false
+	case filter.CourseID != nil && feedback.CourseID != *filter.CourseID:
+		return false
+	case filter.Status != nil && feedback.Status != *filter.Status:
+		return false
+	case filter.ApprovedOnly && !feedback.IsApproved():
+		return false
+	case filter.UnreadOnly && feedback.ReadAt != nil:
+		return false
+	case filter.ResubmissionOnly && !feedback.RequiresResubmission:
+		return false
+	case !filter.IncludeArchived && feedback.ArchivedAt != nil:
+		return false
+	case filter.CreatedAfter != nil && feedback.CreatedAt.Before(*filter.CreatedAfter):
+		return false
+	case filter.CreatedBefore != nil && !feedback.CreatedAt.Before(*filter.CreatedBefore):
+		return false
+	}
+	if since := filter.ChangedSince; since != nil {
+		changed := !feedback.UpdatedAt.Before(*since) || (feedback.PublishedAt != nil && !feedback.PublishedAt.Before(*since))
+		for _, entry := range s.audit {
+			if entry.FeedbackID == feedback.ID && entry.Action == models.AuditActionApproved && !entry.CreatedAt.Before(*since) {
+				changed = true
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+
+	// Attachment filters are answered from the metadata rows, as in PostgreSQL, unless counted in MinIO
+	attachments := int64(len(s.assets[feedback.ID]))
+	if count, ok := filter.AttachmentCounts[feedback.ID]; ok {
+		attachments = count
+	}
+	if filter.HasAttachments != nil && (attachments > 0) != *filter.HasAttachments {
+		return false
+	}
+	if filter.MinAttachments != nil && attachments < int64(*filter.MinAttachments) {
+		return false
+	}
+	return true
+}
+
+// compareFeedbacks orders feedbacks by a sort field, ties broken by ID, ascending
+func compareFeedbacks(a, b *models.FeedbackCursor, field string) int {
+	var c int
+	switch field {
+	case models.FeedbackSortUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case models.FeedbackSortTitle:
+		c = strings.Compare(a.Title, b.Title)
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return compareIDs(a.ID, b.ID)
+}
+
+// list lists the live feedbacks selected by keep and the filter, in the filter's order, from
+// its cursor or page, with the total count
+func (r *feedbackRepository) list(filter models.FeedbackFilter, keep func(*models.Feedback) bool) ([]*models.Feedback, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var rows []*feedbackRow
+	for _, row := range r.s.feedbacks {
+		if keep(&row.feedback) && r.s.matches(row, filter) {
+			rows = append(rows, row)
+		}
+	}
+	field := filter.Sort.Field()
+	direction := -1
+	if filter.Sort.Ascending {
+		direction = 1
+	}
+	slices.SortFunc(rows, func(a, b *feedbackRow) int {
+		return direction * compareFeedbacks(models.CursorOf(&a.feedback, filter.Sort), models.CursorOf(&b.feedback, filter.Sort), field)
+	})
+
+	total := int64(len(rows))
+	if filter.After != nil {
+		start := len(rows)
+		for i, row := range rows {
+			if direction*compareFeedbacks(models.CursorOf(&row.feedback, filter.Sort), filter.After, field) > 0 {
+				start = i
+				break
+			}
+		}
+		rows = rows[start:]
+		if len(rows) > filter.Limit {
+			rows = rows[:filter.Limit]
+		}
+	} else {
+		rows = page(rows, filter.Page, filter.Limit)
+	}
+
+	feedbacks := make([]*models.Feedback, len(rows))
+	for i, row := range rows {
+		feedbacks[i] = r.s.loadFeedback(row)
+	}
+	return feedbacks, total, nil
+}
+
+func (r *feedbackRepository) ListByUser(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	return r.list(filter, func(feedback *models.Feedback) bool {
+		return filter.ReviewerID != nil && feedback.ReviewerID == *filter.ReviewerID
+	})
+}
+
+func (r *feedbackRepository) ListByStudent(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	return r.list(filter, func(feedback *models.Feedback) bool {
+		return filter.StudentID != nil && feedback.StudentID == *filter.StudentID
+	})
+}
+
+func (r *feedbackRepository) ListBySubmission(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	return r.list(filter, func(feedback *models.Feedback) bool {
+		return filter.SubmissionID != nil && feedback.SubmissionID == *filter.SubmissionID
+	})
+}
+
+func (r *feedbackRepository) List(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	return r.list(filter, func(feedback *models.Feedback) bool {
+		return (filter.ReviewerID == nil || feedback.ReviewerID == *filter.ReviewerID) &&
+			(filter.StudentID == nil || feedback.StudentID == *filter.StudentID)
+	})
+}
+
+func (r *feedbackRepository) ListAttachmentPending(ctx context.Context, filter models.FeedbackFilter, limit int) ([]uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	filter.HasAttachments, filter.MinAttachments, filter.AttachmentCounts = nil, nil, nil
+	var ids []uuid.UUID
+	for id, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		if (filter.ReviewerID != nil && feedback.ReviewerID != *filter.ReviewerID) ||
+			(filter.StudentID != nil && feedback.StudentID != *filter.StudentID) || !r.s.matches(row, filter) {
+			continue
+		}
+		if entry := r.s.backfill[id.String()]; entry == nil || !finished(entry) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, compareIDs)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *feedbackRepository) ListIDsBySubmission(ctx context.Context, submissionID int64, after uuid.UUID, limit int, includeDeleted bool) ([]uuid.UUID, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ids []uuid.UUID
+	for id, row := range r.s.feedbacks {
+		if row.feedback.SubmissionID == submissionID && compareIDs(id, after) > 0 && (includeDeleted || row.deletedAt == nil) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, compareIDs)
+	return ids[:min(limit, len(ids))], nil
+}
+
+func (r *feedbackRepository) SoftDelete(ctx context.Context, id uuid.UUID, actorID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.liveFeedback(id)
+	if row == nil {
+		return repository.ErrFeedbackNotFound
+	}
+	now := time.Now()
+	row.deletedAt = &now
+	return nil
+}
+
+// update applies change to a live feedback and reports whether it changed anything
+func (r *feedbackRepository) update(id uuid.UUID, change func(*models.Feedback) bool) bool {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.liveFeedback(id)
+	return row != nil && change(&row.feedback)
+}
+
+func (r *feedbackRepository) SetLock(ctx context.Context, id uuid.UUID, locked bool, reason string) (bool, error) {
+	return r.update(id, func(feedback *models.Feedback) bool {
+		if feedback.Locked == locked && feedback.LockReason == reason {
+			return false
+		}
+		feedback.Locked, feedback.LockReason = locked, reason
+		return true
+	}), nil
+}
+
+func (r *feedbackRepository) TransferOwnership(ctx context.Context, id uuid.UUID, fromReviewerID, toReviewerID int64, updatedAt time.Time) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.liveFeedback(id)
+	if row == nil || row.feedback.ReviewerID != fromReviewerID {
+		return false, nil
+	}
+	if existing := r.s.activeFeedbackID(toReviewerID, row.feedback.SubmissionID); existing != uuid.Nil && existing != id {
+		return false, &repository.DuplicateFeedbackError{ExistingID: existing}
+	}
+	row.feedback.ReviewerID = toReviewerID
+	row.feedback.UpdatedAt = updatedAt
+	return true, nil
+}
+
+func (r *feedbackRepository) SetArchived(ctx context.Context, id uuid.UUID, archived bool, archivedAt time.Time) (bool, error) {
+	return r.update(id, func(feedback *models.Feedback) bool {
+		if (feedback.ArchivedAt != nil) == archived {
+			return false
+		}
+		feedback.ArchivedAt = nil
+		if archived {
+			feedback.ArchivedAt = &archivedAt
+		}
+		return true
+	}), nil
+}
+
+func (r *feedbackRepository) ArchiveCreatedBefore(ctx context.Context, cutoff, archivedAt time.Time, limit int) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var rows []*feedbackRow
+	for _, row := range r.s.feedbacks {
+		if row.deletedAt == nil && row.feedback.ArchivedAt == nil && row.feedback.CreatedAt.Before(cutoff) {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b *feedbackRow) int {
+		return compareFeedbacks(models.CursorOf(&a.feedback, models.FeedbackSort{}), models.CursorOf(&b.feedback, models.FeedbackSort{}), models.FeedbackSortCreatedAt)
+	})
+	rows = rows[:min(limit, len(rows))]
+	for _, row := range rows {
+		row.feedback.ArchivedAt = &archivedAt
+	}
+	return int64(len(rows)), nil
+}
+
+func (r *feedbackRepository) Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) (bool, error) {
+	return r.update(id, func(feedback *models.Feedback) bool {
+		if feedback.Status == models.FeedbackPublished {
+			return false
+		}
+		feedback.Status, feedback.PublishedAt = models.FeedbackPublished, &publishedAt
+		return true
+	}), nil
+}
+
+func (r *feedbackRepository) Acknowledge(ctx context.Context, id uuid.UUID, readAt time.Time) (bool, error) {
+	return r.update(id, func(feedback *models.Feedback) bool {
+		if feedback.ReadAt != nil {
+			return false
+		}
+		feedback.ReadAt = &readAt
+		return true
+	}), nil
+}
+
+func (r *feedbackRepository) SetReaction(ctx context.Context, id uuid.UUID, userID int64, reaction string) (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.feedbacks[id] == nil {
+		return "", repository.ErrFeedbackNotFound
+	}
+	if r.s.reactions[id] == nil {
+		r.s.reactions[id] = make(map[int64]string)
+	}
+	previous := r.s.reactions[id][userID]
+	r.s.reactions[id][userID] = reaction
+	return previous, nil
+}
+
+func (r *feedbackRepository) RemoveReaction(ctx context.Context, id uuid.UUID, userID int64) (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	previous := r.s.reactions[id][userID]
+	delete(r.s.reactions[id], userID)
+	return previous, nil
+}
+
+func (r *feedbackRepository) TransitionApproval(ctx context.Context, id uuid.UUID, from, to string, approverID int64, note string) (bool, error) {
+	return r.update(id, func(feedback *models.Feedback) bool {
+		if feedback.ApprovalStatus != from {
+			return false
+		}
+		feedback.ApprovalStatus, feedback.ApproverID, feedback.ApprovalNote = to, &approverID, note
+		return true
+	}), nil
+}
+
+func (r *feedbackRepository) IsLocked(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.feedbacks[id]
+	if row == nil {
+		return false, repository.ErrFeedbackNotFound
+	}
+	return row.feedback.Locked, nil
+}
+
+func (r *feedbackRepository) SetNeedsReupload(ctx context.Context, id uuid.UUID, needsReupload bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.feedbacks[id]
+	if row == nil {
+		return repository.ErrFeedbackNotFound
+	}
+	row.feedback.NeedsReupload = needsReupload
+	return nil
+}
+
+func (r *feedbackRepository) UpdateSnapshots(ctx context.Context, updates []models.SubmissionSnapshotUpdate) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var updated int64
+	for _, update := range updates {
+		for _, row := range r.s.feedbacks {
+			if row.deletedAt != nil || row.feedback.SubmissionID != update.SubmissionID {
+				continue
+			}
+			snapshot := models.SubmissionSnapshot{}
+			if row.feedback.Snapshot != nil {
+				snapshot = *row.feedback.Snapshot
+			}
+			for _, field := range []struct {
+				stored *string
+				value  string
+			}{
+				{&snapshot.LabTitle, update.Snapshot.LabTitle},
+				{&snapshot.StudentDisplayName, update.Snapshot.StudentDisplayName},
+				{&snapshot.SubmissionLabel, update.Snapshot.SubmissionLabel},
+				{&snapshot.ReviewerDisplayName, update.Snapshot.ReviewerDisplayName},
+			} {
+				if field.value != "" {
+					*field.stored = field.value
+				}
+			}
+			row.feedback.Snapshot = &snapshot
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *feedbackRepository) ListRefsAfter(ctx context.Context, after uuid.UUID, limit int) ([]models.FeedbackRef, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var refs []models.FeedbackRef
+	for id, row := range r.s.feedbacks {
+		if compareIDs(id, after) > 0 {
+			refs = append(refs, models.FeedbackRef{ID: id, Deleted: row.deletedAt != nil})
+		}
+	}
+	slices.SortFunc(refs, func(a, b models.FeedbackRef) int { return compareIDs(a.ID, b.ID) })
+	return refs[:min(limit, len(refs))], nil
+}
+
+func (r *feedbackRepository) ListTitleCandidates(ctx context.Context, reviewerID, submissionID int64, since time.Time, minLength, maxLength, limit int) ([]*models.SimilarFeedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var candidates []*models.SimilarFeedback
+	for _, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		length := len([]rune(feedback.Title))
+		if row.deletedAt != nil || feedback.ReviewerID != reviewerID || length < minLength || length > maxLength {
+			continue
+		}
+		if feedback.SubmissionID != submissionID && feedback.CreatedAt.Before(since) {
+			continue
+		}
+		candidates = append(candidates, &models.SimilarFeedback{
+			ID:           feedback.ID,
+			StudentID:    feedback.StudentID,
+			SubmissionID: feedback.SubmissionID,
+			Title:        feedback.Title,
+			CreatedAt:    feedback.CreatedAt,
+		})
+	}
+	slices.SortFunc(candidates, func(a, b *models.SimilarFeedback) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(b.ID, a.ID)
+	})
+	return candidates[:min(limit, len(candidates))], nil
+}
+
+// truncateToBucket returns the start of the UTC bucket containing t, as date_trunc does
+func truncateToBucket(t time.Time, size string) time.Time {
+	t = t.UTC()
+	switch size {
+	case models.RateBucketMinute:
+		return t.Truncate(time.Minute)
+	case models.RateBucketHour:
+		return t.Truncate(time.Hour)
+	case models.RateBucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// countByBucket counts times in [since, until) by bucket, non-empty buckets in time order
+func countByBucket(times []time.Time, bucketSize string, since, until time.Time) []*models.RateBucket {
+	counts := make(map[time.Time]int64)
+	for _, t := range times {
+		if !t.Before(since) && t.Before(until) {
+			counts[truncateToBucket(t, bucketSize)]++
+		}
+	}
+	buckets := make([]*models.RateBucket, 0, len(counts))
+	for start, count := range counts {
+		buckets = append(buckets, &models.RateBucket{Start: start, Count: count})
+	}
+	slices.SortFunc(buckets, func(a, b *models.RateBucket) int { return a.Start.Compare(b.Start) })
+	return buckets
+}
+
+func (r *feedbackRepository) CountCreatedByBucket(ctx context.Context, reviewerID, submissionID *int64, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var times []time.Time
+	for _, row := range r.s.feedbacks {
+		if (reviewerID == nil || row.feedback.ReviewerID == *reviewerID) && (submissionID == nil || row.feedback.SubmissionID == *submissionID) {
+			times = append(times, row.feedback.CreatedAt)
+		}
+	}
+	return countByBucket(times, bucketSize, since, until), nil
+}
+
+func (r *feedbackRepository) CountBySubmissionReviewer(ctx context.Context, submissionIDs []int64) ([]models.ReviewerFeedbackCount, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	counts := make(map[[2]int64]*models.ReviewerFeedbackCount)
+	for _, row := range r.s.feedbacks {
+		if row.deletedAt != nil || !slices.Contains(submissionIDs, row.feedback.SubmissionID) {
+			continue
+		}
+		key := [2]int64{row.feedback.SubmissionID, row.feedback.ReviewerID}
+		if counts[key] == nil {
+			counts[key] = &models.ReviewerFeedbackCount{SubmissionID: key[0], ReviewerID: key[1]}
+		}
+		counts[key].Count++
+		switch row.feedback.Status {
+		case models.FeedbackPublished:
+			counts[key].PublishedCount++
+		case models.FeedbackDraft:
+			counts[key].DraftCount++
+		}
+	}
+	var result []models.ReviewerFeedbackCount
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	slices.SortFunc(result, func(a, b models.ReviewerFeedbackCount) int {
+		if a.SubmissionID != b.SubmissionID {
+			return compareInt64(a.SubmissionID, b.SubmissionID)
+		}
+		return compareInt64(a.ReviewerID, b.ReviewerID)
+	})
+	return result, nil
+}
+
+// compareInt64 orders int64 values ascending
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Search matches the words of the query, less quotes and excluded words, case-insensitively
+// against the title and stored content. Title matches rank above content matches.
+func (r *feedbackRepository) Search(ctx context.Context, filter models.FeedbackSearchFilter) ([]*models.FeedbackSearchResult, int32, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(filter.Query, `"`, " "))) {
+		if word != "or" && !strings.HasPrefix(word, "-") {
+			words = append(words, word)
+		}
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var results []*models.FeedbackSearchResult
+	for _, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		switch {
+		case row.deletedAt != nil, len(words) == 0:
+			continue
+		case filter.ReviewerID != nil && feedback.ReviewerID != *filter.ReviewerID:
+			continue
+		case filter.StudentID != nil && feedback.StudentID != *filter.StudentID:
+			continue
+		case filter.SubmissionID != nil && feedback.SubmissionID != *filter.SubmissionID:
+			continue
+		case filter.Status != nil && feedback.Status != *filter.Status:
+			continue
+		case filter.ApprovedOnly && !feedback.IsApproved():
+			continue
+		}
+		title, content := strings.ToLower(feedback.Title), strings.ToLower(r.s.contents[feedback.ID])
+		inTitle, inText := true, true
+		for _, word := range words {
+			inTitle = inTitle && strings.Contains(title, word)
+			inText = inText && (strings.Contains(title, word) || strings.Contains(content, word))
+		}
+		if !inText {
+			continue
+		}
+		result := &models.FeedbackSearchResult{Feedback: r.s.loadFeedback(row), Rank: 0.5, Snippet: feedback.Title}
+		if inTitle {
+			result.Rank = 1
+		}
+		results = append(results, result)
+	}
+	slices.SortFunc(results, func(a, b *models.FeedbackSearchResult) int {
+		if a.Rank != b.Rank {
+			return compareInt64(int64(b.Rank*2), int64(a.Rank*2))
+		}
+		return compareIDs(a.Feedback.ID, b.Feedback.ID)
+	})
+	return page(results, filter.Page, filter.Limit), int32(len(results)), nil
+}
+
+func (r *feedbackRepository) Stats(ctx context.Context, filter models.FeedbackStatsFilter) (*models.FeedbackStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stats := &models.FeedbackStats{PerSubmission: []models.SubmissionFeedbackCount{}}
+	perSubmission := make(map[int64]int64)
+	var contentLength int64
+	for _, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		switch {
+		case row.deletedAt != nil:
+			continue
+		case filter.ReviewerID != nil && feedback.ReviewerID != *filter.ReviewerID:
+			continue
+		case filter.StudentID != nil && feedback.StudentID != *filter.StudentID:
+			continue
+		case filter.VisibleOnly && !feedback.IsVisibleToStudent():
+			continue
+		}
+		stats.Total++
+		if !feedback.CreatedAt.Before(filter.Since7Days) {
+			stats.CreatedLast7Days++
+		}
+		if !feedback.CreatedAt.Before(filter.Since30Days) {
+			stats.CreatedLast30Days++
+		}
+		contentLength += int64(len([]rune(r.s.contents[feedback.ID])))
+		perSubmission[feedback.SubmissionID]++
+	}
+	if stats.Total == 0 {
+		return stats, nil
+	}
+	stats.AverageContentLength = float64(contentLength) / float64(stats.Total)
+	stats.SubmissionCount = int64(len(perSubmission))
+	for submissionID, count := range perSubmission {
+		stats.PerSubmission = append(stats.PerSubmission, models.SubmissionFeedbackCount{SubmissionID: submissionID, Count: count})
+	}
+	slices.SortFunc(stats.PerSubmission, func(a, b models.SubmissionFeedbackCount) int {
+		if a.Count != b.Count {
+			return compareInt64(b.Count, a.Count)
+		}
+		return compareInt64(a.SubmissionID, b.SubmissionID)
+	})
+	stats.PerSubmission = stats.PerSubmission[:min(max(filter.MaxSubmissions, 0), len(stats.PerSubmission))]
+	return stats, nil
+}
+
+// summaryTotals accumulates the counts of a student feedback summary
+type summaryTotals struct {
+	models.SubmissionFeedbackSummary
+	ratioSum float64
+}
+
+func (t *summaryTotals) add(feedback *models.Feedback) {
+	t.FeedbackCount++
+	if feedback.ReadAt == nil {
+		t.UnreadCount++
+	}
+	at := feedback.CreatedAt
+	if feedback.PublishedAt != nil {
+		at = *feedback.PublishedAt
+	}
+	if at.After(t.LatestFeedbackAt) {
+		t.LatestFeedbackAt = at
+	}
+	if feedback.Grade != nil && feedback.Grade.MaxPoints > 0 {
+		t.GradedCount++
+		t.ratioSum += feedback.Grade.Points / feedback.Grade.MaxPoints
+		average := t.ratioSum / float64(t.GradedCount)
+		t.AverageGradeRatio = &average
+	}
+}
+
+func (r *feedbackRepository) StudentSummary(ctx context.Context, studentID int64) (*models.StudentFeedbackSummary, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var overall summaryTotals
+	perSubmission := make(map[int64]*summaryTotals)
+	for _, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		if row.deletedAt != nil || feedback.StudentID != studentID || !feedback.IsVisibleToStudent() {
+			continue
+		}
+		overall.add(feedback)
+		totals := perSubmission[feedback.SubmissionID]
+		if totals == nil {
+			totals = &summaryTotals{}
+			totals.SubmissionID = feedback.SubmissionID
+			perSubmission[feedback.SubmissionID] = totals
+		}
+		totals.add(feedback)
+	}
+
+	summary := &models.StudentFeedbackSummary{
+		Submissions:       []models.SubmissionFeedbackSummary{},
+		FeedbackCount:     overall.FeedbackCount,
+		UnreadCount:       overall.UnreadCount,
+		GradedCount:       overall.GradedCount,
+		AverageGradeRatio: overall.AverageGradeRatio,
+	}
+	if overall.FeedbackCount > 0 {
+		latest := overall.LatestFeedbackAt
+		summary.LatestFeedbackAt = &latest
+	}
+	for _, totals := range perSubmission {
+		summary.Submissions = append(summary.Submissions, totals.SubmissionFeedbackSummary)
+	}
+	slices.SortFunc(summary.Submissions, func(a, b models.SubmissionFeedbackSummary) int {
+		if c := b.LatestFeedbackAt.Compare(a.LatestFeedbackAt); c != 0 {
+			return c
+		}
+		return compareInt64(a.SubmissionID, b.SubmissionID)
+	})
+	return summary, nil
+}
+
+func (r *feedbackRepository) StudentLastActivity(ctx context.Context, filter models.FeedbackFilter) (*time.Time, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var last *time.Time
+	for _, row := range r.s.feedbacks {
+		feedback := &row.feedback
+		if filter.StudentID == nil || feedback.StudentID != *filter.StudentID || !r.s.matches(row, filter) {
+			continue
+		}
+		activity := feedback.CreatedAt
+		for _, at := range []*time.Time{feedback.PublishedAt, &feedback.UpdatedAt, feedback.ReadAt} {
+			if at != nil && at.After(activity) {
+				activity = *at
+			}
+		}
+		if last == nil || activity.After(*last) {
+			last = &activity
+		}
+	}
+	return last, nil
+}
+
+// IndexContent does nothing; Search reads the stored content directly
+func (r *feedbackRepository) IndexContent(ctx context.Context, id uuid.UUID, content string) error {
+	return nil
+}
+
+func (r *feedbackRepository) GetByIdempotencyKey(ctx context.Context, reviewerID int64, key string) (*models.IdempotencyRecord, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, row := range r.s.feedbacks {
+		if row.feedback.ReviewerID == reviewerID && row.feedback.IdempotencyKey == key {
+			return &models.IdempotencyRecord{
+				FeedbackID:  row.feedback.ID,
+				Fingerprint: row.feedback.IdempotencyFingerprint,
+				CreatedAt:   row.feedback.CreatedAt,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *feedbackRepository) ClearIdempotencyKey(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if row := r.s.feedbacks[id]; row != nil {
+		row.feedback.IdempotencyKey, row.feedback.IdempotencyFingerprint = "", ""
+	}
+	return nil
+}
+
+func (r *feedbackRepository) SetContent(ctx context.Context, id uuid.UUID, content string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.contentErr != nil {
+		return r.s.contentErr
+	}
+	r.s.contents[id] = content
+	return nil
+}
+
+func (r *feedbackRepository) GetContent(ctx context.Context, id uuid.UUID) (string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.content(id), nil
+}
+
+func (r *feedbackRepository) GetContents(ctx context.Context, ids []string) (map[string]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	contents := make(map[string]string)
+	for _, id := range ids {
+		parsed, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		if entry := r.s.outbox[parsed]; entry != nil {
+			contents[id] = entry.Content
+		} else if content, ok := r.s.contents[parsed]; ok {
+			contents[id] = content
+		}
+	}
+	return contents, nil
+}
+
+func (r *feedbackRepository) DeleteContent(ctx context.Context, id uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.contents[id]; !ok {
+		return 0, nil
+	}
+	delete(r.s.contents, id)
+	return 1, nil
+}
+
+// outboxRepository implements repository.ContentOutboxRepository on a Store
+type outboxRepository struct {
+	s *Store
+}
+
+func (r *outboxRepository) ListPending(ctx context.Context, feedbackID uuid.UUID, after uuid.UUID, due bool, limit int) ([]*models.ContentOutboxEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := time.Now()
+	var entries []*models.ContentOutboxEntry
+	for id, entry := range r.s.outbox {
+		if compareIDs(id, after) <= 0 || (feedbackID != uuid.Nil && id != feedbackID) || (due && entry.NextAttemptAt.After(now)) {
+			continue
+		}
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	slices.SortFunc(entries, func(a, b *models.ContentOutboxEntry) int { return compareIDs(a.FeedbackID, b.FeedbackID) })
+	return entries[:min(limit, len(entries))], nil
+}
+
+func (r *outboxRepository) Complete(ctx context.Context, entry *models.ContentOutboxEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.completeContent(entry.FeedbackID, entry.Version)
+	return nil
+}
+
+func (r *outboxRepository) RecordFailure(ctx context.Context, entry *models.ContentOutboxEntry, message string, retryIn time.Duration) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if stored := r.s.outbox[entry.FeedbackID]; stored != nil && stored.Version == entry.Version {
+		stored.Attempts++
+		stored.LastError = message
+		stored.NextAttemptAt = time.Now().Add(retryIn)
+	}
+	return nil
+}
+
+func (r *outboxRepository) Count(ctx context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.outbox)), nil
+}
+
+func (r *outboxRepository) DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.outbox[feedbackID] == nil {
+		return 0, nil
+	}
+	delete(r.s.outbox, feedbackID)
+	return 1, nil
+}
@@ -0,0 +1,474 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// intentRepository implements repository.DeletionIntentRepository on a Store
+type intentRepository struct {
+	s *Store
+}
+
+func (r *intentRepository) Begin(ctx context.Context, intent *models.DeletionIntent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	row := r.s.feedbacks[intent.FeedbackID]
+	existing := r.s.intents[intent.FeedbackID]
+	if row == nil && existing == nil {
+		return repository.ErrFeedbackNotFound
+	}
+	now := time.Now().UTC()
+	if row != nil && row.deletedAt == nil {
+		row.deletedAt = &now
+	}
+	if existing != nil {
+		existing.UpdatedAt = now
+		return nil
+	}
+	stored := *intent
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.s.intents[intent.FeedbackID] = &stored
+	return nil
+}
+
+func (r *intentRepository) ListStale(ctx context.Context, olderThan time.Duration, limit int) ([]*models.DeletionIntent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cutoff := time.Now().Add(-olderThan)
+	var intents []*models.DeletionIntent
+	for _, intent := range r.s.intents {
+		if intent.UpdatedAt.Before(cutoff) {
+			copied := *intent
+			intents = append(intents, &copied)
+		}
+	}
+	slices.SortFunc(intents, func(a, b *models.DeletionIntent) int {
+		if c := a.UpdatedAt.Compare(b.UpdatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.FeedbackID, b.FeedbackID)
+	})
+	return intents[:min(limit, len(intents))], nil
+}
+
+func (r *intentRepository) RecordFailure(ctx context.Context, feedbackID uuid.UUID, message string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if intent := r.s.intents[feedbackID]; intent != nil {
+		intent.Attempts++
+		intent.LastError = message
+		intent.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+func (r *intentRepository) Complete(ctx context.Context, feedbackID uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.intents, feedbackID)
+	return nil
+}
+
+// auditRepository implements repository.AuditRepository on a Store
+type auditRepository struct {
+	s *Store
+}
+
+func (r *auditRepository) Record(ctx context.Context, entry *models.AuditEntry) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	r.s.nextAudit++
+	entry.ID = r.s.nextAudit
+	copied := *entry
+	r.s.audit = append(r.s.audit, &copied)
+	return nil
+}
+
+func (r *auditRepository) ListByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]*models.AuditEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var entries []*models.AuditEntry
+	for _, entry := range r.s.audit {
+		if entry.FeedbackID == feedbackID {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b *models.AuditEntry) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return entries, nil
+}
+
+// sealRepository implements repository.SealRepository on a Store
+type sealRepository struct {
+	s *Store
+}
+
+func (r *sealRepository) Append(ctx context.Context, seal *models.FeedbackSeal) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var previous string
+	if len(r.s.seals) > 0 {
+		previous = r.s.seals[len(r.s.seals)-1].ChainSHA256
+	}
+	r.s.nextSealID++
+	seal.ID = r.s.nextSealID
+	seal.PreviousSHA256 = previous
+	seal.ChainSHA256 = repository.SealChainHash(previous, seal.ManifestSHA256)
+	seal.SealedAt = time.Now().UTC()
+	copied := *seal
+	r.s.seals = append(r.s.seals, &copied)
+	return nil
+}
+
+func (r *sealRepository) Latest(ctx context.Context, feedbackID uuid.UUID) (*models.FeedbackSeal, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := len(r.s.seals) - 1; i >= 0; i-- {
+		if r.s.seals[i].FeedbackID == feedbackID {
+			copied := *r.s.seals[i]
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrFeedbackSealNotFound
+}
+
+// templateRepository implements repository.TemplateRepository on a Store
+type templateRepository struct {
+	s *Store
+}
+
+// nameTaken reports whether another template of the reviewer has the name. The caller holds the lock.
+func (s *Store) nameTaken(template *models.FeedbackTemplate) bool {
+	for id, stored := range s.templates {
+		if id != template.ID && stored.ReviewerID == template.ReviewerID && stored.Name == template.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *templateRepository) Create(ctx context.Context, template *models.FeedbackTemplate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	template.ID = uuid.New()
+	template.CreatedAt = time.Now().UTC()
+	template.UpdatedAt = template.CreatedAt
+	if r.s.nameTaken(template) {
+		return repository.ErrTemplateNameTaken
+	}
+	copied := *template
+	r.s.templates[template.ID] = &copied
+	return nil
+}
+
+func (r *templateRepository) Get(ctx context.Context, id uuid.UUID) (*models.FeedbackTemplate, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.templates[id]
+	if stored == nil {
+		return nil, repository.ErrTemplateNotFound
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *templateRepository) ListByReviewer(ctx context.Context, reviewerID int64, pageNumber, limit int32) ([]*models.FeedbackTemplate, int32, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var templates []*models.FeedbackTemplate
+	for _, stored := range r.s.templates {
+		if stored.ReviewerID == reviewerID {
+			copied := *stored
+			templates = append(templates, &copied)
+		}
+	}
+	slices.SortFunc(templates, func(a, b *models.FeedbackTemplate) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	return page(templates, int(pageNumber), int(limit)), int32(len(templates)), nil
+}
+
+func (r *templateRepository) Update(ctx context.Context, template *models.FeedbackTemplate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.templates[template.ID]
+	if stored == nil {
+		return repository.ErrTemplateNotFound
+	}
+	if r.s.nameTaken(template) {
+		return repository.ErrTemplateNameTaken
+	}
+	template.UpdatedAt = time.Now().UTC()
+	stored.Name, stored.Title, stored.Content, stored.UpdatedAt = template.Name, template.Title, template.Content, template.UpdatedAt
+	return nil
+}
+
+func (r *templateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.templates[id] == nil {
+		return repository.ErrTemplateNotFound
+	}
+	delete(r.s.templates, id)
+	return nil
+}
+
+// policyRepository implements repository.CoursePolicyRepository on a Store
+type policyRepository struct {
+	s *Store
+}
+
+func (r *policyRepository) Get(ctx context.Context, courseID string) (*models.CoursePolicy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.policies[courseID]
+	if stored == nil {
+		return nil, nil
+	}
+	copied := *stored
+	return &copied, nil
+}
+
+func (r *policyRepository) List(ctx context.Context) ([]*models.CoursePolicy, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var policies []*models.CoursePolicy
+	for _, stored := range r.s.policies {
+		copied := *stored
+		policies = append(policies, &copied)
+	}
+	slices.SortFunc(policies, func(a, b *models.CoursePolicy) int { return strings.Compare(a.CourseID, b.CourseID) })
+	return policies, nil
+}
+
+func (r *policyRepository) Upsert(ctx context.Context, policy *models.CoursePolicy) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := time.Now().UTC()
+	policy.CreatedAt = now
+	if stored := r.s.policies[policy.CourseID]; stored != nil {
+		policy.CreatedAt = stored.CreatedAt
+	}
+	policy.UpdatedAt = now
+	copied := *policy
+	r.s.policies[policy.CourseID] = &copied
+	return nil
+}
+
+func (r *policyRepository) Delete(ctx context.Context, courseID string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	_, ok := r.s.policies[courseID]
+	delete(r.s.policies, courseID)
+	return ok, nil
+}
+
+// usageKey identifies a daily transfer counter
+type usageKey struct {
+	principalID int64
+	day         time.Time
+}
+
+// transferRepository implements repository.TransferUsageRepository on a Store
+type transferRepository struct {
+	s *Store
+}
+
+func (r *transferRepository) AddUsage(ctx context.Context, deltas []*models.TransferUsage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, delta := range deltas {
+		key := usageKey{delta.PrincipalID, delta.Day.UTC()}
+		usage := r.s.usage[key]
+		if usage == nil {
+			usage = &models.TransferUsage{PrincipalID: delta.PrincipalID, Day: key.day}
+			r.s.usage[key] = usage
+		}
+		usage.BytesUploaded += delta.BytesUploaded
+		usage.BytesDownloaded += delta.BytesDownloaded
+	}
+	return nil
+}
+
+// usageBetween returns the counters of the days between since and until. The caller holds the lock.
+func (s *Store) usageBetween(since, until time.Time) []*models.TransferUsage {
+	var usage []*models.TransferUsage
+	for key, counter := range s.usage {
+		if !key.day.Before(since) && !key.day.After(until) {
+			copied := *counter
+			usage = append(usage, &copied)
+		}
+	}
+	return usage
+}
+
+func (r *transferRepository) GetUsage(ctx context.Context, principalID int64, since, until time.Time) ([]*models.TransferUsage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	usage := slices.DeleteFunc(r.s.usageBetween(since, until), func(u *models.TransferUsage) bool { return u.PrincipalID != principalID })
+	slices.SortFunc(usage, func(a, b *models.TransferUsage) int { return a.Day.Compare(b.Day) })
+	return usage, nil
+}
+
+func (r *transferRepository) TopPrincipals(ctx context.Context, since, until time.Time, limit int) ([]*models.TransferUsage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	totals := make(map[int64]*models.TransferUsage)
+	var principals []*models.TransferUsage
+	for _, usage := range r.s.usageBetween(since, until) {
+		total := totals[usage.PrincipalID]
+		if total == nil {
+			total = &models.TransferUsage{PrincipalID: usage.PrincipalID, Day: usage.Day}
+			totals[usage.PrincipalID] = total
+			principals = append(principals, total)
+		}
+		if usage.Day.Before(total.Day) {
+			total.Day = usage.Day
+		}
+		total.BytesUploaded += usage.BytesUploaded
+		total.BytesDownloaded += usage.BytesDownloaded
+	}
+	slices.SortFunc(principals, func(a, b *models.TransferUsage) int {
+		if c := compareInt64(b.BytesUploaded+b.BytesDownloaded, a.BytesUploaded+a.BytesDownloaded); c != 0 {
+			return c
+		}
+		return compareInt64(a.PrincipalID, b.PrincipalID)
+	})
+	return principals[:min(limit, len(principals))], nil
+}
+
+// retentionRow is a row of a retention dataset
+type retentionRow struct {
+	key       string
+	at        time.Time
+	protected bool
+	prune     func() // Deletes or clears the row; called with the lock held
+}
+
+// retentionRepository implements repository.RetentionRepository on a Store
+type retentionRepository struct {
+	s *Store
+}
+
+// rows returns the rows of a dataset, oldest first. The caller holds the lock.
+func (r *retentionRepository) rows(dataset string) ([]retentionRow, error) {
+	var rows []retentionRow
+	switch dataset {
+	case models.RetentionUploadSessions:
+		for id, session := range r.s.sessions {
+			rows = append(rows, retentionRow{
+				key:       id.String(),
+				at:        session.UpdatedAt,
+				protected: session.Status == models.UploadSessionOpen || session.Status == models.UploadSessionCommitting,
+				prune:     func() { delete(r.s.sessions, id) },
+			})
+		}
+	case models.RetentionAuditLog:
+		for _, entry := range r.s.audit {
+			rows = append(rows, retentionRow{
+				key: strconv.FormatInt(entry.ID, 10),
+				at:  entry.CreatedAt,
+				prune: func() {
+					r.s.audit = slices.DeleteFunc(r.s.audit, func(e *models.AuditEntry) bool { return e == entry })
+				},
+			})
+		}
+	case models.RetentionTransferUsage:
+		for key := range r.s.usage {
+			rows = append(rows, retentionRow{
+				key:   fmt.Sprintf("%d/%s", key.principalID, key.day.Format(time.DateOnly)),
+				at:    key.day,
+				prune: func() { delete(r.s.usage, key) },
+			})
+		}
+	case models.RetentionIdempotencyKeys:
+		for id, row := range r.s.feedbacks {
+			if row.feedback.IdempotencyKey == "" {
+				continue
+			}
+			rows = append(rows, retentionRow{
+				key: id.String(),
+				at:  row.feedback.CreatedAt,
+				prune: func() {
+					row.feedback.IdempotencyKey = ""
+					row.feedback.IdempotencyFingerprint = ""
+				},
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unknown retention dataset %q", dataset)
+	}
+	slices.SortFunc(rows, func(a, b retentionRow) int {
+		if c := a.at.Compare(b.at); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key, b.key)
+	})
+	return rows, nil
+}
+
+func (r *retentionRepository) PruneBatch(ctx context.Context, dataset string, cutoff time.Time, limit int, force bool) ([]string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rows, err := r.rows(dataset)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, row := range rows {
+		if len(keys) == limit {
+			break
+		}
+		if row.at.Before(cutoff) && (force || !row.protected) {
+			row.prune()
+			keys = append(keys, row.key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *retentionRepository) CountExpired(ctx context.Context, dataset string, cutoff time.Time, force bool) (int64, int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rows, err := r.rows(dataset)
+	if err != nil {
+		return 0, 0, err
+	}
+	var expired, protected int64
+	for _, row := range rows {
+		switch {
+		case !row.at.Before(cutoff):
+		case force || !row.protected:
+			expired++
+		default:
+			protected++
+		}
+	}
+	return expired, protected, nil
+}
+
+func (r *retentionRepository) Oldest(ctx context.Context, dataset string) (*time.Time, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rows, err := r.rows(dataset)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return &rows[0].at, nil
+}
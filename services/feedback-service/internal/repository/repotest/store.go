@@ -0,0 +1,245 @@
+// Package repotest provides in-memory implementations of the repository interfaces for tests.
+// A Store holds the state of every repository behind one lock, so the repositories see each
+// other's writes as the PostgreSQL, MongoDB and MinIO ones do: a feedback row, its content
+// document, its outbox entry, its objects and their metadata rows are separate records that
+// can be made to disagree, as they can in production.
+package repotest
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Store is the shared state of the in-memory repositories. The zero value is not usable; call New.
+type Store struct {
+	mu sync.Mutex
+
+	// PostgreSQL
+	feedbacks  map[uuid.UUID]*feedbackRow
+	revisions  map[uuid.UUID][]*models.FeedbackRevision
+	reactions  map[uuid.UUID]map[int64]string
+	outbox     map[uuid.UUID]*models.ContentOutboxEntry
+	assets     map[uuid.UUID]map[string]*assetRow
+	audit      []*models.AuditEntry
+	seals      []*models.FeedbackSeal
+	intents    map[uuid.UUID]*models.DeletionIntent
+	sessions   map[uuid.UUID]*models.UploadSession
+	backfill   map[string]*models.BackfillEntry
+	listing    models.BackfillProgress // Only PrefixesTotal and Complete are kept
+	templates  map[uuid.UUID]*models.FeedbackTemplate
+	policies   map[string]*models.CoursePolicy
+	usage      map[usageKey]*models.TransferUsage
+	nextAudit  int64
+	nextSealID int64
+
+	// MongoDB
+	contents         map[uuid.UUID]string
+	comments         map[primitive.ObjectID]*models.Comment
+	commentReactions map[primitive.ObjectID]map[int64]string
+	deletions        []*models.CommentDeletion
+	reports          map[reportKey]*models.CommentReport
+	merges           map[string]*models.CommentMerge
+	erasures         map[string]*models.CommentErasure
+
+	// MinIO
+	objects map[string]*object
+
+	contentErr     error            // Returned by SetContent while set
+	assetDeleteErr error            // Returned by AssetRepository.Delete while set
+	assetUpsertErr error            // Returned by AssetRepository.Upsert while set
+	summaryErr     error            // Returned by SummarizeFeedbackThreads while set
+	batchReadErr   error            // Returned by FeedbackRepository.GetByIDs while set
+	promoteErr     map[string]error // Returned by PromoteStaged for a filename while set
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{
+		feedbacks:        make(map[uuid.UUID]*feedbackRow),
+		revisions:        make(map[uuid.UUID][]*models.FeedbackRevision),
+		reactions:        make(map[uuid.UUID]map[int64]string),
+		outbox:           make(map[uuid.UUID]*models.ContentOutboxEntry),
+		assets:           make(map[uuid.UUID]map[string]*assetRow),
+		intents:          make(map[uuid.UUID]*models.DeletionIntent),
+		sessions:         make(map[uuid.UUID]*models.UploadSession),
+		backfill:         make(map[string]*models.BackfillEntry),
+		templates:        make(map[uuid.UUID]*models.FeedbackTemplate),
+		policies:         make(map[string]*models.CoursePolicy),
+		usage:            make(map[usageKey]*models.TransferUsage),
+		contents:         make(map[uuid.UUID]string),
+		comments:         make(map[primitive.ObjectID]*models.Comment),
+		commentReactions: make(map[primitive.ObjectID]map[int64]string),
+		reports:          make(map[reportKey]*models.CommentReport),
+		merges:           make(map[string]*models.CommentMerge),
+		erasures:         make(map[string]*models.CommentErasure),
+		objects:          make(map[string]*object),
+	}
+}
+
+// FailContentWrites makes SetContent fail with err, as when MongoDB is unavailable; nil lets
+// content writes succeed again
+func (s *Store) FailContentWrites(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contentErr = err
+}
+
+// FailThreadSummaries makes SummarizeFeedbackThreads fail with err, as when MongoDB is
+// unavailable; nil lets summaries succeed again
+func (s *Store) FailThreadSummaries(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaryErr = err
+}
+
+// FailBatchFeedbackReads makes FeedbackRepository.GetByIDs fail with err, as when PostgreSQL
+// is unavailable; nil lets batch reads succeed again
+func (s *Store) FailBatchFeedbackReads(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchReadErr = err
+}
+
+// FailAssetDeletes makes AssetRepository.Delete fail with err; nil lets deletes succeed again
+func (s *Store) FailAssetDeletes(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assetDeleteErr = err
+}
+
+// FailAssetUpserts makes AssetRepository.Upsert fail with err; nil lets upserts succeed again
+func (s *Store) FailAssetUpserts(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.assetUpsertErr = err
+}
+
+// FailPromotion makes PromoteStaged of filename fail with err, as when storage is unavailable
+// partway through a commit; nil lets the promotion succeed again
+func (s *Store) FailPromotion(filename string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.promoteErr == nil {
+		s.promoteErr = make(map[string]error)
+	}
+	s.promoteErr[filename] = err
+}
+
+// StoredContent returns the content document of a feedback, as MongoDB holds it
+func (s *Store) StoredContent(id uuid.UUID) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.contents[id]
+	return content, ok
+}
+
+// ObjectExists reports whether storage holds an attachment
+func (s *Store) ObjectExists(feedbackID uuid.UUID, filename string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[attachmentKey(feedbackID, filename)]
+	return ok
+}
+
+// SeedObject stores an attachment in storage only, without a metadata row, as attachments
+// uploaded before the metadata table existed are
+func (s *Store) SeedObject(feedbackID uuid.UUID, filename, contentType string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[attachmentKey(feedbackID, filename)] = &object{
+		data:        bytes.Clone(data),
+		contentType: contentType,
+		uploadedAt:  time.Now().UTC(),
+	}
+}
+
+// DropObject removes an attachment from storage only, keeping its metadata row, as objects lost
+// from MinIO are
+func (s *Store) DropObject(feedbackID uuid.UUID, filename string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, attachmentKey(feedbackID, filename))
+}
+
+// SeedSession stores an upload session with its own status and timestamps, as sessions left
+// from earlier days are
+func (s *Store) SeedSession(session *models.UploadSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	stored := *session
+	stored.Files = nil
+	s.sessions[session.ID] = &stored
+}
+
+// Feedbacks returns the feedback repository of the store
+func (s *Store) Feedbacks() repository.FeedbackRepository { return &feedbackRepository{s} }
+
+// Outbox returns the content outbox repository of the store
+func (s *Store) Outbox() repository.ContentOutboxRepository { return &outboxRepository{s} }
+
+// Attachments returns the attachment storage of the store
+func (s *Store) Attachments() repository.AttachmentRepository { return &attachmentRepository{s} }
+
+// Assets returns the attachment metadata repository of the store
+func (s *Store) Assets() repository.AssetRepository { return &assetRepository{s} }
+
+// Sessions returns the upload session repository of the store
+func (s *Store) Sessions() repository.UploadSessionRepository { return &sessionRepository{s} }
+
+// Backfill returns the attachment metadata backfill journal of the store
+func (s *Store) Backfill() repository.BackfillJournalRepository { return &backfillRepository{s} }
+
+// Intents returns the deletion intent repository of the store
+func (s *Store) Intents() repository.DeletionIntentRepository { return &intentRepository{s} }
+
+// Audit returns the audit log of the store
+func (s *Store) Audit() repository.AuditRepository { return &auditRepository{s} }
+
+// Seals returns the seal repository of the store
+func (s *Store) Seals() repository.SealRepository { return &sealRepository{s} }
+
+// Templates returns the template repository of the store
+func (s *Store) Templates() repository.TemplateRepository { return &templateRepository{s} }
+
+// Policies returns the course policy repository of the store
+func (s *Store) Policies() repository.CoursePolicyRepository { return &policyRepository{s} }
+
+// Transfers returns the transfer usage repository of the store
+func (s *Store) Transfers() repository.TransferUsageRepository { return &transferRepository{s} }
+
+// Retention returns the retention repository of the store
+func (s *Store) Retention() repository.RetentionRepository { return &retentionRepository{s} }
+
+// Comments returns the comment repository of the store
+func (s *Store) Comments() repository.CommentRepository { return &commentRepository{s} }
+
+// unsupported is returned by the methods the in-memory repositories do not implement
+func unsupported(method string) error {
+	return fmt.Errorf("%s is not supported by repotest", method)
+}
+
+// page returns the part of items at a 1-based page of limit items, as OFFSET and LIMIT do
+func page[T any](items []T, pageNumber, limit int) []T {
+	offset := (pageNumber - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
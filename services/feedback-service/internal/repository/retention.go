@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// retentionTable describes how a retention dataset is stored
+type retentionTable struct {
+	table      string
+	timeColumn string // Rows are expired when this column is older than the cutoff
+	key        string // Expression returned for each pruned row
+	protected  string // Rows matching this condition are only pruned when forced
+	scope      string // Only rows matching this condition belong to the dataset (empty for all)
+	clear      string // SET clause that expires a row in place instead of deleting it
+}
+
+// retentionTables maps retention datasets to their tables
+var retentionTables = map[string]retentionTable{
+	models.RetentionUploadSessions: {
+		table:      "upload_sessions",
+		timeColumn: "updated_at",
+		key:        "id::text",
+		protected:  "status IN ('open', 'committing')",
+	},
+	models.RetentionAuditLog: {
+		table:      "feedback_audit_log",
+		timeColumn: "created_at",
+		key:        "id::text",
+	},
+	models.RetentionTransferUsage: {
+		table:      "transfer_usage",
+		timeColumn: "day",
+		key:        "principal_id::text || '/' || day::text",
+	},
+	models.RetentionIdempotencyKeys: {
+		table:      "feedbacks",
+		timeColumn: "created_at",
+		key:        "id::text",
+		scope:      "idempotency_key IS NOT NULL",
+		clear:      "idempotency_key = NULL, idempotency_fingerprint = NULL",
+	},
+}
+
+// retentionRepository implements RetentionRepository using PostgreSQL
+type retentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository creates a new retention repository
+func NewRetentionRepository(db *sql.DB) RetentionRepository {
+	return &retentionRepository{
+		db: db,
+	}
+}
+
+// lookup returns the table definition of a dataset
+func (r *retentionRepository) lookup(dataset string) (retentionTable, error) {
+	table, ok := retentionTables[dataset]
+	if !ok {
+		return retentionTable{}, fmt.Errorf("unknown retention dataset %q", dataset)
+	}
+	return table, nil
+}
+
+// scopeCondition returns the WHERE condition selecting the rows of a dataset
+func (t retentionTable) scopeCondition() string {
+	if t.scope == "" {
+		return "TRUE"
+	}
+	return t.scope
+}
+
+// expiredCondition returns the WHERE condition selecting expired rows of a table
+func (t retentionTable) expiredCondition(force bool) string {
+	condition := t.timeColumn + " < $1"
+	if t.scope != "" {
+		condition += " AND " + t.scope
+	}
+	if t.protected != "" && !force {
+		condition += " AND NOT (" + t.protected + ")"
+	}
+	return condition
+}
+
+// PruneBatch deletes up to limit expired rows of a dataset, or clears them for datasets that
+// live in columns of another table, and returns their keys. Protected rows are only pruned
+// when force is set.
+func (r *retentionRepository) PruneBatch(ctx context.Context, dataset string, cutoff time.Time, limit int, force bool) ([]string, error) {
+	table, err := r.lookup(dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s
+		WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s LIMIT $2)
+		RETURNING %[3]s
+	`, table.table, table.expiredCondition(force), table.key)
+	if table.clear != "" {
+		query = fmt.Sprintf(`
+			UPDATE %[1]s SET %[4]s
+			WHERE ctid IN (SELECT ctid FROM %[1]s WHERE %[2]s LIMIT $2)
+			RETURNING %[3]s
+		`, table.table, table.expiredCondition(force), table.key, table.clear)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune %s: %w", dataset, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan pruned %s row: %w", dataset, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pruned %s rows: %w", dataset, err)
+	}
+
+	return keys, nil
+}
+
+// CountExpired returns the number of expired rows that would be pruned, and the number of
+// expired rows that are protected (0 when forced)
+func (r *retentionRepository) CountExpired(ctx context.Context, dataset string, cutoff time.Time, force bool) (int64, int64, error) {
+	table, err := r.lookup(dataset)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var expired, protected int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, table.table, table.expiredCondition(force))
+	if err := r.db.QueryRowContext(ctx, query, cutoff.UTC()).Scan(&expired); err != nil {
+		return 0, 0, fmt.Errorf("failed to count expired %s: %w", dataset, err)
+	}
+
+	if table.protected != "" && !force {
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s < $1 AND %s AND (%s)`, table.table, table.timeColumn, table.scopeCondition(), table.protected)
+		if err := r.db.QueryRowContext(ctx, query, cutoff.UTC()).Scan(&protected); err != nil {
+			return 0, 0, fmt.Errorf("failed to count protected %s: %w", dataset, err)
+		}
+	}
+
+	return expired, protected, nil
+}
+
+// Oldest returns the timestamp of the oldest row of a dataset, or nil if it is empty
+func (r *retentionRepository) Oldest(ctx context.Context, dataset string) (*time.Time, error) {
+	table, err := r.lookup(dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldest sql.NullTime
+	query := fmt.Sprintf(`SELECT MIN(%s) FROM %s WHERE %s`, table.timeColumn, table.table, table.scopeCondition())
+	if err := r.db.QueryRowContext(ctx, query).Scan(&oldest); err != nil {
+		return nil, fmt.Errorf("failed to get oldest %s row: %w", dataset, err)
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+
+	return &oldest.Time, nil
+}
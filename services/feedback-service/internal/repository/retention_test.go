@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// pruneAll prunes a dataset in batches of two until a batch comes back short, as the retention
+// service does, and returns the pruned keys
+func pruneAll(t *testing.T, r *retentionRepository, dataset string, cutoff time.Time, force bool) []string {
+	t.Helper()
+	var pruned []string
+	for {
+		keys, err := r.PruneBatch(context.Background(), dataset, cutoff, 2, force)
+		if err != nil {
+			t.Fatalf("PruneBatch(%s) error = %v", dataset, err)
+		}
+		pruned = append(pruned, keys...)
+		if len(keys) < 2 {
+			return pruned
+		}
+	}
+}
+
+// remaining returns the values of the first column of a query, sorted
+func remaining(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(), query)
+	if err != nil {
+		t.Fatalf("query %q: %v", query, err)
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			t.Fatalf("scan %q: %v", query, err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("query %q: %v", query, err)
+	}
+	slices.Sort(values)
+	return values
+}
+
+// TestRetentionPostgres seeds expired and unexpired rows of each pruned table and checks which
+// survive a normal and a forced run
+func TestRetentionPostgres(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	r := &retentionRepository{db: db}
+	cutoff := time.Date(2025, 3, 21, 12, 0, 0, 0, time.UTC)
+	feedbackID := seedFeedbackRow(t, db, 301)
+
+	sessions := []struct {
+		status    string
+		updatedAt time.Time
+	}{
+		{"committed", cutoff.Add(-time.Hour)},
+		{"aborted", cutoff.Add(-time.Second)},
+		{"aborted", cutoff},
+		{"committed", cutoff.Add(time.Hour)},
+		{"open", cutoff.Add(-time.Hour)},
+		{"committing", cutoff.Add(-time.Hour)},
+		{"open", cutoff.Add(time.Hour)},
+	}
+	for _, session := range sessions {
+		mustExec(t, db, `INSERT INTO upload_sessions (feedback_id, reviewer_id, status, created_at, updated_at)
+			VALUES ($1, 101, $2, $3, $3)`, feedbackID, session.status, session.updatedAt)
+	}
+	for _, entry := range []struct {
+		action    string
+		createdAt time.Time
+	}{
+		{"expired", cutoff.AddDate(0, -1, 0)},
+		{"just-expired", cutoff.Add(-time.Microsecond)},
+		{"at-cutoff", cutoff},
+		{"recent", cutoff.Add(time.Hour)},
+	} {
+		mustExec(t, db, `INSERT INTO feedback_audit_log (feedback_id, actor_id, action, created_at) VALUES ($1, 101, $2, $3)`,
+			feedbackID, entry.action, entry.createdAt)
+	}
+	for day := 19; day <= 23; day++ {
+		mustExec(t, db, `INSERT INTO transfer_usage (principal_id, day, bytes_uploaded) VALUES (7, $1, 1)`,
+			time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC))
+	}
+
+	t.Run("upload sessions", func(t *testing.T) {
+		count := func() (int64, int64) {
+			expired, protected, err := r.CountExpired(ctx, models.RetentionUploadSessions, cutoff, false)
+			if err != nil {
+				t.Fatalf("CountExpired() error = %v", err)
+			}
+			return expired, protected
+		}
+		if expired, protected := count(); expired != 2 || protected != 2 {
+			t.Errorf("CountExpired() = %d, %d, want 2 expired and 2 protected", expired, protected)
+		}
+		if pruned := pruneAll(t, r, models.RetentionUploadSessions, cutoff, false); len(pruned) != 2 {
+			t.Errorf("PruneBatch() pruned %v, want the 2 resolved expired sessions", pruned)
+		}
+		query := `SELECT status || '@' || to_char(updated_at, 'HH24:MI:SS') FROM upload_sessions`
+		want := []string{"aborted@12:00:00", "committed@13:00:00", "committing@11:00:00", "open@11:00:00", "open@13:00:00"}
+		if got := remaining(t, db, query); !slices.Equal(got, want) {
+			t.Errorf("sessions left = %v, want %v", got, want)
+		}
+
+		if pruned := pruneAll(t, r, models.RetentionUploadSessions, cutoff, true); len(pruned) != 2 {
+			t.Errorf("forced PruneBatch() pruned %v, want the 2 unresolved expired sessions", pruned)
+		}
+		want = []string{"aborted@12:00:00", "committed@13:00:00", "open@13:00:00"}
+		if got := remaining(t, db, query); !slices.Equal(got, want) {
+			t.Errorf("sessions left after a forced run = %v, want %v", got, want)
+		}
+		if expired, protected := count(); expired != 0 || protected != 0 {
+			t.Errorf("CountExpired() after pruning = %d, %d, want none", expired, protected)
+		}
+	})
+
+	t.Run("audit log", func(t *testing.T) {
+		if pruned := pruneAll(t, r, models.RetentionAuditLog, cutoff, false); len(pruned) != 2 {
+			t.Errorf("PruneBatch() pruned %v, want 2 entries", pruned)
+		}
+		if got, want := remaining(t, db, `SELECT action FROM feedback_audit_log`), []string{"at-cutoff", "recent"}; !slices.Equal(got, want) {
+			t.Errorf("entries left = %v, want %v", got, want)
+		}
+		oldest, err := r.Oldest(ctx, models.RetentionAuditLog)
+		if err != nil || oldest == nil || oldest.Before(cutoff) {
+			t.Errorf("Oldest() = %v, %v, want an entry from the cutoff on", oldest, err)
+		}
+	})
+
+	t.Run("transfer usage", func(t *testing.T) {
+		// The day of the cutoff started before it, so its counters are expired
+		pruned := pruneAll(t, r, models.RetentionTransferUsage, cutoff, false)
+		slices.Sort(pruned)
+		if want := []string{"7/2025-03-19", "7/2025-03-20", "7/2025-03-21"}; !slices.Equal(pruned, want) {
+			t.Errorf("PruneBatch() pruned %v, want %v", pruned, want)
+		}
+		if got, want := remaining(t, db, `SELECT day::text FROM transfer_usage`), []string{"2025-03-22", "2025-03-23"}; !slices.Equal(got, want) {
+			t.Errorf("days left = %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// replaceRubricItems replaces the rubric items of a feedback in a transaction, keeping their order
+func replaceRubricItems(ctx context.Context, tx *sql.Tx, feedbackID uuid.UUID, items []models.RubricItem) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM feedback_rubric_items WHERE feedback_id = $1`, feedbackID); err != nil {
+		return fmt.Errorf("failed to delete rubric items: %w", err)
+	}
+	for i, item := range items {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO feedback_rubric_items (feedback_id, position, name, score, max_score, comment)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, feedbackID, i, item.Name, item.Score, item.MaxScore, item.Comment)
+		if err != nil {
+			return fmt.Errorf("failed to insert rubric item %q: %w", item.Name, err)
+		}
+	}
+	return nil
+}
+
+// loadRubricItems sets the rubric items of feedbacks with one query
+func (r *feedbackRepository) loadRubricItems(ctx context.Context, feedbacks []*models.Feedback) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*models.Feedback, len(feedbacks))
+	ids := make([]string, len(feedbacks))
+	for i, feedback := range feedbacks {
+		byID[feedback.ID] = feedback
+		ids[i] = feedback.ID.String()
+	}
+
+	query := `
+		SELECT feedback_id, name, score, max_score, comment
+		FROM feedback_rubric_items
+		WHERE feedback_id = ANY($1::uuid[])
+		ORDER BY feedback_id, position
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get rubric items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feedbackID uuid.UUID
+		var item models.RubricItem
+		if err := rows.Scan(&feedbackID, &item.Name, &item.Score, &item.MaxScore, &item.Comment); err != nil {
+			return fmt.Errorf("failed to scan rubric item: %w", err)
+		}
+		if feedback, ok := byID[feedbackID]; ok {
+			feedback.RubricItems = append(feedback.RubricItems, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rubric item rows: %w", err)
+	}
+	return nil
+}
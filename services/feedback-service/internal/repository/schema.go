@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+)
+
+// A column added to feedbacks is rolled out in three phases, so replicas running the old and
+// the new code can share the database:
+//
+//  1. A migration adds the column as nullable, or with a default. The column is registered in
+//     rollingColumns, so the code reads its default wherever the column does not exist yet.
+//  2. Code that writes the column is deployed. Until every replica reads it, writes can be held
+//     back with SCHEMA_DEFERRED_WRITE_COLUMNS. Rows written before then are filled in by the
+//     backfill-column maintenance task.
+//  3. A later migration enforces the column (NOT NULL, constraints) and it is removed from
+//     rollingColumns.
+
+// RollingColumn is a feedbacks column that is being rolled out
+type RollingColumn struct {
+	Name     string
+	Default  string // SQL expression read in place of the column while it does not exist
+	Backfill string // SQL expression computing the column for rows written before it, which must leave Pending false; empty if it has none
+	Pending  string // Predicate selecting the rows the backfill still has to fill; defaults to the column being NULL
+}
+
+// rollingColumns are the feedbacks columns still in their rollout
+var rollingColumns = []RollingColumn{
+	{Name: "read_at", Default: "NULL::timestamp"},
+	{Name: "idempotency_key", Default: "NULL::varchar"},
+	{Name: "idempotency_fingerprint", Default: "NULL::varchar"},
+	{Name: "revision_count", Default: "0"},
+	{Name: "requires_resubmission", Default: "FALSE"},
+	{Name: "resubmission_deadline", Default: "NULL::timestamp"},
+	{Name: "archived_at", Default: "NULL::timestamp"},
+}
+
+// rollingColumn returns the registered rolling column with a name
+func rollingColumn(name string) (RollingColumn, bool) {
+	for _, column := range rollingColumns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return RollingColumn{}, false
+}
+
+// feedbackColumns are the columns read into models.Feedback, in scan order
+var feedbackColumns = []string{
+	"id", "reviewer_id", "student_id", "submission_id", "title", "locked", "lock_reason", "needs_reupload", "submission_snapshot", "COALESCE(course_id, '')",
+	"points", "max_points", "status", "published_at", "approval_status", "approver_id", "approval_note", "read_at", "revision_count",
+	"requires_resubmission", "resubmission_deadline", "archived_at", "created_at", "updated_at",
+}
+
+// Schema is the shape of the feedbacks table found at startup. A nil *Schema stands for the
+// latest shape with every rolling column written.
+type Schema struct {
+	missing  map[string]bool // Rolling columns the table does not have
+	deferred map[string]bool // Rolling columns not written yet
+}
+
+// LoadSchema finds which rolling columns the feedbacks table has. deferredWrites lists, comma
+// separated, the rolling columns that are read but not written yet.
+func LoadSchema(ctx context.Context, db *sql.DB, deferredWrites string) (*Schema, error) {
+	schema := &Schema{missing: make(map[string]bool), deferred: make(map[string]bool)}
+	for _, name := range strings.Split(deferredWrites, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := rollingColumn(name); !ok {
+			return nil, fmt.Errorf("%q is not a rolling column", name)
+		}
+		schema.deferred[name] = true
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'feedbacks'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedbacks columns: %w", err)
+	}
+	defer rows.Close()
+
+	var present []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan feedbacks column: %w", err)
+		}
+		present = append(present, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedbacks columns: %w", err)
+	}
+
+	for _, column := range rollingColumns {
+		if !slices.Contains(present, column.Name) {
+			schema.missing[column.Name] = true
+		}
+	}
+	return schema, nil
+}
+
+// Missing returns the rolling columns the table does not have yet
+func (s *Schema) Missing() []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, column := range rollingColumns {
+		if s.missing[column.Name] {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+// readExpr returns the expression a column is read with: the column itself, or the default of
+// a rolling column the table does not have
+func (s *Schema) readExpr(name string) string {
+	if s == nil || !s.missing[name] {
+		return name
+	}
+	column, _ := rollingColumn(name)
+	return column.Default
+}
+
+// has reports whether the table has a column
+func (s *Schema) has(name string) bool {
+	return s == nil || !s.missing[name]
+}
+
+// writable reports whether a rolling column exists and may be written
+func (s *Schema) writable(name string) bool {
+	return s == nil || (!s.missing[name] && !s.deferred[name])
+}
+
+// selectColumns returns the select list of models.Feedback rows
+func (s *Schema) selectColumns() string {
+	exprs := make([]string, len(feedbackColumns))
+	for i, name := range feedbackColumns {
+		exprs[i] = s.readExpr(name)
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// columnUnavailable is returned by writes that need a rolling column that is missing or whose
+// writes are deferred
+func columnUnavailable(name string) error {
+	return apperr.Unavailable("COLUMN_UNAVAILABLE", fmt.Sprintf("column %s is not available for writes yet", name)).WithMetadata("column", name)
+}
+
+// BackfillColumn fills a rolling column for the rows written before it, batchSize rows per
+// statement, until no row is left. progress, if set, is called with the running total after
+// each batch. It returns the number of rows updated.
+func BackfillColumn(ctx context.Context, db *sql.DB, name string, batchSize int, progress func(updated int64)) (int64, error) {
+	column, ok := rollingColumn(name)
+	if !ok {
+		return 0, fmt.Errorf("%q is not a rolling column", name)
+	}
+	if column.Backfill == "" {
+		return 0, fmt.Errorf("rolling column %s has no backfill", name)
+	}
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive")
+	}
+	pending := column.Pending
+	if pending == "" {
+		pending = column.Name + " IS NULL"
+	}
+
+	// Locked rows are skipped and picked up by a later batch, so the backfill never waits on
+	// rows being written
+	query := fmt.Sprintf(`
+		UPDATE feedbacks SET %s = %s
+		WHERE id IN (SELECT id FROM feedbacks WHERE %s LIMIT %d FOR UPDATE SKIP LOCKED)
+	`, column.Name, column.Backfill, pending, batchSize)
+
+	var updated int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return updated, err
+		}
+		result, err := db.ExecContext(ctx, query)
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill %s: %w", column.Name, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return updated, fmt.Errorf("failed to backfill %s: %w", column.Name, err)
+		}
+		if n == 0 {
+			return updated, nil
+		}
+		updated += n
+		if progress != nil {
+			progress(updated)
+		}
+	}
+}
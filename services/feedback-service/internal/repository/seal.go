@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// sealRepository implements SealRepository using the feedback_seals table
+type sealRepository struct {
+	db *sql.DB
+}
+
+// NewSealRepository creates a new feedback seal repository
+func NewSealRepository(db *sql.DB) SealRepository {
+	return &sealRepository{
+		db: db,
+	}
+}
+
+// SealChainHash returns the chain hash of a seal: the hex SHA-256 of the previous seal's chain
+// hash followed by the seal's manifest hash
+func SealChainHash(previous, manifestSHA256 string) string {
+	sum := sha256.Sum256([]byte(previous + manifestSHA256))
+	return hex.EncodeToString(sum[:])
+}
+
+// Append stores a seal at the end of the chain, filling in its ID, previous and chain hashes
+// and sealing time. Appends are serialized, so every seal links to the one stored before it.
+func (r *sealRepository) Append(ctx context.Context, seal *models.FeedbackSeal) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Readers are not blocked; concurrent appends wait for this one
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE feedback_seals IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock feedback seals: %w", err)
+	}
+
+	var previous string
+	err = tx.QueryRowContext(ctx, `SELECT chain_sha256 FROM feedback_seals ORDER BY id DESC LIMIT 1`).Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get the previous seal: %w", err)
+	}
+	seal.PreviousSHA256 = previous
+	seal.ChainSHA256 = SealChainHash(previous, seal.ManifestSHA256)
+	seal.SealedAt = time.Now().UTC()
+
+	query := `
+		INSERT INTO feedback_seals (feedback_id, actor_id, manifest, manifest_sha256, previous_sha256, chain_sha256, sealed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		seal.FeedbackID, seal.ActorID, seal.Manifest, seal.ManifestSHA256, seal.PreviousSHA256, seal.ChainSHA256, seal.SealedAt,
+	).Scan(&seal.ID)
+	if err != nil {
+		return fmt.Errorf("failed to store feedback seal: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feedback seal: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the most recent seal of a feedback, or ErrFeedbackSealNotFound
+func (r *sealRepository) Latest(ctx context.Context, feedbackID uuid.UUID) (*models.FeedbackSeal, error) {
+	query := `
+		SELECT id, feedback_id, actor_id, manifest, manifest_sha256, previous_sha256, chain_sha256, sealed_at
+		FROM feedback_seals
+		WHERE feedback_id = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	seal := &models.FeedbackSeal{}
+	err := r.db.QueryRowContext(ctx, query, feedbackID).Scan(
+		&seal.ID, &seal.FeedbackID, &seal.ActorID, &seal.Manifest, &seal.ManifestSHA256, &seal.PreviousSHA256, &seal.ChainSHA256, &seal.SealedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedbackSealNotFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback seal: %w", err)
+	}
+	return seal, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// recordedQuery is a statement received by a fake database
+type recordedQuery struct {
+	query string
+	args  []interface{}
+}
+
+// fakeResult is the answer of a fake database to a query
+type fakeResult struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+// fakeDB is a database/sql driver recording the statements it receives and answering queries
+// with the results of respond. It checks the SQL a repository builds without a PostgreSQL server.
+type fakeDB struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+	respond func(query string, args []interface{}) fakeResult
+}
+
+// openFakeDB returns a database answering queries with respond, or with no rows when nil
+func openFakeDB(respond func(query string, args []interface{}) fakeResult) (*sql.DB, *fakeDB) {
+	fake := &fakeDB{respond: respond}
+	return sql.OpenDB(fake), fake
+}
+
+// recorded returns the statements received so far
+func (f *fakeDB) recorded() []recordedQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedQuery(nil), f.queries...)
+}
+
+func (f *fakeDB) record(query string, named []driver.NamedValue) fakeResult {
+	args := make([]interface{}, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+	f.mu.Lock()
+	f.queries = append(f.queries, recordedQuery{query: query, args: args})
+	f.mu.Unlock()
+	if f.respond == nil {
+		return fakeResult{}
+	}
+	return f.respond(query, args)
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake database: prepared statements are not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.record(query, args)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &fakeRows{columns: result.columns, rows: result.rows}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.record(query, args)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return driver.RowsAffected(len(result.rows)), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// templateRepository implements TemplateRepository using the feedback_templates table
+type templateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository creates a new feedback template repository
+func NewTemplateRepository(db *sql.DB) TemplateRepository {
+	return &templateRepository{
+		db: db,
+	}
+}
+
+// templateColumns are the columns read into models.FeedbackTemplate, in scan order
+const templateColumns = "id, reviewer_id, name, title, content, created_at, updated_at"
+
+// scanTemplate scans a feedback_templates row
+func scanTemplate(scan func(dest ...interface{}) error) (*models.FeedbackTemplate, error) {
+	template := &models.FeedbackTemplate{}
+	err := scan(&template.ID, &template.ReviewerID, &template.Name, &template.Title, &template.Content, &template.CreatedAt, &template.UpdatedAt)
+	return template, err
+}
+
+// Create stores a new template, assigning its ID and timestamps
+func (r *templateRepository) Create(ctx context.Context, template *models.FeedbackTemplate) error {
+	template.ID = uuid.New()
+	template.CreatedAt = time.Now().UTC()
+	template.UpdatedAt = template.CreatedAt
+
+	query := `
+		INSERT INTO feedback_templates (id, reviewer_id, name, title, content, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.ExecContext(ctx, query, template.ID, template.ReviewerID, template.Name, template.Title, template.Content, template.CreatedAt, template.UpdatedAt)
+	if isUniqueViolation(err, "idx_feedback_templates_reviewer_name") {
+		return ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create feedback template: %w", err)
+	}
+	return nil
+}
+
+// Get returns a template, or ErrTemplateNotFound
+func (r *templateRepository) Get(ctx context.Context, id uuid.UUID) (*models.FeedbackTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM feedback_templates WHERE id = $1`
+	template, err := scanTemplate(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err == sql.ErrNoRows {
+		return nil, ErrTemplateNotFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback template: %w", err)
+	}
+	return template, nil
+}
+
+// ListByReviewer lists a reviewer's templates by name, with the total number of templates
+func (r *templateRepository) ListByReviewer(ctx context.Context, reviewerID int64, page, limit int32) ([]*models.FeedbackTemplate, int32, error) {
+	var totalCount int32
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feedback_templates WHERE reviewer_id = $1`, reviewerID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count feedback templates: %w", err)
+	}
+	if totalCount == 0 {
+		return []*models.FeedbackTemplate{}, 0, nil
+	}
+
+	query := `
+		SELECT ` + templateColumns + `
+		FROM feedback_templates
+		WHERE reviewer_id = $1
+		ORDER BY name, id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, reviewerID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list feedback templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]*models.FeedbackTemplate, 0, limit)
+	for rows.Next() {
+		template, err := scanTemplate(rows.Scan)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan feedback template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating feedback template rows: %w", err)
+	}
+	return templates, totalCount, nil
+}
+
+// Update stores the name, title and content of a template and sets its update time
+func (r *templateRepository) Update(ctx context.Context, template *models.FeedbackTemplate) error {
+	template.UpdatedAt = time.Now().UTC()
+
+	query := `
+		UPDATE feedback_templates
+		SET name = $2, title = $3, content = $4, updated_at = $5
+		WHERE id = $1
+	`
+	result, err := r.db.ExecContext(ctx, query, template.ID, template.Name, template.Title, template.Content, template.UpdatedAt)
+	if isUniqueViolation(err, "idx_feedback_templates_reviewer_name") {
+		return ErrTemplateNameTaken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update feedback template: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// Delete removes a template, or returns ErrTemplateNotFound
+func (r *templateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM feedback_templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete feedback template: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
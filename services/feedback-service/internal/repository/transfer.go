@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// transferUsageRepository implements TransferUsageRepository using PostgreSQL
+type transferUsageRepository struct {
+	db *sql.DB
+}
+
+// NewTransferUsageRepository creates a new transfer usage repository
+func NewTransferUsageRepository(db *sql.DB) TransferUsageRepository {
+	return &transferUsageRepository{
+		db: db,
+	}
+}
+
+// AddUsage adds the given byte deltas to the stored daily counters
+func (r *transferUsageRepository) AddUsage(ctx context.Context, deltas []*models.TransferUsage) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO transfer_usage (principal_id, day, bytes_uploaded, bytes_downloaded, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (principal_id, day) DO UPDATE SET
+			bytes_uploaded = transfer_usage.bytes_uploaded + EXCLUDED.bytes_uploaded,
+			bytes_downloaded = transfer_usage.bytes_downloaded + EXCLUDED.bytes_downloaded,
+			updated_at = NOW()
+	`
+	for _, delta := range deltas {
+		_, err := tx.ExecContext(ctx, query, delta.PrincipalID, delta.Day, delta.BytesUploaded, delta.BytesDownloaded)
+		if err != nil {
+			return fmt.Errorf("failed to add transfer usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transfer usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsage returns the daily counters of a principal between since and until (inclusive days)
+func (r *transferUsageRepository) GetUsage(ctx context.Context, principalID int64, since, until time.Time) ([]*models.TransferUsage, error) {
+	query := `
+		SELECT principal_id, day, bytes_uploaded, bytes_downloaded
+		FROM transfer_usage
+		WHERE principal_id = $1 AND day BETWEEN $2 AND $3
+		ORDER BY day
+	`
+	rows, err := r.db.QueryContext(ctx, query, principalID, since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer usage: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTransferUsage(rows)
+}
+
+// TopPrincipals returns principals ordered by total bytes transferred between since and until
+func (r *transferUsageRepository) TopPrincipals(ctx context.Context, since, until time.Time, limit int) ([]*models.TransferUsage, error) {
+	query := `
+		SELECT principal_id, MIN(day), SUM(bytes_uploaded), SUM(bytes_downloaded)
+		FROM transfer_usage
+		WHERE day BETWEEN $1 AND $2
+		GROUP BY principal_id
+		ORDER BY SUM(bytes_uploaded + bytes_downloaded) DESC, principal_id
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, since, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTransferUsage(rows)
+}
+
+// scanTransferUsage scans transfer usage rows
+func scanTransferUsage(rows *sql.Rows) ([]*models.TransferUsage, error) {
+	var usage []*models.TransferUsage
+	for rows.Next() {
+		u := &models.TransferUsage{}
+		if err := rows.Scan(&u.PrincipalID, &u.Day, &u.BytesUploaded, &u.BytesDownloaded); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfer usage rows: %w", err)
+	}
+
+	return usage, nil
+}
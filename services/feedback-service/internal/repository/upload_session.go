@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// uploadSessionRepository implements UploadSessionRepository using PostgreSQL
+type uploadSessionRepository struct {
+	db *sql.DB
+}
+
+// NewUploadSessionRepository creates a new upload session repository
+func NewUploadSessionRepository(db *sql.DB) UploadSessionRepository {
+	return &uploadSessionRepository{
+		db: db,
+	}
+}
+
+// Create creates a new open upload session
+func (r *uploadSessionRepository) Create(ctx context.Context, session *models.UploadSession) error {
+	query := `
+		INSERT INTO upload_sessions (feedback_id, reviewer_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	session.Status = models.UploadSessionOpen
+	err := r.db.QueryRowContext(ctx, query, session.FeedbackID, session.ReviewerID, session.Status).
+		Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an upload session with its files
+func (r *uploadSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.UploadSession, error) {
+	query := `
+		SELECT id, feedback_id, reviewer_id, status, created_at, updated_at
+		FROM upload_sessions
+		WHERE id = $1
+	`
+	session := &models.UploadSession{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID, &session.FeedbackID, &session.ReviewerID, &session.Status, &session.CreatedAt, &session.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUploadSessionNotFound.Wrap(err)
+		}
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT filename, content_type, file_size, completed, promoted, sha256
+		FROM upload_session_files
+		WHERE session_id = $1
+		ORDER BY filename
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload session files: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		file := &models.UploadSessionFile{}
+		if err := rows.Scan(&file.Filename, &file.ContentType, &file.Size, &file.Completed, &file.Promoted, &file.SHA256); err != nil {
+			return nil, fmt.Errorf("failed to scan upload session file: %w", err)
+		}
+		session.Files = append(session.Files, file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upload session files: %w", err)
+	}
+
+	return session, nil
+}
+
+// SetStatus moves a session from one status to another. It reports false if the session was
+// not in the expected status, so concurrent transitions cannot both succeed.
+func (r *uploadSessionRepository) SetStatus(ctx context.Context, id uuid.UUID, from, to string) (bool, error) {
+	query := `UPDATE upload_sessions SET status = $3, updated_at = NOW() WHERE id = $1 AND status = $2`
+	result, err := r.db.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to update upload session status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UpsertFile records a file being staged; uploading the same filename again replaces it
+func (r *uploadSessionRepository) UpsertFile(ctx context.Context, sessionID uuid.UUID, file *models.UploadSessionFile) error {
+	query := `
+		INSERT INTO upload_session_files (session_id, filename, content_type, file_size, completed, sha256)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (session_id, filename)
+		DO UPDATE SET content_type = EXCLUDED.content_type, file_size = EXCLUDED.file_size, completed = EXCLUDED.completed, sha256 = EXCLUDED.sha256
+	`
+	_, err := r.db.ExecContext(ctx, query, sessionID, file.Filename, file.ContentType, file.Size, file.Completed, file.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to record upload session file: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFilePromoted records that a staged file has been copied to the feedback's attachments
+func (r *uploadSessionRepository) MarkFilePromoted(ctx context.Context, sessionID uuid.UUID, filename string) error {
+	query := `UPDATE upload_session_files SET promoted = TRUE WHERE session_id = $1 AND filename = $2`
+	if _, err := r.db.ExecContext(ctx, query, sessionID, filename); err != nil {
+		return fmt.Errorf("failed to mark upload session file promoted: %w", err)
+	}
+
+	return nil
+}
+
+// ListIDsByFeedback returns the IDs of all upload sessions of a feedback, whatever their status
+func (r *uploadSessionRepository) ListIDsByFeedback(ctx context.Context, feedbackID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM upload_sessions WHERE feedback_id = $1 ORDER BY id`, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upload sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan upload session ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate upload sessions: %w", err)
+	}
+
+	return ids, nil
+}
+
+// DeleteByFeedback deletes all upload sessions of a feedback together with their file records
+func (r *uploadSessionRepository) DeleteByFeedback(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE feedback_id = $1`, feedbackID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete upload sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,161 @@
+// Package resourcename builds and parses AIP-style resource names for the entities this
+// service exposes, so other services can link to them without inventing their own paths:
+//
+//	feedbacks/{uuid}
+//	feedbacks/{uuid}/attachments/{filename}
+//	contents/{type}/{content_id}/comments/{comment_id}
+//
+// The content_id of a comment is the numeric ID of a lab or article, or the UUID of a feedback
+// for comments discussing one.
+//
+// Request ID fields accept either a bare ID or the full resource name; the Parse functions
+// validate both forms and return the bare ID.
+//
+// Comment IDs are opaque at the API boundary: "cmt_" followed by the lower-case, unpadded
+// base32 encoding of the stored ObjectID. Clients must not derive anything from them, so the
+// backing store can change without breaking them. Bare hex ObjectIDs are still accepted while
+// their deprecation window lasts.
+package resourcename
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collection identifiers used in resource names
+const (
+	feedbacksCollection   = "feedbacks"
+	attachmentsCollection = "attachments"
+	contentsCollection    = "contents"
+	commentsCollection    = "comments"
+)
+
+// commentIDPrefix starts every opaque comment ID
+const commentIDPrefix = "cmt_"
+
+// commentIDEncoding encodes the 12 ObjectID bytes of an opaque comment ID
+var commentIDEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+var (
+	// ErrInvalidName is returned when a value is neither a valid bare ID nor a valid resource name
+	ErrInvalidName = errors.New("invalid resource name")
+
+	// ErrHexCommentID is returned for a bare hex comment ID once hex IDs are no longer accepted
+	ErrHexCommentID = errors.New("hex comment IDs are no longer accepted")
+)
+
+// Feedback returns the resource name of a feedback
+func Feedback(id uuid.UUID) string {
+	return feedbacksCollection + "/" + id.String()
+}
+
+// Attachment returns the resource name of a feedback attachment. The filename is path-escaped
+// so it always forms a single segment.
+func Attachment(feedbackID uuid.UUID, filename string) string {
+	return Feedback(feedbackID) + "/" + attachmentsCollection + "/" + url.PathEscape(filename)
+}
+
+// Comment returns the resource name of a comment; contentKey is the numeric or UUID ID of its
+// content and storedID the hex ObjectID, which is converted to the opaque form
+func Comment(contentType, contentKey, storedID string) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", contentsCollection, contentType, contentKey, commentsCollection, CommentID(storedID))
+}
+
+// CommentID converts a stored comment ID (24 hex characters) into its opaque API form. Values
+// that are not ObjectIDs are returned unchanged.
+func CommentID(storedID string) string {
+	raw, err := hex.DecodeString(storedID)
+	if err != nil || len(raw) != len(primitive.ObjectID{}) {
+		return storedID
+	}
+	return commentIDPrefix + commentIDEncoding.EncodeToString(raw)
+}
+
+// ParseFeedbackID accepts a bare feedback UUID or a feedbacks/{uuid} name and returns the UUID
+func ParseFeedbackID(value string) (uuid.UUID, error) {
+	raw := value
+	if strings.Contains(value, "/") {
+		segments := strings.Split(value, "/")
+		if len(segments) != 2 || segments[0] != feedbacksCollection {
+			return uuid.Nil, fmt.Errorf("%w: %q is not a feedback name", ErrInvalidName, value)
+		}
+		raw = segments[1]
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid feedback ID %q", ErrInvalidName, raw)
+	}
+	return id, nil
+}
+
+// ParseAttachment parses a feedbacks/{uuid}/attachments/{filename} name and returns the
+// feedback ID and the unescaped filename
+func ParseAttachment(value string) (uuid.UUID, string, error) {
+	segments := strings.Split(value, "/")
+	if len(segments) != 4 || segments[0] != feedbacksCollection || segments[2] != attachmentsCollection {
+		return uuid.Nil, "", fmt.Errorf("%w: %q is not an attachment name", ErrInvalidName, value)
+	}
+
+	feedbackID, err := uuid.Parse(segments[1])
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("%w: invalid feedback ID %q", ErrInvalidName, segments[1])
+	}
+	filename, err := url.PathUnescape(segments[3])
+	if err != nil || filename == "" {
+		return uuid.Nil, "", fmt.Errorf("%w: invalid attachment filename %q", ErrInvalidName, segments[3])
+	}
+	return feedbackID, filename, nil
+}
+
+// ParseCommentID accepts a comment ID or a contents/{type}/{content_id}/comments/{comment_id}
+// name and returns the stored ID (24 hex characters). The comment ID is the opaque "cmt_" form,
+// or a bare hex ObjectID if allowHex is set; otherwise hex IDs fail with ErrHexCommentID.
+func ParseCommentID(value string, allowHex bool) (string, error) {
+	raw := value
+	if strings.Contains(value, "/") {
+		segments := strings.Split(value, "/")
+		if len(segments) != 5 || segments[0] != contentsCollection || segments[3] != commentsCollection || segments[1] == "" {
+			return "", fmt.Errorf("%w: %q is not a comment name", ErrInvalidName, value)
+		}
+		if !validContentKey(segments[2]) {
+			return "", fmt.Errorf("%w: invalid content ID %q", ErrInvalidName, segments[2])
+		}
+		raw = segments[4]
+	}
+
+	if encoded, ok := strings.CutPrefix(raw, commentIDPrefix); ok {
+		// Re-encoding rejects IDs whose unused trailing bits are set, so every comment has one ID
+		decoded, err := commentIDEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) != len(primitive.ObjectID{}) || commentIDEncoding.EncodeToString(decoded) != encoded {
+			return "", fmt.Errorf("%w: invalid comment ID %q", ErrInvalidName, raw)
+		}
+		return hex.EncodeToString(decoded), nil
+	}
+
+	if _, err := primitive.ObjectIDFromHex(raw); err != nil {
+		return "", fmt.Errorf("%w: invalid comment ID %q", ErrInvalidName, raw)
+	}
+	if !allowHex {
+		return "", fmt.Errorf("%w: use the %s form instead of %q", ErrHexCommentID, commentIDPrefix, raw)
+	}
+	return raw, nil
+}
+
+// validContentKey reports whether a resource name segment is a positive numeric content ID or
+// a UUID
+func validContentKey(segment string) bool {
+	if contentID, err := strconv.ParseInt(segment, 10, 64); err == nil {
+		return contentID > 0
+	}
+	_, err := uuid.Parse(segment)
+	return err == nil
+}
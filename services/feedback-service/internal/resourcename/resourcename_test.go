@@ -0,0 +1,137 @@
+package resourcename
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testFeedbackID = "0b8c4ff2-3f7e-4a47-9b2f-6a1f1f0ad9c1"
+	testStoredID   = "507f1f77bcf86cd799439011"
+	testCommentID  = "cmt_kb7r65547bwnpgkdsaiq"
+)
+
+func TestNames(t *testing.T) {
+	feedbackID := uuid.MustParse(testFeedbackID)
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "feedback", got: Feedback(feedbackID), want: "feedbacks/" + testFeedbackID},
+		{name: "attachment", got: Attachment(feedbackID, "report.pdf"), want: "feedbacks/" + testFeedbackID + "/attachments/report.pdf"},
+		{name: "attachment escaped", got: Attachment(feedbackID, "a b/c.txt"), want: "feedbacks/" + testFeedbackID + "/attachments/a%20b%2Fc.txt"},
+		{name: "lab comment", got: Comment("lab", "42", testStoredID), want: "contents/lab/42/comments/" + testCommentID},
+		{name: "feedback comment", got: Comment("feedback", testFeedbackID, testStoredID), want: "contents/feedback/" + testFeedbackID + "/comments/" + testCommentID},
+		{name: "comment ID", got: CommentID(testStoredID), want: testCommentID},
+		{name: "comment ID not an ObjectID", got: CommentID("legacy-1"), want: "legacy-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFeedbackID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "bare ID", value: testFeedbackID},
+		{name: "resource name", value: "feedbacks/" + testFeedbackID},
+		{name: "invalid ID", value: "not-a-uuid", wantErr: true},
+		{name: "invalid ID in name", value: "feedbacks/42", wantErr: true},
+		{name: "other collection", value: "comments/" + testFeedbackID, wantErr: true},
+		{name: "attachment name", value: "feedbacks/" + testFeedbackID + "/attachments/a.txt", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFeedbackID(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidName) {
+					t.Errorf("ParseFeedbackID(%q) error = %v, want %v", tt.value, err, ErrInvalidName)
+				}
+				return
+			}
+			if err != nil || got.String() != testFeedbackID {
+				t.Errorf("ParseFeedbackID(%q) = %v, %v, want %s", tt.value, got, err, testFeedbackID)
+			}
+		})
+	}
+}
+
+func TestParseAttachment(t *testing.T) {
+	feedbackID := uuid.MustParse(testFeedbackID)
+	tests := []struct {
+		name         string
+		value        string
+		wantFilename string
+		wantErr      bool
+	}{
+		{name: "plain filename", value: Attachment(feedbackID, "report.pdf"), wantFilename: "report.pdf"},
+		{name: "escaped filename", value: Attachment(feedbackID, "a b/c.txt"), wantFilename: "a b/c.txt"},
+		{name: "bare filename", value: "report.pdf", wantErr: true},
+		{name: "unescaped slash", value: "feedbacks/" + testFeedbackID + "/attachments/a/b.txt", wantErr: true},
+		{name: "empty filename", value: "feedbacks/" + testFeedbackID + "/attachments/", wantErr: true},
+		{name: "bad escape", value: "feedbacks/" + testFeedbackID + "/attachments/%zz", wantErr: true},
+		{name: "invalid feedback ID", value: "feedbacks/42/attachments/report.pdf", wantErr: true},
+		{name: "other collection", value: "feedbacks/" + testFeedbackID + "/files/report.pdf", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, gotFilename, err := ParseAttachment(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidName) {
+					t.Errorf("ParseAttachment(%q) error = %v, want %v", tt.value, err, ErrInvalidName)
+				}
+				return
+			}
+			if err != nil || gotID != feedbackID || gotFilename != tt.wantFilename {
+				t.Errorf("ParseAttachment(%q) = %v, %q, %v, want %v, %q", tt.value, gotID, gotFilename, err, feedbackID, tt.wantFilename)
+			}
+		})
+	}
+}
+
+func TestParseCommentID(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		allowHex bool
+		wantErr  error
+	}{
+		{name: "opaque ID", value: testCommentID},
+		{name: "lab comment name", value: "contents/lab/42/comments/" + testCommentID},
+		{name: "feedback comment name", value: "contents/feedback/" + testFeedbackID + "/comments/" + testCommentID},
+		{name: "hex ID allowed", value: testStoredID, allowHex: true},
+		{name: "hex ID in name allowed", value: "contents/lab/42/comments/" + testStoredID, allowHex: true},
+		{name: "hex ID rejected", value: testStoredID, wantErr: ErrHexCommentID},
+		{name: "non-canonical opaque ID", value: "cmt_kb7r65547bwnpgkdsair", wantErr: ErrInvalidName},
+		{name: "short opaque ID", value: "cmt_kb7r65547bwnpgkd", wantErr: ErrInvalidName},
+		{name: "invalid opaque ID", value: "cmt_KB7R65547BWNPGKDSAIQ", wantErr: ErrInvalidName},
+		{name: "invalid hex ID", value: "507f1f77bcf86cd79943901", allowHex: true, wantErr: ErrInvalidName},
+		{name: "zero content ID", value: "contents/lab/0/comments/" + testCommentID, wantErr: ErrInvalidName},
+		{name: "invalid content ID", value: "contents/lab/abc/comments/" + testCommentID, wantErr: ErrInvalidName},
+		{name: "missing content type", value: "contents//42/comments/" + testCommentID, wantErr: ErrInvalidName},
+		{name: "other collection", value: "contents/lab/42/replies/" + testCommentID, wantErr: ErrInvalidName},
+		{name: "empty", value: "", wantErr: ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCommentID(tt.value, tt.allowHex)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseCommentID(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			if err == nil && got != testStoredID {
+				t.Errorf("ParseCommentID(%q) = %q, want %q", tt.value, got, testStoredID)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Package seed generates, scrubs and loads test datasets of feedbacks, attachments and comments.
+// Datasets refer to their records with dataset-local keys, since the repositories assign the
+// real IDs when a dataset is loaded.
+package seed
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// Dataset is a set of feedbacks with their attachments, and comments
+type Dataset struct {
+	Feedbacks   []*Feedback
+	Attachments []*Attachment
+	Comments    []*Comment // Parents come before their replies
+}
+
+// Feedback is a feedback of a dataset
+type Feedback struct {
+	Key          string        `json:"key"`
+	ReviewerID   int64         `json:"reviewer_id"`
+	StudentID    int64         `json:"student_id"`
+	SubmissionID int64         `json:"submission_id"`
+	CourseID     string        `json:"course_id,omitempty"`
+	Title        string        `json:"title"`
+	Content      string        `json:"content"`
+	Status       string        `json:"status"` // models.FeedbackDraft or models.FeedbackPublished
+	Grade        *models.Grade `json:"grade,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// Attachment is the metadata of an attachment of a dataset feedback. Its content is generated
+// when the dataset is loaded.
+type Attachment struct {
+	FeedbackKey string    `json:"feedback_key"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// Comment is a comment of a dataset
+type Comment struct {
+	Key       string    `json:"key"`
+	ParentKey string    `json:"parent_key,omitempty"` // Empty for top-level comments
+	ContentID int64     `json:"content_id"`
+	Type      string    `json:"type"` // "lab" or "article"
+	UserID    int64     `json:"user_id"`
+	CourseID  string    `json:"course_id,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// record is one line of a dataset file; exactly one field is set
+type record struct {
+	Feedback   *Feedback   `json:"feedback,omitempty"`
+	Attachment *Attachment `json:"attachment,omitempty"`
+	Comment    *Comment    `json:"comment,omitempty"`
+}
+
+// Write writes a dataset as JSON lines: feedbacks, then attachments, then comments
+func (d *Dataset) Write(w io.Writer) error {
+	out := json.NewEncoder(w)
+	for _, feedback := range d.Feedbacks {
+		if err := out.Encode(record{Feedback: feedback}); err != nil {
+			return err
+		}
+	}
+	for _, attachment := range d.Attachments {
+		if err := out.Encode(record{Attachment: attachment}); err != nil {
+			return err
+		}
+	}
+	for _, comment := range d.Comments {
+		if err := out.Encode(record{Comment: comment}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads a dataset written by Write and checks its references
+func Read(r io.Reader) (*Dataset, error) {
+	d := &Dataset{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 32*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case rec.Feedback != nil:
+			d.Feedbacks = append(d.Feedbacks, rec.Feedback)
+		case rec.Attachment != nil:
+			d.Attachments = append(d.Attachments, rec.Attachment)
+		case rec.Comment != nil:
+			d.Comments = append(d.Comments, rec.Comment)
+		default:
+			return nil, fmt.Errorf("line %d: empty record", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Validate checks that keys are unique, that attachments belong to a feedback of the dataset and
+// that every reply comes after its parent
+func (d *Dataset) Validate() error {
+	feedbacks := make(map[string]bool, len(d.Feedbacks))
+	reviewed := make(map[[2]int64]bool, len(d.Feedbacks))
+	for _, feedback := range d.Feedbacks {
+		if feedback.Key == "" || feedbacks[feedback.Key] {
+			return fmt.Errorf("feedback key %q is empty or repeated", feedback.Key)
+		}
+		feedbacks[feedback.Key] = true
+		// A reviewer has at most one feedback per submission
+		pair := [2]int64{feedback.ReviewerID, feedback.SubmissionID}
+		if reviewed[pair] {
+			return fmt.Errorf("reviewer %d has several feedbacks for submission %d", pair[0], pair[1])
+		}
+		reviewed[pair] = true
+	}
+
+	filenames := make(map[string]bool, len(d.Attachments))
+	for _, attachment := range d.Attachments {
+		if !feedbacks[attachment.FeedbackKey] {
+			return fmt.Errorf("attachment %q refers to unknown feedback %q", attachment.Filename, attachment.FeedbackKey)
+		}
+		name := attachment.FeedbackKey + "/" + attachment.Filename
+		if filenames[name] {
+			return fmt.Errorf("attachment %q is repeated", name)
+		}
+		filenames[name] = true
+		if attachment.Size < 0 {
+			return fmt.Errorf("attachment %q has a negative size", name)
+		}
+	}
+
+	comments := make(map[string]*Comment, len(d.Comments))
+	for _, comment := range d.Comments {
+		if comment.Key == "" || comments[comment.Key] != nil {
+			return fmt.Errorf("comment key %q is empty or repeated", comment.Key)
+		}
+		if comment.ParentKey != "" {
+			parent := comments[comment.ParentKey]
+			if parent == nil {
+				return fmt.Errorf("comment %q comes before its parent %q or the parent does not exist", comment.Key, comment.ParentKey)
+			}
+			if parent.ContentID != comment.ContentID || parent.Type != comment.Type {
+				return fmt.Errorf("comment %q is not on the content of its parent", comment.Key)
+			}
+		}
+		comments[comment.Key] = comment
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// Options sets the volume and shape of a generated dataset
+type Options struct {
+	Seed               uint64    // The same seed and options always generate the same dataset
+	Feedbacks          int       // Number of feedbacks
+	Reviewers          int       // Reviewers writing them; a few write most of them
+	Students           int       // Students receiving them
+	Courses            int       // Courses feedbacks and comments belong to
+	Attachments        float64   // Mean attachments per feedback
+	MaxAttachmentBytes int64     // Largest generated attachment
+	Contents           int       // Labs and articles comments are written on; a few get most of them
+	Threads            int       // Top-level comments
+	Branching          float64   // Mean replies per comment
+	MaxDepth           int       // Deepest reply level
+	MaxThreadSize      int       // Most comments in one thread, replies included
+	Start              time.Time // Earliest creation time
+	Span               time.Duration
+}
+
+// DefaultOptions returns options for a dataset of a few thousand records
+func DefaultOptions() Options {
+	return Options{
+		Seed:               1,
+		Feedbacks:          2000,
+		Reviewers:          40,
+		Students:           600,
+		Courses:            8,
+		Attachments:        1,
+		MaxAttachmentBytes: 16 * 1024,
+		Contents:           150,
+		Threads:            1500,
+		Branching:          0.9,
+		MaxDepth:           8,
+		MaxThreadSize:      200,
+		Start:              time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Span:               180 * 24 * time.Hour,
+	}
+}
+
+// validate checks that the options can generate a dataset
+func (o Options) validate() error {
+	if o.Feedbacks < 0 || o.Threads < 0 {
+		return fmt.Errorf("feedback and thread counts must not be negative")
+	}
+	if o.Reviewers <= 0 || o.Students <= 0 || o.Contents <= 0 {
+		return fmt.Errorf("reviewers, students and contents must be positive")
+	}
+	if o.Courses < 0 || o.Attachments < 0 || o.Branching < 0 || o.MaxDepth < 0 {
+		return fmt.Errorf("courses, attachments, branching and max depth must not be negative")
+	}
+	if o.MaxAttachmentBytes < 0 || o.MaxThreadSize <= 0 || o.Span <= 0 {
+		return fmt.Errorf("max attachment bytes must not be negative, max thread size and span must be positive")
+	}
+	return nil
+}
+
+// attachmentKinds are the extensions and content types of generated attachments
+var attachmentKinds = []struct{ ext, contentType string }{
+	{"pdf", "application/pdf"},
+	{"png", "image/png"},
+	{"txt", "text/plain"},
+	{"zip", "application/zip"},
+	{"py", "text/x-python"},
+}
+
+// attachmentNames are the base names of generated attachments
+var attachmentNames = []string{"report", "screenshot", "notes", "solution", "diff", "log", "rubric"}
+
+// titleTopics are the topics of generated feedback titles
+var titleTopics = []string{"Lab", "Assignment", "Project milestone", "Homework", "Quiz", "Code review"}
+
+// generator draws a dataset from one random source, so its output only depends on the seed
+type generator struct {
+	opts Options
+	rand *rand.Rand
+	text *lorem
+
+	reviewers *rand.Zipf // Few reviewers write most feedbacks
+	contents  *rand.Zipf // Few contents get most comments
+	courses   *rand.Zipf
+}
+
+// Generate generates a dataset. Counts follow skewed distributions: a few reviewers write most
+// feedbacks, a few contents get most comments, and reply counts per comment are drawn around
+// the branching factor, so some threads stay single comments and others grow deep.
+func Generate(opts Options) (*Dataset, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5eed))
+	g := &generator{
+		opts:      opts,
+		rand:      r,
+		text:      newLorem(r),
+		reviewers: rand.NewZipf(r, 1.3, 2, uint64(opts.Reviewers-1)),
+		contents:  rand.NewZipf(r, 1.2, 2, uint64(opts.Contents-1)),
+	}
+	if opts.Courses > 0 {
+		g.courses = rand.NewZipf(r, 1.1, 1, uint64(opts.Courses-1))
+	}
+
+	d := &Dataset{}
+	g.feedbacks(d)
+	g.comments(d)
+	return d, nil
+}
+
+// feedbacks generates the feedbacks and their attachments. Every submission gets one to three
+// reviewers and each reviewer at most one feedback per submission.
+func (g *generator) feedbacks(d *Dataset) {
+	var submissionID int64
+	for len(d.Feedbacks) < g.opts.Feedbacks {
+		submissionID++
+		studentID := int64(g.opts.Reviewers) + 1 + g.rand.Int64N(int64(g.opts.Students))
+		course := g.course()
+
+		reviewers := 1
+		if p := g.rand.Float64(); p > 0.9 {
+			reviewers = 3
+		} else if p > 0.7 {
+			reviewers = 2
+		}
+		reviewers = min(reviewers, g.opts.Reviewers, g.opts.Feedbacks-len(d.Feedbacks))
+
+		chosen := make(map[int64]bool, reviewers)
+		for len(chosen) < reviewers {
+			reviewerID := int64(g.reviewers.Uint64()) + 1
+			for chosen[reviewerID] {
+				reviewerID = reviewerID%int64(g.opts.Reviewers) + 1
+			}
+			chosen[reviewerID] = true
+			d.Feedbacks = append(d.Feedbacks, g.feedback(len(d.Feedbacks)+1, reviewerID, studentID, submissionID, course))
+			g.attachments(d, d.Feedbacks[len(d.Feedbacks)-1])
+		}
+	}
+}
+
+// feedback generates one feedback
+func (g *generator) feedback(n int, reviewerID, studentID, submissionID int64, course string) *Feedback {
+	feedback := &Feedback{
+		Key:          fmt.Sprintf("f%d", n),
+		ReviewerID:   reviewerID,
+		StudentID:    studentID,
+		SubmissionID: submissionID,
+		CourseID:     course,
+		Title:        fmt.Sprintf("%s %d: %s", titleTopics[g.rand.IntN(len(titleTopics))], 1+g.rand.IntN(12), g.text.words(2+g.rand.IntN(5))),
+		Content:      g.markdown(),
+		Status:       models.FeedbackPublished,
+		CreatedAt:    g.timestamp(),
+	}
+	if g.rand.Float64() < 0.2 {
+		feedback.Status = models.FeedbackDraft
+	}
+	if g.rand.Float64() < 0.7 {
+		maxPoints := []float64{10, 20, 100}[g.rand.IntN(3)]
+		feedback.Grade = &models.Grade{Points: math.Round(g.rand.Float64()*maxPoints*2) / 2, MaxPoints: maxPoints}
+	}
+	return feedback
+}
+
+// attachments generates the attachments of a feedback
+func (g *generator) attachments(d *Dataset, feedback *Feedback) {
+	count := min(g.poisson(g.opts.Attachments), 5)
+	used := make(map[string]bool, count)
+	for i := 0; i < count; i++ {
+		kind := attachmentKinds[g.rand.IntN(len(attachmentKinds))]
+		filename := fmt.Sprintf("%s-%d.%s", attachmentNames[g.rand.IntN(len(attachmentNames))], i+1, kind.ext)
+		if used[filename] {
+			continue
+		}
+		used[filename] = true
+		var size int64
+		if g.opts.MaxAttachmentBytes > 0 {
+			size = 1 + g.rand.Int64N(g.opts.MaxAttachmentBytes)
+		}
+		d.Attachments = append(d.Attachments, &Attachment{
+			FeedbackKey: feedback.Key,
+			Filename:    filename,
+			ContentType: kind.contentType,
+			Size:        size,
+			UploadedAt:  feedback.CreatedAt.Add(time.Duration(g.rand.IntN(120)) * time.Minute),
+		})
+	}
+}
+
+// comments generates the comment threads. Each comment gets a number of replies drawn around
+// the branching factor, down to the maximum depth and up to the maximum thread size.
+func (g *generator) comments(d *Dataset) {
+	for i := 0; i < g.opts.Threads; i++ {
+		contentID := int64(g.contents.Uint64()) + 1
+		contentType := "lab"
+		if contentID%4 == 0 {
+			contentType = "article"
+		}
+		root := &Comment{
+			ContentID: contentID,
+			Type:      contentType,
+			CourseID:  g.course(),
+			CreatedAt: g.timestamp(),
+		}
+		size := 0
+		g.thread(d, root, 0, &size)
+	}
+}
+
+// thread appends a comment and, depth first, its replies, so parents precede their replies
+func (g *generator) thread(d *Dataset, comment *Comment, depth int, size *int) {
+	*size++
+	comment.Key = fmt.Sprintf("c%d", len(d.Comments)+1)
+	comment.UserID = 1 + g.rand.Int64N(int64(g.opts.Reviewers+g.opts.Students))
+	comment.Content = g.text.sentences(1 + g.rand.IntN(4))
+	comment.UpdatedAt = comment.CreatedAt
+	if g.rand.Float64() < 0.1 {
+		comment.UpdatedAt = comment.CreatedAt.Add(time.Duration(1+g.rand.IntN(600)) * time.Minute)
+	}
+	d.Comments = append(d.Comments, comment)
+
+	if depth >= g.opts.MaxDepth {
+		return
+	}
+	replies := g.poisson(g.opts.Branching)
+	for i := 0; i < replies && *size < g.opts.MaxThreadSize; i++ {
+		g.thread(d, &Comment{
+			ParentKey: comment.Key,
+			ContentID: comment.ContentID,
+			Type:      comment.Type,
+			CourseID:  comment.CourseID,
+			CreatedAt: comment.CreatedAt.Add(time.Duration(1+g.rand.IntN(48*60)) * time.Minute),
+		}, depth+1, size)
+	}
+}
+
+// markdown generates feedback content of a few paragraphs, sometimes with a list
+func (g *generator) markdown() string {
+	var b strings.Builder
+	paragraphs := 1 + int(g.rand.ExpFloat64()*2)
+	for i := 0; i < paragraphs; i++ {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		if g.rand.Float64() < 0.25 {
+			for j := 0; j < 2+g.rand.IntN(4); j++ {
+				fmt.Fprintf(&b, "- %s\n", g.text.sentences(1))
+			}
+			continue
+		}
+		b.WriteString(g.text.sentences(2 + g.rand.IntN(6)))
+	}
+	return b.String()
+}
+
+// course returns a course ID, or an empty one for a tenth of the records
+func (g *generator) course() string {
+	if g.courses == nil || g.rand.Float64() < 0.1 {
+		return ""
+	}
+	return fmt.Sprintf("course-%d", g.courses.Uint64()+1)
+}
+
+// timestamp returns a time within the span, to the second
+func (g *generator) timestamp() time.Time {
+	return g.opts.Start.Add(time.Duration(g.rand.Int64N(int64(g.opts.Span/time.Second))) * time.Second)
+}
+
+// poisson draws a count with the given mean
+func (g *generator) poisson(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	limit, product, count := math.Exp(-mean), g.rand.Float64(), 0
+	for product > limit {
+		product *= g.rand.Float64()
+		count++
+	}
+	return count
+}
@@ -0,0 +1,185 @@
+package seed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// commentBatchSize is the number of comments inserted per call
+const commentBatchSize = 500
+
+// Summary reports what a load wrote
+type Summary struct {
+	Feedbacks   int   `json:"feedbacks"`
+	Attachments int   `json:"attachments"`
+	Bytes       int64 `json:"bytes"` // Attachment content written
+	Comments    int   `json:"comments"`
+}
+
+// Loader writes datasets through the repositories the service uses, so loaded data goes through
+// the same code paths (content storage, search indexing, attachment encryption and metadata) as
+// data created by clients
+type Loader struct {
+	feedbackRepo       repository.FeedbackRepository
+	attachmentRepo     repository.AttachmentRepository
+	assetRepo          repository.AssetRepository
+	commentRepo        repository.CommentRepository
+	seed               uint64
+	maxAttachmentBytes int64
+	logger             *slog.Logger
+}
+
+// NewLoader creates a dataset loader. Attachment content is random bytes drawn from seed, cut
+// to maxAttachmentBytes.
+func NewLoader(feedbackRepo repository.FeedbackRepository, attachmentRepo repository.AttachmentRepository, assetRepo repository.AssetRepository, commentRepo repository.CommentRepository, seed uint64, maxAttachmentBytes int64, logger *slog.Logger) *Loader {
+	return &Loader{
+		feedbackRepo:       feedbackRepo,
+		attachmentRepo:     attachmentRepo,
+		assetRepo:          assetRepo,
+		commentRepo:        commentRepo,
+		seed:               seed,
+		maxAttachmentBytes: maxAttachmentBytes,
+		logger:             logger,
+	}
+}
+
+// Load writes a dataset: feedbacks, then their attachments, then comments level by level. The
+// repositories assign new IDs and creation times to feedbacks; comments keep their timestamps.
+// It stops at the first error, leaving what was written so far.
+func (l *Loader) Load(ctx context.Context, d *Dataset) (*Summary, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	summary := &Summary{}
+
+	ids := make(map[string]uuid.UUID, len(d.Feedbacks))
+	for _, feedback := range d.Feedbacks {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		created := &models.Feedback{
+			ReviewerID:   feedback.ReviewerID,
+			StudentID:    feedback.StudentID,
+			SubmissionID: feedback.SubmissionID,
+			CourseID:     feedback.CourseID,
+			Title:        feedback.Title,
+			Content:      feedback.Content,
+			Status:       feedback.Status,
+			Grade:        feedback.Grade,
+		}
+		if err := l.feedbackRepo.Create(ctx, created); err != nil {
+			return summary, fmt.Errorf("failed to create feedback %s: %w", feedback.Key, err)
+		}
+		ids[feedback.Key] = created.ID
+		summary.Feedbacks++
+		if summary.Feedbacks%500 == 0 {
+			l.logger.Info("Seed progress", "feedbacks", summary.Feedbacks)
+		}
+	}
+
+	content := rand.New(rand.NewPCG(l.seed, l.seed^0xa77a))
+	for _, attachment := range d.Attachments {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		written, err := l.loadAttachment(ctx, content, ids[attachment.FeedbackKey], attachment)
+		if err != nil {
+			return summary, fmt.Errorf("failed to upload attachment %s of feedback %s: %w", attachment.Filename, attachment.FeedbackKey, err)
+		}
+		summary.Attachments++
+		summary.Bytes += written
+	}
+	l.logger.Info("Seed progress", "feedbacks", summary.Feedbacks, "attachments", summary.Attachments)
+
+	if err := l.loadComments(ctx, d.Comments, summary); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// loadAttachment uploads random content for an attachment and records its metadata, as an
+// upload by a client does. It returns the bytes written.
+func (l *Loader) loadAttachment(ctx context.Context, content *rand.Rand, feedbackID uuid.UUID, attachment *Attachment) (int64, error) {
+	data := make([]byte, min(attachment.Size, l.maxAttachmentBytes))
+	for i := range data {
+		data[i] = byte(content.UintN(256))
+	}
+	if err := l.attachmentRepo.Upload(ctx, feedbackID, attachment.Filename, attachment.ContentType, bytes.NewReader(data), int64(len(data))); err != nil {
+		return 0, err
+	}
+	sum := sha256.Sum256(data)
+	info := &models.AttachmentInfo{
+		Filename:    attachment.Filename,
+		Size:        int64(len(data)),
+		ContentType: attachment.ContentType,
+		UploadedAt:  attachment.UploadedAt.UTC(),
+		SHA256:      hex.EncodeToString(sum[:]),
+	}
+	if err := l.assetRepo.Upsert(ctx, feedbackID, info); err != nil {
+		return 0, fmt.Errorf("failed to record metadata: %w", err)
+	}
+	return int64(len(data)), nil
+}
+
+// loadComments inserts comments one reply level at a time, so every parent has its ID before
+// its replies are written
+func (l *Loader) loadComments(ctx context.Context, comments []*Comment, summary *Summary) error {
+	depths := make(map[string]int32, len(comments))
+	var levels [][]*Comment
+	for _, comment := range comments {
+		var depth int32
+		if comment.ParentKey != "" {
+			depth = depths[comment.ParentKey] + 1
+		}
+		depths[comment.Key] = depth
+		if int(depth) == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[depth] = append(levels[depth], comment)
+	}
+
+	ids := make(map[string]string, len(comments))
+	for depth, level := range levels {
+		for start := 0; start < len(level); start += commentBatchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			batch := level[start:min(start+commentBatchSize, len(level))]
+			created := make([]*models.Comment, len(batch))
+			for i, comment := range batch {
+				created[i] = &models.Comment{
+					ContentID: comment.ContentID,
+					UserID:    comment.UserID,
+					Content:   comment.Content,
+					CreatedAt: comment.CreatedAt,
+					UpdatedAt: comment.UpdatedAt,
+					Type:      comment.Type,
+					Depth:     int32(depth),
+					CourseID:  comment.CourseID,
+				}
+				if comment.ParentKey != "" {
+					parentID := ids[comment.ParentKey]
+					created[i].ParentID = &parentID
+				}
+			}
+			for i, err := range l.commentRepo.CreateWithTimestamps(ctx, created) {
+				if err != nil {
+					return fmt.Errorf("failed to create comment %s: %w", batch[i].Key, err)
+				}
+				ids[batch[i].Key] = created[i].ID.Hex()
+			}
+			summary.Comments += len(batch)
+		}
+		l.logger.Info("Seed progress", "comment_depth", depth, "comments", summary.Comments)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package seed
+
+import (
+	"math/rand/v2"
+	"strings"
+	"unicode"
+)
+
+// loremWords are the words generated and scrubbed text is made of
+var loremWords = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod
+	tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation
+	ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate
+	velit esse cillum eu fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt
+	culpa qui officia deserunt mollit anim id est laborum`)
+
+// loremLetters are the letters of loremWords run together, used to overwrite text letter by
+// letter
+var loremLetters = []rune(strings.Join(loremWords, ""))
+
+// lorem produces placeholder text from a random source
+type lorem struct {
+	rand *rand.Rand
+}
+
+func newLorem(r *rand.Rand) *lorem {
+	return &lorem{rand: r}
+}
+
+// words returns n words separated by spaces
+func (l *lorem) words(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = loremWords[l.rand.IntN(len(loremWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+// sentences returns n capitalized sentences
+func (l *lorem) sentences(n int) string {
+	sentences := make([]string, n)
+	for i := range sentences {
+		sentence := []rune(l.words(4 + l.rand.IntN(10)))
+		sentence[0] = unicode.ToUpper(sentence[0])
+		sentences[i] = string(sentence) + "."
+	}
+	return strings.Join(sentences, " ")
+}
+
+// overwrite returns text with every letter and digit replaced by placeholder letters. Spacing,
+// punctuation and markdown syntax are kept, so the result has the same length in characters
+// and the same layout.
+func (l *lorem) overwrite(text string) string {
+	runes := []rune(text)
+	next := l.rand.IntN(len(loremLetters))
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		replacement := loremLetters[next%len(loremLetters)]
+		next++
+		if unicode.IsUpper(r) {
+			replacement = unicode.ToUpper(replacement)
+		}
+		runes[i] = replacement
+	}
+	return string(runes)
+}
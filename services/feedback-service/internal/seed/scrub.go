@@ -0,0 +1,102 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"path"
+)
+
+// idMap assigns consecutive IDs to values in the order they are first seen, so the same value
+// always maps to the same ID
+type idMap[K comparable] struct {
+	ids map[K]int64
+}
+
+func newIDMap[K comparable]() *idMap[K] {
+	return &idMap[K]{ids: make(map[K]int64)}
+}
+
+func (m *idMap[K]) get(value K) int64 {
+	id, ok := m.ids[value]
+	if !ok {
+		id = int64(len(m.ids)) + 1
+		m.ids[value] = id
+	}
+	return id
+}
+
+// contentRef identifies what comments are written on
+type contentRef struct {
+	id          int64
+	contentType string
+}
+
+// Scrub returns an anonymized copy of a dataset. Titles, content and comments are overwritten
+// with placeholder text of the same length and layout, and attachment names keep only their
+// extension. User, submission, content and course IDs and the record keys are remapped
+// consistently: a user who reviewed one feedback and commented elsewhere is still the same
+// user, and threads, reviewer workloads and grades keep their shape. Timestamps, sizes, content
+// types, statuses and grades are kept as they are.
+func Scrub(d *Dataset, seed uint64) (*Dataset, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dataset: %w", err)
+	}
+	text := newLorem(rand.New(rand.NewPCG(seed, seed^0x5c2b)))
+	users := newIDMap[int64]()
+	submissions := newIDMap[int64]()
+	contents := newIDMap[contentRef]()
+	courses := newIDMap[string]()
+	course := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		return fmt.Sprintf("course-%d", courses.get(id))
+	}
+
+	out := &Dataset{
+		Feedbacks:   make([]*Feedback, len(d.Feedbacks)),
+		Attachments: make([]*Attachment, len(d.Attachments)),
+		Comments:    make([]*Comment, len(d.Comments)),
+	}
+
+	feedbackKeys := make(map[string]string, len(d.Feedbacks))
+	for i, feedback := range d.Feedbacks {
+		scrubbed := *feedback
+		scrubbed.Key = fmt.Sprintf("f%d", i+1)
+		scrubbed.ReviewerID = users.get(feedback.ReviewerID)
+		scrubbed.StudentID = users.get(feedback.StudentID)
+		scrubbed.SubmissionID = submissions.get(feedback.SubmissionID)
+		scrubbed.CourseID = course(feedback.CourseID)
+		scrubbed.Title = text.overwrite(feedback.Title)
+		scrubbed.Content = text.overwrite(feedback.Content)
+		if feedback.Grade != nil {
+			grade := *feedback.Grade
+			scrubbed.Grade = &grade
+		}
+		feedbackKeys[feedback.Key] = scrubbed.Key
+		out.Feedbacks[i] = &scrubbed
+	}
+
+	attachmentCounts := make(map[string]int)
+	for i, attachment := range d.Attachments {
+		scrubbed := *attachment
+		scrubbed.FeedbackKey = feedbackKeys[attachment.FeedbackKey]
+		attachmentCounts[scrubbed.FeedbackKey]++
+		scrubbed.Filename = fmt.Sprintf("attachment-%d%s", attachmentCounts[scrubbed.FeedbackKey], path.Ext(attachment.Filename))
+		out.Attachments[i] = &scrubbed
+	}
+
+	commentKeys := make(map[string]string, len(d.Comments))
+	for i, comment := range d.Comments {
+		scrubbed := *comment
+		scrubbed.Key = fmt.Sprintf("c%d", i+1)
+		scrubbed.ParentKey = commentKeys[comment.ParentKey]
+		scrubbed.ContentID = contents.get(contentRef{comment.ContentID, comment.Type})
+		scrubbed.UserID = users.get(comment.UserID)
+		scrubbed.CourseID = course(comment.CourseID)
+		scrubbed.Content = text.overwrite(comment.Content)
+		commentKeys[comment.Key] = scrubbed.Key
+		out.Comments[i] = &scrubbed
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxApprovalNoteLength is the maximum number of characters in an approver's note
+const maxApprovalNoteLength = 2000
+
+// approvalActions maps the approval status a feedback moves to onto its audit action and event
+var approvalActions = map[string]struct {
+	audit string
+	event bus.Topic[bus.FeedbackEvent]
+}{
+	models.ApprovalPending:          {models.AuditActionApprovalRequested, bus.FeedbackApprovalRequested},
+	models.ApprovalApproved:         {models.AuditActionApproved, bus.FeedbackApproved},
+	models.ApprovalChangesRequested: {models.AuditActionChangesRequested, bus.FeedbackChangesRequested},
+}
+
+// RequestFeedbackApproval asks a second reviewer to approve a feedback (author only). Until it
+// is approved the feedback is hidden from its student, even if it is published.
+func (s *FeedbackService) RequestFeedbackApproval(ctx context.Context, id uuid.UUID, reviewerID, approverID int64) (*models.Feedback, error) {
+	s.logger.Info("Requesting feedback approval", "feedback_id", id, "reviewer_id", reviewerID, "approver_id", approverID)
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if reviewerID <= 0 {
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if approverID <= 0 {
+		return nil, apperr.InvalidField("approver_id", "invalid approver ID")
+	}
+	if approverID == reviewerID {
+		return nil, authz.ErrSelfApproval
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for approval request", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if err := authz.RequestFeedbackApproval(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		return nil, err
+	}
+
+	return s.transitionApproval(ctx, feedback, reviewerID, models.ApprovalPending, approverID, "")
+}
+
+// ApproveFeedback approves a feedback awaiting approval (requested approver only), making it
+// visible to its student once published. The note is optional.
+func (s *FeedbackService) ApproveFeedback(ctx context.Context, id uuid.UUID, approverID int64, note string) (*models.Feedback, error) {
+	return s.reviewApproval(ctx, id, approverID, models.ApprovalApproved, note)
+}
+
+// RequestFeedbackChanges sends a feedback awaiting approval back to its author (requested
+// approver only) with a note explaining what to change. It stays hidden from its student.
+func (s *FeedbackService) RequestFeedbackChanges(ctx context.Context, id uuid.UUID, approverID int64, note string) (*models.Feedback, error) {
+	if strings.TrimSpace(note) == "" {
+		return nil, apperr.InvalidField("note", "a note is required when requesting changes")
+	}
+	return s.reviewApproval(ctx, id, approverID, models.ApprovalChangesRequested, note)
+}
+
+// reviewApproval records the approver's decision on a feedback awaiting approval
+func (s *FeedbackService) reviewApproval(ctx context.Context, id uuid.UUID, approverID int64, to, note string) (*models.Feedback, error) {
+	s.logger.Info("Reviewing feedback approval", "feedback_id", id, "approver_id", approverID, "approval_status", to)
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if approverID <= 0 {
+		return nil, apperr.InvalidField("approver_id", "invalid approver ID")
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxApprovalNoteLength {
+		return nil, apperr.InvalidField("note", fmt.Sprintf("note must be at most %d characters", maxApprovalNoteLength))
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for approval", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if err := authz.ReviewFeedbackApproval(authz.Principal{UserID: approverID}, feedback); err != nil {
+		return nil, err
+	}
+
+	return s.transitionApproval(ctx, feedback, approverID, to, approverID, note)
+}
+
+// transitionApproval moves an authorized feedback to a new approval status, then audits the
+// change and publishes its event. The actor is the author for requests and the approver for
+// decisions.
+func (s *FeedbackService) transitionApproval(ctx context.Context, feedback *models.Feedback, actorID int64, to string, approverID int64, note string) (*models.Feedback, error) {
+	from := feedback.ApprovalStatus
+	changed, err := s.feedbackRepo.TransitionApproval(ctx, feedback.ID, from, to, approverID, note)
+	if err != nil {
+		s.logger.Error("Failed to update feedback approval", "feedback_id", feedback.ID, "error", err)
+		return nil, fmt.Errorf("failed to update feedback approval: %w", err)
+	}
+	if !changed {
+		// Another request changed the approval status since the feedback was read
+		return nil, authz.ErrInvalidApprovalTransition.WithMessage("approval status changed concurrently; reload the feedback and try again")
+	}
+	feedback.ApprovalStatus = to
+	feedback.ApproverID = &approverID
+	feedback.ApprovalNote = note
+
+	action := approvalActions[to]
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, action.event, bus.FeedbackEvent{Feedback: feedback})
+
+	details := fmt.Sprintf("from=%s to=%s approver_id=%d", from, to, approverID)
+	if note != "" {
+		details += " note=" + note
+	}
+	entry := &models.AuditEntry{
+		FeedbackID: feedback.ID,
+		ActorID:    actorID,
+		Action:     action.audit,
+		Details:    details,
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", feedback.ID, "action", entry.Action, "error", err)
+	}
+
+	s.logger.Info("Feedback approval updated", "feedback_id", feedback.ID, "from", from, "to", to)
+	return feedback, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// SetFeedbackArchived archives a feedback, or restores it when archived is false (its author
+// or an administrator, see authz.ArchiveFeedback). Archived feedbacks are left out of lists
+// unless asked for, but can still be read by ID. Setting the current state again is a no-op;
+// actual changes are recorded in the audit log.
+func (s *FeedbackService) SetFeedbackArchived(ctx context.Context, id uuid.UUID, archived bool, actor authz.Principal) (*models.Feedback, error) {
+	s.logger.Info("Setting feedback archive state",
+		"feedback_id", id,
+		"archived", archived,
+		"actor_id", actor.UserID,
+	)
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if actor.UserID <= 0 {
+		return nil, apperr.InvalidField("actor_id", "invalid actor ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for archiving", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if err := authz.ArchiveFeedback(actor, feedback); err != nil {
+		return nil, err
+	}
+	if archived == (feedback.ArchivedAt != nil) {
+		return feedback, nil
+	}
+
+	now := time.Now()
+	changed, err := s.feedbackRepo.SetArchived(ctx, id, archived, now)
+	if err != nil {
+		s.logger.Error("Failed to set feedback archive state", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to set feedback archive state: %w", err)
+	}
+	if !changed {
+		// Archived or restored concurrently; return the stored state
+		return s.feedbackRepo.GetByID(ctx, id)
+	}
+	feedback.ArchivedAt = nil
+	action := models.AuditActionRestored
+	if archived {
+		feedback.ArchivedAt = &now
+		action = models.AuditActionArchived
+	}
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, bus.FeedbackUpdated, bus.FeedbackEvent{Feedback: feedback})
+
+	entry := &models.AuditEntry{
+		FeedbackID: id,
+		ActorID:    actor.UserID,
+		Action:     action,
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", action, "error", err)
+	}
+
+	s.logger.Info("Feedback archive state set", "feedback_id", id, "archived", archived)
+	return feedback, nil
+}
+
+// ArchiveOldFeedbacks archives every active feedback created more than the configured age
+// ago, in batches, and returns how many it archived
+func (s *FeedbackService) ArchiveOldFeedbacks(ctx context.Context) (int64, error) {
+	if s.archiveCfg.After <= 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-s.archiveCfg.After)
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		archived, err := s.feedbackRepo.ArchiveCreatedBefore(ctx, cutoff, now, s.archiveCfg.BatchSize)
+		total += archived
+		if err != nil {
+			return total, err
+		}
+		if archived < int64(s.archiveCfg.BatchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		// Archived feedbacks leave cached pages of many reviewers and students
+		s.prefetch.invalidateAll()
+		s.logger.Info("Old feedbacks archived", "archived", total, "created_before", cutoff)
+	}
+	return total, nil
+}
+
+// RunArchiver archives old feedbacks at startup and then every archive interval until ctx is
+// done. It returns at once if archiving is disabled.
+func (s *FeedbackService) RunArchiver(ctx context.Context) {
+	if s.archiveCfg.After <= 0 {
+		return
+	}
+
+	archive := func() {
+		if _, err := s.ArchiveOldFeedbacks(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Feedback archiving failed", "error", err)
+		}
+	}
+
+	archive()
+	ticker := time.NewTicker(s.archiveCfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			archive()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
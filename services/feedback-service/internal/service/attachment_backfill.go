@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/throttle"
+	"github.com/google/uuid"
+)
+
+// backfillLogEvery is the number of prefixes between progress log lines of a backfill run
+const backfillLogEvery = 100
+
+// ErrAttachmentFiltersUnavailable is returned for attachment filters on a feedback list that
+// would need more feedbacks listed in MinIO than ATTACHMENT_FILTER_MAX_PENDING allows, while the
+// attachment metadata backfill is not complete
+var ErrAttachmentFiltersUnavailable = apperr.FailedPrecondition("ATTACHMENT_METADATA_INCOMPLETE", "too many feedbacks in the list have attachment metadata still being backfilled; narrow the list or retry once the backfill is complete")
+
+// AttachmentBackfillService records feedback_assets rows for attachments stored in MinIO
+// before their metadata was recorded. Every prefix is journaled once it is done, so an
+// interrupted run resumes with the prefixes it has not finished and failed prefixes are
+// retried by the next run.
+type AttachmentBackfillService struct {
+	attachmentRepo repository.AttachmentRepository
+	assetRepo      repository.AssetRepository
+	journal        repository.BackfillJournalRepository
+	cfg            config.AttachmentMetadataConfig
+	logger         *slog.Logger
+}
+
+// NewAttachmentBackfillService creates a new attachment metadata backfill service
+func NewAttachmentBackfillService(attachmentRepo repository.AttachmentRepository, assetRepo repository.AssetRepository, journal repository.BackfillJournalRepository, cfg config.AttachmentMetadataConfig, logger *slog.Logger) *AttachmentBackfillService {
+	return &AttachmentBackfillService{
+		attachmentRepo: attachmentRepo,
+		assetRepo:      assetRepo,
+		journal:        journal,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// requestLimiter paces the MinIO requests of all backfill workers together
+type requestLimiter struct {
+	mu      sync.Mutex
+	limiter *throttle.Limiter // nil when unlimited
+}
+
+// wait blocks until n more requests may be made or ctx is done
+func (l *requestLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limiter.WaitN(ctx, n)
+}
+
+// Run walks the attachment prefixes in MinIO and backfills those not journaled as finished,
+// using up to BackfillConcurrency workers. Rows that already exist are kept. Once a run lists
+// every prefix without failures, the backfill is recorded as complete.
+func (s *AttachmentBackfillService) Run(ctx context.Context) (*models.BackfillProgress, error) {
+	finished, err := s.journal.ListFinished(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Starting attachment metadata backfill",
+		"finished_prefixes", len(finished),
+		"concurrency", s.cfg.BackfillConcurrency,
+		"requests_per_second", s.cfg.BackfillRequestsPerSecond,
+	)
+
+	limiter := &requestLimiter{limiter: throttle.New(s.cfg.BackfillRequestsPerSecond, 1)}
+	prefixes := make(chan models.AttachmentPrefix)
+	var processed, failed, created, conflicts atomic.Int64
+	var journalErr error
+	var journalOnce sync.Once
+
+	var wg sync.WaitGroup
+	for range s.cfg.BackfillConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for prefix := range prefixes {
+				entry := s.backfillPrefix(ctx, limiter, prefix)
+				if entry == nil {
+					continue // Interrupted; the prefix is backfilled by the next run
+				}
+				// Journal finished work even when the run is being cancelled
+				if err := s.journal.Record(context.WithoutCancel(ctx), entry); err != nil {
+					journalOnce.Do(func() { journalErr = err })
+					continue
+				}
+				if entry.Status == models.BackfillFailed {
+					failed.Add(1)
+				}
+				created.Add(entry.RowsCreated)
+				conflicts.Add(entry.Conflicts)
+				if n := processed.Add(1); n%backfillLogEvery == 0 {
+					s.logger.Info("Attachment metadata backfill progress",
+						"prefixes", n,
+						"rows_created", created.Load(),
+						"conflicts", conflicts.Load(),
+						"failed", failed.Load(),
+					)
+				}
+			}
+		}()
+	}
+
+	var total int64
+	var listErr error
+	stream := s.attachmentRepo.StreamPrefixes(ctx)
+walk:
+	for prefix := range stream {
+		if prefix.Err != nil {
+			listErr = prefix.Err
+			break
+		}
+		total++
+		if finished[prefix.Prefix] {
+			continue
+		}
+		select {
+		case prefixes <- prefix:
+		case <-ctx.Done():
+			break walk
+		}
+	}
+	close(prefixes)
+	wg.Wait()
+	// Let the listing goroutine finish if the walk stopped early
+	for range stream {
+	}
+
+	if journalErr != nil {
+		return nil, journalErr
+	}
+	if listErr != nil {
+		return nil, fmt.Errorf("failed to list attachment prefixes: %w", listErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	complete := failed.Load() == 0
+	if err := s.journal.FinishListing(ctx, total, complete); err != nil {
+		return nil, err
+	}
+
+	progress, err := s.journal.GetProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Attachment metadata backfill run finished",
+		"prefixes", progress.PrefixesTotal,
+		"done", progress.PrefixesDone,
+		"failed", progress.PrefixesFailed,
+		"rows_created", progress.RowsCreated,
+		"conflicts", progress.ConflictsSkipped,
+		"complete", progress.Complete,
+	)
+	return progress, nil
+}
+
+// backfillPrefix records the missing metadata rows of one prefix and returns its journal
+// entry, or nil if ctx ended before the prefix was done. A failure is journaled rather than
+// stopping the run.
+func (s *AttachmentBackfillService) backfillPrefix(ctx context.Context, limiter *requestLimiter, prefix models.AttachmentPrefix) *models.BackfillEntry {
+	entry := &models.BackfillEntry{Prefix: prefix.Prefix}
+	if prefix.FeedbackID == uuid.Nil {
+		entry.Status = models.BackfillOrphaned
+		return entry
+	}
+
+	// One listing request and one stat request per object
+	if err := limiter.wait(ctx, 1+len(prefix.Objects)); err != nil {
+		return nil
+	}
+	attachments, err := s.attachmentRepo.List(ctx, prefix.FeedbackID)
+	if err == nil {
+		var found bool
+		entry.RowsCreated, found, err = s.assetRepo.InsertMissing(ctx, prefix.FeedbackID, attachments)
+		if err == nil && !found {
+			entry.Status = models.BackfillOrphaned
+			return entry
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		s.logger.Warn("Failed to backfill attachment metadata", "prefix", prefix.Prefix, "error", err)
+		entry.Status = models.BackfillFailed
+		entry.Error = err.Error()
+		entry.RowsCreated = 0
+		return entry
+	}
+
+	entry.Status = models.BackfillDone
+	entry.Conflicts = int64(len(attachments)) - entry.RowsCreated
+	return entry
+}
+
+// Progress returns the backfill progress recorded in the journal
+func (s *AttachmentBackfillService) Progress(ctx context.Context) (*models.BackfillProgress, error) {
+	return s.journal.GetProgress(ctx)
+}
+
+// attachmentLister reads the attachments of a feedback from the source configured by
+// ATTACHMENT_LIST_SOURCE. In dual mode PostgreSQL is read for prefixes that are backfilled and
+// MinIO is listed for the others, until the backfill is complete.
+type attachmentLister struct {
+	cfg            config.AttachmentMetadataConfig
+	source         string
+	attachmentRepo repository.AttachmentRepository
+	assetRepo      repository.AssetRepository
+	journal        repository.BackfillJournalRepository
+	complete       atomic.Bool // Backfill found complete; the journal is no longer consulted
+}
+
+// newAttachmentLister creates the attachment lister of a feedback service
+func newAttachmentLister(cfg config.AttachmentMetadataConfig, attachmentRepo repository.AttachmentRepository, assetRepo repository.AssetRepository, journal repository.BackfillJournalRepository) *attachmentLister {
+	source := cfg.ListSource
+	if source == "" || journal == nil {
+		source = config.AttachmentListStorage
+	}
+	return &attachmentLister{
+		cfg:            cfg,
+		source:         source,
+		attachmentRepo: attachmentRepo,
+		assetRepo:      assetRepo,
+		journal:        journal,
+	}
+}
+
+// list returns the attachments of a feedback
+func (l *attachmentLister) list(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
+	switch l.source {
+	case config.AttachmentListTable:
+		return l.assetRepo.List(ctx, feedbackID)
+	case config.AttachmentListDual:
+		backfilled, err := l.backfilled(ctx, feedbackID)
+		if err != nil {
+			return nil, err
+		}
+		if backfilled {
+			return l.assetRepo.List(ctx, feedbackID)
+		}
+	}
+	return l.attachmentRepo.List(ctx, feedbackID)
+}
+
+// backfilled reports whether the metadata of a feedback's attachments is fully recorded
+func (l *attachmentLister) backfilled(ctx context.Context, feedbackID uuid.UUID) (bool, error) {
+	if l.complete.Load() {
+		return true, nil
+	}
+	finished, complete, err := l.journal.IsFinished(ctx, feedbackID.String())
+	if err != nil {
+		return false, err
+	}
+	if complete {
+		l.complete.Store(true)
+	}
+	return finished || complete, nil
+}
+
+// tableComplete reports whether feedback_assets records every attachment: the table is the
+// configured source, or the backfill is complete
+func (l *attachmentLister) tableComplete(ctx context.Context) (bool, error) {
+	if l.source == config.AttachmentListTable || l.complete.Load() {
+		return true, nil
+	}
+	if l.journal == nil {
+		return false, nil
+	}
+	progress, err := l.journal.GetProgress(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get backfill progress: %w", err)
+	}
+	if progress.Complete {
+		l.complete.Store(true)
+	}
+	return progress.Complete, nil
+}
+
+// resolveAttachmentFilters prepares the attachment filters of a feedback list. They are answered
+// from feedback_assets, which may miss attachments stored before their metadata was recorded
+// until the backfill is complete; meanwhile the attachments of the listed feedbacks whose prefix
+// is not backfilled are counted in MinIO and passed along in filter.AttachmentCounts. It fails
+// with ErrAttachmentFiltersUnavailable when there are more such feedbacks than
+// ATTACHMENT_FILTER_MAX_PENDING.
+func (s *FeedbackService) resolveAttachmentFilters(ctx context.Context, filter models.FeedbackFilter) (models.FeedbackFilter, error) {
+	if filter.HasAttachments == nil && filter.MinAttachments == nil {
+		return filter, nil
+	}
+	complete, err := s.lister.tableComplete(ctx)
+	if err != nil {
+		return filter, err
+	}
+	if complete {
+		return filter, nil
+	}
+
+	maxPending := s.lister.cfg.FilterMaxPending
+	pending, err := s.feedbackRepo.ListAttachmentPending(ctx, filter, maxPending+1)
+	if err != nil {
+		return filter, err
+	}
+	if len(pending) > maxPending {
+		s.logger.Warn("Attachment filters need too many storage listings",
+			"max_pending", maxPending,
+			"reviewer_id", filter.ReviewerID,
+			"student_id", filter.StudentID,
+		)
+		return filter, ErrAttachmentFiltersUnavailable
+	}
+	counts, err := s.countStoredAttachments(ctx, pending)
+	if err != nil {
+		return filter, err
+	}
+	filter.AttachmentCounts = counts
+	return filter, nil
+}
+
+// countStoredAttachments counts the attachments of feedbacks in MinIO, listing up to
+// ATTACHMENT_FILTER_CONCURRENCY prefixes at a time. The first failure cancels the others.
+func (s *FeedbackService) countStoredAttachments(ctx context.Context, feedbackIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	if len(feedbackIDs) == 0 {
+		return nil, nil
+	}
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var firstErr error
+	counts := make(map[uuid.UUID]int64, len(feedbackIDs))
+	work := make(chan uuid.UUID)
+	var wg sync.WaitGroup
+	for range min(s.lister.cfg.FilterConcurrency, len(feedbackIDs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range work {
+				count, err := s.attachmentRepo.Count(listCtx, id)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				counts[id] = count
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, id := range feedbackIDs {
+		select {
+		case work <- id:
+		case <-listCtx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, fmt.Errorf("failed to count stored attachments: %w", firstErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// countingAttachments counts the objects of a feedback slowly, recording how many counts overlap
+type countingAttachments struct {
+	repository.AttachmentRepository
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	calls    atomic.Int64
+	failOn   uuid.UUID
+}
+
+func (r *countingAttachments) Count(ctx context.Context, feedbackID uuid.UUID) (int64, error) {
+	r.calls.Add(1)
+	r.mu.Lock()
+	r.inFlight++
+	r.peak = max(r.peak, r.inFlight)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+
+	if feedbackID == r.failOn {
+		return 0, errors.New("storage unavailable")
+	}
+	select {
+	case <-time.After(5 * time.Millisecond):
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	return int64(feedbackID[0]), nil
+}
+
+func TestCountStoredAttachments(t *testing.T) {
+	ids := make([]uuid.UUID, 20)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		ids         []uuid.UUID
+		failOn      uuid.UUID
+		wantErr     bool
+		maxCalls    int64
+	}{
+		{name: "bounded by the concurrency", concurrency: 4, ids: ids, maxCalls: 20},
+		{name: "one at a time", concurrency: 1, ids: ids, maxCalls: 20},
+		{name: "fewer feedbacks than workers", concurrency: 8, ids: ids[:3], maxCalls: 3},
+		{name: "no feedbacks", concurrency: 8, ids: nil},
+		// The first failure stops the remaining listings
+		{name: "storage failure", concurrency: 2, ids: ids, failOn: ids[0], wantErr: true, maxCalls: 19},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachments := &countingAttachments{failOn: tt.failOn}
+			s := &FeedbackService{
+				attachmentRepo: attachments,
+				lister:         &attachmentLister{cfg: config.AttachmentMetadataConfig{FilterConcurrency: tt.concurrency}},
+			}
+
+			counts, err := s.countStoredAttachments(context.Background(), tt.ids)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("countStoredAttachments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attachments.peak > tt.concurrency {
+				t.Errorf("countStoredAttachments() ran %d counts at once, want at most %d", attachments.peak, tt.concurrency)
+			}
+			if calls := attachments.calls.Load(); calls > tt.maxCalls {
+				t.Errorf("countStoredAttachments() made %d counts, want at most %d", calls, tt.maxCalls)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(counts) != len(tt.ids) {
+				t.Fatalf("countStoredAttachments() = %d counts, want %d", len(counts), len(tt.ids))
+			}
+			for _, id := range tt.ids {
+				if counts[id] != int64(id[0]) {
+					t.Errorf("countStoredAttachments()[%s] = %d, want %d", id, counts[id], id[0])
+				}
+			}
+		})
+	}
+}
+
+func TestCountStoredAttachmentsCanceled(t *testing.T) {
+	ids := make([]uuid.UUID, 50)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	attachments := &countingAttachments{}
+	s := &FeedbackService{
+		attachmentRepo: attachments,
+		lister:         &attachmentLister{cfg: config.AttachmentMetadataConfig{FilterConcurrency: 2}},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Millisecond)
+	defer cancel()
+	if _, err := s.countStoredAttachments(ctx, ids); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("countStoredAttachments() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if calls := attachments.calls.Load(); calls >= int64(len(ids)) {
+		t.Errorf("countStoredAttachments() made %d counts after the deadline, want fewer than %d", calls, len(ids))
+	}
+}
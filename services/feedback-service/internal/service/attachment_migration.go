@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+)
+
+var (
+	// ErrMigrationNotActive is returned when objects are copied while no migration is configured
+	// or after cutover
+	ErrMigrationNotActive = errors.New("attachment migration is not enabled or already cut over")
+
+	// ErrMigrationIncomplete is returned when cutover is configured before every legacy object
+	// was migrated
+	ErrMigrationIncomplete = errors.New("attachment migration is incomplete")
+)
+
+// AttachmentMigrationService copies attachments from the legacy location to the current one.
+// Every object's outcome is journaled together with the listing cursor, so an interrupted run
+// resumes where it stopped and failed objects are retried by the next run.
+type AttachmentMigrationService struct {
+	attachmentRepo repository.AttachmentRepository
+	journal        repository.MigrationJournalRepository
+	cfg            config.MigrationConfig
+	logger         *slog.Logger
+}
+
+// NewAttachmentMigrationService creates a new attachment migration service
+func NewAttachmentMigrationService(attachmentRepo repository.AttachmentRepository, journal repository.MigrationJournalRepository, cfg config.MigrationConfig, logger *slog.Logger) *AttachmentMigrationService {
+	return &AttachmentMigrationService{
+		attachmentRepo: attachmentRepo,
+		journal:        journal,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Run retries previously failed objects, then copies the legacy objects after the journaled
+// cursor in batches. With restart the legacy location is listed again from the start. A
+// cancelled run journals the objects it finished before returning.
+func (s *AttachmentMigrationService) Run(ctx context.Context, restart bool) (*models.MigrationProgress, error) {
+	if !s.cfg.ReadsLegacy() {
+		return nil, ErrMigrationNotActive
+	}
+	if restart {
+		if err := s.journal.RestartListing(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	failed, err := s.journal.ListFailed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		s.logger.Info("Retrying failed attachment migrations", "objects", len(failed))
+	}
+	for start := 0; start < len(failed); start += s.cfg.BatchSize {
+		batch := failed[start:min(start+s.cfg.BatchSize, len(failed))]
+		entries, _ := s.migrateBatch(ctx, batch)
+		if err := s.journal.RecordBatch(context.WithoutCancel(ctx), entries, nil); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	progress, err := s.journal.GetProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor := progress.Cursor
+	for !progress.ListingComplete {
+		keys, err := s.attachmentRepo.ListLegacyObjects(ctx, cursor, s.cfg.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			if err := s.journal.RecordBatch(ctx, nil, &models.MigrationCursor{Key: cursor, ListingComplete: true}); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		entries, last := s.migrateBatch(ctx, keys)
+		if last != "" {
+			cursor = last
+		}
+		// Journal what was finished even when the run was cancelled mid-batch
+		if err := s.journal.RecordBatch(context.WithoutCancel(ctx), entries, &models.MigrationCursor{Key: cursor}); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.logger.Info("Attachment migration batch done", "objects", len(entries), "cursor", cursor)
+	}
+
+	progress, err = s.journal.GetProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Attachment migration run finished",
+		"copied", progress.Copied,
+		"skipped", progress.Skipped,
+		"missing", progress.Missing,
+		"failed", progress.Failed,
+		"complete", progress.Complete,
+	)
+	return progress, nil
+}
+
+// migrateBatch migrates keys in order until ctx is cancelled and returns their journal entries
+// and the last key it finished. A failed object is journaled as failed rather than stopping
+// the batch.
+func (s *AttachmentMigrationService) migrateBatch(ctx context.Context, keys []string) ([]*models.MigrationEntry, string) {
+	entries := make([]*models.MigrationEntry, 0, len(keys))
+	var last string
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		entry, err := s.attachmentRepo.MigrateObject(ctx, key)
+		if err != nil {
+			if ctx.Err() != nil {
+				// Interrupted rather than failed; the object is copied by the next run
+				break
+			}
+			s.logger.Warn("Failed to migrate attachment", "object", key, "error", err)
+			entry = &models.MigrationEntry{Key: key, Status: models.MigrationFailed, Error: err.Error()}
+		}
+		entries = append(entries, entry)
+		last = key
+	}
+	return entries, last
+}
+
+// Progress returns the migration progress recorded in the journal
+func (s *AttachmentMigrationService) Progress(ctx context.Context) (*models.MigrationProgress, error) {
+	return s.journal.GetProgress(ctx)
+}
+
+// CheckCutover returns ErrMigrationIncomplete unless the journal shows that every legacy object
+// was migrated, so reads may stop falling back to the legacy location
+func (s *AttachmentMigrationService) CheckCutover(ctx context.Context) error {
+	progress, err := s.journal.GetProgress(ctx)
+	if err != nil {
+		return err
+	}
+	if !progress.Complete {
+		return fmt.Errorf("%w: %.1f%% done, %d failed", ErrMigrationIncomplete, progress.Percent, progress.Failed)
+	}
+	return nil
+}
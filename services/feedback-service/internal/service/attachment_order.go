@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// ErrNotFeedbackAuthor is returned when someone other than the author changes a feedback or its attachments
+var ErrNotFeedbackAuthor = authz.ErrNotFeedbackAuthor
+
+// AttachmentOrderError reports how a requested attachment order differs from the current attachments
+type AttachmentOrderError struct {
+	Missing    []string // Current attachments left out of the order
+	Extra      []string // Listed files that are not attachments of the feedback
+	Duplicates []string // Files listed more than once
+}
+
+func (e *AttachmentOrderError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Extra) > 0 {
+		parts = append(parts, "unknown: "+strings.Join(e.Extra, ", "))
+	}
+	if len(e.Duplicates) > 0 {
+		parts = append(parts, "duplicated: "+strings.Join(e.Duplicates, ", "))
+	}
+	return "attachment order must list every attachment exactly once (" + strings.Join(parts, "; ") + ")"
+}
+
+// compareAttachmentOrder checks that filenames lists every current attachment exactly once
+func compareAttachmentOrder(filenames []string, attachments []*models.AttachmentInfo) *AttachmentOrderError {
+	current := make(map[string]bool, len(attachments))
+	for _, info := range attachments {
+		current[info.Filename] = true
+	}
+
+	orderErr := &AttachmentOrderError{}
+	seen := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		switch {
+		case seen[filename]:
+			orderErr.Duplicates = append(orderErr.Duplicates, filename)
+		case !current[filename]:
+			orderErr.Extra = append(orderErr.Extra, filename)
+		}
+		seen[filename] = true
+	}
+	for _, info := range attachments {
+		if !seen[info.Filename] {
+			orderErr.Missing = append(orderErr.Missing, info.Filename)
+		}
+	}
+
+	if len(orderErr.Missing) == 0 && len(orderErr.Extra) == 0 && len(orderErr.Duplicates) == 0 {
+		return nil
+	}
+	return orderErr
+}
+
+// sortAttachments puts items in the reviewer's order. Items without a position follow the
+// ordered ones by upload time, then by filename.
+func sortAttachments[T any](items []T, positions map[string]int, key func(T) (string, time.Time)) {
+	sort.SliceStable(items, func(i, j int) bool {
+		nameI, uploadedI := key(items[i])
+		nameJ, uploadedJ := key(items[j])
+		posI, orderedI := positions[nameI]
+		posJ, orderedJ := positions[nameJ]
+		switch {
+		case orderedI && orderedJ:
+			return posI < posJ
+		case orderedI != orderedJ:
+			return orderedI
+		case !uploadedI.Equal(uploadedJ):
+			return uploadedI.Before(uploadedJ)
+		default:
+			return nameI < nameJ
+		}
+	})
+}
+
+// attachmentPositions loads the reviewer's attachment order. The order is cosmetic, so a failure
+// is logged and the attachments keep their storage order.
+func (s *FeedbackService) attachmentPositions(ctx context.Context, feedbackID uuid.UUID) (map[string]int, bool) {
+	positions, err := s.assetRepo.ListOrder(ctx, feedbackID)
+	if err != nil {
+		s.logger.Error("Failed to load attachment order", "feedback_id", feedbackID, "error", err)
+		return nil, false
+	}
+	return positions, true
+}
+
+// orderAttachmentInfos sorts attachments into the reviewer's order
+func (s *FeedbackService) orderAttachmentInfos(ctx context.Context, feedbackID uuid.UUID, attachments []*models.AttachmentInfo) {
+	if positions, ok := s.attachmentPositions(ctx, feedbackID); ok {
+		sortAttachments(attachments, positions, func(info *models.AttachmentInfo) (string, time.Time) {
+			return info.Filename, info.UploadedAt
+		})
+	}
+}
+
+// orderAttachmentLocations sorts attachment locations into the reviewer's order
+func (s *FeedbackService) orderAttachmentLocations(ctx context.Context, feedbackID uuid.UUID, locations []*models.AttachmentLocationInfo) {
+	if positions, ok := s.attachmentPositions(ctx, feedbackID); ok {
+		sortAttachments(locations, positions, func(info *models.AttachmentLocationInfo) (string, time.Time) {
+			return info.Filename, info.UploadedAt
+		})
+	}
+}
+
+// SetAttachmentOrder sets the order in which a feedback's attachments are listed (author only).
+// filenames must name every current attachment exactly once. Attachments uploaded later are
+// listed after the ordered ones; deleting an attachment removes it from the order.
+func (s *FeedbackService) SetAttachmentOrder(ctx context.Context, feedbackID uuid.UUID, reviewerID int64, filenames []string) ([]*models.AttachmentInfo, error) {
+	s.logger.Info("Setting attachment order",
+		"feedback_id", feedbackID,
+		"reviewer_id", reviewerID,
+		"count", len(filenames),
+	)
+
+	if feedbackID == uuid.Nil {
+		return nil, fmt.Errorf("invalid feedback ID")
+	}
+	if reviewerID <= 0 {
+		return nil, fmt.Errorf("invalid reviewer ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("feedback not found: %w", err)
+	}
+	if err := authz.ChangeAttachments(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		s.logger.Warn("Attachment ordering denied",
+			"feedback_id", feedbackID,
+			"owner_id", feedback.ReviewerID,
+			"attempted_by_id", reviewerID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.List(ctx, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	if orderErr := compareAttachmentOrder(filenames, attachments); orderErr != nil {
+		return nil, orderErr
+	}
+
+	byName := make(map[string]*models.AttachmentInfo, len(attachments))
+	for _, info := range attachments {
+		byName[info.Filename] = info
+	}
+	ordered := make([]*models.AttachmentInfo, len(filenames))
+	for i, filename := range filenames {
+		ordered[i] = byName[filename]
+	}
+
+	if err := s.assetRepo.SetOrder(ctx, feedbackID, ordered); err != nil {
+		s.logger.Error("Failed to save attachment order", "feedback_id", feedbackID, "error", err)
+		return nil, fmt.Errorf("failed to save attachment order: %w", err)
+	}
+
+	s.logger.Info("Attachment order set", "feedback_id", feedbackID, "count", len(ordered))
+	return ordered, nil
+}
@@ -2,68 +2,157 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/render"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/textenc"
+)
+
+var (
+	// ErrCommentDepthExceeded is returned when a reply would be nested deeper than the configured limit
+	ErrCommentDepthExceeded = errors.New("maximum reply depth exceeded")
+
+	// ErrCommentEditWindowClosed is returned when a comment is edited after its course's edit window
+	ErrCommentEditWindowClosed = authz.ErrCommentEditWindowClosed
 )
 
+// CommentDepthError reports the depth limit a reply exceeded; it matches ErrCommentDepthExceeded
+type CommentDepthError struct {
+	MaxDepth int32
+}
+
+func (e *CommentDepthError) Error() string {
+	return fmt.Sprintf("%v: replies are limited to depth %d", ErrCommentDepthExceeded, e.MaxDepth)
+}
+
+// Is makes errors.Is(err, ErrCommentDepthExceeded) match
+func (e *CommentDepthError) Is(target error) bool {
+	return target == ErrCommentDepthExceeded
+}
+
 // CommentService handles comment business logic
 type CommentService struct {
 	commentRepo repository.CommentRepository
+	cfg         config.CommentConfig
+	policies    *CoursePolicyService
+	feedbacks   FeedbackLookup // Checks that discussed feedback exists; nil skips the check
+	events      *bus.Bus
+	watchers    *commentWatchHub // Streams of WatchComments, fed by RunCommentWatch
 	logger      *slog.Logger
 }
 
-// NewCommentService creates a new comment service
-func NewCommentService(commentRepo repository.CommentRepository, logger *slog.Logger) *CommentService {
+// NewCommentService creates a new comment service; feedbacks may be nil
+func NewCommentService(commentRepo repository.CommentRepository, cfg config.CommentConfig, policies *CoursePolicyService, feedbacks FeedbackLookup, events *bus.Bus, logger *slog.Logger) *CommentService {
 	return &CommentService{
 		commentRepo: commentRepo,
+		cfg:         cfg,
+		policies:    policies,
+		feedbacks:   feedbacks,
+		events:      events,
+		watchers:    newEventHub[string, models.CommentStreamEvent](cfg.WatchBufferSize, cfg.WatchReplayWindow),
 		logger:      logger,
 	}
 }
 
-// CreateComment creates a new comment
-func (s *CommentService) CreateComment(ctx context.Context, contentID, userID int64, parentID *string, content string, commentType string) (*models.Comment, error) {
+// MaxDepthForCourse returns the deepest reply level allowed in the threads of a course; an empty
+// course ID returns the global limit
+func (s *CommentService) MaxDepthForCourse(ctx context.Context, courseID string) (int32, error) {
+	policy, err := s.policies.Resolve(ctx, courseID)
+	if err != nil {
+		return 0, err
+	}
+	return policy.MaxCommentDepth, nil
+}
+
+// replyDepth returns the depth of a reply to parent, or a CommentDepthError if it is too deep
+func replyDepth(parent *models.Comment, maxDepth int32) (int32, error) {
+	depth := parent.Depth + 1
+	if depth > maxDepth {
+		return 0, &CommentDepthError{MaxDepth: maxDepth}
+	}
+	return depth, nil
+}
+
+// CreateComment creates a new comment. A reply belongs to its parent's course when it has one;
+// otherwise courseID (which may be empty) selects the course policy.
+func (s *CommentService) CreateComment(ctx context.Context, contentID int64, contentRef string, userID int64, parentID *string, content string, commentType string, courseID string) (*models.Comment, error) {
 	// Validate input
-	if contentID <= 0 {
-		return nil, fmt.Errorf("invalid content ID")
+	if err := validateCommentType(commentType); err != nil {
+		return nil, err
+	}
+	contentRef, err := ValidateCommentTarget(commentType, contentID, contentRef)
+	if err != nil {
+		return nil, err
 	}
 	if userID <= 0 {
 		return nil, fmt.Errorf("invalid user ID")
 	}
-	if content == "" {
-		return nil, fmt.Errorf("content is required")
+	content, err = s.cleanContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateCourseID(courseID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCourseID, err)
 	}
-	if commentType == "" {
-		return nil, fmt.Errorf("type is required")
+
+	if err := s.checkTarget(ctx, commentType, contentRef); err != nil {
+		return nil, err
 	}
 
-	// Validate parent comment exists if specified
+	// Validate parent comment exists if specified and the reply stays within the depth limit
+	var depth int32
 	if parentID != nil && *parentID != "" {
-		_, err := s.commentRepo.GetByID(ctx, *parentID)
+		parent, err := s.commentRepo.GetByID(ctx, *parentID)
 		if err != nil {
 			return nil, fmt.Errorf("parent comment not found: %w", err)
 		}
+		if parent.CourseID != "" {
+			courseID = parent.CourseID
+		}
+		policy, err := s.policies.Resolve(ctx, courseID)
+		if err != nil {
+			return nil, err
+		}
+		if depth, err = replyDepth(parent, policy.MaxCommentDepth); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create comment
 	comment := &models.Comment{
-		ContentID: contentID,
-		UserID:    userID,
-		ParentID:  parentID,
-		Content:   content,
-		Type:      commentType,
+		ContentID:  contentID,
+		ContentRef: contentRef,
+		UserID:     userID,
+		ParentID:   parentID,
+		Content:    content,
+		Type:       commentType,
+		Depth:      depth,
+		CourseID:   courseID,
 	}
 
 	// Save to MongoDB
 	if err := s.commentRepo.Create(ctx, comment); err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
+	bus.Publish(ctx, s.events, bus.CommentCreated, bus.CommentEvent{Comment: comment})
 
 	s.logger.Info("Comment created successfully",
 		"comment_id", comment.ID.Hex(),
 		"content_id", contentID,
+		"content_ref", contentRef,
 		"user_id", userID,
 		"parent_id", parentID,
 	)
@@ -71,6 +160,19 @@ func (s *CommentService) CreateComment(ctx context.Context, contentID, userID in
 	return comment, nil
 }
 
+// cleanContent checks the length of new comment content and returns it sanitized and trimmed,
+// as it is stored. Content that is empty once sanitized and trimmed is rejected.
+func (s *CommentService) cleanContent(content string) (string, error) {
+	if utf8.RuneCountInString(content) > s.cfg.MaxContentChars {
+		return "", apperr.InvalidField("content", fmt.Sprintf("content must be at most %d characters", s.cfg.MaxContentChars))
+	}
+	content = strings.TrimSpace(render.SanitizeSource(content))
+	if content == "" {
+		return "", apperr.InvalidField("content", "content must not be empty or whitespace only")
+	}
+	return content, nil
+}
+
 // GetComment retrieves a comment by ID
 func (s *CommentService) GetComment(ctx context.Context, id string) (*models.Comment, error) {
 	comment, err := s.commentRepo.GetByID(ctx, id)
@@ -81,10 +183,11 @@ func (s *CommentService) GetComment(ctx context.Context, id string) (*models.Com
 	return comment, nil
 }
 
-// UpdateComment updates an existing comment
-func (s *CommentService) UpdateComment(ctx context.Context, id, content string) (*models.Comment, error) {
-	if content == "" {
-		return nil, fmt.Errorf("content is required")
+// UpdateComment updates an existing comment (author only) within the edit window of its course
+func (s *CommentService) UpdateComment(ctx context.Context, id string, userID int64, content string) (*models.Comment, error) {
+	content, err := s.cleanContent(content)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get existing comment
@@ -93,24 +196,64 @@ func (s *CommentService) UpdateComment(ctx context.Context, id, content string)
 		return nil, fmt.Errorf("failed to get comment: %w", err)
 	}
 
+	// Enforce ownership and the edit window of the comment's course
+	policy, err := s.policies.Resolve(ctx, comment.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authz.UpdateComment(authz.Principal{UserID: userID}, comment, policy, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Saving the same content again is not an edit
+	if content == comment.Content {
+		return comment, nil
+	}
+
 	// Update content
 	comment.Content = content
 
-	// Save changes
+	// Save changes; the repository keeps the replaced version in the edit history
 	if err := s.commentRepo.Update(ctx, comment); err != nil {
 		return nil, fmt.Errorf("failed to update comment: %w", err)
 	}
+	bus.Publish(ctx, s.events, bus.CommentUpdated, bus.CommentEvent{Comment: comment})
 
 	return comment, nil
 }
 
-// DeleteComment deletes a comment and all its replies
-func (s *CommentService) DeleteComment(ctx context.Context, id string) error {
-	// Check if comment exists
-	_, err := s.commentRepo.GetByID(ctx, id)
+// GetCommentEditHistory returns a comment and its previous versions, newest first, to its
+// author and moderators. Only the last models.MaxCommentEdits versions are kept; the comment's
+// EditCount tells how many edits were made in all.
+func (s *CommentService) GetCommentEditHistory(ctx context.Context, id string, principal authz.Principal) (*models.Comment, []models.CommentEdit, error) {
+	s.logger.Info("Getting comment edit history", "comment_id", id, "user_id", principal.UserID, "admin", principal.Admin)
+
+	if principal.UserID <= 0 {
+		return nil, nil, apperr.InvalidField("user_id", "invalid user ID")
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if err := authz.ViewCommentEditHistory(principal, comment); err != nil {
+		return nil, nil, err
+	}
+
+	edits := slices.Clone(comment.Edits)
+	slices.Reverse(edits)
+	return comment, edits, nil
+}
+
+// DeleteComment deletes a comment and all its replies (author only)
+func (s *CommentService) DeleteComment(ctx context.Context, id string, userID int64) error {
+	comment, err := s.commentRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get comment: %w", err)
 	}
+	if err := authz.DeleteComment(authz.Principal{UserID: userID}, comment); err != nil {
+		return err
+	}
 
 	// Delete comment and all replies (handled by repository)
 	err = s.commentRepo.WithTransaction(ctx, func(txRepo repository.CommentTxRepository) error {
@@ -119,43 +262,71 @@ func (s *CommentService) DeleteComment(ctx context.Context, id string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
+	bus.Publish(ctx, s.events, bus.CommentDeleted, bus.CommentDeletedEvent{CommentID: id})
 
 	s.logger.Info("Comment deleted successfully", "comment_id", id)
 
 	return nil
 }
 
-// ListComments lists comments by content ID
-func (s *CommentService) ListComments(ctx context.Context, contentID int64, parentID *string, page, limit int32, commentType string) ([]*models.Comment, int32, error) {
+// ListComments lists comments by content ID. The filter optionally narrows the list to some
+// authors, a creation window [CreatedAfter, CreatedBefore) and top-level comments without
+// replies; page and limit are normalized. Comments carry their reaction counts and, with
+// filter.ViewerUserID, the viewer's own reaction. A non-nil filter.After continues the list
+// from a cursor instead of the page; the cursor of the following page is returned, or nil on
+// the last page.
+func (s *CommentService) ListComments(ctx context.Context, filter models.CommentFilter) ([]*models.Comment, int64, *models.CommentCursor, error) {
 	s.logger.Info("Listing comments",
-		"content_id", contentID,
-		"parent_id", parentID,
-		"page", page,
-		"limit", limit,
-		"type", commentType,
+		"content_id", filter.ContentID,
+		"content_ref", filter.ContentRef,
+		"parent_id", filter.ParentID,
+		"user_ids", len(filter.UserIDs),
+		"created_after", filter.CreatedAfter,
+		"created_before", filter.CreatedBefore,
+		"unanswered_only", filter.UnansweredOnly,
+		"sort", filter.Sort,
+		"page", filter.Page,
+		"after", filter.After != nil,
+		"limit", filter.Limit,
+		"type", filter.Type,
 	)
 
-	if contentID <= 0 {
-		return nil, 0, fmt.Errorf("invalid content ID")
+	contentRef, err := ValidateCommentTarget(filter.Type, filter.ContentID, filter.ContentRef)
+	if err != nil {
+		return nil, 0, nil, err
 	}
-	if page <= 0 {
-		page = 1
+	filter.ContentRef = contentRef
+	if len(filter.UserIDs) > models.MaxCommentFilterUsers {
+		return nil, 0, nil, apperr.InvalidField("user_ids", fmt.Sprintf("at most %d user IDs are allowed", models.MaxCommentFilterUsers))
 	}
-	if limit <= 0 || limit > 100 {
-		limit = 20
+	if filter.UnansweredOnly && filter.ParentID != nil {
+		return nil, 0, nil, apperr.InvalidField("unanswered_only", "unanswered_only lists top-level comments and cannot be combined with parent_id")
 	}
-
-	filter := models.CommentFilter{
-		ContentID: contentID,
-		ParentID:  parentID,
-		Page:      page,
-		Limit:     limit,
-		Type:      commentType,
+	switch filter.Sort {
+	case "":
+		filter.Sort = models.CommentSortNewest
+	case models.CommentSortNewest, models.CommentSortOldest, models.CommentSortMostReplies:
+	default:
+		return nil, 0, nil, apperr.InvalidField("sort", fmt.Sprintf("unknown sort %q", filter.Sort))
+	}
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
 	}
 
+	limit := filter.Limit
+	if filter.After != nil {
+		filter.Limit++ // One more comment tells whether another page follows
+	}
 	comments, totalCount, err := s.commentRepo.ListByContext(ctx, filter)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list comments: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	comments, next := commentPage(comments, totalCount, filter.Sort, filter.After, filter.Page, limit)
+	if err := s.commentRepo.LoadReactions(ctx, comments, filter.ViewerUserID); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to load comment reactions: %w", err)
 	}
 
 	s.logger.Info("Comments listed successfully",
@@ -163,17 +334,79 @@ func (s *CommentService) ListComments(ctx context.Context, contentID int64, pare
 		"total_count", totalCount,
 	)
 
-	return comments, totalCount, nil
+	return comments, totalCount, next, nil
+}
+
+// commentPage trims a page read after a cursor, fetched with one comment more than limit, and
+// returns the cursor of the following page, nil on the last page. Without a cursor, the total
+// tells whether more pages follow.
+func commentPage(comments []*models.Comment, total int64, sort string, after *models.CommentCursor, page, limit int32) ([]*models.Comment, *models.CommentCursor) {
+	var more bool
+	if after != nil {
+		more = len(comments) > int(limit)
+		if more {
+			comments = comments[:limit]
+		}
+	} else {
+		more = int64(page)*int64(limit) < total
+	}
+	if !more || len(comments) == 0 {
+		return comments, nil
+	}
+	return comments, models.CommentCursorOf(comments[len(comments)-1], sort)
 }
 
-// GetCommentReplies gets replies to a specific comment
-func (s *CommentService) GetCommentReplies(ctx context.Context, commentID string, page, limit int32) ([]*models.Comment, int32, error) {
-	// Check if parent comment exists
-	_, err := s.commentRepo.GetByID(ctx, commentID)
+// GetCommentReplies gets replies to a specific comment in the order of sort, oldest first by
+// default, after a cursor when set and from page otherwise, with the cursor of the following
+// page as in ListComments
+func (s *CommentService) GetCommentReplies(ctx context.Context, commentID string, sort string, after *models.CommentCursor, page, limit int32) ([]*models.Comment, int64, *models.CommentCursor, error) {
+	switch sort {
+	case "":
+		sort = models.CommentSortOldest
+	case models.CommentSortNewest, models.CommentSortOldest:
+	default:
+		return nil, 0, nil, apperr.InvalidField("sort", fmt.Sprintf("replies cannot be sorted by %q", sort))
+	}
+
+	// A missing parent fails with ErrCommentNotFound rather than listing no replies, which would
+	// look the same as a comment nobody answered
+	if _, err := s.commentRepo.GetByID(ctx, commentID); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to get parent comment: %w", err)
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	fetch := limit
+	if after != nil {
+		fetch++ // One more reply tells whether another page follows
+	}
+	replies, totalCount, err := s.commentRepo.ListReplies(ctx, commentID, sort, after, page, fetch)
 	if err != nil {
-		return nil, 0, fmt.Errorf("parent comment not found: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to get comment replies: %w", err)
 	}
 
+	replies, next := commentPage(replies, totalCount, sort, after, page, limit)
+	return replies, totalCount, next, nil
+}
+
+// ListUserComments lists the comments a user wrote, newest first, optionally only those on one
+// type of content; page and limit are normalized
+func (s *CommentService) ListUserComments(ctx context.Context, userID int64, contentType string, page, limit int32) ([]*models.Comment, int64, error) {
+	s.logger.Info("Listing user comments",
+		"user_id", userID,
+		"type", contentType,
+		"page", page,
+		"limit", limit,
+	)
+
+	if userID <= 0 {
+		return nil, 0, apperr.InvalidField("user_id", "invalid user ID")
+	}
 	if page <= 0 {
 		page = 1
 	}
@@ -181,10 +414,312 @@ func (s *CommentService) GetCommentReplies(ctx context.Context, commentID string
 		limit = 20
 	}
 
-	replies, totalCount, err := s.commentRepo.ListReplies(ctx, commentID, page, limit)
+	comments, totalCount, err := s.commentRepo.ListByUser(ctx, userID, contentType, page, limit)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get comment replies: %w", err)
+		return nil, 0, fmt.Errorf("failed to list user comments: %w", err)
 	}
 
-	return replies, totalCount, nil
+	s.logger.Info("User comments listed successfully",
+		"user_id", userID,
+		"count", len(comments),
+		"total_count", totalCount,
+	)
+	return comments, totalCount, nil
+}
+
+// CountComments returns the number of comments on a content, or of its top-level comments
+// without includeReplies
+func (s *CommentService) CountComments(ctx context.Context, contentID int64, contentType string, includeReplies bool) (int64, error) {
+	s.logger.Info("Counting comments",
+		"content_id", contentID,
+		"type", contentType,
+		"include_replies", includeReplies,
+	)
+
+	if contentID <= 0 {
+		return 0, apperr.InvalidField("content_id", "invalid content ID")
+	}
+	if contentType == "" {
+		return 0, apperr.InvalidField("type", "type is required")
+	}
+	if models.CommentTypeUsesRef(contentType) {
+		return 0, apperr.InvalidField("type", fmt.Sprintf("%q comments are counted by the total_count of ListComments", contentType))
+	}
+
+	count, err := s.commentRepo.CountByContent(ctx, contentID, contentType, includeReplies)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+	return count, nil
+}
+
+// BatchCountComments returns the number of comments on each of up to MaxCommentCountContents
+// contents of one type, with 0 for contents without comments
+func (s *CommentService) BatchCountComments(ctx context.Context, contentIDs []int64, contentType string, includeReplies bool) (map[int64]int64, error) {
+	s.logger.Info("Counting comments of contents",
+		"content_ids", len(contentIDs),
+		"type", contentType,
+		"include_replies", includeReplies,
+	)
+
+	if len(contentIDs) == 0 {
+		return nil, apperr.InvalidField("content_ids", "at least one content ID is required")
+	}
+	if len(contentIDs) > models.MaxCommentCountContents {
+		return nil, apperr.InvalidField("content_ids", fmt.Sprintf("at most %d content IDs are allowed", models.MaxCommentCountContents))
+	}
+	if contentType == "" {
+		return nil, apperr.InvalidField("type", "type is required")
+	}
+	if models.CommentTypeUsesRef(contentType) {
+		return nil, apperr.InvalidField("type", fmt.Sprintf("%q comments are counted by the total_count of ListComments", contentType))
+	}
+	for _, contentID := range contentIDs {
+		if contentID <= 0 {
+			return nil, apperr.InvalidField("content_ids", "content IDs must be positive")
+		}
+	}
+
+	counts, err := s.commentRepo.CountByContents(ctx, contentIDs, contentType, includeReplies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+	for _, contentID := range contentIDs {
+		if _, ok := counts[contentID]; !ok {
+			counts[contentID] = 0
+		}
+	}
+	return counts, nil
+}
+
+// depthBackfillBatchSize is the number of parent IDs matched per update during the depth backfill
+const depthBackfillBatchSize = 500
+
+// BackfillCommentDepth computes the depth of existing comments created before depth was stored
+func (s *CommentService) BackfillCommentDepth(ctx context.Context) (int64, error) {
+	s.logger.Info("Backfilling comment depth")
+
+	modified, err := s.commentRepo.BackfillDepth(ctx, depthBackfillBatchSize)
+	if err != nil {
+		s.logger.Error("Comment depth backfill failed", "modified", modified, "error", err)
+		return modified, fmt.Errorf("failed to backfill comment depth: %w", err)
+	}
+
+	s.logger.Info("Comment depth backfill finished", "modified", modified)
+	return modified, nil
+}
+
+// importBatchSize is the number of comments inserted per batch during imports
+const importBatchSize = 100
+
+// ImportComments imports historical comments keeping their original timestamps.
+// Replies that reference their parent by source ID are inserted after the parent
+// in successive passes, so records may arrive in any order. In dry-run mode the
+// records are validated and resolved but nothing is written.
+func (s *CommentService) ImportComments(ctx context.Context, records []*models.CommentImportRecord, opts models.CommentImportOptions) []*models.CommentImportResult {
+	s.logger.Info("Importing comments",
+		"records", len(records),
+		"dry_run", opts.DryRun,
+		"source_encoding", opts.SourceEncoding,
+		"lenient", opts.Lenient,
+	)
+	dryRun := opts.DryRun
+
+	results := make([]*models.CommentImportResult, len(records))
+	sourceIndex := make(map[string]int)
+	for i, record := range records {
+		results[i] = &models.CommentImportResult{Index: i, SourceID: record.SourceID}
+		if err := decodeImportContent(record, opts, results[i]); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := validateImportRecord(record); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if record.SourceID != "" {
+			if _, duplicate := sourceIndex[record.SourceID]; duplicate {
+				results[i].Err = fmt.Errorf("duplicate source_id %q", record.SourceID)
+				continue
+			}
+			sourceIndex[record.SourceID] = i
+		}
+	}
+
+	// Validate references to comments that already exist, once per parent
+	existingParents := make(map[string]*models.Comment)
+	var pending []int
+	for i, record := range records {
+		if results[i].Err != nil {
+			continue
+		}
+		if parentID := record.Comment.ParentID; parentID != nil && *parentID != "" {
+			parent, checked := existingParents[*parentID]
+			if !checked {
+				parent, _ = s.commentRepo.GetByID(ctx, *parentID)
+				existingParents[*parentID] = parent
+			}
+			if parent == nil {
+				results[i].Err = fmt.Errorf("parent comment not found")
+				continue
+			}
+			depth, err := replyDepth(parent, s.cfg.MaxDepth)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			record.Comment.Depth = depth
+		}
+		pending = append(pending, i)
+	}
+
+	// Insert in passes: each pass imports every record whose parent is resolved
+	resolved := make(map[int]bool)
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			for _, i := range pending {
+				results[i].Err = fmt.Errorf("import cancelled: %w", err)
+			}
+			break
+		}
+
+		var ready, deferred []int
+		for _, i := range pending {
+			record := records[i]
+			if record.ParentSourceID == nil || *record.ParentSourceID == "" {
+				ready = append(ready, i)
+				continue
+			}
+
+			parentIndex, ok := sourceIndex[*record.ParentSourceID]
+			switch {
+			case !ok:
+				results[i].Err = fmt.Errorf("parent source_id %q is not part of this import", *record.ParentSourceID)
+			case results[parentIndex].Err != nil:
+				results[i].Err = fmt.Errorf("parent source_id %q failed to import", *record.ParentSourceID)
+			case resolved[parentIndex]:
+				depth, err := replyDepth(&records[parentIndex].Comment, s.cfg.MaxDepth)
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				record.Comment.Depth = depth
+				if !dryRun {
+					parentID := results[parentIndex].CommentID
+					record.Comment.ParentID = &parentID
+				}
+				ready = append(ready, i)
+			default:
+				deferred = append(deferred, i)
+			}
+		}
+
+		if len(ready) == 0 {
+			for _, i := range deferred {
+				results[i].Err = fmt.Errorf("parent reference cycle detected")
+			}
+			break
+		}
+
+		s.importPass(ctx, records, results, ready, dryRun)
+		for _, i := range ready {
+			if results[i].Err == nil {
+				resolved[i] = true
+			}
+		}
+		pending = deferred
+	}
+
+	var failed int
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	s.logger.Info("Comments import finished",
+		"records", len(records),
+		"failed", failed,
+		"dry_run", dryRun,
+	)
+
+	return results
+}
+
+// importPass inserts the given records in batches and stores the outcome in results
+func (s *CommentService) importPass(ctx context.Context, records []*models.CommentImportRecord, results []*models.CommentImportResult, indexes []int, dryRun bool) {
+	now := time.Now().UTC()
+
+	for start := 0; start < len(indexes); start += importBatchSize {
+		end := min(start+importBatchSize, len(indexes))
+		batch := indexes[start:end]
+
+		if dryRun {
+			for _, i := range batch {
+				results[i].Err = records[i].Comment.ValidateImportTimestamps(now)
+			}
+			continue
+		}
+
+		comments := make([]*models.Comment, len(batch))
+		for j, i := range batch {
+			comments[j] = &records[i].Comment
+		}
+
+		errs := s.commentRepo.CreateWithTimestamps(ctx, comments)
+		for j, i := range batch {
+			if errs[j] != nil {
+				results[i].Err = errs[j]
+				continue
+			}
+			results[i].CommentID = comments[j].ID.Hex()
+		}
+	}
+}
+
+// decodeImportContent decodes the raw content of an import record into its comment, in the
+// record's encoding or the stream's, and reports the encoding and replacements in its result
+func decodeImportContent(record *models.CommentImportRecord, opts models.CommentImportOptions, result *models.CommentImportResult) error {
+	if record.RawContent == nil {
+		return nil
+	}
+	if record.Comment.Content != "" {
+		return fmt.Errorf("content and raw_content are mutually exclusive")
+	}
+
+	encoding := opts.SourceEncoding
+	if record.SourceEncoding != nil {
+		encoding = *record.SourceEncoding
+	}
+	decoded, err := textenc.Decode(encoding, record.RawContent, opts.Lenient)
+	if err != nil {
+		return fmt.Errorf("failed to decode raw_content: %w", err)
+	}
+	record.Comment.Content = decoded.Text
+	result.SourceEncoding = decoded.Encoding
+	result.Replaced = decoded.Replaced
+	return nil
+}
+
+// validateImportRecord checks the required fields of an imported comment
+func validateImportRecord(record *models.CommentImportRecord) error {
+	comment := record.Comment
+	if comment.ContentID <= 0 {
+		return fmt.Errorf("invalid content ID")
+	}
+	if comment.UserID <= 0 {
+		return fmt.Errorf("invalid user ID")
+	}
+	if comment.Content == "" {
+		return fmt.Errorf("content is required")
+	}
+	if !utf8.ValidString(comment.Content) {
+		return fmt.Errorf("content is not valid UTF-8")
+	}
+	if comment.Type != "lab" && comment.Type != "article" {
+		return fmt.Errorf("type must be 'lab' or 'article'")
+	}
+	if comment.ParentID != nil && *comment.ParentID != "" && record.ParentSourceID != nil && *record.ParentSourceID != "" {
+		return fmt.Errorf("parent_id and parent_source_id are mutually exclusive")
+	}
+	return nil
 }
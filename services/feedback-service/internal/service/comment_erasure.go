@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// commentErasureBatchSize is the number of comments erased per update of an erasure
+const commentErasureBatchSize = 500
+
+// EraseUserComments erases every comment of a user whose account is deleted, in batches:
+// anonymized comments get placeholder content and soft deleted ones empty content, and both
+// lose the user's ID and their edit history. Replies, by the user or others, stay in their
+// threads under an erased parent. The erasure is audited in a record that retries update, and
+// a retry after an interruption erases the comments that are left.
+func (s *CommentService) EraseUserComments(ctx context.Context, userID int64, mode string, actorID int64) (*models.CommentErasureResult, error) {
+	s.logger.Info("Erasing user comments", "user_id", userID, "mode", mode, "actor_id", actorID)
+
+	if userID <= 0 {
+		return nil, apperr.InvalidField("user_id", "invalid user ID")
+	}
+	if mode != models.CommentErasureAnonymize && mode != models.CommentErasureDelete {
+		return nil, apperr.InvalidField("mode", "mode must be 'anonymize' or 'delete'")
+	}
+	if actorID <= 0 {
+		return nil, apperr.InvalidField("actor_id", "invalid actor ID")
+	}
+
+	erasure, err := s.commentRepo.StartErasure(ctx, userID, mode, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CommentErasureResult{Erasure: erasure}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("comment erasure interrupted after %d comments: %w", result.Erased, err)
+		}
+		erased, err := s.commentRepo.EraseUserBatch(ctx, userID, mode, commentErasureBatchSize)
+		if err != nil {
+			s.logger.Error("Comment erasure batch failed", "erasure_id", erasure.ID, "erased", result.Erased, "error", err)
+			return nil, err
+		}
+		if erased == 0 {
+			break
+		}
+		result.Erased += erased
+		if err := s.commentRepo.AddErasureProgress(ctx, erasure.ID, erased); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Erasure, err = s.commentRepo.CompleteErasure(ctx, erasure.ID); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User comments erased",
+		"erasure_id", erasure.ID,
+		"mode", mode,
+		"erased", result.Erased,
+		"total_erased", result.Erasure.Erased,
+		"attempts", result.Erasure.Attempts,
+	)
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+const (
+	// commentWatchOverlap is how far before the last read changes are read again, so a write
+	// stamped before a read but committed after it is not missed; repeats are filtered out
+	commentWatchOverlap = 5 * time.Second
+
+	// commentWatchRetryDelay is the wait before the comment feed retries a failed operation
+	commentWatchRetryDelay = 5 * time.Second
+)
+
+// commentWatchHub fans comment events out to the streams of the content they are on, keyed by
+// commentWatchKey
+type commentWatchHub = eventHub[string, models.CommentStreamEvent]
+
+// commentWatchKey returns the key of the streams of a content
+func commentWatchKey(contentType, contentKey string) string {
+	return contentType + "/" + contentKey
+}
+
+// commentChangeKey identifies a change read by polling, to skip it when it is read again
+type commentChangeKey struct {
+	kind string
+	id   string
+	at   int64
+}
+
+// changeKey returns the key of the change an event reports
+func changeKey(event models.CommentStreamEvent) commentChangeKey {
+	return commentChangeKey{kind: event.Kind, id: event.Comment.ID.Hex(), at: event.OccurredAt.UnixNano()}
+}
+
+// CloseCommentStreams ends the streams of WatchComments with ErrEventStreamsClosed, so
+// shutdown does not wait for clients to cancel them
+func (s *CommentService) CloseCommentStreams() {
+	s.watchers.close()
+}
+
+// publishCommentEvent forwards a comment event to the streams of its content
+func (s *CommentService) publishCommentEvent(event models.CommentStreamEvent) {
+	s.watchers.publish(commentWatchKey(event.Comment.Type, event.Comment.ContentKey()), event)
+}
+
+// RunCommentWatch feeds the streams of WatchComments until ctx is done. Each replica runs it
+// for its own streams. Changes come from a MongoDB change stream when the deployment has them,
+// a replica set or sharded cluster, and are polled for every COMMENT_WATCH_POLL_INTERVAL
+// otherwise. Writers never wait for it: it reads committed changes from MongoDB.
+func (s *CommentService) RunCommentWatch(ctx context.Context) {
+	var supported bool
+	for {
+		var err error
+		supported, err = s.commentRepo.ChangeStreamsSupported(ctx)
+		if err == nil {
+			break
+		}
+		s.logger.Error("Failed to check for comment change streams", "error", err)
+		if sleepContext(ctx, commentWatchRetryDelay) != nil {
+			return
+		}
+	}
+
+	if !supported {
+		s.logger.Info("MongoDB has no change streams; polling for comment changes", "interval", s.cfg.WatchPollInterval)
+		s.pollCommentChanges(ctx)
+		return
+	}
+	s.watchCommentChanges(ctx)
+}
+
+// watchCommentChanges feeds the streams from a change stream, reopening it when it fails and
+// then reading back the changes made while it was down
+func (s *CommentService) watchCommentChanges(ctx context.Context) {
+	s.logger.Info("Watching comment changes with a change stream")
+	var missedSince *time.Time
+	for {
+		err := s.commentRepo.WatchChanges(ctx, func() {
+			if missedSince == nil {
+				return
+			}
+			if err := s.publishCommentChanges(ctx, *missedSince, nil); err != nil {
+				s.logger.Error("Failed to read back missed comment changes; dropping comment streams", "error", err)
+				s.watchers.dropAll()
+			}
+			missedSince = nil
+		}, s.publishCommentEvent)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Error("Comment change stream failed; reopening", "error", err)
+		if missedSince == nil {
+			failedAt := time.Now().Add(-commentWatchOverlap)
+			missedSince = &failedAt
+		}
+		if sleepContext(ctx, commentWatchRetryDelay) != nil {
+			return
+		}
+	}
+}
+
+// pollCommentChanges feeds the streams by reading the changes made since the last poll, while
+// any stream is open. A failed poll is retried from the same time at the next tick.
+func (s *CommentService) pollCommentChanges(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.WatchPollInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	seen := make(map[commentChangeKey]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		started := time.Now()
+		if s.watchers.releaseIfIdle() {
+			last = started
+			clear(seen)
+			continue
+		}
+		since := last.Add(-commentWatchOverlap)
+		if err := s.publishCommentChanges(ctx, since, seen); err != nil {
+			s.logger.Error("Failed to poll for comment changes", "error", err)
+			continue
+		}
+		last = started
+
+		for key, at := range seen {
+			if at.Before(last.Add(-commentWatchOverlap)) {
+				delete(seen, key)
+			}
+		}
+	}
+}
+
+// publishCommentChanges forwards the changes made since a time to the streams of their
+// content. With seen, changes in it are skipped and the others added to it.
+func (s *CommentService) publishCommentChanges(ctx context.Context, since time.Time, seen map[commentChangeKey]time.Time) error {
+	filter := models.CommentChangeFilter{Since: since, Limit: replayBatchSize}
+	for {
+		events, next, err := s.commentRepo.ListChanges(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if seen != nil {
+				key := changeKey(event)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = event.OccurredAt
+			}
+			s.publishCommentEvent(event)
+		}
+		if next == nil {
+			return nil
+		}
+		filter.After = next
+	}
+}
+
+// WatchComments sends the events of the comments on a content until ctx is done, which ends
+// the watch without an error. With opts.Since, changes made from then on are read back from the
+// database and sent first, oldest first, one event per comment with its current state; a change
+// read back is not sent again when it is then observed live. opts.Since may be at most
+// models.CommentDeletionRetention ago. With opts.ResumeAfter, the events that followed that
+// position are sent first, each once and in order, and the live events go on from there
+// without a gap or a repeat. A stream that falls more than the configured buffer behind, or
+// that may have missed events while the change stream was down, ends with ErrSubscriberLagged.
+func (s *CommentService) WatchComments(ctx context.Context, contentType string, contentID int64, contentRef string, opts WatchOptions, send func(event models.CommentStreamEvent) error) error {
+	s.logger.Info("Watching comments", "type", contentType, "content_id", contentID, "content_ref", contentRef, "since", opts.Since, "resume_after", opts.ResumeAfter)
+
+	if err := validateCommentType(contentType); err != nil {
+		return err
+	}
+	contentRef, err := ValidateCommentTarget(contentType, contentID, contentRef)
+	if err != nil {
+		return err
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if opts.Since != nil && time.Since(*opts.Since) > models.CommentDeletionRetention {
+		return apperr.InvalidField("since", fmt.Sprintf("since must be within the last %d days; list the comments again instead", int(models.CommentDeletionRetention.Hours()/24)))
+	}
+
+	contentKey := contentRef
+	if contentKey == "" {
+		contentKey = strconv.FormatInt(contentID, 10)
+	}
+	key := commentWatchKey(contentType, contentKey)
+
+	// Watch before replaying, so nothing that happens during the replay is missed
+	resumeSince := time.Now()
+	w, backlog, start, err := s.watchers.watch(key, opts.ResumeAfter)
+	if err != nil {
+		s.logger.Info("Comment stream cannot resume", "key", key, "error", err)
+		return watchError(err)
+	}
+	defer s.watchers.unwatch(key, w)
+
+	var replayed map[commentChangeKey]struct{}
+	if opts.Since != nil {
+		replayed = make(map[commentChangeKey]struct{})
+		if err := s.replayComments(ctx, contentType, contentID, contentRef, *opts.Since, start, replayed, send); err != nil {
+			return err
+		}
+	}
+	for _, sequenced := range backlog {
+		event := sequenced.event
+		event.Replayed = true
+		event.Position = sequenced.position
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case sequenced := <-w.events:
+			event := sequenced.event
+			if _, ok := replayed[changeKey(event)]; ok {
+				delete(replayed, changeKey(event)) // Read back already; each change is observed once
+				continue
+			}
+			event.Position = sequenced.position
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-w.lagged:
+			s.logger.Warn("Comment stream fell behind", "key", key)
+			return ErrSubscriberLagged.WithMetadata("resume_since", resumeSince.UTC().Format(time.RFC3339Nano))
+		case <-s.watchers.closed:
+			return ErrEventStreamsClosed.WithMetadata("resume_since", resumeSince.UTC().Format(time.RFC3339Nano))
+		case <-ctx.Done():
+			s.logger.Info("Comment watch ended", "key", key)
+			return nil
+		}
+	}
+}
+
+// replayComments sends the changes to the comments on a content made since a time, in order
+// of the change, and adds them to replayed. They carry the position the stream started at, as
+// the changes the poller or change stream had not observed yet follow it.
+func (s *CommentService) replayComments(ctx context.Context, contentType string, contentID int64, contentRef string, since time.Time, start models.StreamPosition, replayed map[commentChangeKey]struct{}, send func(event models.CommentStreamEvent) error) error {
+	filter := models.CommentChangeFilter{
+		Type:       contentType,
+		ContentID:  contentID,
+		ContentRef: contentRef,
+		Since:      since,
+		Limit:      replayBatchSize,
+	}
+	for {
+		events, next, err := s.commentRepo.ListChanges(ctx, filter)
+		if err != nil {
+			s.logger.Error("Failed to replay comments", "type", contentType, "content_id", contentID, "content_ref", contentRef, "error", err)
+			return fmt.Errorf("failed to replay comment events: %w", err)
+		}
+		for _, event := range events {
+			replayed[changeKey(event)] = struct{}{}
+			event.Replayed = true
+			event.Position = start
+			if event.Kind == models.CommentEventUpdated && !event.Comment.CreatedAt.Before(since) {
+				event.Kind = models.CommentEventCreated // New to the client, however often it changed since
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		filter.After = next
+	}
+}
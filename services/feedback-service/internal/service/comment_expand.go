@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// CommentExpandFeedback is the ListComments expansion that embeds the discussed feedback of
+// "feedback" comments
+const CommentExpandFeedback = "feedback"
+
+// errFeedbackLookupUnavailable is returned by ExpandFeedbacks when the service was created
+// without a feedback lookup
+var errFeedbackLookupUnavailable = errors.New("feedback lookup is not configured")
+
+// ExpandFeedbacks reads the feedbacks discussed by the "feedback" comments of a page with one
+// batch read, keyed by feedback ID. Feedback that does not exist or was deleted is left out of
+// the map. The read runs under the request's context, so it never outlasts its deadline.
+func (s *CommentService) ExpandFeedbacks(ctx context.Context, comments []*models.Comment) (map[uuid.UUID]*models.Feedback, error) {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, comment := range comments {
+		if comment.Type != models.CommentTypeFeedback {
+			continue
+		}
+		id, err := uuid.Parse(comment.ContentRef)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.feedbacks == nil {
+		return nil, errFeedbackLookupUnavailable
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	found, _, err := s.feedbacks.BatchGetFeedbacks(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the discussed feedbacks: %w", err)
+	}
+	feedbacks := make(map[uuid.UUID]*models.Feedback, len(found))
+	for _, feedback := range found {
+		feedbacks[feedback.ID] = feedback
+	}
+	return feedbacks, nil
+}
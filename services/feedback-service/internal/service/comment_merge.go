@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// commentMergeBatchSize is the number of comments re-pointed per update of a thread merge
+const commentMergeBatchSize = 500
+
+// ErrCommentMergeIncomplete is returned when comments were created on the source content while a
+// merge ran. The number left is in the remaining metadata; a retry moves them.
+var ErrCommentMergeIncomplete = apperr.Unavailable("COMMENT_MERGE_INCOMPLETE", "comments are still on the source content; retry the merge")
+
+// MergeCommentThreads moves every comment of a duplicate content onto the content it
+// duplicates, in batches. Replies keep their parents, so the target ends up with the union of
+// both threads. The merge is audited in a record that retries update, and a retry after a
+// partial run moves the comments that are left.
+func (s *CommentService) MergeCommentThreads(ctx context.Context, sourceContentID, targetContentID int64, contentType string, actorID int64) (*models.CommentMergeResult, error) {
+	s.logger.Info("Merging comment threads",
+		"source_content_id", sourceContentID,
+		"target_content_id", targetContentID,
+		"type", contentType,
+		"actor_id", actorID,
+	)
+
+	if sourceContentID <= 0 {
+		return nil, apperr.InvalidField("source_content_id", "invalid source content ID")
+	}
+	if targetContentID <= 0 {
+		return nil, apperr.InvalidField("target_content_id", "invalid target content ID")
+	}
+	if sourceContentID == targetContentID {
+		return nil, apperr.InvalidField("target_content_id", "source and target content must differ")
+	}
+	if contentType != "lab" && contentType != "article" {
+		return nil, apperr.InvalidField("type", "type must be 'lab' or 'article'")
+	}
+	if actorID <= 0 {
+		return nil, apperr.InvalidField("actor_id", "invalid actor ID")
+	}
+
+	merge, err := s.commentRepo.StartMerge(ctx, sourceContentID, targetContentID, contentType, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CommentMergeResult{Merge: merge}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("comment merge interrupted after %d comments: %w", result.Moved, err)
+		}
+		moved, err := s.commentRepo.MoveContentBatch(ctx, sourceContentID, targetContentID, contentType, commentMergeBatchSize)
+		if err != nil {
+			s.logger.Error("Comment merge batch failed", "merge_id", merge.ID, "moved", result.Moved, "error", err)
+			return nil, err
+		}
+		if moved == 0 {
+			break
+		}
+		result.Moved += moved
+		if err := s.commentRepo.AddMergeProgress(ctx, merge.ID, moved); err != nil {
+			return nil, err
+		}
+	}
+
+	// Comments created on the source while the last batch ran are moved by a retry
+	remaining, err := s.commentRepo.CountByContent(ctx, sourceContentID, contentType, true)
+	if err != nil {
+		return nil, err
+	}
+	if remaining > 0 {
+		return nil, ErrCommentMergeIncomplete.WithMetadata("remaining", strconv.FormatInt(remaining, 10))
+	}
+
+	if result.Merge, err = s.commentRepo.CompleteMerge(ctx, merge.ID); err != nil {
+		return nil, err
+	}
+	if result.TargetComments, err = s.commentRepo.CountByContent(ctx, targetContentID, contentType, true); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Comment threads merged",
+		"merge_id", merge.ID,
+		"moved", result.Moved,
+		"total_moved", result.Merge.Moved,
+		"attempts", result.Merge.Attempts,
+		"target_comments", result.TargetComments,
+	)
+	return result, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportComment records a user's report of a comment for moderators and reports whether it is
+// the user's first report of it. Reporting again replaces the reason and note.
+func (s *CommentService) ReportComment(ctx context.Context, commentID string, reporterID int64, reason, note string) (bool, error) {
+	s.logger.Info("Reporting comment", "comment_id", commentID, "reporter_id", reporterID, "reason", reason)
+
+	if reporterID <= 0 {
+		return false, apperr.InvalidField("reporter_user_id", "invalid reporter ID")
+	}
+	if !models.IsValidCommentReportReason(reason) {
+		return false, apperr.InvalidField("reason", "reason must be SPAM, HARASSMENT, OFF_TOPIC or OTHER")
+	}
+	if utf8.RuneCountInString(note) > models.MaxCommentReportNoteLength {
+		return false, apperr.InvalidField("note", fmt.Sprintf("note must be at most %d characters", models.MaxCommentReportNoteLength))
+	}
+	objectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return false, apperr.InvalidField("id", "invalid comment ID")
+	}
+
+	// The comment is read in the transaction, so a report is never left behind by a
+	// concurrent delete
+	var created bool
+	err = s.commentRepo.WithTransaction(ctx, func(txRepo repository.CommentTxRepository) error {
+		if _, err := txRepo.GetByID(ctx, commentID); err != nil {
+			return err
+		}
+		var err error
+		created, err = txRepo.SaveReport(ctx, &models.CommentReport{
+			CommentID:  objectID,
+			ReporterID: reporterID,
+			Reason:     reason,
+			Note:       note,
+		})
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to report comment", "comment_id", commentID, "error", err)
+		return false, fmt.Errorf("failed to report comment: %w", err)
+	}
+
+	s.logger.Info("Comment reported", "comment_id", commentID, "created", created)
+	return created, nil
+}
+
+// ListReportedComments lists the comments with open reports for moderators, most reported
+// first; page and limit are normalized
+func (s *CommentService) ListReportedComments(ctx context.Context, page, limit int32) ([]*models.ReportedComment, int64, error) {
+	s.logger.Info("Listing reported comments", "page", page, "limit", limit)
+
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	reported, totalCount, err := s.commentRepo.ListReported(ctx, page, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list reported comments: %w", err)
+	}
+
+	s.logger.Info("Reported comments listed", "count", len(reported), "total_count", totalCount)
+	return reported, totalCount, nil
+}
+
+// ModerateComment applies a moderator's decision to a comment: hiding it closes its open
+// reports, dismissing closes them and leaves the comment shown, and restoring shows a hidden
+// comment again. The comment and its reports change in one transaction.
+func (s *CommentService) ModerateComment(ctx context.Context, commentID string, moderatorID int64, action string) (*models.ModerationResult, error) {
+	s.logger.Info("Moderating comment", "comment_id", commentID, "moderator_id", moderatorID, "action", action)
+
+	if moderatorID <= 0 {
+		return nil, apperr.InvalidField("moderator_id", "invalid moderator ID")
+	}
+	switch action {
+	case models.CommentModerationHide, models.CommentModerationDismiss, models.CommentModerationRestore:
+	default:
+		return nil, apperr.InvalidField("action", "action must be HIDE, DISMISS or RESTORE")
+	}
+
+	result := &models.ModerationResult{}
+	err := s.commentRepo.WithTransaction(ctx, func(txRepo repository.CommentTxRepository) error {
+		comment, err := txRepo.GetByID(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		result.Comment = comment
+
+		switch action {
+		case models.CommentModerationHide:
+			if !comment.IsHidden() {
+				if result.Comment, err = txRepo.SetHidden(ctx, commentID, true, moderatorID); err != nil {
+					return err
+				}
+			}
+			result.ResolvedReports, err = txRepo.CloseReports(ctx, commentID, action, moderatorID)
+			return err
+		case models.CommentModerationDismiss:
+			result.ResolvedReports, err = txRepo.CloseReports(ctx, commentID, action, moderatorID)
+			return err
+		default: // models.CommentModerationRestore
+			if comment.IsHidden() {
+				result.Comment, err = txRepo.SetHidden(ctx, commentID, false, moderatorID)
+			}
+			return err
+		}
+	})
+	if err != nil {
+		s.logger.Error("Failed to moderate comment", "comment_id", commentID, "action", action, "error", err)
+		return nil, fmt.Errorf("failed to moderate comment: %w", err)
+	}
+
+	s.logger.Info("Comment moderated",
+		"comment_id", commentID,
+		"action", action,
+		"hidden", result.Comment.IsHidden(),
+		"resolved_reports", result.ResolvedReports,
+	)
+	return result, nil
+}
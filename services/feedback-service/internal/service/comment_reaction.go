@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+)
+
+// GetCommentWithReactions retrieves a comment by ID with its reaction counts and, for a
+// viewerUserID other than 0, the viewer's own reaction
+func (s *CommentService) GetCommentWithReactions(ctx context.Context, id string, viewerUserID int64) (*models.Comment, error) {
+	comment, err := s.GetComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.commentRepo.LoadReactions(ctx, []*models.Comment{comment}, viewerUserID); err != nil {
+		return nil, fmt.Errorf("failed to load comment reactions: %w", err)
+	}
+	return comment, nil
+}
+
+// ReactToComment sets the user's reaction to a comment, replacing an earlier reaction, and
+// returns the comment with its updated counts and the user's reaction
+func (s *CommentService) ReactToComment(ctx context.Context, commentID string, userID int64, reaction string) (*models.Comment, error) {
+	s.logger.Info("Reacting to comment", "comment_id", commentID, "user_id", userID, "reaction", reaction)
+
+	if !models.IsValidCommentReaction(reaction) {
+		return nil, apperr.InvalidField("reaction", "reaction must be LIKE or DISLIKE")
+	}
+	if userID <= 0 {
+		return nil, apperr.InvalidField("user_id", "invalid user ID")
+	}
+
+	// The comment is read in the transaction, so a reaction is never left behind by a
+	// concurrent delete
+	var comment *models.Comment
+	err := s.commentRepo.WithTransaction(ctx, func(txRepo repository.CommentTxRepository) error {
+		var err error
+		comment, err = txRepo.GetByID(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		_, err = txRepo.SetReaction(ctx, commentID, userID, reaction)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to set comment reaction", "comment_id", commentID, "error", err)
+		return nil, fmt.Errorf("failed to set comment reaction: %w", err)
+	}
+	if err := s.commentRepo.LoadReactions(ctx, []*models.Comment{comment}, userID); err != nil {
+		return nil, fmt.Errorf("failed to load comment reactions: %w", err)
+	}
+
+	s.logger.Info("Comment reaction set", "comment_id", commentID, "reaction", reaction)
+	return comment, nil
+}
+
+// RemoveCommentReaction removes the user's reaction to a comment, if any, and returns the
+// comment with its updated counts
+func (s *CommentService) RemoveCommentReaction(ctx context.Context, commentID string, userID int64) (*models.Comment, error) {
+	s.logger.Info("Removing comment reaction", "comment_id", commentID, "user_id", userID)
+
+	if userID <= 0 {
+		return nil, apperr.InvalidField("user_id", "invalid user ID")
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if _, err := s.commentRepo.RemoveReaction(ctx, commentID, userID); err != nil {
+		s.logger.Error("Failed to remove comment reaction", "comment_id", commentID, "error", err)
+		return nil, fmt.Errorf("failed to remove comment reaction: %w", err)
+	}
+	if err := s.commentRepo.LoadReactions(ctx, []*models.Comment{comment}, userID); err != nil {
+		return nil, fmt.Errorf("failed to load comment reactions: %w", err)
+	}
+
+	s.logger.Info("Comment reaction removed", "comment_id", commentID)
+	return comment, nil
+}
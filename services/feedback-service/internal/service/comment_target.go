@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/google/uuid"
+)
+
+// ErrCommentTargetNotFound is returned when a new comment is attached to content that does
+// not exist, such as a deleted feedback
+var ErrCommentTargetNotFound = apperr.FailedPrecondition("COMMENT_TARGET_NOT_FOUND", "the content to comment on does not exist")
+
+// FeedbackLookup finds feedbacks by ID; FeedbackService implements it
+type FeedbackLookup interface {
+	GetFeedbackByID(ctx context.Context, id uuid.UUID) (*models.Feedback, error)
+	BatchGetFeedbacks(ctx context.Context, ids []uuid.UUID) ([]*models.Feedback, []uuid.UUID, error)
+}
+
+// ValidateCommentTarget checks how the content of a comment is referenced: by content_id for
+// labs and articles, and by content_ref for the types referenced by a string ID, with the other
+// field unset. It returns the content reference in canonical form, "" for numeric types.
+func ValidateCommentTarget(contentType string, contentID int64, contentRef string) (string, error) {
+	if !models.CommentTypeUsesRef(contentType) {
+		if contentID <= 0 {
+			return "", apperr.InvalidField("content_id", "invalid content ID")
+		}
+		if contentRef != "" {
+			return "", apperr.InvalidField("content_ref", fmt.Sprintf("%q comments are referenced by content_id; leave content_ref unset", contentType))
+		}
+		return "", nil
+	}
+
+	if contentID != 0 {
+		return "", apperr.InvalidField("content_id", fmt.Sprintf("%q comments are referenced by content_ref; leave content_id unset", contentType))
+	}
+	if contentRef == "" {
+		return "", apperr.InvalidField("content_ref", fmt.Sprintf("content_ref is required for %q comments", contentType))
+	}
+	switch contentType {
+	case models.CommentTypeFeedback:
+		id, err := resourcename.ParseFeedbackID(contentRef)
+		if err != nil {
+			return "", apperr.InvalidField("content_ref", "content_ref must be a feedback ID")
+		}
+		return id.String(), nil
+	}
+	return contentRef, nil
+}
+
+// validateCommentType rejects content types comments cannot be attached to
+func validateCommentType(contentType string) error {
+	if contentType == "" {
+		return apperr.InvalidField("type", "type is required")
+	}
+	if !models.IsValidCommentType(contentType) {
+		return apperr.InvalidField("type", fmt.Sprintf("type must be one of: %s", strings.Join(models.CommentTypes(), ", ")))
+	}
+	return nil
+}
+
+// checkTarget confirms that the content a new comment is attached to exists, for the types
+// whose content this service owns. Labs and articles belong to other services and are not
+// checked. A nil feedback lookup skips the feedback check.
+func (s *CommentService) checkTarget(ctx context.Context, contentType, contentRef string) error {
+	switch contentType {
+	case models.CommentTypeFeedback:
+		if s.feedbacks == nil {
+			return nil
+		}
+		_, err := s.feedbacks.GetFeedbackByID(ctx, uuid.MustParse(contentRef))
+		if errors.Is(err, repository.ErrFeedbackNotFound) {
+			return ErrCommentTargetNotFound.WithMetadata("content_ref", contentRef)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check the feedback: %w", err)
+		}
+	}
+	return nil
+}
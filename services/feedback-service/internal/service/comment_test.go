@@ -0,0 +1,46 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+func TestReplyDepth(t *testing.T) {
+	tests := []struct {
+		name        string
+		parentDepth int32
+		maxDepth    int32
+		want        int32
+		wantErr     bool
+	}{
+		{name: "reply to a top-level comment", parentDepth: 0, maxDepth: 10, want: 1},
+		{name: "at the limit", parentDepth: 9, maxDepth: 10, want: 10},
+		{name: "one past the limit", parentDepth: 10, maxDepth: 10, wantErr: true},
+		{name: "parent already past a lowered limit", parentDepth: 5, maxDepth: 3, wantErr: true},
+		{name: "limit of one", parentDepth: 0, maxDepth: 1, want: 1},
+		{name: "replies disabled", parentDepth: 0, maxDepth: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replyDepth(&models.Comment{Depth: tt.parentDepth}, tt.maxDepth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("replyDepth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var depthErr *CommentDepthError
+				if !errors.As(err, &depthErr) || depthErr.MaxDepth != tt.maxDepth {
+					t.Errorf("replyDepth() error = %v, want a CommentDepthError with limit %d", err, tt.maxDepth)
+				}
+				if !errors.Is(err, ErrCommentDepthExceeded) {
+					t.Errorf("replyDepth() error = %v, want %v", err, ErrCommentDepthExceeded)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("replyDepth() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+const (
+	// maxCompletenessSubmissions caps the submissions checked by one completeness request
+	maxCompletenessSubmissions = 500
+
+	// maxExpectedReviewers caps the expected reviewers of one completeness request
+	maxExpectedReviewers = 100
+)
+
+// ErrInvalidCompletenessRequest is returned when a completeness request has invalid or too many IDs
+var ErrInvalidCompletenessRequest = errors.New("invalid completeness request")
+
+// uniquePositiveIDs removes duplicates from ids, keeping the first occurrence, and rejects
+// IDs that are not positive
+func uniquePositiveIDs(ids []int64, name string) ([]int64, error) {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("%w: invalid %s %d", ErrInvalidCompletenessRequest, name, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// summarizeCompleteness builds the completeness of one submission from its per-reviewer counts.
+// Only published feedback completes a submission; an expected reviewer with drafts only is not
+// missing but leaves the submission partial.
+func summarizeCompleteness(submissionID int64, counts []models.ReviewerFeedbackCount, expectedReviewerIDs []int64) *models.SubmissionCompleteness {
+	result := &models.SubmissionCompleteness{SubmissionID: submissionID}
+	published := make(map[int64]bool, len(counts))
+	for _, count := range counts {
+		result.FeedbackCount += count.Count
+		result.PublishedCount += count.PublishedCount
+		result.DraftCount += count.DraftCount
+		result.ReviewerIDs = append(result.ReviewerIDs, count.ReviewerID)
+		published[count.ReviewerID] = count.PublishedCount > 0
+	}
+	unpublished := false
+	for _, reviewerID := range expectedReviewerIDs {
+		isPublished, wrote := published[reviewerID]
+		if !wrote {
+			result.MissingReviewerIDs = append(result.MissingReviewerIDs, reviewerID)
+		} else if !isPublished {
+			unpublished = true
+		}
+	}
+	sort.Slice(result.MissingReviewerIDs, func(i, j int) bool {
+		return result.MissingReviewerIDs[i] < result.MissingReviewerIDs[j]
+	})
+
+	switch {
+	case result.PublishedCount == 0:
+		result.Status = models.CompletenessMissing
+	case len(result.MissingReviewerIDs) > 0 || unpublished:
+		result.Status = models.CompletenessPartial
+	default:
+		result.Status = models.CompletenessComplete
+	}
+	return result
+}
+
+// GetFeedbackCompleteness reports, for each submission, how much feedback was published or
+// drafted and by whom. When expectedReviewerIDs is not empty, reviewers from it who have not written feedback
+// for a submission are reported as missing. Results follow the order of submissionIDs, without
+// duplicates. All submissions are counted with a single grouped query.
+func (s *FeedbackService) GetFeedbackCompleteness(ctx context.Context, submissionIDs, expectedReviewerIDs []int64) ([]*models.SubmissionCompleteness, error) {
+	s.logger.Info("Getting feedback completeness",
+		"submissions", len(submissionIDs),
+		"expected_reviewers", len(expectedReviewerIDs),
+	)
+
+	if len(submissionIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one submission ID is required", ErrInvalidCompletenessRequest)
+	}
+	submissionIDs, err := uniquePositiveIDs(submissionIDs, "submission ID")
+	if err != nil {
+		return nil, err
+	}
+	if len(submissionIDs) > maxCompletenessSubmissions {
+		return nil, fmt.Errorf("%w: at most %d submissions per request", ErrInvalidCompletenessRequest, maxCompletenessSubmissions)
+	}
+	expectedReviewerIDs, err = uniquePositiveIDs(expectedReviewerIDs, "reviewer ID")
+	if err != nil {
+		return nil, err
+	}
+	if len(expectedReviewerIDs) > maxExpectedReviewers {
+		return nil, fmt.Errorf("%w: at most %d expected reviewers per request", ErrInvalidCompletenessRequest, maxExpectedReviewers)
+	}
+
+	counts, err := s.feedbackRepo.CountBySubmissionReviewer(ctx, submissionIDs)
+	if err != nil {
+		s.logger.Error("Failed to count feedbacks by submission", "error", err)
+		return nil, fmt.Errorf("failed to get feedback completeness: %w", err)
+	}
+
+	// Counts are ordered by submission, then reviewer
+	bySubmission := make(map[int64][]models.ReviewerFeedbackCount)
+	for _, count := range counts {
+		bySubmission[count.SubmissionID] = append(bySubmission[count.SubmissionID], count)
+	}
+
+	results := make([]*models.SubmissionCompleteness, len(submissionIDs))
+	for i, submissionID := range submissionIDs {
+		results[i] = summarizeCompleteness(submissionID, bySubmission[submissionID], expectedReviewerIDs)
+	}
+	return results, nil
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// consistencyBatchSize is the number of feedback rows read per batch during a consistency check
+const consistencyBatchSize = 200
+
+// defaultSamplePercent is the share of feedbacks checked when no sample size is given
+const defaultSamplePercent = 10
+
+// CheckConsistency cross-references feedback rows in PostgreSQL with attachment prefixes in
+// MinIO. Both sides are read in ID order and merged, so memory use is bounded by one batch of
+// feedbacks and one prefix. Findings are passed to emit as they are found; repairs requested
+// in opts are applied inline. Sampling selects feedback IDs by their first byte, so the same
+// IDs are picked on both sides.
+func (s *FeedbackService) CheckConsistency(ctx context.Context, opts models.ConsistencyOptions, emit func(*models.ConsistencyFinding) error) (*models.ConsistencySummary, error) {
+	if !opts.FullScan && opts.SamplePercent == 0 {
+		opts.SamplePercent = defaultSamplePercent
+	}
+	if !opts.FullScan && (opts.SamplePercent < 1 || opts.SamplePercent > 100) {
+		return nil, fmt.Errorf("sample percent must be between 1 and 100")
+	}
+
+	s.logger.Info("Starting consistency check",
+		"full_scan", opts.FullScan,
+		"sample_percent", opts.SamplePercent,
+		"delete_orphans", opts.DeleteOrphans,
+		"mark_for_reupload", opts.MarkForReupload,
+	)
+
+	// Stop the MinIO listing when the check returns early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sampled := func(id uuid.UUID) bool {
+		return opts.FullScan || int(id[0])*100/256 < opts.SamplePercent
+	}
+
+	summary := &models.ConsistencySummary{}
+	prefixes := s.attachmentRepo.StreamPrefixes(ctx)
+
+	// nextPrefix returns the next prefix to check, skipping unsampled feedback prefixes
+	nextPrefix := func() (*models.AttachmentPrefix, error) {
+		for prefix := range prefixes {
+			if prefix.Err != nil {
+				return nil, prefix.Err
+			}
+			if prefix.FeedbackID != uuid.Nil && !sampled(prefix.FeedbackID) {
+				continue
+			}
+			summary.PrefixesChecked++
+			for _, object := range prefix.Objects {
+				summary.Objects++
+				summary.Bytes += object.Size
+			}
+			return &prefix, nil
+		}
+		return nil, ctx.Err()
+	}
+
+	prefix, err := nextPrefix()
+	if err != nil {
+		return summary, err
+	}
+
+	after := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, fmt.Errorf("consistency check interrupted: %w", err)
+		}
+
+		refs, err := s.feedbackRepo.ListRefsAfter(ctx, after, consistencyBatchSize)
+		if err != nil {
+			return summary, fmt.Errorf("failed to list feedbacks: %w", err)
+		}
+		if len(refs) == 0 {
+			break
+		}
+		after = refs[len(refs)-1].ID
+
+		var batch []models.FeedbackRef
+		for _, ref := range refs {
+			if sampled(ref.ID) {
+				batch = append(batch, ref)
+			}
+		}
+		expected, err := s.expectedAttachments(ctx, batch)
+		if err != nil {
+			return summary, err
+		}
+
+		for _, ref := range batch {
+			if err := ctx.Err(); err != nil {
+				return summary, fmt.Errorf("consistency check interrupted: %w", err)
+			}
+			summary.FeedbacksChecked++
+			id := ref.ID.String()
+
+			// Prefixes sorting before this feedback have no feedback row
+			for prefix != nil && prefix.Prefix < id {
+				if err := s.reportOrphan(ctx, prefix, opts, summary, emit); err != nil {
+					return summary, err
+				}
+				if prefix, err = nextPrefix(); err != nil {
+					return summary, err
+				}
+			}
+
+			stored := make(map[string]bool)
+			if prefix != nil && prefix.Prefix == id {
+				for _, object := range prefix.Objects {
+					stored[object.Filename] = true
+				}
+				if prefix, err = nextPrefix(); err != nil {
+					return summary, err
+				}
+			}
+
+			if ref.Deleted {
+				continue
+			}
+
+			var missing []string
+			for _, filename := range expected[ref.ID] {
+				if !stored[filename] {
+					missing = append(missing, filename)
+				}
+			}
+			if len(missing) > 0 {
+				if err := s.reportMissing(ctx, ref.ID, missing, opts, summary, emit); err != nil {
+					return summary, err
+				}
+			}
+		}
+	}
+
+	// Remaining prefixes sort after the last feedback
+	for prefix != nil {
+		if err := s.reportOrphan(ctx, prefix, opts, summary, emit); err != nil {
+			return summary, err
+		}
+		if prefix, err = nextPrefix(); err != nil {
+			return summary, err
+		}
+	}
+
+	s.logger.Info("Consistency check finished",
+		"feedbacks_checked", summary.FeedbacksChecked,
+		"prefixes_checked", summary.PrefixesChecked,
+		"missing_objects", summary.MissingObjects,
+		"orphan_prefixes", summary.OrphanPrefixes,
+		"repaired", summary.Repaired,
+	)
+	return summary, nil
+}
+
+// expectedAttachments returns the filenames each live feedback should have in MinIO: those
+// recorded in feedback_assets plus, best effort, object paths mentioned in its content
+func (s *FeedbackService) expectedAttachments(ctx context.Context, refs []models.FeedbackRef) (map[uuid.UUID][]string, error) {
+	var ids []uuid.UUID
+	var idStrings []string
+	for _, ref := range refs {
+		if !ref.Deleted {
+			ids = append(ids, ref.ID)
+			idStrings = append(idStrings, ref.ID.String())
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	expected, err := s.assetRepo.ListFilenames(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachment metadata: %w", err)
+	}
+
+	contents, err := s.feedbackRepo.GetContents(ctx, idStrings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback contents: %w", err)
+	}
+
+	for _, id := range ids {
+		content, ok := contents[id.String()]
+		if !ok {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, filename := range expected[id] {
+			seen[filename] = true
+		}
+		for _, filename := range mentionedAttachments(id, content) {
+			if !seen[filename] {
+				seen[filename] = true
+				expected[id] = append(expected[id], filename)
+			}
+		}
+	}
+
+	return expected, nil
+}
+
+// mentionedAttachments finds object paths of the form {feedbackID}/{filename} in content
+func mentionedAttachments(id uuid.UUID, content string) []string {
+	pattern := regexp.MustCompile(regexp.QuoteMeta(id.String()) + `/([^\s/()<>"'\]]+)`)
+
+	var filenames []string
+	for _, match := range pattern.FindAllStringSubmatch(content, -1) {
+		filenames = append(filenames, match[1])
+	}
+	return filenames
+}
+
+// reportOrphan reports a prefix without a feedback row and removes it if requested
+func (s *FeedbackService) reportOrphan(ctx context.Context, prefix *models.AttachmentPrefix, opts models.ConsistencyOptions, summary *models.ConsistencySummary, emit func(*models.ConsistencyFinding) error) error {
+	summary.OrphanPrefixes++
+
+	finding := &models.ConsistencyFinding{
+		Kind:        models.FindingOrphanPrefix,
+		FeedbackID:  prefix.FeedbackID,
+		Prefix:      prefix.Prefix,
+		ObjectCount: len(prefix.Objects),
+	}
+	for _, object := range prefix.Objects {
+		finding.Bytes += object.Size
+	}
+
+	if opts.DeleteOrphans {
+		switch {
+		case prefix.FeedbackID == uuid.Nil:
+			finding.RepairError = "prefix is not a feedback ID"
+		default:
+			if _, err := s.attachmentRepo.DeleteAll(ctx, prefix.FeedbackID); err != nil {
+				finding.RepairError = err.Error()
+			} else {
+				finding.Repaired = true
+				summary.Repaired++
+			}
+		}
+	}
+
+	return emit(finding)
+}
+
+// reportMissing reports a feedback with missing objects and flags it for re-upload if requested
+func (s *FeedbackService) reportMissing(ctx context.Context, id uuid.UUID, missing []string, opts models.ConsistencyOptions, summary *models.ConsistencySummary, emit func(*models.ConsistencyFinding) error) error {
+	summary.MissingObjects++
+
+	finding := &models.ConsistencyFinding{
+		Kind:       models.FindingMissingObjects,
+		FeedbackID: id,
+		Missing:    missing,
+	}
+
+	if opts.MarkForReupload {
+		if err := s.feedbackRepo.SetNeedsReupload(ctx, id, true); err != nil {
+			finding.RepairError = err.Error()
+		} else {
+			finding.Repaired = true
+			summary.Repaired++
+		}
+	}
+
+	return emit(finding)
+}
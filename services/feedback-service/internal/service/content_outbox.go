@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// contentOutboxBatchSize is the number of content outbox entries written per batch
+const contentOutboxBatchSize = 100
+
+// ReconcileFeedback writes content still pending in the content outbox to MongoDB, whether or
+// not its next retry is due: the entry of one feedback, or every entry when feedbackID is
+// uuid.Nil. Entries that fail again are rescheduled with backoff.
+func (s *FeedbackService) ReconcileFeedback(ctx context.Context, feedbackID uuid.UUID) (*models.ContentReconciliation, error) {
+	return s.applyContentOutbox(ctx, feedbackID, false)
+}
+
+// RunContentOutbox writes every pending entry at startup, then the entries whose retry is due
+// every configured interval until ctx is cancelled
+func (s *FeedbackService) RunContentOutbox(ctx context.Context) {
+	if _, err := s.ReconcileFeedback(ctx, uuid.Nil); err != nil && ctx.Err() == nil {
+		s.logger.Error("Feedback content reconciliation failed", "error", err)
+	}
+
+	ticker := time.NewTicker(s.outboxCfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.applyContentOutbox(ctx, uuid.Nil, true); err != nil && ctx.Err() == nil {
+				s.logger.Error("Feedback content outbox run failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// applyContentOutbox writes pending entries in batches; with due set, only those whose retry
+// is due
+func (s *FeedbackService) applyContentOutbox(ctx context.Context, feedbackID uuid.UUID, due bool) (*models.ContentReconciliation, error) {
+	result := &models.ContentReconciliation{}
+	after := uuid.Nil
+	for {
+		entries, err := s.outboxRepo.ListPending(ctx, feedbackID, after, due, contentOutboxBatchSize)
+		if err != nil {
+			return result, err
+		}
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			after = entry.FeedbackID
+			if err := s.applyOutboxEntry(ctx, entry); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Applied++
+		}
+		if len(entries) < contentOutboxBatchSize {
+			break
+		}
+	}
+
+	remaining, err := s.outboxRepo.Count(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Remaining = remaining
+
+	if result.Applied > 0 {
+		// Cached pages may have been built while the content was missing
+		s.prefetch.invalidateAll()
+	}
+	if result.Applied > 0 || result.Failed > 0 {
+		s.logger.Info("Feedback content outbox applied",
+			"applied", result.Applied,
+			"failed", result.Failed,
+			"remaining", result.Remaining,
+		)
+	}
+	return result, nil
+}
+
+// applyOutboxEntry writes the content of one entry and completes it. A failed write is
+// counted on the entry and retried after a delay that doubles with every failure.
+func (s *FeedbackService) applyOutboxEntry(ctx context.Context, entry *models.ContentOutboxEntry) error {
+	if err := s.feedbackRepo.SetContent(ctx, entry.FeedbackID, entry.Content); err != nil {
+		delay := s.outboxRetryDelay(entry.Attempts)
+		s.logger.Warn("Failed to write feedback content from outbox",
+			"feedback_id", entry.FeedbackID,
+			"attempts", entry.Attempts+1,
+			"retry_in", delay,
+			"error", err,
+		)
+		if recordErr := s.outboxRepo.RecordFailure(context.WithoutCancel(ctx), entry, err.Error(), delay); recordErr != nil {
+			s.logger.Error("Failed to record content outbox failure", "feedback_id", entry.FeedbackID, "error", recordErr)
+		}
+		return err
+	}
+	// If completing fails, the entry is written again later, which is harmless
+	if err := s.outboxRepo.Complete(ctx, entry); err != nil {
+		s.logger.Error("Failed to complete content outbox entry", "feedback_id", entry.FeedbackID, "error", err)
+		return fmt.Errorf("failed to complete content outbox entry: %w", err)
+	}
+	return nil
+}
+
+// outboxRetryDelay returns the delay before retrying an entry that failed attempts times before
+func (s *FeedbackService) outboxRetryDelay(attempts int) time.Duration {
+	delay := s.outboxCfg.RetryDelay
+	for i := 0; i < attempts && delay < s.outboxCfg.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.outboxCfg.MaxRetryDelay)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// DashboardSchemaVersion is the version of the reviewer dashboard row schema. It changes when
+// fields change meaning or are removed, not when fields are added.
+const DashboardSchemaVersion = 1
+
+// cachedCount is a cached per-feedback count
+type cachedCount struct {
+	value   int32
+	expires time.Time
+}
+
+// countCache keeps per-feedback counts of one enrichment source for a short time. It trades a
+// little staleness for fewer queries; events evict entries that are known to have changed.
+type countCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cachedCount
+}
+
+// newCountCache creates a cache; a zero TTL disables it
+func newCountCache(ttl time.Duration, maxEntries int) *countCache {
+	return &countCache{ttl: ttl, maxEntries: maxEntries, entries: make(map[uuid.UUID]cachedCount)}
+}
+
+// get returns the cached counts of ids and the ids that must be loaded
+func (c *countCache) get(ids []uuid.UUID) (map[uuid.UUID]int32, []uuid.UUID) {
+	counts := make(map[uuid.UUID]int32, len(ids))
+	if c.ttl <= 0 {
+		return counts, ids
+	}
+
+	now := time.Now()
+	var missing []uuid.UUID
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		if entry, ok := c.entries[id]; ok && now.Before(entry.expires) {
+			counts[id] = entry.value
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return counts, missing
+}
+
+// put caches counts loaded for ids; ids without a count are cached as zero
+func (c *countCache) put(ids []uuid.UUID, counts map[uuid.UUID]int32) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	expires := time.Now().Add(c.ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Bounded by resetting: entries are cheap to load again
+	if len(c.entries)+len(ids) > c.maxEntries {
+		c.entries = make(map[uuid.UUID]cachedCount)
+	}
+	for _, id := range ids {
+		c.entries[id] = cachedCount{value: counts[id], expires: expires}
+	}
+}
+
+// forget drops the cached count of a feedback
+func (c *countCache) forget(id uuid.UUID) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
+
+// dashboardEnricher fills the enrichments of reviewer dashboard rows
+type dashboardEnricher struct {
+	cfg         config.DashboardConfig
+	attachments *countCache
+}
+
+// newDashboardEnricher creates the dashboard enrichment caches
+func newDashboardEnricher(cfg config.DashboardConfig) *dashboardEnricher {
+	return &dashboardEnricher{
+		cfg:         cfg,
+		attachments: newCountCache(cfg.AttachmentsTTL, cfg.CacheEntries),
+	}
+}
+
+// ForgetDashboardRow drops cached enrichments of a feedback, so the next dashboard load
+// reflects a change right away instead of after the cache TTL
+func (s *FeedbackService) ForgetDashboardRow(feedbackID uuid.UUID) {
+	s.dashboard.attachments.forget(feedbackID)
+}
+
+// GetReviewerDashboard returns a page of a reviewer's feedbacks with everything the dashboard
+// shows for each row. Rows come from the regular list query; enrichments are loaded with one
+// batched query per source for the whole page and may be slightly stale. A source that fails or
+// exceeds its timeout leaves its fields unset and is reported in DashboardPage.Degraded instead
+// of failing the page.
+func (s *FeedbackService) GetReviewerDashboard(ctx context.Context, filter models.FeedbackFilter) (*models.DashboardPage, error) {
+	if filter.ReviewerID == nil || *filter.ReviewerID <= 0 {
+		return nil, fmt.Errorf("invalid reviewer ID")
+	}
+	if filter.MinAttachments != nil && *filter.MinAttachments < 0 {
+		return nil, fmt.Errorf("invalid minimum attachment count")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	s.logger.Info("Getting reviewer dashboard",
+		"reviewer_id", *filter.ReviewerID,
+		"page", filter.Page,
+		"limit", filter.Limit,
+	)
+
+	filter, err := s.resolveAttachmentFilters(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	feedbacks, total, _, err := s.listFeedbackPage(ctx, listReviewer, filter, false)
+	if err != nil {
+		s.logger.Error("Failed to list reviewer dashboard feedbacks", "reviewer_id", *filter.ReviewerID, "error", err)
+		return nil, fmt.Errorf("failed to list reviewer feedbacks: %w", err)
+	}
+
+	page := &models.DashboardPage{
+		Rows:       make([]*models.DashboardRow, len(feedbacks)),
+		TotalCount: total,
+	}
+	ids := make([]uuid.UUID, len(feedbacks))
+	for i, feedback := range feedbacks {
+		ids[i] = feedback.ID
+		page.Rows[i] = &models.DashboardRow{
+			Feedback:       feedback,
+			ContentPreview: contentPreview(feedback.Content, s.dashboard.cfg.PreviewChars),
+		}
+	}
+	if len(ids) == 0 {
+		return page, nil
+	}
+
+	counts, err := s.dashboardAttachmentCounts(ctx, ids)
+	if err != nil {
+		s.logger.Warn("Dashboard enrichment degraded",
+			"reviewer_id", *filter.ReviewerID,
+			"source", models.DashboardSourceAttachments,
+			"error", err,
+		)
+		page.Degraded = append(page.Degraded, models.DegradedSource{
+			Source: models.DashboardSourceAttachments,
+			Reason: degradedReason(err),
+		})
+	} else {
+		for _, row := range page.Rows {
+			count := counts[row.Feedback.ID]
+			row.AttachmentCount = &count
+		}
+	}
+
+	s.logger.Info("Reviewer dashboard assembled",
+		"reviewer_id", *filter.ReviewerID,
+		"rows", len(page.Rows),
+		"degraded", len(page.Degraded),
+	)
+	return page, nil
+}
+
+// dashboardAttachmentCounts returns the attachment count of each feedback, from the cache where
+// possible and with a single metadata query for the rest
+func (s *FeedbackService) dashboardAttachmentCounts(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int32, error) {
+	counts, missing := s.dashboard.attachments.get(ids)
+	if len(missing) == 0 {
+		return counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.dashboard.cfg.SourceTimeout)
+	defer cancel()
+	filenames, err := s.assetRepo.ListFilenames(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[uuid.UUID]int32, len(filenames))
+	for id, names := range filenames {
+		loaded[id] = int32(len(names))
+	}
+	s.dashboard.attachments.put(missing, loaded)
+	for _, id := range missing {
+		counts[id] = loaded[id]
+	}
+	return counts, nil
+}
+
+// degradedReason describes why an enrichment source failed without exposing its error
+func degradedReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return "unavailable"
+}
+
+// contentPreview shortens content to maxChars characters for list display, marking a cut with
+// an ellipsis
+func contentPreview(content string, maxChars int) string {
+	content = strings.TrimSpace(content)
+	runes := []rune(content)
+	if len(runes) <= maxChars {
+		return content
+	}
+	return strings.TrimRightFunc(string(runes[:maxChars]), unicode.IsSpace) + "…"
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// deletionAttempts is how often deleting one dataset is tried before the feedback deletion stops
+const deletionAttempts = 3
+
+// deletionRetryDelay is the wait before the first retry of a dataset; it doubles with each retry
+const deletionRetryDelay = 200 * time.Millisecond
+
+// datasetDeleter removes one dataset of a feedback and returns how many items it removed.
+// Removing a dataset that is already gone must succeed and return 0.
+type datasetDeleter struct {
+	dataset string
+	delete  func(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// deletionPlan lists everything a feedback owns, in deletion order. It is the only place that
+// knows this dependency graph; data keyed by feedback ID that is added later belongs here, before
+// the feedback row. Audit log entries are kept on purpose.
+func (s *FeedbackService) deletionPlan() []datasetDeleter {
+	return []datasetDeleter{
+		{models.DatasetStagedFiles, s.deleteStagedFiles},
+		{models.DatasetUploadSessions, s.sessionRepo.DeleteByFeedback},
+		{models.DatasetAttachments, s.attachmentRepo.DeleteAll},
+		{models.DatasetAttachmentMetadata, s.assetRepo.DeleteAll},
+		{models.DatasetContentOutbox, s.outboxRepo.DeleteByFeedback},
+		{models.DatasetContent, s.feedbackRepo.DeleteContent},
+		{models.DatasetFeedback, s.deleteFeedbackRow},
+	}
+}
+
+// deletionRecoveryBatchSize is the number of interrupted deletions resumed per batch
+const deletionRecoveryBatchSize = 50
+
+// purgeFeedback hard deletes a feedback on behalf of actorID. It first records a deletion
+// intent, which hides the feedback in the same transaction, so for callers the feedback is
+// deleted once the intent commits. If removing its data fails, the failure is reported in the
+// returned datasets and RecoverDeletions finishes the deletion later; only a failure to record
+// the intent is returned as an error.
+func (s *FeedbackService) purgeFeedback(ctx context.Context, id uuid.UUID, actorID int64, details string) ([]models.DatasetDeletion, error) {
+	intent := &models.DeletionIntent{
+		FeedbackID:  id,
+		RequestedBy: actorID,
+		Action:      models.AuditActionPurged,
+		Details:     details,
+	}
+	if err := s.intentRepo.Begin(ctx, intent); err != nil {
+		return nil, fmt.Errorf("failed to begin feedback deletion: %w", err)
+	}
+
+	datasets, err := s.completeDeletion(ctx, intent)
+	if err != nil {
+		s.logger.Warn("Feedback deletion left to recovery", "feedback_id", id, "error", err)
+	}
+	return datasets, nil
+}
+
+// completeDeletion removes the data of a feedback whose deletion intent is recorded, then
+// audits the deletion, publishes it and removes the intent. A failure is counted on the intent.
+// Every step may run again after a crash: an interrupted run is resumed as a whole, so a
+// deletion may be audited and published twice, the second time with zero counts.
+func (s *FeedbackService) completeDeletion(ctx context.Context, intent *models.DeletionIntent) ([]models.DatasetDeletion, error) {
+	id := intent.FeedbackID
+	datasets, err := s.deleteFeedbackData(ctx, id)
+	if err != nil {
+		if recordErr := s.intentRepo.RecordFailure(context.WithoutCancel(ctx), id, err.Error()); recordErr != nil {
+			s.logger.Error("Failed to record feedback deletion failure", "feedback_id", id, "error", recordErr)
+		}
+		return datasets, err
+	}
+
+	s.recordDeletion(ctx, id, intent.RequestedBy, intent.Action, intent.Details, datasets)
+	bus.Publish(ctx, s.events, bus.FeedbackDeleted, bus.FeedbackDeletedEvent{FeedbackID: id, ActorID: intent.RequestedBy, Purged: true})
+
+	if err := s.intentRepo.Complete(ctx, id); err != nil {
+		// Nothing is left to delete; recovery completes the intent on its next run
+		s.logger.Error("Failed to complete feedback deletion intent", "feedback_id", id, "error", err)
+	}
+	return datasets, nil
+}
+
+// RecoverDeletions resumes hard deletions whose intent was not touched for the configured
+// DeletionConfig.RecoveryAfter, e.g. because the process died midway. Failed deletions are
+// retried once they are stale again. It returns the number of deletions completed.
+func (s *FeedbackService) RecoverDeletions(ctx context.Context) (int, error) {
+	var completed int
+	for {
+		intents, err := s.intentRepo.ListStale(ctx, s.deletionCfg.RecoveryAfter, deletionRecoveryBatchSize)
+		if err != nil {
+			return completed, err
+		}
+
+		var batchCompleted int
+		for _, intent := range intents {
+			if err := ctx.Err(); err != nil {
+				return completed, err
+			}
+			s.logger.Info("Resuming interrupted feedback deletion",
+				"feedback_id", intent.FeedbackID,
+				"requested_by", intent.RequestedBy,
+				"attempts", intent.Attempts,
+				"last_error", intent.LastError,
+			)
+			if _, err := s.completeDeletion(ctx, intent); err != nil {
+				s.logger.Error("Failed to resume feedback deletion", "feedback_id", intent.FeedbackID, "error", err)
+				continue
+			}
+			batchCompleted++
+		}
+		completed += batchCompleted
+
+		// Failed intents are touched and drop out of the stale list; stop if none made progress
+		if len(intents) < deletionRecoveryBatchSize || batchCompleted == 0 {
+			break
+		}
+	}
+
+	if completed > 0 {
+		s.prefetch.invalidateAll()
+		s.logger.Info("Interrupted feedback deletions completed", "count", completed)
+	}
+	return completed, nil
+}
+
+// RunDeletionRecovery resumes interrupted deletions at startup and then every configured
+// interval until ctx is cancelled
+func (s *FeedbackService) RunDeletionRecovery(ctx context.Context) {
+	recoverDeletions := func() {
+		if _, err := s.RecoverDeletions(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Feedback deletion recovery failed", "error", err)
+		}
+	}
+
+	recoverDeletions()
+	if s.deletionCfg.RecoveryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.deletionCfg.RecoveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			recoverDeletions()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deleteFeedbackData hard deletes a feedback and everything it owns, one dataset at a time.
+// A dataset that fails is retried; if it still fails, deletion stops there and the error is
+// returned together with the datasets handled so far. The feedback row is deleted last, so
+// calling again resumes the deletion. Callers record a deletion intent first, see purgeFeedback.
+func (s *FeedbackService) deleteFeedbackData(ctx context.Context, id uuid.UUID) ([]models.DatasetDeletion, error) {
+	plan := s.deletionPlan()
+	datasets := make([]models.DatasetDeletion, 0, len(plan))
+	for _, step := range plan {
+		result := models.DatasetDeletion{Dataset: step.dataset}
+		delay := deletionRetryDelay
+		for {
+			result.Attempts++
+			result.Deleted, result.Err = step.delete(ctx, id)
+			if result.Err == nil || result.Attempts >= deletionAttempts || ctx.Err() != nil {
+				break
+			}
+			s.logger.Warn("Retrying feedback dataset deletion",
+				"feedback_id", id,
+				"dataset", step.dataset,
+				"attempt", result.Attempts,
+				"error", result.Err,
+			)
+			if err := sleepContext(ctx, delay); err != nil {
+				break
+			}
+			delay *= 2
+		}
+		datasets = append(datasets, result)
+
+		if result.Err != nil {
+			s.logger.Error("Feedback deletion stopped",
+				"feedback_id", id,
+				"dataset", step.dataset,
+				"attempts", result.Attempts,
+				"error", result.Err,
+			)
+			return datasets, fmt.Errorf("failed to delete feedback %s: %w", step.dataset, result.Err)
+		}
+	}
+
+	s.logger.Info("Feedback data deleted", "feedback_id", id, "datasets", deletionSummary(datasets))
+	return datasets, nil
+}
+
+// deleteStagedFiles removes the staged objects of every upload session of a feedback
+func (s *FeedbackService) deleteStagedFiles(ctx context.Context, id uuid.UUID) (int64, error) {
+	sessionIDs, err := s.sessionRepo.ListIDsByFeedback(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, sessionID := range sessionIDs {
+		n, err := s.attachmentRepo.DeleteStaged(ctx, sessionID)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteFeedbackRow deletes the feedback row; a row that is already gone counts as deleted
+func (s *FeedbackService) deleteFeedbackRow(ctx context.Context, id uuid.UUID) (int64, error) {
+	err := s.feedbackRepo.Delete(ctx, id)
+	if errors.Is(err, repository.ErrFeedbackNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// deletionSummary formats per-dataset counts for logs and audit entries, e.g.
+// "attachments=2 content=1 feedback=1"
+func deletionSummary(datasets []models.DatasetDeletion) string {
+	parts := make([]string, len(datasets))
+	for i, dataset := range datasets {
+		parts[i] = fmt.Sprintf("%s=%d", dataset.Dataset, dataset.Deleted)
+	}
+	return strings.Join(parts, " ")
+}
+
+// sleepContext waits for d or until ctx ends
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
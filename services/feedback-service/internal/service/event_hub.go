@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxJournalEvents is the most events the journal keeps per key, however recent
+const maxJournalEvents = 1000
+
+var (
+	// errPositionExpired is returned by eventHub.watch for a position whose following events
+	// are no longer all retained
+	errPositionExpired = errors.New("event position is older than the retained history")
+
+	// errPositionUnknown is returned by eventHub.watch for a position the hub never issued
+	errPositionUnknown = errors.New("event position was never issued")
+)
+
+// sequencedEvent is an event with its position in the hub's journal
+type sequencedEvent[E any] struct {
+	position models.StreamPosition
+	event    E
+	at       time.Time
+}
+
+// eventWatch is the buffered queue of one event stream
+type eventWatch[E any] struct {
+	events chan sequencedEvent[E]
+	lagged chan struct{} // Closed, and the watch removed, once the queue overflowed
+}
+
+// eventHub fans events out to the streams watching their key, such as the student a feedback
+// event addresses. A stream whose queue is full is dropped rather than slowing down the others.
+//
+// Every event published is numbered, across keys, and kept in a journal for the replay window,
+// so a stream can resume after the last event it received. The journal is lost on restart and
+// when events may have been missed; its epoch then changes, which expires every earlier position.
+type eventHub[K comparable, E any] struct {
+	bufferSize   int
+	replayWindow time.Duration
+	closed       chan struct{} // Closed on shutdown, ending every stream
+	closeOnce    sync.Once
+
+	mu         sync.Mutex
+	watchers   map[K]map[*eventWatch[E]]struct{}
+	journals   map[K][]sequencedEvent[E]
+	epoch      string
+	sequence   uint64    // Sequence number of the last event published
+	pruned     uint64    // Highest sequence number dropped from the journal
+	lastSweep  time.Time // When every journal was last pruned
+	lastActive time.Time // When a stream last started or ended
+}
+
+// newEventHub creates a hub whose streams buffer bufferSize events each and whose journal
+// keeps events for replayWindow
+func newEventHub[K comparable, E any](bufferSize int, replayWindow time.Duration) *eventHub[K, E] {
+	return &eventHub[K, E]{
+		bufferSize:   bufferSize,
+		replayWindow: replayWindow,
+		closed:       make(chan struct{}),
+		watchers:     make(map[K]map[*eventWatch[E]]struct{}),
+		journals:     make(map[K][]sequencedEvent[E]),
+		epoch:        uuid.NewString(),
+	}
+}
+
+// watch registers a stream for the events of a key and returns the position it starts at.
+// With resume, the journaled events of the key after that position are returned as well, to be
+// sent before the queued ones; no event is both returned and queued.
+func (h *eventHub[K, E]) watch(key K, resume *models.StreamPosition) (*eventWatch[E], []sequencedEvent[E], models.StreamPosition, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []sequencedEvent[E]
+	if resume != nil {
+		if resume.Epoch != h.epoch || resume.Sequence < h.pruned {
+			return nil, nil, models.StreamPosition{}, errPositionExpired
+		}
+		if resume.Sequence > h.sequence {
+			return nil, nil, models.StreamPosition{}, errPositionUnknown
+		}
+		for _, event := range h.journals[key] {
+			if event.position.Sequence > resume.Sequence {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	w := &eventWatch[E]{
+		events: make(chan sequencedEvent[E], h.bufferSize),
+		lagged: make(chan struct{}),
+	}
+	if h.watchers[key] == nil {
+		h.watchers[key] = make(map[*eventWatch[E]]struct{})
+	}
+	h.watchers[key][w] = struct{}{}
+	h.lastActive = time.Now()
+	return w, backlog, models.StreamPosition{Epoch: h.epoch, Sequence: h.sequence}, nil
+}
+
+// unwatch removes a stream; removing it twice is a no-op
+func (h *eventHub[K, E]) unwatch(key K, w *eventWatch[E]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(key, w)
+	h.lastActive = time.Now()
+}
+
+// remove removes a stream; the caller holds h.mu
+func (h *eventHub[K, E]) remove(key K, w *eventWatch[E]) {
+	watchers := h.watchers[key]
+	delete(watchers, w)
+	if len(watchers) == 0 {
+		delete(h.watchers, key)
+	}
+}
+
+// publish journals an event and queues it for every stream of a key without blocking
+func (h *eventHub[K, E]) publish(key K, event E) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.sequence++
+	sequenced := sequencedEvent[E]{
+		position: models.StreamPosition{Epoch: h.epoch, Sequence: h.sequence},
+		event:    event,
+		at:       now,
+	}
+	h.journals[key] = append(h.journals[key], sequenced)
+	h.prune(key, now)
+	if now.Sub(h.lastSweep) >= h.replayWindow {
+		for key := range h.journals {
+			h.prune(key, now)
+		}
+		h.lastSweep = now
+	}
+	h.deliver(key, sequenced)
+}
+
+// publishEphemeral queues an event for every stream of a key without journaling it, for events
+// that are not worth replaying. It carries the position of the last journaled event.
+func (h *eventHub[K, E]) publishEphemeral(key K, event E) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deliver(key, sequencedEvent[E]{
+		position: models.StreamPosition{Epoch: h.epoch, Sequence: h.sequence},
+		event:    event,
+		at:       time.Now(),
+	})
+}
+
+// deliver queues an event for every stream of a key; the caller holds h.mu
+func (h *eventHub[K, E]) deliver(key K, event sequencedEvent[E]) {
+	for w := range h.watchers[key] {
+		select {
+		case w.events <- event:
+		default:
+			h.remove(key, w)
+			close(w.lagged)
+		}
+	}
+}
+
+// prune drops the events of a key that are older than the replay window or beyond the journal
+// limit; the caller holds h.mu
+func (h *eventHub[K, E]) prune(key K, now time.Time) {
+	journal := h.journals[key]
+	drop := max(len(journal)-maxJournalEvents, 0)
+	for drop < len(journal) && now.Sub(journal[drop].at) >= h.replayWindow {
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+	h.pruned = max(h.pruned, journal[drop-1].position.Sequence)
+	if drop == len(journal) {
+		delete(h.journals, key)
+		return
+	}
+	h.journals[key] = append(journal[:0:0], journal[drop:]...)
+}
+
+// releaseIfIdle reports whether no stream has been registered for the replay window, so no
+// stream can resume and events need not be journaled. The journal is then discarded, and its
+// epoch renewed, as the events that follow are not observed.
+func (h *eventHub[K, E]) releaseIfIdle() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.watchers) > 0 || time.Since(h.lastActive) < h.replayWindow {
+		return false
+	}
+	h.forget()
+	return true
+}
+
+// forget discards the journal and renews its epoch; the caller holds h.mu
+func (h *eventHub[K, E]) forget() {
+	clear(h.journals)
+	h.epoch = uuid.NewString()
+	h.pruned = h.sequence
+}
+
+// dropAll drops every current stream as lagged and forgets the journal, for when events may
+// have been missed
+func (h *eventHub[K, E]) dropAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key, watchers := range h.watchers {
+		for w := range watchers {
+			close(w.lagged)
+		}
+		delete(h.watchers, key)
+	}
+	h.forget()
+}
+
+// close ends every stream, current and future
+func (h *eventHub[K, E]) close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
@@ -2,218 +2,842 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
 	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrFeedbackLocked is returned when a mutation targets a locked feedback
+	ErrFeedbackLocked = authz.ErrFeedbackLocked
+
+	// ErrFeedbackLockDisabled is returned when a feedback's course policy does not allow locking
+	ErrFeedbackLockDisabled = authz.ErrFeedbackLockDisabled
+
+	// ErrInvalidCourseID is returned when a request carries a malformed course ID
+	ErrInvalidCourseID = errors.New("invalid course ID")
+
+	// ErrDuplicateFeedback is returned when a reviewer creates a second feedback for a
+	// submission. The existing feedback is named in the existing_feedback_id metadata.
+	ErrDuplicateFeedback = apperr.AlreadyExists("DUPLICATE_FEEDBACK", "the reviewer already has a feedback for this submission")
+
+	// ErrTransferTargetHasFeedback is returned when a feedback is transferred to a reviewer who
+	// already has feedback on its submission, named in the existing_feedback_id metadata
+	ErrTransferTargetHasFeedback = apperr.FailedPrecondition("TARGET_REVIEWER_HAS_FEEDBACK", "the new reviewer already has a feedback for this submission")
+
+	// ErrReviewerChanged is returned when a transfer names a current reviewer the feedback no
+	// longer belongs to, e.g. after a concurrent transfer
+	ErrReviewerChanged = apperr.FailedPrecondition("REVIEWER_CHANGED", "the feedback belongs to another reviewer")
+)
+
 // FeedbackService handles feedback business logic
 type FeedbackService struct {
 	feedbackRepo   repository.FeedbackRepository
 	attachmentRepo repository.AttachmentRepository
+	assetRepo      repository.AssetRepository
+	auditRepo      repository.AuditRepository
+	sessionRepo    repository.UploadSessionRepository
+	intentRepo     repository.DeletionIntentRepository
+	deletionCfg    config.DeletionConfig
+	policies       *CoursePolicyService
+	prefetch       *listPrefetcher
+	dashboard      *dashboardEnricher
+	lister         *attachmentLister
+	sealRepo       repository.SealRepository
+	outboxRepo     repository.ContentOutboxRepository
+	commentRepo    repository.CommentRepository
+	outboxCfg      config.OutboxConfig
+	archiveCfg     config.ArchiveConfig
+	studentEvents  *studentEventHub
+	progressEvery  time.Duration // Least time between two progress events of one upload
+	upstream       *UpstreamValidator
+	limits         config.FeedbackConfig
+	idempotencyTTL time.Duration // How long CreateFeedback idempotency keys are honored (0 forever)
+	events         *bus.Bus
 	logger         *slog.Logger
+
+	corruptAttachments atomic.Int64 // Checksum mismatches detected since startup
+}
+
+// FeedbackServiceDeps holds the repositories, collaborators and settings of a FeedbackService
+type FeedbackServiceDeps struct {
+	FeedbackRepo    repository.FeedbackRepository
+	AttachmentRepo  repository.AttachmentRepository
+	AssetRepo       repository.AssetRepository
+	AuditRepo       repository.AuditRepository
+	SessionRepo     repository.UploadSessionRepository
+	IntentRepo      repository.DeletionIntentRepository
+	BackfillJournal repository.BackfillJournalRepository // Nil lists attachments from storage
+	SealRepo        repository.SealRepository
+	OutboxRepo      repository.ContentOutboxRepository
+	CommentRepo     repository.CommentRepository // Nil leaves comment thread summaries out
+	Policies        *CoursePolicyService
+	Upstream        *UpstreamValidator // Nil skips the checks of other services
+	Events          *bus.Bus           // Nil publishes no events
+
+	DeletionCfg    config.DeletionConfig
+	PrefetchCfg    config.PrefetchConfig
+	DashboardCfg   config.DashboardConfig
+	MetadataCfg    config.AttachmentMetadataConfig
+	OutboxCfg      config.OutboxConfig
+	ArchiveCfg     config.ArchiveConfig
+	EventsCfg      config.EventStreamConfig
+	Limits         config.FeedbackConfig
+	IdempotencyTTL time.Duration // How long CreateFeedback idempotency keys are honored (0 forever)
+
+	Logger *slog.Logger
 }
 
 // NewFeedbackService creates a new feedback service
-func NewFeedbackService(feedbackRepo repository.FeedbackRepository, attachmentRepo repository.AttachmentRepository, logger *slog.Logger) *FeedbackService {
+func NewFeedbackService(deps FeedbackServiceDeps) *FeedbackService {
 	return &FeedbackService{
-		feedbackRepo:   feedbackRepo,
-		attachmentRepo: attachmentRepo,
-		logger:         logger,
+		feedbackRepo:   deps.FeedbackRepo,
+		attachmentRepo: deps.AttachmentRepo,
+		assetRepo:      deps.AssetRepo,
+		auditRepo:      deps.AuditRepo,
+		sessionRepo:    deps.SessionRepo,
+		intentRepo:     deps.IntentRepo,
+		deletionCfg:    deps.DeletionCfg,
+		policies:       deps.Policies,
+		prefetch:       newListPrefetcher(deps.PrefetchCfg, deps.Logger),
+		dashboard:      newDashboardEnricher(deps.DashboardCfg),
+		lister:         newAttachmentLister(deps.MetadataCfg, deps.AttachmentRepo, deps.AssetRepo, deps.BackfillJournal),
+		sealRepo:       deps.SealRepo,
+		outboxRepo:     deps.OutboxRepo,
+		commentRepo:    deps.CommentRepo,
+		outboxCfg:      deps.OutboxCfg,
+		archiveCfg:     deps.ArchiveCfg,
+		studentEvents:  newStudentEventHub(deps.EventsCfg),
+		progressEvery:  deps.EventsCfg.UploadProgressInterval,
+		upstream:       deps.Upstream,
+		limits:         deps.Limits,
+		idempotencyTTL: deps.IdempotencyTTL,
+		events:         deps.Events,
+		logger:         deps.Logger,
 	}
 }
 
-// CreateFeedback creates a new feedback entry (reviewer only)
-func (s *FeedbackService) CreateFeedback(ctx context.Context, reviewerID, studentID, submissionID int64, title, content string) (*models.Feedback, error) {
+// CreateFeedback creates a new feedback entry (reviewer only). It is saved as a draft that
+// only the reviewer sees unless req.Publish is set. The rubric items, if any, are stored with
+// the feedback. A retry with the idempotency key and payload of an earlier request returns the
+// feedback that request created.
+func (s *FeedbackService) CreateFeedback(ctx context.Context, req models.FeedbackCreateRequest) (*models.Feedback, error) {
 	s.logger.Info("Creating new feedback",
-		"reviewer_id", reviewerID,
-		"student_id", studentID,
-		"submission_id", submissionID,
-		"title", title,
+		"reviewer_id", req.ReviewerID,
+		"student_id", req.StudentID,
+		"submission_id", req.SubmissionID,
+		"course_id", req.CourseID,
+		"title", req.Title,
+		"publish", req.Publish,
 	)
 
 	// Validate input
-	if reviewerID <= 0 {
+	if req.ReviewerID <= 0 {
 		return nil, fmt.Errorf("invalid reviewer ID")
 	}
-	if studentID <= 0 {
+	if req.StudentID <= 0 {
 		return nil, fmt.Errorf("invalid student ID")
 	}
-	if submissionID <= 0 {
+	if req.SubmissionID <= 0 {
 		return nil, fmt.Errorf("invalid submission ID")
 	}
-	if title == "" {
-		return nil, fmt.Errorf("title is required")
+	if err := validateTitle(req.Title, s.limits); err != nil {
+		return nil, err
+	}
+	if err := CheckTextLimits(s.limits, nil, &req.Content); err != nil {
+		return nil, err
+	}
+	if err := validateSnapshot(req.Snapshot); err != nil {
+		return nil, err
+	}
+	if req.Snapshot.IsEmpty() {
+		req.Snapshot = nil
+	}
+	if err := ValidateCourseID(req.CourseID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCourseID, err)
+	}
+	if err := validateGrade(req.Grade); err != nil {
+		return nil, err
+	}
+	if err := validateRubric(req.RubricItems); err != nil {
+		return nil, err
+	}
+	if err := validateIdempotencyKey(req.IdempotencyKey); err != nil {
+		return nil, err
+	}
+
+	var fingerprint string
+	if req.IdempotencyKey != "" {
+		fingerprint = createFingerprint(req)
+		if feedback, err := s.replayCreate(ctx, req.ReviewerID, req.IdempotencyKey, fingerprint); err != nil || feedback != nil {
+			return feedback, err
+		}
+	}
+
+	// Retries replayed above are not checked again, so they succeed while a service is down
+	if err := s.upstream.CheckFeedbackIDs(ctx, req.ReviewerID, req.StudentID, req.SubmissionID); err != nil {
+		return nil, err
 	}
 
 	// Create feedback entry
 	feedback := &models.Feedback{
-		ReviewerID:   reviewerID,
-		StudentID:    studentID,
-		SubmissionID: submissionID,
-		Title:        title,
-		Content:      content,
+		ReviewerID:   req.ReviewerID,
+		StudentID:    req.StudentID,
+		SubmissionID: req.SubmissionID,
+		Title:        req.Title,
+		Content:      req.Content,
+		Snapshot:     req.Snapshot,
+		CourseID:     req.CourseID,
+		Grade:        req.Grade,
+		RubricItems:  req.RubricItems,
+		Status:       models.FeedbackDraft,
+
+		RequiresResubmission: req.RequiresResubmission,
+		ResubmissionDeadline: req.ResubmissionDeadline,
+
+		IdempotencyKey:         req.IdempotencyKey,
+		IdempotencyFingerprint: fingerprint,
+	}
+	if err := validateResubmission(feedback); err != nil {
+		return nil, err
+	}
+	if req.Publish {
+		if err := checkPublishDeadline(feedback, time.Now()); err != nil {
+			return nil, err
+		}
+		feedback.Status = models.FeedbackPublished
 	}
 
 	// Save to repository (handles both PostgreSQL and MongoDB)
 	if err := s.feedbackRepo.Create(ctx, feedback); err != nil {
+		if errors.Is(err, repository.ErrIdempotencyKeyInUse) {
+			// A concurrent retry created the feedback first
+			if existing, replayErr := s.replayCreate(ctx, req.ReviewerID, req.IdempotencyKey, fingerprint); replayErr != nil || existing != nil {
+				return existing, replayErr
+			}
+		}
+		var duplicate *repository.DuplicateFeedbackError
+		if errors.As(err, &duplicate) {
+			s.logger.Warn("Duplicate feedback rejected",
+				"reviewer_id", req.ReviewerID,
+				"submission_id", req.SubmissionID,
+				"existing_feedback_id", duplicate.ExistingID,
+			)
+			if duplicate.ExistingID == uuid.Nil {
+				return nil, ErrDuplicateFeedback.Wrap(err)
+			}
+			return nil, ErrDuplicateFeedback.Wrap(err).WithMetadata("existing_feedback_id", duplicate.ExistingID.String())
+		}
 		s.logger.Error("Failed to create feedback", "error", err)
 		return nil, fmt.Errorf("failed to create feedback: %w", err)
 	}
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, bus.FeedbackCreated, bus.FeedbackEvent{Feedback: feedback})
+	if feedback.IsPublished() {
+		bus.Publish(ctx, s.events, bus.FeedbackPublished, bus.FeedbackEvent{Feedback: feedback})
+	}
 
-	s.logger.Info("Feedback created successfully", "feedback_id", feedback.ID)
+	s.logger.Info("Feedback created successfully", "feedback_id", feedback.ID, "status", feedback.Status)
 	return feedback, nil
 }
 
-// UpdateFeedback updates an existing feedback entry (reviewer only)
-func (s *FeedbackService) UpdateFeedback(ctx context.Context, id uuid.UUID, reviewerID int64, title, content *string) (*models.Feedback, error) {
+// UpdateFeedback updates an existing feedback entry (reviewer only). Without a mask, a nil field
+// is left unchanged, ClearGrade removes the grade, non-empty rubric items replace the stored
+// ones and ClearRubricItems removes them; likewise ClearResubmissionDeadline removes the
+// resubmission deadline, and turning RequiresResubmission off removes it too. With a mask,
+// exactly the named fields are replaced and a named nil field is cleared. A new deadline on a
+// published feedback must be in the future.
+func (s *FeedbackService) UpdateFeedback(ctx context.Context, req models.FeedbackUpdateRequest) (*models.Feedback, error) {
 	s.logger.Info("Updating feedback",
-		"feedback_id", id,
-		"reviewer_id", reviewerID,
+		"feedback_id", req.ID,
+		"reviewer_id", req.ReviewerID,
 	)
 
-	if id == uuid.Nil {
-		return nil, fmt.Errorf("invalid feedback ID")
+	if req.ID == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
 	}
-	if reviewerID <= 0 {
-		return nil, fmt.Errorf("invalid reviewer ID")
+	if req.ReviewerID <= 0 {
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if req.Grade != nil && req.ClearGrade {
+		return nil, apperr.InvalidField("clear_grade", "clear_grade conflicts with grade")
+	}
+	if len(req.RubricItems) > 0 && req.ClearRubricItems {
+		return nil, apperr.InvalidField("clear_rubric_items", "clear_rubric_items conflicts with rubric_items")
+	}
+	if req.ResubmissionDeadline != nil && req.ClearResubmissionDeadline {
+		return nil, apperr.InvalidField("clear_resubmission_deadline", "clear_resubmission_deadline conflicts with resubmission_deadline")
+	}
+	if req.Title != nil {
+		if err := validateTitle(*req.Title, s.limits); err != nil {
+			return nil, err
+		}
+	}
+	if err := CheckTextLimits(s.limits, nil, req.Content); err != nil {
+		return nil, err
+	}
+	if len(req.Mask) > 0 {
+		if req.ClearGrade {
+			return nil, apperr.InvalidField("clear_grade", "clear_grade conflicts with update_mask; name grade in the mask and leave it unset")
+		}
+		if req.ClearRubricItems {
+			return nil, apperr.InvalidField("clear_rubric_items", "clear_rubric_items conflicts with update_mask; name rubric_items in the mask and leave it empty")
+		}
+		if req.ClearResubmissionDeadline {
+			return nil, apperr.InvalidField("clear_resubmission_deadline", "clear_resubmission_deadline conflicts with update_mask; name resubmission_deadline in the mask and leave it unset")
+		}
+		if err := validateUpdateMask(req.Mask, req.Title); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateGrade(req.Grade); err != nil {
+		return nil, err
+	}
+	if err := validateRubric(req.RubricItems); err != nil {
+		return nil, err
 	}
 
 	// Get existing feedback
-	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	feedback, err := s.feedbackRepo.GetByID(ctx, req.ID)
 	if err != nil {
-		s.logger.Error("Failed to get feedback for update", "feedback_id", id, "error", err)
+		s.logger.Error("Failed to get feedback for update", "feedback_id", req.ID, "error", err)
 		return nil, fmt.Errorf("failed to get feedback: %w", err)
 	}
 
-	// Check if the reviewer is the author
-	if !feedback.CanModify(reviewerID) {
-		s.logger.Warn("Access denied to update feedback",
-			"feedback_id", id,
+	if err := authz.UpdateFeedback(authz.Principal{UserID: req.ReviewerID}, feedback); err != nil {
+		s.logger.Warn("Feedback update denied",
+			"feedback_id", req.ID,
 			"owner_id", feedback.ReviewerID,
-			"attempted_by_id", reviewerID,
+			"attempted_by_id", req.ReviewerID,
+			"error", err,
 		)
-		return nil, fmt.Errorf("access denied: only the feedback author can update it")
+		return nil, err
 	}
 
-	// Update fields if provided
-	if title != nil {
-		feedback.Title = *title
+	contentSet := req.Content != nil
+	if len(req.Mask) > 0 {
+		contentSet = slices.Contains(req.Mask, updatePathContent)
+		applyUpdateMask(feedback, req)
+	} else {
+		// Update fields if provided
+		if req.Title != nil {
+			feedback.Title = *req.Title
+		}
+		if req.Content != nil {
+			feedback.Content = *req.Content
+		}
+		if req.Grade != nil || req.ClearGrade {
+			feedback.Grade = req.Grade
+		}
+		if len(req.RubricItems) > 0 || req.ClearRubricItems {
+			feedback.RubricItems = req.RubricItems
+		}
+		applyResubmission(feedback, req.RequiresResubmission, req.ResubmissionDeadline, req.ClearResubmissionDeadline)
 	}
-	if content != nil {
-		feedback.Content = *content
+	if err := validateResubmission(feedback); err != nil {
+		return nil, err
+	}
+	if req.ResubmissionDeadline != nil && feedback.IsPublished() {
+		if err := checkPublishDeadline(feedback, time.Now()); err != nil {
+			return nil, err
+		}
 	}
 
 	// Save changes
-	if err := s.feedbackRepo.Update(ctx, feedback); err != nil {
-		s.logger.Error("Failed to update feedback", "feedback_id", id, "error", err)
+	if err := s.feedbackRepo.Update(ctx, feedback, req.ReviewerID, contentSet); err != nil {
+		s.logger.Error("Failed to update feedback", "feedback_id", req.ID, "error", err)
 		return nil, fmt.Errorf("failed to update feedback: %w", err)
 	}
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, bus.FeedbackUpdated, bus.FeedbackEvent{Feedback: feedback})
 
-	s.logger.Info("Feedback updated successfully", "feedback_id", id)
+	s.logger.Info("Feedback updated successfully", "feedback_id", req.ID)
 	return feedback, nil
 }
 
-// DeleteFeedback deletes a feedback entry (reviewer only)
-func (s *FeedbackService) DeleteFeedback(ctx context.Context, id uuid.UUID, reviewerID int64) error {
+// DeleteFeedback hard deletes a feedback entry and all of its data (reviewer only). It returns
+// what was deleted per dataset. Once the deletion intent is recorded the feedback is gone for
+// callers; data that could not be removed yet is deleted by RecoverDeletions.
+func (s *FeedbackService) DeleteFeedback(ctx context.Context, id uuid.UUID, reviewerID int64) ([]models.DatasetDeletion, error) {
 	s.logger.Info("Deleting feedback",
 		"feedback_id", id,
 		"reviewer_id", reviewerID,
 	)
 
 	if id == uuid.Nil {
-		return fmt.Errorf("invalid feedback ID")
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
 	}
 	if reviewerID <= 0 {
-		return fmt.Errorf("invalid reviewer ID")
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
 	}
 
 	// Get existing feedback
 	feedback, err := s.feedbackRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Failed to get feedback for deletion", "feedback_id", id, "error", err)
-		return fmt.Errorf("failed to get feedback: %w", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
 	}
 
-	// Check if the reviewer is the author
-	if !feedback.CanModify(reviewerID) {
-		s.logger.Warn("Access denied to delete feedback",
+	if err := authz.DeleteFeedback(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		s.logger.Warn("Feedback deletion denied",
 			"feedback_id", id,
 			"owner_id", feedback.ReviewerID,
 			"attempted_by_id", reviewerID,
+			"error", err,
 		)
-		return fmt.Errorf("access denied: only the feedback author can delete it")
-	}
-
-	// Delete feedback and all associated attachments
-	if err := s.attachmentRepo.DeleteAll(ctx, id); err != nil {
-		s.logger.Error("Failed to delete feedback attachments", "feedback_id", id, "error", err)
-		return fmt.Errorf("failed to delete feedback attachments: %w", err)
+		return nil, err
 	}
 
-	if err := s.feedbackRepo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete feedback from repository", "feedback_id", id, "error", err)
-		return fmt.Errorf("failed to delete feedback: %w", err)
+	// Delete feedback and everything it owns
+	datasets, err := s.purgeFeedback(ctx, id, reviewerID, "deleted by author")
+	if err != nil {
+		return nil, err
 	}
+	s.prefetch.invalidate(feedback)
 
-	s.logger.Info("Feedback deleted successfully", "feedback_id", id)
-	return nil
+	s.logger.Info("Feedback deleted successfully", "feedback_id", id, "pending", models.DeletionPending(datasets))
+	return datasets, nil
 }
 
-// GetStudentFeedback retrieves feedback for a student by submission ID
-func (s *FeedbackService) GetStudentFeedback(ctx context.Context, studentID, submissionID int64) ([]*models.Feedback, error) {
-	s.logger.Info("Getting student feedback",
-		"student_id", studentID,
+// submissionDeleteBatchSize is the number of feedbacks processed per batch by DeleteFeedbacksBySubmission
+const submissionDeleteBatchSize = 20
+
+// DeleteFeedbacksBySubmission deletes every feedback of a submission (staff only).
+// Feedbacks are soft deleted, or hard deleted together with their attachments when purge is set.
+// Work is done in batches; on cancellation the results gathered so far are returned with the
+// context error, and calling again resumes with the feedbacks that are still present.
+func (s *FeedbackService) DeleteFeedbacksBySubmission(ctx context.Context, submissionID, actorID int64, purge bool) ([]*models.FeedbackDeletionResult, error) {
+	s.logger.Info("Deleting feedbacks by submission",
 		"submission_id", submissionID,
+		"actor_id", actorID,
+		"purge", purge,
 	)
 
-	if studentID <= 0 {
-		return nil, fmt.Errorf("invalid student ID")
-	}
 	if submissionID <= 0 {
 		return nil, fmt.Errorf("invalid submission ID")
 	}
+	if actorID <= 0 {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
 
-	filter := models.FeedbackFilter{
-		StudentID:    &studentID,
-		SubmissionID: &submissionID,
-		Page:         1,
-		Limit:        100, // Get all feedback for this submission
+	var results []*models.FeedbackDeletionResult
+	after := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("bulk deletion interrupted: %w", err)
+		}
+
+		ids, err := s.feedbackRepo.ListIDsBySubmission(ctx, submissionID, after, submissionDeleteBatchSize, purge)
+		if err != nil {
+			s.logger.Error("Failed to list submission feedbacks", "submission_id", submissionID, "error", err)
+			return results, fmt.Errorf("failed to list submission feedbacks: %w", err)
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				return results, fmt.Errorf("bulk deletion interrupted: %w", err)
+			}
+
+			result := &models.FeedbackDeletionResult{FeedbackID: id}
+			datasets, err := s.deleteSubmissionFeedback(ctx, id, actorID, purge)
+			result.Datasets = datasets
+			if err != nil {
+				s.logger.Error("Failed to delete submission feedback",
+					"submission_id", submissionID,
+					"feedback_id", id,
+					"error", err,
+				)
+				result.Err = err
+			} else {
+				result.Deleted = true
+			}
+			results = append(results, result)
+		}
+
+		after = ids[len(ids)-1]
 	}
 
-	feedbacks, _, err := s.feedbackRepo.ListByStudent(ctx, filter)
+	s.prefetch.invalidateAll()
+
+	s.logger.Info("Submission feedbacks deleted",
+		"submission_id", submissionID,
+		"processed", len(results),
+	)
+	return results, nil
+}
+
+// deleteSubmissionFeedback deletes a single feedback for DeleteFeedbacksBySubmission and audits it.
+// Hard deletions report what was deleted per dataset.
+func (s *FeedbackService) deleteSubmissionFeedback(ctx context.Context, id uuid.UUID, actorID int64, purge bool) ([]models.DatasetDeletion, error) {
+	locked, err := s.feedbackRepo.IsLocked(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get student feedback",
-			"student_id", studentID,
-			"submission_id", submissionID,
-			"error", err,
-		)
-		return nil, fmt.Errorf("failed to get student feedback: %w", err)
+		return nil, fmt.Errorf("failed to check feedback lock: %w", err)
+	}
+	if locked {
+		return nil, ErrFeedbackLocked
 	}
 
-	s.logger.Info("Student feedback retrieved successfully",
-		"student_id", studentID,
-		"submission_id", submissionID,
-		"count", len(feedbacks),
+	if purge {
+		return s.purgeFeedback(ctx, id, actorID, "bulk deletion by submission")
+	}
+	if err := s.feedbackRepo.SoftDelete(ctx, id, actorID); err != nil {
+		return nil, fmt.Errorf("failed to soft delete feedback: %w", err)
+	}
+
+	s.recordDeletion(ctx, id, actorID, models.AuditActionSoftDeleted, "bulk deletion by submission", nil)
+	bus.Publish(ctx, s.events, bus.FeedbackDeleted, bus.FeedbackDeletedEvent{FeedbackID: id, ActorID: actorID, Purged: false})
+	return nil, nil
+}
+
+// recordDeletion audits a completed deletion with its per-dataset counts. The deletion already
+// happened, so an audit failure is logged rather than reported.
+func (s *FeedbackService) recordDeletion(ctx context.Context, id uuid.UUID, actorID int64, action, details string, datasets []models.DatasetDeletion) {
+	if len(datasets) > 0 {
+		details += "; " + deletionSummary(datasets)
+	}
+	entry := &models.AuditEntry{
+		FeedbackID: id,
+		ActorID:    actorID,
+		Action:     action,
+		Details:    details,
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", action, "error", err)
+	}
+
+	s.logger.Info("Feedback deletion event", "feedback_id", id, "action", action, "actor_id", actorID)
+}
+
+// AuthorizeAttachmentChange returns an error unless the reviewer may change the attachments of
+// a feedback. It lets streaming handlers reject an upload before accepting any data.
+func (s *FeedbackService) AuthorizeAttachmentChange(ctx context.Context, id uuid.UUID, reviewerID int64) error {
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("feedback not found: %w", err)
+	}
+	return authz.ChangeAttachments(authz.Principal{UserID: reviewerID}, feedback)
+}
+
+// SetFeedbackLock locks or unlocks a feedback (admin only, see authz.LockFeedback). Setting the
+// current state again is a no-op; actual changes are recorded in the audit log. Courses whose
+// policy disallows locking reject new locks, while existing locks can still be lifted.
+func (s *FeedbackService) SetFeedbackLock(ctx context.Context, id uuid.UUID, locked bool, reason string, actor authz.Principal) (*models.Feedback, error) {
+	s.logger.Info("Setting feedback lock",
+		"feedback_id", id,
+		"locked", locked,
+		"actor_id", actor.UserID,
+	)
+
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("invalid feedback ID")
+	}
+	if actor.UserID <= 0 {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+	if locked && reason == "" {
+		return nil, fmt.Errorf("lock reason is required")
+	}
+	if !locked {
+		reason = ""
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for locking", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if locked {
+		policy, err := s.policies.Resolve(ctx, feedback.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authz.LockFeedback(actor, feedback, policy); err != nil {
+			return nil, err
+		}
+	} else if err := authz.UnlockFeedback(actor); err != nil {
+		return nil, err
+	}
+
+	changed, err := s.feedbackRepo.SetLock(ctx, id, locked, reason)
+	if err != nil {
+		s.logger.Error("Failed to set feedback lock", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to set feedback lock: %w", err)
+	}
+	feedback.Locked = locked
+	feedback.LockReason = reason
+	if changed {
+		s.prefetch.invalidate(feedback)
+		bus.Publish(ctx, s.events, bus.FeedbackUpdated, bus.FeedbackEvent{Feedback: feedback})
+	}
+
+	if changed {
+		action := models.AuditActionUnlocked
+		if locked {
+			action = models.AuditActionLocked
+		}
+		entry := &models.AuditEntry{
+			FeedbackID: id,
+			ActorID:    actor.UserID,
+			Action:     action,
+			Details:    reason,
+		}
+		if err := s.auditRepo.Record(ctx, entry); err != nil {
+			s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", action, "error", err)
+		}
+	}
+
+	s.logger.Info("Feedback lock set", "feedback_id", id, "locked", locked, "changed", changed)
+	return feedback, nil
+}
+
+// TransferFeedbackOwnership hands a feedback over to another reviewer (author or admin). If
+// fromReviewerID is set, the feedback must still belong to that reviewer. The cached list
+// pages of both reviewers are dropped, so their lists show the new owner right away.
+func (s *FeedbackService) TransferFeedbackOwnership(ctx context.Context, id uuid.UUID, fromReviewerID, toReviewerID int64, actor authz.Principal) (*models.Feedback, error) {
+	s.logger.Info("Transferring feedback ownership",
+		"feedback_id", id,
+		"from_reviewer_id", fromReviewerID,
+		"to_reviewer_id", toReviewerID,
+		"actor_id", actor.UserID,
 	)
-	return feedbacks, nil
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if actor.UserID <= 0 {
+		return nil, apperr.InvalidField("actor_id", "invalid actor ID")
+	}
+	if toReviewerID <= 0 {
+		return nil, apperr.InvalidField("new_reviewer_id", "invalid new reviewer ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for transfer", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if fromReviewerID > 0 && feedback.ReviewerID != fromReviewerID {
+		return nil, ErrReviewerChanged.WithMetadata("reviewer_id", strconv.FormatInt(feedback.ReviewerID, 10))
+	}
+	if err := authz.TransferFeedback(actor, feedback, toReviewerID); err != nil {
+		s.logger.Warn("Feedback transfer denied", "feedback_id", id, "attempted_by_id", actor.UserID)
+		return nil, err
+	}
+	if feedback.ReviewerID == toReviewerID {
+		return nil, apperr.InvalidField("new_reviewer_id", "the feedback already belongs to this reviewer")
+	}
+
+	previous := *feedback
+	updatedAt := time.Now().UTC()
+	changed, err := s.feedbackRepo.TransferOwnership(ctx, id, previous.ReviewerID, toReviewerID, updatedAt)
+	if err != nil {
+		var duplicate *repository.DuplicateFeedbackError
+		if errors.As(err, &duplicate) {
+			s.logger.Warn("Feedback transfer rejected: new reviewer has feedback on the submission",
+				"feedback_id", id,
+				"to_reviewer_id", toReviewerID,
+				"existing_feedback_id", duplicate.ExistingID,
+			)
+			if duplicate.ExistingID == uuid.Nil {
+				return nil, ErrTransferTargetHasFeedback.Wrap(err)
+			}
+			return nil, ErrTransferTargetHasFeedback.Wrap(err).WithMetadata("existing_feedback_id", duplicate.ExistingID.String())
+		}
+		s.logger.Error("Failed to transfer feedback", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to transfer feedback: %w", err)
+	}
+	if !changed {
+		// Transferred or deleted since it was read
+		return nil, ErrReviewerChanged
+	}
+	feedback.ReviewerID = toReviewerID
+	feedback.UpdatedAt = updatedAt
+	s.prefetch.invalidate(&previous)
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, bus.FeedbackUpdated, bus.FeedbackEvent{Feedback: feedback})
+
+	entry := &models.AuditEntry{
+		FeedbackID: id,
+		ActorID:    actor.UserID,
+		Action:     models.AuditActionTransferred,
+		Details:    fmt.Sprintf("from reviewer %d to reviewer %d", previous.ReviewerID, toReviewerID),
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", entry.Action, "error", err)
+	}
+
+	s.logger.Info("Feedback ownership transferred", "feedback_id", id, "from_reviewer_id", previous.ReviewerID, "to_reviewer_id", toReviewerID)
+	return feedback, nil
 }
 
-// ListReviewerFeedbacks lists feedbacks created by a specific reviewer
-func (s *FeedbackService) ListReviewerFeedbacks(ctx context.Context, reviewerID int64, submissionID *int64, page, limit int32) ([]*models.Feedback, int32, error) {
-	s.logger.Info("Listing reviewer feedbacks",
-		"reviewer_id", reviewerID,
+// PublishFeedback makes a draft feedback visible to its student (author only) and stamps its
+// publication time. Publishing a published feedback is a no-op that returns it unchanged.
+func (s *FeedbackService) PublishFeedback(ctx context.Context, id uuid.UUID, reviewerID int64) (*models.Feedback, error) {
+	s.logger.Info("Publishing feedback", "feedback_id", id, "reviewer_id", reviewerID)
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if reviewerID <= 0 {
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for publishing", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if err := authz.PublishFeedback(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		return nil, err
+	}
+	if feedback.IsPublished() {
+		return feedback, nil
+	}
+
+	now := time.Now()
+	if err := checkPublishDeadline(feedback, now); err != nil {
+		return nil, err
+	}
+	changed, err := s.feedbackRepo.Publish(ctx, id, now)
+	if err != nil {
+		s.logger.Error("Failed to publish feedback", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to publish feedback: %w", err)
+	}
+	if !changed {
+		// Published concurrently; return the stored publication time
+		return s.feedbackRepo.GetByID(ctx, id)
+	}
+	feedback.Status = models.FeedbackPublished
+	feedback.PublishedAt = &now
+
+	s.prefetch.invalidate(feedback)
+	bus.Publish(ctx, s.events, bus.FeedbackPublished, bus.FeedbackEvent{Feedback: feedback})
+
+	entry := &models.AuditEntry{
+		FeedbackID: id,
+		ActorID:    reviewerID,
+		Action:     models.AuditActionPublished,
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", entry.Action, "error", err)
+	}
+
+	s.logger.Info("Feedback published", "feedback_id", id)
+	return feedback, nil
+}
+
+// AcknowledgeFeedback records that the student a feedback is for has read it. Feedback the
+// student cannot see yet is not found. Acknowledging again keeps the first read_at.
+func (s *FeedbackService) AcknowledgeFeedback(ctx context.Context, id uuid.UUID, studentID int64) (*models.Feedback, error) {
+	s.logger.Info("Acknowledging feedback", "feedback_id", id, "student_id", studentID)
+
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if studentID <= 0 {
+		return nil, apperr.InvalidField("student_id", "invalid student ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for acknowledgement", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if !feedback.IsVisibleToStudent() {
+		return nil, repository.ErrFeedbackNotFound
+	}
+	if err := authz.AcknowledgeFeedback(authz.Principal{UserID: studentID}, feedback); err != nil {
+		return nil, err
+	}
+	if feedback.ReadAt != nil {
+		return feedback, nil
+	}
+
+	now := time.Now()
+	changed, err := s.feedbackRepo.Acknowledge(ctx, id, now)
+	if err != nil {
+		s.logger.Error("Failed to acknowledge feedback", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to acknowledge feedback: %w", err)
+	}
+	if !changed {
+		// Acknowledged concurrently; return the stored read time
+		return s.feedbackRepo.GetByID(ctx, id)
+	}
+	feedback.ReadAt = &now
+	s.prefetch.invalidate(feedback)
+
+	s.logger.Info("Feedback acknowledged", "feedback_id", id)
+	return feedback, nil
+}
+
+// GetStudentFeedback retrieves the most recent feedback a student can see for a submission. A
+// submission may have feedback from several reviewers; GetStudentSubmissionFeedbacks returns
+// all of them.
+func (s *FeedbackService) GetStudentFeedback(ctx context.Context, studentID, submissionID int64) (*models.Feedback, error) {
+	feedbacks, _, err := s.GetStudentSubmissionFeedbacks(ctx, studentID, submissionID, 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedbacks) == 0 {
+		return nil, repository.ErrFeedbackNotFound.WithMessage("no feedback found for this submission")
+	}
+	return feedbacks[0], nil
+}
+
+// GetStudentSubmissionFeedbacks lists the feedbacks of every reviewer for a student's
+// submission, newest first, with the pagination of ListStudentFeedbacks. Archived feedbacks
+// are included, since the submission is asked for by ID.
+func (s *FeedbackService) GetStudentSubmissionFeedbacks(ctx context.Context, studentID, submissionID int64, page, limit int32) ([]*models.Feedback, int64, error) {
+	if studentID <= 0 {
+		return nil, 0, apperr.InvalidField("student_id", "invalid student ID")
+	}
+	if submissionID <= 0 {
+		return nil, 0, apperr.InvalidField("submission_id", "invalid submission ID")
+	}
+	feedbacks, totalCount, _, _, err := s.ListStudentFeedbacks(ctx, models.StudentFeedbackListOptions{
+		StudentID:       studentID,
+		SubmissionID:    &submissionID,
+		IncludeArchived: true,
+		Page:            page,
+		Limit:           limit,
+	})
+	return feedbacks, totalCount, err
+}
+
+// ListSubmissionFeedbacks lists the feedbacks of every reviewer for a submission, newest
+// first, drafts included unless a status is given and archived feedbacks only with
+// includeArchived. It is meant for staff and internal services; callers check access.
+func (s *FeedbackService) ListSubmissionFeedbacks(ctx context.Context, submissionID int64, feedbackStatus *string, includeArchived bool, page, limit int32) ([]*models.Feedback, int64, error) {
+	s.logger.Info("Listing submission feedbacks",
 		"submission_id", submissionID,
+		"status", feedbackStatus,
+		"include_archived", includeArchived,
 		"page", page,
 		"limit", limit,
 	)
 
-	if reviewerID <= 0 {
-		return nil, 0, fmt.Errorf("invalid reviewer ID")
+	if submissionID <= 0 {
+		return nil, 0, apperr.InvalidField("submission_id", "invalid submission ID")
+	}
+	if feedbackStatus != nil && *feedbackStatus != models.FeedbackDraft && *feedbackStatus != models.FeedbackPublished {
+		return nil, 0, apperr.InvalidField("status", "invalid feedback status")
 	}
 	if page < 1 {
 		page = 1
@@ -223,16 +847,65 @@ func (s *FeedbackService) ListReviewerFeedbacks(ctx context.Context, reviewerID
 	}
 
 	filter := models.FeedbackFilter{
-		ReviewerID:   &reviewerID,
-		SubmissionID: submissionID,
-		Page:         int(page),
-		Limit:        int(limit),
+		SubmissionID:    &submissionID,
+		Status:          feedbackStatus,
+		IncludeArchived: includeArchived,
+		Page:            int(page),
+		Limit:           int(limit),
+	}
+	feedbacks, totalCount, err := s.feedbackRepo.ListBySubmission(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list submission feedbacks", "submission_id", submissionID, "error", err)
+		return nil, 0, fmt.Errorf("failed to list submission feedbacks: %w", err)
+	}
+	return feedbacks, totalCount, nil
+}
+
+// ListReviewerFeedbacks lists feedbacks created by a specific reviewer. It also returns the
+// cursor of the following page, or nil on the last page; filter.After continues from a cursor.
+func (s *FeedbackService) ListReviewerFeedbacks(ctx context.Context, filter models.FeedbackFilter, prefetchNext bool) ([]*models.Feedback, int64, *models.FeedbackCursor, error) {
+	s.logger.Info("Listing reviewer feedbacks",
+		"reviewer_id", filter.ReviewerID,
+		"submission_id", filter.SubmissionID,
+		"has_attachments", filter.HasAttachments,
+		"min_attachments", filter.MinAttachments,
+		"status", filter.Status,
+		"include_archived", filter.IncludeArchived,
+		"created_after", filter.CreatedAfter,
+		"created_before", filter.CreatedBefore,
+		"sort_by", filter.Sort.Field(),
+		"sort_ascending", filter.Sort.Ascending,
+		"page", filter.Page,
+		"after", filter.After != nil,
+		"limit", filter.Limit,
+	)
+
+	if filter.ReviewerID == nil || *filter.ReviewerID <= 0 {
+		return nil, 0, nil, fmt.Errorf("invalid reviewer ID")
+	}
+	reviewerID := *filter.ReviewerID
+	if filter.MinAttachments != nil && *filter.MinAttachments < 0 {
+		return nil, 0, nil, fmt.Errorf("invalid minimum attachment count")
+	}
+	if filter.Status != nil && *filter.Status != models.FeedbackDraft && *filter.Status != models.FeedbackPublished {
+		return nil, 0, nil, apperr.InvalidField("status", "invalid feedback status")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
 	}
 
-	feedbacks, totalCount, err := s.feedbackRepo.ListByUser(ctx, filter)
+	filter, err := s.resolveAttachmentFilters(ctx, filter)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	feedbacks, totalCount, next, err := s.listFeedbackPage(ctx, listReviewer, filter, prefetchNext)
 	if err != nil {
 		s.logger.Error("Failed to list reviewer feedbacks", "reviewer_id", reviewerID, "error", err)
-		return nil, 0, fmt.Errorf("failed to list reviewer feedbacks: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed to list reviewer feedbacks: %w", err)
 	}
 
 	s.logger.Info("Reviewer feedbacks listed successfully",
@@ -240,21 +913,81 @@ func (s *FeedbackService) ListReviewerFeedbacks(ctx context.Context, reviewerID
 		"count", len(feedbacks),
 		"total_count", totalCount,
 	)
-	return feedbacks, int32(totalCount), nil
+	return feedbacks, totalCount, next, nil
 }
 
-// ListStudentFeedbacks lists feedbacks for a specific student
-func (s *FeedbackService) ListStudentFeedbacks(ctx context.Context, studentID int64, submissionID *int64, page, limit int32) ([]*models.Feedback, int32, error) {
+// ListFeedbacks lists feedbacks across reviewers and students for admin audits, narrowed by
+// the filter's optional reviewer, student and other predicates
+func (s *FeedbackService) ListFeedbacks(ctx context.Context, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	s.logger.Info("Listing feedbacks",
+		"reviewer_id", filter.ReviewerID,
+		"student_id", filter.StudentID,
+		"submission_id", filter.SubmissionID,
+		"has_attachments", filter.HasAttachments,
+		"min_attachments", filter.MinAttachments,
+		"status", filter.Status,
+		"include_archived", filter.IncludeArchived,
+		"created_after", filter.CreatedAfter,
+		"created_before", filter.CreatedBefore,
+		"page", filter.Page,
+		"limit", filter.Limit,
+	)
+
+	if filter.MinAttachments != nil && *filter.MinAttachments < 0 {
+		return nil, 0, apperr.InvalidField("min_attachment_count", "must not be negative")
+	}
+	if filter.Status != nil && *filter.Status != models.FeedbackDraft && *filter.Status != models.FeedbackPublished {
+		return nil, 0, apperr.InvalidField("status", "invalid feedback status")
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	filter, err := s.resolveAttachmentFilters(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	feedbacks, totalCount, err := s.feedbackRepo.List(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to list feedbacks", "error", err)
+		return nil, 0, fmt.Errorf("failed to list feedbacks: %w", err)
+	}
+	return feedbacks, totalCount, nil
+}
+
+// ListStudentFeedbacks lists the feedbacks a student can see: drafts and feedbacks awaiting
+// approval are left out, and archived feedbacks unless opts.IncludeArchived is set. With
+// ResubmissionOnly, only feedbacks asking for a resubmission are listed. CreatedAfter and
+// CreatedBefore optionally restrict the list, and its count, to feedbacks created in
+// [CreatedAfter, CreatedBefore); Sort orders it.
+// A non-nil After continues the list from a cursor instead of Page; the cursor of the following
+// page is returned, or nil on the last page. The last activity across every matching feedback,
+// not only the page, is returned too, nil when nothing matches.
+func (s *FeedbackService) ListStudentFeedbacks(ctx context.Context, opts models.StudentFeedbackListOptions) ([]*models.Feedback, int64, *models.FeedbackCursor, *time.Time, error) {
 	s.logger.Info("Listing student feedbacks",
-		"student_id", studentID,
-		"submission_id", submissionID,
-		"page", page,
-		"limit", limit,
+		"student_id", opts.StudentID,
+		"submission_id", opts.SubmissionID,
+		"unread_only", opts.UnreadOnly,
+		"resubmission_only", opts.ResubmissionOnly,
+		"include_archived", opts.IncludeArchived,
+		"created_after", opts.CreatedAfter,
+		"created_before", opts.CreatedBefore,
+		"sort_by", opts.Sort.Field(),
+		"sort_ascending", opts.Sort.Ascending,
+		"page", opts.Page,
+		"after", opts.After != nil,
+		"limit", opts.Limit,
 	)
 
+	studentID := opts.StudentID
 	if studentID <= 0 {
-		return nil, 0, fmt.Errorf("invalid student ID")
+		return nil, 0, nil, nil, fmt.Errorf("invalid student ID")
 	}
+	page, limit := opts.Page, opts.Limit
 	if page < 1 {
 		page = 1
 	}
@@ -262,33 +995,53 @@ func (s *FeedbackService) ListStudentFeedbacks(ctx context.Context, studentID in
 		limit = 20
 	}
 
+	published := models.FeedbackPublished
 	filter := models.FeedbackFilter{
-		StudentID:    &studentID,
-		SubmissionID: submissionID,
-		Page:         int(page),
-		Limit:        int(limit),
+		StudentID:        &studentID,
+		SubmissionID:     opts.SubmissionID,
+		Status:           &published,
+		ApprovedOnly:     true,
+		UnreadOnly:       opts.UnreadOnly,
+		ResubmissionOnly: opts.ResubmissionOnly,
+		IncludeArchived:  opts.IncludeArchived,
+		CreatedAfter:     opts.CreatedAfter,
+		CreatedBefore:    opts.CreatedBefore,
+		Sort:             opts.Sort,
+		After:            opts.After,
+		Page:             int(page),
+		Limit:            int(limit),
 	}
 
-	feedbacks, totalCount, err := s.feedbackRepo.ListByStudent(ctx, filter)
+	feedbacks, totalCount, next, err := s.listFeedbackPage(ctx, listStudent, filter, opts.PrefetchNext)
 	if err != nil {
 		s.logger.Error("Failed to list student feedbacks", "student_id", studentID, "error", err)
-		return nil, 0, fmt.Errorf("failed to list student feedbacks: %w", err)
+		return nil, 0, nil, nil, fmt.Errorf("failed to list student feedbacks: %w", err)
+	}
+
+	var lastActivity *time.Time
+	if totalCount > 0 {
+		lastActivity, err = s.feedbackRepo.StudentLastActivity(ctx, filter)
+		if err != nil {
+			s.logger.Error("Failed to get student feedback activity", "student_id", studentID, "error", err)
+			return nil, 0, nil, nil, fmt.Errorf("failed to list student feedbacks: %w", err)
+		}
 	}
 
 	s.logger.Info("Student feedbacks listed successfully",
 		"student_id", studentID,
 		"count", len(feedbacks),
 		"total_count", totalCount,
+		"last_activity_at", lastActivity,
 	)
-	return feedbacks, int32(totalCount), nil
+	return feedbacks, totalCount, next, lastActivity, nil
 }
 
-// UploadAttachment uploads an attachment file for a feedback
-func (s *FeedbackService) UploadAttachment(ctx context.Context, feedbackID uuid.UUID, filename, contentType string, data io.Reader, size int64) error {
+// UploadAttachment uploads an attachment file for a feedback (author only)
+func (s *FeedbackService) UploadAttachment(ctx context.Context, feedbackID uuid.UUID, reviewerID int64, filename, contentType string, data io.Reader, size int64) error {
 	s.logger.Info("Uploading attachment",
 		"feedback_id", feedbackID,
-		"filename", filename,
-		"content_type", contentType,
+		"filename", validation.LogValue(filename),
+		"content_type", validation.LogValue(contentType),
 		"size", size,
 	)
 
@@ -298,7 +1051,7 @@ func (s *FeedbackService) UploadAttachment(ctx context.Context, feedbackID uuid.
 	if filename == "" {
 		return fmt.Errorf("filename is required")
 	}
-	if size <= 0 {
+	if size < 0 {
 		return fmt.Errorf("invalid file size")
 	}
 
@@ -309,35 +1062,105 @@ func (s *FeedbackService) UploadAttachment(ctx context.Context, feedbackID uuid.
 	default:
 	}
 
-	// Verify feedback exists
-	_, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	// Verify feedback exists and the reviewer may change its attachments
+	feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
 	if err != nil {
 		s.logger.Error("Feedback not found for attachment upload", "feedback_id", feedbackID, "error", err)
 		return fmt.Errorf("feedback not found: %w", err)
 	}
+	if err := authz.ChangeAttachments(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		return err
+	}
 
-	// Upload attachment with context monitoring
+	// Upload attachment with context monitoring, hashing the content and reporting progress on
+	// the way
+	progress := s.trackUploadProgress(ctx, feedback, filename, data, size)
+	data, checksum := contentHash(progress)
 	if err := s.attachmentRepo.Upload(ctx, feedbackID, filename, contentType, data, size); err != nil {
 		s.logger.Error("Failed to upload attachment",
 			"feedback_id", feedbackID,
-			"filename", filename,
+			"filename", validation.LogValue(filename),
 			"error", err,
 		)
+		progress.finish(err)
 		return fmt.Errorf("failed to upload attachment: %w", err)
 	}
 
+	// Mirror metadata into PostgreSQL so attachment counts are queryable and downloads are
+	// verified. A row kept from a previous upload would fail every download of this one with
+	// its old checksum, so it is removed when the new one cannot be recorded; a download without
+	// a row is served unverified.
+	info := &models.AttachmentInfo{
+		Filename:    filename,
+		Size:        size,
+		ContentType: contentType,
+		UploadedAt:  time.Now().UTC(),
+		SHA256:      checksum(),
+	}
+	if err := s.assetRepo.Upsert(ctx, feedbackID, info); err != nil {
+		s.logger.Error("Failed to record attachment metadata",
+			"feedback_id", feedbackID,
+			"filename", validation.LogValue(filename),
+			"error", err,
+		)
+		if err := s.assetRepo.Delete(ctx, feedbackID, filename); err != nil {
+			s.logger.Error("Failed to remove stale attachment metadata",
+				"feedback_id", feedbackID,
+				"filename", validation.LogValue(filename),
+				"error", err,
+			)
+			progress.finish(err)
+			return ErrChecksumNotRecorded.Wrap(err)
+		}
+	}
+
+	// A fresh upload resolves a re-upload request raised by the consistency check
+	if feedback.NeedsReupload {
+		if err := s.feedbackRepo.SetNeedsReupload(ctx, feedbackID, false); err != nil {
+			s.logger.Error("Failed to clear re-upload flag",
+				"feedback_id", feedbackID,
+				"error", err,
+			)
+		}
+	}
+
+	progress.finish(nil)
+	bus.Publish(ctx, s.events, bus.AttachmentUploaded, bus.AttachmentEvent{FeedbackID: feedbackID, Attachment: *info})
+
 	s.logger.Info("Attachment uploaded successfully",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 	return nil
 }
 
+// CheckAttachmentCount returns ErrAttachmentLimitExceeded if a feedback already has as many
+// attachments as its course policy allows
+func (s *FeedbackService) CheckAttachmentCount(ctx context.Context, feedbackID uuid.UUID) error {
+	feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	if err != nil {
+		return fmt.Errorf("feedback not found: %w", err)
+	}
+	policy, err := s.policies.Resolve(ctx, feedback.CourseID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.attachmentRepo.List(ctx, feedbackID)
+	if err != nil {
+		return fmt.Errorf("failed to list existing attachments: %w", err)
+	}
+	if len(existing) >= policy.MaxAttachmentsPerFeedback {
+		return fmt.Errorf("%w: maximum %d attachments allowed per feedback", ErrAttachmentLimitExceeded, policy.MaxAttachmentsPerFeedback)
+	}
+	return nil
+}
+
 // DownloadAttachment downloads an attachment file for a feedback
 func (s *FeedbackService) DownloadAttachment(ctx context.Context, feedbackID uuid.UUID, filename string) (io.ReadCloser, *models.AttachmentInfo, error) {
 	s.logger.Info("Downloading attachment",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 
 	if feedbackID == uuid.Nil {
@@ -354,27 +1177,41 @@ func (s *FeedbackService) DownloadAttachment(ctx context.Context, feedbackID uui
 		return nil, nil, fmt.Errorf("feedback not found: %w", err)
 	}
 
+	// The content is verified against the checksum recorded at upload. Without the lookup a
+	// corrupted object could not be told apart, so a failed one fails the download.
+	checksum, err := s.assetRepo.GetChecksum(ctx, feedbackID, filename)
+	if err != nil {
+		s.logger.Error("Failed to get attachment checksum",
+			"feedback_id", feedbackID,
+			"filename", validation.LogValue(filename),
+			"error", err,
+		)
+		return nil, nil, ErrChecksumUnavailable.Wrap(err)
+	}
+
 	// Download attachment
 	reader, attachmentInfo, err := s.attachmentRepo.Download(ctx, feedbackID, filename)
 	if err != nil {
 		s.logger.Error("Failed to download attachment",
 			"feedback_id", feedbackID,
-			"filename", filename,
+			"filename", validation.LogValue(filename),
 			"error", err,
 		)
 		return nil, nil, fmt.Errorf("failed to download attachment: %w", err)
 	}
+	attachmentInfo.SHA256 = checksum
+	reader = s.verifyDownload(ctx, feedbackID, filename, checksum, reader)
 
 	s.logger.Info("Attachment download started",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 		"size", attachmentInfo.Size,
 		"content_type", attachmentInfo.ContentType,
 	)
 	return reader, attachmentInfo, nil
 }
 
-// ListAttachments lists all attachments for a feedback
+// ListAttachments lists all attachments for a feedback in the reviewer's order
 func (s *FeedbackService) ListAttachments(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentInfo, error) {
 	s.logger.Info("Listing attachments", "feedback_id", feedbackID)
 
@@ -390,11 +1227,12 @@ func (s *FeedbackService) ListAttachments(ctx context.Context, feedbackID uuid.U
 	}
 
 	// List attachments
-	attachments, err := s.attachmentRepo.List(ctx, feedbackID)
+	attachments, err := s.lister.list(ctx, feedbackID)
 	if err != nil {
 		s.logger.Error("Failed to list attachments", "feedback_id", feedbackID, "error", err)
 		return nil, fmt.Errorf("failed to list attachments: %w", err)
 	}
+	s.orderAttachmentInfos(ctx, feedbackID, attachments)
 
 	s.logger.Info("Attachments listed successfully",
 		"feedback_id", feedbackID,
@@ -404,10 +1242,10 @@ func (s *FeedbackService) ListAttachments(ctx context.Context, feedbackID uuid.U
 }
 
 // DeleteAttachment deletes a specific attachment
-func (s *FeedbackService) DeleteAttachment(ctx context.Context, feedbackID uuid.UUID, filename string) error {
+func (s *FeedbackService) DeleteAttachment(ctx context.Context, feedbackID uuid.UUID, reviewerID int64, filename string) error {
 	s.logger.Info("Deleting attachment",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 
 	if feedbackID == uuid.Nil {
@@ -417,26 +1255,45 @@ func (s *FeedbackService) DeleteAttachment(ctx context.Context, feedbackID uuid.
 		return fmt.Errorf("filename is required")
 	}
 
-	// Verify feedback exists
-	_, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	// Verify feedback exists and the reviewer may change its attachments
+	feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
 	if err != nil {
 		s.logger.Error("Feedback not found for attachment deletion", "feedback_id", feedbackID, "error", err)
 		return fmt.Errorf("feedback not found: %w", err)
 	}
+	if err := authz.ChangeAttachments(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		return err
+	}
+
+	// Delete the metadata first: a row left behind by a failed deletion would keep the file in
+	// the attachment order and counts, while an object left behind is removed by retrying
+	if err := s.assetRepo.Delete(ctx, feedbackID, filename); err != nil {
+		s.logger.Error("Failed to delete attachment metadata",
+			"feedback_id", feedbackID,
+			"filename", validation.LogValue(filename),
+			"error", err,
+		)
+		return fmt.Errorf("failed to delete attachment metadata: %w", err)
+	}
 
 	// Delete attachment
 	if err := s.attachmentRepo.Delete(ctx, feedbackID, filename); err != nil {
 		s.logger.Error("Failed to delete attachment",
 			"feedback_id", feedbackID,
-			"filename", filename,
+			"filename", validation.LogValue(filename),
 			"error", err,
 		)
 		return fmt.Errorf("failed to delete attachment: %w", err)
 	}
 
+	bus.Publish(ctx, s.events, bus.AttachmentDeleted, bus.AttachmentEvent{
+		FeedbackID: feedbackID,
+		Attachment: models.AttachmentInfo{Filename: filename},
+	})
+
 	s.logger.Info("Attachment deleted successfully",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 	return nil
 }
@@ -459,11 +1316,63 @@ func (s *FeedbackService) GetFeedbackByID(ctx context.Context, id uuid.UUID) (*m
 	return feedback, nil
 }
 
+// maxBatchGetFeedbacks is the most feedbacks BatchGetFeedbacks returns per call
+const maxBatchGetFeedbacks = 100
+
+// BatchGetFeedbacks gets several feedbacks with one query. Found feedbacks are returned in the
+// order of ids, each once; IDs of feedbacks that do not exist or were deleted are returned as
+// missing, in the same order.
+func (s *FeedbackService) BatchGetFeedbacks(ctx context.Context, ids []uuid.UUID) ([]*models.Feedback, []uuid.UUID, error) {
+	s.logger.Info("Batch getting feedbacks", "count", len(ids))
+
+	if len(ids) == 0 {
+		return nil, nil, apperr.InvalidField("ids", "at least one feedback ID is required")
+	}
+	if len(ids) > maxBatchGetFeedbacks {
+		return nil, nil, apperr.InvalidField("ids", fmt.Sprintf("at most %d feedback IDs are allowed", maxBatchGetFeedbacks))
+	}
+
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if id == uuid.Nil {
+			return nil, nil, apperr.InvalidField("ids", "invalid feedback ID")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	found, err := s.feedbackRepo.GetByIDs(ctx, unique)
+	if err != nil {
+		s.logger.Error("Failed to batch get feedbacks", "count", len(unique), "error", err)
+		return nil, nil, fmt.Errorf("failed to get feedbacks: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.Feedback, len(found))
+	for _, feedback := range found {
+		byID[feedback.ID] = feedback
+	}
+
+	feedbacks := make([]*models.Feedback, 0, len(found))
+	var missing []uuid.UUID
+	for _, id := range unique {
+		if feedback, ok := byID[id]; ok {
+			feedbacks = append(feedbacks, feedback)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	s.logger.Info("Feedbacks batch retrieved successfully", "found", len(feedbacks), "missing", len(missing))
+	return feedbacks, missing, nil
+}
+
 // GetAttachmentLocation gets location information for a specific attachment
 func (s *FeedbackService) GetAttachmentLocation(ctx context.Context, feedbackID uuid.UUID, filename string) (*models.AttachmentLocationInfo, error) {
 	s.logger.Info("Getting attachment location",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 
 	if feedbackID == uuid.Nil {
@@ -485,7 +1394,7 @@ func (s *FeedbackService) GetAttachmentLocation(ctx context.Context, feedbackID
 	if err != nil {
 		s.logger.Error("Failed to get attachment location",
 			"feedback_id", feedbackID,
-			"filename", filename,
+			"filename", validation.LogValue(filename),
 			"error", err,
 		)
 		return nil, fmt.Errorf("failed to get attachment location: %w", err)
@@ -493,12 +1402,13 @@ func (s *FeedbackService) GetAttachmentLocation(ctx context.Context, feedbackID
 
 	s.logger.Info("Attachment location retrieved successfully",
 		"feedback_id", feedbackID,
-		"filename", filename,
+		"filename", validation.LogValue(filename),
 	)
 	return locationInfo, nil
 }
 
-// ListAttachmentLocations lists location information for all attachments of a feedback
+// ListAttachmentLocations lists location information for all attachments of a feedback in the
+// reviewer's order
 func (s *FeedbackService) ListAttachmentLocations(ctx context.Context, feedbackID uuid.UUID) ([]*models.AttachmentLocationInfo, error) {
 	s.logger.Info("Listing attachment locations", "feedback_id", feedbackID)
 
@@ -519,6 +1429,7 @@ func (s *FeedbackService) ListAttachmentLocations(ctx context.Context, feedbackI
 		s.logger.Error("Failed to list attachment locations", "feedback_id", feedbackID, "error", err)
 		return nil, fmt.Errorf("failed to list attachment locations: %w", err)
 	}
+	s.orderAttachmentLocations(ctx, feedbackID, locationInfos)
 
 	s.logger.Info("Attachment locations listed successfully",
 		"feedback_id", feedbackID,
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// ErrSubscriberLagged ends an event stream whose client does not keep up with its events. The
+// client resumes after the last event it received; the resume_since metadata holds the time to
+// resubscribe with when it cannot: when the stream started receiving live events, since events
+// are stamped after their change is made.
+var ErrSubscriberLagged = apperr.Unavailable("SUBSCRIBER_LAGGED", "event stream fell behind; resume after the last event received, or resubscribe with since, to catch up")
+
+// ErrEventStreamsClosed ends the event streams when the server shuts down, with the same
+// resume_since metadata as ErrSubscriberLagged
+var ErrEventStreamsClosed = apperr.Unavailable("EVENT_STREAMS_CLOSED", "server is shutting down; resume after the last event received, or resubscribe with since, to catch up")
+
+// ErrReplayWindowExceeded is returned for a resume position whose following events are no
+// longer kept, because they are older than the replay window or the service restarted since.
+// The client reloads what it shows and watches again without resuming.
+var ErrReplayWindowExceeded = apperr.FailedPrecondition("REPLAY_WINDOW_EXCEEDED", "resume_after is older than the retained event history; reload and watch again without it")
+
+// WatchOptions selects what an event stream sends before its live events
+type WatchOptions struct {
+	Since       *time.Time             // Replay the changes made since then from the database
+	ResumeAfter *models.StreamPosition // Replay the events that followed this position, exactly once
+}
+
+// validate rejects options that cannot be combined
+func (o WatchOptions) validate() error {
+	if o.Since != nil && o.ResumeAfter != nil {
+		return apperr.InvalidField("resume_after", "resume_after cannot be combined with since")
+	}
+	return nil
+}
+
+// watchError converts an error of eventHub.watch into a service error
+func watchError(err error) error {
+	switch {
+	case errors.Is(err, errPositionExpired):
+		return ErrReplayWindowExceeded
+	case errors.Is(err, errPositionUnknown):
+		return apperr.InvalidField("resume_after", "resume_after was not issued by this service")
+	}
+	return err
+}
+
+// replayBatchSize is the number of feedbacks read per query when replaying changes
+const replayBatchSize = 100
+
+// studentEventHub fans feedback events out to the streams of the students they address
+type studentEventHub = eventHub[int64, models.FeedbackStreamEvent]
+
+// newStudentEventHub creates a hub with the configured per-stream buffer and replay window
+func newStudentEventHub(cfg config.EventStreamConfig) *studentEventHub {
+	return newEventHub[int64, models.FeedbackStreamEvent](cfg.BufferSize, cfg.ReplayWindow)
+}
+
+// CloseEventStreams ends the streams of WatchStudentFeedback with ErrEventStreamsClosed, so
+// shutdown does not wait for clients to cancel them
+func (s *FeedbackService) CloseEventStreams() {
+	s.studentEvents.close()
+}
+
+// PushFeedbackEvent forwards a feedback event to the streams of its student. Changes to
+// feedback the student cannot see, such as drafts, are not forwarded.
+func (s *FeedbackService) PushFeedbackEvent(kind string, feedback *models.Feedback) {
+	if !feedback.IsVisibleToStudent() {
+		return
+	}
+	s.studentEvents.publish(feedback.StudentID, models.FeedbackStreamEvent{
+		Kind:       kind,
+		Feedback:   feedback,
+		OccurredAt: time.Now(),
+	})
+}
+
+// PushUploadProgress forwards the progress of an attachment upload to the streams of the
+// feedback's student while the student can see the feedback. Progress is not journaled, so a
+// stream that resumes later does not receive it.
+func (s *FeedbackService) PushUploadProgress(feedback *models.Feedback, progress models.UploadProgress) {
+	if !feedback.IsVisibleToStudent() {
+		return
+	}
+	s.studentEvents.publishEphemeral(feedback.StudentID, models.FeedbackStreamEvent{
+		Kind:       models.FeedbackEventUpload,
+		Feedback:   feedback,
+		OccurredAt: time.Now(),
+		Upload:     &progress,
+	})
+}
+
+// WatchStudentFeedback sends the events of feedback addressed to a student until ctx is done,
+// which ends the watch without an error. With opts.Since, changes made from then on are read
+// back from the database and sent first, oldest first, one event per feedback with its current
+// state; events that happen meanwhile may be sent twice. With opts.ResumeAfter, the events that
+// followed that position are sent first, each once and in order, and the live events go on
+// from there without a gap or a repeat. A stream that falls more than the configured buffer
+// behind ends with ErrSubscriberLagged.
+func (s *FeedbackService) WatchStudentFeedback(ctx context.Context, studentID int64, opts WatchOptions, send func(event models.FeedbackStreamEvent) error) error {
+	s.logger.Info("Watching student feedback", "student_id", studentID, "since", opts.Since, "resume_after", opts.ResumeAfter)
+
+	if studentID <= 0 {
+		return apperr.InvalidField("student_id", "invalid student ID")
+	}
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	// Watch before replaying, so nothing that happens during the replay is missed
+	resumeSince := time.Now()
+	w, backlog, start, err := s.studentEvents.watch(studentID, opts.ResumeAfter)
+	if err != nil {
+		s.logger.Info("Student feedback stream cannot resume", "student_id", studentID, "error", err)
+		return watchError(err)
+	}
+	defer s.studentEvents.unwatch(studentID, w)
+
+	if opts.Since != nil {
+		if err := s.replayStudentFeedback(ctx, studentID, *opts.Since, start, send); err != nil {
+			return err
+		}
+	}
+	for _, sequenced := range backlog {
+		event := sequenced.event
+		event.Replayed = true
+		event.Position = sequenced.position
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case sequenced := <-w.events:
+			event := sequenced.event
+			event.Position = sequenced.position
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-w.lagged:
+			s.logger.Warn("Student feedback stream fell behind", "student_id", studentID)
+			return ErrSubscriberLagged.WithMetadata("resume_since", resumeSince.UTC().Format(time.RFC3339Nano))
+		case <-s.studentEvents.closed:
+			return ErrEventStreamsClosed.WithMetadata("resume_since", resumeSince.UTC().Format(time.RFC3339Nano))
+		case <-ctx.Done():
+			s.logger.Info("Student feedback watch ended", "student_id", studentID)
+			return nil
+		}
+	}
+}
+
+// replayStudentFeedback sends the feedbacks of a student that changed since a time, in order
+// of their last update. They carry the position the stream started at, as the live events
+// that follow may repeat them.
+func (s *FeedbackService) replayStudentFeedback(ctx context.Context, studentID int64, since time.Time, start models.StreamPosition, send func(event models.FeedbackStreamEvent) error) error {
+	published := models.FeedbackPublished
+	filter := models.FeedbackFilter{
+		StudentID:       &studentID,
+		Status:          &published,
+		ApprovedOnly:    true,
+		IncludeArchived: true,
+		ChangedSince:    &since,
+		Sort:            models.FeedbackSort{By: models.FeedbackSortUpdatedAt, Ascending: true},
+		Page:            1,
+		Limit:           replayBatchSize,
+	}
+	for {
+		feedbacks, _, err := s.feedbackRepo.ListByStudent(ctx, filter)
+		if err != nil {
+			s.logger.Error("Failed to replay student feedback", "student_id", studentID, "error", err)
+			return fmt.Errorf("failed to replay feedback events: %w", err)
+		}
+		for _, feedback := range feedbacks {
+			event := models.FeedbackStreamEvent{
+				Kind:       models.FeedbackEventUpdated,
+				Feedback:   feedback,
+				OccurredAt: feedback.UpdatedAt,
+				Replayed:   true,
+				Position:   start,
+			}
+			if feedback.PublishedAt != nil && !feedback.PublishedAt.Before(since) {
+				event.Kind = models.FeedbackEventPublished
+				event.OccurredAt = *feedback.PublishedAt
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		if len(feedbacks) < replayBatchSize {
+			return nil
+		}
+		filter.After = models.CursorOf(feedbacks[len(feedbacks)-1], filter.Sort)
+	}
+}
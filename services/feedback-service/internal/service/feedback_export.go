@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// exportBatchSize is the number of feedbacks read per query during an export
+const exportBatchSize = 500
+
+// ExportReviewerFeedbacks passes every feedback of a reviewer to emit, oldest first, in batches
+// read with keyset pagination, so memory use does not depend on the number of feedbacks. A
+// non-empty courseID limits the export to that course. It stops at the first error from emit
+// or when ctx is done, and returns the number of feedbacks emitted.
+func (s *FeedbackService) ExportReviewerFeedbacks(ctx context.Context, reviewerID int64, courseID string, emit func([]*models.Feedback) error) (int64, error) {
+	s.logger.Info("Exporting reviewer feedbacks", "reviewer_id", reviewerID, "course_id", courseID)
+
+	if reviewerID <= 0 {
+		return 0, fmt.Errorf("invalid reviewer ID")
+	}
+
+	filter := models.FeedbackFilter{
+		ReviewerID: &reviewerID,
+		Sort:       models.FeedbackSort{By: models.FeedbackSortCreatedAt, Ascending: true},
+		Page:       1,
+		Limit:      exportBatchSize,
+	}
+	if courseID != "" {
+		filter.CourseID = &courseID
+	}
+
+	var exported int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return exported, err
+		}
+		feedbacks, _, err := s.feedbackRepo.ListByUser(ctx, filter)
+		if err != nil {
+			s.logger.Error("Failed to export reviewer feedbacks", "reviewer_id", reviewerID, "exported", exported, "error", err)
+			return exported, fmt.Errorf("failed to list reviewer feedbacks: %w", err)
+		}
+		if len(feedbacks) == 0 {
+			break
+		}
+		if err := emit(feedbacks); err != nil {
+			return exported, err
+		}
+		exported += int64(len(feedbacks))
+		if len(feedbacks) < exportBatchSize {
+			break
+		}
+		filter.After = models.CursorOf(feedbacks[len(feedbacks)-1], filter.Sort)
+	}
+
+	s.logger.Info("Reviewer feedbacks exported", "reviewer_id", reviewerID, "course_id", courseID, "count", exported)
+	return exported, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ListFeedbackRevisions lists the edit history of a feedback, newest first, for its reviewer
+// or its student. A feedback the student cannot see yet is reported as not found to them.
+func (s *FeedbackService) ListFeedbackRevisions(ctx context.Context, id uuid.UUID, userID int64, page, limit int32) ([]*models.FeedbackRevision, int32, error) {
+	s.logger.Info("Listing feedback revisions", "feedback_id", id, "user_id", userID, "page", page, "limit", limit)
+
+	if id == uuid.Nil {
+		return nil, 0, apperr.InvalidField("feedback_id", "invalid feedback ID")
+	}
+	if userID <= 0 {
+		return nil, 0, apperr.InvalidField("user_id", "invalid user ID")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for revisions", "feedback_id", id, "error", err)
+		return nil, 0, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if feedback.StudentID == userID && feedback.ReviewerID != userID && !feedback.IsVisibleToStudent() {
+		return nil, 0, repository.ErrFeedbackNotFound
+	}
+	if err := authz.ViewFeedbackRevisions(authz.Principal{UserID: userID}, feedback); err != nil {
+		s.logger.Warn("Feedback revisions denied", "feedback_id", id, "attempted_by_id", userID, "error", err)
+		return nil, 0, err
+	}
+
+	revisions, totalCount, err := s.feedbackRepo.ListRevisions(ctx, id, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to list feedback revisions", "feedback_id", id, "error", err)
+		return nil, 0, fmt.Errorf("failed to list feedback revisions: %w", err)
+	}
+	return revisions, totalCount, nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+)
+
+// validateTitle checks a feedback title; CreateFeedback and UpdateFeedback both go through it so
+// their rules cannot drift apart
+func validateTitle(title string, limits config.FeedbackConfig) error {
+	if strings.TrimSpace(title) == "" {
+		return apperr.InvalidField("title", "title is required")
+	}
+	return CheckTextLimits(limits, &title, nil)
+}
+
+// CheckTextLimits checks the length of a title and the size of Markdown content against the
+// configured limits; nil fields are not checked. Services apply it with the rest of their
+// validation, and the gRPC layer applies it first so oversized requests are rejected before any
+// storage is touched. The title is counted in characters, the content in bytes.
+func CheckTextLimits(limits config.FeedbackConfig, title, content *string) error {
+	if title != nil && utf8.RuneCountInString(*title) > limits.MaxTitleLength {
+		return apperr.InvalidField("title", fmt.Sprintf("title must be at most %d characters", limits.MaxTitleLength))
+	}
+	if content != nil && len(*content) > limits.MaxContentLength {
+		return apperr.InvalidField("content", fmt.Sprintf("content must be at most %d bytes", limits.MaxContentLength))
+	}
+	return nil
+}
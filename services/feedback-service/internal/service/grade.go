@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// validateGrade checks that a grade is within its scale; a nil grade is valid
+func validateGrade(grade *models.Grade) error {
+	if grade == nil {
+		return nil
+	}
+	if math.IsNaN(grade.MaxPoints) || math.IsInf(grade.MaxPoints, 0) || grade.MaxPoints <= 0 {
+		return apperr.InvalidField("grade.max_points", "max_points must be a positive number")
+	}
+	if math.IsNaN(grade.Points) || grade.Points < 0 {
+		return apperr.InvalidField("grade.points", "points must not be negative")
+	}
+	if grade.Points > grade.MaxPoints {
+		return apperr.InvalidField("grade.points", "points must not exceed max_points")
+	}
+	return nil
+}
+
+// Limits of a feedback's rubric (feedback_rubric_items.name is VARCHAR(100))
+const (
+	maxRubricItems         = 50
+	maxRubricNameLength    = 100
+	maxRubricCommentLength = 2000
+)
+
+// validateRubric checks that every rubric item has a name unique within the rubric and a score
+// within its scale; an empty rubric is valid
+func validateRubric(items []models.RubricItem) error {
+	if len(items) > maxRubricItems {
+		return apperr.InvalidField("rubric_items", fmt.Sprintf("a rubric has at most %d items", maxRubricItems))
+	}
+	names := make(map[string]bool, len(items))
+	for i, item := range items {
+		field := fmt.Sprintf("rubric_items[%d]", i)
+		if strings.TrimSpace(item.Name) == "" {
+			return apperr.InvalidField(field+".name", "name is required")
+		}
+		if utf8.RuneCountInString(item.Name) > maxRubricNameLength {
+			return apperr.InvalidField(field+".name", fmt.Sprintf("name must be at most %d characters", maxRubricNameLength))
+		}
+		if names[item.Name] {
+			return apperr.InvalidField(field+".name", fmt.Sprintf("duplicate rubric criterion %q", item.Name))
+		}
+		names[item.Name] = true
+		if math.IsNaN(item.MaxScore) || math.IsInf(item.MaxScore, 0) || item.MaxScore <= 0 {
+			return apperr.InvalidField(field+".max_score", "max_score must be a positive number")
+		}
+		if math.IsNaN(item.Score) || item.Score < 0 {
+			return apperr.InvalidField(field+".score", "score must not be negative")
+		}
+		if item.Score > item.MaxScore {
+			return apperr.InvalidField(field+".score", "score must not exceed max_score")
+		}
+		if utf8.RuneCountInString(item.Comment) > maxRubricCommentLength {
+			return apperr.InvalidField(field+".comment", fmt.Sprintf("comment must be at most %d characters", maxRubricCommentLength))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxIdempotencyKeyLength is the longest idempotency key accepted, in bytes
+const maxIdempotencyKeyLength = 128
+
+// ErrIdempotencyKeyConflict is returned when an idempotency key is reused for a request with a
+// different payload. The feedback created with the key is named in the feedback_id metadata.
+var ErrIdempotencyKeyConflict = apperr.AlreadyExists("IDEMPOTENCY_KEY_CONFLICT", "the idempotency key was already used for a different request")
+
+// validateIdempotencyKey checks that a key is short printable ASCII; an empty key is valid
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLength {
+		return apperr.InvalidField("idempotency_key", fmt.Sprintf("idempotency key must be at most %d characters", maxIdempotencyKeyLength))
+	}
+	for _, r := range key {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || r == ' ' {
+			return apperr.InvalidField("idempotency_key", "idempotency key must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// createFingerprint hashes the payload of a CreateFeedback request, so a retry can be told
+// from a different request reusing its idempotency key
+func createFingerprint(req models.FeedbackCreateRequest) string {
+	payload, _ := json.Marshal(struct {
+		StudentID    int64                      `json:"student_id"`
+		SubmissionID int64                      `json:"submission_id"`
+		Title        string                     `json:"title"`
+		Content      string                     `json:"content"`
+		Snapshot     *models.SubmissionSnapshot `json:"snapshot"`
+		CourseID     string                     `json:"course_id"`
+		Grade        *models.Grade              `json:"grade"`
+		Rubric       []models.RubricItem        `json:"rubric_items,omitempty"` // Left out when empty, so keys from before rubrics keep their hash
+		Resubmission bool                       `json:"requires_resubmission,omitempty"`
+		Deadline     *time.Time                 `json:"resubmission_deadline,omitempty"`
+		Publish      bool                       `json:"publish"`
+	}{req.StudentID, req.SubmissionID, req.Title, req.Content, req.Snapshot, req.CourseID, req.Grade, req.RubricItems, req.RequiresResubmission, req.ResubmissionDeadline, req.Publish})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// idempotencyRecord returns the feedback a reviewer created with an idempotency key, or nil if
+// the key is unused. An expired key that retention has not cleared yet is released here.
+func (s *FeedbackService) idempotencyRecord(ctx context.Context, reviewerID int64, key string) (*models.IdempotencyRecord, error) {
+	record, err := s.feedbackRepo.GetByIdempotencyKey(ctx, reviewerID, key)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	if s.idempotencyTTL > 0 && time.Since(record.CreatedAt) >= s.idempotencyTTL {
+		if err := s.feedbackRepo.ClearIdempotencyKey(ctx, record.FeedbackID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return record, nil
+}
+
+// replayCreate returns the feedback created by an earlier request with the same idempotency
+// key and payload, or nil if the key is unused. A different payload is a conflict.
+func (s *FeedbackService) replayCreate(ctx context.Context, reviewerID int64, key, fingerprint string) (*models.Feedback, error) {
+	record, err := s.idempotencyRecord(ctx, reviewerID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if record == nil {
+		return nil, nil
+	}
+	if record.Fingerprint != fingerprint {
+		s.logger.Warn("Idempotency key reused with a different payload",
+			"reviewer_id", reviewerID,
+			"feedback_id", record.FeedbackID,
+		)
+		conflict := ErrIdempotencyKeyConflict.WithMetadata("feedback_id", record.FeedbackID.String())
+		if s.idempotencyTTL > 0 {
+			conflict = conflict.WithMetadata("expires_at", record.CreatedAt.Add(s.idempotencyTTL).UTC().Format(time.RFC3339))
+		}
+		return nil, conflict
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, record.FeedbackID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Replayed feedback creation", "reviewer_id", reviewerID, "feedback_id", feedback.ID)
+	return feedback, nil
+}
+
+// IdempotentFeedbackID returns the ID of the feedback a reviewer created with an idempotency
+// key, or uuid.Nil if the key is unused or expired
+func (s *FeedbackService) IdempotentFeedbackID(ctx context.Context, reviewerID int64, key string) (uuid.UUID, error) {
+	if key == "" {
+		return uuid.Nil, nil
+	}
+	record, err := s.idempotencyRecord(ctx, reviewerID, key)
+	if err != nil || record == nil {
+		return uuid.Nil, err
+	}
+	return record.FeedbackID, nil
+}
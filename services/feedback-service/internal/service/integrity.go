@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand/v2"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/envelope"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
+	"github.com/google/uuid"
+)
+
+// ErrAttachmentCorrupted is returned at the end of a download whose content does not match the
+// checksum recorded at upload. The bytes already read must be discarded.
+var ErrAttachmentCorrupted = apperr.DataLoss("ATTACHMENT_CHECKSUM_MISMATCH", "attachment is corrupted: checksum mismatch")
+
+// ErrChecksumUnavailable is returned when the checksum recorded for an attachment cannot be
+// read, so its download could not be verified
+var ErrChecksumUnavailable = apperr.Unavailable("ATTACHMENT_CHECKSUM_UNAVAILABLE", "could not read the attachment's checksum; try again later")
+
+// ErrChecksumNotRecorded is returned when an attachment was stored but neither its checksum nor
+// the removal of a previous upload's checksum could be recorded, so its downloads would fail
+var ErrChecksumNotRecorded = apperr.Unavailable("ATTACHMENT_CHECKSUM_NOT_RECORDED", "the attachment was stored but its checksum could not be recorded; upload it again")
+
+// verifyBatchSize is the number of recorded checksums read per batch while verifying attachments
+const verifyBatchSize = 200
+
+// Sources of a detected corruption, recorded in the audit log
+const (
+	corruptionSourceDownload = "download"
+	corruptionSourceVerify   = "verify"
+)
+
+// contentHash hashes data as it is read and returns the reader to consume instead of data and
+// a function returning the hex SHA-256 of what was read
+func contentHash(data io.Reader) (io.Reader, func() string) {
+	h := sha256.New()
+	return io.TeeReader(data, h), func() string {
+		return hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// verifyingReader hashes an attachment while it is streamed and checks the digest at EOF.
+// A trailing read that returns data together with io.EOF is split, so callers that stop at
+// io.EOF still receive every byte before the verdict.
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected string
+	mismatch func(actual string) // Called once when the digest does not match
+	checked  bool
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.checked {
+		return 0, io.EOF
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	if err != io.EOF {
+		return n, err
+	}
+	if n > 0 {
+		return n, nil
+	}
+
+	r.checked = true
+	if actual := hex.EncodeToString(r.hash.Sum(nil)); actual != r.expected {
+		r.mismatch(actual)
+		return 0, ErrAttachmentCorrupted
+	}
+	return 0, io.EOF
+}
+
+// verifyDownload wraps a download of an attachment with a recorded checksum so a mismatch is
+// reported at EOF. Without a checksum the reader is returned as is.
+func (s *FeedbackService) verifyDownload(ctx context.Context, feedbackID uuid.UUID, filename, expected string, reader io.ReadCloser) io.ReadCloser {
+	if expected == "" {
+		return reader
+	}
+	return &verifyingReader{
+		ReadCloser: reader,
+		hash:       sha256.New(),
+		expected:   expected,
+		mismatch: func(actual string) {
+			// The stream may already be canceled by the time the last chunk is read
+			s.recordCorruption(context.WithoutCancel(ctx), feedbackID, filename, expected, actual, corruptionSourceDownload)
+		},
+	}
+}
+
+// recordCorruption counts an attachment whose content does not match its recorded checksum and
+// writes it to the audit log
+func (s *FeedbackService) recordCorruption(ctx context.Context, feedbackID uuid.UUID, filename, expected, actual, source string) {
+	total := s.corruptAttachments.Add(1)
+	s.logger.Error("Attachment checksum mismatch",
+		"feedback_id", feedbackID,
+		"filename", validation.LogValue(filename),
+		"expected_sha256", expected,
+		"actual_sha256", actual,
+		"source", source,
+		"corrupt_attachments", total,
+	)
+
+	entry := &models.AuditEntry{
+		FeedbackID: feedbackID,
+		Action:     models.AuditActionAttachmentCorrupted,
+		Details:    fmt.Sprintf("filename=%s expected_sha256=%s actual_sha256=%s source=%s", filename, expected, actual, source),
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", feedbackID, "action", entry.Action, "error", err)
+	}
+}
+
+// CorruptAttachments returns how many checksum mismatches were detected since startup
+func (s *FeedbackService) CorruptAttachments() int64 {
+	return s.corruptAttachments.Load()
+}
+
+// VerifyAttachments re-hashes stored attachments that have a recorded checksum and compares
+// them with it. Mismatches and attachments that cannot be read are passed to emit as they are
+// found; mismatches are also counted and audited like those found on download. Attachments are
+// read one at a time, so a run only adds the load of one download.
+func (s *FeedbackService) VerifyAttachments(ctx context.Context, opts models.AttachmentVerifyOptions, emit func(*models.AttachmentMismatch) error) (*models.AttachmentVerifySummary, error) {
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1")
+	}
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 1
+	}
+
+	s.logger.Info("Starting attachment verification", "feedback_id", opts.FeedbackID, "sample_rate", opts.SampleRate)
+
+	summary := &models.AttachmentVerifySummary{}
+	var after *models.AttachmentChecksum
+	for {
+		if err := ctx.Err(); err != nil {
+			return summary, fmt.Errorf("attachment verification interrupted: %w", err)
+		}
+
+		checksums, err := s.assetRepo.ListChecksums(ctx, opts.FeedbackID, after, verifyBatchSize)
+		if err != nil {
+			return summary, err
+		}
+		if len(checksums) == 0 {
+			break
+		}
+		after = checksums[len(checksums)-1]
+
+		for _, checksum := range checksums {
+			if opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+				summary.Skipped++
+				continue
+			}
+
+			mismatch, err := s.verifyAttachment(ctx, checksum, summary)
+			if err != nil {
+				return summary, err
+			}
+			if mismatch == nil {
+				continue
+			}
+			if err := emit(mismatch); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	s.logger.Info("Attachment verification completed",
+		"checked", summary.Checked,
+		"skipped", summary.Skipped,
+		"mismatches", summary.Mismatches,
+		"failed", summary.Failed,
+	)
+	return summary, nil
+}
+
+// verifyAttachment re-hashes one stored attachment and returns the mismatch, if any. Only a
+// canceled context is returned as an error; read failures are reported as mismatches with the
+// error set.
+func (s *FeedbackService) verifyAttachment(ctx context.Context, checksum *models.AttachmentChecksum, summary *models.AttachmentVerifySummary) (*models.AttachmentMismatch, error) {
+	mismatch := &models.AttachmentMismatch{
+		FeedbackID: checksum.FeedbackID,
+		Filename:   checksum.Filename,
+		Expected:   checksum.SHA256,
+	}
+
+	reader, _, err := s.attachmentRepo.Download(ctx, checksum.FeedbackID, checksum.Filename)
+	if err == nil {
+		h := sha256.New()
+		var n int64
+		n, err = io.Copy(h, reader)
+		reader.Close()
+		summary.Bytes += n
+		mismatch.Actual = hex.EncodeToString(h.Sum(nil))
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("attachment verification interrupted: %w", ctxErr)
+	}
+	summary.Checked++
+
+	switch {
+	case err != nil && errors.Is(err, envelope.ErrCorrupted):
+		// Encrypted content that fails authentication is corrupted just the same
+		mismatch.Actual = ""
+		mismatch.Error = err.Error()
+		summary.Mismatches++
+		s.recordCorruption(ctx, checksum.FeedbackID, checksum.Filename, checksum.SHA256, "", corruptionSourceVerify)
+	case err != nil:
+		mismatch.Actual = ""
+		mismatch.Error = err.Error()
+		summary.Failed++
+	case mismatch.Actual != checksum.SHA256:
+		summary.Mismatches++
+		s.recordCorruption(ctx, checksum.FeedbackID, checksum.Filename, checksum.SHA256, mismatch.Actual, corruptionSourceVerify)
+	default:
+		return nil, nil
+	}
+	return mismatch, nil
+}
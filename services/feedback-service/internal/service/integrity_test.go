@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/iotest"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// readAllChunks reads r in small chunks until io.EOF or an error, as the download handler does
+func readAllChunks(r io.Reader) ([]byte, error) {
+	var out []byte
+	buf := make([]byte, 7)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
+
+func TestVerifyingReader(t *testing.T) {
+	stored := []byte("the attachment content, long enough to span several reads")
+	altered := bytes.Clone(stored)
+	altered[10] ^= 0x01
+
+	tests := []struct {
+		name         string
+		content      []byte
+		wrap         func(io.Reader) io.Reader
+		wantMismatch bool
+	}{
+		{name: "intact", content: stored},
+		{name: "intact, data with EOF", content: stored, wrap: iotest.DataErrReader},
+		{name: "intact, one byte per read", content: stored, wrap: iotest.OneByteReader},
+		{name: "altered byte", content: altered, wantMismatch: true},
+		{name: "altered byte, data with EOF", content: altered, wrap: iotest.DataErrReader, wantMismatch: true},
+		{name: "truncated", content: stored[:len(stored)-1], wantMismatch: true},
+		{name: "extended", content: append(bytes.Clone(stored), '!'), wantMismatch: true},
+		{name: "empty", content: nil, wantMismatch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source io.Reader = bytes.NewReader(tt.content)
+			if tt.wrap != nil {
+				source = tt.wrap(source)
+			}
+			var mismatches []string
+			reader := &verifyingReader{
+				ReadCloser: io.NopCloser(source),
+				hash:       sha256.New(),
+				expected:   sha256Hex(stored),
+				mismatch:   func(actual string) { mismatches = append(mismatches, actual) },
+			}
+
+			got, err := readAllChunks(reader)
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("read %q, want every byte %q before the verdict", got, tt.content)
+			}
+			if !tt.wantMismatch {
+				if err != nil || len(mismatches) != 0 {
+					t.Fatalf("error = %v, mismatches = %v, want none", err, mismatches)
+				}
+				return
+			}
+			if !errors.Is(err, ErrAttachmentCorrupted) {
+				t.Fatalf("error = %v, want ErrAttachmentCorrupted", err)
+			}
+			if len(mismatches) != 1 || mismatches[0] != sha256Hex(tt.content) {
+				t.Errorf("mismatches = %v, want [%s]", mismatches, sha256Hex(tt.content))
+			}
+
+			// The verdict is given once; later reads only report EOF
+			if n, err := reader.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+				t.Errorf("Read() after verdict = %d, %v, want 0, EOF", n, err)
+			}
+		})
+	}
+}
+
+// fakeDownloadFeedbacks finds every feedback
+type fakeDownloadFeedbacks struct {
+	repository.FeedbackRepository
+}
+
+func (fakeDownloadFeedbacks) GetByID(ctx context.Context, id uuid.UUID) (*models.Feedback, error) {
+	return &models.Feedback{ID: id}, nil
+}
+
+// fakeDownloadStorage serves the same object for every attachment
+type fakeDownloadStorage struct {
+	repository.AttachmentRepository
+	content []byte
+	opened  int
+}
+
+func (s *fakeDownloadStorage) Download(ctx context.Context, feedbackID uuid.UUID, filename string) (io.ReadCloser, *models.AttachmentInfo, error) {
+	s.opened++
+	info := &models.AttachmentInfo{Filename: filename, Size: int64(len(s.content))}
+	return io.NopCloser(bytes.NewReader(s.content)), info, nil
+}
+
+// fakeDownloadAssets records one checksum for every attachment, or fails to read it
+type fakeDownloadAssets struct {
+	repository.AssetRepository
+	checksum string
+	err      error
+}
+
+func (a *fakeDownloadAssets) GetChecksum(ctx context.Context, feedbackID uuid.UUID, filename string) (string, error) {
+	return a.checksum, a.err
+}
+
+// fakeDownloadAudit keeps the recorded entries
+type fakeDownloadAudit struct {
+	repository.AuditRepository
+	entries []*models.AuditEntry
+}
+
+func (a *fakeDownloadAudit) Record(ctx context.Context, entry *models.AuditEntry) error {
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+func TestDownloadAttachmentVerification(t *testing.T) {
+	stored := []byte("attachment content as uploaded")
+	altered := bytes.Clone(stored)
+	altered[0] ^= 0xff
+
+	tests := []struct {
+		name        string
+		content     []byte
+		checksum    string
+		checksumErr error
+		wantOpenErr error
+		wantReadErr error
+		wantAudit   bool
+	}{
+		{name: "intact", content: stored, checksum: sha256Hex(stored)},
+		{name: "altered bytes", content: altered, checksum: sha256Hex(stored), wantReadErr: ErrAttachmentCorrupted, wantAudit: true},
+		{name: "no recorded checksum", content: altered},
+		{name: "checksum lookup fails", content: stored, checksumErr: errors.New("connection refused"), wantOpenErr: ErrChecksumUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &fakeDownloadStorage{content: tt.content}
+			audit := &fakeDownloadAudit{}
+			s := &FeedbackService{
+				feedbackRepo:   fakeDownloadFeedbacks{},
+				attachmentRepo: storage,
+				assetRepo:      &fakeDownloadAssets{checksum: tt.checksum, err: tt.checksumErr},
+				auditRepo:      audit,
+				logger:         slog.New(slog.DiscardHandler),
+			}
+
+			reader, info, err := s.DownloadAttachment(context.Background(), uuid.New(), "report.pdf")
+			if tt.wantOpenErr != nil {
+				if !errors.Is(err, tt.wantOpenErr) {
+					t.Fatalf("DownloadAttachment() error = %v, want %v", err, tt.wantOpenErr)
+				}
+				var appErr *apperr.Error
+				if !errors.As(err, &appErr) || appErr.Kind != apperr.KindUnavailable {
+					t.Errorf("DownloadAttachment() error = %v, want an Unavailable error", err)
+				}
+				if storage.opened != 0 {
+					t.Errorf("object opened %d times, want no unverified stream", storage.opened)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadAttachment() error = %v", err)
+			}
+			defer reader.Close()
+			if info.SHA256 != tt.checksum {
+				t.Errorf("SHA256 = %q, want %q", info.SHA256, tt.checksum)
+			}
+
+			got, err := readAllChunks(reader)
+			if !errors.Is(err, tt.wantReadErr) {
+				t.Fatalf("read error = %v, want %v", err, tt.wantReadErr)
+			}
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("read %q, want %q", got, tt.content)
+			}
+			if tt.wantAudit != (len(audit.entries) == 1) {
+				t.Fatalf("audit entries = %d, want corruption recorded: %v", len(audit.entries), tt.wantAudit)
+			}
+			if tt.wantAudit && audit.entries[0].Action != models.AuditActionAttachmentCorrupted {
+				t.Errorf("audit action = %q, want %q", audit.entries[0].Action, models.AuditActionAttachmentCorrupted)
+			}
+			if tt.wantAudit && s.CorruptAttachments() != 1 {
+				t.Errorf("CorruptAttachments() = %d, want 1", s.CorruptAttachments())
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+)
+
+// PermissionService tells clients which actions a principal may perform on a resource. It
+// loads the resource and evaluates the same authz predicates the mutating methods enforce;
+// quotas and limits that depend on the request, such as attachment counts, are not evaluated.
+type PermissionService struct {
+	feedbackRepo repository.FeedbackRepository
+	commentRepo  repository.CommentRepository
+	policies     *CoursePolicyService
+	acceptHexIDs bool
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+// NewPermissionService creates a new permission service
+func NewPermissionService(feedbackRepo repository.FeedbackRepository, commentRepo repository.CommentRepository, policies *CoursePolicyService, comments config.CommentConfig, logger *slog.Logger) *PermissionService {
+	return &PermissionService{
+		feedbackRepo: feedbackRepo,
+		commentRepo:  commentRepo,
+		policies:     policies,
+		acceptHexIDs: comments.AcceptHexIDs,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// GetPermissions returns the outcome of every action on the named feedback, attachment or
+// comment: nil if the principal may perform it, otherwise the error the action would fail with
+func (s *PermissionService) GetPermissions(ctx context.Context, name string, principal authz.Principal) (map[string]error, error) {
+	if principal.UserID <= 0 {
+		return nil, apperr.InvalidField("principal_id", "invalid principal ID")
+	}
+
+	switch {
+	case strings.HasPrefix(name, "feedbacks/") && strings.Contains(name, "/attachments/"):
+		feedbackID, _, err := resourcename.ParseAttachment(name)
+		if err != nil {
+			return nil, apperr.InvalidField("resource_name", err.Error())
+		}
+		feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feedback: %w", err)
+		}
+		return map[string]error{
+			authz.ActionDelete: authz.ChangeAttachments(principal, feedback),
+		}, nil
+
+	case strings.HasPrefix(name, "feedbacks/"):
+		feedbackID, err := resourcename.ParseFeedbackID(name)
+		if err != nil {
+			return nil, apperr.InvalidField("resource_name", err.Error())
+		}
+		feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feedback: %w", err)
+		}
+		policy, err := s.policies.Resolve(ctx, feedback.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]error{
+			authz.ActionUpdate:             authz.UpdateFeedback(principal, feedback),
+			authz.ActionDelete:             authz.DeleteFeedback(principal, feedback),
+			authz.ActionPublish:            authz.PublishFeedback(principal, feedback),
+			authz.ActionRequestApproval:    authz.RequestFeedbackApproval(principal, feedback),
+			authz.ActionApprove:            authz.ReviewFeedbackApproval(principal, feedback),
+			authz.ActionUploadAttachment:   authz.ChangeAttachments(principal, feedback),
+			authz.ActionReorderAttachments: authz.ChangeAttachments(principal, feedback),
+			authz.ActionLock:               authz.LockFeedback(principal, feedback, policy),
+			authz.ActionUnlock:             authz.UnlockFeedback(principal),
+			authz.ActionTransfer:           authz.TransferFeedback(principal, feedback, 0),
+			authz.ActionAcknowledge:        authz.AcknowledgeFeedback(principal, feedback),
+			authz.ActionViewRevisions:      authz.ViewFeedbackRevisions(principal, feedback),
+			authz.ActionReact:              authz.ReactToFeedback(principal, feedback),
+			authz.ActionArchive:            authz.ArchiveFeedback(principal, feedback),
+		}, nil
+
+	case strings.HasPrefix(name, "contents/"):
+		commentID, err := resourcename.ParseCommentID(name, s.acceptHexIDs)
+		if err != nil {
+			return nil, apperr.InvalidField("resource_name", err.Error())
+		}
+		comment, err := s.commentRepo.GetByID(ctx, commentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comment: %w", err)
+		}
+		policy, err := s.policies.Resolve(ctx, comment.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]error{
+			authz.ActionUpdate:          authz.UpdateComment(principal, comment, policy, s.now()),
+			authz.ActionDelete:          authz.DeleteComment(principal, comment),
+			authz.ActionViewEditHistory: authz.ViewCommentEditHistory(principal, comment),
+		}, nil
+
+	default:
+		return nil, apperr.InvalidField("resource_name", "resource_name must name a feedback, attachment or comment")
+	}
+}
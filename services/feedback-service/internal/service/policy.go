@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+)
+
+var (
+	// ErrInvalidPolicy is returned when a course policy has unknown keys or out-of-range values
+	ErrInvalidPolicy = errors.New("invalid course policy")
+
+	// ErrCoursePolicyNotFound is returned when a course has no policy
+	ErrCoursePolicyNotFound = errors.New("course policy not found")
+)
+
+const (
+	// maxCourseIDLength matches the course_id columns
+	maxCourseIDLength = 128
+
+	// Upper bounds for course policy overrides
+	maxPolicyAttachments     = 100
+	maxPolicyAttachmentBytes = 1024 * 1024 * 1024
+	maxPolicyCommentDepth    = 100
+)
+
+// CoursePolicyService stores per-course policies and resolves the limits that apply to a course
+type CoursePolicyService struct {
+	repo     repository.CoursePolicyRepository
+	defaults models.EffectivePolicy
+	logger   *slog.Logger
+}
+
+// NewCoursePolicyService creates a policy service whose defaults come from the global configuration
+func NewCoursePolicyService(repo repository.CoursePolicyRepository, comments config.CommentConfig, logger *slog.Logger) *CoursePolicyService {
+	return &CoursePolicyService{
+		repo: repo,
+		defaults: models.EffectivePolicy{
+			MaxAttachmentsPerFeedback:     config.MaxAttachmentsPerFeedback,
+			MaxAttachmentBytesPerFeedback: config.MaxAttachmentBytesPerFeedback,
+			MaxCommentDepth:               comments.MaxDepth,
+			CommentEditWindow:             comments.EditWindow,
+			AllowFeedbackLock:             true,
+		},
+		logger: logger,
+	}
+}
+
+// Defaults returns the global limits
+func (s *CoursePolicyService) Defaults() models.EffectivePolicy {
+	return s.defaults
+}
+
+// mergePolicy applies a course's overrides on top of the global limits
+func mergePolicy(defaults models.EffectivePolicy, courseID string, overrides models.PolicyOverrides) models.EffectivePolicy {
+	policy := defaults
+	policy.CourseID = courseID
+	if overrides.MaxAttachmentsPerFeedback != nil {
+		policy.MaxAttachmentsPerFeedback = *overrides.MaxAttachmentsPerFeedback
+	}
+	if overrides.MaxAttachmentBytesPerFeedback != nil {
+		policy.MaxAttachmentBytesPerFeedback = *overrides.MaxAttachmentBytesPerFeedback
+	}
+	if overrides.MaxCommentDepth != nil {
+		policy.MaxCommentDepth = *overrides.MaxCommentDepth
+	}
+	if overrides.CommentEditWindow != nil {
+		policy.CommentEditWindow = time.Duration(*overrides.CommentEditWindow)
+	}
+	if overrides.AllowFeedbackLock != nil {
+		policy.AllowFeedbackLock = *overrides.AllowFeedbackLock
+	}
+	return policy
+}
+
+// Effective returns the limits a stored course policy results in
+func (s *CoursePolicyService) Effective(policy *models.CoursePolicy) models.EffectivePolicy {
+	return mergePolicy(s.defaults, policy.CourseID, policy.Overrides)
+}
+
+// Resolve returns the limits for a course: the global configuration overridden by the course
+// policy, if the course has one. An empty course ID resolves to the global limits.
+func (s *CoursePolicyService) Resolve(ctx context.Context, courseID string) (models.EffectivePolicy, error) {
+	if courseID == "" {
+		return s.defaults, nil
+	}
+
+	policy, err := s.repo.Get(ctx, courseID)
+	if err != nil {
+		return models.EffectivePolicy{}, fmt.Errorf("failed to resolve policy of course %s: %w", courseID, err)
+	}
+	if policy == nil {
+		effective := s.defaults
+		effective.CourseID = courseID
+		return effective, nil
+	}
+	return mergePolicy(s.defaults, courseID, policy.Overrides), nil
+}
+
+// ValidateCourseID checks a course ID supplied on a request; empty means no course
+func ValidateCourseID(courseID string) error {
+	if len(courseID) > maxCourseIDLength {
+		return fmt.Errorf("course ID must be at most %d bytes", maxCourseIDLength)
+	}
+	if strings.TrimSpace(courseID) != courseID {
+		return fmt.Errorf("course ID must not have leading or trailing whitespace")
+	}
+	return nil
+}
+
+// ParsePolicyOverrides decodes a JSON policy document, rejecting unknown keys and values out of range
+func ParsePolicyOverrides(document string) (models.PolicyOverrides, error) {
+	var overrides models.PolicyOverrides
+
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&overrides); err != nil {
+		return overrides, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if decoder.More() {
+		return overrides, fmt.Errorf("%w: unexpected data after the policy object", ErrInvalidPolicy)
+	}
+
+	if v := overrides.MaxAttachmentsPerFeedback; v != nil && (*v < 1 || *v > maxPolicyAttachments) {
+		return overrides, fmt.Errorf("%w: max_attachments_per_feedback must be between 1 and %d", ErrInvalidPolicy, maxPolicyAttachments)
+	}
+	if v := overrides.MaxAttachmentBytesPerFeedback; v != nil && (*v < 1 || *v > maxPolicyAttachmentBytes) {
+		return overrides, fmt.Errorf("%w: max_attachment_bytes_per_feedback must be between 1 and %d", ErrInvalidPolicy, maxPolicyAttachmentBytes)
+	}
+	if v := overrides.MaxCommentDepth; v != nil && (*v < 0 || *v > maxPolicyCommentDepth) {
+		return overrides, fmt.Errorf("%w: max_comment_depth must be between 0 and %d", ErrInvalidPolicy, maxPolicyCommentDepth)
+	}
+	if v := overrides.CommentEditWindow; v != nil && *v < 0 {
+		return overrides, fmt.Errorf("%w: comment_edit_window must not be negative", ErrInvalidPolicy)
+	}
+	return overrides, nil
+}
+
+// SetCoursePolicy creates or replaces the policy of a course (admin only). The document is a
+// JSON object whose keys are the limits to override; omitted keys keep the global value.
+func (s *CoursePolicyService) SetCoursePolicy(ctx context.Context, courseID, document string, adminID int64) (*models.CoursePolicy, error) {
+	s.logger.Info("Setting course policy", "course_id", courseID, "admin_id", adminID)
+
+	if courseID == "" {
+		return nil, fmt.Errorf("%w: course ID is required", ErrInvalidPolicy)
+	}
+	if err := ValidateCourseID(courseID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if adminID <= 0 {
+		return nil, fmt.Errorf("invalid admin ID")
+	}
+
+	overrides, err := ParsePolicyOverrides(document)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &models.CoursePolicy{
+		CourseID:  courseID,
+		Overrides: overrides,
+		UpdatedBy: adminID,
+	}
+	if err := s.repo.Upsert(ctx, policy); err != nil {
+		s.logger.Error("Failed to save course policy", "course_id", courseID, "error", err)
+		return nil, fmt.Errorf("failed to save course policy: %w", err)
+	}
+
+	s.logger.Info("Course policy saved", "course_id", courseID)
+	return policy, nil
+}
+
+// GetCoursePolicy returns the stored policy of a course
+func (s *CoursePolicyService) GetCoursePolicy(ctx context.Context, courseID string) (*models.CoursePolicy, error) {
+	if courseID == "" {
+		return nil, fmt.Errorf("%w: course ID is required", ErrInvalidPolicy)
+	}
+
+	policy, err := s.repo.Get(ctx, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course policy: %w", err)
+	}
+	if policy == nil {
+		return nil, ErrCoursePolicyNotFound
+	}
+	return policy, nil
+}
+
+// ListCoursePolicies returns every stored course policy
+func (s *CoursePolicyService) ListCoursePolicies(ctx context.Context) ([]*models.CoursePolicy, error) {
+	policies, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list course policies: %w", err)
+	}
+	return policies, nil
+}
+
+// DeleteCoursePolicy removes the policy of a course so it falls back to the global limits
+func (s *CoursePolicyService) DeleteCoursePolicy(ctx context.Context, courseID string, adminID int64) error {
+	s.logger.Info("Deleting course policy", "course_id", courseID, "admin_id", adminID)
+
+	if courseID == "" {
+		return fmt.Errorf("%w: course ID is required", ErrInvalidPolicy)
+	}
+
+	deleted, err := s.repo.Delete(ctx, courseID)
+	if err != nil {
+		s.logger.Error("Failed to delete course policy", "course_id", courseID, "error", err)
+		return fmt.Errorf("failed to delete course policy: %w", err)
+	}
+	if !deleted {
+		return ErrCoursePolicyNotFound
+	}
+
+	s.logger.Info("Course policy deleted", "course_id", courseID)
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository/repotest"
+)
+
+var testPolicyDefaults = models.EffectivePolicy{
+	MaxAttachmentsPerFeedback:     5,
+	MaxAttachmentBytesPerFeedback: 1000,
+	MaxCommentDepth:               10,
+	CommentEditWindow:             time.Hour,
+	AllowFeedbackLock:             true,
+}
+
+func TestMergePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     func(*models.EffectivePolicy) // Applied to the defaults
+	}{
+		{name: "no overrides", document: `{}`, want: func(*models.EffectivePolicy) {}},
+		{name: "comment depth", document: `{"max_comment_depth": 3}`, want: func(p *models.EffectivePolicy) { p.MaxCommentDepth = 3 }},
+		{name: "comment depth of zero", document: `{"max_comment_depth": 0}`, want: func(p *models.EffectivePolicy) { p.MaxCommentDepth = 0 }},
+		{name: "attachment count raised", document: `{"max_attachments_per_feedback": 20}`, want: func(p *models.EffectivePolicy) { p.MaxAttachmentsPerFeedback = 20 }},
+		{name: "attachment count lowered", document: `{"max_attachments_per_feedback": 1}`, want: func(p *models.EffectivePolicy) { p.MaxAttachmentsPerFeedback = 1 }},
+		{name: "attachment bytes", document: `{"max_attachment_bytes_per_feedback": 64}`, want: func(p *models.EffectivePolicy) { p.MaxAttachmentBytesPerFeedback = 64 }},
+		{name: "edit window", document: `{"comment_edit_window": "15m"}`, want: func(p *models.EffectivePolicy) { p.CommentEditWindow = 15 * time.Minute }},
+		{name: "edit window of zero", document: `{"comment_edit_window": "0s"}`, want: func(p *models.EffectivePolicy) { p.CommentEditWindow = 0 }},
+		{name: "feedback lock disallowed", document: `{"allow_feedback_lock": false}`, want: func(p *models.EffectivePolicy) { p.AllowFeedbackLock = false }},
+		{
+			name:     "several limits",
+			document: `{"max_attachments_per_feedback": 2, "max_attachment_bytes_per_feedback": 10, "max_comment_depth": 1}`,
+			want: func(p *models.EffectivePolicy) {
+				p.MaxAttachmentsPerFeedback, p.MaxAttachmentBytesPerFeedback, p.MaxCommentDepth = 2, 10, 1
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := ParsePolicyOverrides(tt.document)
+			if err != nil {
+				t.Fatalf("ParsePolicyOverrides(%s) error = %v", tt.document, err)
+			}
+			want := testPolicyDefaults
+			want.CourseID = "course-1"
+			tt.want(&want)
+			if got := mergePolicy(testPolicyDefaults, "course-1", overrides); got != want {
+				t.Errorf("mergePolicy() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestResolvePolicy(t *testing.T) {
+	ctx := context.Background()
+	store := repotest.New()
+	s := NewCoursePolicyService(store.Policies(), config.CommentConfig{MaxDepth: 4, EditWindow: time.Hour}, slog.New(slog.DiscardHandler))
+	if _, err := s.SetCoursePolicy(ctx, "course-1", `{"max_attachments_per_feedback": 2, "max_attachment_bytes_per_feedback": 10}`, 1); err != nil {
+		t.Fatalf("SetCoursePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		courseID string
+		want     func(*models.EffectivePolicy) // Applied to the defaults
+	}{
+		{name: "no course", courseID: "", want: func(*models.EffectivePolicy) {}},
+		{name: "course without a policy", courseID: "course-2", want: func(p *models.EffectivePolicy) { p.CourseID = "course-2" }},
+		{
+			// Limits the policy omits keep the configured values
+			name:     "course with a policy",
+			courseID: "course-1",
+			want: func(p *models.EffectivePolicy) {
+				p.CourseID, p.MaxAttachmentsPerFeedback, p.MaxAttachmentBytesPerFeedback = "course-1", 2, 10
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Resolve(ctx, tt.courseID)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.courseID, err)
+			}
+			want := s.Defaults()
+			tt.want(&want)
+			if got != want {
+				t.Errorf("Resolve(%q) = %+v, want %+v", tt.courseID, got, want)
+			}
+			if got.MaxCommentDepth != 4 {
+				t.Errorf("Resolve(%q) max comment depth = %d, want the configured 4", tt.courseID, got.MaxCommentDepth)
+			}
+		})
+	}
+}
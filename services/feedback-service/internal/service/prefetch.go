@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// listKind identifies which feedback list a page belongs to
+type listKind string
+
+const (
+	listReviewer listKind = "reviewer"
+	listStudent  listKind = "student"
+)
+
+// PrefetchStats counts prefetch activity since the service started
+type PrefetchStats struct {
+	Started     int64 // Background prefetches started
+	SkippedBusy int64 // Prefetches skipped because one was already in flight for the principal
+	Completed   int64 // Prefetches that cached a page
+	Failed      int64 // Prefetches that failed or timed out
+	CacheHits   int64 // List requests served from a prefetched page
+	CacheMisses int64 // List requests that queried the database
+}
+
+// prefetchedPage is a list page prepared in the background
+type prefetchedPage struct {
+	principal string
+	feedbacks []*models.Feedback
+	total     int64
+	next      *models.FeedbackCursor
+	expires   time.Time
+}
+
+// listPrefetcher prepares feedback list pages in the background and keeps them until they are
+// served once or expire. At most one prefetch runs per principal (the reviewer or student the
+// list belongs to); further requests are skipped while it is in flight.
+type listPrefetcher struct {
+	cfg    config.PrefetchConfig
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	inFlight    map[string]bool
+	generations map[string]uint64 // bumped on invalidation so in-flight results are discarded
+	pages       map[string]*prefetchedPage
+
+	started, skippedBusy, completed, failed, hits, misses atomic.Int64
+}
+
+// newListPrefetcher creates a prefetcher; a zero cache TTL disables prefetching
+func newListPrefetcher(cfg config.PrefetchConfig, logger *slog.Logger) *listPrefetcher {
+	return &listPrefetcher{
+		cfg:         cfg,
+		logger:      logger,
+		inFlight:    make(map[string]bool),
+		generations: make(map[string]uint64),
+		pages:       make(map[string]*prefetchedPage),
+	}
+}
+
+// enabled reports whether prefetching is configured
+func (p *listPrefetcher) enabled() bool {
+	return p.cfg.CacheTTL > 0
+}
+
+// listPrincipal returns the principal whose feedbacks a list shows
+func listPrincipal(kind listKind, filter models.FeedbackFilter) string {
+	if kind == listReviewer {
+		return fmt.Sprintf("%s:%d", kind, *filter.ReviewerID)
+	}
+	return fmt.Sprintf("%s:%d", kind, *filter.StudentID)
+}
+
+// pageKey identifies one page of a list with all of its filters
+func pageKey(kind listKind, filter models.FeedbackFilter) string {
+	key := fmt.Sprintf("%s|page=%d|limit=%d", listPrincipal(kind, filter), filter.Page, filter.Limit)
+	key += fmt.Sprintf("|sort=%s:%t", filter.Sort.Field(), filter.Sort.Ascending)
+	if filter.After != nil {
+		key += fmt.Sprintf("|after=%d:%d:%q:%s", filter.After.CreatedAt.UnixNano(), filter.After.UpdatedAt.UnixNano(), filter.After.Title, filter.After.ID)
+	}
+	if filter.SubmissionID != nil {
+		key += fmt.Sprintf("|submission=%d", *filter.SubmissionID)
+	}
+	if filter.CourseID != nil {
+		key += fmt.Sprintf("|course=%q", *filter.CourseID)
+	}
+	if filter.Status != nil {
+		key += "|status=" + *filter.Status
+	}
+	if filter.ApprovedOnly {
+		key += "|approved"
+	}
+	if filter.UnreadOnly {
+		key += "|unread"
+	}
+	if filter.ResubmissionOnly {
+		key += "|resubmission"
+	}
+	if filter.IncludeArchived {
+		key += "|archived"
+	}
+	if filter.CreatedAfter != nil {
+		key += fmt.Sprintf("|created_after=%d", filter.CreatedAfter.UnixNano())
+	}
+	if filter.CreatedBefore != nil {
+		key += fmt.Sprintf("|created_before=%d", filter.CreatedBefore.UnixNano())
+	}
+	if filter.HasAttachments != nil {
+		key += fmt.Sprintf("|has_attachments=%t", *filter.HasAttachments)
+	}
+	if filter.MinAttachments != nil {
+		key += fmt.Sprintf("|min_attachments=%d", *filter.MinAttachments)
+	}
+	return key
+}
+
+// take removes and returns a prefetched page if one is cached and still fresh
+func (p *listPrefetcher) take(key string) (*prefetchedPage, bool) {
+	if !p.enabled() {
+		return nil, false
+	}
+
+	p.mu.Lock()
+	page, ok := p.pages[key]
+	delete(p.pages, key)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(page.expires) {
+		p.misses.Add(1)
+		return nil, false
+	}
+	p.hits.Add(1)
+	return page, true
+}
+
+// begin claims the principal's prefetch slot and returns its current generation
+func (p *listPrefetcher) begin(principal string) (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.inFlight[principal] {
+		p.skippedBusy.Add(1)
+		return 0, false
+	}
+	p.inFlight[principal] = true
+	p.started.Add(1)
+	return p.generations[principal], true
+}
+
+// finish releases the principal's prefetch slot and caches the page unless the principal's
+// feedbacks changed since the prefetch began
+func (p *listPrefetcher) finish(principal, key string, generation uint64, page *prefetchedPage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.inFlight, principal)
+	if page == nil || p.generations[principal] != generation {
+		return
+	}
+
+	// Bounded by resetting, like the render cache: pages are cheap to prefetch again
+	if len(p.pages) >= p.cfg.CacheEntries {
+		p.pages = make(map[string]*prefetchedPage)
+	}
+	page.principal = principal
+	page.expires = time.Now().Add(p.cfg.CacheTTL)
+	p.pages[key] = page
+}
+
+// invalidate drops the cached pages of the reviewer and student of a changed feedback
+func (p *listPrefetcher) invalidate(feedback *models.Feedback) {
+	if !p.enabled() {
+		return
+	}
+
+	principals := []string{
+		fmt.Sprintf("%s:%d", listReviewer, feedback.ReviewerID),
+		fmt.Sprintf("%s:%d", listStudent, feedback.StudentID),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, principal := range principals {
+		p.generations[principal]++
+	}
+	for key, page := range p.pages {
+		if page.principal == principals[0] || page.principal == principals[1] {
+			delete(p.pages, key)
+		}
+	}
+}
+
+// invalidateAll drops every cached page, used after bulk changes
+func (p *listPrefetcher) invalidateAll() {
+	if !p.enabled() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for principal := range p.inFlight {
+		p.generations[principal]++
+	}
+	p.pages = make(map[string]*prefetchedPage)
+}
+
+// stats returns a snapshot of the prefetch counters
+func (p *listPrefetcher) stats() PrefetchStats {
+	return PrefetchStats{
+		Started:     p.started.Load(),
+		SkippedBusy: p.skippedBusy.Load(),
+		Completed:   p.completed.Load(),
+		Failed:      p.failed.Load(),
+		CacheHits:   p.hits.Load(),
+		CacheMisses: p.misses.Load(),
+	}
+}
+
+// PrefetchStats returns the prefetch counters
+func (s *FeedbackService) PrefetchStats() PrefetchStats {
+	return s.prefetch.stats()
+}
+
+// queryFeedbackList loads a list page from the repository
+func (s *FeedbackService) queryFeedbackList(ctx context.Context, kind listKind, filter models.FeedbackFilter) ([]*models.Feedback, int64, error) {
+	if kind == listReviewer {
+		return s.feedbackRepo.ListByUser(ctx, filter)
+	}
+	return s.feedbackRepo.ListByStudent(ctx, filter)
+}
+
+// loadFeedbackPage loads a list page and the cursor of its last feedback if more results
+// follow. A keyset page is queried with one extra row to find out whether more follow.
+func (s *FeedbackService) loadFeedbackPage(ctx context.Context, kind listKind, filter models.FeedbackFilter) (*prefetchedPage, error) {
+	query := filter
+	if filter.After != nil {
+		query.Limit++
+	}
+	feedbacks, total, err := s.queryFeedbackList(ctx, kind, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var more bool
+	if filter.After != nil {
+		more = len(feedbacks) > filter.Limit
+		if more {
+			feedbacks = feedbacks[:filter.Limit]
+		}
+	} else {
+		more = int64(filter.Page)*int64(filter.Limit) < total
+	}
+
+	page := &prefetchedPage{feedbacks: feedbacks, total: total}
+	if more && len(feedbacks) > 0 {
+		page.next = models.CursorOf(feedbacks[len(feedbacks)-1], filter.Sort)
+	}
+	return page, nil
+}
+
+// listFeedbackPage serves a normalized list page, from the prefetch cache when possible, with
+// the cursor of the following page if more results remain. With prefetchNext set, the
+// following page is prepared in the background.
+func (s *FeedbackService) listFeedbackPage(ctx context.Context, kind listKind, filter models.FeedbackFilter, prefetchNext bool) ([]*models.Feedback, int64, *models.FeedbackCursor, error) {
+	page, ok := s.prefetch.take(pageKey(kind, filter))
+	if !ok {
+		var err error
+		page, err = s.loadFeedbackPage(ctx, kind, filter)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	if prefetchNext && page.next != nil {
+		next := filter
+		if filter.After != nil {
+			next.After = page.next
+		} else {
+			next.Page++
+		}
+		s.prefetchPage(kind, next)
+	}
+
+	return page.feedbacks, page.total, page.next, nil
+}
+
+// prefetchPage prepares a list page in the background and reports whether a prefetch was
+// started. It returns immediately; the work is detached from the caller's request.
+func (s *FeedbackService) prefetchPage(kind listKind, filter models.FeedbackFilter) bool {
+	if !s.prefetch.enabled() {
+		return false
+	}
+
+	principal := listPrincipal(kind, filter)
+	generation, ok := s.prefetch.begin(principal)
+	if !ok {
+		s.logger.Debug("Prefetch already in flight", "principal", principal)
+		return false
+	}
+
+	key := pageKey(kind, filter)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.prefetch.cfg.Timeout)
+		defer cancel()
+
+		start := time.Now()
+		page, err := s.loadFeedbackPage(ctx, kind, filter)
+		if err != nil {
+			s.prefetch.finish(principal, key, generation, nil)
+			s.prefetch.failed.Add(1)
+			s.logger.Warn("Prefetch failed", "principal", principal, "page", filter.Page, "error", err)
+			return
+		}
+
+		s.prefetch.finish(principal, key, generation, page)
+		s.prefetch.completed.Add(1)
+
+		stats := s.prefetch.stats()
+		s.logger.Info("Prefetched feedback page",
+			"principal", principal,
+			"page", filter.Page,
+			"count", len(page.feedbacks),
+			"duration", time.Since(start),
+			"prefetch_started", stats.Started,
+			"prefetch_skipped_busy", stats.SkippedBusy,
+			"prefetch_failed", stats.Failed,
+			"cache_hits", stats.CacheHits,
+			"cache_misses", stats.CacheMisses,
+		)
+	}()
+	return true
+}
+
+// PrefetchReviewerDashboard warms the first page of a reviewer's feedback list in the
+// background. It reports whether a prefetch was started; false means prefetching is disabled
+// or one is already in flight for the reviewer.
+func (s *FeedbackService) PrefetchReviewerDashboard(reviewerID int64, limit int32) (bool, error) {
+	if reviewerID <= 0 {
+		return false, fmt.Errorf("invalid reviewer ID")
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := models.FeedbackFilter{
+		ReviewerID: &reviewerID,
+		Page:       1,
+		Limit:      int(limit),
+	}
+	return s.prefetchPage(listReviewer, filter), nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// maxRateBuckets caps the number of buckets a single creation rate request may span
+const maxRateBuckets = 1000
+
+// ErrInvalidRateRange is returned when a creation rate request has an unsupported bucket size or range
+var ErrInvalidRateRange = errors.New("invalid rate range")
+
+// rateBucketDurations maps supported bucket sizes to their length
+var rateBucketDurations = map[string]time.Duration{
+	models.RateBucketMinute: time.Minute,
+	models.RateBucketHour:   time.Hour,
+	models.RateBucketDay:    24 * time.Hour,
+	models.RateBucketWeek:   7 * 24 * time.Hour,
+}
+
+// truncateToBucket returns the start of the UTC bucket containing t, matching date_trunc
+func truncateToBucket(t time.Time, size string) time.Time {
+	t = t.UTC()
+	switch size {
+	case models.RateBucketMinute:
+		return t.Truncate(time.Minute)
+	case models.RateBucketHour:
+		return t.Truncate(time.Hour)
+	case models.RateBucketDay:
+		return utcDay(t)
+	default:
+		day := utcDay(t)
+		// time.Weekday counts from Sunday; buckets start on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+}
+
+// validateRateRange checks a creation rate request and returns the normalized UTC range
+func validateRateRange(size string, since, until time.Time) (time.Time, time.Time, error) {
+	step, ok := rateBucketDurations[size]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: unsupported bucket size %q", ErrInvalidRateRange, size)
+	}
+
+	since, until = since.UTC(), until.UTC()
+	if !since.Before(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: since must be before until", ErrInvalidRateRange)
+	}
+
+	buckets := (until.Sub(truncateToBucket(since, size)) + step - 1) / step
+	if buckets > maxRateBuckets {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: range spans %d %s buckets, maximum is %d", ErrInvalidRateRange, buckets, size, maxRateBuckets)
+	}
+
+	return since, until, nil
+}
+
+// fillRateBuckets returns one bucket for every step in [since, until), taking counts from
+// the sparse buckets returned by storage and zero elsewhere
+func fillRateBuckets(size string, since, until time.Time, counts []*models.RateBucket) []*models.RateBucket {
+	byStart := make(map[time.Time]int64, len(counts))
+	for _, bucket := range counts {
+		byStart[bucket.Start.UTC()] += bucket.Count
+	}
+
+	step := rateBucketDurations[size]
+	var buckets []*models.RateBucket
+	for start := truncateToBucket(since, size); start.Before(until); start = start.Add(step) {
+		buckets = append(buckets, &models.RateBucket{Start: start, Count: byStart[start]})
+	}
+	return buckets
+}
+
+// GetFeedbackCreationRate returns the number of feedbacks created per bucket in [since, until)
+// by a reviewer or for a submission. Every bucket in the range is returned, including empty ones.
+func (s *FeedbackService) GetFeedbackCreationRate(ctx context.Context, reviewerID, submissionID *int64, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	if (reviewerID == nil) == (submissionID == nil) {
+		return nil, fmt.Errorf("%w: exactly one of reviewer ID or submission ID is required", ErrInvalidRateRange)
+	}
+
+	since, until, err := validateRateRange(bucketSize, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.feedbackRepo.CountCreatedByBucket(ctx, reviewerID, submissionID, bucketSize, since, until)
+	if err != nil {
+		s.logger.Error("Failed to get feedback creation rate", "error", err)
+		return nil, fmt.Errorf("failed to get feedback creation rate: %w", err)
+	}
+
+	return fillRateBuckets(bucketSize, since, until, counts), nil
+}
+
+// GetCommentCreationRate returns the number of comments created per bucket in [since, until)
+// on a content. Every bucket in the range is returned, including empty ones.
+func (s *CommentService) GetCommentCreationRate(ctx context.Context, contentID int64, contentType, bucketSize string, since, until time.Time) ([]*models.RateBucket, error) {
+	if contentID <= 0 {
+		return nil, fmt.Errorf("invalid content ID")
+	}
+	if contentType == "" {
+		return nil, fmt.Errorf("content type is required")
+	}
+
+	since, until, err := validateRateRange(bucketSize, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.commentRepo.CountCreatedByBucket(ctx, contentID, contentType, bucketSize, since, until)
+	if err != nil {
+		s.logger.Error("Failed to get comment creation rate", "content_id", contentID, "error", err)
+		return nil, fmt.Errorf("failed to get comment creation rate: %w", err)
+	}
+
+	return fillRateBuckets(bucketSize, since, until, counts), nil
+}
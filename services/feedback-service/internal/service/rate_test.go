@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+func TestFillRateBuckets(t *testing.T) {
+	at := func(value string) time.Time {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	// 02:00 on March 2 in UTC+3 is 23:00 on March 1 in UTC
+	zone := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name         string
+		size         string
+		since, until time.Time
+		counts       []*models.RateBucket
+		want         []string // Bucket starts in UTC, with the count after a space when not zero
+	}{
+		{
+			name:  "range on bucket edges",
+			size:  models.RateBucketHour,
+			since: at("2025-03-01T10:00:00Z"), until: at("2025-03-01T12:00:00Z"),
+			counts: []*models.RateBucket{{Start: at("2025-03-01T10:00:00Z"), Count: 2}, {Start: at("2025-03-01T11:00:00Z"), Count: 1}},
+			want:   []string{"2025-03-01T10:00:00Z 2", "2025-03-01T11:00:00Z 1"},
+		},
+		{
+			name:  "range inside buckets",
+			size:  models.RateBucketHour,
+			since: at("2025-03-01T10:30:00Z"), until: at("2025-03-01T11:00:01Z"),
+			counts: []*models.RateBucket{{Start: at("2025-03-01T11:00:00Z"), Count: 4}},
+			want:   []string{"2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z 4"},
+		},
+		{
+			name:  "days across UTC midnight from another zone",
+			size:  models.RateBucketDay,
+			since: time.Date(2025, 3, 2, 2, 0, 0, 0, zone), until: time.Date(2025, 3, 3, 2, 0, 0, 0, zone),
+			counts: []*models.RateBucket{{Start: at("2025-03-02T00:00:00Z"), Count: 5}},
+			want:   []string{"2025-03-01T00:00:00Z", "2025-03-02T00:00:00Z 5"},
+		},
+		{
+			name:  "storage buckets in another zone",
+			size:  models.RateBucketDay,
+			since: at("2025-03-01T00:00:00Z"), until: at("2025-03-02T00:00:00Z"),
+			counts: []*models.RateBucket{{Start: at("2025-03-01T00:00:00Z").In(zone), Count: 3}},
+			want:   []string{"2025-03-01T00:00:00Z 3"},
+		},
+		{
+			name:  "weeks start on Monday",
+			size:  models.RateBucketWeek,
+			since: at("2025-03-02T23:59:59Z"), until: at("2025-03-03T00:00:01Z"), // Sunday to Monday
+			want: []string{"2025-02-24T00:00:00Z", "2025-03-03T00:00:00Z"},
+		},
+		{
+			name:  "no feedbacks in the range",
+			size:  models.RateBucketHour,
+			since: at("2025-03-01T10:00:00Z"), until: at("2025-03-01T13:00:00Z"),
+			want: []string{"2025-03-01T10:00:00Z", "2025-03-01T11:00:00Z", "2025-03-01T12:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := validateRateRange(tt.size, tt.since, tt.until)
+			if err != nil {
+				t.Fatalf("validateRateRange() error = %v", err)
+			}
+			buckets := fillRateBuckets(tt.size, since, until, tt.counts)
+			var got []string
+			for _, bucket := range buckets {
+				entry := bucket.Start.Format(time.RFC3339)
+				if bucket.Start.Location() != time.UTC {
+					t.Errorf("bucket %s is not in UTC", bucket.Start)
+				}
+				if bucket.Count != 0 {
+					entry += " " + strconv.FormatInt(bucket.Count, 10)
+				}
+				got = append(got, entry)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("fillRateBuckets() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("fillRateBuckets()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRateRange(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		size         string
+		since, until time.Time
+		wantErr      bool
+	}{
+		{name: "one minute", size: models.RateBucketMinute, since: start, until: start.Add(time.Minute)},
+		{name: "empty", size: models.RateBucketHour, since: start, until: start, wantErr: true},
+		{name: "reversed", size: models.RateBucketHour, since: start.Add(time.Hour), until: start, wantErr: true},
+		{name: "unsupported size", size: "fortnight", since: start, until: start.Add(time.Hour), wantErr: true},
+		{name: "at the bucket limit", size: models.RateBucketMinute, since: start, until: start.Add(maxRateBuckets * time.Minute)},
+		{name: "past the bucket limit", size: models.RateBucketMinute, since: start, until: start.Add(maxRateBuckets*time.Minute + time.Second), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := validateRateRange(tt.size, tt.since, tt.until)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateRateRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRateRange) {
+				t.Errorf("validateRateRange() error = %v, want %v", err, ErrInvalidRateRange)
+			}
+		})
+	}
+}
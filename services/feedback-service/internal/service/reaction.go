@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ReactToFeedback sets the student's reaction to a feedback addressed to them, replacing an
+// earlier reaction, and returns the feedback with its updated counts
+func (s *FeedbackService) ReactToFeedback(ctx context.Context, id uuid.UUID, studentID int64, reaction string) (*models.Feedback, error) {
+	s.logger.Info("Reacting to feedback", "feedback_id", id, "student_id", studentID, "reaction", reaction)
+
+	if !models.IsValidReaction(reaction) {
+		return nil, apperr.InvalidField("reaction", "reaction must be HELPFUL or NOT_HELPFUL")
+	}
+	feedback, err := s.reactableFeedback(ctx, id, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.feedbackRepo.SetReaction(ctx, id, studentID, reaction)
+	if err != nil {
+		s.logger.Error("Failed to set reaction", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to set reaction: %w", err)
+	}
+	if previous != reaction {
+		feedback.CountReaction(previous, -1)
+		feedback.CountReaction(reaction, 1)
+		s.prefetch.invalidate(feedback)
+	}
+
+	s.logger.Info("Feedback reaction set", "feedback_id", id, "reaction", reaction)
+	return feedback, nil
+}
+
+// RemoveFeedbackReaction removes the student's reaction to a feedback, if any, and returns the
+// feedback with its updated counts
+func (s *FeedbackService) RemoveFeedbackReaction(ctx context.Context, id uuid.UUID, studentID int64) (*models.Feedback, error) {
+	s.logger.Info("Removing feedback reaction", "feedback_id", id, "student_id", studentID)
+
+	feedback, err := s.reactableFeedback(ctx, id, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.feedbackRepo.RemoveReaction(ctx, id, studentID)
+	if err != nil {
+		s.logger.Error("Failed to remove reaction", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	if previous != "" {
+		feedback.CountReaction(previous, -1)
+		s.prefetch.invalidate(feedback)
+	}
+
+	s.logger.Info("Feedback reaction removed", "feedback_id", id)
+	return feedback, nil
+}
+
+// reactableFeedback returns a feedback the student may react to. Feedbacks the student cannot
+// see yet are reported as not found, as for AcknowledgeFeedback.
+func (s *FeedbackService) reactableFeedback(ctx context.Context, id uuid.UUID, studentID int64) (*models.Feedback, error) {
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("id", "invalid feedback ID")
+	}
+	if studentID <= 0 {
+		return nil, apperr.InvalidField("student_id", "invalid student ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get feedback for reaction", "feedback_id", id, "error", err)
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if !feedback.IsVisibleToStudent() {
+		return nil, repository.ErrFeedbackNotFound
+	}
+	if err := authz.ReactToFeedback(authz.Principal{UserID: studentID}, feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// validateResubmission rejects a resubmission deadline on a feedback that does not ask for a
+// resubmission
+func validateResubmission(feedback *models.Feedback) error {
+	if feedback.ResubmissionDeadline != nil && !feedback.RequiresResubmission {
+		return apperr.InvalidField("resubmission_deadline", "resubmission_deadline needs requires_resubmission")
+	}
+	return nil
+}
+
+// checkPublishDeadline rejects publishing a feedback whose resubmission deadline has passed,
+// which the student could never meet
+func checkPublishDeadline(feedback *models.Feedback, now time.Time) error {
+	if feedback.ResubmissionDeadline != nil && !feedback.ResubmissionDeadline.After(now) {
+		return apperr.InvalidField("resubmission_deadline", "resubmission_deadline is in the past; move or clear it before publishing")
+	}
+	return nil
+}
+
+// applyResubmission applies the resubmission fields of an update without a mask. Turning the
+// flag off also clears the deadline unless a new one is given.
+func applyResubmission(feedback *models.Feedback, requires *bool, deadline *time.Time, clearDeadline bool) {
+	if requires != nil {
+		feedback.RequiresResubmission = *requires
+		if !*requires && deadline == nil {
+			feedback.ResubmissionDeadline = nil
+		}
+	}
+	if deadline != nil || clearDeadline {
+		feedback.ResubmissionDeadline = deadline
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ErrUnknownRetentionDataset is returned when a retention run names a dataset that does not exist
+var ErrUnknownRetentionDataset = errors.New("unknown retention dataset")
+
+// RetentionService prunes expired maintenance data (upload sessions, audit log, transfer
+// counters, idempotency keys) in batches, either periodically or on demand. Unresolved upload sessions are
+// kept regardless of age unless the run is forced.
+type RetentionService struct {
+	repo           repository.RetentionRepository
+	attachmentRepo repository.AttachmentRepository
+	cfg            config.RetentionConfig
+	logger         *slog.Logger
+	now            func() time.Time
+}
+
+// NewRetentionService creates a new retention service
+func NewRetentionService(repo repository.RetentionRepository, attachmentRepo repository.AttachmentRepository, cfg config.RetentionConfig, logger *slog.Logger) *RetentionService {
+	return &RetentionService{
+		repo:           repo,
+		attachmentRepo: attachmentRepo,
+		cfg:            cfg,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Datasets returns the names of all retention datasets in a stable order
+func (s *RetentionService) Datasets() []string {
+	datasets := make([]string, 0, len(s.retentions()))
+	for dataset := range s.retentions() {
+		datasets = append(datasets, dataset)
+	}
+	sort.Strings(datasets)
+	return datasets
+}
+
+// retentions maps each dataset to its configured retention duration
+func (s *RetentionService) retentions() map[string]time.Duration {
+	return map[string]time.Duration{
+		models.RetentionUploadSessions:  s.cfg.UploadSessions,
+		models.RetentionAuditLog:        s.cfg.AuditLog,
+		models.RetentionTransferUsage:   s.cfg.TransferUsage,
+		models.RetentionIdempotencyKeys: s.cfg.IdempotencyKeys,
+	}
+}
+
+// Run prunes all datasets every configured interval until ctx is cancelled. A cancelled run
+// stops between batches, so shutdown never waits for a whole dataset.
+func (s *RetentionService) Run(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		s.logger.Info("Periodic retention pruning disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.RunAll(ctx, false, false)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunAll prunes every dataset and returns one result per dataset
+func (s *RetentionService) RunAll(ctx context.Context, dryRun, force bool) []*models.RetentionResult {
+	var results []*models.RetentionResult
+	for _, dataset := range s.Datasets() {
+		if ctx.Err() != nil {
+			break
+		}
+		result, _ := s.RunDataset(ctx, dataset, dryRun, force)
+		results = append(results, result)
+	}
+	return results
+}
+
+// RunDataset prunes rows of one dataset older than its retention. A dry run only counts the
+// rows that would be pruned. Errors are reported both in the result and as the return value.
+func (s *RetentionService) RunDataset(ctx context.Context, dataset string, dryRun, force bool) (*models.RetentionResult, error) {
+	retention, ok := s.retentions()[dataset]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownRetentionDataset, dataset)
+	}
+
+	result := &models.RetentionResult{Dataset: dataset}
+	if retention == 0 {
+		result.Disabled = true
+		return result, nil
+	}
+
+	start := s.now()
+	result.Cutoff = start.UTC().Add(-retention)
+
+	if dryRun {
+		result.Pruned, result.Protected, result.Err = s.repo.CountExpired(ctx, dataset, result.Cutoff, force)
+	} else {
+		result.Err = s.prune(ctx, result, force)
+	}
+
+	if result.Err == nil {
+		result.Oldest, result.Err = s.repo.Oldest(ctx, dataset)
+	}
+	result.Duration = s.now().Sub(start)
+
+	logArgs := []any{
+		"dataset", dataset,
+		"dry_run", dryRun,
+		"force", force,
+		"cutoff", result.Cutoff,
+		"pruned", result.Pruned,
+		"protected", result.Protected,
+		"duration", result.Duration,
+	}
+	if result.Oldest != nil {
+		logArgs = append(logArgs, "oldest_remaining", *result.Oldest)
+	}
+	if result.Err != nil {
+		s.logger.Error("Retention run failed", append(logArgs, "error", result.Err)...)
+	} else {
+		s.logger.Info("Retention run completed", logArgs...)
+	}
+
+	return result, result.Err
+}
+
+// prune deletes expired rows batch by batch, stopping between batches on cancellation
+func (s *RetentionService) prune(ctx context.Context, result *models.RetentionResult, force bool) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("retention run interrupted: %w", err)
+		}
+
+		keys, err := s.repo.PruneBatch(ctx, result.Dataset, result.Cutoff, s.cfg.BatchSize, force)
+		if err != nil {
+			return err
+		}
+		result.Pruned += int64(len(keys))
+
+		if result.Dataset == models.RetentionUploadSessions {
+			s.deleteStagedFiles(ctx, keys)
+		}
+
+		if len(keys) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	if !force {
+		_, protected, err := s.repo.CountExpired(ctx, result.Dataset, result.Cutoff, false)
+		if err != nil {
+			return err
+		}
+		result.Protected = protected
+	}
+
+	return nil
+}
+
+// deleteStagedFiles removes any staged objects left behind by pruned upload sessions, e.g.
+// unresolved sessions pruned by a forced run or aborts whose cleanup failed
+func (s *RetentionService) deleteStagedFiles(ctx context.Context, sessionIDs []string) {
+	for _, key := range sessionIDs {
+		sessionID, err := uuid.Parse(key)
+		if err != nil {
+			continue
+		}
+		if _, err := s.attachmentRepo.DeleteStaged(ctx, sessionID); err != nil {
+			s.logger.Warn("Failed to delete staged files of pruned upload session", "session_id", sessionID, "error", err)
+		}
+	}
+}
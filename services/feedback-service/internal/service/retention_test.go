@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository/repotest"
+	"github.com/google/uuid"
+)
+
+var testRetentionConfig = config.RetentionConfig{
+	BatchSize:      2,
+	UploadSessions: 7 * 24 * time.Hour,
+	AuditLog:       30 * 24 * time.Hour,
+	TransferUsage:  10 * 24 * time.Hour,
+}
+
+// newTestRetention returns a retention service on store with its clock at now
+func newTestRetention(store *repotest.Store, now time.Time) *RetentionService {
+	s := NewRetentionService(store.Retention(), store.Attachments(), testRetentionConfig, slog.New(slog.DiscardHandler))
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestRetentionUploadSessions(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-testRetentionConfig.UploadSessions)
+	ctx := context.Background()
+
+	sessions := []struct {
+		name      string
+		status    string
+		updatedAt time.Time
+		force     bool // Survives a normal run but not a forced one
+		survives  bool
+	}{
+		{name: "committed, expired", status: models.UploadSessionCommitted, updatedAt: cutoff.Add(-time.Hour)},
+		{name: "aborted, expired", status: models.UploadSessionAborted, updatedAt: cutoff.Add(-24 * time.Hour)},
+		{name: "aborted, just expired", status: models.UploadSessionAborted, updatedAt: cutoff.Add(-time.Second)},
+		{name: "committed, at the cutoff", status: models.UploadSessionCommitted, updatedAt: cutoff, survives: true},
+		{name: "aborted, recent", status: models.UploadSessionAborted, updatedAt: now.Add(-time.Hour), survives: true},
+		{name: "open, expired", status: models.UploadSessionOpen, updatedAt: cutoff.Add(-time.Hour), force: true},
+		{name: "committing, expired", status: models.UploadSessionCommitting, updatedAt: cutoff.Add(-time.Hour), force: true},
+		{name: "open, recent", status: models.UploadSessionOpen, updatedAt: now.Add(-time.Hour), survives: true},
+	}
+
+	// run prunes the dataset and returns the names of the sessions left
+	run := func(t *testing.T, force bool) ([]string, *models.RetentionResult) {
+		t.Helper()
+		store := repotest.New()
+		ids := make([]uuid.UUID, len(sessions))
+		for i, seeded := range sessions {
+			session := &models.UploadSession{FeedbackID: uuid.New(), ReviewerID: 101, Status: seeded.status, CreatedAt: seeded.updatedAt, UpdatedAt: seeded.updatedAt}
+			store.SeedSession(session)
+			ids[i] = session.ID
+			// Staged files an abort failed to clean up are removed with their session
+			if err := store.Attachments().UploadStaged(ctx, session.ID, session.FeedbackID, "staged.txt", "text/plain", bytes.NewReader([]byte("x")), 1); err != nil {
+				t.Fatalf("UploadStaged() error = %v", err)
+			}
+		}
+
+		result, err := newTestRetention(store, now).RunDataset(ctx, models.RetentionUploadSessions, false, force)
+		if err != nil {
+			t.Fatalf("RunDataset() error = %v", err)
+		}
+		var left []string
+		for i, seeded := range sessions {
+			_, err := store.Sessions().GetByID(ctx, ids[i])
+			switch {
+			case err == nil:
+				left = append(left, seeded.name)
+			case err != repository.ErrUploadSessionNotFound:
+				t.Fatalf("GetByID(%s) error = %v", seeded.name, err)
+			default:
+				if staged, _ := store.Attachments().DeleteStaged(ctx, ids[i]); staged != 0 {
+					t.Errorf("session %s pruned with %d staged files left", seeded.name, staged)
+				}
+			}
+		}
+		return left, result
+	}
+
+	t.Run("unresolved sessions kept", func(t *testing.T) {
+		left, result := run(t, false)
+		var want []string
+		for _, seeded := range sessions {
+			if seeded.survives || seeded.force {
+				want = append(want, seeded.name)
+			}
+		}
+		if !slices.Equal(left, want) {
+			t.Errorf("sessions left = %v, want %v", left, want)
+		}
+		if result.Pruned != 3 || result.Protected != 2 {
+			t.Errorf("RunDataset() pruned %d and protected %d, want 3 and 2", result.Pruned, result.Protected)
+		}
+		if !result.Cutoff.Equal(cutoff) {
+			t.Errorf("RunDataset() cutoff = %v, want %v", result.Cutoff, cutoff)
+		}
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		left, result := run(t, true)
+		var want []string
+		for _, seeded := range sessions {
+			if seeded.survives {
+				want = append(want, seeded.name)
+			}
+		}
+		if !slices.Equal(left, want) {
+			t.Errorf("sessions left = %v, want %v", left, want)
+		}
+		if result.Pruned != 5 || result.Protected != 0 {
+			t.Errorf("RunDataset() pruned %d and protected %d, want 5 and 0", result.Pruned, result.Protected)
+		}
+		if result.Oldest == nil || !result.Oldest.Equal(cutoff) {
+			t.Errorf("RunDataset() oldest = %v, want the session at the cutoff", result.Oldest)
+		}
+	})
+}
+
+func TestRetentionAuditLog(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-testRetentionConfig.AuditLog)
+	ctx := context.Background()
+	store := repotest.New()
+	feedbackID := uuid.New()
+
+	entries := map[string]time.Time{
+		"long expired": cutoff.AddDate(-1, 0, 0),
+		"expired":      cutoff.Add(-time.Hour),
+		"just expired": cutoff.Add(-time.Microsecond),
+		"at cutoff":    cutoff,
+		"recent":       now.Add(-time.Hour),
+	}
+	for action, createdAt := range entries {
+		if err := store.Audit().Record(ctx, &models.AuditEntry{FeedbackID: feedbackID, ActorID: 101, Action: action, CreatedAt: createdAt}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	// A dry run counts without deleting
+	s := newTestRetention(store, now)
+	dry, err := s.RunDataset(ctx, models.RetentionAuditLog, true, false)
+	if err != nil {
+		t.Fatalf("RunDataset(dry run) error = %v", err)
+	}
+	if dry.Pruned != 3 {
+		t.Errorf("RunDataset(dry run) pruned = %d, want 3", dry.Pruned)
+	}
+	if left, _ := store.Audit().ListByFeedback(ctx, feedbackID); len(left) != len(entries) {
+		t.Errorf("dry run left %d entries, want all %d", len(left), len(entries))
+	}
+
+	result, err := s.RunDataset(ctx, models.RetentionAuditLog, false, false)
+	if err != nil {
+		t.Fatalf("RunDataset() error = %v", err)
+	}
+	if result.Pruned != 3 {
+		t.Errorf("RunDataset() pruned = %d, want 3", result.Pruned)
+	}
+	left, err := store.Audit().ListByFeedback(ctx, feedbackID)
+	if err != nil {
+		t.Fatalf("ListByFeedback() error = %v", err)
+	}
+	var actions []string
+	for _, entry := range left {
+		actions = append(actions, entry.Action)
+	}
+	if want := []string{"at cutoff", "recent"}; !slices.Equal(actions, want) {
+		t.Errorf("entries left = %v, want %v", actions, want)
+	}
+}
+
+func TestRetentionTransferUsage(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	store := repotest.New()
+
+	// Counters are kept by UTC day; the cutoff is March 21 12:00, so the March 21 row started
+	// before it and is expired while March 22 is not
+	var deltas []*models.TransferUsage
+	for day := 1; day <= 31; day++ {
+		for _, principal := range []int64{7, 8} {
+			deltas = append(deltas, &models.TransferUsage{PrincipalID: principal, Day: time.Date(2025, 3, day, 0, 0, 0, 0, time.UTC), BytesUploaded: 1})
+		}
+	}
+	if err := store.Transfers().AddUsage(ctx, deltas); err != nil {
+		t.Fatalf("AddUsage() error = %v", err)
+	}
+
+	result, err := newTestRetention(store, now).RunDataset(ctx, models.RetentionTransferUsage, false, false)
+	if err != nil {
+		t.Fatalf("RunDataset() error = %v", err)
+	}
+	if result.Pruned != 2*21 {
+		t.Errorf("RunDataset() pruned = %d, want %d", result.Pruned, 2*21)
+	}
+	firstKept := time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC)
+	if result.Oldest == nil || !result.Oldest.Equal(firstKept) {
+		t.Errorf("RunDataset() oldest = %v, want %v", result.Oldest, firstKept)
+	}
+	for _, principal := range []int64{7, 8} {
+		usage, err := store.Transfers().GetUsage(ctx, principal, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), now)
+		if err != nil {
+			t.Fatalf("GetUsage() error = %v", err)
+		}
+		if len(usage) != 10 {
+			t.Fatalf("principal %d kept %d days, want 10", principal, len(usage))
+		}
+		if !usage[0].Day.Equal(firstKept) {
+			t.Errorf("principal %d kept days from %v, want from %v", principal, usage[0].Day, firstKept)
+		}
+	}
+}
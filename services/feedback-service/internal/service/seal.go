@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
+	"github.com/google/uuid"
+)
+
+// SealFeedback records the current content and attachments of a feedback in a new seal, chained
+// to the last seal stored. Attachments are hashed from their stored content rather than taken
+// from recorded checksums. Sealing does not lock the feedback; see SetFeedbackLock.
+func (s *FeedbackService) SealFeedback(ctx context.Context, id uuid.UUID, actorID int64) (*models.FeedbackSeal, error) {
+	s.logger.Info("Sealing feedback", "feedback_id", id, "actor_id", actorID)
+
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("invalid feedback ID")
+	}
+	if actorID <= 0 {
+		return nil, fmt.Errorf("invalid actor ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	manifest, err := s.sealManifest(ctx, feedback)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode seal manifest: %w", err)
+	}
+
+	seal := &models.FeedbackSeal{
+		FeedbackID:     id,
+		ActorID:        actorID,
+		Manifest:       string(encoded),
+		ManifestSHA256: sha256Hex(encoded),
+	}
+	if err := s.sealRepo.Append(ctx, seal); err != nil {
+		s.logger.Error("Failed to store feedback seal", "feedback_id", id, "error", err)
+		return nil, err
+	}
+
+	entry := &models.AuditEntry{
+		FeedbackID: id,
+		ActorID:    actorID,
+		Action:     models.AuditActionSealed,
+		Details:    fmt.Sprintf("seal_id=%d manifest_sha256=%s chain_sha256=%s", seal.ID, seal.ManifestSHA256, seal.ChainSHA256),
+	}
+	if err := s.auditRepo.Record(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", "feedback_id", id, "action", entry.Action, "error", err)
+	}
+
+	s.logger.Info("Feedback sealed", "feedback_id", id, "seal_id", seal.ID, "attachments", len(manifest.Attachments))
+	return seal, nil
+}
+
+// GetFeedbackSeal returns the latest seal of a feedback. Seals outlive their feedback.
+func (s *FeedbackService) GetFeedbackSeal(ctx context.Context, id uuid.UUID) (*models.FeedbackSeal, error) {
+	if id == uuid.Nil {
+		return nil, fmt.Errorf("invalid feedback ID")
+	}
+	return s.sealRepo.Latest(ctx, id)
+}
+
+// VerifyFeedbackSeal compares a feedback with its latest seal. It first checks that the stored
+// seal matches its own hashes, then recomputes the manifest from the current content and
+// attachments and reports every component that differs.
+func (s *FeedbackService) VerifyFeedbackSeal(ctx context.Context, id uuid.UUID) (*models.SealVerification, error) {
+	s.logger.Info("Verifying feedback seal", "feedback_id", id)
+
+	seal, err := s.GetFeedbackSeal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.SealVerification{Seal: seal, Divergences: []models.SealDivergence{}}
+
+	if actual := sha256Hex([]byte(seal.Manifest)); actual != seal.ManifestSHA256 {
+		result.Divergences = append(result.Divergences, models.SealDivergence{Component: models.SealComponentSeal, Sealed: seal.ManifestSHA256, Current: actual})
+	}
+	if actual := repository.SealChainHash(seal.PreviousSHA256, seal.ManifestSHA256); actual != seal.ChainSHA256 {
+		result.Divergences = append(result.Divergences, models.SealDivergence{Component: models.SealComponentSeal, Sealed: seal.ChainSHA256, Current: actual})
+	}
+	var sealed models.SealManifest
+	if err := json.Unmarshal([]byte(seal.Manifest), &sealed); err != nil {
+		return nil, fmt.Errorf("failed to decode seal manifest: %w", err)
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, id)
+	if errors.Is(err, repository.ErrFeedbackNotFound) {
+		result.Divergences = append(result.Divergences, models.SealDivergence{Component: models.SealComponentFeedback, Sealed: "present", Current: "deleted"})
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	current, err := s.sealManifest(ctx, feedback)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Divergences = append(result.Divergences, compareManifests(&sealed, current)...)
+	s.logger.Info("Feedback seal verified", "feedback_id", id, "seal_id", seal.ID, "divergences", len(result.Divergences))
+	return result, nil
+}
+
+// sealManifest records the current state of a feedback, hashing every stored attachment
+func (s *FeedbackService) sealManifest(ctx context.Context, feedback *models.Feedback) (*models.SealManifest, error) {
+	manifest := &models.SealManifest{
+		FeedbackID:    feedback.ID,
+		Title:         feedback.Title,
+		ContentSHA256: sha256Hex([]byte(feedback.Content)),
+		ContentBytes:  int64(len(feedback.Content)),
+		UpdatedAt:     feedback.UpdatedAt.UTC(),
+		Attachments:   []models.SealAttachment{},
+	}
+
+	attachments, err := s.lister.list(ctx, feedback.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	for _, attachment := range attachments {
+		sum, size, err := s.hashAttachment(ctx, feedback.ID, attachment.Filename)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Attachments = append(manifest.Attachments, models.SealAttachment{
+			Filename:   attachment.Filename,
+			SHA256:     sum,
+			Size:       size,
+			UploadedAt: attachment.UploadedAt.UTC(),
+		})
+	}
+	sort.Slice(manifest.Attachments, func(i, j int) bool {
+		return manifest.Attachments[i].Filename < manifest.Attachments[j].Filename
+	})
+	return manifest, nil
+}
+
+// hashAttachment returns the hex SHA-256 and size of an attachment's stored content
+func (s *FeedbackService) hashAttachment(ctx context.Context, feedbackID uuid.UUID, filename string) (string, int64, error) {
+	reader, _, err := s.attachmentRepo.Download(ctx, feedbackID, filename)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read attachment %s: %w", validation.LogValue(filename), err)
+	}
+	defer reader.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash attachment %s: %w", validation.LogValue(filename), err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// compareManifests returns the components of a feedback that differ between two manifests
+func compareManifests(sealed, current *models.SealManifest) []models.SealDivergence {
+	var divergences []models.SealDivergence
+	if sealed.Title != current.Title {
+		divergences = append(divergences, models.SealDivergence{Component: models.SealComponentTitle, Sealed: sealed.Title, Current: current.Title})
+	}
+	if sealed.ContentSHA256 != current.ContentSHA256 {
+		divergences = append(divergences, models.SealDivergence{Component: models.SealComponentContent, Sealed: sealed.ContentSHA256, Current: current.ContentSHA256})
+	}
+
+	currentAttachments := make(map[string]string, len(current.Attachments))
+	for _, attachment := range current.Attachments {
+		currentAttachments[attachment.Filename] = attachment.SHA256
+	}
+	for _, attachment := range sealed.Attachments {
+		sum, ok := currentAttachments[attachment.Filename]
+		delete(currentAttachments, attachment.Filename)
+		if !ok || sum != attachment.SHA256 {
+			divergences = append(divergences, models.SealDivergence{Component: models.SealComponentAttachment, Filename: attachment.Filename, Sealed: attachment.SHA256, Current: sum})
+		}
+	}
+	// Attachments added since sealing, in filename order
+	for _, attachment := range current.Attachments {
+		if _, added := currentAttachments[attachment.Filename]; added {
+			divergences = append(divergences, models.SealDivergence{Component: models.SealComponentAttachment, Filename: attachment.Filename, Current: attachment.SHA256})
+		}
+	}
+	return divergences
+}
+
+// sha256Hex returns the hex SHA-256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
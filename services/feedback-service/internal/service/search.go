@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// minSearchQueryChars is the fewest letters and digits a search query must contain, so that
+// queries which would match almost every feedback are rejected before they reach the database
+const minSearchQueryChars = 3
+
+// maxSearchQueryBytes is the longest accepted search query
+const maxSearchQueryBytes = 256
+
+// searchIndexBatchSize is the number of feedbacks indexed per batch by BackfillSearchIndex
+const searchIndexBatchSize = 200
+
+// searchableChars counts the letters and digits of a query
+func searchableChars(query string) int {
+	count := 0
+	for _, r := range query {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// SearchFeedbacks finds feedbacks by keywords in their title or content, most relevant first.
+// A reviewer or student filter is required. Without a reviewer filter only the feedbacks the
+// student can see are searched, like ListStudentFeedbacks.
+func (s *FeedbackService) SearchFeedbacks(ctx context.Context, filter models.FeedbackSearchFilter) ([]*models.FeedbackSearchResult, int32, error) {
+	s.logger.Info("Searching feedbacks",
+		"reviewer_id", filter.ReviewerID,
+		"student_id", filter.StudentID,
+		"submission_id", filter.SubmissionID,
+		"status", filter.Status,
+		"query_length", len(filter.Query),
+		"page", filter.Page,
+		"limit", filter.Limit,
+	)
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, 0, apperr.InvalidField("query", "query is required")
+	}
+	if len(filter.Query) > maxSearchQueryBytes {
+		return nil, 0, apperr.InvalidField("query", fmt.Sprintf("query must be at most %d bytes", maxSearchQueryBytes))
+	}
+	if searchableChars(filter.Query) < minSearchQueryChars {
+		return nil, 0, apperr.InvalidField("query", fmt.Sprintf("query must contain at least %d letters or digits", minSearchQueryChars))
+	}
+	if filter.ReviewerID == nil && filter.StudentID == nil {
+		return nil, 0, apperr.InvalidField("reviewer_id", "reviewer_id or student_id is required")
+	}
+	if filter.ReviewerID != nil && *filter.ReviewerID <= 0 {
+		return nil, 0, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if filter.StudentID != nil && *filter.StudentID <= 0 {
+		return nil, 0, apperr.InvalidField("student_id", "invalid student ID")
+	}
+	if filter.Status != nil && *filter.Status != models.FeedbackDraft && *filter.Status != models.FeedbackPublished {
+		return nil, 0, apperr.InvalidField("status", "invalid feedback status")
+	}
+	if filter.ReviewerID == nil {
+		published := models.FeedbackPublished
+		filter.Status = &published
+		filter.ApprovedOnly = true
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	results, totalCount, err := s.feedbackRepo.Search(ctx, filter)
+	if err != nil {
+		s.logger.Error("Failed to search feedbacks", "error", err)
+		return nil, 0, fmt.Errorf("failed to search feedbacks: %w", err)
+	}
+
+	s.logger.Info("Feedbacks searched successfully", "count", len(results), "total_count", totalCount)
+	return results, totalCount, nil
+}
+
+// BackfillSearchIndex indexes the content of every feedback for search. Content stored after
+// search was introduced is indexed when it is written; this covers older feedbacks and repairs
+// the index after content was changed directly in MongoDB. Running it again is harmless.
+func (s *FeedbackService) BackfillSearchIndex(ctx context.Context) (int64, error) {
+	s.logger.Info("Backfilling feedback search index")
+
+	var indexed int64
+	after := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return indexed, fmt.Errorf("search index backfill interrupted: %w", err)
+		}
+
+		refs, err := s.feedbackRepo.ListRefsAfter(ctx, after, searchIndexBatchSize)
+		if err != nil {
+			return indexed, fmt.Errorf("failed to list feedbacks: %w", err)
+		}
+		if len(refs) == 0 {
+			break
+		}
+		after = refs[len(refs)-1].ID
+
+		ids := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			if !ref.Deleted {
+				ids = append(ids, ref.ID.String())
+			}
+		}
+		contents, err := s.feedbackRepo.GetContents(ctx, ids)
+		if err != nil {
+			return indexed, err
+		}
+
+		for id, content := range contents {
+			feedbackID, err := uuid.Parse(id)
+			if err != nil {
+				s.logger.Warn("Skipping feedback content with an invalid ID", "id", id)
+				continue
+			}
+			if err := s.feedbackRepo.IndexContent(ctx, feedbackID, content); err != nil {
+				return indexed, err
+			}
+			indexed++
+		}
+		s.logger.Info("Search index batch done", "after", after, "indexed", indexed)
+	}
+
+	s.logger.Info("Search index backfill finished", "indexed", indexed)
+	return indexed, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// similarTitleWindow is how far back a reviewer's feedbacks on other submissions are checked
+	similarTitleWindow = 30 * 24 * time.Hour
+	// maxSimilarCandidates bounds the number of titles compared for one check
+	maxSimilarCandidates = 200
+	// maxSimilarResults bounds the number of matches reported
+	maxSimilarResults = 10
+	// maxTitleDistance is the largest edit distance at which titles are considered similar
+	maxTitleDistance = 2
+	// shortTitleLength is the length below which only one edit is tolerated
+	shortTitleLength = 8
+)
+
+// normalizeTitle applies NFKC normalization, lower-cases a title and collapses whitespace so
+// that differences in Unicode form, casing and spacing do not count as edits
+func normalizeTitle(title string) []rune {
+	return []rune(strings.Join(strings.Fields(strings.ToLower(norm.NFKC.String(title))), " "))
+}
+
+// titleDistanceLimit returns the edit distance tolerated for a normalized title
+func titleDistanceLimit(title []rune) int {
+	if len(title) < shortTitleLength {
+		return 1
+	}
+	return maxTitleDistance
+}
+
+// titleDigits returns the digits of a title in order, e.g. "13" for "Lab 1, task 3"
+func titleDigits(title []rune) string {
+	var digits []rune
+	for _, r := range title {
+		if unicode.IsDigit(r) {
+			digits = append(digits, r)
+		}
+	}
+	return string(digits)
+}
+
+// boundedEditDistance returns the Levenshtein distance between a and b, or limit+1 once the
+// distance is known to exceed limit
+func boundedEditDistance(a, b []rune, limit int) int {
+	if len(a)-len(b) > limit || len(b)-len(a) > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// titlesSimilar reports whether two normalized titles are close enough to be confused, and their
+// edit distance. Titles that differ in their numbers ("Lab 3" and "Lab 4") are never similar.
+func titlesSimilar(a, b []rune) (int, bool) {
+	if titleDigits(a) != titleDigits(b) {
+		return 0, false
+	}
+	limit := min(titleDistanceLimit(a), titleDistanceLimit(b))
+	distance := boundedEditDistance(a, b, limit)
+	return distance, distance <= limit
+}
+
+// FindSimilarFeedbacks returns the reviewer's feedbacks whose titles closely match title: those
+// on the same submission or created in the last 30 days, closest match first. Candidates are
+// prefiltered by title length in the database and capped, so the check stays bounded.
+func (s *FeedbackService) FindSimilarFeedbacks(ctx context.Context, reviewerID, submissionID int64, title string) ([]*models.SimilarFeedback, error) {
+	if reviewerID <= 0 {
+		return nil, fmt.Errorf("invalid reviewer ID")
+	}
+	normalized := normalizeTitle(title)
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+
+	// Each edit changes the length by at most one character. The bounds apply to stored titles,
+	// so one padded with extra whitespace can be missed; that is acceptable for a warning.
+	limit := titleDistanceLimit(normalized)
+	minLength := max(len(normalized)-limit, 1)
+	maxLength := len(normalized) + limit
+	since := time.Now().Add(-similarTitleWindow)
+
+	candidates, err := s.feedbackRepo.ListTitleCandidates(ctx, reviewerID, submissionID, since, minLength, maxLength, maxSimilarCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list title candidates: %w", err)
+	}
+
+	var matches []*models.SimilarFeedback
+	for _, candidate := range candidates {
+		distance, ok := titlesSimilar(normalized, normalizeTitle(candidate.Title))
+		if !ok {
+			continue
+		}
+		candidate.Distance = distance
+		matches = append(matches, candidate)
+	}
+
+	// Closest first; candidates are already newest first, which the stable sort keeps among ties
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	if len(matches) > maxSimilarResults {
+		matches = matches[:maxSimilarResults]
+	}
+
+	s.logger.Info("Checked for similar feedback titles",
+		"reviewer_id", reviewerID,
+		"submission_id", submissionID,
+		"candidates", len(candidates),
+		"matches", len(matches),
+	)
+	return matches, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import "testing"
+
+func TestTitlesSimilar(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         string
+		wantSimilar  bool
+		wantDistance int
+	}{
+		{name: "identical", a: "Review of lab 3", b: "Review of lab 3", wantSimilar: true},
+		{name: "case only", a: "Review of Lab 3", b: "REVIEW OF LAB 3", wantSimilar: true},
+		{name: "non-ASCII case only", a: "Ошибка в отчёте", b: "ОШИБКА В ОТЧЁТЕ", wantSimilar: true},
+		{name: "repeated spaces", a: "Review of lab 3", b: "Review  of   lab 3", wantSimilar: true},
+		{name: "tabs and newlines", a: "Review of lab 3", b: "Review\tof\nlab 3", wantSimilar: true},
+		{name: "leading and trailing whitespace", a: "Review of lab 3", b: "  Review of lab 3 \n", wantSimilar: true},
+		{name: "non-breaking and ideographic spaces", a: "Review of lab 3", b: "Review\u00a0of\u3000lab 3", wantSimilar: true},
+		{name: "composed and decomposed accents", a: "R\u00e9sum\u00e9 feedback", b: "Re\u0301sume\u0301 feedback", wantSimilar: true},
+		{name: "full-width letters and digits", a: "Lab 3 review", b: "\uff2c\uff41\uff42 \uff13 review", wantSimilar: true},
+		{name: "ligature", a: "Final fixes", b: "Final \ufb01xes", wantSimilar: true},
+		{name: "case, spacing and form together", a: "Caf\u00e9 lab 3", b: "  CAFE\u0301 LAB  3", wantSimilar: true},
+		{name: "one typo", a: "Review of lab 3", b: "Reveiw of lab 3", wantSimilar: true, wantDistance: 2},
+		{name: "one edit on a short title", a: "Lab 3", b: "Lab 3!", wantSimilar: true, wantDistance: 1},
+		{name: "two edits on a short title", a: "Lab 3", b: "Lab 3!!"},
+		{name: "different numbers", a: "Review of lab 3", b: "Review of lab 4"},
+		{name: "full-width digit of another number", a: "Lab 3 review", b: "Lab \uff14 review"},
+		{name: "different accent", a: "R\u00e9sum\u00e9 feedback", b: "Re\u0300sume\u0300 feedback", wantSimilar: true, wantDistance: 2},
+		{name: "unrelated", a: "Review of lab 3", b: "Great job on lab 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance, similar := titlesSimilar(normalizeTitle(tt.a), normalizeTitle(tt.b))
+			if similar != tt.wantSimilar {
+				t.Fatalf("titlesSimilar(%q, %q) = %v, want %v", tt.a, tt.b, similar, tt.wantSimilar)
+			}
+			if similar && distance != tt.wantDistance {
+				t.Errorf("titlesSimilar(%q, %q) distance = %d, want %d", tt.a, tt.b, distance, tt.wantDistance)
+			}
+		})
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "  Review\tOF\u00a0Lab 3\n", want: "review of lab 3"},
+		{title: "RE\u0301SUME\u0301", want: "r\u00e9sum\u00e9"},
+		{title: "\uff2c\uff41\uff42\u3000\uff13", want: "lab 3"},
+		{title: " \t\n", want: ""},
+	}
+	for _, tt := range tests {
+		if got := string(normalizeTitle(tt.title)); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
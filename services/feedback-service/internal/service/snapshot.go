@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+const (
+	// maxSnapshotFieldLength is the longest accepted snapshot field, in characters
+	maxSnapshotFieldLength = 256
+
+	// maxSnapshotUpdates is the most submissions one bulk snapshot refresh may touch
+	maxSnapshotUpdates = 500
+)
+
+// ErrInvalidSnapshot is returned when a submission snapshot or snapshot refresh is malformed
+var ErrInvalidSnapshot = errors.New("invalid submission snapshot")
+
+// validateSnapshot checks the length of every snapshot field; a nil snapshot is valid
+func validateSnapshot(snapshot *models.SubmissionSnapshot) error {
+	if snapshot == nil {
+		return nil
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"lab_title", snapshot.LabTitle},
+		{"student_display_name", snapshot.StudentDisplayName},
+		{"submission_label", snapshot.SubmissionLabel},
+		{"reviewer_display_name", snapshot.ReviewerDisplayName},
+	}
+	for _, field := range fields {
+		if utf8.RuneCountInString(field.value) > maxSnapshotFieldLength {
+			return fmt.Errorf("%w: %s exceeds %d characters", ErrInvalidSnapshot, field.name, maxSnapshotFieldLength)
+		}
+	}
+	return nil
+}
+
+// UpdateSubmissionSnapshots refreshes the snapshots of all feedbacks of the given submissions
+// and returns how many feedbacks were updated. Non-empty fields replace the stored values and
+// empty fields keep them, so each upstream service can refresh only the data it owns.
+func (s *FeedbackService) UpdateSubmissionSnapshots(ctx context.Context, updates []models.SubmissionSnapshotUpdate) (int64, error) {
+	s.logger.Info("Updating submission snapshots", "submissions", len(updates))
+
+	if len(updates) == 0 {
+		return 0, fmt.Errorf("%w: no updates given", ErrInvalidSnapshot)
+	}
+	if len(updates) > maxSnapshotUpdates {
+		return 0, fmt.Errorf("%w: at most %d submissions per request", ErrInvalidSnapshot, maxSnapshotUpdates)
+	}
+	for _, update := range updates {
+		if update.SubmissionID <= 0 {
+			return 0, fmt.Errorf("%w: invalid submission ID %d", ErrInvalidSnapshot, update.SubmissionID)
+		}
+		if update.Snapshot.IsEmpty() {
+			return 0, fmt.Errorf("%w: snapshot of submission %d is empty", ErrInvalidSnapshot, update.SubmissionID)
+		}
+		if err := validateSnapshot(&update.Snapshot); err != nil {
+			return 0, err
+		}
+	}
+
+	updated, err := s.feedbackRepo.UpdateSnapshots(ctx, updates)
+	if err != nil {
+		s.logger.Error("Failed to update submission snapshots", "error", err)
+		return 0, fmt.Errorf("failed to update submission snapshots: %w", err)
+	}
+	s.prefetch.invalidateAll()
+
+	s.logger.Info("Submission snapshots updated", "submissions", len(updates), "feedbacks", updated)
+	return updated, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// maxStatsSubmissions is the most submissions listed in feedback statistics
+const maxStatsSubmissions = 100
+
+// ErrStatsTimeout is returned when feedback statistics take longer than DASHBOARD_STATS_TIMEOUT
+var ErrStatsTimeout = apperr.Unavailable("STATS_TIMEOUT", "feedback statistics took too long; try again later")
+
+// GetFeedbackStatistics aggregates the feedbacks of a reviewer, a student or both: totals,
+// feedbacks created in the last 7 and 30 days, average content length and the submissions with
+// the most feedbacks. Without a reviewer only the feedbacks the student can see are counted,
+// like ListStudentFeedbacks. The aggregation is bounded by DASHBOARD_STATS_TIMEOUT.
+func (s *FeedbackService) GetFeedbackStatistics(ctx context.Context, reviewerID, studentID *int64) (*models.FeedbackStats, error) {
+	s.logger.Info("Getting feedback statistics", "reviewer_id", reviewerID, "student_id", studentID)
+
+	if reviewerID == nil && studentID == nil {
+		return nil, apperr.InvalidField("reviewer_id", "reviewer_id or student_id is required")
+	}
+	if reviewerID != nil && *reviewerID <= 0 {
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if studentID != nil && *studentID <= 0 {
+		return nil, apperr.InvalidField("student_id", "invalid student ID")
+	}
+
+	now := time.Now()
+	filter := models.FeedbackStatsFilter{
+		ReviewerID:     reviewerID,
+		StudentID:      studentID,
+		VisibleOnly:    reviewerID == nil,
+		Since7Days:     now.AddDate(0, 0, -7),
+		Since30Days:    now.AddDate(0, 0, -30),
+		MaxSubmissions: maxStatsSubmissions,
+	}
+
+	statsCtx, cancel := context.WithTimeout(ctx, s.dashboard.cfg.StatsTimeout)
+	defer cancel()
+	stats, err := s.feedbackRepo.Stats(statsCtx, filter)
+	if err != nil {
+		if errors.Is(statsCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			s.logger.Warn("Feedback statistics timed out", "reviewer_id", reviewerID, "student_id", studentID, "timeout", s.dashboard.cfg.StatsTimeout)
+			return nil, ErrStatsTimeout.Wrap(err)
+		}
+		s.logger.Error("Failed to get feedback statistics", "reviewer_id", reviewerID, "student_id", studentID, "error", err)
+		return nil, fmt.Errorf("failed to get feedback statistics: %w", err)
+	}
+
+	s.logger.Info("Feedback statistics retrieved successfully", "total", stats.Total, "submissions", stats.SubmissionCount)
+	return stats, nil
+}
+
+// GetStudentFeedbackSummary summarizes the feedback a student can see: per submission and
+// overall, the number of feedbacks, how many are unread, when the latest was published and the
+// average grade. Drafts, feedback awaiting approval and deleted feedback are left out. The
+// aggregation is bounded by DASHBOARD_STATS_TIMEOUT.
+func (s *FeedbackService) GetStudentFeedbackSummary(ctx context.Context, studentID int64) (*models.StudentFeedbackSummary, error) {
+	s.logger.Info("Getting student feedback summary", "student_id", studentID)
+
+	if studentID <= 0 {
+		return nil, apperr.InvalidField("student_id", "invalid student ID")
+	}
+
+	summaryCtx, cancel := context.WithTimeout(ctx, s.dashboard.cfg.StatsTimeout)
+	defer cancel()
+	summary, err := s.feedbackRepo.StudentSummary(summaryCtx, studentID)
+	if err != nil {
+		if errors.Is(summaryCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			s.logger.Warn("Student feedback summary timed out", "student_id", studentID, "timeout", s.dashboard.cfg.StatsTimeout)
+			return nil, ErrStatsTimeout.Wrap(err)
+		}
+		s.logger.Error("Failed to get student feedback summary", "student_id", studentID, "error", err)
+		return nil, fmt.Errorf("failed to get student feedback summary: %w", err)
+	}
+
+	s.logger.Info("Student feedback summary retrieved successfully", "student_id", studentID, "feedbacks", summary.FeedbackCount, "submissions", len(summary.Submissions))
+	return summary, nil
+}
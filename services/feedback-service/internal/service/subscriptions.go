@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/render"
+)
+
+// SubscribeSideEffects registers the side effects that react to service events: evicting
+// dashboard rows and rendered comments, and feeding SubscribeFeedbackEvents streams. The
+// service binary and the conformance test server both call it, so tests see the same
+// side effects as production.
+func SubscribeSideEffects(events *bus.Bus, feedbackService *FeedbackService, renderer *render.Renderer) error {
+	dashboard := bus.SubscribeOptions{Name: "dashboard-cache", Policy: bus.DropNewest}
+	forgetRow := func(ctx context.Context, event bus.AttachmentEvent) {
+		feedbackService.ForgetDashboardRow(event.FeedbackID)
+	}
+	if _, err := bus.Subscribe(events, bus.AttachmentUploaded, dashboard, forgetRow); err != nil {
+		return err
+	}
+	if _, err := bus.Subscribe(events, bus.AttachmentDeleted, dashboard, forgetRow); err != nil {
+		return err
+	}
+
+	// The hub never blocks, so its subscriptions keep up and must not lose events
+	studentEvents := bus.SubscribeOptions{Name: "student-feedback-events", Policy: bus.Block}
+	for topic, kind := range map[bus.Topic[bus.FeedbackEvent]]string{
+		bus.FeedbackCreated:   models.FeedbackEventCreated,
+		bus.FeedbackUpdated:   models.FeedbackEventUpdated,
+		bus.FeedbackPublished: models.FeedbackEventPublished,
+		bus.FeedbackApproved:  models.FeedbackEventPublished,
+	} {
+		if _, err := bus.Subscribe(events, topic, studentEvents, func(ctx context.Context, event bus.FeedbackEvent) {
+			feedbackService.PushFeedbackEvent(kind, event.Feedback)
+		}); err != nil {
+			return err
+		}
+	}
+	if _, err := bus.Subscribe(events, bus.AttachmentUploadProgress, studentEvents, func(ctx context.Context, event bus.AttachmentProgressEvent) {
+		feedbackService.PushUploadProgress(event.Feedback, event.Progress)
+	}); err != nil {
+		return err
+	}
+
+	forget := bus.SubscribeOptions{Name: "comment-render-cache", Policy: bus.DropNewest}
+	if _, err := bus.Subscribe(events, bus.CommentUpdated, forget, func(ctx context.Context, event bus.CommentEvent) {
+		renderer.Forget(event.Comment.ID.Hex())
+	}); err != nil {
+		return err
+	}
+	if _, err := bus.Subscribe(events, bus.CommentDeleted, forget, func(ctx context.Context, event bus.CommentDeletedEvent) {
+		renderer.Forget(event.CommentID)
+	}); err != nil {
+		return err
+	}
+	return nil
+}
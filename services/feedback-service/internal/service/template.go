@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// maxTemplateNameLength is the longest accepted template name, in characters
+// (feedback_templates.name is VARCHAR(100))
+const maxTemplateNameLength = 100
+
+// TemplateService manages the feedback templates of reviewers. Every template belongs to the
+// reviewer who created it; no one else can see, use or change it.
+type TemplateService struct {
+	repo   repository.TemplateRepository
+	limits config.FeedbackConfig // Templates fit the feedback they pre-fill
+	logger *slog.Logger
+}
+
+// NewTemplateService creates a new feedback template service
+func NewTemplateService(repo repository.TemplateRepository, limits config.FeedbackConfig, logger *slog.Logger) *TemplateService {
+	return &TemplateService{
+		repo:   repo,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+// validateTemplate checks the name, title and content of a template. The title and content
+// follow the feedback rules; a template must pre-fill at least one of them.
+func validateTemplate(template *models.FeedbackTemplate, limits config.FeedbackConfig) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return apperr.InvalidField("name", "name is required")
+	}
+	if utf8.RuneCountInString(template.Name) > maxTemplateNameLength {
+		return apperr.InvalidField("name", fmt.Sprintf("name must be at most %d characters", maxTemplateNameLength))
+	}
+	if template.Title != "" {
+		if err := validateTitle(template.Title, limits); err != nil {
+			return err
+		}
+	}
+	if err := CheckTextLimits(limits, nil, &template.Content); err != nil {
+		return err
+	}
+	if template.Title == "" && strings.TrimSpace(template.Content) == "" {
+		return apperr.InvalidField("content", "a template needs a title or content")
+	}
+	return nil
+}
+
+// CreateTemplate stores a new template for a reviewer
+func (s *TemplateService) CreateTemplate(ctx context.Context, template *models.FeedbackTemplate) error {
+	s.logger.Info("Creating feedback template", "reviewer_id", template.ReviewerID, "name", template.Name)
+
+	if template.ReviewerID <= 0 {
+		return apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if err := validateTemplate(template, s.limits); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, template); err != nil {
+		s.logger.Error("Failed to create feedback template", "reviewer_id", template.ReviewerID, "error", err)
+		return fmt.Errorf("failed to create feedback template: %w", err)
+	}
+
+	s.logger.Info("Feedback template created", "template_id", template.ID, "reviewer_id", template.ReviewerID)
+	return nil
+}
+
+// ListTemplates lists a reviewer's templates by name
+func (s *TemplateService) ListTemplates(ctx context.Context, reviewerID int64, page, limit int32) ([]*models.FeedbackTemplate, int32, error) {
+	s.logger.Info("Listing feedback templates", "reviewer_id", reviewerID, "page", page, "limit", limit)
+
+	if reviewerID <= 0 {
+		return nil, 0, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	templates, totalCount, err := s.repo.ListByReviewer(ctx, reviewerID, page, limit)
+	if err != nil {
+		s.logger.Error("Failed to list feedback templates", "reviewer_id", reviewerID, "error", err)
+		return nil, 0, fmt.Errorf("failed to list feedback templates: %w", err)
+	}
+	return templates, totalCount, nil
+}
+
+// ownedTemplate returns a template if it belongs to the reviewer
+func (s *TemplateService) ownedTemplate(ctx context.Context, id uuid.UUID, reviewerID int64) (*models.FeedbackTemplate, error) {
+	if id == uuid.Nil {
+		return nil, apperr.InvalidField("template_id", "invalid template ID")
+	}
+	if reviewerID <= 0 {
+		return nil, apperr.InvalidField("reviewer_id", "invalid reviewer ID")
+	}
+
+	template, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback template: %w", err)
+	}
+	if err := authz.UseTemplate(authz.Principal{UserID: reviewerID}, template); err != nil {
+		s.logger.Warn("Feedback template access denied", "template_id", id, "attempted_by_id", reviewerID)
+		return nil, err
+	}
+	return template, nil
+}
+
+// UpdateTemplate replaces the name, title and content of a reviewer's template
+func (s *TemplateService) UpdateTemplate(ctx context.Context, update *models.FeedbackTemplate) (*models.FeedbackTemplate, error) {
+	s.logger.Info("Updating feedback template", "template_id", update.ID, "reviewer_id", update.ReviewerID)
+
+	template, err := s.ownedTemplate(ctx, update.ID, update.ReviewerID)
+	if err != nil {
+		return nil, err
+	}
+	template.Name = update.Name
+	template.Title = update.Title
+	template.Content = update.Content
+	if err := validateTemplate(template, s.limits); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, template); err != nil {
+		s.logger.Error("Failed to update feedback template", "template_id", template.ID, "error", err)
+		return nil, fmt.Errorf("failed to update feedback template: %w", err)
+	}
+
+	s.logger.Info("Feedback template updated", "template_id", template.ID)
+	return template, nil
+}
+
+// DeleteTemplate deletes a reviewer's template. Feedback pre-filled from it is not affected.
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id uuid.UUID, reviewerID int64) error {
+	s.logger.Info("Deleting feedback template", "template_id", id, "reviewer_id", reviewerID)
+
+	if _, err := s.ownedTemplate(ctx, id, reviewerID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete feedback template", "template_id", id, "error", err)
+		return fmt.Errorf("failed to delete feedback template: %w", err)
+	}
+
+	s.logger.Info("Feedback template deleted", "template_id", id)
+	return nil
+}
+
+// Prefill fills the empty title and content of new feedback from a reviewer's template.
+// Fields set explicitly are kept.
+func (s *TemplateService) Prefill(ctx context.Context, reviewerID int64, templateID uuid.UUID, title, content string) (string, string, error) {
+	template, err := s.ownedTemplate(ctx, templateID, reviewerID)
+	if err != nil {
+		return title, content, err
+	}
+	if title == "" {
+		title = template.Title
+	}
+	if content == "" {
+		content = template.Content
+	}
+	return title, content, nil
+}
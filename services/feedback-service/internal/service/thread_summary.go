@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+// FeedbackThreadSummaries summarizes the comment threads of a page of feedbacks with one
+// aggregation, classifying comments by each feedback's reviewer and student. Every feedback has
+// a summary, empty without comments. Summaries only decorate a read, so when comments are
+// unavailable the failure is logged and nil is returned instead.
+func (s *FeedbackService) FeedbackThreadSummaries(ctx context.Context, feedbacks []*models.Feedback) map[uuid.UUID]*models.FeedbackThreadSummary {
+	if s.commentRepo == nil || len(feedbacks) == 0 {
+		return nil
+	}
+
+	threads := make([]models.FeedbackThread, len(feedbacks))
+	for i, feedback := range feedbacks {
+		threads[i] = models.FeedbackThread{
+			FeedbackID: feedback.ID.String(),
+			ReviewerID: feedback.ReviewerID,
+			StudentID:  feedback.StudentID,
+		}
+	}
+	found, err := s.commentRepo.SummarizeFeedbackThreads(ctx, threads)
+	if err != nil {
+		s.logger.Warn("Failed to summarize feedback comment threads", "feedbacks", len(feedbacks), "error", err)
+		return nil
+	}
+
+	summaries := make(map[uuid.UUID]*models.FeedbackThreadSummary, len(feedbacks))
+	for _, feedback := range feedbacks {
+		summary := found[feedback.ID.String()]
+		if summary == nil {
+			summary = &models.FeedbackThreadSummary{}
+		}
+		summaries[feedback.ID] = summary
+	}
+	return summaries
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository"
+)
+
+// TransferDirection identifies whether bytes were uploaded or downloaded
+type TransferDirection int
+
+const (
+	TransferUpload TransferDirection = iota
+	TransferDownload
+)
+
+// ErrTransferQuotaExceeded is returned when a transfer would exceed the hard daily cap
+var ErrTransferQuotaExceeded = errors.New("daily transfer quota exceeded")
+
+// transferKey identifies an in-memory counter
+type transferKey struct {
+	principalID int64
+	day         time.Time
+}
+
+// TransferAccountant tracks bytes transferred per principal per UTC day.
+// Counters are kept in memory and flushed to PostgreSQL periodically, so a crash
+// loses at most one flush interval of accounting.
+type TransferAccountant struct {
+	repo   repository.TransferUsageRepository
+	cfg    config.TransferConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[transferKey]*models.TransferUsage
+}
+
+// NewTransferAccountant creates a new transfer accountant
+func NewTransferAccountant(repo repository.TransferUsageRepository, cfg config.TransferConfig, logger *slog.Logger) *TransferAccountant {
+	return &TransferAccountant{
+		repo:    repo,
+		cfg:     cfg,
+		logger:  logger,
+		now:     time.Now,
+		pending: make(map[transferKey]*models.TransferUsage),
+	}
+}
+
+// utcDay truncates a timestamp to the start of its UTC day
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Check verifies that transferring size more bytes keeps the principal within the daily caps
+func (a *TransferAccountant) Check(ctx context.Context, principalID, size int64) error {
+	if principalID <= 0 || (a.cfg.SoftDailyBytes == 0 && a.cfg.HardDailyBytes == 0) {
+		return nil
+	}
+
+	used, err := a.usedToday(ctx, principalID)
+	if err != nil {
+		// Accounting must not take down transfers, so fail open
+		a.logger.Error("Failed to read transfer usage, allowing transfer", "principal_id", principalID, "error", err)
+		return nil
+	}
+
+	total := used + size
+	if a.cfg.HardDailyBytes > 0 && total > a.cfg.HardDailyBytes {
+		a.logger.Warn("Transfer rejected: hard daily cap exceeded",
+			"principal_id", principalID,
+			"used_bytes", used,
+			"requested_bytes", size,
+			"hard_cap_bytes", a.cfg.HardDailyBytes,
+		)
+		return fmt.Errorf("%w: %d of %d bytes already used today", ErrTransferQuotaExceeded, used, a.cfg.HardDailyBytes)
+	}
+	if a.cfg.SoftDailyBytes > 0 && total > a.cfg.SoftDailyBytes {
+		a.logger.Warn("Soft daily transfer cap exceeded",
+			"principal_id", principalID,
+			"used_bytes", used,
+			"requested_bytes", size,
+			"soft_cap_bytes", a.cfg.SoftDailyBytes,
+		)
+	}
+
+	return nil
+}
+
+// Record adds transferred bytes to the principal's counter for the current day
+func (a *TransferAccountant) Record(principalID int64, direction TransferDirection, size int64) {
+	if principalID <= 0 || size <= 0 {
+		return
+	}
+
+	key := transferKey{principalID: principalID, day: utcDay(a.now())}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	usage, ok := a.pending[key]
+	if !ok {
+		usage = &models.TransferUsage{PrincipalID: key.principalID, Day: key.day}
+		a.pending[key] = usage
+	}
+	switch direction {
+	case TransferUpload:
+		usage.BytesUploaded += size
+	case TransferDownload:
+		usage.BytesDownloaded += size
+	}
+}
+
+// Flush writes the in-memory counters to PostgreSQL.
+// On failure the counters are merged back so they are retried on the next flush.
+func (a *TransferAccountant) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[transferKey]*models.TransferUsage)
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	deltas := make([]*models.TransferUsage, 0, len(pending))
+	for _, usage := range pending {
+		deltas = append(deltas, usage)
+	}
+
+	if err := a.repo.AddUsage(ctx, deltas); err != nil {
+		a.mu.Lock()
+		for key, usage := range pending {
+			if current, ok := a.pending[key]; ok {
+				current.BytesUploaded += usage.BytesUploaded
+				current.BytesDownloaded += usage.BytesDownloaded
+			} else {
+				a.pending[key] = usage
+			}
+		}
+		a.mu.Unlock()
+		return fmt.Errorf("failed to flush transfer usage: %w", err)
+	}
+
+	return nil
+}
+
+// Run flushes counters every flush interval until the context is cancelled
+func (a *TransferAccountant) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				a.logger.Error("Transfer usage flush failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// GetUsage returns daily usage of a principal between since and until (inclusive days)
+func (a *TransferAccountant) GetUsage(ctx context.Context, principalID int64, since, until time.Time) ([]*models.TransferUsage, error) {
+	if principalID <= 0 {
+		return nil, fmt.Errorf("invalid principal ID")
+	}
+	if since.After(until) {
+		return nil, fmt.Errorf("since must not be after until")
+	}
+
+	// Flush first so the answer includes bytes not yet persisted
+	if err := a.Flush(ctx); err != nil {
+		a.logger.Warn("Transfer usage flush before read failed", "error", err)
+	}
+
+	usage, err := a.repo.GetUsage(ctx, principalID, utcDay(since), utcDay(until))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// GetLeaderboard returns the principals with the most bytes transferred between since and until
+func (a *TransferAccountant) GetLeaderboard(ctx context.Context, since, until time.Time, limit int) ([]*models.TransferUsage, error) {
+	if since.After(until) {
+		return nil, fmt.Errorf("since must not be after until")
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	if err := a.Flush(ctx); err != nil {
+		a.logger.Warn("Transfer usage flush before read failed", "error", err)
+	}
+
+	usage, err := a.repo.TopPrincipals(ctx, utcDay(since), utcDay(until), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer leaderboard: %w", err)
+	}
+
+	return usage, nil
+}
+
+// usedToday returns persisted plus pending bytes transferred by a principal today
+func (a *TransferAccountant) usedToday(ctx context.Context, principalID int64) (int64, error) {
+	day := utcDay(a.now())
+
+	usage, err := a.repo.GetUsage(ctx, principalID, day, day)
+	if err != nil {
+		return 0, err
+	}
+
+	var used int64
+	for _, u := range usage {
+		used += u.BytesUploaded + u.BytesDownloaded
+	}
+
+	a.mu.Lock()
+	if pending, ok := a.pending[transferKey{principalID: principalID, day: day}]; ok {
+		used += pending.BytesUploaded + pending.BytesDownloaded
+	}
+	a.mu.Unlock()
+
+	return used, nil
+}
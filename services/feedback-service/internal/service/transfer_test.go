@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/repository/repotest"
+)
+
+// fakeClock is a time source moved by the test
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// newTestAccountant returns an accountant on an in-memory store with its clock at now
+func newTestAccountant(cfg config.TransferConfig, now time.Time) (*TransferAccountant, *fakeClock) {
+	clock := &fakeClock{now: now}
+	a := NewTransferAccountant(repotest.New().Transfers(), cfg, slog.New(slog.DiscardHandler))
+	a.now = clock.Now
+	return a, clock
+}
+
+// usageRows returns the stored daily counters of a principal from since to until
+func usageRows(t *testing.T, a *TransferAccountant, principalID int64, since, until time.Time) []models.TransferUsage {
+	t.Helper()
+	usage, err := a.GetUsage(context.Background(), principalID, since, until)
+	if err != nil {
+		t.Fatalf("GetUsage() error = %v", err)
+	}
+	rows := make([]models.TransferUsage, len(usage))
+	for i, u := range usage {
+		rows[i] = *u
+	}
+	return rows
+}
+
+func TestTransferAccountantMidnight(t *testing.T) {
+	const principal = 7
+	day1 := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	// UTC+3: 01:30 on March 2 local time is still March 1 in UTC
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name  string
+		steps func(t *testing.T, a *TransferAccountant, clock *fakeClock)
+		want  []models.TransferUsage
+	}{
+		{
+			name: "counts either side of midnight",
+			steps: func(t *testing.T, a *TransferAccountant, clock *fakeClock) {
+				clock.Set(day2.Add(-2 * time.Second))
+				a.Record(principal, TransferUpload, 100)
+				clock.Set(day2.Add(-time.Nanosecond))
+				a.Record(principal, TransferDownload, 50)
+				clock.Set(day2)
+				a.Record(principal, TransferUpload, 30)
+			},
+			want: []models.TransferUsage{
+				{PrincipalID: principal, Day: day1, BytesUploaded: 100, BytesDownloaded: 50},
+				{PrincipalID: principal, Day: day2, BytesUploaded: 30},
+			},
+		},
+		{
+			name: "local time past midnight is the UTC day before",
+			steps: func(t *testing.T, a *TransferAccountant, clock *fakeClock) {
+				clock.Set(time.Date(2025, 3, 2, 1, 30, 0, 0, moscow))
+				a.Record(principal, TransferDownload, 10)
+				clock.Set(time.Date(2025, 3, 2, 3, 0, 0, 0, moscow))
+				a.Record(principal, TransferDownload, 20)
+			},
+			want: []models.TransferUsage{
+				{PrincipalID: principal, Day: day1, BytesDownloaded: 10},
+				{PrincipalID: principal, Day: day2, BytesDownloaded: 20},
+			},
+		},
+		{
+			name: "flush before and after midnight",
+			steps: func(t *testing.T, a *TransferAccountant, clock *fakeClock) {
+				clock.Set(day2.Add(-time.Minute))
+				a.Record(principal, TransferUpload, 1)
+				if err := a.Flush(context.Background()); err != nil {
+					t.Fatalf("Flush() error = %v", err)
+				}
+				// Recorded after the flush but before midnight: added to the same row
+				a.Record(principal, TransferUpload, 2)
+				clock.Set(day2.Add(time.Minute))
+				a.Record(principal, TransferUpload, 4)
+			},
+			want: []models.TransferUsage{
+				{PrincipalID: principal, Day: day1, BytesUploaded: 3},
+				{PrincipalID: principal, Day: day2, BytesUploaded: 4},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, clock := newTestAccountant(config.TransferConfig{FlushInterval: time.Hour}, day1)
+			tt.steps(t, a, clock)
+			if err := a.Flush(context.Background()); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			got := usageRows(t, a, principal, day1, day2)
+			if len(got) != len(tt.want) {
+				t.Fatalf("GetUsage() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Day.Equal(tt.want[i].Day) || got[i].BytesUploaded != tt.want[i].BytesUploaded || got[i].BytesDownloaded != tt.want[i].BytesDownloaded {
+					t.Errorf("GetUsage()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTransferAccountantRunAcrossMidnight(t *testing.T) {
+	const principal = 7
+	midnight := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	a, clock := newTestAccountant(config.TransferConfig{FlushInterval: time.Millisecond}, midnight.Add(-time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// waitStored waits until Run has written the given download counts, one per day
+	waitStored := func(want ...int64) []models.TransferUsage {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got := usageRows(t, a, principal, midnight.AddDate(0, 0, -1), midnight)
+			if len(got) == len(want) {
+				matched := true
+				for i := range got {
+					matched = matched && got[i].BytesDownloaded == want[i]
+				}
+				if matched {
+					return got
+				}
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("GetUsage() = %+v, want downloads %v", got, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	a.Record(principal, TransferDownload, 500)
+	waitStored(500)
+	clock.Set(midnight)
+	a.Record(principal, TransferDownload, 70)
+	if got := waitStored(500, 70); !got[1].Day.Equal(midnight) {
+		t.Errorf("GetUsage() = %+v, want the 70 bytes on March 2", got)
+	}
+}
+
+func TestTransferAccountantQuotaResetsAtMidnight(t *testing.T) {
+	const principal = 7
+	midnight := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	a, clock := newTestAccountant(config.TransferConfig{HardDailyBytes: 100, FlushInterval: time.Hour}, midnight.Add(-time.Second))
+	ctx := context.Background()
+
+	a.Record(principal, TransferUpload, 60)
+	if err := a.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	a.Record(principal, TransferDownload, 30)
+	if err := a.Check(ctx, principal, 20); !errors.Is(err, ErrTransferQuotaExceeded) {
+		t.Fatalf("Check() before midnight error = %v, want %v", err, ErrTransferQuotaExceeded)
+	}
+
+	clock.Set(midnight)
+	if err := a.Check(ctx, principal, 20); err != nil {
+		t.Errorf("Check() after midnight error = %v, want the new day's quota", err)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// Paths of UpdateFeedbackRequest.update_mask
+const (
+	updatePathTitle   = "title"
+	updatePathContent = "content"
+	updatePathGrade   = "grade"
+	updatePathRubric  = "rubric_items"
+
+	updatePathRequiresResubmission = "requires_resubmission"
+	updatePathResubmissionDeadline = "resubmission_deadline"
+)
+
+// updatePaths are the feedback fields an update mask may name
+var updatePaths = []string{updatePathTitle, updatePathContent, updatePathGrade, updatePathRubric, updatePathRequiresResubmission, updatePathResubmissionDeadline}
+
+// validateUpdateMask rejects masks with unknown paths and masks that would clear the title
+func validateUpdateMask(mask []string, title *string) error {
+	for _, path := range mask {
+		if !slices.Contains(updatePaths, path) {
+			return apperr.InvalidField("update_mask", fmt.Sprintf("unknown update_mask path %q; allowed paths are title, content, grade, rubric_items, requires_resubmission and resubmission_deadline", path))
+		}
+	}
+	if slices.Contains(mask, updatePathTitle) && title == nil {
+		return apperr.InvalidField("title", "title is required and cannot be cleared")
+	}
+	return nil
+}
+
+// applyUpdateMask replaces the fields named in the mask with the given values, clearing those
+// that are not set. Clearing requires_resubmission also clears the deadline unless the mask
+// names it.
+func applyUpdateMask(feedback *models.Feedback, req models.FeedbackUpdateRequest) {
+	for _, path := range req.Mask {
+		switch path {
+		case updatePathTitle:
+			feedback.Title = *req.Title
+		case updatePathContent:
+			feedback.Content = ""
+			if req.Content != nil {
+				feedback.Content = *req.Content
+			}
+		case updatePathGrade:
+			feedback.Grade = req.Grade
+		case updatePathRubric:
+			feedback.RubricItems = req.RubricItems
+		case updatePathRequiresResubmission:
+			feedback.RequiresResubmission = req.RequiresResubmission != nil && *req.RequiresResubmission
+			if !feedback.RequiresResubmission && !slices.Contains(req.Mask, updatePathResubmissionDeadline) {
+				feedback.ResubmissionDeadline = nil
+			}
+		case updatePathResubmissionDeadline:
+			feedback.ResubmissionDeadline = req.ResubmissionDeadline
+		}
+	}
+}
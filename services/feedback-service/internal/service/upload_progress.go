@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+)
+
+// uploadMilestones are the percentages reported while an upload is in flight
+var uploadMilestones = []int32{25, 50, 75}
+
+// uploadProgress counts the bytes read from an attachment upload and publishes its progress.
+// Milestones come at most once per interval, so the streams of a feedback are not flooded by
+// fast uploads; the start and the outcome of an upload are always reported. It is read by one
+// goroutine, the one storing the upload.
+type uploadProgress struct {
+	ctx      context.Context
+	events   *bus.Bus
+	feedback *models.Feedback
+	filename string
+	total    int64
+	interval time.Duration
+
+	data      io.Reader
+	received  int64
+	milestone int // Index of the next milestone in uploadMilestones
+	lastSent  time.Time
+}
+
+// trackUploadProgress publishes the start of an upload and returns a reader that reports its
+// progress as data is read. Call finish with the outcome once the upload is stored or failed.
+func (s *FeedbackService) trackUploadProgress(ctx context.Context, feedback *models.Feedback, filename string, data io.Reader, size int64) *uploadProgress {
+	p := &uploadProgress{
+		ctx:      ctx,
+		events:   s.events,
+		feedback: feedback,
+		filename: filename,
+		total:    size,
+		interval: s.progressEvery,
+		data:     data,
+	}
+	p.publish(models.UploadStageStarted, 0)
+	return p
+}
+
+func (p *uploadProgress) Read(b []byte) (int, error) {
+	n, err := p.data.Read(b)
+	p.received += int64(n)
+
+	// Milestones passed while rate limited are folded into the next event
+	passed := -1
+	for p.milestone < len(uploadMilestones) && p.received*100 >= int64(uploadMilestones[p.milestone])*p.total {
+		passed = p.milestone
+		p.milestone++
+	}
+	if passed >= 0 && time.Since(p.lastSent) >= p.interval {
+		p.publish(models.UploadStageProgress, uploadMilestones[passed])
+	}
+	return n, err
+}
+
+// finish reports the outcome of the upload
+func (p *uploadProgress) finish(err error) {
+	if err != nil {
+		var percent int32
+		if p.milestone > 0 {
+			percent = uploadMilestones[p.milestone-1]
+		}
+		p.publish(models.UploadStageFailed, percent)
+		return
+	}
+	p.publish(models.UploadStageCompleted, 100)
+}
+
+func (p *uploadProgress) publish(stage string, percent int32) {
+	p.lastSent = time.Now()
+	bus.Publish(p.ctx, p.events, bus.AttachmentUploadProgress, bus.AttachmentProgressEvent{
+		Feedback: p.feedback,
+		Progress: models.UploadProgress{
+			Filename:      p.filename,
+			Stage:         stage,
+			Percent:       percent,
+			BytesReceived: p.received,
+			TotalBytes:    p.total,
+		},
+	})
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/authz"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/validation"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUploadSessionClosed is returned when files are added to, or a commit or abort is
+	// requested for, a session that no longer allows it
+	ErrUploadSessionClosed = errors.New("upload session is closed")
+
+	// ErrUploadSessionIncomplete is returned when a session is committed before every file finished uploading
+	ErrUploadSessionIncomplete = errors.New("upload session has incomplete files")
+
+	// ErrAttachmentLimitExceeded is returned when committing a session would exceed the per-feedback attachment limits
+	ErrAttachmentLimitExceeded = errors.New("attachment limit exceeded")
+)
+
+// getOwnedSession loads an upload session and checks that it belongs to the reviewer
+func (s *FeedbackService) getOwnedSession(ctx context.Context, sessionID uuid.UUID, reviewerID int64) (*models.UploadSession, error) {
+	if sessionID == uuid.Nil {
+		return nil, fmt.Errorf("invalid upload session ID")
+	}
+	if reviewerID <= 0 {
+		return nil, fmt.Errorf("invalid reviewer ID")
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+	if session.ReviewerID != reviewerID {
+		s.logger.Warn("Access denied to upload session",
+			"session_id", sessionID,
+			"owner_id", session.ReviewerID,
+			"attempted_by_id", reviewerID,
+		)
+		return nil, apperr.PermissionDenied("NOT_SESSION_OWNER", "access denied: only the session owner can use it")
+	}
+
+	return session, nil
+}
+
+// CreateUploadSession opens a session for attaching several files to a feedback at once
+func (s *FeedbackService) CreateUploadSession(ctx context.Context, feedbackID uuid.UUID, reviewerID int64) (*models.UploadSession, error) {
+	s.logger.Info("Creating upload session", "feedback_id", feedbackID, "reviewer_id", reviewerID)
+
+	if feedbackID == uuid.Nil {
+		return nil, fmt.Errorf("invalid feedback ID")
+	}
+	if reviewerID <= 0 {
+		return nil, fmt.Errorf("invalid reviewer ID")
+	}
+
+	feedback, err := s.feedbackRepo.GetByID(ctx, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	if err := authz.ChangeAttachments(authz.Principal{UserID: reviewerID}, feedback); err != nil {
+		s.logger.Warn("Upload session creation denied",
+			"feedback_id", feedbackID,
+			"owner_id", feedback.ReviewerID,
+			"attempted_by_id", reviewerID,
+			"error", err,
+		)
+		return nil, err
+	}
+
+	session := &models.UploadSession{
+		FeedbackID: feedbackID,
+		ReviewerID: reviewerID,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		s.logger.Error("Failed to create upload session", "feedback_id", feedbackID, "error", err)
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	s.logger.Info("Upload session created", "session_id", session.ID, "feedback_id", feedbackID)
+	return session, nil
+}
+
+// UploadSessionFile streams a file into the staging area of an open session. The file is
+// recorded as incomplete first, so a failed upload blocks the commit until it is retried.
+func (s *FeedbackService) UploadSessionFile(ctx context.Context, sessionID, feedbackID uuid.UUID, reviewerID int64, filename, contentType string, data io.Reader, size int64) error {
+	s.logger.Info("Uploading upload session file",
+		"session_id", sessionID,
+		"filename", validation.LogValue(filename),
+		"size", size,
+	)
+
+	if filename == "" {
+		return fmt.Errorf("filename is required")
+	}
+	if size < 0 {
+		return fmt.Errorf("invalid file size")
+	}
+
+	session, err := s.getOwnedSession(ctx, sessionID, reviewerID)
+	if err != nil {
+		return err
+	}
+	if session.FeedbackID != feedbackID {
+		return fmt.Errorf("upload session belongs to a different feedback")
+	}
+	if session.Status != models.UploadSessionOpen {
+		return fmt.Errorf("%w: session is %s", ErrUploadSessionClosed, session.Status)
+	}
+
+	file := &models.UploadSessionFile{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+	}
+	if err := s.sessionRepo.UpsertFile(ctx, sessionID, file); err != nil {
+		return err
+	}
+
+	data, checksum := contentHash(data)
+	if err := s.attachmentRepo.UploadStaged(ctx, sessionID, session.FeedbackID, filename, contentType, data, size); err != nil {
+		s.logger.Error("Failed to stage upload session file",
+			"session_id", sessionID,
+			"filename", validation.LogValue(filename),
+			"error", err,
+		)
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	file.Completed = true
+	file.SHA256 = checksum()
+	if err := s.sessionRepo.UpsertFile(ctx, sessionID, file); err != nil {
+		return err
+	}
+
+	s.logger.Info("Upload session file staged", "session_id", sessionID, "filename", validation.LogValue(filename))
+	return nil
+}
+
+// checkSessionLimits checks that the feedback's attachments stay within the per-feedback
+// count and size limits of its course once the staged files are added; staged files replace
+// existing attachments with the same name
+func (s *FeedbackService) checkSessionLimits(ctx context.Context, session *models.UploadSession, courseID string) error {
+	policy, err := s.policies.Resolve(ctx, courseID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.attachmentRepo.List(ctx, session.FeedbackID)
+	if err != nil {
+		return fmt.Errorf("failed to list existing attachments: %w", err)
+	}
+
+	sizes := make(map[string]int64, len(existing)+len(session.Files))
+	for _, attachment := range existing {
+		sizes[attachment.Filename] = attachment.Size
+	}
+	for _, file := range session.Files {
+		sizes[file.Filename] = file.Size
+	}
+
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+
+	if len(sizes) > policy.MaxAttachmentsPerFeedback {
+		return fmt.Errorf("%w: %d attachments, maximum %d allowed per feedback", ErrAttachmentLimitExceeded, len(sizes), policy.MaxAttachmentsPerFeedback)
+	}
+	if total > policy.MaxAttachmentBytesPerFeedback {
+		return fmt.Errorf("%w: %d bytes, maximum %d allowed per feedback", ErrAttachmentLimitExceeded, total, policy.MaxAttachmentBytesPerFeedback)
+	}
+	return nil
+}
+
+// CommitUploadSession attaches every staged file of a session to its feedback. Nothing is
+// promoted unless all files completed and the combined limits pass. Promotion is retry-safe:
+// committing a session again after a partial failure promotes the remaining files, and
+// committing an already committed session succeeds without changes.
+func (s *FeedbackService) CommitUploadSession(ctx context.Context, sessionID uuid.UUID, reviewerID int64) (*models.UploadSession, error) {
+	s.logger.Info("Committing upload session", "session_id", sessionID, "reviewer_id", reviewerID)
+
+	session, err := s.getOwnedSession(ctx, sessionID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch session.Status {
+	case models.UploadSessionCommitted:
+		return session, nil
+	case models.UploadSessionAborted:
+		return nil, fmt.Errorf("%w: session is %s", ErrUploadSessionClosed, session.Status)
+	case models.UploadSessionOpen:
+		if len(session.Files) == 0 {
+			return nil, fmt.Errorf("%w: no files were uploaded", ErrUploadSessionIncomplete)
+		}
+		for _, file := range session.Files {
+			if !file.Completed {
+				return nil, fmt.Errorf("%w: %s", ErrUploadSessionIncomplete, file.Filename)
+			}
+		}
+
+		feedback, err := s.feedbackRepo.GetByID(ctx, session.FeedbackID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get feedback: %w", err)
+		}
+		if err := authz.Unlocked(feedback); err != nil {
+			return nil, err
+		}
+		if err := s.checkSessionLimits(ctx, session, feedback.CourseID); err != nil {
+			return nil, err
+		}
+
+		ok, err := s.sessionRepo.SetStatus(ctx, sessionID, models.UploadSessionOpen, models.UploadSessionCommitting)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: session changed concurrently", ErrUploadSessionClosed)
+		}
+		session.Status = models.UploadSessionCommitting
+	}
+
+	// From here on the session is committing and every step is safe to repeat
+	for _, file := range session.Files {
+		if file.Promoted {
+			continue
+		}
+		if err := s.attachmentRepo.PromoteStaged(ctx, sessionID, session.FeedbackID, file.Filename); err != nil {
+			s.logger.Error("Failed to promote upload session file",
+				"session_id", sessionID,
+				"filename", file.Filename,
+				"error", err,
+			)
+			return nil, fmt.Errorf("failed to promote %s, commit again to resume: %w", file.Filename, err)
+		}
+
+		info := &models.AttachmentInfo{
+			Filename:    file.Filename,
+			Size:        file.Size,
+			ContentType: file.ContentType,
+			UploadedAt:  time.Now().UTC(),
+			SHA256:      file.SHA256,
+		}
+		// As for a single upload, a checksum left from a replaced file must not stay recorded
+		if err := s.assetRepo.Upsert(ctx, session.FeedbackID, info); err != nil {
+			s.logger.Error("Failed to record attachment metadata",
+				"feedback_id", session.FeedbackID,
+				"filename", file.Filename,
+				"error", err,
+			)
+			if err := s.assetRepo.Delete(ctx, session.FeedbackID, file.Filename); err != nil {
+				return nil, fmt.Errorf("failed to record the checksum of %s, commit again to resume: %w", file.Filename, err)
+			}
+		}
+
+		if err := s.sessionRepo.MarkFilePromoted(ctx, sessionID, file.Filename); err != nil {
+			return nil, fmt.Errorf("failed to record promotion of %s, commit again to resume: %w", file.Filename, err)
+		}
+		file.Promoted = true
+		bus.Publish(ctx, s.events, bus.AttachmentUploaded, bus.AttachmentEvent{FeedbackID: session.FeedbackID, Attachment: *info})
+	}
+
+	if _, err := s.sessionRepo.SetStatus(ctx, sessionID, models.UploadSessionCommitting, models.UploadSessionCommitted); err != nil {
+		return nil, err
+	}
+	session.Status = models.UploadSessionCommitted
+
+	if err := s.feedbackRepo.SetNeedsReupload(ctx, session.FeedbackID, false); err != nil {
+		s.logger.Error("Failed to clear re-upload flag", "feedback_id", session.FeedbackID, "error", err)
+	}
+
+	s.logger.Info("Upload session committed",
+		"session_id", sessionID,
+		"feedback_id", session.FeedbackID,
+		"files", len(session.Files),
+	)
+	return session, nil
+}
+
+// AbortUploadSession discards every staged file of an open session. Aborting an aborted
+// session again retries the cleanup; a session whose commit has started can only be committed.
+func (s *FeedbackService) AbortUploadSession(ctx context.Context, sessionID uuid.UUID, reviewerID int64) error {
+	s.logger.Info("Aborting upload session", "session_id", sessionID, "reviewer_id", reviewerID)
+
+	session, err := s.getOwnedSession(ctx, sessionID, reviewerID)
+	if err != nil {
+		return err
+	}
+
+	switch session.Status {
+	case models.UploadSessionAborted:
+	case models.UploadSessionOpen:
+		ok, err := s.sessionRepo.SetStatus(ctx, sessionID, models.UploadSessionOpen, models.UploadSessionAborted)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: session changed concurrently", ErrUploadSessionClosed)
+		}
+	default:
+		return fmt.Errorf("%w: session is %s", ErrUploadSessionClosed, session.Status)
+	}
+
+	if _, err := s.attachmentRepo.DeleteStaged(ctx, sessionID); err != nil {
+		s.logger.Error("Failed to delete staged files", "session_id", sessionID, "error", err)
+		return fmt.Errorf("failed to delete staged files: %w", err)
+	}
+
+	s.logger.Info("Upload session aborted", "session_id", sessionID)
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/apperr"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/upstream"
+)
+
+var (
+	// ErrSubmissionNotFound is returned when labs-service does not know the submission of a
+	// new feedback
+	ErrSubmissionNotFound = apperr.FailedPrecondition("SUBMISSION_NOT_FOUND", "the submission does not exist")
+
+	// ErrSubmissionStudentMismatch is returned when the submission of a new feedback was made by
+	// another student
+	ErrSubmissionStudentMismatch = apperr.FailedPrecondition("SUBMISSION_STUDENT_MISMATCH", "the submission does not belong to the student")
+
+	// ErrReviewerNotFound is returned when users-service does not know the reviewer of a new
+	// feedback
+	ErrReviewerNotFound = apperr.FailedPrecondition("REVIEWER_NOT_FOUND", "the reviewer does not exist")
+
+	// ErrUpstreamUnavailable is returned when the IDs of a new feedback could not be checked and
+	// validation fails closed
+	ErrUpstreamUnavailable = apperr.Unavailable("UPSTREAM_UNAVAILABLE", "could not check the feedback's IDs; try again later")
+)
+
+// UpstreamValidator checks the IDs of new feedback against the services that own them, so
+// typos do not create feedback nobody can find. A nil client skips its check.
+type UpstreamValidator struct {
+	cfg         config.UpstreamConfig
+	submissions upstream.SubmissionClient
+	users       upstream.UserClient
+	logger      *slog.Logger
+}
+
+// NewUpstreamValidator creates a validator; submissions and users may be nil
+func NewUpstreamValidator(cfg config.UpstreamConfig, submissions upstream.SubmissionClient, users upstream.UserClient, logger *slog.Logger) *UpstreamValidator {
+	return &UpstreamValidator{
+		cfg:         cfg,
+		submissions: submissions,
+		users:       users,
+		logger:      logger,
+	}
+}
+
+// CheckFeedbackIDs confirms that the submission exists and was made by the student, and that
+// the reviewer exists. A service that fails or times out lets the feedback through when
+// configured to fail open, and fails with ErrUpstreamUnavailable otherwise. A nil validator,
+// or one with validation turned off, accepts everything.
+func (v *UpstreamValidator) CheckFeedbackIDs(ctx context.Context, reviewerID, studentID, submissionID int64) error {
+	if v == nil || !v.cfg.Validate {
+		return nil
+	}
+
+	if v.submissions != nil {
+		callCtx, cancel := context.WithTimeout(ctx, v.cfg.Timeout)
+		submission, err := v.submissions.GetSubmission(callCtx, submissionID)
+		cancel()
+		switch {
+		case errors.Is(err, upstream.ErrNotFound):
+			return ErrSubmissionNotFound.WithMetadata("submission_id", fmt.Sprint(submissionID))
+		case err != nil:
+			if err := v.unavailable(ctx, "labs-service", err); err != nil {
+				return err
+			}
+		case submission.OwnerID != studentID:
+			return ErrSubmissionStudentMismatch.WithMetadata("submission_id", fmt.Sprint(submissionID))
+		}
+	}
+
+	if v.users != nil {
+		callCtx, cancel := context.WithTimeout(ctx, v.cfg.Timeout)
+		_, err := v.users.GetUser(callCtx, reviewerID)
+		cancel()
+		switch {
+		case errors.Is(err, upstream.ErrNotFound):
+			return ErrReviewerNotFound.WithMetadata("reviewer_id", fmt.Sprint(reviewerID))
+		case err != nil:
+			if err := v.unavailable(ctx, "users-service", err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unavailable decides what a failed upstream call means: nothing when failing open, unless
+// the request itself was canceled, and ErrUpstreamUnavailable otherwise
+func (v *UpstreamValidator) unavailable(ctx context.Context, service string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if v.cfg.FailOpen {
+		v.logger.Warn("Upstream check failed; creating feedback unchecked", "service", service, "error", err)
+		return nil
+	}
+	v.logger.Error("Upstream check failed", "service", service, "error", err)
+	return ErrUpstreamUnavailable.Wrap(err).WithMetadata("service", service)
+}
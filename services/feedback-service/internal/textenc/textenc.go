@@ -0,0 +1,160 @@
+// Package textenc decodes text exported by systems that did not store UTF-8, such as the old
+// LMS whose exports are in Windows-1251.
+//
+// Only single-byte encodings are supported, so every byte decodes on its own and a failure can
+// be reported with its offset. Bytes the encoding leaves undefined fail the decode, or are
+// replaced with U+FFFD in lenient mode.
+package textenc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Names with special meaning
+const (
+	UTF8 = "utf-8" // Content is UTF-8 already and is only validated
+	Auto = "auto"  // Detect UTF-8, Windows-1251 or KOI8-R from the content
+)
+
+// charmaps are the supported single-byte encodings by lowercase name and alias
+var charmaps = map[string]*charmap.Charmap{
+	"windows-1251": charmap.Windows1251,
+	"cp1251":       charmap.Windows1251,
+	"koi8-r":       charmap.KOI8R,
+	"koi8-u":       charmap.KOI8U,
+	"ibm866":       charmap.CodePage866,
+	"cp866":        charmap.CodePage866,
+	"iso-8859-5":   charmap.ISO8859_5,
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"iso-8859-1":   charmap.ISO8859_1,
+	"latin1":       charmap.ISO8859_1,
+}
+
+// normalize returns the lookup form of an encoding name; empty means UTF-8
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "utf8" {
+		return UTF8
+	}
+	return name
+}
+
+// Supported reports whether an encoding name can be decoded
+func Supported(name string) bool {
+	name = normalize(name)
+	_, ok := charmaps[name]
+	return ok || name == UTF8 || name == Auto
+}
+
+// Names returns the accepted encoding names in lexical order
+func Names() []string {
+	names := []string{UTF8, Auto}
+	for name := range charmaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Result is decoded text
+type Result struct {
+	Text     string
+	Encoding string // The encoding decoded from; the detected one for Auto
+	Replaced int    // Invalid bytes or sequences replaced with U+FFFD in lenient mode
+}
+
+// DecodeError reports the first byte that is invalid in the source encoding
+type DecodeError struct {
+	Encoding string
+	Offset   int
+	Byte     byte
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("invalid %s byte 0x%02x at offset %d", e.Encoding, e.Byte, e.Offset)
+}
+
+// Decode converts data in the named encoding to UTF-8. Without lenient, the first invalid
+// byte fails the decode with a *DecodeError; with it, invalid bytes are replaced and counted.
+func Decode(name string, data []byte, lenient bool) (*Result, error) {
+	name = normalize(name)
+	if name == Auto {
+		name = detect(data)
+	}
+	if name == UTF8 {
+		return decodeUTF8(data, lenient)
+	}
+
+	cm, ok := charmaps[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+
+	result := &Result{Encoding: name}
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for i, c := range data {
+		r := cm.DecodeByte(c)
+		if r == utf8.RuneError {
+			if !lenient {
+				return nil, &DecodeError{Encoding: name, Offset: i, Byte: c}
+			}
+			result.Replaced++
+		}
+		b.WriteRune(r)
+	}
+	result.Text = b.String()
+	return result, nil
+}
+
+// decodeUTF8 validates UTF-8 content, replacing each invalid sequence in lenient mode
+func decodeUTF8(data []byte, lenient bool) (*Result, error) {
+	result := &Result{Encoding: UTF8}
+	var b strings.Builder
+	b.Grow(len(data))
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			if !lenient {
+				return nil, &DecodeError{Encoding: UTF8, Offset: i, Byte: data[i]}
+			}
+			result.Replaced++
+		}
+		b.WriteRune(r)
+		i += size
+	}
+	result.Text = b.String()
+	return result, nil
+}
+
+// detect guesses the encoding of Cyrillic text: UTF-8 if the content is valid UTF-8,
+// otherwise whichever of Windows-1251 and KOI8-R yields more lowercase Cyrillic letters. The
+// two encodings swap the ranges of lowercase and uppercase letters, and running text is mostly
+// lowercase.
+func detect(data []byte) string {
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	if lowercaseCyrillic(charmap.KOI8R, data) > lowercaseCyrillic(charmap.Windows1251, data) {
+		return "koi8-r"
+	}
+	return "windows-1251"
+}
+
+// lowercaseCyrillic counts the bytes that decode to lowercase Cyrillic letters
+func lowercaseCyrillic(cm *charmap.Charmap, data []byte) int {
+	count := 0
+	for _, c := range data {
+		if r := cm.DecodeByte(c); unicode.Is(unicode.Cyrillic, r) && unicode.IsLower(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,107 @@
+package textenc
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var (
+	privetWindows1251 = []byte{0xEF, 0xF0, 0xE8, 0xE2, 0xE5, 0xF2} // "привет"
+	privetKOI8R       = []byte{0xD0, 0xD2, 0xC9, 0xD7, 0xC5, 0xD4}
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name         string
+		encoding     string
+		data         []byte
+		lenient      bool
+		want         string
+		wantEncoding string
+		wantReplaced int
+	}{
+		{name: "utf-8 by default", encoding: "", data: []byte("привет"), want: "привет", wantEncoding: UTF8},
+		{name: "utf8 alias", encoding: " UTF8 ", data: []byte("ok"), want: "ok", wantEncoding: UTF8},
+		{name: "windows-1251", encoding: "Windows-1251", data: privetWindows1251, want: "привет", wantEncoding: "windows-1251"},
+		{name: "cp1251 alias", encoding: "cp1251", data: privetWindows1251, want: "привет", wantEncoding: "cp1251"},
+		{name: "koi8-r", encoding: "koi8-r", data: privetKOI8R, want: "привет", wantEncoding: "koi8-r"},
+		{name: "latin1", encoding: "latin1", data: []byte{'c', 'a', 'f', 0xE9}, want: "café", wantEncoding: "latin1"},
+		{name: "auto detects utf-8", encoding: Auto, data: []byte("привет"), want: "привет", wantEncoding: UTF8},
+		{name: "auto detects windows-1251", encoding: Auto, data: privetWindows1251, want: "привет", wantEncoding: "windows-1251"},
+		{name: "auto detects koi8-r", encoding: "AUTO", data: privetKOI8R, want: "привет", wantEncoding: "koi8-r"},
+		{name: "lenient utf-8", encoding: UTF8, data: []byte{'a', 0xFF, 'b', 0xC3}, lenient: true, want: "a�b�", wantEncoding: UTF8, wantReplaced: 2},
+		{name: "lenient windows-1251", encoding: "windows-1251", data: []byte{0xEF, 0x98}, lenient: true, want: "п�", wantEncoding: "windows-1251", wantReplaced: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.encoding, tt.data, tt.lenient)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got.Text != tt.want || got.Encoding != tt.wantEncoding || got.Replaced != tt.wantReplaced {
+				t.Errorf("Decode() = %+v, want text %q, encoding %q, replaced %d", got, tt.want, tt.wantEncoding, tt.wantReplaced)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		data     []byte
+		want     *DecodeError
+	}{
+		{name: "invalid utf-8 byte", encoding: UTF8, data: []byte{'o', 'k', 0xFF}, want: &DecodeError{Encoding: UTF8, Offset: 2, Byte: 0xFF}},
+		{name: "truncated utf-8 sequence", encoding: UTF8, data: []byte{'a', 0xD0}, want: &DecodeError{Encoding: UTF8, Offset: 1, Byte: 0xD0}},
+		{name: "undefined windows-1251 byte", encoding: "windows-1251", data: []byte{0xEF, 0x98}, want: &DecodeError{Encoding: "windows-1251", Offset: 1, Byte: 0x98}},
+		{name: "unsupported encoding", encoding: "ebcdic"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.encoding, tt.data, false)
+			if err == nil {
+				t.Fatal("Decode() error = nil")
+			}
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) != (tt.want != nil) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil && *decodeErr != *tt.want {
+				t.Errorf("Decode() error = %+v, want %+v", decodeErr, tt.want)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "", want: true},
+		{name: "utf-8", want: true},
+		{name: "Auto", want: true},
+		{name: " KOI8-U ", want: true},
+		{name: "ibm866", want: true},
+		{name: "utf-16", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Supported(tt.name); got != tt.want {
+				t.Errorf("Supported(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	names := Names()
+	if !slices.IsSorted(names) || !slices.Contains(names, UTF8) || !slices.Contains(names, Auto) {
+		t.Errorf("Names() = %v, want the sorted names including %q and %q", names, UTF8, Auto)
+	}
+	for _, name := range names {
+		if !Supported(name) {
+			t.Errorf("Names() lists %q, which is not supported", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package throttle paces byte streams with a token bucket.
+package throttle
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter is a token bucket limiting a single stream to a number of bytes per second.
+// A nil *Limiter imposes no limit, so disabled throttling costs a nil check per chunk.
+// A Limiter is not safe for concurrent use; create one per stream.
+type Limiter struct {
+	bytesPerSecond float64
+	burst          float64
+	tokens         float64
+	last           time.Time
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// New creates a limiter allowing bytesPerSecond with bursts of up to burst bytes.
+// It returns nil (unlimited) if bytesPerSecond is not positive. The burst is raised to
+// bytesPerSecond if smaller, so a single chunk never waits for more than a second of budget.
+func New(bytesPerSecond int64, burst int) *Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	if int64(burst) < bytesPerSecond {
+		burst = int(bytesPerSecond)
+	}
+
+	l := &Limiter{
+		bytesPerSecond: float64(bytesPerSecond),
+		burst:          float64(burst),
+		tokens:         float64(burst),
+		now:            time.Now,
+		after:          time.After,
+	}
+	l.last = l.now()
+	return l
+}
+
+// BytesPerSecond returns the configured limit, or 0 if the limiter is unlimited
+func (l *Limiter) BytesPerSecond() int64 {
+	if l == nil {
+		return 0
+	}
+	return int64(l.bytesPerSecond)
+}
+
+// WaitN blocks until n bytes may be sent or ctx is done
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+
+	now := l.now()
+	l.tokens += now.Sub(l.last).Seconds() * l.bytesPerSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return nil
+	}
+
+	wait := time.Duration(-l.tokens / l.bytesPerSecond * float64(time.Second))
+	select {
+	case <-ctx.Done():
+		// The bytes were not sent, so return their tokens
+		l.tokens += float64(n)
+		return ctx.Err()
+	case <-l.after(wait):
+		return nil
+	}
+}
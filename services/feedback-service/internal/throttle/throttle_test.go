@@ -0,0 +1,115 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for the wall clock; a wait returns at once and advances the clock
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) after(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func newTestLimiter(bytesPerSecond int64, burst int) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l := New(bytesPerSecond, burst)
+	l.now = func() time.Time { return clock.now }
+	l.after = clock.after
+	l.last = clock.now
+	return l, clock
+}
+
+func TestWaitN(t *testing.T) {
+	type step struct {
+		idle time.Duration // Time passing before the call
+		n    int
+		wait time.Duration // Expected wait, 0 for none
+	}
+	tests := []struct {
+		name  string
+		rate  int64
+		burst int
+		steps []step
+	}{
+		{name: "within burst", rate: 100, burst: 200, steps: []step{{n: 150}, {n: 50}}},
+		{name: "over burst", rate: 100, burst: 100, steps: []step{{n: 150, wait: 500 * time.Millisecond}}},
+		{name: "burst raised to rate", rate: 100, burst: 10, steps: []step{{n: 100}}},
+		{name: "debt carried over", rate: 100, burst: 100, steps: []step{
+			{n: 100},
+			{n: 50, wait: 500 * time.Millisecond},
+			{n: 50, wait: 500 * time.Millisecond},
+		}},
+		{name: "refilled while idle", rate: 100, burst: 100, steps: []step{{n: 100}, {idle: time.Second, n: 100}}},
+		{name: "refill capped at burst", rate: 100, burst: 100, steps: []step{{idle: time.Minute, n: 150, wait: 500 * time.Millisecond}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, clock := newTestLimiter(tt.rate, tt.burst)
+			for i, s := range tt.steps {
+				clock.now = clock.now.Add(s.idle)
+				clock.waits = nil
+				if err := l.WaitN(context.Background(), s.n); err != nil {
+					t.Fatalf("step %d: WaitN() error = %v", i, err)
+				}
+				var waited time.Duration
+				for _, wait := range clock.waits {
+					waited += wait
+				}
+				if waited != s.wait {
+					t.Errorf("step %d: WaitN(%d) waited %v, want %v", i, s.n, waited, s.wait)
+				}
+			}
+		})
+	}
+}
+
+func TestWaitNCanceled(t *testing.T) {
+	l, clock := newTestLimiter(100, 100)
+	l.after = func(time.Duration) <-chan time.Time { return nil }
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := l.WaitN(ctx, 150); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitN() error = %v, want %v", err, context.Canceled)
+	}
+	// The bytes of the canceled wait were not sent, so the burst is still available
+	l.after = clock.after
+	if err := l.WaitN(context.Background(), 100); err != nil || len(clock.waits) != 0 {
+		t.Errorf("WaitN() after a canceled wait = %v, waits %v, want no wait", err, clock.waits)
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	tests := []struct {
+		name string
+		rate int64
+	}{
+		{name: "zero", rate: 0},
+		{name: "negative", rate: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, 1024)
+			if l != nil {
+				t.Fatalf("New(%d) = %+v, want nil", tt.rate, l)
+			}
+			if got := l.BytesPerSecond(); got != 0 {
+				t.Errorf("BytesPerSecond() = %d, want 0", got)
+			}
+			if err := l.WaitN(context.Background(), 1<<30); err != nil {
+				t.Errorf("WaitN() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package upstream holds the clients of the services whose IDs feedback refers to:
+// users-service for users and labs-service for submissions.
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound is returned when the service does not know the ID asked for
+var ErrNotFound = errors.New("not found upstream")
+
+// Submission is a labs-service submission
+type Submission struct {
+	ID      int64
+	LabID   int64
+	OwnerID int64 // The student who submitted it
+}
+
+// User is a users-service user
+type User struct {
+	ID   int64
+	Role string
+}
+
+// SubmissionClient looks up submissions
+type SubmissionClient interface {
+	GetSubmission(ctx context.Context, submissionID int64) (*Submission, error)
+}
+
+// UserClient looks up users
+type UserClient interface {
+	GetUser(ctx context.Context, userID int64) (*User, error)
+}
+
+// Dial creates a connection to a service; it connects lazily, on the first call
+func Dial(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// grpcSubmissionClient looks submissions up in labs-service
+type grpcSubmissionClient struct {
+	client pb.SubmissionServiceClient
+}
+
+// NewSubmissionClient creates a SubmissionClient calling labs-service over conn
+func NewSubmissionClient(conn grpc.ClientConnInterface) SubmissionClient {
+	return &grpcSubmissionClient{client: pb.NewSubmissionServiceClient(conn)}
+}
+
+func (c *grpcSubmissionClient) GetSubmission(ctx context.Context, submissionID int64) (*Submission, error) {
+	resp, err := c.client.GetSubmission(ctx, &pb.GetSubmissionRequest{SubmissionId: submissionID})
+	if err != nil {
+		return nil, callError(err, "failed to get submission")
+	}
+	return &Submission{ID: resp.SubmissionId, LabID: resp.LabId, OwnerID: resp.OwnerId}, nil
+}
+
+// grpcUserClient looks users up in users-service
+type grpcUserClient struct {
+	client pb.UsersServiceClient
+}
+
+// NewUserClient creates a UserClient calling users-service over conn
+func NewUserClient(conn grpc.ClientConnInterface) UserClient {
+	return &grpcUserClient{client: pb.NewUsersServiceClient(conn)}
+}
+
+func (c *grpcUserClient) GetUser(ctx context.Context, userID int64) (*User, error) {
+	resp, err := c.client.GetUserInfo(ctx, &pb.GetUserInfoRequest{UserId: userID})
+	if err != nil {
+		return nil, callError(err, "failed to get user")
+	}
+	if resp.UserInfo == nil {
+		return nil, ErrNotFound
+	}
+	return &User{ID: resp.UserInfo.UserId, Role: resp.UserInfo.Role}, nil
+}
+
+// callError maps NOT_FOUND to ErrNotFound and wraps any other error of a call
+func callError(err error, msg string) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,94 @@
+// Package validation checks attachment metadata supplied by clients before it reaches object
+// storage, and shortens untrusted values for log output.
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// MaxFilenameBytes is the longest accepted attachment filename, in bytes of UTF-8
+	MaxFilenameBytes = 255
+
+	// MaxContentTypeBytes is the longest accepted content type
+	MaxContentTypeBytes = 127
+
+	// maxLogValueBytes is how much of an untrusted value is written to the log
+	maxLogValueBytes = 128
+)
+
+// Reasons reported by Error
+const (
+	ReasonTooLong = "FIELD_TOO_LONG"
+	ReasonInvalid = "FIELD_INVALID"
+)
+
+// contentTypePattern matches a type/subtype media type with optional parameters
+var contentTypePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*/[A-Za-z0-9][A-Za-z0-9!#$&^_.+-]*(\s*;.*)?$`)
+
+// Error describes a rejected field. Limit and Actual are set for length violations.
+type Error struct {
+	Field  string
+	Reason string
+	Limit  int
+	Actual int
+}
+
+func (e *Error) Error() string {
+	if e.Reason == ReasonTooLong {
+		return fmt.Sprintf("%s is %d bytes, maximum is %d bytes", e.Field, e.Actual, e.Limit)
+	}
+	return fmt.Sprintf("%s is invalid", e.Field)
+}
+
+// Details returns the error as key/value pairs for ErrorInfo metadata
+func (e *Error) Details() map[string]string {
+	details := map[string]string{"field": e.Field}
+	if e.Reason == ReasonTooLong {
+		details["max_bytes"] = strconv.Itoa(e.Limit)
+		details["actual_bytes"] = strconv.Itoa(e.Actual)
+	}
+	return details
+}
+
+// Filename checks an attachment filename: non-empty, valid UTF-8 and at most MaxFilenameBytes
+func Filename(filename string) error {
+	if filename == "" || !utf8.ValidString(filename) {
+		return &Error{Field: "filename", Reason: ReasonInvalid}
+	}
+	if len(filename) > MaxFilenameBytes {
+		return &Error{Field: "filename", Reason: ReasonTooLong, Limit: MaxFilenameBytes, Actual: len(filename)}
+	}
+	return nil
+}
+
+// ContentType checks an attachment content type. Empty means unknown and is accepted;
+// anything else must be a type/subtype of at most MaxContentTypeBytes.
+func ContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	if len(contentType) > MaxContentTypeBytes {
+		return &Error{Field: "content_type", Reason: ReasonTooLong, Limit: MaxContentTypeBytes, Actual: len(contentType)}
+	}
+	if !contentTypePattern.MatchString(contentType) {
+		return &Error{Field: "content_type", Reason: ReasonInvalid}
+	}
+	return nil
+}
+
+// LogValue shortens an untrusted value for logging, cutting at a rune boundary and noting
+// the original length
+func LogValue(value string) string {
+	if len(value) <= maxLogValueBytes {
+		return value
+	}
+	cut := maxLogValueBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(%d bytes)", value[:cut], len(value))
+}
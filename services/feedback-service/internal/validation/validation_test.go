@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// checkError compares err with the reason and lengths a test expects; an empty reason expects nil
+func checkError(t *testing.T, err error, reason string, limit, actual int) {
+	t.Helper()
+	if reason == "" {
+		if err != nil {
+			t.Fatalf("error = %v, want nil", err)
+		}
+		return
+	}
+	var fieldErr *Error
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("error = %v, want *Error", err)
+	}
+	if fieldErr.Reason != reason {
+		t.Errorf("Reason = %q, want %q", fieldErr.Reason, reason)
+	}
+	if fieldErr.Limit != limit || fieldErr.Actual != actual {
+		t.Errorf("Limit, Actual = %d, %d, want %d, %d", fieldErr.Limit, fieldErr.Actual, limit, actual)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	// "ж" is 2 bytes and "界" 3 bytes of UTF-8
+	tests := []struct {
+		name     string
+		filename string
+		reason   string
+		actual   int
+	}{
+		{name: "short", filename: "report.pdf"},
+		{name: "empty", filename: "", reason: ReasonInvalid},
+		{name: "invalid UTF-8", filename: "report\xff.pdf", reason: ReasonInvalid},
+		{name: "ASCII at limit", filename: strings.Repeat("a", 251) + ".pdf"},
+		{name: "ASCII over limit", filename: strings.Repeat("a", 252) + ".pdf", reason: ReasonTooLong, actual: 256},
+		{name: "2-byte runes at limit", filename: strings.Repeat("ж", 125) + ".pdf!"},
+		{name: "2-byte runes over limit", filename: strings.Repeat("ж", 126) + ".pdf", reason: ReasonTooLong, actual: 256},
+		{name: "3-byte runes at limit", filename: strings.Repeat("界", 85)},
+		{name: "3-byte runes over limit", filename: strings.Repeat("界", 85) + "a", reason: ReasonTooLong, actual: 256},
+		{name: "255 runes over limit", filename: strings.Repeat("界", 255), reason: ReasonTooLong, actual: 765},
+		{name: "rune cut at limit", filename: strings.Repeat("界", 85)[:254], reason: ReasonInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := 0
+			if tt.reason == ReasonTooLong {
+				limit = MaxFilenameBytes
+			}
+			checkError(t, Filename(tt.filename), tt.reason, limit, tt.actual)
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	// A type of exactly n bytes: "application/" followed by a subtype of x's
+	ofLength := func(n int) string {
+		return "application/" + strings.Repeat("x", n-len("application/"))
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		reason      string
+		actual      int
+	}{
+		{name: "empty", contentType: ""},
+		{name: "plain", contentType: "application/pdf"},
+		{name: "parameters", contentType: "text/plain; charset=utf-8"},
+		{name: "vendor type", contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{name: "old column size", contentType: ofLength(100)},
+		{name: "over old column size", contentType: ofLength(101)},
+		{name: "at limit", contentType: ofLength(MaxContentTypeBytes)},
+		{name: "over limit", contentType: ofLength(MaxContentTypeBytes + 1), reason: ReasonTooLong, actual: MaxContentTypeBytes + 1},
+		{name: "no subtype", contentType: "application", reason: ReasonInvalid},
+		{name: "empty subtype", contentType: "application/", reason: ReasonInvalid},
+		{name: "space in type", contentType: "text /plain", reason: ReasonInvalid},
+		{name: "newline", contentType: "text/plain\nX-Injected: 1", reason: ReasonInvalid},
+		{name: "non-ASCII", contentType: "text/plaín", reason: ReasonInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := 0
+			if tt.reason == ReasonTooLong {
+				limit = MaxContentTypeBytes
+			}
+			checkError(t, ContentType(tt.contentType), tt.reason, limit, tt.actual)
+		})
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want map[string]string
+	}{
+		{
+			name: "too long",
+			err:  &Error{Field: "filename", Reason: ReasonTooLong, Limit: 255, Actual: 300},
+			want: map[string]string{"field": "filename", "max_bytes": "255", "actual_bytes": "300"},
+		},
+		{
+			name: "invalid",
+			err:  &Error{Field: "content_type", Reason: ReasonInvalid},
+			want: map[string]string{"field": "content_type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.err.Details()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Details() = %v, want %v", got, tt.want)
+			}
+			for key, value := range tt.want {
+				if got[key] != value {
+					t.Errorf("Details()[%q] = %q, want %q", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "short", value: "report.pdf", want: "report.pdf"},
+		{name: "at limit", value: strings.Repeat("a", maxLogValueBytes), want: strings.Repeat("a", maxLogValueBytes)},
+		{name: "over limit", value: strings.Repeat("a", 200), want: strings.Repeat("a", maxLogValueBytes) + "...(200 bytes)"},
+		// 43 runes of 3 bytes are 129 bytes, so the cut falls inside the 43rd rune
+		{name: "cut inside rune", value: strings.Repeat("界", 43), want: strings.Repeat("界", 42) + "...(129 bytes)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LogValue(tt.value)
+			if got != tt.want {
+				t.Errorf("LogValue() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("LogValue() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// minSecretLength is the shortest signing secret accepted, in bytes
+const minSecretLength = 16
+
+// Key is a shared secret payloads are signed with. Its ID is sent with every payload so the
+// consumer knows which secret to verify with.
+type Key struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// Endpoint is an HTTP endpoint receiving events. It lists every key it may currently be signed
+// with; PrimaryKey names the one outgoing payloads are signed with, and may be left empty when
+// there is only one key.
+type Endpoint struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	Events     []string `json:"events"` // Event names delivered to the endpoint; empty delivers all of Events
+	PrimaryKey string   `json:"primary_key"`
+	Keys       []Key    `json:"keys"`
+}
+
+// primary returns the key outgoing payloads are signed with. Endpoints are validated by
+// ParseEndpoints, so the key exists.
+func (e *Endpoint) primary() Key {
+	for _, key := range e.Keys {
+		if key.ID == e.PrimaryKey {
+			return key
+		}
+	}
+	return Key{}
+}
+
+// wants reports whether the endpoint receives an event
+func (e *Endpoint) wants(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+// keyIDs returns the IDs of the endpoint's keys, in file order
+func (e *Endpoint) keyIDs() []string {
+	ids := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		ids[i] = key.ID
+	}
+	return ids
+}
+
+// endpointsFile is the layout of the endpoints file
+type endpointsFile struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// ParseEndpoints parses the JSON of an endpoints file and validates it: endpoint names and the
+// key IDs of an endpoint are unique, URLs are absolute http or https URLs, events are among
+// Events, every endpoint has at least one key, and its primary key is one of them.
+func ParseEndpoints(data []byte) ([]Endpoint, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var file endpointsFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid webhook endpoints: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i := range file.Endpoints {
+		endpoint := &file.Endpoints[i]
+		if endpoint.Name == "" {
+			return nil, fmt.Errorf("webhook endpoint %d has no name", i)
+		}
+		if names[endpoint.Name] {
+			return nil, fmt.Errorf("webhook endpoint %q is listed twice", endpoint.Name)
+		}
+		names[endpoint.Name] = true
+		if err := validateEndpoint(endpoint); err != nil {
+			return nil, fmt.Errorf("webhook endpoint %q: %w", endpoint.Name, err)
+		}
+	}
+	return file.Endpoints, nil
+}
+
+// validateEndpoint checks an endpoint and fills in a primary key left empty for a single key
+func validateEndpoint(endpoint *Endpoint) error {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	for _, event := range endpoint.Events {
+		if !slices.Contains(Events, event) {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+
+	if len(endpoint.Keys) == 0 {
+		return fmt.Errorf("no signing keys")
+	}
+	ids := make(map[string]bool)
+	for _, key := range endpoint.Keys {
+		if !validKeyID(key.ID) {
+			return fmt.Errorf("key ID %q must be 1-64 letters, digits, '.', '_' or '-'", key.ID)
+		}
+		if ids[key.ID] {
+			return fmt.Errorf("key %q is listed twice", key.ID)
+		}
+		ids[key.ID] = true
+		if len(key.Secret) < minSecretLength {
+			return fmt.Errorf("secret of key %q must be at least %d bytes", key.ID, minSecretLength)
+		}
+	}
+
+	switch {
+	case endpoint.PrimaryKey == "" && len(endpoint.Keys) == 1:
+		endpoint.PrimaryKey = endpoint.Keys[0].ID
+	case endpoint.PrimaryKey == "":
+		return fmt.Errorf("primary_key is required with several keys")
+	case !ids[endpoint.PrimaryKey]:
+		return fmt.Errorf("primary key %q is not listed in keys", endpoint.PrimaryKey)
+	}
+	return nil
+}
+
+// validKeyID reports whether id is safe to send in a header
+func validKeyID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,489 @@
+// Package webhook delivers feedback events to HTTP endpoints listed in a file.
+//
+// Every payload is signed with HMAC-SHA256 using the primary key of its endpoint, and the key's
+// ID is sent along, so signing keys can be rotated without downtime: a new key is added to the
+// endpoint, made primary once the consumer accepts it, and the old key removed. The file is
+// reloaded on SIGHUP or when it changes, without restarting the dispatcher. Failed deliveries
+// are retried with backoff, and every attempt is signed again with the primary key of the
+// moment, so retries never use a key rotated out in the meantime.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/resourcename"
+	"github.com/google/uuid"
+)
+
+// Headers sent with every delivery
+const (
+	EventHeader     = "X-Feedback-Event"
+	EventIDHeader   = "X-Feedback-Event-Id"      // Same for every attempt and endpoint, so consumers can drop duplicates
+	TimestampHeader = "X-Feedback-Timestamp"     // Unix time of the attempt in seconds, covered by the signature
+	KeyIDHeader     = "X-Feedback-Signature-Key" // ID of the key the payload is signed with
+	SignatureHeader = "X-Feedback-Signature"
+)
+
+// Events lists the events delivered to endpoints, named like their bus topics
+var Events = []string{
+	bus.FeedbackCreated.Name(),
+	bus.FeedbackUpdated.Name(),
+	bus.FeedbackPublished.Name(),
+	bus.FeedbackApprovalRequested.Name(),
+	bus.FeedbackApproved.Name(),
+	bus.FeedbackChangesRequested.Name(),
+	bus.FeedbackDeleted.Name(),
+}
+
+// maxResponseBytes is how much of a response body is read so the connection can be reused
+const maxResponseBytes = 64 * 1024
+
+// Sign returns the signature of a payload sent at timestamp: "sha256=" followed by the hex
+// HMAC-SHA256, keyed with secret, of the timestamp in decimal, a '.' and the body
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// payload is the JSON body of a delivery
+type payload struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// feedbackData is the data of the feedback events; the content is left out
+type feedbackData struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	ReviewerID     int64      `json:"reviewer_id"`
+	StudentID      int64      `json:"student_id"`
+	SubmissionID   int64      `json:"submission_id"`
+	CourseID       string     `json:"course_id,omitempty"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	ApprovalStatus string     `json:"approval_status"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func newFeedbackData(feedback *models.Feedback) feedbackData {
+	return feedbackData{
+		ID:             feedback.ID,
+		Name:           resourcename.Feedback(feedback.ID),
+		ReviewerID:     feedback.ReviewerID,
+		StudentID:      feedback.StudentID,
+		SubmissionID:   feedback.SubmissionID,
+		CourseID:       feedback.CourseID,
+		Title:          feedback.Title,
+		Status:         feedback.Status,
+		ApprovalStatus: feedback.ApprovalStatus,
+		PublishedAt:    feedback.PublishedAt,
+		UpdatedAt:      feedback.UpdatedAt,
+	}
+}
+
+// deletedData is the data of feedback.deleted
+type deletedData struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	ActorID int64     `json:"actor_id"`
+	Purged  bool      `json:"purged"`
+}
+
+// delivery is an event on its way to one endpoint
+type delivery struct {
+	endpoint string
+	event    string
+	eventID  string
+	body     []byte
+}
+
+// statusError is a delivery attempt answered with a status other than 2xx
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("endpoint answered %d %s", e.code, http.StatusText(e.code))
+}
+
+// retryable reports whether a failed attempt may succeed when repeated: transport errors,
+// timeouts, throttling and server errors are retried, other client errors are not
+func retryable(err error) bool {
+	var status *statusError
+	if !errors.As(err, &status) {
+		return true
+	}
+	return status.code >= 500 || status.code == http.StatusRequestTimeout || status.code == http.StatusTooManyRequests
+}
+
+// endpointStats counts the deliveries to an endpoint
+type endpointStats struct {
+	delivered int64
+	failed    int64
+	dropped   int64
+	signed    map[string]int64
+}
+
+// EndpointStatus reports on the deliveries to an endpoint since the process started
+type EndpointStatus struct {
+	Name         string
+	PrimaryKeyID string // Empty once the endpoint was removed
+	KeyIDs       []string
+	Delivered    int64
+	Failed       int64            // Given up after the last attempt or a permanent error
+	Dropped      int64            // Not sent because the queue was full or the endpoint was removed
+	Signed       map[string]int64 // Attempts signed with each key ID, keys since removed included
+}
+
+// Dispatcher sends events to the configured endpoints. It is safe for concurrent use.
+type Dispatcher struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	logger *slog.Logger
+	now    func() time.Time
+
+	endpoints atomic.Pointer[map[string]*Endpoint]
+	queue     chan *delivery
+
+	mu     sync.Mutex
+	loaded [sha256.Size]byte // Hash of the file last read by Load
+	stats  map[string]*endpointStats
+}
+
+// New creates a dispatcher without endpoints; Load or Reload configures them
+func New(cfg config.WebhookConfig, logger *slog.Logger) *Dispatcher {
+	d := &Dispatcher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		now:    time.Now,
+		queue:  make(chan *delivery, cfg.QueueSize),
+		stats:  make(map[string]*endpointStats),
+	}
+	d.endpoints.Store(&map[string]*Endpoint{})
+	return d
+}
+
+// Load reads the endpoints from a file and applies them with Reload. A file that fails to
+// load leaves the current endpoints in place.
+func (d *Dispatcher) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read webhook endpoints: %w", err)
+	}
+	d.mu.Lock()
+	d.loaded = sha256.Sum256(data)
+	d.mu.Unlock()
+	endpoints, err := ParseEndpoints(data)
+	if err != nil {
+		return err
+	}
+	d.Reload(endpoints)
+	return nil
+}
+
+// Reload replaces the endpoints. Deliveries queued or waiting for a retry keep going to the
+// endpoint of the same name, signed with its primary key at the time of each attempt; those to
+// removed endpoints are dropped.
+func (d *Dispatcher) Reload(endpoints []Endpoint) {
+	byName := make(map[string]*Endpoint, len(endpoints))
+	for _, endpoint := range endpoints {
+		byName[endpoint.Name] = &endpoint
+	}
+	previous := *d.endpoints.Swap(&byName)
+
+	for _, endpoint := range byName {
+		old := previous[endpoint.Name]
+		switch {
+		case old == nil:
+			d.logger.Info("Webhook endpoint configured",
+				"endpoint", endpoint.Name,
+				"primary_key_id", endpoint.PrimaryKey,
+				"key_ids", endpoint.keyIDs(),
+			)
+		case old.PrimaryKey != endpoint.PrimaryKey:
+			d.logger.Info("Webhook signing key rotated",
+				"endpoint", endpoint.Name,
+				"previous_key_id", old.PrimaryKey,
+				"primary_key_id", endpoint.PrimaryKey,
+				"key_ids", endpoint.keyIDs(),
+			)
+		case !slices.Equal(old.keyIDs(), endpoint.keyIDs()):
+			d.logger.Info("Webhook signing keys changed",
+				"endpoint", endpoint.Name,
+				"primary_key_id", endpoint.PrimaryKey,
+				"key_ids", endpoint.keyIDs(),
+			)
+		}
+	}
+	for name := range previous {
+		if byName[name] == nil {
+			d.logger.Info("Webhook endpoint removed", "endpoint", name)
+		}
+	}
+}
+
+// Watch reloads the endpoints from path when hup receives a signal and, every
+// cfg.ReloadInterval, when the file content changed. A file that fails to load is logged and
+// the current endpoints are kept. Watch returns when ctx ends.
+func (d *Dispatcher) Watch(ctx context.Context, path string, hup <-chan os.Signal) {
+	var tick <-chan time.Time
+	if d.cfg.ReloadInterval > 0 {
+		ticker := time.NewTicker(d.cfg.ReloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	reload := func(reason string) {
+		if err := d.Load(path); err != nil {
+			d.logger.Error("Failed to reload webhook endpoints; keeping the current ones", "path", path, "reason", reason, "error", err)
+			return
+		}
+		d.logger.Info("Webhook endpoints reloaded", "path", path, "reason", reason)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("signal")
+		case <-tick:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				d.logger.Warn("Failed to check webhook endpoints for changes", "path", path, "error", err)
+				continue
+			}
+			d.mu.Lock()
+			changed := sha256.Sum256(data) != d.loaded
+			d.mu.Unlock()
+			if changed {
+				reload("file changed")
+			}
+		}
+	}
+}
+
+// Subscribe publishes the feedback events of b to the endpoints. Publish never blocks, so the
+// subscriptions keep up with the bus and must not lose events.
+func (d *Dispatcher) Subscribe(b *bus.Bus) error {
+	opts := bus.SubscribeOptions{Name: "webhooks", Policy: bus.Block}
+	for _, topic := range []bus.Topic[bus.FeedbackEvent]{
+		bus.FeedbackCreated,
+		bus.FeedbackUpdated,
+		bus.FeedbackPublished,
+		bus.FeedbackApprovalRequested,
+		bus.FeedbackApproved,
+		bus.FeedbackChangesRequested,
+	} {
+		if _, err := bus.Subscribe(b, topic, opts, func(ctx context.Context, event bus.FeedbackEvent) {
+			d.Publish(topic.Name(), newFeedbackData(event.Feedback))
+		}); err != nil {
+			return err
+		}
+	}
+	_, err := bus.Subscribe(b, bus.FeedbackDeleted, opts, func(ctx context.Context, event bus.FeedbackDeletedEvent) {
+		d.Publish(bus.FeedbackDeleted.Name(), deletedData{
+			ID:      event.FeedbackID,
+			Name:    resourcename.Feedback(event.FeedbackID),
+			ActorID: event.ActorID,
+			Purged:  event.Purged,
+		})
+	})
+	return err
+}
+
+// Publish queues an event for every endpoint receiving it and returns without waiting. When
+// the queue is full the event is dropped for the endpoint, which is logged and counted.
+func (d *Dispatcher) Publish(event string, data any) {
+	eventID := uuid.NewString()
+	body, err := json.Marshal(payload{ID: eventID, Event: event, OccurredAt: d.now().UTC(), Data: data})
+	if err != nil {
+		d.logger.Error("Failed to encode webhook payload", "event", event, "error", err)
+		return
+	}
+
+	for _, endpoint := range *d.endpoints.Load() {
+		if !endpoint.wants(event) {
+			continue
+		}
+		select {
+		case d.queue <- &delivery{endpoint: endpoint.Name, event: event, eventID: eventID, body: body}:
+		default:
+			d.logger.Warn("Webhook queue full, dropping delivery", "endpoint", endpoint.Name, "event", event, "event_id", eventID)
+			d.count(endpoint.Name, func(s *endpointStats) { s.dropped++ })
+		}
+	}
+}
+
+// Run sends queued deliveries with cfg.Workers workers until ctx ends. Deliveries still queued
+// or waiting for a retry then are dropped.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+	for range d.cfg.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-d.queue:
+					d.deliver(ctx, delivery)
+				}
+			}
+		}()
+	}
+	workers.Wait()
+
+	if pending := len(d.queue); pending > 0 {
+		d.logger.Warn("Webhook deliveries dropped at shutdown", "pending", pending)
+	}
+}
+
+// deliver sends a delivery, retrying failed attempts with backoff. The endpoint is looked up
+// for every attempt, so a retry after a reload goes to the current URL and is signed with the
+// current primary key.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *delivery) {
+	delay := d.cfg.RetryDelay
+	for attempt := 1; ; attempt++ {
+		endpoint := (*d.endpoints.Load())[delivery.endpoint]
+		if endpoint == nil {
+			d.logger.Warn("Webhook endpoint removed, dropping delivery", "endpoint", delivery.endpoint, "event", delivery.event, "event_id", delivery.eventID)
+			d.count(delivery.endpoint, func(s *endpointStats) { s.dropped++ })
+			return
+		}
+
+		key := endpoint.primary()
+		err := d.send(ctx, endpoint, key, delivery)
+		d.count(endpoint.Name, func(s *endpointStats) { s.signed[key.ID]++ })
+		if err == nil {
+			d.logger.Info("Webhook delivered",
+				"endpoint", endpoint.Name,
+				"event", delivery.event,
+				"event_id", delivery.eventID,
+				"key_id", key.ID,
+				"attempt", attempt,
+			)
+			d.count(endpoint.Name, func(s *endpointStats) { s.delivered++ })
+			return
+		}
+		if attempt >= d.cfg.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			d.logger.Error("Webhook delivery failed",
+				"endpoint", endpoint.Name,
+				"event", delivery.event,
+				"event_id", delivery.eventID,
+				"key_id", key.ID,
+				"attempts", attempt,
+				"error", err,
+			)
+			d.count(endpoint.Name, func(s *endpointStats) { s.failed++ })
+			return
+		}
+
+		d.logger.Warn("Webhook delivery attempt failed, retrying",
+			"endpoint", endpoint.Name,
+			"event", delivery.event,
+			"event_id", delivery.eventID,
+			"key_id", key.ID,
+			"attempt", attempt,
+			"retry_in", delay,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, d.cfg.MaxRetryDelay)
+	}
+}
+
+// send makes one delivery attempt, signed with key
+func (d *Dispatcher) send(ctx context.Context, endpoint *Endpoint, key Key, delivery *delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.body))
+	if err != nil {
+		return err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.event)
+	req.Header.Set(EventIDHeader, delivery.eventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(KeyIDHeader, key.ID)
+	req.Header.Set(SignatureHeader, Sign(key.Secret, timestamp, delivery.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+// count updates the counters of an endpoint
+func (d *Dispatcher) count(endpoint string, update func(*endpointStats)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := d.stats[endpoint]
+	if stats == nil {
+		stats = &endpointStats{signed: make(map[string]int64)}
+		d.stats[endpoint] = stats
+	}
+	update(stats)
+}
+
+// Statuses returns the configured endpoints and those removed since deliveries were counted,
+// sorted by name
+func (d *Dispatcher) Statuses() []EndpointStatus {
+	endpoints := *d.endpoints.Load()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var statuses []EndpointStatus
+	for name, endpoint := range endpoints {
+		status := EndpointStatus{Name: name, PrimaryKeyID: endpoint.PrimaryKey, KeyIDs: endpoint.keyIDs()}
+		if stats := d.stats[name]; stats != nil {
+			status.Delivered, status.Failed, status.Dropped, status.Signed = stats.delivered, stats.failed, stats.dropped, maps.Clone(stats.signed)
+		}
+		statuses = append(statuses, status)
+	}
+	for name, stats := range d.stats {
+		if endpoints[name] == nil {
+			statuses = append(statuses, EndpointStatus{Name: name, Delivered: stats.delivered, Failed: stats.failed, Dropped: stats.dropped, Signed: maps.Clone(stats.signed)})
+		}
+	}
+	slices.SortFunc(statuses, func(a, b EndpointStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
@@ -0,0 +1,433 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/bus"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/config"
+	"github.com/IU-Capstone-Project-2025/open-labs-share/services/feedback-service/internal/models"
+	"github.com/google/uuid"
+)
+
+var (
+	oldKey = Key{ID: "2025-01", Secret: "first-secret-0123456789"}
+	newKey = Key{ID: "2025-02", Secret: "second-secret-0123456789"}
+)
+
+var testWebhookConfig = config.WebhookConfig{
+	Timeout:       time.Second,
+	MaxAttempts:   3,
+	RetryDelay:    time.Millisecond,
+	MaxRetryDelay: 2 * time.Millisecond,
+	QueueSize:     10,
+	Workers:       1,
+}
+
+func newTestDispatcher(cfg config.WebhookConfig) *Dispatcher {
+	return New(cfg, slog.New(slog.DiscardHandler))
+}
+
+// attempt is a request received by a test endpoint
+type attempt struct {
+	header http.Header
+	body   []byte
+}
+
+// testEndpoint records the requests it receives and answers each with respond, called with
+// the attempt number starting at 1
+func testEndpoint(t *testing.T, respond func(n int) int) (*httptest.Server, func() []attempt) {
+	var mu sync.Mutex
+	var attempts []attempt
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		attempts = append(attempts, attempt{header: r.Header.Clone(), body: body})
+		n := len(attempts)
+		mu.Unlock()
+		w.WriteHeader(respond(n))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []attempt {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(attempts)
+	}
+}
+
+// deliverNext sends the next queued delivery, retries included
+func deliverNext(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	select {
+	case delivery := <-d.queue:
+		d.deliver(context.Background(), delivery)
+	default:
+		t.Fatal("no delivery queued")
+	}
+}
+
+// checkSignature verifies an attempt against the secrets by key ID and returns the key ID used
+func checkSignature(t *testing.T, a attempt, secrets map[string]string) string {
+	t.Helper()
+	keyID := a.header.Get(KeyIDHeader)
+	secret, ok := secrets[keyID]
+	if !ok {
+		t.Fatalf("%s = %q, want a known key ID", KeyIDHeader, keyID)
+	}
+	timestamp, err := strconv.ParseInt(a.header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("%s = %q: %v", TimestampHeader, a.header.Get(TimestampHeader), err)
+	}
+	if got, want := a.header.Get(SignatureHeader), Sign(secret, timestamp, a.body); got != want {
+		t.Errorf("%s = %s, want %s for key %s", SignatureHeader, got, want, keyID)
+	}
+	return keyID
+}
+
+func TestSign(t *testing.T) {
+	// Computed independently, so consumers can check their verification against it
+	want := "sha256=0b3a3c546f1111641fc469e85268dc342bebf3bcc8dedcd0714cb0ac06440f5b"
+	if got := Sign(oldKey.Secret, 1740823200, []byte(`{"id":"1"}`)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestParseEndpoints(t *testing.T) {
+	key := func(id string) string {
+		return `{"id": "` + id + `", "secret": "0123456789abcdef"}`
+	}
+	tests := []struct {
+		name        string
+		data        string
+		wantPrimary string
+		wantErr     string
+	}{
+		{name: "single key is primary", data: `{"endpoints": [{"name": "a", "url": "https://example.com/hook", "keys": [` + key("k1") + `]}]}`, wantPrimary: "k1"},
+		{name: "several keys", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "primary_key": "k2", "keys": [` + key("k1") + `, ` + key("k2") + `]}]}`, wantPrimary: "k2"},
+		{name: "events", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "events": ["feedback.published"], "keys": [` + key("k1") + `]}]}`, wantPrimary: "k1"},
+		{name: "no endpoints", data: `{"endpoints": []}`},
+		{name: "not JSON", data: `endpoints:`, wantErr: "invalid"},
+		{name: "unknown field", data: `{"endpoints": [{"name": "a", "secret": "x"}]}`, wantErr: "unknown field"},
+		{name: "no name", data: `{"endpoints": [{"url": "http://example.com", "keys": [` + key("k1") + `]}]}`, wantErr: "no name"},
+		{name: "duplicate name", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "keys": [` + key("k1") + `]}, {"name": "a", "url": "http://example.org", "keys": [` + key("k1") + `]}]}`, wantErr: "listed twice"},
+		{name: "relative URL", data: `{"endpoints": [{"name": "a", "url": "/hook", "keys": [` + key("k1") + `]}]}`, wantErr: "url"},
+		{name: "unsupported scheme", data: `{"endpoints": [{"name": "a", "url": "ftp://example.com", "keys": [` + key("k1") + `]}]}`, wantErr: "url"},
+		{name: "unknown event", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "events": ["comment.created"], "keys": [` + key("k1") + `]}]}`, wantErr: "unknown event"},
+		{name: "no keys", data: `{"endpoints": [{"name": "a", "url": "http://example.com"}]}`, wantErr: "no signing keys"},
+		{name: "invalid key ID", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "keys": [` + key("k 1") + `]}]}`, wantErr: "key ID"},
+		{name: "duplicate key ID", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "primary_key": "k1", "keys": [` + key("k1") + `, ` + key("k1") + `]}]}`, wantErr: "listed twice"},
+		{name: "short secret", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "keys": [{"id": "k1", "secret": "short"}]}]}`, wantErr: "at least 16 bytes"},
+		{name: "primary key required", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "keys": [` + key("k1") + `, ` + key("k2") + `]}]}`, wantErr: "primary_key is required"},
+		{name: "primary key not listed", data: `{"endpoints": [{"name": "a", "url": "http://example.com", "primary_key": "k3", "keys": [` + key("k1") + `]}]}`, wantErr: "not listed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoints, err := ParseEndpoints([]byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseEndpoints() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEndpoints() error = %v", err)
+			}
+			if tt.wantPrimary != "" && endpoints[0].PrimaryKey != tt.wantPrimary {
+				t.Errorf("PrimaryKey = %q, want %q", endpoints[0].PrimaryKey, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+// TestRotationMidRetry rotates the signing key while the first attempt of a delivery is being
+// answered with an error, and checks which key the retry is signed with
+func TestRotationMidRetry(t *testing.T) {
+	secrets := map[string]string{oldKey.ID: oldKey.Secret, newKey.ID: newKey.Secret}
+	tests := []struct {
+		name        string
+		rotated     Endpoint // Without URL, filled in with the test server's
+		wantKeyIDs  []string // Key ID of each attempt
+		wantPrimary string
+	}{
+		{
+			name:        "new key primary, old key removed",
+			rotated:     Endpoint{Name: "lms", PrimaryKey: newKey.ID, Keys: []Key{newKey}},
+			wantKeyIDs:  []string{oldKey.ID, newKey.ID},
+			wantPrimary: newKey.ID,
+		},
+		{
+			name:        "new key primary, old key kept",
+			rotated:     Endpoint{Name: "lms", PrimaryKey: newKey.ID, Keys: []Key{oldKey, newKey}},
+			wantKeyIDs:  []string{oldKey.ID, newKey.ID},
+			wantPrimary: newKey.ID,
+		},
+		{
+			name:        "new key added, not yet primary",
+			rotated:     Endpoint{Name: "lms", PrimaryKey: oldKey.ID, Keys: []Key{oldKey, newKey}},
+			wantKeyIDs:  []string{oldKey.ID, oldKey.ID},
+			wantPrimary: oldKey.ID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDispatcher(testWebhookConfig)
+			var url string
+			server, attempts := testEndpoint(t, func(n int) int {
+				if n == 1 {
+					rotated := tt.rotated
+					rotated.URL = url
+					d.Reload([]Endpoint{rotated})
+					return http.StatusInternalServerError
+				}
+				return http.StatusNoContent
+			})
+			url = server.URL
+			d.Reload([]Endpoint{{Name: "lms", URL: url, PrimaryKey: oldKey.ID, Keys: []Key{oldKey}}})
+
+			d.Publish(bus.FeedbackPublished.Name(), map[string]string{"id": "1"})
+			deliverNext(t, d)
+
+			got := attempts()
+			if len(got) != len(tt.wantKeyIDs) {
+				t.Fatalf("got %d attempts, want %d", len(got), len(tt.wantKeyIDs))
+			}
+			var keyIDs []string
+			for _, a := range got {
+				keyIDs = append(keyIDs, checkSignature(t, a, secrets))
+			}
+			if !slices.Equal(keyIDs, tt.wantKeyIDs) {
+				t.Errorf("attempts signed with %v, want %v", keyIDs, tt.wantKeyIDs)
+			}
+			if got[0].header.Get(EventIDHeader) != got[1].header.Get(EventIDHeader) {
+				t.Errorf("%s changed between attempts: %q, %q", EventIDHeader, got[0].header.Get(EventIDHeader), got[1].header.Get(EventIDHeader))
+			}
+			if got[0].header.Get(EventHeader) != bus.FeedbackPublished.Name() {
+				t.Errorf("%s = %q, want %q", EventHeader, got[0].header.Get(EventHeader), bus.FeedbackPublished.Name())
+			}
+
+			status := d.Statuses()[0]
+			if status.PrimaryKeyID != tt.wantPrimary || status.Delivered != 1 || status.Failed != 0 {
+				t.Errorf("Statuses() = %+v, want primary %s and 1 delivered", status, tt.wantPrimary)
+			}
+			wantSigned := make(map[string]int64)
+			for _, id := range tt.wantKeyIDs {
+				wantSigned[id]++
+			}
+			if !maps.Equal(status.Signed, wantSigned) {
+				t.Errorf("Statuses().Signed = %v, want %v", status.Signed, wantSigned)
+			}
+		})
+	}
+}
+
+func TestEndpointRemovedMidRetry(t *testing.T) {
+	d := newTestDispatcher(testWebhookConfig)
+	server, attempts := testEndpoint(t, func(int) int {
+		d.Reload(nil)
+		return http.StatusServiceUnavailable
+	})
+	d.Reload([]Endpoint{{Name: "lms", URL: server.URL, PrimaryKey: oldKey.ID, Keys: []Key{oldKey}}})
+
+	d.Publish(bus.FeedbackCreated.Name(), map[string]string{"id": "1"})
+	deliverNext(t, d)
+
+	if got := len(attempts()); got != 1 {
+		t.Errorf("got %d attempts, want 1", got)
+	}
+	statuses := d.Statuses()
+	if len(statuses) != 1 || statuses[0].PrimaryKeyID != "" || statuses[0].Dropped != 1 || statuses[0].Signed[oldKey.ID] != 1 {
+		t.Errorf("Statuses() = %+v, want the removed endpoint with 1 dropped", statuses)
+	}
+}
+
+func TestDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []int // Answer to each attempt, the last one repeated
+		wantAttempts  int
+		wantDelivered int64
+	}{
+		{name: "first attempt", statuses: []int{http.StatusOK}, wantAttempts: 1, wantDelivered: 1},
+		{name: "server error retried", statuses: []int{http.StatusBadGateway, http.StatusAccepted}, wantAttempts: 2, wantDelivered: 1},
+		{name: "throttling retried", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 2, wantDelivered: 1},
+		{name: "timeout retried", statuses: []int{http.StatusRequestTimeout, http.StatusOK}, wantAttempts: 2, wantDelivered: 1},
+		{name: "client error not retried", statuses: []int{http.StatusBadRequest}, wantAttempts: 1},
+		{name: "redirect not followed", statuses: []int{http.StatusNotModified}, wantAttempts: 1},
+		{name: "gives up after the last attempt", statuses: []int{http.StatusInternalServerError}, wantAttempts: testWebhookConfig.MaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDispatcher(testWebhookConfig)
+			server, attempts := testEndpoint(t, func(n int) int {
+				return tt.statuses[min(n, len(tt.statuses))-1]
+			})
+			d.Reload([]Endpoint{{Name: "lms", URL: server.URL, PrimaryKey: oldKey.ID, Keys: []Key{oldKey}}})
+
+			d.Publish(bus.FeedbackCreated.Name(), map[string]string{"id": "1"})
+			deliverNext(t, d)
+
+			if got := len(attempts()); got != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", got, tt.wantAttempts)
+			}
+			status := d.Statuses()[0]
+			wantFailed := 1 - tt.wantDelivered
+			if status.Delivered != tt.wantDelivered || status.Failed != wantFailed {
+				t.Errorf("Statuses() = %+v, want %d delivered and %d failed", status, tt.wantDelivered, wantFailed)
+			}
+		})
+	}
+}
+
+func TestPublishQueueFull(t *testing.T) {
+	cfg := testWebhookConfig
+	cfg.QueueSize = 1
+	d := newTestDispatcher(cfg)
+	d.Reload([]Endpoint{{Name: "lms", URL: "http://example.com", PrimaryKey: oldKey.ID, Keys: []Key{oldKey}}})
+
+	d.Publish(bus.FeedbackCreated.Name(), nil)
+	d.Publish(bus.FeedbackUpdated.Name(), nil)
+
+	if got := len(d.queue); got != 1 {
+		t.Errorf("queued %d deliveries, want 1", got)
+	}
+	if status := d.Statuses()[0]; status.Dropped != 1 {
+		t.Errorf("Statuses() = %+v, want 1 dropped", status)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	d := newTestDispatcher(testWebhookConfig)
+	d.Reload([]Endpoint{
+		{Name: "all", URL: "http://example.com", PrimaryKey: oldKey.ID, Keys: []Key{oldKey}},
+		{Name: "published", URL: "http://example.org", Events: []string{bus.FeedbackPublished.Name(), bus.FeedbackDeleted.Name()}, PrimaryKey: oldKey.ID, Keys: []Key{oldKey}},
+	})
+	b := bus.New(slog.New(slog.DiscardHandler))
+	if err := d.Subscribe(b); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	ctx := context.Background()
+	feedback := &models.Feedback{ID: uuid.New(), ReviewerID: 101, StudentID: 201, SubmissionID: 301, Title: "Lab 1"}
+	bus.Publish(ctx, b, bus.FeedbackCreated, bus.FeedbackEvent{Feedback: feedback})
+	bus.Publish(ctx, b, bus.FeedbackPublished, bus.FeedbackEvent{Feedback: feedback})
+	bus.Publish(ctx, b, bus.FeedbackDeleted, bus.FeedbackDeletedEvent{FeedbackID: feedback.ID, ActorID: 101})
+	if err := b.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got := make(map[string][]string)
+	for len(d.queue) > 0 {
+		delivery := <-d.queue
+		var body struct {
+			ID    string `json:"id"`
+			Event string `json:"event"`
+			Data  struct {
+				Name       string `json:"name"`
+				ReviewerID int64  `json:"reviewer_id"`
+				ActorID    int64  `json:"actor_id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(delivery.body, &body); err != nil {
+			t.Fatalf("payload %s: %v", delivery.body, err)
+		}
+		if body.ID != delivery.eventID || body.Event != delivery.event {
+			t.Errorf("payload %s, want id %s and event %s", delivery.body, delivery.eventID, delivery.event)
+		}
+		if body.Data.Name != "feedbacks/"+feedback.ID.String() || body.Data.ReviewerID+body.Data.ActorID != 101 {
+			t.Errorf("payload %s, want the feedback's name and user", delivery.body)
+		}
+		got[delivery.endpoint] = append(got[delivery.endpoint], delivery.event)
+	}
+
+	want := map[string][]string{
+		"all":       {bus.FeedbackCreated.Name(), bus.FeedbackPublished.Name(), bus.FeedbackDeleted.Name()},
+		"published": {bus.FeedbackPublished.Name(), bus.FeedbackDeleted.Name()},
+	}
+	for endpoint, events := range want {
+		// Each topic has its own subscriber, so events of different topics may be queued in any order
+		slices.Sort(events)
+		slices.Sort(got[endpoint])
+		if !slices.Equal(got[endpoint], events) {
+			t.Errorf("events queued for %s = %v, want %v", endpoint, got[endpoint], events)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	file := func(primary string, keys ...Key) string {
+		keysJSON, _ := json.Marshal(keys)
+		return `{"endpoints": [{"name": "lms", "url": "http://example.com", "primary_key": "` + primary + `", "keys": ` + string(keysJSON) + `}]}`
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		signal   bool
+	}{
+		{name: "SIGHUP", signal: true},
+		{name: "file change", interval: 5 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "endpoints.json")
+			write := func(data string) {
+				if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write(file(oldKey.ID, oldKey))
+
+			cfg := testWebhookConfig
+			cfg.ReloadInterval = tt.interval
+			d := newTestDispatcher(cfg)
+			if err := d.Load(path); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			hup := make(chan os.Signal)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				d.Watch(ctx, path, hup)
+			}()
+			defer func() { cancel(); <-done }()
+
+			write(file(newKey.ID, oldKey, newKey))
+			if tt.signal {
+				hup <- nil
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for d.Statuses()[0].PrimaryKeyID != newKey.ID {
+				if time.Now().After(deadline) {
+					t.Fatalf("Statuses() = %+v, want primary %s after the reload", d.Statuses(), newKey.ID)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if got := d.Statuses()[0].KeyIDs; !slices.Equal(got, []string{oldKey.ID, newKey.ID}) {
+				t.Errorf("KeyIDs = %v after the reload", got)
+			}
+
+			// An invalid file keeps the current endpoints; the second signal is only received
+			// once the first one is handled
+			write(file("missing", oldKey, newKey))
+			hup <- nil
+			hup <- nil
+			if got := d.Statuses()[0].PrimaryKeyID; got != newKey.ID {
+				t.Errorf("PrimaryKeyID = %s after an invalid file, want %s", got, newKey.ID)
+			}
+		})
+	}
+}
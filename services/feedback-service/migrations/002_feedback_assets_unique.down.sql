@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_feedback_assets_filename;
+
+CREATE INDEX idx_feedback_assets_filename ON feedback_assets(feedback_id, filename);
@@ -0,0 +1,10 @@
+CREATE TABLE transfer_usage (
+    principal_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    bytes_uploaded BIGINT NOT NULL DEFAULT 0,
+    bytes_downloaded BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (principal_id, day)
+);
+
+CREATE INDEX idx_transfer_usage_day ON transfer_usage(day);
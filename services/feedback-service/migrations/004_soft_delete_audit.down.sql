@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS feedback_audit_log;
+DROP INDEX IF EXISTS idx_feedbacks_deleted_at;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,16 @@
+ALTER TABLE feedbacks ADD COLUMN deleted_at TIMESTAMP NULL;
+ALTER TABLE feedbacks ADD COLUMN deleted_by BIGINT NULL;
+
+CREATE INDEX idx_feedbacks_deleted_at ON feedbacks(deleted_at);
+
+CREATE TABLE feedback_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    feedback_id UUID NOT NULL,
+    actor_id BIGINT NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_feedback_audit_log_feedback_id ON feedback_audit_log(feedback_id);
+CREATE INDEX idx_feedback_audit_log_created_at ON feedback_audit_log(created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS lock_reason;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS locked;
@@ -0,0 +1,2 @@
+ALTER TABLE feedbacks ADD COLUMN locked BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feedbacks ADD COLUMN lock_reason TEXT NOT NULL DEFAULT '';
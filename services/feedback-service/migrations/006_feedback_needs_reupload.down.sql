@@ -0,0 +1 @@
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS needs_reupload;
@@ -0,0 +1 @@
+ALTER TABLE feedbacks ADD COLUMN needs_reupload BOOLEAN NOT NULL DEFAULT FALSE;
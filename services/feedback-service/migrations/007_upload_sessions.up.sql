@@ -0,0 +1,20 @@
+CREATE TABLE upload_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    feedback_id UUID NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    reviewer_id BIGINT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_upload_sessions_feedback_id ON upload_sessions(feedback_id);
+
+CREATE TABLE upload_session_files (
+    session_id UUID NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    file_size BIGINT NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    promoted BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (session_id, filename)
+);
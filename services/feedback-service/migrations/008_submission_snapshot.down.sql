@@ -0,0 +1 @@
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS submission_snapshot;
@@ -0,0 +1 @@
+ALTER TABLE feedbacks ADD COLUMN submission_snapshot JSONB;
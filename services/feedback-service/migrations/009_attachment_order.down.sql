@@ -0,0 +1 @@
+ALTER TABLE feedback_assets DROP COLUMN IF EXISTS sort_position;
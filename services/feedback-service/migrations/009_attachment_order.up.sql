@@ -0,0 +1 @@
+ALTER TABLE feedback_assets ADD COLUMN sort_position INTEGER;
@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS course_id;
+
+DROP TABLE IF EXISTS course_policies;
@@ -0,0 +1,9 @@
+CREATE TABLE course_policies (
+    course_id VARCHAR(128) PRIMARY KEY,
+    policy JSONB NOT NULL,
+    updated_by BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE feedbacks ADD COLUMN course_id VARCHAR(128);
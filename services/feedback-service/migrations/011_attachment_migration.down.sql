@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attachment_migration_journal;
+DROP TABLE IF EXISTS attachment_migration_state;
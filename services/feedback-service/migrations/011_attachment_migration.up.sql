@@ -0,0 +1,20 @@
+CREATE TABLE attachment_migration_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    cursor TEXT NOT NULL DEFAULT '',
+    listing_complete BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO attachment_migration_state (id) VALUES (1);
+
+CREATE TABLE attachment_migration_journal (
+    object_key TEXT PRIMARY KEY,
+    status VARCHAR(16) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    checksum TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_attachment_migration_journal_failed ON attachment_migration_journal (object_key) WHERE status = 'failed';
@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks DROP CONSTRAINT IF EXISTS feedbacks_grade_check;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS max_points;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS points;
@@ -0,0 +1,8 @@
+ALTER TABLE feedbacks ADD COLUMN points DOUBLE PRECISION;
+ALTER TABLE feedbacks ADD COLUMN max_points DOUBLE PRECISION;
+
+-- A grade is stored as a whole: both columns are set or both are NULL
+ALTER TABLE feedbacks ADD CONSTRAINT feedbacks_grade_check CHECK (
+    (points IS NULL AND max_points IS NULL)
+    OR (points >= 0 AND max_points > 0 AND points <= max_points)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE feedbacks DROP CONSTRAINT IF EXISTS feedbacks_status_check;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS published_at;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS status;
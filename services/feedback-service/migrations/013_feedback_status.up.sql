@@ -0,0 +1,9 @@
+-- Existing feedbacks were visible to students, so they are published as of their creation
+ALTER TABLE feedbacks ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'PUBLISHED';
+ALTER TABLE feedbacks ADD COLUMN published_at TIMESTAMP;
+UPDATE feedbacks SET published_at = created_at;
+
+ALTER TABLE feedbacks ADD CONSTRAINT feedbacks_status_check CHECK (
+    (status = 'DRAFT' AND published_at IS NULL)
+    OR (status = 'PUBLISHED' AND published_at IS NOT NULL)
+);
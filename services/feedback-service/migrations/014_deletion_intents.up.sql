@@ -0,0 +1,14 @@
+-- A feedback being hard deleted. Written together with deleted_at on the feedback row, so the
+-- feedback disappears from reads before any of its data is removed.
+CREATE TABLE feedback_deletion_intents (
+    feedback_id UUID PRIMARY KEY,
+    requested_by BIGINT NOT NULL,
+    action VARCHAR(64) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_feedback_deletion_intents_updated_at ON feedback_deletion_intents(updated_at);
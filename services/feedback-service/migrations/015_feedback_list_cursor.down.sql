@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feedbacks_student_created;
+DROP INDEX IF EXISTS idx_feedbacks_reviewer_created;
@@ -0,0 +1,4 @@
+-- Serve reviewer and student lists, ordered by (created_at, id), straight from an index so
+-- keyset pages after a page token do not scan the preceding rows.
+CREATE INDEX idx_feedbacks_reviewer_created ON feedbacks(reviewer_id, created_at DESC, id DESC);
+CREATE INDEX idx_feedbacks_student_created ON feedbacks(student_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE upload_session_files DROP COLUMN IF EXISTS sha256;
+ALTER TABLE feedback_assets DROP COLUMN IF EXISTS sha256;
@@ -0,0 +1,4 @@
+-- SHA-256 of an attachment's plaintext, recorded while it is uploaded and verified when it is
+-- downloaded. Empty for attachments uploaded before checksums were recorded.
+ALTER TABLE feedback_assets ADD COLUMN sha256 VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE upload_session_files ADD COLUMN sha256 VARCHAR(64) NOT NULL DEFAULT '';
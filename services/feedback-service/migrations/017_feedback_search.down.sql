@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_feedbacks_search_vector;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS content_search;
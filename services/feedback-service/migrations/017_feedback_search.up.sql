@@ -0,0 +1,10 @@
+-- Full-text search over feedback titles and content. The content lives in MongoDB, so its
+-- lexemes are written by the service into content_search whenever the content is stored;
+-- search_vector combines them with the title, which ranks higher. Content stored before this
+-- migration is indexed by the backfill-search-index maintenance task.
+ALTER TABLE feedbacks ADD COLUMN content_search TSVECTOR NOT NULL DEFAULT ''::tsvector;
+ALTER TABLE feedbacks ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(content_search, 'B')
+) STORED;
+
+CREATE INDEX idx_feedbacks_search_vector ON feedbacks USING GIN (search_vector);
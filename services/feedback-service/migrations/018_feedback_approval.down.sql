@@ -0,0 +1,4 @@
+ALTER TABLE feedbacks DROP CONSTRAINT IF EXISTS feedbacks_approval_status_check;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS approval_note;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS approver_id;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS approval_status;
@@ -0,0 +1,9 @@
+-- Existing feedbacks need no approval, so students keep seeing them
+ALTER TABLE feedbacks ADD COLUMN approval_status VARCHAR(24) NOT NULL DEFAULT 'NOT_REQUIRED';
+ALTER TABLE feedbacks ADD COLUMN approver_id BIGINT;
+ALTER TABLE feedbacks ADD COLUMN approval_note TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE feedbacks ADD CONSTRAINT feedbacks_approval_status_check CHECK (
+    approval_status IN ('NOT_REQUIRED', 'PENDING', 'APPROVED', 'CHANGES_REQUESTED')
+    AND (approval_status = 'NOT_REQUIRED' OR approver_id IS NOT NULL)
+);
@@ -0,0 +1,5 @@
+-- Content length of each feedback in characters, for statistics. The content lives in MongoDB,
+-- so the service writes the length together with the search lexemes whenever the content is
+-- stored. Content stored before this migration is measured by the backfill-search-index
+-- maintenance task.
+ALTER TABLE feedbacks ADD COLUMN content_length INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attachment_backfill_journal;
+DROP TABLE IF EXISTS attachment_backfill_state;
@@ -0,0 +1,21 @@
+-- Backfill of feedback_assets from the objects in MinIO, for attachments uploaded before their
+-- metadata was recorded. Each attachment prefix ({feedbackID}/) is journaled once its objects
+-- have rows, so an interrupted backfill resumes with the prefixes it has not finished.
+CREATE TABLE attachment_backfill_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    total_prefixes BIGINT NOT NULL DEFAULT 0, -- Prefixes found by the last complete listing
+    complete BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO attachment_backfill_state (id) VALUES (1);
+
+CREATE TABLE attachment_backfill_journal (
+    prefix TEXT PRIMARY KEY,
+    status VARCHAR(16) NOT NULL,
+    rows_created INTEGER NOT NULL DEFAULT 0,
+    conflicts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
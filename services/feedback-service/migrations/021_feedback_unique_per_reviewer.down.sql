@@ -0,0 +1,2 @@
+-- Feedbacks soft deleted as duplicates stay deleted
+DROP INDEX IF EXISTS idx_feedbacks_reviewer_submission;
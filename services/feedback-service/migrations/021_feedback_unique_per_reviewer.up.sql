@@ -0,0 +1,24 @@
+-- A reviewer writes at most one feedback per submission; a second one is almost always a
+-- double submit from the UI. Existing duplicates are resolved by keeping the newest feedback
+-- and soft deleting the others on behalf of the system (actor 0), with an audit entry naming
+-- the feedback that was kept, so nothing is lost before retention purges them.
+WITH ranked AS (
+    SELECT
+        id,
+        FIRST_VALUE(id) OVER w AS kept_id,
+        ROW_NUMBER() OVER w AS position
+    FROM feedbacks
+    WHERE deleted_at IS NULL
+    WINDOW w AS (PARTITION BY reviewer_id, submission_id ORDER BY created_at DESC, id DESC)
+), removed AS (
+    UPDATE feedbacks f
+    SET deleted_at = NOW(), deleted_by = 0
+    FROM ranked r
+    WHERE f.id = r.id AND r.position > 1
+    RETURNING f.id, r.kept_id
+)
+INSERT INTO feedback_audit_log (feedback_id, actor_id, action, details)
+SELECT id, 0, 'feedback.soft_deleted', 'duplicate of ' || kept_id FROM removed;
+
+-- Soft-deleted feedbacks do not count, so a feedback can be written again after deleting one
+CREATE UNIQUE INDEX idx_feedbacks_reviewer_submission ON feedbacks(reviewer_id, submission_id) WHERE deleted_at IS NULL;
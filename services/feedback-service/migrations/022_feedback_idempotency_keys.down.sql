@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_feedbacks_idempotency_key;
+
+ALTER TABLE feedbacks
+    DROP COLUMN IF EXISTS idempotency_fingerprint,
+    DROP COLUMN IF EXISTS idempotency_key;
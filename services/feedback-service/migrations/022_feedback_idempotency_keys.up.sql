@@ -0,0 +1,9 @@
+-- Mobile clients retry CreateFeedback on flaky networks with the same client-chosen key. A
+-- retry returns the feedback the key created instead of inserting another one. The payload
+-- fingerprint tells a retry from a different request that reuses the key. Keys expire with
+-- the idempotency_keys retention dataset, which clears both columns.
+ALTER TABLE feedbacks
+    ADD COLUMN idempotency_key TEXT,
+    ADD COLUMN idempotency_fingerprint TEXT;
+
+CREATE UNIQUE INDEX idx_feedbacks_idempotency_key ON feedbacks(reviewer_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
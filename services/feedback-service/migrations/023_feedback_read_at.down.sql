@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feedbacks_student_unread;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS read_at;
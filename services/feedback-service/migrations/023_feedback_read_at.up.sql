@@ -0,0 +1,4 @@
+-- When the student first acknowledged (opened) the feedback; NULL while unread
+ALTER TABLE feedbacks ADD COLUMN read_at TIMESTAMP NULL;
+
+CREATE INDEX idx_feedbacks_student_unread ON feedbacks(student_id) WHERE read_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feedback_seals;
+DROP FUNCTION IF EXISTS feedback_seals_append_only();
@@ -0,0 +1,26 @@
+-- Tamper-evident records of a feedback's content and attachments, e.g. at the end of an appeal
+-- window. Seals of all feedbacks form one hash chain: chain_sha256 hashes the previous seal's
+-- chain_sha256 with this seal's manifest_sha256, so a changed or removed seal breaks every
+-- later link. Rows are never updated or deleted, and outlive the feedback they record.
+CREATE TABLE feedback_seals (
+    id BIGSERIAL PRIMARY KEY,
+    feedback_id UUID NOT NULL,
+    actor_id BIGINT NOT NULL,
+    manifest TEXT NOT NULL,
+    manifest_sha256 CHAR(64) NOT NULL,
+    previous_sha256 CHAR(64) NOT NULL DEFAULT '',
+    chain_sha256 CHAR(64) NOT NULL UNIQUE,
+    sealed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_feedback_seals_feedback_id ON feedback_seals(feedback_id, id);
+
+CREATE FUNCTION feedback_seals_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'feedback_seals is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER feedback_seals_append_only
+    BEFORE UPDATE OR DELETE ON feedback_seals
+    FOR EACH ROW EXECUTE FUNCTION feedback_seals_append_only();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feedback_revisions;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS revision_count;
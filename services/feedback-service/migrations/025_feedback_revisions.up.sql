@@ -0,0 +1,14 @@
+-- Edit history of feedbacks. Revision n holds the title and content written by the nth edit;
+-- revision 0 is the text as created and is recorded with the first edit. revision_count is
+-- the number of edits, so clients can tell an edited feedback without listing revisions.
+ALTER TABLE feedbacks ADD COLUMN revision_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE feedback_revisions (
+    feedback_id UUID NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    edited_at TIMESTAMP NOT NULL,
+    edited_by BIGINT NOT NULL,
+    PRIMARY KEY (feedback_id, revision)
+);
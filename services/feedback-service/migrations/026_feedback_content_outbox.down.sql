@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feedback_content_outbox_next_attempt;
+DROP TABLE IF EXISTS feedback_content_outbox;
@@ -0,0 +1,16 @@
+-- Feedback content waiting to be written to MongoDB. A row is written in the same transaction
+-- as the feedbacks row it belongs to and deleted once the content is stored, so content is
+-- never lost when the MongoDB write fails. A feedback has at most one pending row: a newer
+-- write replaces the content and bumps version.
+CREATE TABLE feedback_content_outbox (
+    feedback_id UUID PRIMARY KEY REFERENCES feedbacks(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_feedback_content_outbox_next_attempt ON feedback_content_outbox(next_attempt_at);
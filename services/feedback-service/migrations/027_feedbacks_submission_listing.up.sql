@@ -0,0 +1,3 @@
+-- Serves ListSubmissionFeedbacks: the feedbacks of a submission that are not deleted, newest
+-- first, without sorting
+CREATE INDEX idx_feedbacks_submission_created ON feedbacks(submission_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
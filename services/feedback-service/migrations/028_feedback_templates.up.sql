@@ -0,0 +1,13 @@
+-- Boilerplate titles and content a reviewer starts new feedback from. Templates belong to one
+-- reviewer, and a reviewer's template names are unique.
+CREATE TABLE feedback_templates (
+    id UUID PRIMARY KEY,
+    reviewer_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_feedback_templates_reviewer_name ON feedback_templates(reviewer_id, name);
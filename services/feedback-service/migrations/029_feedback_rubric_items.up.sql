@@ -0,0 +1,14 @@
+-- Per-criterion scores of feedbacks graded against a rubric, in rubric order. A feedback's set
+-- of items is written as a whole; the total score is computed from them when read.
+CREATE TABLE feedback_rubric_items (
+    feedback_id UUID NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    max_score DOUBLE PRECISION NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (feedback_id, position),
+    CONSTRAINT feedback_rubric_items_score_range CHECK (max_score > 0 AND score >= 0 AND score <= max_score)
+);
+
+CREATE UNIQUE INDEX idx_feedback_rubric_items_name ON feedback_rubric_items (feedback_id, name);
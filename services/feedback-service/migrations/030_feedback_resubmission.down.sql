@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_feedbacks_student_resubmission;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS resubmission_deadline;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS requires_resubmission;
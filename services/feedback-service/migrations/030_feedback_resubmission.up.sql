@@ -0,0 +1,5 @@
+-- Whether the reviewer asks the student to fix and resubmit, and by when (NULL for no deadline)
+ALTER TABLE feedbacks ADD COLUMN requires_resubmission BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feedbacks ADD COLUMN resubmission_deadline TIMESTAMP NULL;
+
+CREATE INDEX idx_feedbacks_student_resubmission ON feedbacks(student_id) WHERE requires_resubmission AND deleted_at IS NULL;
@@ -0,0 +1,11 @@
+-- Reactions of users to feedbacks. A user has at most one reaction per feedback; reacting
+-- again replaces it. Counts are computed from the rows when feedbacks are read.
+CREATE TABLE feedback_reactions (
+    feedback_id UUID NOT NULL REFERENCES feedbacks(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    reaction VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (feedback_id, user_id),
+    CONSTRAINT feedback_reactions_reaction CHECK (reaction IN ('HELPFUL', 'NOT_HELPFUL'))
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_feedbacks_unarchived_created;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS archived_at;
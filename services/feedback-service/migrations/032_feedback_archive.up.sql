@@ -0,0 +1,6 @@
+-- When a feedback was archived, explicitly or by the archiver; NULL while it is active.
+-- Archived feedbacks are left out of lists by default.
+ALTER TABLE feedbacks ADD COLUMN archived_at TIMESTAMP NULL;
+
+-- The archiver looks for old feedbacks that are still active
+CREATE INDEX idx_feedbacks_unarchived_created ON feedbacks(created_at) WHERE archived_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_feedbacks_student_activity;
@@ -0,0 +1,4 @@
+-- Student lists report when the matching feedbacks last saw activity: publication, an edit
+-- or the student reading them. The expression matches the repository query, so the maximum
+-- per student is read from the index rather than the table.
+CREATE INDEX idx_feedbacks_student_activity ON feedbacks(student_id, (GREATEST(COALESCE(published_at, created_at), updated_at, read_at)) DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE feedback_assets ALTER COLUMN content_type TYPE VARCHAR(100) USING LEFT(content_type, 100);
@@ -0,0 +1,4 @@
+-- Uploads accept content types of up to 127 bytes (validation.MaxContentTypeBytes), longer
+-- than the original column, so a valid type could fail the insert of the attachment row.
+-- Widened to the size of upload_sessions.content_type, which holds the same value.
+ALTER TABLE feedback_assets ALTER COLUMN content_type TYPE VARCHAR(255);